@@ -14,6 +14,7 @@ import (
 	"xiaoheiplay/internal/adapter/payment"
 	"xiaoheiplay/internal/adapter/plugins/automation"
 	"xiaoheiplay/internal/adapter/plugins/core"
+	"xiaoheiplay/internal/adapter/probe"
 	"xiaoheiplay/internal/adapter/push"
 	"xiaoheiplay/internal/adapter/realname"
 	"xiaoheiplay/internal/adapter/repo/core"
@@ -21,25 +22,37 @@ import (
 	"xiaoheiplay/internal/adapter/seed"
 	"xiaoheiplay/internal/adapter/sse"
 	"xiaoheiplay/internal/adapter/system"
+	appaddon "xiaoheiplay/internal/app/addon"
 	appadmin "xiaoheiplay/internal/app/admin"
 	appadminvps "xiaoheiplay/internal/app/adminvps"
 	appapikey "xiaoheiplay/internal/app/apikey"
 	appauth "xiaoheiplay/internal/app/auth"
 	appautomationlog "xiaoheiplay/internal/app/automationlog"
+	appbackuppolicy "xiaoheiplay/internal/app/backuppolicy"
+	appbroadcast "xiaoheiplay/internal/app/broadcast"
 	appcart "xiaoheiplay/internal/app/cart"
 	appcatalog "xiaoheiplay/internal/app/catalog"
 	appcms "xiaoheiplay/internal/app/cms"
 	appcoupon "xiaoheiplay/internal/app/coupon"
+	appdunning "xiaoheiplay/internal/app/dunning"
 	appgoodstype "xiaoheiplay/internal/app/goodstype"
 	appintegration "xiaoheiplay/internal/app/integration"
+	appinventory "xiaoheiplay/internal/app/inventory"
+	appipaddress "xiaoheiplay/internal/app/ipaddress"
+	appledger "xiaoheiplay/internal/app/ledger"
 	applogcleanup "xiaoheiplay/internal/app/logcleanup"
 	appmessage "xiaoheiplay/internal/app/message"
+	appmetrics "xiaoheiplay/internal/app/metrics"
 	appnotification "xiaoheiplay/internal/app/notification"
+	appnotifychannel "xiaoheiplay/internal/app/notifychannel"
+	appnotifypref "xiaoheiplay/internal/app/notifypref"
 	appopenapi "xiaoheiplay/internal/app/openapi"
 	apporder "xiaoheiplay/internal/app/order"
+	apporderapproval "xiaoheiplay/internal/app/orderapproval"
 	apporderevent "xiaoheiplay/internal/app/orderevent"
 	apppasswordreset "xiaoheiplay/internal/app/passwordreset"
 	apppayment "xiaoheiplay/internal/app/payment"
+	apppayout "xiaoheiplay/internal/app/payout"
 	apppermission "xiaoheiplay/internal/app/permission"
 	apppluginadmin "xiaoheiplay/internal/app/pluginadmin"
 	appprobe "xiaoheiplay/internal/app/probe"
@@ -49,14 +62,20 @@ import (
 	appscheduledtask "xiaoheiplay/internal/app/scheduledtask"
 	appsecurityticket "xiaoheiplay/internal/app/securityticket"
 	appsettings "xiaoheiplay/internal/app/settings"
+	appsshkey "xiaoheiplay/internal/app/sshkey"
+	appstatement "xiaoheiplay/internal/app/statement"
 	appsystemstatus "xiaoheiplay/internal/app/systemstatus"
 	appticket "xiaoheiplay/internal/app/ticket"
+	apptrial "xiaoheiplay/internal/app/trial"
 	appupload "xiaoheiplay/internal/app/upload"
 	appuserapikey "xiaoheiplay/internal/app/userapikey"
 	appusertier "xiaoheiplay/internal/app/usertier"
 	appvps "xiaoheiplay/internal/app/vps"
+	appvpsbulk "xiaoheiplay/internal/app/vpsbulk"
+	appvpsmigration "xiaoheiplay/internal/app/vpsmigration"
 	appwallet "xiaoheiplay/internal/app/wallet"
 	appwalletorder "xiaoheiplay/internal/app/walletorder"
+	"xiaoheiplay/internal/domain"
 	"xiaoheiplay/internal/pkg/config"
 	"xiaoheiplay/internal/pkg/cryptox"
 	"xiaoheiplay/internal/pkg/db"
@@ -120,11 +139,18 @@ func main() {
 	if err != nil {
 		log.Fatalf("plugin cipher: %v", err)
 	}
+	repoSQLite.SetRealNameCipher(pluginCipher)
+	if n, err := repoSQLite.EncryptLegacyIDNumbers(context.Background()); err != nil {
+		log.Printf("encrypt realname id numbers: %v", err)
+	} else if n > 0 {
+		log.Printf("encrypted %d legacy realname id numbers", n)
+	}
 	if strings.TrimSpace(cfg.PluginsDir) == "" {
 		log.Fatalf("plugins_dir is empty in config")
 	}
 	pluginMgr := plugins.NewManager(cfg.PluginsDir, repoSQLite, pluginCipher, plugins.ParseEd25519PublicKeys(cfg.PluginOfficialKeys))
 	pluginSMSSender := plugins.NewSMSSender(pluginMgr)
+	notifyChannelSvc := appnotifychannel.NewService(repoSQLite, plugins.NewNotifySender(pluginMgr))
 	pluginAdminSvc := apppluginadmin.NewService(plugins.NewAdminManager(pluginMgr), repoSQLite, repoSQLite)
 	_ = pluginMgr.BootstrapFromDisk(context.Background(), repoSQLite)
 	pluginMgr.StartEnabled(context.Background())
@@ -134,26 +160,54 @@ func main() {
 	cartSvc := appcart.NewService(repoSQLite, repoSQLite, repoSQLite)
 	broker := sse.NewBroker(repoSQLite)
 	automationResolver := automation.NewResolver(repoSQLite, pluginMgr, repoSQLite, repoSQLite)
-	emailSender := email.NewSender(repoSQLite)
+	emailSender := notifyChannelSvc.EmailSender(email.NewSender(repoSQLite))
 	robotNotifier := robot.NewWebhookNotifier(repoSQLite)
 	pushSender := push.NewFCMSender()
 	pushSvc := apppush.NewService(repoSQLite, repoSQLite, repoSQLite, pushSender)
 	pushNotifier := push.NewOrderPushNotifier(repoSQLite, pushSvc)
-	eventBus := event.NewFanoutPublisher(broker, robotNotifier, pushNotifier)
+	eventBus := event.NewFanoutPublisher(broker, robotNotifier, pushNotifier, notifyChannelSvc)
 	realnameRegistry := realname.NewRegistry(repoSQLite)
 	realnameRegistry.SetPluginManager(pluginMgr)
 	realnameSvc := apprealname.NewService(repoSQLite, realnameRegistry, repoSQLite)
 	messageSvc := appmessage.NewService(repoSQLite, repoSQLite)
-	orderSvc := apporder.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, eventBus, automationResolver, nil, repoSQLite, repoSQLite, emailSender, repoSQLite, repoSQLite, repoSQLite, repoSQLite, messageSvc, realnameSvc)
+	notifyPrefSvc := appnotifypref.NewService(repoSQLite)
+	messageSvc.SetNotificationPreferences(notifyPrefSvc)
+	messageSvc.SetEmailSender(emailSender)
+	messageSvc.SetSMSSender(pluginSMSSender, repoSQLite)
+	messageSvc.SetDeferredQueue(repoSQLite)
+	pushSvc.SetNotificationPreferences(notifyPrefSvc)
+	pushSvc.SetDeferredQueue(repoSQLite)
+	realnameSvc.SetManualReview(repoSQLite, repoSQLite, repoSQLite)
+	realnameSvc.SetMessageService(messageSvc)
+	realnameSvc.SetCompanyVerification(repoSQLite)
+	pluginAdminSvc.SetHealthNotifier(repoSQLite, messageSvc)
+	pluginMgr.SetHealthEventHandler(func(ctx context.Context, ev domain.PluginHealthEvent) {
+		pluginAdminSvc.HandleHealthEvent(ctx, ev)
+		notifyChannelSvc.NotifyPluginHealth(ctx, ev)
+	})
+	orderSvc := apporder.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, eventBus, automationResolver, nil, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, messageSvc, realnameSvc)
 	vpsSvc := appvps.NewService(repoSQLite, automationResolver, repoSQLite)
+	sshKeySvc := appsshkey.NewService(repoSQLite)
+	vpsSvc.SetSSHKeyService(sshKeySvc)
 	adminSvc := appadmin.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite)
 	adminVPSSvc := appadminvps.NewService(repoSQLite, automationResolver, repoSQLite, repoSQLite, repoSQLite, messageSvc)
 	apiKeySvc := appapikey.NewService(repoSQLite)
 	userAPIKeySvc := appuserapikey.NewService(repoSQLite)
 	authSvc := appauth.NewService(repoSQLite, repoSQLite, repoSQLite)
-	notifySvc := appnotification.NewService(repoSQLite, repoSQLite, repoSQLite, emailSender, messageSvc)
+	notifySvc := appnotification.NewService(repoSQLite, repoSQLite, repoSQLite, messageSvc)
 	integrationSvc := appintegration.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, automationResolver, repoSQLite)
 	reportSvc := appreport.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite)
+	reportSvc.SetWalletSources(repoSQLite, repoSQLite, repoSQLite)
+	financeReportSvc := appreport.NewFinanceService(repoSQLite, reportSvc, repoSQLite)
+	statementSvc := appstatement.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite)
+	dunningSvc := appdunning.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite)
+	dunningSvc.SetMessageService(messageSvc)
+	dunningSvc.SetInstanceActions(vpsSvc)
+	vpsSvc.SetDunningService(dunningSvc)
+	notifySvc.SetDunningService(dunningSvc)
+	broadcastSvc := appbroadcast.NewService(repoSQLite, messageSvc, repoSQLite, repoSQLite)
+	broadcastSvc.SetPushService(pushSvc)
+	financeReportSvc.SetMailer(email.NewSender(repoSQLite))
 	cmsSvc := appcms.NewService(repoSQLite, repoSQLite, repoSQLite, messageSvc)
 	ticketSvc := appticket.NewService(repoSQLite, repoSQLite, repoSQLite, messageSvc)
 	permissionSvc := apppermission.NewService(repoSQLite, repoSQLite, repoSQLite)
@@ -181,7 +235,18 @@ func main() {
 	orderSvc.SetUserTierPricingResolver(userTierSvc)
 	orderSvc.SetUserTierAutoApprover(userTierSvc)
 	orderSvc.SetCouponService(couponSvc)
+	addonSvc := appaddon.NewService(repoSQLite, repoSQLite)
+	addonSvc.SetAddonPricer(userTierSvc)
+	orderSvc.SetAddonService(addonSvc)
+	cartSvc.SetAddonService(addonSvc)
+	vpsSvc.SetAddonService(addonSvc)
+	inventorySvc := appinventory.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite)
+	orderSvc.SetInventoryService(inventorySvc)
+	orderSvc.SetSSHKeyService(sshKeySvc)
+	integrationSvc.SetInventoryReconciler(inventorySvc)
 	walletOrderSvc.SetUserTierAutoApprover(userTierSvc)
+	walletOrderSvc.SetWalletHolds(repoSQLite)
+	walletSvc.SetWalletHolds(repoSQLite)
 	uploadSvc := appupload.NewService(repoSQLite)
 	autoLogSvc := appautomationlog.NewService(repoSQLite)
 	orderEventSvc := apporderevent.NewService(repoSQLite)
@@ -192,15 +257,54 @@ func main() {
 	paymentRegistry.SetPluginManager(pluginMgr)
 	paymentRegistry.SetPluginPaymentMethodRepo(repoSQLite)
 	paymentSvc := apppayment.NewService(repoSQLite, repoSQLite, repoSQLite, paymentRegistry, repoSQLite, orderSvc, eventBus)
+	paymentSvc.SetWalletHolds(repoSQLite)
+	ledgerSvc := appledger.NewService(repoSQLite)
+	ledgerSvc.SetAlerter(notifyChannelSvc)
+	walletSvc.SetLedger(ledgerSvc)
+	walletOrderSvc.SetLedger(ledgerSvc)
+	paymentSvc.SetLedger(ledgerSvc)
+	orderSvc.SetLedger(ledgerSvc)
+	payoutSvc := apppayout.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite)
+	payoutSvc.SetLedger(ledgerSvc)
+	walletOrderSvc.SetPayoutAccounts(payoutSvc)
 	openAPISvc := appopenapi.NewService(orderSvc, paymentSvc, repoSQLite)
 	statusSvc := appsystemstatus.NewService(system.NewProvider())
 	taskSvc := appscheduledtask.NewService(repoSQLite, vpsSvc, orderSvc, notifySvc, repoSQLite, realnameSvc)
 	logCleanupSvc := applogcleanup.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite)
 	taskSvc.SetUserTierService(userTierSvc)
 	taskSvc.SetIntegrationService(integrationSvc)
+	taskSvc.SetInventoryService(orderSvc)
 	taskSvc.SetLogRetentionCleaner(logCleanupSvc)
+	taskSvc.SetLedgerService(ledgerSvc)
+	taskSvc.SetFinanceReportService(financeReportSvc)
+	taskSvc.SetDunningService(dunningSvc)
+	taskSvc.SetBroadcastService(broadcastSvc)
+	taskSvc.SetDeferredNotificationServices(messageSvc, pushSvc)
+	backupPolicySvc := appbackuppolicy.NewService(repoSQLite, repoSQLite, vpsSvc, repoSQLite)
+	backupPolicySvc.SetMessageService(messageSvc)
+	taskSvc.SetBackupPolicyService(backupPolicySvc)
+	vpsBulkSvc := appvpsbulk.NewService(repoSQLite, adminVPSSvc)
+	_ = vpsBulkSvc.RecoverInterrupted(context.Background())
+	vpsMigrationSvc := appvpsmigration.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, automationResolver, repoSQLite)
+	vpsMigrationSvc.SetOrderItemRepository(repoSQLite)
+	vpsMigrationSvc.SetMessageService(messageSvc)
+	taskSvc.SetVPSMigrationService(vpsMigrationSvc)
+	ipAddressSvc := appipaddress.NewService(repoSQLite, repoSQLite, automationResolver)
+	vpsSvc.SetIPAddressService(ipAddressSvc)
+	trialSvc := apptrial.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, orderSvc)
+	orderSvc.SetTrialService(trialSvc)
+	vpsSvc.SetTrialService(trialSvc)
+	orderApprovalSvc := apporderapproval.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite)
+	orderSvc.SetApprovalPolicy(orderApprovalSvc)
 	probeHub := appprobe.NewHub()
 	probeSvc := appprobe.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite)
+	probeSvc.SetReleaseStore(probe.NewReleaseStore(cfg.ProbeReleasesDir, plugins.ParseEd25519PublicKeys(cfg.PluginOfficialKeys)))
+	metricsSvc := appmetrics.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite)
+	metricsSvc.SetTaskLister(taskSvc)
+	metricsSvc.SetPluginLister(pluginAdminSvc)
+	metricsSvc.SetProbeHub(probeHub)
+	metricsSvc.AddSSESource("order_events", broker)
+	metricsSvc.AddSSESource("probe_logs", probeHub)
 	go taskSvc.Start(context.Background())
 	go probeSvc.StartOfflineWatcher(context.Background())
 
@@ -211,6 +315,7 @@ func main() {
 	handler := http.NewHandler(http.HandlerDeps{
 		AuthSvc:           authSvc,
 		CatalogSvc:        catalogSvc,
+		AddonSvc:          addonSvc,
 		GoodsTypes:        goodsTypeSvc,
 		CartSvc:           cartSvc,
 		OrderSvc:          orderSvc,
@@ -248,6 +353,23 @@ func main() {
 		ProbeHub:          probeHub,
 		EmailSender:       emailSender,
 		RobotNotifier:     robotNotifier,
+		MetricsSvc:        metricsSvc,
+		NotifyChannelSvc:  notifyChannelSvc,
+		InventorySvc:      inventorySvc,
+		SSHKeySvc:         sshKeySvc,
+		BackupPolicySvc:   backupPolicySvc,
+		VPSBulkSvc:        vpsBulkSvc,
+		VPSMigrationSvc:   vpsMigrationSvc,
+		IPAddressSvc:      ipAddressSvc,
+		TrialSvc:          trialSvc,
+		OrderApprovalSvc:  orderApprovalSvc,
+		LedgerSvc:         ledgerSvc,
+		PayoutSvc:         payoutSvc,
+		FinanceReportSvc:  financeReportSvc,
+		StatementSvc:      statementSvc,
+		DunningSvc:        dunningSvc,
+		NotifyPrefSvc:     notifyPrefSvc,
+		BroadcastSvc:      broadcastSvc,
 	})
 	middleware := http.NewMiddleware(cfg.JWTSecret, apiKeySvc, userAPIKeySvc, permissionSvc, authSvc, settingsSvc)
 	server := http.NewServer(handler, middleware)
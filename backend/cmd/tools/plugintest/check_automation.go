@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"xiaoheiplay/pkg/pluginsdk"
+	pluginv1 "xiaoheiplay/plugin/v1"
+)
+
+const groupAutomation = "automation"
+
+// provisioningStates are AutomationInstance.state values the host maps to
+// "provisioning"; 2 is running, 3 is stopped and 5 is reinstall failed.
+var provisioningStates = map[int32]bool{0: true, 1: true, 13: true}
+
+func (r *runner) checkAutomation(ctx context.Context, capability *pluginv1.AutomationCapability) {
+	cli, err := dispense[pluginv1.AutomationServiceClient](r.rpc, pluginsdk.PluginKeyAutomation)
+	if err != nil {
+		r.rep.fail(groupAutomation, "dispense", err.Error(), 0)
+		return
+	}
+	features := map[pluginv1.AutomationFeature]bool{}
+	for _, f := range capability.GetFeatures() {
+		features[f] = true
+	}
+	sc := r.opts.Scenario.Automation
+
+	if features[pluginv1.AutomationFeature_AUTOMATION_FEATURE_CATALOG_SYNC] {
+		r.checkCatalog(ctx, cli, sc)
+	} else {
+		r.rep.skip(groupAutomation, "catalog", "catalog_sync not declared")
+	}
+
+	if !features[pluginv1.AutomationFeature_AUTOMATION_FEATURE_LIFECYCLE] {
+		r.rep.skip(groupAutomation, "lifecycle", "lifecycle not declared")
+		return
+	}
+	if sc == nil || len(sc.Create) == 0 {
+		r.rep.skip(groupAutomation, "lifecycle", "no automation.create in scenario")
+		return
+	}
+	req := &pluginv1.CreateInstanceRequest{}
+	if err := decodeMessage(sc.Create, req); err != nil {
+		r.rep.fail(groupAutomation, "CreateInstance", err.Error(), 0)
+		return
+	}
+	if len(req.GetSshPublicKeys()) > 0 && !features[pluginv1.AutomationFeature_AUTOMATION_FEATURE_SSH_KEYS] {
+		r.rep.warn(groupAutomation, "CreateInstance", "ssh_public_keys given but ssh_keys not declared; the host never sends them", 0)
+	}
+	if req.GetUserData() != "" && !features[pluginv1.AutomationFeature_AUTOMATION_FEATURE_CLOUD_INIT] {
+		r.rep.warn(groupAutomation, "CreateInstance", "user_data given but cloud_init not declared; the host never sends it", 0)
+	}
+
+	start := time.Now()
+	cctx, cancel := r.callCtx(ctx)
+	created, err := cli.CreateInstance(cctx, req)
+	cancel()
+	if err != nil {
+		r.rep.fail(groupAutomation, "CreateInstance", err.Error(), time.Since(start))
+		return
+	}
+	id := created.GetInstanceId()
+	if id <= 0 {
+		r.rep.fail(groupAutomation, "CreateInstance", "instance_id is empty", time.Since(start))
+		return
+	}
+	r.rep.add(groupAutomation, "CreateInstance", statusPass, fmt.Sprintf("instance_id=%d", id), time.Since(start))
+
+	if sc.Keep {
+		defer r.rep.skip(groupAutomation, "Destroy", "keep is set")
+	} else {
+		defer r.destroy(ctx, cli, id)
+	}
+
+	if !r.waitState(ctx, cli, id, "GetInstance(ready)", sc.wait(), func(state int32) bool { return !provisioningStates[state] }) {
+		return
+	}
+	cctx, cancel = r.callCtx(ctx)
+	start = time.Now()
+	res, err := cli.Shutdown(cctx, &pluginv1.ShutdownRequest{InstanceId: id})
+	cancel()
+	if !r.operation("Shutdown", res, err, time.Since(start)) {
+		return
+	}
+	if !r.waitState(ctx, cli, id, "GetInstance(stopped)", sc.wait(), func(state int32) bool { return state == 3 }) {
+		return
+	}
+	cctx, cancel = r.callCtx(ctx)
+	start = time.Now()
+	res, err = cli.Start(cctx, &pluginv1.StartRequest{InstanceId: id})
+	cancel()
+	if !r.operation("Start", res, err, time.Since(start)) {
+		return
+	}
+	if !r.waitState(ctx, cli, id, "GetInstance(running)", sc.wait(), func(state int32) bool { return state == 2 }) {
+		return
+	}
+	r.checkInstanceFeatures(ctx, cli, features, id)
+}
+
+func (r *runner) checkCatalog(ctx context.Context, cli pluginv1.AutomationServiceClient, sc *automationScenario) {
+	start := time.Now()
+	cctx, cancel := r.callCtx(ctx)
+	areas, err := cli.ListAreas(cctx, &pluginv1.Empty{})
+	cancel()
+	if err != nil {
+		r.rep.fail(groupAutomation, "ListAreas", err.Error(), time.Since(start))
+	} else {
+		r.rep.add(groupAutomation, "ListAreas", statusPass, fmt.Sprintf("%d areas", len(areas.GetItems())), time.Since(start))
+	}
+
+	start = time.Now()
+	cctx, cancel = r.callCtx(ctx)
+	lines, err := cli.ListLines(cctx, &pluginv1.Empty{})
+	cancel()
+	if err != nil {
+		r.rep.fail(groupAutomation, "ListLines", err.Error(), time.Since(start))
+		return
+	}
+	if len(lines.GetItems()) == 0 {
+		r.rep.warn(groupAutomation, "ListLines", "no lines returned", time.Since(start))
+	} else {
+		r.rep.add(groupAutomation, "ListLines", statusPass, fmt.Sprintf("%d lines", len(lines.GetItems())), time.Since(start))
+	}
+
+	var lineID int64
+	if sc != nil {
+		lineID = sc.LineID
+	}
+	if lineID <= 0 && len(lines.GetItems()) > 0 {
+		lineID = lines.GetItems()[0].GetId()
+	}
+	if lineID <= 0 {
+		r.rep.skip(groupAutomation, "ListImages", "no line to query")
+		r.rep.skip(groupAutomation, "ListPackages", "no line to query")
+		return
+	}
+
+	start = time.Now()
+	cctx, cancel = r.callCtx(ctx)
+	images, err := cli.ListImages(cctx, &pluginv1.ListImagesRequest{LineId: lineID})
+	cancel()
+	if err != nil {
+		r.rep.fail(groupAutomation, "ListImages", err.Error(), time.Since(start))
+	} else {
+		r.rep.add(groupAutomation, "ListImages", statusPass, fmt.Sprintf("line %d: %d images", lineID, len(images.GetItems())), time.Since(start))
+	}
+
+	start = time.Now()
+	cctx, cancel = r.callCtx(ctx)
+	packages, err := cli.ListPackages(cctx, &pluginv1.ListPackagesRequest{LineId: lineID})
+	cancel()
+	switch {
+	case isUnimplemented(err):
+		r.rep.warn(groupAutomation, "ListPackages", "unimplemented", time.Since(start))
+	case err != nil:
+		r.rep.fail(groupAutomation, "ListPackages", err.Error(), time.Since(start))
+	default:
+		r.rep.add(groupAutomation, "ListPackages", statusPass, fmt.Sprintf("line %d: %d packages", lineID, len(packages.GetItems())), time.Since(start))
+	}
+}
+
+// checkInstanceFeatures calls the read-only RPC of every optional feature the
+// plugin declares; a declared feature must not answer Unimplemented.
+func (r *runner) checkInstanceFeatures(ctx context.Context, cli pluginv1.AutomationServiceClient, features map[pluginv1.AutomationFeature]bool, id int64) {
+	checks := []struct {
+		feature pluginv1.AutomationFeature
+		name    string
+		call    func(context.Context) error
+	}{
+		{pluginv1.AutomationFeature_AUTOMATION_FEATURE_PORT_MAPPING, "ListPortMappings", func(ctx context.Context) error {
+			_, err := cli.ListPortMappings(ctx, &pluginv1.ListPortMappingsRequest{InstanceId: id})
+			return err
+		}},
+		{pluginv1.AutomationFeature_AUTOMATION_FEATURE_BACKUP, "ListBackups", func(ctx context.Context) error {
+			_, err := cli.ListBackups(ctx, &pluginv1.ListBackupsRequest{InstanceId: id})
+			return err
+		}},
+		{pluginv1.AutomationFeature_AUTOMATION_FEATURE_SNAPSHOT, "ListSnapshots", func(ctx context.Context) error {
+			_, err := cli.ListSnapshots(ctx, &pluginv1.ListSnapshotsRequest{InstanceId: id})
+			return err
+		}},
+		{pluginv1.AutomationFeature_AUTOMATION_FEATURE_FIREWALL, "ListFirewallRules", func(ctx context.Context) error {
+			_, err := cli.ListFirewallRules(ctx, &pluginv1.ListFirewallRulesRequest{InstanceId: id})
+			return err
+		}},
+		// Resetting the PTR of the test instance is the least intrusive write.
+		{pluginv1.AutomationFeature_AUTOMATION_FEATURE_RDNS, "SetReverseDNS", func(ctx context.Context) error {
+			inst, err := cli.GetInstance(ctx, &pluginv1.GetInstanceRequest{InstanceId: id})
+			if err != nil {
+				return err
+			}
+			ips := inst.GetInstance().GetIps()
+			if len(ips) == 0 {
+				return fmt.Errorf("rdns declared but instance reports no ips")
+			}
+			res, err := cli.SetReverseDNS(ctx, &pluginv1.SetReverseDNSRequest{InstanceId: id, Ip: ips[0].GetAddress()})
+			if err != nil {
+				return err
+			}
+			if !res.GetOk() {
+				return fmt.Errorf("%s: %s", res.GetErrorCode(), res.GetErrorMessage())
+			}
+			return nil
+		}},
+	}
+	for _, c := range checks {
+		if !features[c.feature] {
+			continue
+		}
+		start := time.Now()
+		cctx, cancel := r.callCtx(ctx)
+		err := c.call(cctx)
+		cancel()
+		switch {
+		case isUnimplemented(err):
+			r.rep.fail(groupAutomation, c.name, "feature declared but RPC is unimplemented", time.Since(start))
+		case err != nil:
+			r.rep.fail(groupAutomation, c.name, err.Error(), time.Since(start))
+		default:
+			r.rep.pass(groupAutomation, c.name, time.Since(start))
+		}
+	}
+}
+
+func (r *runner) destroy(ctx context.Context, cli pluginv1.AutomationServiceClient, id int64) {
+	start := time.Now()
+	cctx, cancel := r.callCtx(ctx)
+	res, err := cli.Destroy(cctx, &pluginv1.DestroyRequest{InstanceId: id})
+	cancel()
+	r.operation("Destroy", res, err, time.Since(start))
+}
+
+// operation records an OperationResult the way the host interprets it: a
+// zero-value result from legacy plugins counts as success.
+func (r *runner) operation(name string, res *pluginv1.OperationResult, err error, elapsed time.Duration) bool {
+	switch {
+	case err != nil:
+		r.rep.fail(groupAutomation, name, err.Error(), elapsed)
+		return false
+	case res.GetOk():
+		r.rep.pass(groupAutomation, name, elapsed)
+	case strings.TrimSpace(res.GetErrorCode()) == "" && strings.TrimSpace(res.GetErrorMessage()) == "":
+		r.rep.warn(groupAutomation, name, "empty OperationResult, set ok=true", elapsed)
+	default:
+		r.rep.fail(groupAutomation, name, strings.TrimSpace(res.GetErrorMessage()+" "+res.GetErrorCode()), elapsed)
+		return false
+	}
+	return true
+}
+
+// waitState polls GetInstance until done reports true or wait elapses.
+func (r *runner) waitState(ctx context.Context, cli pluginv1.AutomationServiceClient, id int64, name string, wait time.Duration, done func(int32) bool) bool {
+	start := time.Now()
+	deadline := start.Add(wait)
+	var lastState int32 = -1
+	for {
+		cctx, cancel := r.callCtx(ctx)
+		resp, err := cli.GetInstance(cctx, &pluginv1.GetInstanceRequest{InstanceId: id})
+		cancel()
+		if err != nil {
+			r.rep.fail(groupAutomation, name, err.Error(), time.Since(start))
+			return false
+		}
+		inst := resp.GetInstance()
+		if inst.GetId() != 0 && inst.GetId() != id {
+			r.rep.fail(groupAutomation, name, fmt.Sprintf("returned instance %d, want %d", inst.GetId(), id), time.Since(start))
+			return false
+		}
+		lastState = inst.GetState()
+		if done(lastState) {
+			r.rep.add(groupAutomation, name, statusPass, fmt.Sprintf("state=%d", lastState), time.Since(start))
+			return true
+		}
+		if time.Now().After(deadline) {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			r.rep.fail(groupAutomation, name, ctx.Err().Error(), time.Since(start))
+			return false
+		case <-time.After(r.opts.PollInterval):
+		}
+	}
+	r.rep.fail(groupAutomation, name, fmt.Sprintf("timed out, last state=%d", lastState), time.Since(start))
+	return false
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"xiaoheiplay/pkg/pluginsdk"
+	pluginv1 "xiaoheiplay/plugin/v1"
+)
+
+const (
+	groupSMS    = "sms"
+	groupKYC    = "kyc"
+	groupNotify = "notify"
+)
+
+// SMS, KYC and notify calls reach real users, so they only run when the
+// scenario supplies the request.
+
+func (r *runner) checkSMS(ctx context.Context) {
+	cli, err := dispense[pluginv1.SmsServiceClient](r.rpc, pluginsdk.PluginKeySMS)
+	if err != nil {
+		r.rep.fail(groupSMS, "dispense", err.Error(), 0)
+		return
+	}
+	if len(r.opts.Scenario.SMS) == 0 {
+		r.rep.skip(groupSMS, "Send", "no sms scenario")
+		return
+	}
+	req := &pluginv1.SendSmsRequest{}
+	if err := decodeMessage(r.opts.Scenario.SMS, req); err != nil {
+		r.rep.fail(groupSMS, "Send", err.Error(), 0)
+		return
+	}
+	start := time.Now()
+	cctx, cancel := r.callCtx(ctx)
+	resp, err := cli.Send(cctx, req)
+	cancel()
+	if detail := responseError(err, resp.GetOk(), resp.GetError()); detail != "" {
+		r.rep.fail(groupSMS, "Send", detail, time.Since(start))
+		return
+	}
+	r.rep.add(groupSMS, "Send", statusPass, "message_id="+resp.GetMessageId(), time.Since(start))
+}
+
+func (r *runner) checkKYC(ctx context.Context) {
+	cli, err := dispense[pluginv1.KycServiceClient](r.rpc, pluginsdk.PluginKeyKYC)
+	if err != nil {
+		r.rep.fail(groupKYC, "dispense", err.Error(), 0)
+		return
+	}
+	if len(r.opts.Scenario.KYC) == 0 {
+		r.rep.skip(groupKYC, "Start", "no kyc scenario")
+		return
+	}
+	req := &pluginv1.KycStartRequest{}
+	if err := decodeMessage(r.opts.Scenario.KYC, req); err != nil {
+		r.rep.fail(groupKYC, "Start", err.Error(), 0)
+		return
+	}
+	start := time.Now()
+	cctx, cancel := r.callCtx(ctx)
+	started, err := cli.Start(cctx, req)
+	cancel()
+	if detail := responseError(err, started.GetOk(), started.GetError()); detail != "" {
+		r.rep.fail(groupKYC, "Start", detail, time.Since(start))
+		return
+	}
+	if started.GetToken() == "" {
+		r.rep.fail(groupKYC, "Start", "empty token", time.Since(start))
+		return
+	}
+	r.rep.add(groupKYC, "Start", statusPass, "token="+started.GetToken(), time.Since(start))
+
+	start = time.Now()
+	cctx, cancel = r.callCtx(ctx)
+	result, err := cli.QueryResult(cctx, &pluginv1.KycQueryRequest{Token: started.GetToken()})
+	cancel()
+	if detail := responseError(err, result.GetOk(), result.GetError()); detail != "" {
+		r.rep.fail(groupKYC, "QueryResult", detail, time.Since(start))
+		return
+	}
+	r.rep.add(groupKYC, "QueryResult", statusPass, "status="+result.GetStatus(), time.Since(start))
+}
+
+func (r *runner) checkNotify(ctx context.Context) {
+	cli, err := dispense[pluginv1.NotifyServiceClient](r.rpc, pluginsdk.PluginKeyNotify)
+	if err != nil {
+		r.rep.fail(groupNotify, "dispense", err.Error(), 0)
+		return
+	}
+	if len(r.opts.Scenario.Notify) == 0 {
+		r.rep.skip(groupNotify, "Send", "no notify scenario")
+		return
+	}
+	req := &pluginv1.SendNotifyRequest{}
+	if err := decodeMessage(r.opts.Scenario.Notify, req); err != nil {
+		r.rep.fail(groupNotify, "Send", err.Error(), 0)
+		return
+	}
+	if req.Type == "" {
+		req.Type = "plugintest"
+	}
+	if req.Title == "" {
+		req.Title = "plugintest"
+	}
+	if req.Content == "" {
+		req.Content = "plugintest"
+	}
+	start := time.Now()
+	cctx, cancel := r.callCtx(ctx)
+	resp, err := cli.Send(cctx, req)
+	cancel()
+	if detail := responseError(err, resp.GetOk(), resp.GetError()); detail != "" {
+		r.rep.fail(groupNotify, "Send", detail, time.Since(start))
+		return
+	}
+	r.rep.pass(groupNotify, "Send", time.Since(start))
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"xiaoheiplay/pkg/pluginsdk"
+	pluginv1 "xiaoheiplay/plugin/v1"
+)
+
+const groupPayment = "payment"
+
+func (r *runner) checkPayment(ctx context.Context, capability *pluginv1.PaymentCapability) {
+	cli, err := dispense[pluginv1.PaymentServiceClient](r.rpc, pluginsdk.PluginKeyPayment)
+	if err != nil {
+		r.rep.fail(groupPayment, "dispense", err.Error(), 0)
+		return
+	}
+
+	start := time.Now()
+	cctx, cancel := r.callCtx(ctx)
+	listed, err := cli.ListMethods(cctx, &pluginv1.Empty{})
+	cancel()
+	if detail := responseError(err, listed.GetOk(), listed.GetError()); detail != "" {
+		r.rep.fail(groupPayment, "ListMethods", detail, time.Since(start))
+	} else {
+		have := map[string]bool{}
+		for _, m := range listed.GetMethods() {
+			have[m] = true
+		}
+		var missing []string
+		for _, m := range capability.GetMethods() {
+			if !have[m] {
+				missing = append(missing, m)
+			}
+		}
+		if len(missing) > 0 {
+			r.rep.fail(groupPayment, "ListMethods", "declared but not listed: "+strings.Join(missing, ", "), time.Since(start))
+		} else {
+			r.rep.pass(groupPayment, "ListMethods", time.Since(start))
+		}
+	}
+
+	methods := capability.GetMethods()
+	sc := r.opts.Scenario.Payment
+	if sc != nil && strings.TrimSpace(sc.Method) != "" {
+		methods = []string{strings.TrimSpace(sc.Method)}
+	}
+	for _, method := range methods {
+		r.checkPaymentMethod(ctx, cli, method, sc)
+	}
+}
+
+func (r *runner) checkPaymentMethod(ctx context.Context, cli pluginv1.PaymentServiceClient, method string, sc *paymentScenario) {
+	group := groupPayment + ":" + method
+	orderNo := "PT" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	tradeNo := ""
+	var amount int64 = 1
+
+	if sc == nil {
+		r.rep.skip(group, "CreatePayment", "no payment scenario")
+	} else {
+		in := &pluginv1.PaymentCreateRequest{}
+		if err := decodeMessage(sc.Request, in); err != nil {
+			r.rep.fail(group, "CreatePayment", err.Error(), 0)
+			return
+		}
+		if in.OrderNo == "" {
+			in.OrderNo = orderNo
+		}
+		if in.Amount <= 0 {
+			in.Amount = amount
+		}
+		if in.Currency == "" {
+			in.Currency = "CNY"
+		}
+		if in.Subject == "" {
+			in.Subject = "plugintest"
+		}
+		if in.NotifyUrl == "" {
+			in.NotifyUrl = "http://127.0.0.1/plugintest/notify"
+		}
+		if in.ReturnUrl == "" {
+			in.ReturnUrl = "http://127.0.0.1/plugintest/return"
+		}
+		orderNo, amount = in.OrderNo, in.Amount
+
+		start := time.Now()
+		cctx, cancel := r.callCtx(ctx)
+		created, err := cli.CreatePayment(cctx, &pluginv1.CreatePaymentRpcRequest{Method: method, Request: in})
+		cancel()
+		switch detail := responseError(err, created.GetOk(), created.GetError()); {
+		case detail != "":
+			r.rep.fail(group, "CreatePayment", detail, time.Since(start))
+		case created.GetPayUrl() == "" && created.GetTradeNo() == "":
+			r.rep.warn(group, "CreatePayment", "neither pay_url nor trade_no returned", time.Since(start))
+		default:
+			tradeNo = created.GetTradeNo()
+			r.rep.add(group, "CreatePayment", statusPass, "trade_no="+tradeNo, time.Since(start))
+		}
+
+		start = time.Now()
+		cctx, cancel = r.callCtx(ctx)
+		queried, err := cli.QueryPayment(cctx, &pluginv1.QueryPaymentRpcRequest{Method: method, TradeNo: tradeNo, OrderNo: orderNo})
+		cancel()
+		if detail := responseError(err, queried.GetOk(), queried.GetError()); detail != "" {
+			r.rep.warn(group, "QueryPayment", detail, time.Since(start))
+		} else {
+			r.rep.add(group, "QueryPayment", statusPass, "status="+queried.GetStatus().String(), time.Since(start))
+		}
+	}
+
+	// A callback nobody signed must never be reported as paid.
+	form := url.Values{}
+	form.Set("order_no", orderNo)
+	form.Set("out_trade_no", orderNo)
+	form.Set("trade_no", tradeNo)
+	form.Set("amount", strconv.FormatInt(amount, 10))
+	form.Set("status", "paid")
+	form.Set("trade_status", "TRADE_SUCCESS")
+	forged := &pluginv1.RawHttpRequest{
+		Method: "POST",
+		Path:   "/notify",
+		Headers: map[string]*pluginv1.StringList{
+			"Content-Type": {Values: []string{"application/x-www-form-urlencoded"}},
+		},
+		Body: []byte(form.Encode()),
+	}
+	start := time.Now()
+	cctx, cancel := r.callCtx(ctx)
+	verified, err := cli.VerifyNotify(cctx, &pluginv1.VerifyNotifyRequest{Method: method, Raw: forged})
+	cancel()
+	if err == nil && verified.GetOk() && verified.GetStatus() == pluginv1.PaymentStatus_PAYMENT_STATUS_PAID {
+		r.rep.fail(group, "VerifyNotify(forged)", "unsigned callback accepted as paid", time.Since(start))
+	} else {
+		r.rep.pass(group, "VerifyNotify(forged)", time.Since(start))
+	}
+
+	if sc == nil || len(sc.Notify) == 0 {
+		r.rep.skip(group, "VerifyNotify", "no captured notify in scenario")
+		return
+	}
+	raw := &pluginv1.RawHttpRequest{}
+	if err := decodeMessage(sc.Notify, raw); err != nil {
+		r.rep.fail(group, "VerifyNotify", err.Error(), 0)
+		return
+	}
+	start = time.Now()
+	cctx, cancel = r.callCtx(ctx)
+	verified, err = cli.VerifyNotify(cctx, &pluginv1.VerifyNotifyRequest{Method: method, Raw: raw})
+	cancel()
+	if detail := responseError(err, verified.GetOk(), verified.GetError()); detail != "" {
+		r.rep.fail(group, "VerifyNotify", detail, time.Since(start))
+		return
+	}
+	r.rep.add(group, "VerifyNotify", statusPass,
+		fmt.Sprintf("order_no=%s status=%s", verified.GetOrderNo(), verified.GetStatus()), time.Since(start))
+}
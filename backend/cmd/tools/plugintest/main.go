@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-plugin"
+
+	plugins "xiaoheiplay/internal/adapter/plugins/core"
+	"xiaoheiplay/pkg/pluginsdk"
+)
+
+func main() {
+	dir := flag.String("dir", "", "plugin directory (with manifest.json); the binary for the current platform is used")
+	bin := flag.String("bin", "", "plugin binary to launch directly (manifest.json consistency is not checked)")
+	configPath := flag.String("config", "", "instance config JSON file passed to ValidateConfig/Init")
+	scenarioPath := flag.String("scenario", "", "scenario JSON file; side-effecting checks are skipped without it")
+	instanceID := flag.String("instance", "plugintest", "instance id passed to Init/Health")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout of a single RPC")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	if strings.TrimSpace(*dir) == "" && strings.TrimSpace(*bin) == "" {
+		fmt.Fprintln(os.Stderr, "missing -dir or -bin")
+		os.Exit(2)
+	}
+
+	opts := options{
+		ConfigJSON: "{}",
+		InstanceID: strings.TrimSpace(*instanceID),
+		Timeout:    *timeout,
+	}
+	if p := strings.TrimSpace(*configPath); p != "" {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "read -config:", err)
+			os.Exit(2)
+		}
+		opts.ConfigJSON = string(b)
+	}
+	if p := strings.TrimSpace(*scenarioPath); p != "" {
+		sc, err := loadScenario(p)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "read -scenario:", err)
+			os.Exit(2)
+		}
+		opts.Scenario = sc
+	}
+
+	entry := strings.TrimSpace(*bin)
+	workDir := filepath.Dir(entry)
+	if d := strings.TrimSpace(*dir); d != "" {
+		manifest, err := plugins.ReadManifest(d)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "manifest.json:", err)
+			os.Exit(2)
+		}
+		opts.Manifest = &manifest
+		if entry == "" {
+			info, err := plugins.ResolveEntry(d, manifest)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "resolve entry:", err)
+				os.Exit(2)
+			}
+			entry = info.EntryPath
+		}
+		workDir = d
+	}
+
+	client := launch(entry, workDir)
+	defer client.Kill()
+	rpc, err := client.Client()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "handshake:", err)
+		os.Exit(1)
+	}
+
+	rep := run(context.Background(), rpc, opts)
+	if *asJSON {
+		err = rep.writeJSON(os.Stdout)
+	} else {
+		err = rep.writeText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "write report:", err)
+		os.Exit(1)
+	}
+	if rep.Failed() {
+		os.Exit(1)
+	}
+}
+
+// launch starts the plugin with the same handshake and plugin set the host
+// runtime uses.
+func launch(entry, workDir string) *plugin.Client {
+	cmd := exec.Command(entry)
+	cmd.Dir = workDir
+	return plugin.NewClient(&plugin.ClientConfig{
+		HandshakeConfig:  pluginsdk.Handshake,
+		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
+		Plugins:          pluginSet(),
+		Cmd:              cmd,
+	})
+}
+
+func pluginSet() map[string]plugin.Plugin {
+	return map[string]plugin.Plugin{
+		pluginsdk.PluginKeyCore:       &pluginsdk.CoreGRPCPlugin{},
+		pluginsdk.PluginKeySMS:        &pluginsdk.SmsGRPCPlugin{},
+		pluginsdk.PluginKeyPayment:    &pluginsdk.PaymentGRPCPlugin{},
+		pluginsdk.PluginKeyKYC:        &pluginsdk.KycGRPCPlugin{},
+		pluginsdk.PluginKeyAutomation: &pluginsdk.AutomationGRPCPlugin{},
+		pluginsdk.PluginKeyNotify:     &pluginsdk.NotifyGRPCPlugin{},
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	statusPass = "pass"
+	statusWarn = "warn"
+	statusFail = "fail"
+	statusSkip = "skip"
+)
+
+type checkResult struct {
+	Group    string `json:"group"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Duration int64  `json:"duration_ms"`
+}
+
+type report struct {
+	PluginID string        `json:"plugin_id"`
+	Name     string        `json:"name"`
+	Version  string        `json:"version"`
+	Checks   []checkResult `json:"checks"`
+}
+
+func (r *report) add(group, name, status, detail string, elapsed time.Duration) {
+	r.Checks = append(r.Checks, checkResult{
+		Group:    group,
+		Name:     name,
+		Status:   status,
+		Detail:   strings.TrimSpace(detail),
+		Duration: elapsed.Milliseconds(),
+	})
+}
+
+func (r *report) pass(group, name string, elapsed time.Duration) {
+	r.add(group, name, statusPass, "", elapsed)
+}
+
+func (r *report) warn(group, name, detail string, elapsed time.Duration) {
+	r.add(group, name, statusWarn, detail, elapsed)
+}
+
+func (r *report) fail(group, name, detail string, elapsed time.Duration) {
+	r.add(group, name, statusFail, detail, elapsed)
+}
+
+func (r *report) skip(group, name, detail string) {
+	r.add(group, name, statusSkip, detail, 0)
+}
+
+func (r *report) count(status string) int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *report) Failed() bool {
+	return r.count(statusFail) > 0
+}
+
+func (r *report) writeText(w io.Writer) error {
+	fmt.Fprintf(w, "plugin: %s %s (%s)\n\n", r.PluginID, r.Version, r.Name)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "GROUP\tCHECK\tRESULT\tTIME\tDETAIL")
+	for _, c := range r.Checks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%dms\t%s\n", c.Group, c.Name, strings.ToUpper(c.Status), c.Duration, c.Detail)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	verdict := "COMPATIBLE"
+	if r.Failed() {
+		verdict = "NOT COMPATIBLE"
+	}
+	_, err := fmt.Fprintf(w, "\n%d passed, %d warnings, %d failed, %d skipped: %s\n",
+		r.count(statusPass), r.count(statusWarn), r.count(statusFail), r.count(statusSkip), verdict)
+	return err
+}
+
+func (r *report) writeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		*report
+		Compatible bool `json:"compatible"`
+	}{r, !r.Failed()})
+}
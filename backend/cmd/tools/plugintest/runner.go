@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	plugins "xiaoheiplay/internal/adapter/plugins/core"
+	"xiaoheiplay/pkg/pluginsdk"
+	pluginv1 "xiaoheiplay/plugin/v1"
+)
+
+const groupCore = "core"
+
+type options struct {
+	ConfigJSON string
+	InstanceID string
+	Timeout    time.Duration
+	Scenario   scenario
+	// Manifest is the plugin's manifest.json, compared against GetManifest.
+	Manifest *plugins.Manifest
+	// PollInterval is the delay between GetInstance polls (default 5s).
+	PollInterval time.Duration
+}
+
+type runner struct {
+	rpc  plugin.ClientProtocol
+	opts options
+	rep  *report
+}
+
+// run executes the core checks and then the scenarios of every capability
+// the plugin declares.
+func run(ctx context.Context, rpc plugin.ClientProtocol, opts options) *report {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	if strings.TrimSpace(opts.ConfigJSON) == "" {
+		opts.ConfigJSON = "{}"
+	}
+	r := &runner{rpc: rpc, opts: opts, rep: &report{}}
+	manifest := r.checkCore(ctx)
+	if manifest == nil {
+		return r.rep
+	}
+	if manifest.Automation != nil {
+		r.checkAutomation(ctx, manifest.Automation)
+	}
+	if manifest.Payment != nil {
+		r.checkPayment(ctx, manifest.Payment)
+	}
+	if manifest.Sms != nil {
+		r.checkSMS(ctx)
+	}
+	if manifest.Kyc != nil {
+		r.checkKYC(ctx)
+	}
+	if manifest.Notify != nil {
+		r.checkNotify(ctx)
+	}
+	return r.rep
+}
+
+func (r *runner) callCtx(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, r.opts.Timeout)
+}
+
+func dispense[T any](rpc plugin.ClientProtocol, key string) (T, error) {
+	var zero T
+	raw, err := rpc.Dispense(key)
+	if err != nil {
+		return zero, err
+	}
+	cli, ok := raw.(T)
+	if !ok {
+		return zero, fmt.Errorf("invalid %s client", key)
+	}
+	return cli, nil
+}
+
+func (r *runner) checkCore(ctx context.Context) *pluginv1.Manifest {
+	core, err := dispense[pluginv1.CoreServiceClient](r.rpc, pluginsdk.PluginKeyCore)
+	if err != nil {
+		r.rep.fail(groupCore, "dispense", err.Error(), 0)
+		return nil
+	}
+
+	start := time.Now()
+	cctx, cancel := r.callCtx(ctx)
+	manifest, err := core.GetManifest(cctx, &pluginv1.Empty{})
+	cancel()
+	elapsed := time.Since(start)
+	switch {
+	case err != nil:
+		r.rep.fail(groupCore, "GetManifest", err.Error(), elapsed)
+		return nil
+	case strings.TrimSpace(manifest.GetPluginId()) == "":
+		r.rep.fail(groupCore, "GetManifest", "empty plugin_id", elapsed)
+		return nil
+	case manifest.Sms == nil && manifest.Payment == nil && manifest.Kyc == nil && manifest.Automation == nil && manifest.Notify == nil:
+		r.rep.fail(groupCore, "GetManifest", "no capability declared", elapsed)
+	case strings.TrimSpace(manifest.GetName()) == "" || strings.TrimSpace(manifest.GetVersion()) == "":
+		r.rep.warn(groupCore, "GetManifest", "name or version is empty", elapsed)
+	default:
+		r.rep.pass(groupCore, "GetManifest", elapsed)
+	}
+	r.rep.PluginID = manifest.GetPluginId()
+	r.rep.Name = manifest.GetName()
+	r.rep.Version = manifest.GetVersion()
+
+	if r.opts.Manifest == nil {
+		r.rep.skip(groupCore, "manifest.json", "no plugin directory given")
+	} else if err := plugins.ValidateManifestConsistency(*r.opts.Manifest, manifest); err != nil {
+		r.rep.fail(groupCore, "manifest.json", err.Error(), 0)
+	} else {
+		r.rep.pass(groupCore, "manifest.json", 0)
+	}
+
+	r.checkConfigSchema(ctx, core)
+	r.checkValidateConfig(ctx, core)
+
+	start = time.Now()
+	cctx, cancel = r.callCtx(ctx)
+	initResp, err := core.Init(cctx, &pluginv1.InitRequest{InstanceId: r.opts.InstanceID, ConfigJson: r.opts.ConfigJSON})
+	cancel()
+	if detail := responseError(err, initResp.GetOk(), initResp.GetError()); detail != "" {
+		r.rep.fail(groupCore, "Init", detail, time.Since(start))
+	} else {
+		r.rep.pass(groupCore, "Init", time.Since(start))
+	}
+
+	start = time.Now()
+	cctx, cancel = r.callCtx(ctx)
+	reloadResp, err := core.ReloadConfig(cctx, &pluginv1.ReloadConfigRequest{ConfigJson: r.opts.ConfigJSON})
+	cancel()
+	if detail := responseError(err, reloadResp.GetOk(), reloadResp.GetError()); detail != "" {
+		r.rep.fail(groupCore, "ReloadConfig", detail, time.Since(start))
+	} else {
+		r.rep.pass(groupCore, "ReloadConfig", time.Since(start))
+	}
+
+	start = time.Now()
+	cctx, cancel = r.callCtx(ctx)
+	health, err := core.Health(cctx, &pluginv1.HealthCheckRequest{InstanceId: r.opts.InstanceID})
+	cancel()
+	elapsed = time.Since(start)
+	switch {
+	case err != nil:
+		r.rep.fail(groupCore, "Health", err.Error(), elapsed)
+	case health.GetStatus() == pluginv1.HealthStatus_HEALTH_STATUS_OK:
+		r.rep.pass(groupCore, "Health", elapsed)
+	case health.GetStatus() == pluginv1.HealthStatus_HEALTH_STATUS_ERROR:
+		r.rep.fail(groupCore, "Health", "status ERROR: "+health.GetMessage(), elapsed)
+	default:
+		r.rep.warn(groupCore, "Health", "status "+health.GetStatus().String()+": "+health.GetMessage(), elapsed)
+	}
+	return manifest
+}
+
+func (r *runner) checkConfigSchema(ctx context.Context, core pluginv1.CoreServiceClient) {
+	start := time.Now()
+	cctx, cancel := r.callCtx(ctx)
+	schema, err := core.GetConfigSchema(cctx, &pluginv1.Empty{})
+	cancel()
+	elapsed := time.Since(start)
+	if err != nil {
+		r.rep.fail(groupCore, "GetConfigSchema", err.Error(), elapsed)
+		return
+	}
+	if strings.TrimSpace(schema.GetJsonSchema()) == "" {
+		r.rep.warn(groupCore, "GetConfigSchema", "empty json_schema, admin UI falls back to a raw JSON editor", elapsed)
+		return
+	}
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(schema.GetJsonSchema()), &obj); err != nil {
+		r.rep.fail(groupCore, "GetConfigSchema", "json_schema is not a JSON object: "+err.Error(), elapsed)
+		return
+	}
+	if ui := strings.TrimSpace(schema.GetUiSchema()); ui != "" && !json.Valid([]byte(ui)) {
+		r.rep.fail(groupCore, "GetConfigSchema", "ui_schema is not valid JSON", elapsed)
+		return
+	}
+	r.rep.pass(groupCore, "GetConfigSchema", elapsed)
+}
+
+func (r *runner) checkValidateConfig(ctx context.Context, core pluginv1.CoreServiceClient) {
+	start := time.Now()
+	cctx, cancel := r.callCtx(ctx)
+	resp, err := core.ValidateConfig(cctx, &pluginv1.ValidateConfigRequest{ConfigJson: r.opts.ConfigJSON})
+	cancel()
+	if detail := responseError(err, resp.GetOk(), resp.GetError()); detail != "" {
+		r.rep.fail(groupCore, "ValidateConfig", "config rejected: "+detail, time.Since(start))
+	} else {
+		r.rep.pass(groupCore, "ValidateConfig", time.Since(start))
+	}
+
+	start = time.Now()
+	cctx, cancel = r.callCtx(ctx)
+	resp, err = core.ValidateConfig(cctx, &pluginv1.ValidateConfigRequest{ConfigJson: "{"})
+	cancel()
+	if err == nil && resp.GetOk() {
+		r.rep.warn(groupCore, "ValidateConfig(malformed)", "malformed JSON accepted", time.Since(start))
+	} else {
+		r.rep.pass(groupCore, "ValidateConfig(malformed)", time.Since(start))
+	}
+}
+
+// responseError returns why an {ok, error} style response failed, or "".
+func responseError(err error, ok bool, msg string) string {
+	if err != nil {
+		return err.Error()
+	}
+	if ok {
+		return ""
+	}
+	if msg = strings.TrimSpace(msg); msg != "" {
+		return msg
+	}
+	return "ok=false"
+}
+
+func isUnimplemented(err error) bool {
+	return status.Code(err) == codes.Unimplemented
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-plugin"
+
+	plugins "xiaoheiplay/internal/adapter/plugins/core"
+	"xiaoheiplay/pkg/pluginsdk"
+	pluginv1 "xiaoheiplay/plugin/v1"
+)
+
+type fakeCore struct {
+	pluginv1.UnimplementedCoreServiceServer
+	manifest *pluginv1.Manifest
+}
+
+func (f *fakeCore) GetManifest(context.Context, *pluginv1.Empty) (*pluginv1.Manifest, error) {
+	return f.manifest, nil
+}
+
+func (f *fakeCore) GetConfigSchema(context.Context, *pluginv1.Empty) (*pluginv1.ConfigSchema, error) {
+	return &pluginv1.ConfigSchema{JsonSchema: `{"type":"object"}`, UiSchema: `{}`}, nil
+}
+
+func (f *fakeCore) ValidateConfig(_ context.Context, req *pluginv1.ValidateConfigRequest) (*pluginv1.ValidateConfigResponse, error) {
+	if !json.Valid([]byte(req.GetConfigJson())) {
+		return &pluginv1.ValidateConfigResponse{Ok: false, Error: "invalid json"}, nil
+	}
+	return &pluginv1.ValidateConfigResponse{Ok: true}, nil
+}
+
+func (f *fakeCore) Init(context.Context, *pluginv1.InitRequest) (*pluginv1.InitResponse, error) {
+	return &pluginv1.InitResponse{Ok: true}, nil
+}
+
+func (f *fakeCore) ReloadConfig(context.Context, *pluginv1.ReloadConfigRequest) (*pluginv1.ReloadConfigResponse, error) {
+	return &pluginv1.ReloadConfigResponse{Ok: true}, nil
+}
+
+func (f *fakeCore) Health(context.Context, *pluginv1.HealthCheckRequest) (*pluginv1.HealthCheckResponse, error) {
+	return &pluginv1.HealthCheckResponse{Status: pluginv1.HealthStatus_HEALTH_STATUS_OK}, nil
+}
+
+type fakeAutomation struct {
+	pluginv1.UnimplementedAutomationServiceServer
+	mu        sync.Mutex
+	state     int32
+	destroyed bool
+}
+
+func (f *fakeAutomation) ListAreas(context.Context, *pluginv1.Empty) (*pluginv1.ListAreasResponse, error) {
+	return &pluginv1.ListAreasResponse{Items: []*pluginv1.AutomationArea{{Id: 1, Name: "hk"}}}, nil
+}
+
+func (f *fakeAutomation) ListLines(context.Context, *pluginv1.Empty) (*pluginv1.ListLinesResponse, error) {
+	return &pluginv1.ListLinesResponse{Items: []*pluginv1.AutomationLine{{Id: 3, Name: "hk-1", AreaId: 1}}}, nil
+}
+
+func (f *fakeAutomation) ListImages(context.Context, *pluginv1.ListImagesRequest) (*pluginv1.ListImagesResponse, error) {
+	return &pluginv1.ListImagesResponse{Items: []*pluginv1.AutomationImage{{Id: 9, Name: "debian"}}}, nil
+}
+
+func (f *fakeAutomation) ListPackages(context.Context, *pluginv1.ListPackagesRequest) (*pluginv1.ListPackagesResponse, error) {
+	return &pluginv1.ListPackagesResponse{}, nil
+}
+
+func (f *fakeAutomation) CreateInstance(context.Context, *pluginv1.CreateInstanceRequest) (*pluginv1.CreateInstanceResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state = 1
+	return &pluginv1.CreateInstanceResponse{InstanceId: 7}, nil
+}
+
+func (f *fakeAutomation) GetInstance(_ context.Context, req *pluginv1.GetInstanceRequest) (*pluginv1.GetInstanceResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	state := f.state
+	if f.state == 1 {
+		f.state = 2
+	}
+	return &pluginv1.GetInstanceResponse{Instance: &pluginv1.AutomationInstance{Id: req.GetInstanceId(), State: state}}, nil
+}
+
+func (f *fakeAutomation) Shutdown(context.Context, *pluginv1.ShutdownRequest) (*pluginv1.OperationResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state = 3
+	return &pluginv1.OperationResult{Ok: true}, nil
+}
+
+func (f *fakeAutomation) Start(context.Context, *pluginv1.StartRequest) (*pluginv1.OperationResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state = 2
+	return &pluginv1.OperationResult{Ok: true}, nil
+}
+
+func (f *fakeAutomation) Destroy(context.Context, *pluginv1.DestroyRequest) (*pluginv1.OperationResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.destroyed = true
+	return &pluginv1.OperationResult{Ok: true}, nil
+}
+
+type fakePayment struct {
+	pluginv1.UnimplementedPaymentServiceServer
+	trustNotify bool
+}
+
+func (f *fakePayment) ListMethods(context.Context, *pluginv1.Empty) (*pluginv1.ListMethodsResponse, error) {
+	return &pluginv1.ListMethodsResponse{Ok: true, Methods: []string{"mock"}}, nil
+}
+
+func (f *fakePayment) CreatePayment(_ context.Context, req *pluginv1.CreatePaymentRpcRequest) (*pluginv1.PaymentCreateResponse, error) {
+	return &pluginv1.PaymentCreateResponse{Ok: true, TradeNo: "T-" + req.GetRequest().GetOrderNo(), PayUrl: "http://pay"}, nil
+}
+
+func (f *fakePayment) VerifyNotify(context.Context, *pluginv1.VerifyNotifyRequest) (*pluginv1.NotifyVerifyResult, error) {
+	if f.trustNotify {
+		return &pluginv1.NotifyVerifyResult{Ok: true, Status: pluginv1.PaymentStatus_PAYMENT_STATUS_PAID}, nil
+	}
+	return &pluginv1.NotifyVerifyResult{Ok: false, Error: "bad signature"}, nil
+}
+
+func serveFake(t *testing.T, core *fakeCore, automation *fakeAutomation, payment *fakePayment) plugin.ClientProtocol {
+	t.Helper()
+	client, server := plugin.TestPluginGRPCConn(t, false, map[string]plugin.Plugin{
+		pluginsdk.PluginKeyCore:       &pluginsdk.CoreGRPCPlugin{Impl: core},
+		pluginsdk.PluginKeyAutomation: &pluginsdk.AutomationGRPCPlugin{Impl: automation},
+		pluginsdk.PluginKeyPayment:    &pluginsdk.PaymentGRPCPlugin{Impl: payment},
+	})
+	t.Cleanup(func() {
+		_ = client.Close()
+		server.Stop()
+	})
+	return client
+}
+
+func findCheck(rep *report, group, name string) checkResult {
+	for _, c := range rep.Checks {
+		if c.Group == group && c.Name == name {
+			return c
+		}
+	}
+	return checkResult{}
+}
+
+func TestRun_CompatiblePlugin(t *testing.T) {
+	automation := &fakeAutomation{}
+	rpc := serveFake(t, &fakeCore{manifest: &pluginv1.Manifest{
+		PluginId: "fake",
+		Name:     "Fake",
+		Version:  "1.0.0",
+		Automation: &pluginv1.AutomationCapability{Features: []pluginv1.AutomationFeature{
+			pluginv1.AutomationFeature_AUTOMATION_FEATURE_CATALOG_SYNC,
+			pluginv1.AutomationFeature_AUTOMATION_FEATURE_LIFECYCLE,
+		}},
+		Payment: &pluginv1.PaymentCapability{Methods: []string{"mock"}},
+	}}, automation, &fakePayment{})
+
+	rep := run(context.Background(), rpc, options{
+		Timeout:      5 * time.Second,
+		PollInterval: time.Millisecond,
+		Scenario: scenario{
+			Automation: &automationScenario{Create: json.RawMessage(`{"line_id":3,"image_id":9,"name":"pt","ssh_public_keys":[]}`), WaitSeconds: 5},
+			Payment:    &paymentScenario{},
+		},
+	})
+	if rep.Failed() {
+		var b strings.Builder
+		_ = rep.writeText(&b)
+		t.Fatalf("expected compatible plugin:\n%s", b.String())
+	}
+	for _, name := range []string{"CreateInstance", "GetInstance(ready)", "Shutdown", "GetInstance(stopped)", "Start", "GetInstance(running)", "Destroy"} {
+		if c := findCheck(rep, groupAutomation, name); c.Status != statusPass {
+			t.Fatalf("%s: %+v", name, c)
+		}
+	}
+	if !automation.destroyed {
+		t.Fatalf("expected instance to be destroyed")
+	}
+	if c := findCheck(rep, groupPayment+":mock", "CreatePayment"); c.Status != statusPass {
+		t.Fatalf("create payment: %+v", c)
+	}
+	if c := findCheck(rep, groupPayment+":mock", "QueryPayment"); c.Status != statusWarn {
+		t.Fatalf("expected unimplemented query to warn: %+v", c)
+	}
+}
+
+func TestRun_ReportsContractViolations(t *testing.T) {
+	rpc := serveFake(t, &fakeCore{manifest: &pluginv1.Manifest{
+		PluginId: "fake",
+		Name:     "Fake",
+		Version:  "1.0.1",
+		Automation: &pluginv1.AutomationCapability{Features: []pluginv1.AutomationFeature{
+			pluginv1.AutomationFeature_AUTOMATION_FEATURE_LIFECYCLE,
+			pluginv1.AutomationFeature_AUTOMATION_FEATURE_SNAPSHOT,
+		}},
+		Payment: &pluginv1.PaymentCapability{Methods: []string{"mock", "card"}},
+	}}, &fakeAutomation{}, &fakePayment{trustNotify: true})
+
+	var manifest plugins.Manifest
+	if err := json.Unmarshal([]byte(`{"plugin_id":"fake","name":"Fake","version":"1.0.0","capabilities":{"automation":{"features":["lifecycle","snapshot"]},"payment":{"methods":["mock","card"]}}}`), &manifest); err != nil {
+		t.Fatalf("manifest: %v", err)
+	}
+	rep := run(context.Background(), rpc, options{
+		Timeout:      5 * time.Second,
+		PollInterval: time.Millisecond,
+		Manifest:     &manifest,
+		Scenario: scenario{
+			Automation: &automationScenario{Create: json.RawMessage(`{"line_id":3}`), WaitSeconds: 5},
+		},
+	})
+	if !rep.Failed() {
+		t.Fatalf("expected failures")
+	}
+	want := map[[2]string]string{
+		{groupCore, "manifest.json"}:                     statusFail,
+		{groupAutomation, "catalog"}:                     statusSkip,
+		{groupAutomation, "ListSnapshots"}:               statusFail,
+		{groupAutomation, "Destroy"}:                     statusPass,
+		{groupPayment, "ListMethods"}:                    statusFail,
+		{groupPayment + ":mock", "CreatePayment"}:        statusSkip,
+		{groupPayment + ":mock", "VerifyNotify(forged)"}: statusFail,
+		{groupPayment + ":card", "VerifyNotify(forged)"}: statusFail,
+	}
+	for key, status := range want {
+		if c := findCheck(rep, key[0], key[1]); c.Status != status {
+			t.Fatalf("%s/%s: want %s, got %+v", key[0], key[1], status, c)
+		}
+	}
+}
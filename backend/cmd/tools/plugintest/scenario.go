@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// scenario holds the inputs for checks that talk to the upstream provider.
+// Request payloads use the protobuf JSON form of the plugin/v1 messages
+// (snake_case field names are accepted).
+type scenario struct {
+	Automation *automationScenario `json:"automation,omitempty"`
+	Payment    *paymentScenario    `json:"payment,omitempty"`
+	SMS        json.RawMessage     `json:"sms,omitempty"`
+	KYC        json.RawMessage     `json:"kyc,omitempty"`
+	Notify     json.RawMessage     `json:"notify,omitempty"`
+}
+
+type automationScenario struct {
+	// LineID is used for ListImages/ListPackages; defaults to the first line.
+	LineID int64 `json:"line_id,omitempty"`
+	// Create is a CreateInstanceRequest. Without it the lifecycle is skipped.
+	Create json.RawMessage `json:"create,omitempty"`
+	// WaitSeconds bounds each wait for an instance state (default 300).
+	WaitSeconds int `json:"wait_seconds,omitempty"`
+	// Keep leaves the instance running instead of destroying it.
+	Keep bool `json:"keep,omitempty"`
+}
+
+type paymentScenario struct {
+	// Method defaults to every method the manifest declares.
+	Method string `json:"method,omitempty"`
+	// Request is a PaymentCreateRequest; order_no and amount are filled in
+	// when empty.
+	Request json.RawMessage `json:"request,omitempty"`
+	// Notify is a RawHttpRequest captured from a real callback. When set,
+	// VerifyNotify must accept it.
+	Notify json.RawMessage `json:"notify,omitempty"`
+}
+
+func loadScenario(path string) (scenario, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return scenario{}, err
+	}
+	var sc scenario
+	if err := json.Unmarshal(b, &sc); err != nil {
+		return scenario{}, err
+	}
+	return sc, nil
+}
+
+func (a *automationScenario) wait() time.Duration {
+	if a == nil || a.WaitSeconds <= 0 {
+		return 300 * time.Second
+	}
+	return time.Duration(a.WaitSeconds) * time.Second
+}
+
+// decodeMessage fills msg from a protobuf JSON payload. An empty payload
+// leaves msg untouched; otherwise msg is reset first.
+func decodeMessage(raw json.RawMessage, msg proto.Message) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := protojson.Unmarshal(raw, msg); err != nil {
+		return fmt.Errorf("decode %s: %w", msg.ProtoReflect().Descriptor().Name(), err)
+	}
+	return nil
+}
@@ -1,11 +1,17 @@
 package email
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/base64"
 	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
 	"net"
 	"net/smtp"
+	"net/textproto"
 	"strings"
 	"time"
 
@@ -21,32 +27,106 @@ func NewSender(settings appports.SettingsRepository) *Sender {
 }
 
 func (s *Sender) Send(ctx context.Context, to string, subject string, body string) error {
-	enabled, _ := s.getSetting(ctx, "smtp_enabled")
-	if enabled != "" && enabled != "true" {
-		return fmt.Errorf("smtp disabled")
+	cfg, err := s.loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	msg := []byte(fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: %s; charset=UTF-8\r\n\r\n%s", cfg.from, to, subject, bodyContentType(body), body))
+	return s.deliver(ctx, cfg, to, msg)
+}
+
+// SendWithAttachments sends body as the first part of a multipart/mixed
+// message followed by the attachments, base64 encoded.
+func (s *Sender) SendWithAttachments(ctx context.Context, to string, subject string, body string, attachments []appports.EmailAttachment) error {
+	if len(attachments) == 0 {
+		return s.Send(ctx, to, subject, body)
+	}
+	cfg, err := s.loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: multipart/mixed; boundary=%q\r\n\r\n", cfg.from, to, subject, mw.Boundary())
+	bodyHeader := textproto.MIMEHeader{}
+	bodyHeader.Set("Content-Type", bodyContentType(body)+"; charset=UTF-8")
+	bodyHeader.Set("Content-Transfer-Encoding", "8bit")
+	part, err := mw.CreatePart(bodyHeader)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write([]byte(body)); err != nil {
+		return err
 	}
-	host, _ := s.getSetting(ctx, "smtp_host")
-	port, _ := s.getSetting(ctx, "smtp_port")
-	user, _ := s.getSetting(ctx, "smtp_user")
-	pass, _ := s.getSetting(ctx, "smtp_pass")
-	from, _ := s.getSetting(ctx, "smtp_from")
-	if host == "" || port == "" || from == "" {
-		return fmt.Errorf("smtp not configured")
+	for _, att := range attachments {
+		contentType := att.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", mime.FormatMediaType(contentType, map[string]string{"name": att.Name}))
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": att.Name}))
+		header.Set("Content-Transfer-Encoding", "base64")
+		part, err := mw.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		if err := writeBase64Lines(part, att.Content); err != nil {
+			return err
+		}
 	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+	return s.deliver(ctx, cfg, to, buf.Bytes())
+}
 
-	contentType := "text/plain"
-	if isHTMLContent(body) {
-		contentType = "text/html"
+type smtpConfig struct {
+	host string
+	port string
+	user string
+	pass string
+	from string
+}
+
+func (s *Sender) loadConfig(ctx context.Context) (smtpConfig, error) {
+	enabled, _ := s.getSetting(ctx, "smtp_enabled")
+	if enabled != "" && enabled != "true" {
+		return smtpConfig{}, fmt.Errorf("smtp disabled")
 	}
-	msg := []byte(fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: %s; charset=UTF-8\r\n\r\n%s", from, to, subject, contentType, body))
+	cfg := smtpConfig{}
+	cfg.host, _ = s.getSetting(ctx, "smtp_host")
+	cfg.port, _ = s.getSetting(ctx, "smtp_port")
+	cfg.user, _ = s.getSetting(ctx, "smtp_user")
+	cfg.pass, _ = s.getSetting(ctx, "smtp_pass")
+	cfg.from, _ = s.getSetting(ctx, "smtp_from")
+	if cfg.host == "" || cfg.port == "" || cfg.from == "" {
+		return smtpConfig{}, fmt.Errorf("smtp not configured")
+	}
+	return cfg, nil
+}
 
-	addr := fmt.Sprintf("%s:%s", host, port)
+func (s *Sender) deliver(ctx context.Context, cfg smtpConfig, to string, msg []byte) error {
+	addr := fmt.Sprintf("%s:%s", cfg.host, cfg.port)
 
 	// 端口465需要SSL连接，其他端口使用STARTTLS
-	if port == "465" {
-		return s.sendWithSSL(ctx, addr, host, user, pass, from, to, msg)
+	if cfg.port == "465" {
+		return s.sendWithSSL(ctx, addr, cfg.host, cfg.user, cfg.pass, cfg.from, to, msg)
+	}
+	return s.sendWithStartTLS(ctx, addr, cfg.host, cfg.user, cfg.pass, cfg.from, to, msg)
+}
+
+// writeBase64Lines 按 76 字符换行写入 base64，符合 RFC 2045
+func writeBase64Lines(w io.Writer, content []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	for len(encoded) > 76 {
+		if _, err := io.WriteString(w, encoded[:76]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
 	}
-	return s.sendWithStartTLS(ctx, addr, host, user, pass, from, to, msg)
+	_, err := io.WriteString(w, encoded+"\r\n")
+	return err
 }
 
 // sendWithSSL 使用SSL/TLS直接连接（端口465）
@@ -131,6 +211,13 @@ func (s *Sender) getSetting(ctx context.Context, key string) (string, error) {
 	return setting.ValueJSON, nil
 }
 
+func bodyContentType(body string) string {
+	if isHTMLContent(body) {
+		return "text/html"
+	}
+	return "text/plain"
+}
+
 func isHTMLContent(body string) bool {
 	lower := strings.ToLower(body)
 	return strings.Contains(lower, "<html") ||
@@ -1,7 +1,9 @@
 package email
 
 import (
+	"bytes"
 	"context"
+	"strings"
 	"testing"
 
 	"xiaoheiplay/internal/domain"
@@ -25,3 +27,15 @@ func TestIsHTMLContent(t *testing.T) {
 		t.Fatalf("expected non-html")
 	}
 }
+
+func TestWriteBase64Lines(t *testing.T) {
+	var buf strings.Builder
+	if err := writeBase64Lines(&buf, bytes.Repeat([]byte("a"), 120)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > 76 {
+			t.Fatalf("line too long: %d", len(line))
+		}
+	}
+}
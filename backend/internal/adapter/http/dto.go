@@ -74,6 +74,10 @@ type PlanGroupDTO struct {
 	Active            bool    `json:"active"`
 	Visible           bool    `json:"visible"`
 	CapacityRemaining int     `json:"capacity_remaining"`
+	CapacityReserved  int     `json:"capacity_reserved"`
+	CapacityAvailable int     `json:"capacity_available"`
+	SnapshotQuota     int     `json:"snapshot_quota"`
+	BackupQuota       int     `json:"backup_quota"`
 	SortOrder         int     `json:"sort_order"`
 }
 
@@ -95,6 +99,9 @@ type PackageDTO struct {
 	Active               bool    `json:"active"`
 	Visible              bool    `json:"visible"`
 	CapacityRemaining    int     `json:"capacity_remaining"`
+	CapacityReserved     int     `json:"capacity_reserved"`
+	CapacityAvailable    int     `json:"capacity_available"`
+	TrialDays            int     `json:"trial_days"`
 }
 
 type SystemImageDTO struct {
@@ -122,6 +129,22 @@ type BillingCycleDTO struct {
 	UpdatedAt  time.Time `json:"updated_at"`
 }
 
+// AddonDTO 附加商品（额外 IPv4、快照配额、备份计划），月价以元表示
+type AddonDTO struct {
+	ID           int64     `json:"id"`
+	PlanGroupID  int64     `json:"plan_group_id"`
+	Kind         string    `json:"kind"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	MonthlyPrice float64   `json:"monthly_price"`
+	Units        int       `json:"units"`
+	MaxQty       int       `json:"max_qty"`
+	Active       bool      `json:"active"`
+	SortOrder    int       `json:"sort_order"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
 type CartItemDTO struct {
 	ID        int64              `json:"id"`
 	UserID    int64              `json:"user_id"`
@@ -221,9 +244,23 @@ type PaymentSelectDTO struct {
 type WalletDTO struct {
 	UserID    int64     `json:"user_id"`
 	Balance   float64   `json:"balance"`
+	Frozen    float64   `json:"frozen"`
+	Available float64   `json:"available"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+type WalletHoldDTO struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	Amount    float64    `json:"amount"`
+	RefType   string     `json:"ref_type"`
+	RefID     int64      `json:"ref_id"`
+	Status    string     `json:"status"`
+	Note      string     `json:"note"`
+	CreatedAt time.Time  `json:"created_at"`
+	SettledAt *time.Time `json:"settled_at"`
+}
+
 type WalletTransactionDTO struct {
 	ID        int64     `json:"id"`
 	UserID    int64     `json:"user_id"`
@@ -269,10 +306,36 @@ type RealNameVerificationDTO struct {
 	Provider    string     `json:"provider"`
 	Reason      string     `json:"reason"`
 	RedirectURL string     `json:"redirect_url,omitempty"`
+	UploadIDs   []int64    `json:"upload_ids,omitempty"`
+	ReviewedBy  *int64     `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 	VerifiedAt  *time.Time `json:"verified_at"`
 }
 
+type CompanyVerificationDTO struct {
+	ID                  int64      `json:"id"`
+	UserID              int64      `json:"user_id"`
+	CompanyName         string     `json:"company_name"`
+	CreditCode          string     `json:"credit_code"`
+	LegalPersonName     string     `json:"legal_person_name"`
+	LegalPersonIDNumber string     `json:"legal_person_id_number"`
+	InvoiceTitle        string     `json:"invoice_title"`
+	TaxNumber           string     `json:"tax_number"`
+	InvoiceAddress      string     `json:"invoice_address"`
+	InvoicePhone        string     `json:"invoice_phone"`
+	InvoiceBank         string     `json:"invoice_bank"`
+	InvoiceBankAccount  string     `json:"invoice_bank_account"`
+	Status              string     `json:"status"`
+	Provider            string     `json:"provider"`
+	Reason              string     `json:"reason"`
+	UploadIDs           []int64    `json:"upload_ids,omitempty"`
+	ReviewedBy          *int64     `json:"reviewed_by,omitempty"`
+	ReviewedAt          *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	VerifiedAt          *time.Time `json:"verified_at"`
+}
+
 type ServerStatusDTO struct {
 	Hostname        string  `json:"hostname"`
 	OS              string  `json:"os"`
@@ -425,6 +488,7 @@ type UserTierDiscountRuleDTO struct {
 	RegionID         int64  `json:"region_id"`
 	PlanGroupID      int64  `json:"plan_group_id"`
 	PackageID        int64  `json:"package_id"`
+	AddonID          int64  `json:"addon_id"`
 	DiscountPermille int    `json:"discount_permille"`
 	FixedPrice       *int64 `json:"fixed_price"`
 	AddCorePermille  int    `json:"add_core_permille"`
@@ -462,6 +526,7 @@ type CouponProductRuleDTO struct {
 	RegionID         int64  `json:"region_id"`
 	PlanGroupID      int64  `json:"plan_group_id"`
 	PackageID        int64  `json:"package_id"`
+	AddonID          int64  `json:"addon_id"`
 	AddonCoreEnabled bool   `json:"addon_core_enabled"`
 	AddonMemEnabled  bool   `json:"addon_mem_enabled"`
 	AddonDiskEnabled bool   `json:"addon_disk_enabled"`
@@ -538,6 +603,7 @@ func toUserTierDiscountRuleDTO(item domain.UserTierDiscountRule) UserTierDiscoun
 		RegionID:         item.RegionID,
 		PlanGroupID:      item.PlanGroupID,
 		PackageID:        item.PackageID,
+		AddonID:          item.AddonID,
 		DiscountPermille: item.DiscountPermille,
 		FixedPrice:       item.FixedPrice,
 		AddCorePermille:  item.AddCorePermille,
@@ -631,6 +697,7 @@ func toCouponProductRuleDTO(item domain.CouponProductRule) CouponProductRuleDTO
 		RegionID:         item.RegionID,
 		PlanGroupID:      item.PlanGroupID,
 		PackageID:        item.PackageID,
+		AddonID:          item.AddonID,
 		AddonCoreEnabled: item.AddonCoreEnabled,
 		AddonMemEnabled:  item.AddonMemEnabled,
 		AddonDiskEnabled: item.AddonDiskEnabled,
@@ -701,6 +768,10 @@ func toPlanGroupDTO(plan domain.PlanGroup) PlanGroupDTO {
 		Active:            plan.Active,
 		Visible:           plan.Visible,
 		CapacityRemaining: plan.CapacityRemaining,
+		CapacityReserved:  plan.CapacityReserved,
+		CapacityAvailable: plan.CapacityAvailable(),
+		SnapshotQuota:     plan.SnapshotQuota,
+		BackupQuota:       plan.BackupQuota,
 		SortOrder:         plan.SortOrder,
 	}
 }
@@ -724,6 +795,9 @@ func toPackageDTO(pkg domain.Package) PackageDTO {
 		Active:               pkg.Active,
 		Visible:              pkg.Visible,
 		CapacityRemaining:    pkg.CapacityRemaining,
+		CapacityReserved:     pkg.CapacityReserved,
+		CapacityAvailable:    pkg.CapacityAvailable(),
+		TrialDays:            pkg.TrialDays,
 	}
 }
 
@@ -752,6 +826,23 @@ func toBillingCycleDTO(cycle domain.BillingCycle) BillingCycleDTO {
 	}
 }
 
+func toAddonDTO(addon domain.Addon) AddonDTO {
+	return AddonDTO{
+		ID:           addon.ID,
+		PlanGroupID:  addon.PlanGroupID,
+		Kind:         string(addon.Kind),
+		Name:         addon.Name,
+		Description:  addon.Description,
+		MonthlyPrice: centsToFloat(addon.Monthly),
+		Units:        addon.Units,
+		MaxQty:       addon.MaxQty,
+		Active:       addon.Active,
+		SortOrder:    addon.SortOrder,
+		CreatedAt:    addon.CreatedAt,
+		UpdatedAt:    addon.UpdatedAt,
+	}
+}
+
 func toCartItemDTO(item domain.CartItem) CartItemDTO {
 	return CartItemDTO{
 		ID:        item.ID,
@@ -882,10 +973,30 @@ func toWalletDTO(wallet domain.Wallet) WalletDTO {
 	return WalletDTO{
 		UserID:    wallet.UserID,
 		Balance:   centsToFloat(wallet.Balance),
+		Frozen:    centsToFloat(wallet.Frozen),
+		Available: centsToFloat(wallet.Available()),
 		UpdatedAt: wallet.UpdatedAt,
 	}
 }
 
+func toWalletHoldDTOs(items []domain.WalletHold) []WalletHoldDTO {
+	out := make([]WalletHoldDTO, 0, len(items))
+	for _, item := range items {
+		out = append(out, WalletHoldDTO{
+			ID:        item.ID,
+			UserID:    item.UserID,
+			Amount:    centsToFloat(item.Amount),
+			RefType:   item.RefType,
+			RefID:     item.RefID,
+			Status:    string(item.Status),
+			Note:      item.Note,
+			CreatedAt: item.CreatedAt,
+			SettledAt: item.SettledAt,
+		})
+	}
+	return out
+}
+
 func toWalletTransactionDTO(item domain.WalletTransaction) WalletTransactionDTO {
 	return WalletTransactionDTO{
 		ID:        item.ID,
@@ -954,11 +1065,39 @@ func toRealNameVerificationDTO(item domain.RealNameVerification) RealNameVerific
 		Provider:    item.Provider,
 		Reason:      item.Reason,
 		RedirectURL: parsePendingRedirectURL(item.Reason),
+		UploadIDs:   item.UploadIDs,
+		ReviewedBy:  item.ReviewedBy,
+		ReviewedAt:  item.ReviewedAt,
 		CreatedAt:   item.CreatedAt,
 		VerifiedAt:  item.VerifiedAt,
 	}
 }
 
+func toCompanyVerificationDTO(item domain.CompanyVerification) CompanyVerificationDTO {
+	return CompanyVerificationDTO{
+		ID:                  item.ID,
+		UserID:              item.UserID,
+		CompanyName:         item.CompanyName,
+		CreditCode:          item.CreditCode,
+		LegalPersonName:     item.LegalPersonName,
+		LegalPersonIDNumber: maskIDNumber(item.LegalPersonIDNumber),
+		InvoiceTitle:        item.InvoiceTitle,
+		TaxNumber:           item.TaxNumber,
+		InvoiceAddress:      item.InvoiceAddress,
+		InvoicePhone:        item.InvoicePhone,
+		InvoiceBank:         item.InvoiceBank,
+		InvoiceBankAccount:  item.InvoiceBankAccount,
+		Status:              item.Status,
+		Provider:            item.Provider,
+		Reason:              item.Reason,
+		UploadIDs:           item.UploadIDs,
+		ReviewedBy:          item.ReviewedBy,
+		ReviewedAt:          item.ReviewedAt,
+		CreatedAt:           item.CreatedAt,
+		VerifiedAt:          item.VerifiedAt,
+	}
+}
+
 func parsePendingRedirectURL(reason string) string {
 	reason = strings.TrimSpace(reason)
 	if !strings.HasPrefix(reason, "pending_face:") {
@@ -1159,6 +1298,14 @@ func toBillingCycleDTOs(items []domain.BillingCycle) []BillingCycleDTO {
 	return out
 }
 
+func toAddonDTOs(items []domain.Addon) []AddonDTO {
+	out := make([]AddonDTO, 0, len(items))
+	for _, item := range items {
+		out = append(out, toAddonDTO(item))
+	}
+	return out
+}
+
 func toCartItemDTOs(items []domain.CartItem) []CartItemDTO {
 	out := make([]CartItemDTO, 0, len(items))
 	for _, item := range items {
@@ -1259,8 +1406,11 @@ func maskIDNumber(idNumber string) string {
 	if idNumber == "" {
 		return ""
 	}
+	if len(idNumber) <= 4 {
+		return "****"
+	}
 	if len(idNumber) <= 8 {
-		return idNumber
+		return idNumber[:1] + "****" + idNumber[len(idNumber)-1:]
 	}
 	return idNumber[:4] + "****" + idNumber[len(idNumber)-4:]
 }
@@ -1442,6 +1592,8 @@ func planGroupDTOToDomain(dto PlanGroupDTO) domain.PlanGroup {
 		Active:            dto.Active,
 		Visible:           dto.Visible,
 		CapacityRemaining: dto.CapacityRemaining,
+		SnapshotQuota:     dto.SnapshotQuota,
+		BackupQuota:       dto.BackupQuota,
 		SortOrder:         dto.SortOrder,
 	}
 }
@@ -1465,6 +1617,7 @@ func packageDTOToDomain(dto PackageDTO) domain.Package {
 		Active:               dto.Active,
 		Visible:              dto.Visible,
 		CapacityRemaining:    dto.CapacityRemaining,
+		TrialDays:            dto.TrialDays,
 	}
 }
 
@@ -1488,6 +1641,21 @@ func regionDTOToDomain(dto RegionDTO) domain.Region {
 	}
 }
 
+func addonDTOToDomain(dto AddonDTO) domain.Addon {
+	return domain.Addon{
+		ID:          dto.ID,
+		PlanGroupID: dto.PlanGroupID,
+		Kind:        domain.AddonKind(strings.TrimSpace(dto.Kind)),
+		Name:        dto.Name,
+		Description: dto.Description,
+		Monthly:     floatToCents(dto.MonthlyPrice),
+		Units:       dto.Units,
+		MaxQty:      dto.MaxQty,
+		Active:      dto.Active,
+		SortOrder:   dto.SortOrder,
+	}
+}
+
 func billingCycleDTOToDomain(dto BillingCycleDTO) domain.BillingCycle {
 	return domain.BillingCycle{
 		ID:         dto.ID,
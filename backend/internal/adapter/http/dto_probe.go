@@ -8,17 +8,35 @@ import (
 )
 
 type ProbeNodeDTO struct {
-	ID              int64             `json:"id"`
-	Name            string            `json:"name"`
-	AgentID         string            `json:"agent_id"`
-	Status          string            `json:"status"`
-	OSType          string            `json:"os_type"`
-	Tags            []string          `json:"tags"`
-	LastHeartbeatAt *time.Time        `json:"last_heartbeat_at"`
-	LastSnapshotAt  *time.Time        `json:"last_snapshot_at"`
-	Snapshot        *ProbeSnapshotDTO `json:"snapshot,omitempty"`
-	CreatedAt       time.Time         `json:"created_at"`
-	UpdatedAt       time.Time         `json:"updated_at"`
+	ID              int64               `json:"id"`
+	Name            string              `json:"name"`
+	AgentID         string              `json:"agent_id"`
+	Status          string              `json:"status"`
+	OSType          string              `json:"os_type"`
+	Tags            []string            `json:"tags"`
+	LastHeartbeatAt *time.Time          `json:"last_heartbeat_at"`
+	LastSnapshotAt  *time.Time          `json:"last_snapshot_at"`
+	Snapshot        *ProbeSnapshotDTO   `json:"snapshot,omitempty"`
+	AgentVersion    string              `json:"agent_version"`
+	AgentUpdate     ProbeAgentUpdateDTO `json:"agent_update"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+}
+
+type ProbeAgentUpdateDTO struct {
+	Status    string     `json:"status"`
+	Message   string     `json:"message"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type ProbeAgentReleaseDTO struct {
+	Version         string    `json:"version"`
+	Platforms       []string  `json:"platforms"`
+	SignatureStatus string    `json:"signature_status"`
+	Valid           bool      `json:"valid"`
+	Error           string    `json:"error,omitempty"`
+	Desired         bool      `json:"desired"`
+	CreatedAt       time.Time `json:"created_at"`
 }
 
 type ProbeSnapshotDTO struct {
@@ -65,8 +83,14 @@ func toProbeNodeDTO(node domain.ProbeNode) ProbeNodeDTO {
 		Tags:            decodeStringArray(node.TagsJSON),
 		LastHeartbeatAt: node.LastHeartbeatAt,
 		LastSnapshotAt:  node.LastSnapshotAt,
-		CreatedAt:       node.CreatedAt,
-		UpdatedAt:       node.UpdatedAt,
+		AgentVersion:    node.AgentVersion,
+		AgentUpdate: ProbeAgentUpdateDTO{
+			Status:    string(node.AgentUpdateStatus),
+			Message:   node.AgentUpdateMessage,
+			UpdatedAt: node.AgentUpdatedAt,
+		},
+		CreatedAt: node.CreatedAt,
+		UpdatedAt: node.UpdatedAt,
 	}
 	if strings.TrimSpace(node.LastSnapshotJSON) != "" && node.LastSnapshotJSON != "{}" {
 		snapshot := toProbeSnapshotDTO(node.LastSnapshotJSON)
@@ -75,6 +99,22 @@ func toProbeNodeDTO(node domain.ProbeNode) ProbeNodeDTO {
 	return dto
 }
 
+func toProbeAgentReleaseDTO(rel domain.ProbeAgentRelease, desiredVersion string) ProbeAgentReleaseDTO {
+	platforms := rel.Platforms
+	if platforms == nil {
+		platforms = []string{}
+	}
+	return ProbeAgentReleaseDTO{
+		Version:         rel.Version,
+		Platforms:       platforms,
+		SignatureStatus: string(rel.SignatureStatus),
+		Valid:           rel.Valid,
+		Error:           rel.Error,
+		Desired:         desiredVersion != "" && rel.Version == desiredVersion,
+		CreatedAt:       rel.CreatedAt,
+	}
+}
+
 func toProbeStatusEventDTO(ev domain.ProbeStatusEvent) ProbeStatusEventDTO {
 	return ProbeStatusEventDTO{
 		ID:        ev.ID,
@@ -15,6 +15,12 @@ type GeoResolver interface {
 	Resolve(ctx context.Context, ip, mmdbPath string) (city string, tz string, err error)
 }
 
+// CountryResolver is implemented by resolvers that can also look up the
+// ISO 3166 country code of an IP.
+type CountryResolver interface {
+	ResolveCountry(ctx context.Context, ip, mmdbPath string) (string, error)
+}
+
 type MMDBGeoResolver struct {
 	mu       sync.RWMutex
 	dbPath   string
@@ -26,22 +32,7 @@ func NewMMDBGeoResolver() *MMDBGeoResolver {
 }
 
 func (r *MMDBGeoResolver) Resolve(_ context.Context, rawIP, mmdbPath string) (string, string, error) {
-	path := strings.TrimSpace(mmdbPath)
-	if path == "" {
-		return "", "", domain.ErrGeoIPMMDBPathEmpty
-	}
-	ip := net.ParseIP(strings.TrimSpace(rawIP))
-	if ip == nil {
-		return "", "", domain.ErrInvalidIP
-	}
-	if isNonPublicIP(ip) {
-		return "", "", domain.ErrNonPublicIP
-	}
-	db, err := r.getOrOpen(path)
-	if err != nil {
-		return "", "", err
-	}
-	record, err := db.City(ip)
+	record, err := r.lookup(rawIP, mmdbPath)
 	if err != nil {
 		return "", "", err
 	}
@@ -59,6 +50,33 @@ func (r *MMDBGeoResolver) Resolve(_ context.Context, rawIP, mmdbPath string) (st
 	return city, tz, nil
 }
 
+func (r *MMDBGeoResolver) ResolveCountry(_ context.Context, rawIP, mmdbPath string) (string, error) {
+	record, err := r.lookup(rawIP, mmdbPath)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToUpper(strings.TrimSpace(record.Country.IsoCode)), nil
+}
+
+func (r *MMDBGeoResolver) lookup(rawIP, mmdbPath string) (*geoip2.City, error) {
+	path := strings.TrimSpace(mmdbPath)
+	if path == "" {
+		return nil, domain.ErrGeoIPMMDBPathEmpty
+	}
+	ip := net.ParseIP(strings.TrimSpace(rawIP))
+	if ip == nil {
+		return nil, domain.ErrInvalidIP
+	}
+	if isNonPublicIP(ip) {
+		return nil, domain.ErrNonPublicIP
+	}
+	db, err := r.getOrOpen(path)
+	if err != nil {
+		return nil, err
+	}
+	return db.City(ip)
+}
+
 func (r *MMDBGeoResolver) getOrOpen(path string) (*geoip2.Reader, error) {
 	r.mu.RLock()
 	if r.dbReader != nil && r.dbPath == path {
@@ -4,26 +4,45 @@ import (
 	"github.com/microcosm-cc/bluemonday"
 	"regexp"
 	"time"
+	appaddon "xiaoheiplay/internal/app/addon"
 	appadmin "xiaoheiplay/internal/app/admin"
 	appadminvps "xiaoheiplay/internal/app/adminvps"
+	appbackuppolicy "xiaoheiplay/internal/app/backuppolicy"
+	appbroadcast "xiaoheiplay/internal/app/broadcast"
 	appcart "xiaoheiplay/internal/app/cart"
 	appcatalog "xiaoheiplay/internal/app/catalog"
 	appcms "xiaoheiplay/internal/app/cms"
+	appdunning "xiaoheiplay/internal/app/dunning"
 	appgoodstype "xiaoheiplay/internal/app/goodstype"
+	appinventory "xiaoheiplay/internal/app/inventory"
+	appipaddress "xiaoheiplay/internal/app/ipaddress"
+	appledger "xiaoheiplay/internal/app/ledger"
 	appmessage "xiaoheiplay/internal/app/message"
+	appmetrics "xiaoheiplay/internal/app/metrics"
+	appnotifychannel "xiaoheiplay/internal/app/notifychannel"
+	appnotifypref "xiaoheiplay/internal/app/notifypref"
 	appopenapi "xiaoheiplay/internal/app/openapi"
+	apporderapproval "xiaoheiplay/internal/app/orderapproval"
 	apppasswordreset "xiaoheiplay/internal/app/passwordreset"
 	apppayment "xiaoheiplay/internal/app/payment"
+	apppayout "xiaoheiplay/internal/app/payout"
 	apppermission "xiaoheiplay/internal/app/permission"
 	appports "xiaoheiplay/internal/app/ports"
 	appprobe "xiaoheiplay/internal/app/probe"
 	apppush "xiaoheiplay/internal/app/push"
 	apprealname "xiaoheiplay/internal/app/realname"
+	appreport "xiaoheiplay/internal/app/report"
 	appscheduledtask "xiaoheiplay/internal/app/scheduledtask"
+	appsshkey "xiaoheiplay/internal/app/sshkey"
+	appstatement "xiaoheiplay/internal/app/statement"
 	appticket "xiaoheiplay/internal/app/ticket"
+	apptrial "xiaoheiplay/internal/app/trial"
 	appuserapikey "xiaoheiplay/internal/app/userapikey"
+	appvpsbulk "xiaoheiplay/internal/app/vpsbulk"
+	appvpsmigration "xiaoheiplay/internal/app/vpsmigration"
 	appwallet "xiaoheiplay/internal/app/wallet"
 	appwalletorder "xiaoheiplay/internal/app/walletorder"
+	pkgmetrics "xiaoheiplay/internal/pkg/metrics"
 )
 
 var (
@@ -56,6 +75,7 @@ func sanitizeHTML(raw string) string {
 type HandlerDeps struct {
 	AuthSvc           AuthService
 	CatalogSvc        *appcatalog.Service
+	AddonSvc          *appaddon.Service
 	GoodsTypes        *appgoodstype.Service
 	CartSvc           *appcart.Service
 	OrderSvc          OrderService
@@ -94,11 +114,29 @@ type HandlerDeps struct {
 	EmailSender       appports.EmailSender
 	SMSSender         appports.SMSSender
 	RobotNotifier     RobotEventNotifier
+	MetricsSvc        *appmetrics.Service
+	NotifyChannelSvc  *appnotifychannel.Service
+	InventorySvc      *appinventory.Service
+	SSHKeySvc         *appsshkey.Service
+	BackupPolicySvc   *appbackuppolicy.Service
+	VPSBulkSvc        *appvpsbulk.Service
+	VPSMigrationSvc   *appvpsmigration.Service
+	IPAddressSvc      *appipaddress.Service
+	TrialSvc          *apptrial.Service
+	OrderApprovalSvc  *apporderapproval.Service
+	LedgerSvc         *appledger.Service
+	PayoutSvc         *apppayout.Service
+	FinanceReportSvc  *appreport.FinanceService
+	StatementSvc      *appstatement.Service
+	DunningSvc        *appdunning.Service
+	NotifyPrefSvc     *appnotifypref.Service
+	BroadcastSvc      *appbroadcast.Service
 }
 
 type Handler struct {
 	authSvc           AuthService
 	catalogSvc        *appcatalog.Service
+	addonSvc          *appaddon.Service
 	goodsTypes        *appgoodstype.Service
 	cartSvc           *appcart.Service
 	orderSvc          OrderService
@@ -137,6 +175,24 @@ type Handler struct {
 	emailSender       appports.EmailSender
 	smsSender         appports.SMSSender
 	robotNotifier     RobotEventNotifier
+	metricsSvc        *appmetrics.Service
+	notifyChannelSvc  *appnotifychannel.Service
+	inventorySvc      *appinventory.Service
+	sshKeySvc         *appsshkey.Service
+	backupPolicySvc   *appbackuppolicy.Service
+	vpsBulkSvc        *appvpsbulk.Service
+	vpsMigrationSvc   *appvpsmigration.Service
+	ipAddressSvc      *appipaddress.Service
+	trialSvc          *apptrial.Service
+	orderApprovalSvc  *apporderapproval.Service
+	ledgerSvc         *appledger.Service
+	payoutSvc         *apppayout.Service
+	financeReportSvc  *appreport.FinanceService
+	statementSvc      *appstatement.Service
+	dunningSvc        *appdunning.Service
+	notifyPrefSvc     *appnotifypref.Service
+	broadcastSvc      *appbroadcast.Service
+	httpMetrics       *pkgmetrics.HTTPRecorder
 }
 
 type authSettings struct {
@@ -200,6 +256,7 @@ func NewHandler(deps HandlerDeps) *Handler {
 	return &Handler{
 		authSvc:           deps.AuthSvc,
 		catalogSvc:        deps.CatalogSvc,
+		addonSvc:          deps.AddonSvc,
 		goodsTypes:        deps.GoodsTypes,
 		cartSvc:           deps.CartSvc,
 		orderSvc:          deps.OrderSvc,
@@ -238,5 +295,23 @@ func NewHandler(deps HandlerDeps) *Handler {
 		emailSender:       deps.EmailSender,
 		smsSender:         deps.SMSSender,
 		robotNotifier:     deps.RobotNotifier,
+		metricsSvc:        deps.MetricsSvc,
+		notifyChannelSvc:  deps.NotifyChannelSvc,
+		inventorySvc:      deps.InventorySvc,
+		sshKeySvc:         deps.SSHKeySvc,
+		backupPolicySvc:   deps.BackupPolicySvc,
+		vpsBulkSvc:        deps.VPSBulkSvc,
+		vpsMigrationSvc:   deps.VPSMigrationSvc,
+		ipAddressSvc:      deps.IPAddressSvc,
+		trialSvc:          deps.TrialSvc,
+		orderApprovalSvc:  deps.OrderApprovalSvc,
+		ledgerSvc:         deps.LedgerSvc,
+		payoutSvc:         deps.PayoutSvc,
+		financeReportSvc:  deps.FinanceReportSvc,
+		statementSvc:      deps.StatementSvc,
+		dunningSvc:        deps.DunningSvc,
+		notifyPrefSvc:     deps.NotifyPrefSvc,
+		broadcastSvc:      deps.BroadcastSvc,
+		httpMetrics:       pkgmetrics.NewHTTPRecorder(),
 	}
 }
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"xiaoheiplay/internal/domain"
+)
+
+type adminAddonsQuery struct {
+	PlanGroupID int64 `form:"plan_group_id" binding:"omitempty,gt=0"`
+}
+
+func (h *Handler) AdminAddons(c *gin.Context) {
+	var query adminAddonsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	items, err := h.addonSvc.List(c, query.PlanGroupID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrListError.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": toAddonDTOs(items)})
+}
+
+func (h *Handler) AdminAddonCreate(c *gin.Context) {
+	var payload AddonDTO
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	addon := addonDTOToDomain(payload)
+	if err := h.addonSvc.Create(c, &addon); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toAddonDTO(addon))
+}
+
+func (h *Handler) AdminAddonUpdate(c *gin.Context) {
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var payload AddonDTO
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	payload.ID = uri.ID
+	addon := addonDTOToDomain(payload)
+	if err := h.addonSvc.Update(c, addon); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	updated, err := h.addonSvc.Get(c, uri.ID)
+	if err != nil {
+		c.JSON(http.StatusOK, toAddonDTO(addon))
+		return
+	}
+	c.JSON(http.StatusOK, toAddonDTO(updated))
+}
+
+func (h *Handler) AdminAddonDelete(c *gin.Context) {
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	if err := h.addonSvc.Delete(c, uri.ID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
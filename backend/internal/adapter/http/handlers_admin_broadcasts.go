@@ -0,0 +1,299 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	appbroadcast "xiaoheiplay/internal/app/broadcast"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+type broadcastSegmentDTO struct {
+	UserTierGroupIDs []int64    `json:"user_tier_group_ids" binding:"omitempty,max=100,dive,gt=0"`
+	GoodsTypeIDs     []int64    `json:"goods_type_ids" binding:"omitempty,max=100,dive,gt=0"`
+	RegionIDs        []int64    `json:"region_ids" binding:"omitempty,max=100,dive,gt=0"`
+	RealNameStatus   string     `json:"realname_status" binding:"omitempty,oneof=verified unverified"`
+	RegisteredFrom   *time.Time `json:"registered_from"`
+	RegisteredTo     *time.Time `json:"registered_to"`
+}
+
+func (d broadcastSegmentDTO) toSegment() domain.BroadcastSegment {
+	return domain.BroadcastSegment(d)
+}
+
+func toBroadcastSegmentDTO(segment domain.BroadcastSegment) broadcastSegmentDTO {
+	out := broadcastSegmentDTO(segment)
+	if out.UserTierGroupIDs == nil {
+		out.UserTierGroupIDs = []int64{}
+	}
+	if out.GoodsTypeIDs == nil {
+		out.GoodsTypeIDs = []int64{}
+	}
+	if out.RegionIDs == nil {
+		out.RegionIDs = []int64{}
+	}
+	return out
+}
+
+type broadcastDTO struct {
+	ID          int64               `json:"id"`
+	Title       string              `json:"title"`
+	Content     string              `json:"content"`
+	LinkURL     string              `json:"link_url"`
+	Channels    []string            `json:"channels"`
+	Segment     broadcastSegmentDTO `json:"segment"`
+	Status      string              `json:"status"`
+	ScheduledAt time.Time           `json:"scheduled_at"`
+	StartedAt   *time.Time          `json:"started_at,omitempty"`
+	FinishedAt  *time.Time          `json:"finished_at,omitempty"`
+	Total       int                 `json:"total"`
+	SentCount   int                 `json:"sent_count"`
+	FailedCount int                 `json:"failed_count"`
+	CreatedBy   int64               `json:"created_by"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+func toBroadcastDTO(b domain.Broadcast) broadcastDTO {
+	return broadcastDTO{
+		ID:          b.ID,
+		Title:       b.Title,
+		Content:     b.Content,
+		LinkURL:     b.LinkURL,
+		Channels:    b.Channels,
+		Segment:     toBroadcastSegmentDTO(b.Segment),
+		Status:      string(b.Status),
+		ScheduledAt: b.ScheduledAt,
+		StartedAt:   b.StartedAt,
+		FinishedAt:  b.FinishedAt,
+		Total:       b.Total,
+		SentCount:   b.SentCount,
+		FailedCount: b.FailedCount,
+		CreatedBy:   b.CreatedBy,
+		CreatedAt:   b.CreatedAt,
+	}
+}
+
+type broadcastChannelStatsDTO struct {
+	Channel string `json:"channel"`
+	Total   int    `json:"total"`
+	Pending int    `json:"pending"`
+	Sent    int    `json:"sent"`
+	Failed  int    `json:"failed"`
+	Skipped int    `json:"skipped"`
+	Clicked int    `json:"clicked"`
+}
+
+type broadcastStatsDTO struct {
+	Channels []broadcastChannelStatsDTO `json:"channels"`
+	Read     int                        `json:"read"`
+	Clicked  int                        `json:"clicked"`
+}
+
+func toBroadcastStatsDTO(stats domain.BroadcastStats) broadcastStatsDTO {
+	channels := make([]broadcastChannelStatsDTO, 0, len(stats.Channels))
+	for _, item := range stats.Channels {
+		channels = append(channels, broadcastChannelStatsDTO(item))
+	}
+	return broadcastStatsDTO{Channels: channels, Read: stats.Read, Clicked: stats.Clicked}
+}
+
+type broadcastDeliveryDTO struct {
+	ID             int64      `json:"id"`
+	UserID         int64      `json:"user_id"`
+	Channel        string     `json:"channel"`
+	Status         string     `json:"status"`
+	Error          string     `json:"error"`
+	NotificationID int64      `json:"notification_id,omitempty"`
+	SentAt         *time.Time `json:"sent_at,omitempty"`
+	ClickedAt      *time.Time `json:"clicked_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func toBroadcastDeliveryDTO(d domain.BroadcastDelivery) broadcastDeliveryDTO {
+	return broadcastDeliveryDTO{
+		ID:             d.ID,
+		UserID:         d.UserID,
+		Channel:        d.Channel,
+		Status:         string(d.Status),
+		Error:          d.Error,
+		NotificationID: d.NotificationID,
+		SentAt:         d.SentAt,
+		ClickedAt:      d.ClickedAt,
+		CreatedAt:      d.CreatedAt,
+	}
+}
+
+func broadcastErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, appshared.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidBroadcast):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrBroadcastNotCancelable):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *Handler) AdminBroadcasts(c *gin.Context) {
+	if h.broadcastSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var query struct {
+		Status string `form:"status" binding:"omitempty,oneof=scheduled sending sent canceled"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	limit, offset := paging(c)
+	items, total, err := h.broadcastSvc.List(c, query.Status, limit, offset)
+	if err != nil {
+		c.JSON(broadcastErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]broadcastDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toBroadcastDTO(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "total": total})
+}
+
+func (h *Handler) AdminBroadcastCreate(c *gin.Context) {
+	if h.broadcastSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var payload struct {
+		Title       string              `json:"title" binding:"required,max=128"`
+		Content     string              `json:"content" binding:"required,max=5000"`
+		LinkURL     string              `json:"link_url" binding:"max=1024"`
+		Channels    []string            `json:"channels" binding:"required,min=1,max=4,dive,oneof=inapp email sms push"`
+		Segment     broadcastSegmentDTO `json:"segment"`
+		ScheduledAt *time.Time          `json:"scheduled_at"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	b, err := h.broadcastSvc.Create(c, getUserID(c), appbroadcast.CreateInput{
+		Title:       payload.Title,
+		Content:     payload.Content,
+		LinkURL:     payload.LinkURL,
+		Channels:    payload.Channels,
+		Segment:     payload.Segment.toSegment(),
+		ScheduledAt: payload.ScheduledAt,
+	})
+	if err != nil {
+		c.JSON(broadcastErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toBroadcastDTO(b))
+}
+
+func (h *Handler) AdminBroadcastPreview(c *gin.Context) {
+	if h.broadcastSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var payload struct {
+		Segment broadcastSegmentDTO `json:"segment"`
+	}
+	if err := bindJSONOptional(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	count, err := h.broadcastSvc.Preview(c, payload.Segment.toSegment())
+	if err != nil {
+		c.JSON(broadcastErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recipients": count})
+}
+
+func (h *Handler) AdminBroadcastDetail(c *gin.Context) {
+	if h.broadcastSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	b, stats, err := h.broadcastSvc.Get(c, uri.ID)
+	if err != nil {
+		c.JSON(broadcastErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"broadcast": toBroadcastDTO(b), "stats": toBroadcastStatsDTO(stats)})
+}
+
+func (h *Handler) AdminBroadcastDeliveries(c *gin.Context) {
+	if h.broadcastSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var query struct {
+		Channel string `form:"channel" binding:"omitempty,oneof=inapp email sms push"`
+		Status  string `form:"status" binding:"omitempty,oneof=pending sent failed skipped"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	limit, offset := paging(c)
+	items, total, err := h.broadcastSvc.Deliveries(c, uri.ID, query.Channel, query.Status, limit, offset)
+	if err != nil {
+		c.JSON(broadcastErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]broadcastDeliveryDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toBroadcastDeliveryDTO(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "total": total})
+}
+
+func (h *Handler) AdminBroadcastCancel(c *gin.Context) {
+	if h.broadcastSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	b, err := h.broadcastSvc.Cancel(c, getUserID(c), uri.ID)
+	if err != nil {
+		c.JSON(broadcastErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toBroadcastDTO(b))
+}
+
+// BroadcastLink is the tracked link in broadcast messages. It needs no
+// login so links opened from email and SMS are counted too.
+func (h *Handler) BroadcastLink(c *gin.Context) {
+	if h.broadcastSvc == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrNotFound.Error()})
+		return
+	}
+	target, err := h.broadcastSvc.Click(c, c.Param("token"))
+	if err != nil {
+		c.JSON(broadcastErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Redirect(http.StatusFound, target)
+}
@@ -0,0 +1,103 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+	"xiaoheiplay/internal/testutilhttp"
+)
+
+func TestHandlers_AdminBroadcasts(t *testing.T) {
+	env := testutilhttp.NewTestEnv(t, false)
+	admin := testutil.CreateAdmin(t, env.Repo, "caster", "caster@example.com", "pass", ensureAdminGroup(t, env))
+	token := testutil.IssueJWT(t, env.JWTSecret, admin.ID, "admin", time.Hour)
+	user := testutil.CreateUser(t, env.Repo, "reader", "reader@example.com", "pass")
+
+	rec := testutil.DoJSON(t, env.Router, http.MethodPost, "/admin/api/v1/broadcasts/preview", map[string]any{
+		"segment": map[string]any{"realname_status": "unverified"},
+	}, token)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"recipients":1`) {
+		t.Fatalf("preview: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = testutil.DoJSON(t, env.Router, http.MethodPost, "/admin/api/v1/broadcasts", map[string]any{
+		"title":    "Maintenance",
+		"content":  "Tonight",
+		"channels": []string{"pager"},
+	}, token)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown channel rejected: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = testutil.DoJSON(t, env.Router, http.MethodPost, "/admin/api/v1/broadcasts", map[string]any{
+		"title":    "Maintenance",
+		"content":  "Tonight",
+		"link_url": "https://example.com/notice",
+		"channels": []string{"inapp"},
+	}, token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("create: %d %s", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		ID     int64  `json:"id"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil || created.Status != "scheduled" {
+		t.Fatalf("decode create: %v %s", err, rec.Body.String())
+	}
+
+	if _, err := env.BroadcastSvc.RunDue(context.Background()); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	rec = testutil.DoJSON(t, env.Router, http.MethodGet, "/admin/api/v1/broadcasts/"+testutil.Itoa(created.ID)+"/deliveries", nil, token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("deliveries: %d %s", rec.Code, rec.Body.String())
+	}
+	var deliveries struct {
+		Items []struct {
+			UserID int64  `json:"user_id"`
+			Status string `json:"status"`
+		} `json:"items"`
+		Total int `json:"total"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &deliveries); err != nil || deliveries.Total != 1 || deliveries.Items[0].UserID != user.ID || deliveries.Items[0].Status != "sent" {
+		t.Fatalf("unexpected deliveries: %v %s", err, rec.Body.String())
+	}
+
+	items, _, err := env.Repo.ListBroadcastDeliveries(context.Background(), created.ID, "", "", 10, 0)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("list deliveries: %v", err)
+	}
+	rec = testutil.DoJSON(t, env.Router, http.MethodGet, "/api/v1/broadcasts/links/"+items[0].Token, nil, "")
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "https://example.com/notice" {
+		t.Fatalf("click: %d %s", rec.Code, rec.Header().Get("Location"))
+	}
+
+	rec = testutil.DoJSON(t, env.Router, http.MethodGet, "/admin/api/v1/broadcasts/"+testutil.Itoa(created.ID), nil, token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("detail: %d %s", rec.Code, rec.Body.String())
+	}
+	var detail struct {
+		Broadcast struct {
+			Status    string `json:"status"`
+			SentCount int    `json:"sent_count"`
+		} `json:"broadcast"`
+		Stats struct {
+			Clicked int `json:"clicked"`
+		} `json:"stats"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &detail); err != nil || detail.Broadcast.Status != string(domain.BroadcastStatusSent) || detail.Broadcast.SentCount != 1 || detail.Stats.Clicked != 1 {
+		t.Fatalf("unexpected detail: %v %s", err, rec.Body.String())
+	}
+
+	rec = testutil.DoJSON(t, env.Router, http.MethodPost, "/admin/api/v1/broadcasts/"+testutil.Itoa(created.ID)+"/cancel", nil, token)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected sent broadcast not cancelable: %d %s", rec.Code, rec.Body.String())
+	}
+}
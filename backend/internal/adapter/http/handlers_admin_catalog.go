@@ -328,6 +328,8 @@ func (h *Handler) AdminPlanGroupUpdate(c *gin.Context) {
 		Active            *bool    `json:"active"`
 		Visible           *bool    `json:"visible"`
 		CapacityRemaining *int     `json:"capacity_remaining"`
+		SnapshotQuota     *int     `json:"snapshot_quota"`
+		BackupQuota       *int     `json:"backup_quota"`
 		SortOrder         *int     `json:"sort_order"`
 	}
 	if err := bindJSON(c, &payload); err != nil {
@@ -408,6 +410,12 @@ func (h *Handler) AdminPlanGroupUpdate(c *gin.Context) {
 	if payload.CapacityRemaining != nil {
 		plan.CapacityRemaining = *payload.CapacityRemaining
 	}
+	if payload.SnapshotQuota != nil {
+		plan.SnapshotQuota = *payload.SnapshotQuota
+	}
+	if payload.BackupQuota != nil {
+		plan.BackupQuota = *payload.BackupQuota
+	}
 	if payload.SortOrder != nil {
 		plan.SortOrder = *payload.SortOrder
 	}
@@ -586,6 +594,7 @@ func (h *Handler) AdminPackageUpdate(c *gin.Context) {
 		Active               *bool    `json:"active"`
 		Visible              *bool    `json:"visible"`
 		CapacityRemaining    *int     `json:"capacity_remaining"`
+		TrialDays            *int     `json:"trial_days"`
 	}
 	if err := bindJSON(c, &payload); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
@@ -648,6 +657,9 @@ func (h *Handler) AdminPackageUpdate(c *gin.Context) {
 	if payload.CapacityRemaining != nil {
 		pkg.CapacityRemaining = *payload.CapacityRemaining
 	}
+	if payload.TrialDays != nil {
+		pkg.TrialDays = *payload.TrialDays
+	}
 	if err := h.catalogSvc.UpdatePackage(c, pkg); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -305,6 +305,7 @@ func applyCouponGroupRulesPayload(group *domain.CouponProductGroup, rules []Coup
 			RegionID:         item.RegionID,
 			PlanGroupID:      item.PlanGroupID,
 			PackageID:        item.PackageID,
+			AddonID:          item.AddonID,
 			AddonCoreEnabled: item.AddonCoreEnabled,
 			AddonMemEnabled:  item.AddonMemEnabled,
 			AddonDiskEnabled: item.AddonDiskEnabled,
@@ -0,0 +1,325 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+type financeReportDefinitionDTO struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Period     string     `json:"period"`
+	Formats    []string   `json:"formats"`
+	Recipients []string   `json:"recipients"`
+	Enabled    bool       `json:"enabled"`
+	CreatedBy  int64      `json:"created_by"`
+	LastRunAt  *time.Time `json:"last_run_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type financeReportFileDTO struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+type financeReportRunDTO struct {
+	ID           int64                  `json:"id"`
+	DefinitionID int64                  `json:"definition_id"`
+	Name         string                 `json:"name"`
+	Period       string                 `json:"period"`
+	PeriodStart  time.Time              `json:"period_start"`
+	PeriodEnd    time.Time              `json:"period_end"`
+	Trigger      string                 `json:"trigger"`
+	Status       string                 `json:"status"`
+	Error        string                 `json:"error"`
+	Recipients   []string               `json:"recipients"`
+	CreatedBy    int64                  `json:"created_by"`
+	DeliveredAt  *time.Time             `json:"delivered_at,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+	Files        []financeReportFileDTO `json:"files,omitempty"`
+}
+
+type financeReportFileURI struct {
+	ID     int64 `uri:"id" binding:"required,gt=0"`
+	FileID int64 `uri:"file_id" binding:"required,gt=0"`
+}
+
+type financeReportDefinitionPayload struct {
+	Name       string   `json:"name" binding:"required,max=128"`
+	Period     string   `json:"period" binding:"required,oneof=daily weekly monthly"`
+	Formats    []string `json:"formats" binding:"omitempty,max=2,dive,oneof=csv xlsx"`
+	Recipients []string `json:"recipients" binding:"required,min=1,max=20,dive,email"`
+	Enabled    *bool    `json:"enabled"`
+}
+
+func (p financeReportDefinitionPayload) input() appshared.FinanceReportDefinitionInput {
+	enabled := true
+	if p.Enabled != nil {
+		enabled = *p.Enabled
+	}
+	return appshared.FinanceReportDefinitionInput{
+		Name:       p.Name,
+		Period:     p.Period,
+		Formats:    p.Formats,
+		Recipients: p.Recipients,
+		Enabled:    enabled,
+	}
+}
+
+func toFinanceReportDefinitionDTO(def domain.FinanceReportDefinition) financeReportDefinitionDTO {
+	return financeReportDefinitionDTO{
+		ID:         def.ID,
+		Name:       def.Name,
+		Period:     string(def.Period),
+		Formats:    def.Formats,
+		Recipients: def.Recipients,
+		Enabled:    def.Enabled,
+		CreatedBy:  def.CreatedBy,
+		LastRunAt:  def.LastRunAt,
+		CreatedAt:  def.CreatedAt,
+		UpdatedAt:  def.UpdatedAt,
+	}
+}
+
+func toFinanceReportRunDTO(run domain.FinanceReportRun) financeReportRunDTO {
+	out := financeReportRunDTO{
+		ID:           run.ID,
+		DefinitionID: run.DefinitionID,
+		Name:         run.Name,
+		Period:       string(run.Period),
+		PeriodStart:  run.PeriodStart,
+		PeriodEnd:    run.PeriodEnd,
+		Trigger:      run.Trigger,
+		Status:       string(run.Status),
+		Error:        run.Error,
+		Recipients:   run.Recipients,
+		CreatedBy:    run.CreatedBy,
+		DeliveredAt:  run.DeliveredAt,
+		CreatedAt:    run.CreatedAt,
+	}
+	for _, file := range run.Files {
+		out.Files = append(out.Files, financeReportFileDTO{ID: file.ID, Name: file.Name, ContentType: file.ContentType, Size: file.Size})
+	}
+	return out
+}
+
+func financeReportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, appshared.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, appshared.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, appshared.ErrInvalidInput), errors.Is(err, domain.ErrInvalidFinanceReport):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *Handler) AdminFinanceReports(c *gin.Context) {
+	if h.financeReportSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	items, err := h.financeReportSvc.ListDefinitions(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrListError.Error()})
+		return
+	}
+	resp := make([]financeReportDefinitionDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toFinanceReportDefinitionDTO(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "total": len(resp)})
+}
+
+func (h *Handler) AdminFinanceReportCreate(c *gin.Context) {
+	if h.financeReportSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var payload financeReportDefinitionPayload
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	def, err := h.financeReportSvc.CreateDefinition(c, getUserID(c), payload.input())
+	if err != nil {
+		c.JSON(financeReportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toFinanceReportDefinitionDTO(def))
+}
+
+func (h *Handler) AdminFinanceReportUpdate(c *gin.Context) {
+	if h.financeReportSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var payload financeReportDefinitionPayload
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	def, err := h.financeReportSvc.UpdateDefinition(c, getUserID(c), uri.ID, payload.input())
+	if err != nil {
+		c.JSON(financeReportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toFinanceReportDefinitionDTO(def))
+}
+
+func (h *Handler) AdminFinanceReportDelete(c *gin.Context) {
+	if h.financeReportSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	if err := h.financeReportSvc.DeleteDefinition(c, getUserID(c), uri.ID); err != nil {
+		c.JSON(financeReportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func (h *Handler) AdminFinanceReportRun(c *gin.Context) {
+	if h.financeReportSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var payload struct {
+		FromAt string `json:"from_at"`
+		ToAt   string `json:"to_at"`
+	}
+	if err := bindJSONOptional(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	var from, to time.Time
+	if payload.FromAt != "" || payload.ToAt != "" {
+		var errFrom, errTo error
+		from, errFrom = parseQueryTime(payload.FromAt)
+		to, errTo = parseQueryTime(payload.ToAt)
+		if errFrom != nil || errTo != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+			return
+		}
+	}
+	run, err := h.financeReportSvc.RunNow(c, getUserID(c), uri.ID, from, to)
+	if err != nil {
+		c.JSON(financeReportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toFinanceReportRunDTO(run))
+}
+
+func (h *Handler) AdminFinanceReportRuns(c *gin.Context) {
+	if h.financeReportSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var query struct {
+		DefinitionID int64 `form:"definition_id" binding:"omitempty,gt=0"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	limit, offset := paging(c)
+	items, total, err := h.financeReportSvc.ListRuns(c, query.DefinitionID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrListError.Error()})
+		return
+	}
+	resp := make([]financeReportRunDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toFinanceReportRunDTO(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "total": total})
+}
+
+func (h *Handler) AdminFinanceReportRunDetail(c *gin.Context) {
+	if h.financeReportSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	run, err := h.financeReportSvc.GetRun(c, uri.ID)
+	if err != nil {
+		c.JSON(financeReportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toFinanceReportRunDTO(run))
+}
+
+func (h *Handler) AdminFinanceReportRunFile(c *gin.Context) {
+	if h.financeReportSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri financeReportFileURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	file, err := h.financeReportSvc.GetFile(c, uri.ID, uri.FileID)
+	if err != nil {
+		c.JSON(financeReportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", file.Name))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, file.ContentType, file.Content)
+}
+
+func (h *Handler) AdminFinanceReportRunResend(c *gin.Context) {
+	if h.financeReportSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var payload struct {
+		Recipients []string `json:"recipients" binding:"omitempty,max=20,dive,email"`
+	}
+	if err := bindJSONOptional(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	run, err := h.financeReportSvc.Resend(c, getUserID(c), uri.ID, payload.Recipients)
+	if err != nil {
+		c.JSON(financeReportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toFinanceReportRunDTO(run))
+}
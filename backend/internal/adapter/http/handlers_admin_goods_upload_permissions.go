@@ -245,20 +245,35 @@ func (h *Handler) AdminUploadCreate(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
 		return
 	}
+	item, ok := saveImageUpload(c, "uploads")
+	if !ok {
+		return
+	}
+	item.URL = "/" + filepath.ToSlash(item.Path)
+	if err := h.uploadSvc.Create(c, &item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toUploadDTO(item))
+}
+
+// saveImageUpload checks that the multipart "file" is an image and stores it
+// under root/<date>. On failure it writes the error response.
+func saveImageUpload(c *gin.Context, root string) (domain.Upload, bool) {
 	file, err := c.FormFile("file")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrFileRequired.Error()})
-		return
+		return domain.Upload{}, false
 	}
 	const maxUploadSize = 20 << 20
 	if file.Size > maxUploadSize {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrFileTooLarge.Error()})
-		return
+		return domain.Upload{}, false
 	}
 	opened, err := file.Open()
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrFileOpenFailed.Error()})
-		return
+		return domain.Upload{}, false
 	}
 	head := make([]byte, 512)
 	n, _ := io.ReadFull(opened, head)
@@ -272,26 +287,19 @@ func (h *Handler) AdminUploadCreate(c *gin.Context) {
 	}
 	if !allowed[detected] {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrUnsupportedFileType.Error()})
-		return
+		return domain.Upload{}, false
 	}
 	dateDir := time.Now().Format("20060102")
-	if err := os.MkdirAll(filepath.Join("uploads", dateDir), 0o755); err != nil {
+	if err := os.MkdirAll(filepath.Join(root, dateDir), 0o755); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrUploadDirError.Error()})
-		return
+		return domain.Upload{}, false
 	}
-	name := buildUploadName(detected)
-	localPath := filepath.Join("uploads", dateDir, name)
+	localPath := filepath.Join(root, dateDir, buildUploadName(detected))
 	if err := c.SaveUploadedFile(file, localPath); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrSaveFailed.Error()})
-		return
+		return domain.Upload{}, false
 	}
-	url := "/uploads/" + dateDir + "/" + name
-	item := domain.Upload{Name: file.Filename, Path: localPath, URL: url, Mime: detected, Size: file.Size, UploaderID: getUserID(c)}
-	if err := h.uploadSvc.Create(c, &item); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	c.JSON(http.StatusOK, toUploadDTO(item))
+	return domain.Upload{Name: file.Filename, Path: localPath, Mime: detected, Size: file.Size, UploaderID: getUserID(c)}, true
 }
 
 func (h *Handler) AdminUploads(c *gin.Context) {
@@ -96,10 +96,25 @@ func (h *Handler) AdminRealNameConfig(c *gin.Context) {
 		return
 	}
 	enabled, provider, actions := h.realnameSvc.GetConfig(c)
+	chain := h.realnameSvc.ChainConfig(c)
+	fallbacks := chain.FallbackProviders
+	if fallbacks == nil {
+		fallbacks = []string{}
+	}
+	company := h.realnameSvc.CompanyConfig(c)
+	requirements := make([]CompanyRequirementDTO, 0, len(company.Requirements))
+	for _, item := range company.Requirements {
+		requirements = append(requirements, toCompanyRequirementDTO(item))
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"enabled":       enabled,
-		"provider":      provider,
-		"block_actions": actions,
+		"enabled":              enabled,
+		"provider":             provider,
+		"block_actions":        actions,
+		"fallback_providers":   fallbacks,
+		"provider_timeout_sec": chain.TimeoutSec,
+		"manual_review":        chain.ManualReview,
+		"company_enabled":      company.Enabled,
+		"company_requirements": requirements,
 	})
 }
 
@@ -109,9 +124,14 @@ func (h *Handler) AdminRealNameConfigUpdate(c *gin.Context) {
 		return
 	}
 	var payload struct {
-		Enabled      bool     `json:"enabled"`
-		Provider     string   `json:"provider"`
-		BlockActions []string `json:"block_actions"`
+		Enabled             bool                     `json:"enabled"`
+		Provider            string                   `json:"provider"`
+		BlockActions        []string                 `json:"block_actions"`
+		FallbackProviders   *[]string                `json:"fallback_providers"`
+		ProviderTimeoutSec  *int                     `json:"provider_timeout_sec" binding:"omitempty,min=1,max=120"`
+		ManualReview        *bool                    `json:"manual_review"`
+		CompanyEnabled      *bool                    `json:"company_enabled"`
+		CompanyRequirements *[]CompanyRequirementDTO `json:"company_requirements" binding:"omitempty,max=20,dive"`
 	}
 	if err := bindJSON(c, &payload); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
@@ -121,6 +141,38 @@ func (h *Handler) AdminRealNameConfigUpdate(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if payload.FallbackProviders != nil || payload.ProviderTimeoutSec != nil || payload.ManualReview != nil {
+		chain := h.realnameSvc.ChainConfig(c)
+		if payload.FallbackProviders != nil {
+			chain.FallbackProviders = *payload.FallbackProviders
+		}
+		if payload.ProviderTimeoutSec != nil {
+			chain.TimeoutSec = *payload.ProviderTimeoutSec
+		}
+		if payload.ManualReview != nil {
+			chain.ManualReview = *payload.ManualReview
+		}
+		if err := h.realnameSvc.UpdateChainConfig(c, chain); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if payload.CompanyEnabled != nil || payload.CompanyRequirements != nil {
+		company := h.realnameSvc.CompanyConfig(c)
+		if payload.CompanyEnabled != nil {
+			company.Enabled = *payload.CompanyEnabled
+		}
+		if payload.CompanyRequirements != nil {
+			company.Requirements = make([]appshared.CompanyRequirement, 0, len(*payload.CompanyRequirements))
+			for _, item := range *payload.CompanyRequirements {
+				company.Requirements = append(company.Requirements, item.toRequirement())
+			}
+		}
+		if err := h.realnameSvc.UpdateCompanyConfig(c, company); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
 	c.JSON(http.StatusOK, gin.H{"ok": true})
 }
 
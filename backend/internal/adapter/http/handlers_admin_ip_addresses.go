@@ -0,0 +1,241 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	appipaddress "xiaoheiplay/internal/app/ipaddress"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+type ipAddressDTO struct {
+	ID        int64     `json:"id"`
+	Address   string    `json:"address"`
+	Version   int       `json:"version"`
+	LineID    int64     `json:"line_id"`
+	VPSID     int64     `json:"vps_id"`
+	UserID    int64     `json:"user_id"`
+	Primary   bool      `json:"primary"`
+	PTR       string    `json:"ptr"`
+	Status    string    `json:"status"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ipAddressEventDTO struct {
+	ID        int64     `json:"id"`
+	Address   string    `json:"address"`
+	Type      string    `json:"type"`
+	VPSID     int64     `json:"vps_id"`
+	UserID    int64     `json:"user_id"`
+	AdminID   int64     `json:"admin_id"`
+	Detail    string    `json:"detail"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func toIPAddressDTO(ip domain.IPAddress) ipAddressDTO {
+	return ipAddressDTO{
+		ID:        ip.ID,
+		Address:   ip.Address,
+		Version:   ip.Version,
+		LineID:    ip.LineID,
+		VPSID:     ip.VPSID,
+		UserID:    ip.UserID,
+		Primary:   ip.Primary,
+		PTR:       ip.PTR,
+		Status:    string(ip.Status),
+		Note:      ip.Note,
+		CreatedAt: ip.CreatedAt,
+		UpdatedAt: ip.UpdatedAt,
+	}
+}
+
+func (h *Handler) AdminIPAddresses(c *gin.Context) {
+	if h.ipAddressSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var query struct {
+		Keyword string `form:"keyword" binding:"max=64"`
+		LineID  int64  `form:"line_id" binding:"omitempty,gt=0"`
+		VPSID   int64  `form:"vps_id" binding:"omitempty,gt=0"`
+		Status  string `form:"status" binding:"omitempty,oneof=available assigned reserved blocked"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	limit, offset := paging(c)
+	items, total, err := h.ipAddressSvc.List(c, appshared.IPAddressFilter{
+		Keyword: query.Keyword,
+		LineID:  query.LineID,
+		VPSID:   query.VPSID,
+		Status:  query.Status,
+	}, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrListError.Error()})
+		return
+	}
+	resp := make([]ipAddressDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toIPAddressDTO(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "total": total})
+}
+
+func (h *Handler) AdminIPAddressCreate(c *gin.Context) {
+	if h.ipAddressSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var payload struct {
+		Address string `json:"address" binding:"required,max=64"`
+		LineID  int64  `json:"line_id" binding:"omitempty,gt=0"`
+		Status  string `json:"status" binding:"omitempty,oneof=available reserved blocked"`
+		Note    string `json:"note" binding:"max=2000"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	ip, err := h.ipAddressSvc.Create(c, getUserID(c), appipaddress.CreateInput{
+		Address: payload.Address,
+		LineID:  payload.LineID,
+		Status:  domain.IPAddressStatus(payload.Status),
+		Note:    payload.Note,
+	})
+	if err != nil {
+		c.JSON(ipAddressErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toIPAddressDTO(ip))
+}
+
+func (h *Handler) AdminIPAddressDetail(c *gin.Context) {
+	if h.ipAddressSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	ip, err := h.ipAddressSvc.Get(c, uri.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrNotFound.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toIPAddressDTO(ip))
+}
+
+func (h *Handler) AdminIPAddressUpdate(c *gin.Context) {
+	if h.ipAddressSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var payload struct {
+		LineID *int64  `json:"line_id" binding:"omitempty,min=0"`
+		Status *string `json:"status" binding:"omitempty,oneof=available assigned reserved blocked"`
+		Note   *string `json:"note" binding:"omitempty,max=2000"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	in := appipaddress.UpdateInput{LineID: payload.LineID, Note: payload.Note}
+	if payload.Status != nil {
+		status := domain.IPAddressStatus(*payload.Status)
+		in.Status = &status
+	}
+	ip, err := h.ipAddressSvc.Update(c, getUserID(c), uri.ID, in)
+	if err != nil {
+		c.JSON(ipAddressErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toIPAddressDTO(ip))
+}
+
+func (h *Handler) AdminIPAddressDelete(c *gin.Context) {
+	if h.ipAddressSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	if err := h.ipAddressSvc.Delete(c, uri.ID); err != nil {
+		c.JSON(ipAddressErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func (h *Handler) AdminIPAddressEvents(c *gin.Context) {
+	if h.ipAddressSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	limit, offset := paging(c)
+	items, total, err := h.ipAddressSvc.Events(c, uri.ID, limit, offset)
+	if err != nil {
+		c.JSON(ipAddressErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]ipAddressEventDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, ipAddressEventDTO{
+			ID:        item.ID,
+			Address:   item.Address,
+			Type:      string(item.Type),
+			VPSID:     item.VPSID,
+			UserID:    item.UserID,
+			AdminID:   item.AdminID,
+			Detail:    item.Detail,
+			CreatedAt: item.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "total": total})
+}
+
+func (h *Handler) AdminIPAddressAbuse(c *gin.Context) {
+	if h.ipAddressSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var payload struct {
+		Detail string `json:"detail" binding:"required,max=2000"`
+		Block  bool   `json:"block"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	ip, err := h.ipAddressSvc.ReportAbuse(c, getUserID(c), uri.ID, payload.Detail, payload.Block)
+	if err != nil {
+		c.JSON(ipAddressErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toIPAddressDTO(ip))
+}
@@ -0,0 +1,173 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+type ledgerAccountDTO struct {
+	Account string  `json:"account"`
+	Type    string  `json:"type"`
+	Debit   float64 `json:"debit"`
+	Credit  float64 `json:"credit"`
+	Balance float64 `json:"balance"`
+}
+
+type ledgerLineDTO struct {
+	Account string  `json:"account"`
+	Debit   float64 `json:"debit"`
+	Credit  float64 `json:"credit"`
+}
+
+type ledgerEntryDTO struct {
+	ID        int64           `json:"id"`
+	Key       string          `json:"key"`
+	Kind      string          `json:"kind"`
+	RefType   string          `json:"ref_type"`
+	RefID     int64           `json:"ref_id"`
+	UserID    int64           `json:"user_id"`
+	Memo      string          `json:"memo"`
+	CreatedAt time.Time       `json:"created_at"`
+	Lines     []ledgerLineDTO `json:"lines"`
+}
+
+type ledgerDriftDTO struct {
+	UserID        int64   `json:"user_id"`
+	WalletBalance float64 `json:"wallet_balance"`
+	LedgerBalance float64 `json:"ledger_balance"`
+	Difference    float64 `json:"difference"`
+}
+
+type ledgerEntryQuery struct {
+	Account string `form:"account" binding:"omitempty,max=128"`
+	Kind    string `form:"kind" binding:"omitempty,max=64"`
+	RefType string `form:"ref_type" binding:"omitempty,max=64"`
+	RefID   int64  `form:"ref_id" binding:"omitempty,gt=0"`
+	UserID  int64  `form:"user_id" binding:"omitempty,gt=0"`
+}
+
+func toLedgerEntryDTO(entry domain.LedgerEntry) ledgerEntryDTO {
+	lines := make([]ledgerLineDTO, 0, len(entry.Lines))
+	for _, line := range entry.Lines {
+		lines = append(lines, ledgerLineDTO{Account: line.Account, Debit: centsToFloat(line.Debit), Credit: centsToFloat(line.Credit)})
+	}
+	return ledgerEntryDTO{
+		ID:        entry.ID,
+		Key:       entry.Key,
+		Kind:      entry.Kind,
+		RefType:   entry.RefType,
+		RefID:     entry.RefID,
+		UserID:    entry.UserID,
+		Memo:      entry.Memo,
+		CreatedAt: entry.CreatedAt,
+		Lines:     lines,
+	}
+}
+
+func toLedgerVerificationResponse(result domain.LedgerVerification) gin.H {
+	drifts := make([]ledgerDriftDTO, 0, len(result.Drifts))
+	for _, drift := range result.Drifts {
+		drifts = append(drifts, ledgerDriftDTO{
+			UserID:        drift.UserID,
+			WalletBalance: centsToFloat(drift.WalletBalance),
+			LedgerBalance: centsToFloat(drift.LedgerBalance),
+			Difference:    centsToFloat(drift.WalletBalance - drift.LedgerBalance),
+		})
+	}
+	unbalanced := result.UnbalancedEntries
+	if unbalanced == nil {
+		unbalanced = []int64{}
+	}
+	return gin.H{
+		"ok":                 result.OK(),
+		"checked_at":         result.CheckedAt,
+		"wallets":            result.Wallets,
+		"total_debit":        centsToFloat(result.TotalDebit),
+		"total_credit":       centsToFloat(result.TotalCredit),
+		"drifts":             drifts,
+		"unbalanced_entries": unbalanced,
+	}
+}
+
+func (h *Handler) AdminLedgerAccounts(c *gin.Context) {
+	if h.ledgerSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	accounts, err := h.ledgerSvc.ListAccounts(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrListError.Error()})
+		return
+	}
+	items := make([]ledgerAccountDTO, 0, len(accounts))
+	for _, account := range accounts {
+		items = append(items, ledgerAccountDTO{
+			Account: account.Account,
+			Type:    string(account.Type),
+			Debit:   centsToFloat(account.Debit),
+			Credit:  centsToFloat(account.Credit),
+			Balance: centsToFloat(account.Balance()),
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": len(items)})
+}
+
+func (h *Handler) AdminLedgerEntries(c *gin.Context) {
+	if h.ledgerSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var query ledgerEntryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	limit, offset := paging(c)
+	entries, total, err := h.ledgerSvc.ListEntries(c, appshared.LedgerEntryFilter{
+		Account: query.Account,
+		Kind:    query.Kind,
+		RefType: query.RefType,
+		RefID:   query.RefID,
+		UserID:  query.UserID,
+	}, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrListError.Error()})
+		return
+	}
+	items := make([]ledgerEntryDTO, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, toLedgerEntryDTO(entry))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "total": total})
+}
+
+func (h *Handler) AdminLedgerVerify(c *gin.Context) {
+	if h.ledgerSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	result, err := h.ledgerSvc.Verify(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toLedgerVerificationResponse(result))
+}
+
+func (h *Handler) AdminLedgerOpeningBalances(c *gin.Context) {
+	if h.ledgerSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	posted, err := h.ledgerSvc.PostOpeningBalances(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"posted": posted})
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	appnotifychannel "xiaoheiplay/internal/app/notifychannel"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+func (h *Handler) AdminNotifyChannels(c *gin.Context) {
+	if h.notifyChannelSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	channels, err := h.notifyChannelSvc.Channels(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	routes, err := h.notifyChannelSvc.Routes(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"channels":      channels,
+		"routes":        routes,
+		"types":         appnotifychannel.KnownTypes,
+		"email_channel": strings.TrimSpace(h.getSettingValueByKey(c, "notify_email_channel")),
+	})
+}
+
+func (h *Handler) AdminNotifyChannelsUpdate(c *gin.Context) {
+	if h.notifyChannelSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var payload struct {
+		Routes       []appshared.NotifyRoute `json:"routes"`
+		EmailChannel *string                 `json:"email_channel"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	routes, err := h.notifyChannelSvc.UpdateRoutes(c, payload.Routes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	adminID := getUserID(c)
+	if payload.EmailChannel != nil {
+		_ = h.adminSvc.UpdateSetting(c, adminID, "notify_email_channel", strings.TrimSpace(*payload.EmailChannel))
+	}
+	h.adminSvc.Audit(c, adminID, "notify_channel.routes_update", "setting", "notify_channel_routes", map[string]any{"routes": len(routes)})
+	c.JSON(http.StatusOK, gin.H{"routes": routes})
+}
+
+func (h *Handler) AdminNotifyChannelTest(c *gin.Context) {
+	if h.notifyChannelSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var payload struct {
+		Channel    string   `json:"channel"`
+		Recipients []string `json:"recipients"`
+		Title      string   `json:"title"`
+		Content    string   `json:"content"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	delivery, err := h.notifyChannelSvc.Test(c, payload.Channel, payload.Recipients, payload.Title, payload.Content)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true, "message_id": delivery.MessageID})
+}
@@ -0,0 +1,299 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	apporderapproval "xiaoheiplay/internal/app/orderapproval"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+type orderApprovalRuleDTO struct {
+	ID                 int64     `json:"id"`
+	Name               string    `json:"name"`
+	Priority           int       `json:"priority"`
+	Enabled            bool      `json:"enabled"`
+	Decision           string    `json:"decision"`
+	MinAmount          float64   `json:"min_amount"`
+	MaxAccountAgeDays  int       `json:"max_account_age_days"`
+	RealNameUnverified bool      `json:"realname_unverified"`
+	Countries          []string  `json:"countries"`
+	MinInstances       int       `json:"min_instances"`
+	MinRecentRefunds   int       `json:"min_recent_refunds"`
+	Reason             string    `json:"reason"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+type orderApprovalEvaluationDTO struct {
+	OrderID          int64     `json:"order_id"`
+	Decision         string    `json:"decision"`
+	RuleID           int64     `json:"rule_id"`
+	RuleName         string    `json:"rule_name"`
+	Reason           string    `json:"reason"`
+	Amount           float64   `json:"amount"`
+	AccountAgeDays   int       `json:"account_age_days"`
+	RealNameVerified bool      `json:"realname_verified"`
+	ClientIP         string    `json:"client_ip"`
+	Country          string    `json:"country"`
+	Instances        int       `json:"instances"`
+	RecentRefunds    int       `json:"recent_refunds"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type orderApprovalDryRunItemDTO struct {
+	OrderID   int64                       `json:"order_id"`
+	OrderNo   string                      `json:"order_no"`
+	UserID    int64                       `json:"user_id"`
+	Status    string                      `json:"status"`
+	Result    orderApprovalEvaluationDTO  `json:"result"`
+	Recorded  *orderApprovalEvaluationDTO `json:"recorded"`
+	Different bool                        `json:"different"`
+}
+
+type orderApprovalRulePayload struct {
+	Name               string   `json:"name" binding:"required,max=128"`
+	Priority           int      `json:"priority"`
+	Enabled            *bool    `json:"enabled"`
+	Decision           string   `json:"decision" binding:"required,oneof=approve review reject"`
+	MinAmount          float64  `json:"min_amount" binding:"min=0"`
+	MaxAccountAgeDays  int      `json:"max_account_age_days" binding:"min=0"`
+	RealNameUnverified bool     `json:"realname_unverified"`
+	Countries          []string `json:"countries" binding:"max=100"`
+	MinInstances       int      `json:"min_instances" binding:"min=0"`
+	MinRecentRefunds   int      `json:"min_recent_refunds" binding:"min=0"`
+	Reason             string   `json:"reason" binding:"max=500"`
+}
+
+func (p orderApprovalRulePayload) toDomain(id int64) domain.OrderApprovalRule {
+	enabled := true
+	if p.Enabled != nil {
+		enabled = *p.Enabled
+	}
+	return domain.OrderApprovalRule{
+		ID:                 id,
+		Name:               p.Name,
+		Priority:           p.Priority,
+		Enabled:            enabled,
+		Decision:           domain.OrderApprovalDecision(p.Decision),
+		MinAmount:          floatToCents(p.MinAmount),
+		MaxAccountAgeDays:  p.MaxAccountAgeDays,
+		RealNameUnverified: p.RealNameUnverified,
+		Countries:          p.Countries,
+		MinInstances:       p.MinInstances,
+		MinRecentRefunds:   p.MinRecentRefunds,
+		Reason:             p.Reason,
+	}
+}
+
+func toOrderApprovalRuleDTO(rule domain.OrderApprovalRule) orderApprovalRuleDTO {
+	countries := rule.Countries
+	if countries == nil {
+		countries = []string{}
+	}
+	return orderApprovalRuleDTO{
+		ID:                 rule.ID,
+		Name:               rule.Name,
+		Priority:           rule.Priority,
+		Enabled:            rule.Enabled,
+		Decision:           string(rule.Decision),
+		MinAmount:          centsToFloat(rule.MinAmount),
+		MaxAccountAgeDays:  rule.MaxAccountAgeDays,
+		RealNameUnverified: rule.RealNameUnverified,
+		Countries:          countries,
+		MinInstances:       rule.MinInstances,
+		MinRecentRefunds:   rule.MinRecentRefunds,
+		Reason:             rule.Reason,
+		CreatedAt:          rule.CreatedAt,
+		UpdatedAt:          rule.UpdatedAt,
+	}
+}
+
+func toOrderApprovalEvaluationDTO(eval domain.OrderApprovalEvaluation) orderApprovalEvaluationDTO {
+	return orderApprovalEvaluationDTO{
+		OrderID:          eval.OrderID,
+		Decision:         string(eval.Decision),
+		RuleID:           eval.RuleID,
+		RuleName:         eval.RuleName,
+		Reason:           eval.Reason,
+		Amount:           centsToFloat(eval.Facts.Amount),
+		AccountAgeDays:   eval.Facts.AccountAgeDays,
+		RealNameVerified: eval.Facts.RealNameVerified,
+		ClientIP:         eval.Facts.ClientIP,
+		Country:          eval.Facts.Country,
+		Instances:        eval.Facts.Instances,
+		RecentRefunds:    eval.Facts.RecentRefunds,
+		CreatedAt:        eval.CreatedAt,
+	}
+}
+
+func orderApprovalErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidApprovalRule), errors.Is(err, appshared.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, appshared.ErrNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *Handler) AdminOrderApprovalRules(c *gin.Context) {
+	if h.orderApprovalSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	rules, err := h.orderApprovalSvc.ListRules(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrListError.Error()})
+		return
+	}
+	resp := make([]orderApprovalRuleDTO, 0, len(rules))
+	for _, rule := range rules {
+		resp = append(resp, toOrderApprovalRuleDTO(rule))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "total": len(resp)})
+}
+
+func (h *Handler) AdminOrderApprovalRuleCreate(c *gin.Context) {
+	if h.orderApprovalSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var payload orderApprovalRulePayload
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	rule, err := h.orderApprovalSvc.CreateRule(c, payload.toDomain(0))
+	if err != nil {
+		c.JSON(orderApprovalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toOrderApprovalRuleDTO(rule))
+}
+
+func (h *Handler) AdminOrderApprovalRuleUpdate(c *gin.Context) {
+	if h.orderApprovalSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var payload orderApprovalRulePayload
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	rule, err := h.orderApprovalSvc.UpdateRule(c, payload.toDomain(uri.ID))
+	if err != nil {
+		c.JSON(orderApprovalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toOrderApprovalRuleDTO(rule))
+}
+
+func (h *Handler) AdminOrderApprovalRuleDelete(c *gin.Context) {
+	if h.orderApprovalSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	if err := h.orderApprovalSvc.DeleteRule(c, uri.ID); err != nil {
+		c.JSON(orderApprovalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func (h *Handler) AdminOrderApprovalDryRun(c *gin.Context) {
+	if h.orderApprovalSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var payload struct {
+		OrderIDs []int64                    `json:"order_ids" binding:"max=500,dive,gt=0"`
+		From     string                     `json:"from"`
+		To       string                     `json:"to"`
+		Limit    int                        `json:"limit" binding:"min=0,max=500"`
+		Rules    []orderApprovalRulePayload `json:"rules" binding:"omitempty,max=100,dive"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	in := apporderapproval.DryRunInput{OrderIDs: payload.OrderIDs, Limit: payload.Limit}
+	if len(payload.OrderIDs) == 0 {
+		from, errFrom := parseQueryTime(payload.From)
+		to, errTo := parseQueryTime(payload.To)
+		if errFrom != nil || errTo != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+			return
+		}
+		in.From, in.To = &from, &to
+	}
+	if payload.Rules != nil {
+		in.Rules = make([]domain.OrderApprovalRule, 0, len(payload.Rules))
+		for i, rule := range payload.Rules {
+			in.Rules = append(in.Rules, rule.toDomain(int64(i+1)))
+		}
+	}
+	summary, err := h.orderApprovalSvc.DryRun(c, in)
+	if err != nil {
+		c.JSON(orderApprovalErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	items := make([]orderApprovalDryRunItemDTO, 0, len(summary.Results))
+	for _, item := range summary.Results {
+		row := orderApprovalDryRunItemDTO{
+			OrderID:   item.Order.ID,
+			OrderNo:   item.Order.OrderNo,
+			UserID:    item.Order.UserID,
+			Status:    string(item.Order.Status),
+			Result:    toOrderApprovalEvaluationDTO(item.Result),
+			Different: item.Different,
+		}
+		if item.Recorded != nil {
+			recorded := toOrderApprovalEvaluationDTO(*item.Recorded)
+			row.Recorded = &recorded
+		}
+		items = append(items, row)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"total":   summary.Total,
+		"approve": summary.Approve,
+		"review":  summary.Review,
+		"reject":  summary.Reject,
+		"changed": summary.Changed,
+		"items":   items,
+	})
+}
+
+func (h *Handler) AdminOrderApproval(c *gin.Context) {
+	if h.orderApprovalSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	eval, err := h.orderApprovalSvc.GetEvaluation(c, uri.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrNotFound.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toOrderApprovalEvaluationDTO(eval))
+}
@@ -0,0 +1,290 @@
+package http
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+type payoutBatchItemDTO struct {
+	ID              int64      `json:"id"`
+	WalletOrderID   int64      `json:"wallet_order_id"`
+	UserID          int64      `json:"user_id"`
+	Amount          float64    `json:"amount"`
+	PayoutAccountID int64      `json:"payout_account_id"`
+	AccountName     string     `json:"account_name"`
+	AccountNo       string     `json:"account_no"`
+	BankName        string     `json:"bank_name"`
+	BankBranch      string     `json:"bank_branch"`
+	Status          string     `json:"status"`
+	Reference       string     `json:"reference"`
+	FailReason      string     `json:"fail_reason"`
+	SettledAt       *time.Time `json:"settled_at,omitempty"`
+}
+
+type payoutBatchDTO struct {
+	ID          int64                `json:"id"`
+	Channel     string               `json:"channel"`
+	Status      string               `json:"status"`
+	ItemCount   int                  `json:"item_count"`
+	TotalAmount float64              `json:"total_amount"`
+	Reference   string               `json:"reference"`
+	Note        string               `json:"note"`
+	CreatedBy   int64                `json:"created_by"`
+	ExportedAt  *time.Time           `json:"exported_at,omitempty"`
+	SettledAt   *time.Time           `json:"settled_at,omitempty"`
+	CreatedAt   time.Time            `json:"created_at"`
+	Items       []payoutBatchItemDTO `json:"items,omitempty"`
+}
+
+func toPayoutBatchDTO(batch domain.PayoutBatch) payoutBatchDTO {
+	out := payoutBatchDTO{
+		ID:          batch.ID,
+		Channel:     string(batch.Channel),
+		Status:      string(batch.Status),
+		ItemCount:   batch.ItemCount,
+		TotalAmount: centsToFloat(batch.TotalAmount),
+		Reference:   batch.Reference,
+		Note:        batch.Note,
+		CreatedBy:   batch.CreatedBy,
+		ExportedAt:  batch.ExportedAt,
+		SettledAt:   batch.SettledAt,
+		CreatedAt:   batch.CreatedAt,
+	}
+	for _, item := range batch.Items {
+		out.Items = append(out.Items, payoutBatchItemDTO{
+			ID:              item.ID,
+			WalletOrderID:   item.WalletOrderID,
+			UserID:          item.UserID,
+			Amount:          centsToFloat(item.Amount),
+			PayoutAccountID: item.PayoutAccountID,
+			AccountName:     item.AccountName,
+			AccountNo:       item.AccountNo,
+			BankName:        item.BankName,
+			BankBranch:      item.BankBranch,
+			Status:          string(item.Status),
+			Reference:       item.Reference,
+			FailReason:      item.FailReason,
+			SettledAt:       item.SettledAt,
+		})
+	}
+	return out
+}
+
+func (h *Handler) AdminPayoutAccounts(c *gin.Context) {
+	if h.payoutSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var query struct {
+		Status string `form:"status" binding:"omitempty,oneof=pending verified rejected"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	limit, offset := paging(c)
+	items, total, err := h.payoutSvc.ListAccounts(c, query.Status, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrListError.Error()})
+		return
+	}
+	resp := make([]payoutAccountDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toPayoutAccountDTO(item, true))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "total": total})
+}
+
+func (h *Handler) AdminPayoutAccountVerify(c *gin.Context) {
+	h.reviewPayoutAccount(c, true)
+}
+
+func (h *Handler) AdminPayoutAccountReject(c *gin.Context) {
+	h.reviewPayoutAccount(c, false)
+}
+
+func (h *Handler) reviewPayoutAccount(c *gin.Context, approve bool) {
+	if h.payoutSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var payload struct {
+		Reason string `json:"reason" binding:"omitempty,max=1000"`
+	}
+	if err := bindJSONOptional(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	account, err := h.payoutSvc.ReviewAccount(c, getUserID(c), uri.ID, approve, payload.Reason)
+	if err != nil {
+		c.JSON(payoutErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toPayoutAccountDTO(account, true))
+}
+
+func (h *Handler) AdminPayoutBatches(c *gin.Context) {
+	if h.payoutSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var query struct {
+		Status string `form:"status" binding:"omitempty,oneof=pending paid partial failed"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	limit, offset := paging(c)
+	items, total, err := h.payoutSvc.ListBatches(c, query.Status, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrListError.Error()})
+		return
+	}
+	resp := make([]payoutBatchDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toPayoutBatchDTO(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "total": total})
+}
+
+func (h *Handler) AdminPayoutBatchCreate(c *gin.Context) {
+	if h.payoutSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var payload struct {
+		Channel  string  `json:"channel" binding:"required,oneof=bank alipay"`
+		OrderIDs []int64 `json:"order_ids" binding:"omitempty,max=500,dive,gt=0"`
+		Note     string  `json:"note" binding:"omitempty,max=1000"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	batch, err := h.payoutSvc.CreateBatch(c, getUserID(c), appshared.PayoutBatchCreateInput{
+		Channel:  payload.Channel,
+		OrderIDs: payload.OrderIDs,
+		Note:     payload.Note,
+	})
+	if err != nil {
+		c.JSON(payoutErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toPayoutBatchDTO(batch))
+}
+
+func (h *Handler) AdminPayoutBatchDetail(c *gin.Context) {
+	if h.payoutSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	batch, err := h.payoutSvc.GetBatch(c, uri.ID)
+	if err != nil {
+		c.JSON(payoutErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toPayoutBatchDTO(batch))
+}
+
+func (h *Handler) AdminPayoutBatchExport(c *gin.Context) {
+	if h.payoutSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	batch, records, err := h.payoutSvc.ExportBatch(c, uri.ID)
+	if err != nil {
+		c.JSON(payoutErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	fileName := fmt.Sprintf("payout_batch_%d_%s.csv", batch.ID, batch.Channel)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", fileName))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	if _, err := c.Writer.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+		return
+	}
+	w := csv.NewWriter(c.Writer)
+	_ = w.WriteAll(records)
+}
+
+func (h *Handler) AdminPayoutBatchPaid(c *gin.Context) {
+	if h.payoutSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var payload struct {
+		Reference   string `json:"reference" binding:"required,max=255"`
+		FailedItems []struct {
+			ItemID int64  `json:"item_id" binding:"required,gt=0"`
+			Reason string `json:"reason" binding:"omitempty,max=1000"`
+		} `json:"failed_items" binding:"omitempty,dive"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	failed := make(map[int64]string, len(payload.FailedItems))
+	for _, item := range payload.FailedItems {
+		failed[item.ItemID] = item.Reason
+	}
+	batch, err := h.payoutSvc.MarkPaid(c, getUserID(c), uri.ID, payload.Reference, failed)
+	if err != nil {
+		c.JSON(payoutErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toPayoutBatchDTO(batch))
+}
+
+func (h *Handler) AdminPayoutBatchFailed(c *gin.Context) {
+	if h.payoutSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var payload struct {
+		Reason string `json:"reason" binding:"required,max=1000"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	batch, err := h.payoutSvc.MarkFailed(c, getUserID(c), uri.ID, payload.Reason)
+	if err != nil {
+		c.JSON(payoutErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toPayoutBatchDTO(batch))
+}
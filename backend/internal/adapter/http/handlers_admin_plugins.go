@@ -2,6 +2,7 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
@@ -236,6 +237,80 @@ func (h *Handler) AdminPluginsDiscover(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"items": items})
 }
 
+func (h *Handler) AdminPluginRepositoryList(c *gin.Context) {
+	if h.pluginAdmin == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrPluginsDisabled.Error()})
+		return
+	}
+	repos, err := h.pluginAdmin.ListRepositories(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	items, failures, err := h.pluginAdmin.BrowseRepositories(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"repositories": repos, "items": items, "errors": failures})
+}
+
+type pluginRepositoryInstallRequest struct {
+	Repository    string `json:"repository" binding:"required,max=128"`
+	Category      string `json:"category" binding:"required,max=64"`
+	PluginID      string `json:"plugin_id" binding:"required,max=128"`
+	Version       string `json:"version" binding:"omitempty,max=64"`
+	AdminPassword string `json:"admin_password"`
+}
+
+// AdminPluginRepositoryInstall installs a plugin from a configured repository,
+// or upgrades it in place (keeping instance configs) when already installed.
+// Packages without an official signature need the admin password.
+func (h *Handler) AdminPluginRepositoryInstall(c *gin.Context) {
+	if h.pluginAdmin == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrPluginsDisabled.Error()})
+		return
+	}
+	var payload pluginRepositoryInstallRequest
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	allowUntrusted := false
+	if adminPassword := strings.TrimSpace(payload.AdminPassword); adminPassword != "" {
+		if h.authSvc == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrAuthDisabled.Error()})
+			return
+		}
+		if err := h.authSvc.VerifyPassword(c, getUserID(c), adminPassword); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": domain.ErrInvalidAdminPassword.Error()})
+			return
+		}
+		allowUntrusted = true
+	}
+
+	inst, err := h.pluginAdmin.InstallFromRepository(c, payload.Repository, payload.Category, payload.PluginID, payload.Version, allowUntrusted)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrAdminPasswordRequiredForUntrustedPlugin):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrPluginRepositoryNotFound), errors.Is(err, domain.ErrPluginVersionNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	if h.adminSvc != nil {
+		h.adminSvc.Audit(c, getUserID(c), "plugin.repository_install", "plugin", inst.Category+"/"+inst.PluginID, map[string]any{
+			"repository":       payload.Repository,
+			"version":          payload.Version,
+			"signature_status": inst.SignatureStatus,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true, "plugin": inst})
+}
+
 func (h *Handler) AdminPluginPaymentMethodsList(c *gin.Context) {
 	if h.pluginAdmin == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrPluginsDisabled.Error()})
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+type trialPackageStatDTO struct {
+	PackageID      int64   `json:"package_id"`
+	PackageName    string  `json:"package_name"`
+	Total          int     `json:"total"`
+	Active         int     `json:"active"`
+	Converted      int     `json:"converted"`
+	Expired        int     `json:"expired"`
+	ConversionRate float64 `json:"conversion_rate"`
+}
+
+func (h *Handler) AdminTrials(c *gin.Context) {
+	if h.trialSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var query struct {
+		UserID    int64  `form:"user_id" binding:"omitempty,gt=0"`
+		PackageID int64  `form:"package_id" binding:"omitempty,gt=0"`
+		Status    string `form:"status" binding:"omitempty,oneof=active converted expired"`
+		ClientIP  string `form:"client_ip" binding:"max=64"`
+		DeviceID  string `form:"device_id" binding:"max=128"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	limit, offset := paging(c)
+	items, total, err := h.trialSvc.List(c, appshared.VPSTrialFilter{
+		UserID:    query.UserID,
+		PackageID: query.PackageID,
+		Status:    query.Status,
+		ClientIP:  query.ClientIP,
+		DeviceID:  query.DeviceID,
+	}, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrListError.Error()})
+		return
+	}
+	resp := make([]adminVPSTrialDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, adminVPSTrialDTO{
+			vpsTrialDTO: toVPSTrialDTO(item),
+			Phone:       item.Phone,
+			ClientIP:    item.ClientIP,
+			DeviceID:    item.DeviceID,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "total": total})
+}
+
+// AdminDashboardTrials reports trial conversion for trials started between
+// from and to (RFC3339 or YYYY-MM-DD), defaulting to the last 30 days.
+func (h *Handler) AdminDashboardTrials(c *gin.Context) {
+	if h.trialSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	to := time.Now()
+	from := to.AddDate(0, 0, -30)
+	if raw := c.Query("from"); raw != "" {
+		t, err := parseQueryTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+			return
+		}
+		from = t
+	}
+	if raw := c.Query("to"); raw != "" {
+		t, err := parseQueryTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+			return
+		}
+		to = t
+	}
+	stats, err := h.trialSvc.Stats(c, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	packages := make([]trialPackageStatDTO, 0, len(stats.Packages))
+	for _, item := range stats.Packages {
+		packages = append(packages, trialPackageStatDTO{
+			PackageID:      item.PackageID,
+			PackageName:    item.PackageName,
+			Total:          item.Total,
+			Active:         item.Active,
+			Converted:      item.Converted,
+			Expired:        item.Expired,
+			ConversionRate: item.ConversionRate,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"from":            from,
+		"to":              to,
+		"total":           stats.Total,
+		"active":          stats.Active,
+		"converted":       stats.Converted,
+		"expired":         stats.Expired,
+		"conversion_rate": stats.ConversionRate,
+		"packages":        packages,
+	})
+}
@@ -148,6 +148,7 @@ func (h *Handler) AdminUserTierDiscountRuleCreate(c *gin.Context) {
 		RegionID:         payload.RegionID,
 		PlanGroupID:      payload.PlanGroupID,
 		PackageID:        payload.PackageID,
+		AddonID:          payload.AddonID,
 		DiscountPermille: payload.DiscountPermille,
 		FixedPrice:       payload.FixedPrice,
 		AddCorePermille:  payload.AddCorePermille,
@@ -185,6 +186,7 @@ func (h *Handler) AdminUserTierDiscountRuleUpdate(c *gin.Context) {
 		RegionID:         payload.RegionID,
 		PlanGroupID:      payload.PlanGroupID,
 		PackageID:        payload.PackageID,
+		AddonID:          payload.AddonID,
 		DiscountPermille: payload.DiscountPermille,
 		FixedPrice:       payload.FixedPrice,
 		AddCorePermille:  payload.AddCorePermille,
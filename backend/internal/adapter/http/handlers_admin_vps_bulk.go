@@ -0,0 +1,263 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	appvpsbulk "xiaoheiplay/internal/app/vpsbulk"
+	"xiaoheiplay/internal/domain"
+)
+
+type vpsBulkJobDTO struct {
+	ID          int64                `json:"id"`
+	AdminID     int64                `json:"admin_id"`
+	Action      string               `json:"action"`
+	Params      appvpsbulk.Params    `json:"params"`
+	Filter      domain.VPSBulkFilter `json:"filter"`
+	Concurrency int                  `json:"concurrency"`
+	Status      string               `json:"status"`
+	Total       int                  `json:"total"`
+	Succeeded   int                  `json:"succeeded"`
+	Failed      int                  `json:"failed"`
+	Canceled    int                  `json:"canceled"`
+	Pending     int                  `json:"pending"`
+	CreatedAt   time.Time            `json:"created_at"`
+	FinishedAt  *time.Time           `json:"finished_at,omitempty"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+type vpsBulkJobItemDTO struct {
+	VPSID      int64      `json:"vps_id"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	Seq        int64      `json:"seq,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+func toVPSBulkJobDTO(job domain.VPSBulkJob) vpsBulkJobDTO {
+	dto := vpsBulkJobDTO{
+		ID:          job.ID,
+		AdminID:     job.AdminID,
+		Action:      string(job.Action),
+		Concurrency: job.Concurrency,
+		Status:      string(job.Status),
+		Total:       job.Total,
+		Succeeded:   job.Succeeded,
+		Failed:      job.Failed,
+		Canceled:    job.Canceled,
+		CreatedAt:   job.CreatedAt,
+		FinishedAt:  job.FinishedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+	_ = json.Unmarshal([]byte(job.ParamsJSON), &dto.Params)
+	_ = json.Unmarshal([]byte(job.FilterJSON), &dto.Filter)
+	dto.Pending = job.Total - job.Succeeded - job.Failed - job.Canceled
+	if dto.Pending < 0 {
+		dto.Pending = 0
+	}
+	return dto
+}
+
+func toVPSBulkJobItemDTO(item domain.VPSBulkJobItem) vpsBulkJobItemDTO {
+	return vpsBulkJobItemDTO{
+		VPSID:      item.VPSID,
+		Status:     string(item.Status),
+		Error:      item.Error,
+		Seq:        item.Seq,
+		FinishedAt: item.FinishedAt,
+	}
+}
+
+func (h *Handler) AdminVPSBulkJobs(c *gin.Context) {
+	if h.vpsBulkSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	limit, offset := paging(c)
+	items, total, err := h.vpsBulkSvc.List(c, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrListError.Error()})
+		return
+	}
+	resp := make([]vpsBulkJobDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toVPSBulkJobDTO(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "total": total})
+}
+
+func (h *Handler) AdminVPSBulkJobCreate(c *gin.Context) {
+	if h.vpsBulkSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var payload struct {
+		Action      string               `json:"action" binding:"required"`
+		Params      appvpsbulk.Params    `json:"params"`
+		Filter      domain.VPSBulkFilter `json:"filter"`
+		Concurrency int                  `json:"concurrency" binding:"omitempty,min=1,max=20"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	job, err := h.vpsBulkSvc.Start(c, getUserID(c), appvpsbulk.StartInput{
+		Action:      domain.VPSBulkAction(payload.Action),
+		Params:      payload.Params,
+		Filter:      payload.Filter,
+		Concurrency: payload.Concurrency,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toVPSBulkJobDTO(job))
+}
+
+func (h *Handler) AdminVPSBulkJobDetail(c *gin.Context) {
+	if h.vpsBulkSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var query struct {
+		Status string `form:"status" binding:"omitempty,oneof=pending success failed canceled"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	job, err := h.vpsBulkSvc.Get(c, uri.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrNotFound.Error()})
+		return
+	}
+	items, err := h.vpsBulkSvc.Items(c, job.ID, domain.VPSBulkItemStatus(query.Status))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrListError.Error()})
+		return
+	}
+	resp := make([]vpsBulkJobItemDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toVPSBulkJobItemDTO(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"job": toVPSBulkJobDTO(job), "items": resp})
+}
+
+func (h *Handler) AdminVPSBulkJobCancel(c *gin.Context) {
+	if h.vpsBulkSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	job, err := h.vpsBulkSvc.Cancel(c, uri.ID)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, appshared.ErrNotFound) {
+			status = http.StatusNotFound
+		} else if errors.Is(err, domain.ErrBulkJobFinished) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toVPSBulkJobDTO(job))
+}
+
+// AdminVPSBulkJobStream streams job progress as server-sent events: an "item"
+// event per finished instance (its id is the item seq, so Last-Event-ID
+// resumes), a "progress" event whenever the counters move and a final "done"
+// event once the job has stopped.
+func (h *Handler) AdminVPSBulkJobStream(c *gin.Context) {
+	if h.vpsBulkSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	job, err := h.vpsBulkSvc.Get(c, uri.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrNotFound.Error()})
+		return
+	}
+	var lastSeq int64
+	if last := c.GetHeader("Last-Event-ID"); last != "" {
+		lastSeq, _ = strconv.ParseInt(last, 10, 64)
+	}
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrStreamUnsupported.Error()})
+		return
+	}
+	send := func(event string, id string, payload any) {
+		body, _ := json.Marshal(payload)
+		if id != "" {
+			fmt.Fprintf(c.Writer, "id: %s\n", id)
+		}
+		fmt.Fprintf(c.Writer, "event: %s\n", event)
+		fmt.Fprintf(c.Writer, "data: %s\n\n", string(body))
+	}
+
+	poll := time.NewTicker(time.Second)
+	defer poll.Stop()
+	ping := time.NewTicker(15 * time.Second)
+	defer ping.Stop()
+	var lastUpdate time.Time
+	for {
+		items, err := h.vpsBulkSvc.ItemsAfter(c, job.ID, lastSeq, 200)
+		if err != nil {
+			return
+		}
+		for _, item := range items {
+			send("item", strconv.FormatInt(item.Seq, 10), toVPSBulkJobItemDTO(item))
+			lastSeq = item.Seq
+		}
+		if job, err = h.vpsBulkSvc.Get(c, job.ID); err != nil {
+			return
+		}
+		if !job.UpdatedAt.Equal(lastUpdate) {
+			send("progress", "", toVPSBulkJobDTO(job))
+			lastUpdate = job.UpdatedAt
+		}
+		if job.Status != domain.VPSBulkJobStatusRunning && len(items) == 0 {
+			send("done", "", toVPSBulkJobDTO(job))
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+		if len(items) > 0 {
+			continue
+		}
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ping.C:
+			fmt.Fprintf(c.Writer, ": ping\n\n")
+			flusher.Flush()
+		case <-poll.C:
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	appvpsmigration "xiaoheiplay/internal/app/vpsmigration"
+	"xiaoheiplay/internal/domain"
+)
+
+type vpsMigrationDTO struct {
+	ID                int64      `json:"id"`
+	VPSID             int64      `json:"vps_id"`
+	UserID            int64      `json:"user_id"`
+	AdminID           int64      `json:"admin_id"`
+	SourceGoodsTypeID int64      `json:"source_goods_type_id"`
+	SourceInstanceID  string     `json:"source_instance_id"`
+	SourceLineID      int64      `json:"source_line_id"`
+	SourcePackageID   int64      `json:"source_package_id"`
+	TargetGoodsTypeID int64      `json:"target_goods_type_id"`
+	TargetLineID      int64      `json:"target_line_id"`
+	TargetRegionID    int64      `json:"target_region_id"`
+	TargetPackageID   int64      `json:"target_package_id"`
+	TargetSystemID    int64      `json:"target_system_id"`
+	TargetInstanceID  string     `json:"target_instance_id"`
+	TransferData      bool       `json:"transfer_data"`
+	BackupID          int64      `json:"backup_id,omitempty"`
+	Status            string     `json:"status"`
+	Step              string     `json:"step"`
+	Attempts          int        `json:"attempts"`
+	MaxAttempts       int        `json:"max_attempts"`
+	LastError         string     `json:"last_error,omitempty"`
+	NextRunAt         time.Time  `json:"next_run_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	FinishedAt        *time.Time `json:"finished_at,omitempty"`
+}
+
+func toVPSMigrationDTO(m domain.VPSMigration) vpsMigrationDTO {
+	return vpsMigrationDTO{
+		ID:                m.ID,
+		VPSID:             m.VPSID,
+		UserID:            m.UserID,
+		AdminID:           m.AdminID,
+		SourceGoodsTypeID: m.SourceGoodsTypeID,
+		SourceInstanceID:  m.SourceInstanceID,
+		SourceLineID:      m.SourceLineID,
+		SourcePackageID:   m.SourcePackageID,
+		TargetGoodsTypeID: m.TargetGoodsTypeID,
+		TargetLineID:      m.TargetLineID,
+		TargetRegionID:    m.TargetRegionID,
+		TargetPackageID:   m.TargetPackageID,
+		TargetSystemID:    m.TargetSystemID,
+		TargetInstanceID:  m.TargetInstanceID,
+		TransferData:      m.TransferData,
+		BackupID:          m.BackupID,
+		Status:            string(m.Status),
+		Step:              string(m.Step),
+		Attempts:          m.Attempts,
+		MaxAttempts:       m.MaxAttempts,
+		LastError:         m.LastError,
+		NextRunAt:         m.NextRunAt,
+		CreatedAt:         m.CreatedAt,
+		UpdatedAt:         m.UpdatedAt,
+		FinishedAt:        m.FinishedAt,
+	}
+}
+
+func vpsMigrationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, appshared.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrMigrationInProgress), errors.Is(err, domain.ErrMigrationStateConflict):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidMigration), errors.Is(err, domain.ErrMigrationTransferUnsupported):
+		return http.StatusBadRequest
+	}
+	return http.StatusBadGateway
+}
+
+func (h *Handler) AdminVPSMigrations(c *gin.Context) {
+	if h.vpsMigrationSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var query struct {
+		VPSID int64 `form:"vps_id" binding:"omitempty,gt=0"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	limit, offset := paging(c)
+	items, total, err := h.vpsMigrationSvc.List(c, query.VPSID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrListError.Error()})
+		return
+	}
+	resp := make([]vpsMigrationDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toVPSMigrationDTO(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "total": total})
+}
+
+func (h *Handler) AdminVPSMigrationCreate(c *gin.Context) {
+	if h.vpsMigrationSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var payload struct {
+		VPSID           int64 `json:"vps_id" binding:"required,gt=0"`
+		TargetPackageID int64 `json:"target_package_id" binding:"required,gt=0"`
+		TargetSystemID  int64 `json:"target_system_id" binding:"omitempty,gt=0"`
+		TransferData    bool  `json:"transfer_data"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	m, err := h.vpsMigrationSvc.Start(c, getUserID(c), appvpsmigration.StartInput{
+		VPSID:           payload.VPSID,
+		TargetPackageID: payload.TargetPackageID,
+		TargetSystemID:  payload.TargetSystemID,
+		TransferData:    payload.TransferData,
+	})
+	if err != nil {
+		c.JSON(vpsMigrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toVPSMigrationDTO(m))
+}
+
+func (h *Handler) AdminVPSMigrationDetail(c *gin.Context) {
+	if h.vpsMigrationSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	m, err := h.vpsMigrationSvc.Get(c, uri.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrNotFound.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toVPSMigrationDTO(m))
+}
+
+func (h *Handler) AdminVPSMigrationRetry(c *gin.Context) {
+	if h.vpsMigrationSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	h.adminVPSMigrationAction(c, h.vpsMigrationSvc.Retry)
+}
+
+func (h *Handler) AdminVPSMigrationCancel(c *gin.Context) {
+	if h.vpsMigrationSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	h.adminVPSMigrationAction(c, h.vpsMigrationSvc.Cancel)
+}
+
+func (h *Handler) adminVPSMigrationAction(c *gin.Context, action func(ctx context.Context, id int64) (domain.VPSMigration, error)) {
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	m, err := action(c, uri.ID)
+	if err != nil {
+		c.JSON(vpsMigrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toVPSMigrationDTO(m))
+}
@@ -88,6 +88,30 @@ func (h *Handler) AdminWalletTransactions(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"items": toWalletTransactionDTOs(items), "total": total})
 }
 
+func (h *Handler) AdminWalletHolds(c *gin.Context) {
+	if h.walletSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrWalletDisabled.Error()})
+		return
+	}
+	var uri adminUserIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var query walletHoldQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	limit, offset := paging(c)
+	items, total, err := h.walletSvc.ListHolds(c, uri.UserID, query.Status, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": toWalletHoldDTOs(items), "total": total})
+}
+
 func (h *Handler) AdminWalletOrders(c *gin.Context) {
 	if h.walletOrder == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrWalletOrdersDisabled.Error()})
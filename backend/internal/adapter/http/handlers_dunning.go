@@ -0,0 +1,297 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	appdunning "xiaoheiplay/internal/app/dunning"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+type dunningStepDTO struct {
+	OffsetDays int      `json:"offset_days"`
+	Action     string   `json:"action"`
+	Channels   []string `json:"channels"`
+}
+
+type dunningPolicyDTO struct {
+	ID          int64            `json:"id"`
+	GoodsTypeID int64            `json:"goods_type_id"`
+	Enabled     bool             `json:"enabled"`
+	Steps       []dunningStepDTO `json:"steps"`
+	UpdatedBy   int64            `json:"updated_by"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+type dunningRecordDTO struct {
+	ID            int64     `json:"id"`
+	InstanceID    int64     `json:"instance_id"`
+	UserID        int64     `json:"user_id"`
+	GoodsTypeID   int64     `json:"goods_type_id"`
+	CycleExpireAt time.Time `json:"cycle_expire_at"`
+	StepKey       string    `json:"step_key"`
+	Action        string    `json:"action"`
+	OffsetDays    int       `json:"offset_days"`
+	Status        string    `json:"status"`
+	Channels      []string  `json:"channels"`
+	Error         string    `json:"error"`
+	Attempts      int       `json:"attempts"`
+	ExecutedAt    time.Time `json:"executed_at"`
+}
+
+type dunningStepViewDTO struct {
+	dunningStepDTO
+	DueAt  time.Time         `json:"due_at"`
+	State  string            `json:"state"`
+	Record *dunningRecordDTO `json:"record,omitempty"`
+}
+
+type dunningViewDTO struct {
+	InstanceID  int64                `json:"instance_id"`
+	GoodsTypeID int64                `json:"goods_type_id"`
+	ExpireAt    *time.Time           `json:"expire_at,omitempty"`
+	Stage       string               `json:"stage"`
+	Policy      *dunningPolicyDTO    `json:"policy,omitempty"`
+	Steps       []dunningStepViewDTO `json:"steps"`
+	History     []dunningRecordDTO   `json:"history"`
+}
+
+type dunningPolicyURI struct {
+	GoodsTypeID int64 `uri:"goods_type_id" binding:"min=0"`
+}
+
+type dunningPolicyPayload struct {
+	Enabled *bool `json:"enabled"`
+	Steps   []struct {
+		OffsetDays int      `json:"offset_days" binding:"min=-30,max=365"`
+		Action     string   `json:"action" binding:"required,oneof=remind suspend final_warning terminate"`
+		Channels   []string `json:"channels" binding:"omitempty,max=3,dive,oneof=email sms inapp"`
+	} `json:"steps" binding:"omitempty,max=20,dive"`
+}
+
+type dunningRecordQuery struct {
+	InstanceID int64  `form:"instance_id" binding:"omitempty,gt=0"`
+	Action     string `form:"action" binding:"omitempty,oneof=remind suspend final_warning terminate"`
+	Status     string `form:"status" binding:"omitempty,oneof=success failed skipped"`
+}
+
+func toDunningStepDTOs(steps []domain.DunningStep) []dunningStepDTO {
+	out := make([]dunningStepDTO, 0, len(steps))
+	for _, step := range steps {
+		channels := step.Channels
+		if channels == nil {
+			channels = []string{}
+		}
+		out = append(out, dunningStepDTO{OffsetDays: step.OffsetDays, Action: string(step.Action), Channels: channels})
+	}
+	return out
+}
+
+func toDunningPolicyDTO(p domain.DunningPolicy) dunningPolicyDTO {
+	return dunningPolicyDTO{
+		ID:          p.ID,
+		GoodsTypeID: p.GoodsTypeID,
+		Enabled:     p.Enabled,
+		Steps:       toDunningStepDTOs(p.Steps),
+		UpdatedBy:   p.UpdatedBy,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+func toDunningRecordDTO(r domain.DunningRecord) dunningRecordDTO {
+	channels := r.Channels
+	if channels == nil {
+		channels = []string{}
+	}
+	return dunningRecordDTO{
+		ID:            r.ID,
+		InstanceID:    r.InstanceID,
+		UserID:        r.UserID,
+		GoodsTypeID:   r.GoodsTypeID,
+		CycleExpireAt: r.CycleExpireAt,
+		StepKey:       r.StepKey,
+		Action:        string(r.Action),
+		OffsetDays:    r.OffsetDays,
+		Status:        string(r.Status),
+		Channels:      channels,
+		Error:         r.Error,
+		Attempts:      r.Attempts,
+		ExecutedAt:    r.ExecutedAt,
+	}
+}
+
+func toDunningRecordDTOs(records []domain.DunningRecord) []dunningRecordDTO {
+	out := make([]dunningRecordDTO, 0, len(records))
+	for _, r := range records {
+		out = append(out, toDunningRecordDTO(r))
+	}
+	return out
+}
+
+func toDunningViewDTO(v appdunning.View) dunningViewDTO {
+	resp := dunningViewDTO{
+		InstanceID:  v.Instance.ID,
+		GoodsTypeID: v.Instance.GoodsTypeID,
+		ExpireAt:    v.Instance.ExpireAt,
+		Stage:       v.Stage,
+		Steps:       make([]dunningStepViewDTO, 0, len(v.Steps)),
+		History:     toDunningRecordDTOs(v.History),
+	}
+	if v.Policy != nil {
+		policy := toDunningPolicyDTO(*v.Policy)
+		resp.Policy = &policy
+	}
+	for _, sv := range v.Steps {
+		item := dunningStepViewDTO{
+			dunningStepDTO: toDunningStepDTOs([]domain.DunningStep{sv.Step})[0],
+			DueAt:          sv.DueAt,
+			State:          sv.State,
+		}
+		if sv.Record != nil {
+			record := toDunningRecordDTO(*sv.Record)
+			item.Record = &record
+		}
+		resp.Steps = append(resp.Steps, item)
+	}
+	return resp
+}
+
+func dunningErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, appshared.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, appshared.ErrInvalidInput), errors.Is(err, domain.ErrInvalidDunningPolicy):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *Handler) AdminDunningPolicies(c *gin.Context) {
+	if h.dunningSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	items, err := h.dunningSvc.ListPolicies(c)
+	if err != nil {
+		c.JSON(dunningErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]dunningPolicyDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toDunningPolicyDTO(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "default_steps": toDunningStepDTOs(appdunning.DefaultSteps())})
+}
+
+func (h *Handler) AdminDunningPolicySave(c *gin.Context) {
+	if h.dunningSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri dunningPolicyURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var payload dunningPolicyPayload
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	input := appshared.DunningPolicyInput{Enabled: true}
+	if payload.Enabled != nil {
+		input.Enabled = *payload.Enabled
+	}
+	for _, step := range payload.Steps {
+		input.Steps = append(input.Steps, domain.DunningStep{OffsetDays: step.OffsetDays, Action: domain.DunningAction(step.Action), Channels: step.Channels})
+	}
+	policy, err := h.dunningSvc.SavePolicy(c, getUserID(c), uri.GoodsTypeID, input)
+	if err != nil {
+		c.JSON(dunningErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toDunningPolicyDTO(policy))
+}
+
+func (h *Handler) AdminDunningPolicyDelete(c *gin.Context) {
+	if h.dunningSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri dunningPolicyURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	if err := h.dunningSvc.DeletePolicy(c, getUserID(c), uri.GoodsTypeID); err != nil {
+		c.JSON(dunningErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
+func (h *Handler) AdminDunningRecords(c *gin.Context) {
+	if h.dunningSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var query dunningRecordQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	limit, offset := paging(c)
+	items, total, err := h.dunningSvc.ListRecords(c, appshared.DunningRecordFilter{InstanceID: query.InstanceID, Action: query.Action, Status: query.Status}, limit, offset)
+	if err != nil {
+		c.JSON(dunningErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": toDunningRecordDTOs(items), "total": total})
+}
+
+func (h *Handler) AdminVPSDunning(c *gin.Context) {
+	if h.dunningSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	view, err := h.dunningSvc.InstanceView(c, uri.ID)
+	if err != nil {
+		c.JSON(dunningErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toDunningViewDTO(view))
+}
+
+func (h *Handler) VPSDunning(c *gin.Context) {
+	if h.dunningSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri vpsIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	inst, err := h.vpsSvc.Get(c, uri.ID, getUserID(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrNotFound.Error()})
+		return
+	}
+	view, err := h.dunningSvc.ViewOf(c, inst)
+	if err != nil {
+		c.JSON(dunningErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toDunningViewDTO(view))
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"xiaoheiplay/internal/domain"
+	pkgmetrics "xiaoheiplay/internal/pkg/metrics"
+)
+
+// Metrics serves the OpenMetrics exposition. Access is gated by the
+// metrics_api_key setting (Bearer or X-API-Key) or metrics_ip_allowlist.
+func (h *Handler) Metrics(c *gin.Context) {
+	if h.metricsSvc == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrMetricsDisabled.Error()})
+		return
+	}
+	apiKey := strings.TrimSpace(c.GetHeader("X-API-Key"))
+	if auth := strings.TrimSpace(c.GetHeader("Authorization")); strings.HasPrefix(auth, "Bearer ") {
+		apiKey = strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	if err := h.metricsSvc.Authorize(c, apiKey, c.ClientIP()); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrMetricsDisabled):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrUnauthorized):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusForbidden, gin.H{"error": domain.ErrForbidden.Error()})
+		}
+		return
+	}
+	c.Header("Content-Type", pkgmetrics.ContentType)
+	c.Status(http.StatusOK)
+	w := pkgmetrics.NewWriter(c.Writer)
+	h.httpMetrics.Write(w)
+	h.metricsSvc.Collect(c, w)
+	_ = w.EOF()
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	appnotifypref "xiaoheiplay/internal/app/notifypref"
+	"xiaoheiplay/internal/domain"
+)
+
+type NotificationCategoryDTO struct {
+	Key              string   `json:"key"`
+	Name             string   `json:"name"`
+	Mandatory        bool     `json:"mandatory"`
+	Channels         []string `json:"channels"`
+	DisabledChannels []string `json:"disabled_channels"`
+}
+
+type NotificationPreferencesDTO struct {
+	Categories        []NotificationCategoryDTO `json:"categories"`
+	Disabled          map[string][]string       `json:"disabled"`
+	QuietHoursEnabled bool                      `json:"quiet_hours_enabled"`
+	QuietStart        string                    `json:"quiet_start"`
+	QuietEnd          string                    `json:"quiet_end"`
+	Timezone          string                    `json:"timezone"`
+	UpdatedAt         *time.Time                `json:"updated_at"`
+}
+
+func toNotificationPreferencesDTO(prefs domain.NotificationPreferences) NotificationPreferencesDTO {
+	disabled := prefs.Disabled
+	if disabled == nil {
+		disabled = map[string][]string{}
+	}
+	categories := make([]NotificationCategoryDTO, 0, len(appnotifypref.Categories))
+	for _, item := range appnotifypref.Categories {
+		off := disabled[item.Key]
+		if off == nil {
+			off = []string{}
+		}
+		categories = append(categories, NotificationCategoryDTO{
+			Key:              item.Key,
+			Name:             item.Name,
+			Mandatory:        item.Mandatory,
+			Channels:         item.Channels,
+			DisabledChannels: off,
+		})
+	}
+	var updatedAt *time.Time
+	if !prefs.UpdatedAt.IsZero() {
+		updatedAt = &prefs.UpdatedAt
+	}
+	return NotificationPreferencesDTO{
+		Categories:        categories,
+		Disabled:          disabled,
+		QuietHoursEnabled: prefs.QuietHoursEnabled,
+		QuietStart:        prefs.QuietStart,
+		QuietEnd:          prefs.QuietEnd,
+		Timezone:          prefs.Timezone,
+		UpdatedAt:         updatedAt,
+	}
+}
+
+func notificationPreferencesErrorStatus(err error) int {
+	if errors.Is(err, domain.ErrInvalidNotificationPreferences) || errors.Is(err, domain.ErrInvalidInput) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// NotificationPreferences returns the caller's preferences. It serves both
+// users and admins, who use it to mute push for new orders.
+func (h *Handler) NotificationPreferences(c *gin.Context) {
+	if h.notifyPrefSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	prefs, err := h.notifyPrefSvc.Get(c, getUserID(c))
+	if err != nil {
+		c.JSON(notificationPreferencesErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toNotificationPreferencesDTO(prefs))
+}
+
+func (h *Handler) NotificationPreferencesUpdate(c *gin.Context) {
+	if h.notifyPrefSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var payload struct {
+		Disabled          map[string][]string `json:"disabled"`
+		QuietHoursEnabled bool                `json:"quiet_hours_enabled"`
+		QuietStart        string              `json:"quiet_start" binding:"max=5"`
+		QuietEnd          string              `json:"quiet_end" binding:"max=5"`
+		Timezone          string              `json:"timezone" binding:"max=64"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	prefs, err := h.notifyPrefSvc.Update(c, getUserID(c), domain.NotificationPreferences{
+		Disabled:          payload.Disabled,
+		QuietHoursEnabled: payload.QuietHoursEnabled,
+		QuietStart:        payload.QuietStart,
+		QuietEnd:          payload.QuietEnd,
+		Timezone:          payload.Timezone,
+	})
+	if err != nil {
+		c.JSON(notificationPreferencesErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toNotificationPreferencesDTO(prefs))
+}
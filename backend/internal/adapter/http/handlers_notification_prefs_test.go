@@ -0,0 +1,65 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"xiaoheiplay/internal/testutil"
+	"xiaoheiplay/internal/testutilhttp"
+)
+
+func TestHandlers_NotificationPreferences(t *testing.T) {
+	env := testutilhttp.NewTestEnv(t, false)
+	user := testutil.CreateUser(t, env.Repo, "prefs", "prefs@example.com", "pass")
+	token := testutil.IssueJWT(t, env.JWTSecret, user.ID, "user", time.Hour)
+
+	rec := testutil.DoJSON(t, env.Router, http.MethodGet, "/api/v1/me/notification-preferences", nil, token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get preferences: %d %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Categories []struct {
+			Key              string   `json:"key"`
+			Mandatory        bool     `json:"mandatory"`
+			DisabledChannels []string `json:"disabled_channels"`
+		} `json:"categories"`
+		QuietStart string `json:"quiet_start"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || len(resp.Categories) == 0 {
+		t.Fatalf("decode preferences: %v %s", err, rec.Body.String())
+	}
+
+	rec = testutil.DoJSON(t, env.Router, http.MethodPatch, "/api/v1/me/notification-preferences", map[string]any{
+		"disabled": map[string][]string{"security": {"email"}},
+	}, token)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected mandatory category rejected: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = testutil.DoJSON(t, env.Router, http.MethodPatch, "/api/v1/me/notification-preferences", map[string]any{
+		"disabled":            map[string][]string{"marketing": {"inapp", "email"}},
+		"quiet_hours_enabled": true,
+		"quiet_start":         "22:00",
+		"quiet_end":           "08:00",
+		"timezone":            "Asia/Shanghai",
+	}, token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update preferences: %d %s", rec.Code, rec.Body.String())
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode update: %v", err)
+	}
+	if resp.QuietStart != "22:00" {
+		t.Fatalf("unexpected quiet hours: %s", rec.Body.String())
+	}
+	for _, item := range resp.Categories {
+		if item.Key == "marketing" && len(item.DisabledChannels) != 2 {
+			t.Fatalf("expected marketing muted: %s", rec.Body.String())
+		}
+		if item.Key == "billing" && !item.Mandatory {
+			t.Fatalf("expected billing mandatory: %s", rec.Body.String())
+		}
+	}
+}
@@ -138,7 +138,12 @@ func (h *Handler) OpenInstantOrderCreate(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrItemsRequired.Error()})
 		return
 	}
-	ctx := apporder.WithOrderSource(c, apporder.OrderSourceUserAPIKey)
+	for _, item := range payload.Items {
+		if h.denyIfPackageProvisionOptionsUnsupported(c, item.PackageID, item.Spec) {
+			return
+		}
+	}
+	ctx := h.withOrderClient(c, apporder.WithOrderSource(c, apporder.OrderSourceUserAPIKey))
 	order, items, payRes, err := h.openAPISvc.InstantCreate(ctx, getUserID(c), payload.Items, c.GetHeader("Idempotency-Key"), payload.CouponCode)
 	if err != nil {
 		h.writeOpenOrderError(c, err)
@@ -235,11 +240,16 @@ func (h *Handler) OpenInstantOrderRefund(c *gin.Context) {
 
 func (h *Handler) writeOpenOrderError(c *gin.Context, err error) {
 	status := http.StatusBadRequest
-	if errors.Is(err, appshared.ErrForbidden) || errors.Is(err, appshared.ErrRealNameRequired) {
+	if errors.Is(err, appshared.ErrForbidden) || errors.Is(err, appshared.ErrRealNameRequired) || errors.Is(err, domain.ErrOrderRejectedByPolicy) || errors.Is(err, domain.ErrCompanyVerificationRequired) {
 		status = http.StatusForbidden
 	}
 	if errors.Is(err, appshared.ErrConflict) || errors.Is(err, appshared.ErrInsufficientBalance) {
 		status = http.StatusConflict
 	}
+	var shortage *appshared.StockShortageError
+	if errors.As(err, &shortage) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "shortages": shortage.Shortages})
+		return
+	}
 	c.JSON(status, gin.H{"error": err.Error()})
 }
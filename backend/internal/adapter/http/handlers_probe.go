@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -42,6 +43,11 @@ type probeRefreshQuery struct {
 	Refresh string `form:"refresh" binding:"omitempty,oneof=0 1"`
 }
 
+type probeReleaseFileURI struct {
+	Version string `uri:"version" binding:"required,max=64"`
+	Path    string `uri:"path" binding:"required,max=256"`
+}
+
 type probeSLAQuery struct {
 	Days *int `form:"days" binding:"omitempty,gte=1,lte=365"`
 }
@@ -195,7 +201,23 @@ func (h *Handler) ProbeWS(c *gin.Context) {
 		}
 		switch strings.TrimSpace(msg.Type) {
 		case "hello":
+			var payload struct {
+				AgentVersion string `json:"agent_version"`
+			}
+			_ = json.Unmarshal(msg.Payload, &payload)
 			_ = h.probeSvc.MarkOnline(c, probeID, "hello")
+			if err := h.probeSvc.HandleAgentHello(c, probeID, payload.AgentVersion); err != nil {
+				log.Printf("probe agent version save failed probe_id=%d err=%v", probeID, err)
+			}
+		case "agent_update":
+			var payload struct {
+				Status  string `json:"status"`
+				Message string `json:"message"`
+			}
+			_ = json.Unmarshal(msg.Payload, &payload)
+			if err := h.probeSvc.HandleAgentUpdateStatus(c, probeID, domain.ProbeAgentUpdateStatus(strings.TrimSpace(payload.Status)), payload.Message); err != nil {
+				log.Printf("probe agent update status save failed probe_id=%d status=%s err=%v", probeID, payload.Status, err)
+			}
 		case "heartbeat":
 			var payload struct {
 				At string `json:"at"`
@@ -240,6 +262,35 @@ func (h *Handler) ProbeWS(c *gin.Context) {
 	}
 }
 
+func (h *Handler) ProbeReleaseFile(c *gin.Context) {
+	if h.probeSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrProbeDisabled.Error()})
+		return
+	}
+	if _, err := h.parseProbeIDFromBearer(c.GetHeader("Authorization")); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": domain.ErrInvalidToken.Error()})
+		return
+	}
+	var uri probeReleaseFileURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	file, size, err := h.probeSvc.OpenAgentReleaseFile(c, uri.Version, uri.Path)
+	if err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, domain.ErrProbeReleasesDisabled) {
+			status = http.StatusBadRequest
+		} else if !errors.Is(err, domain.ErrProbeReleaseNotFound) {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+	c.DataFromReader(http.StatusOK, size, "application/octet-stream", file, nil)
+}
+
 func (h *Handler) AdminProbes(c *gin.Context) {
 	if h.probeSvc == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrProbeDisabled.Error()})
@@ -621,6 +672,64 @@ func (h *Handler) AdminProbeLogSessionStream(c *gin.Context) {
 	}
 }
 
+func (h *Handler) AdminProbeAgentReleases(c *gin.Context) {
+	if h.probeSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrProbeDisabled.Error()})
+		return
+	}
+	releases, err := h.probeSvc.ListAgentReleases(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cfg := h.probeSvc.AgentUpdateConfig(c)
+	out := make([]ProbeAgentReleaseDTO, 0, len(releases))
+	for _, rel := range releases {
+		out = append(out, toProbeAgentReleaseDTO(rel, cfg.DesiredVersion))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"items":                out,
+		"desired_version":      cfg.DesiredVersion,
+		"rollback_timeout_sec": cfg.RollbackTimeoutSec,
+	})
+}
+
+func (h *Handler) AdminProbeAgentVersionUpdate(c *gin.Context) {
+	if h.probeSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrProbeDisabled.Error()})
+		return
+	}
+	var payload struct {
+		Version string `json:"version" binding:"omitempty,max=64"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	if err := h.probeSvc.SetDesiredAgentVersion(c, payload.Version); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, domain.ErrProbeReleaseNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	notified := 0
+	if h.probeHub != nil {
+		notified = h.probeHub.BroadcastJSON(map[string]any{
+			"type":       "set_config",
+			"request_id": "cfg_" + probeRandomToken(10),
+			"payload": map[string]any{
+				"config": h.probeRuntimeConfig(c),
+			},
+		})
+	}
+	if h.adminSvc != nil {
+		h.adminSvc.Audit(c, getUserID(c), "probe.agent_version", "probe", "agent", map[string]any{"version": strings.TrimSpace(payload.Version)})
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true, "notified": notified})
+}
+
 func (h *Handler) signProbeToken(probeID int64, ttl time.Duration) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"probe_id": probeID,
@@ -675,11 +784,17 @@ func parseTimeOrNow(raw string) time.Time {
 }
 
 func (h *Handler) probeRuntimeConfig(ctx context.Context) map[string]any {
-	return map[string]any{
+	cfg := map[string]any{
 		"heartbeat_interval_sec": h.probeIntSetting(ctx, "probe_heartbeat_interval_sec", 20),
 		"snapshot_interval_sec":  h.probeIntSetting(ctx, "probe_snapshot_interval_sec", 60),
 		"log_chunk_max_bytes":    h.probeIntSetting(ctx, "probe_log_chunk_max_bytes", 16384),
 	}
+	if h.probeSvc != nil {
+		update := h.probeSvc.AgentUpdateConfig(ctx)
+		cfg["agent_desired_version"] = update.DesiredVersion
+		cfg["agent_update_rollback_sec"] = update.RollbackTimeoutSec
+	}
+	return cfg
 }
 
 func (h *Handler) resolveProbeLogSource(ctx context.Context, source string) string {
@@ -0,0 +1,182 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+// CompanyRequirementDTO carries a company requirement with the amount in yuan.
+type CompanyRequirementDTO struct {
+	Action       string  `json:"action" binding:"required,oneof=purchase_vps renew_vps"`
+	GoodsTypeIDs []int64 `json:"goods_type_ids" binding:"omitempty,dive,gt=0"`
+	MinAmount    float64 `json:"min_amount" binding:"min=0"`
+}
+
+func toCompanyRequirementDTO(item appshared.CompanyRequirement) CompanyRequirementDTO {
+	goodsTypeIDs := item.GoodsTypeIDs
+	if goodsTypeIDs == nil {
+		goodsTypeIDs = []int64{}
+	}
+	return CompanyRequirementDTO{Action: item.Action, GoodsTypeIDs: goodsTypeIDs, MinAmount: centsToFloat(item.MinAmount)}
+}
+
+func (d CompanyRequirementDTO) toRequirement() appshared.CompanyRequirement {
+	return appshared.CompanyRequirement{Action: d.Action, GoodsTypeIDs: d.GoodsTypeIDs, MinAmount: floatToCents(d.MinAmount)}
+}
+
+func companyVerificationErrorStatus(err error) int {
+	if errors.Is(err, domain.ErrInvalidCompanyVerification) || errors.Is(err, domain.ErrInvalidCompanyRequirement) {
+		return http.StatusBadRequest
+	}
+	return realNameReviewErrorStatus(err)
+}
+
+func (h *Handler) RealNameCompanyStatus(c *gin.Context) {
+	if h.realnameSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	resp := gin.H{
+		"enabled":      h.realnameSvc.CompanyConfig(c).Enabled,
+		"verified":     false,
+		"verification": nil,
+	}
+	if latest, err := h.realnameSvc.LatestCompany(c, getUserID(c)); err == nil {
+		resp["verified"] = latest.Status == "verified"
+		resp["verification"] = toCompanyVerificationDTO(latest)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) RealNameCompanySubmit(c *gin.Context) {
+	if h.realnameSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var payload struct {
+		CompanyName         string  `json:"company_name" binding:"required,max=128"`
+		CreditCode          string  `json:"credit_code" binding:"required,len=18"`
+		LegalPersonName     string  `json:"legal_person_name" binding:"required,max=64"`
+		LegalPersonIDNumber string  `json:"legal_person_id_number" binding:"required,max=64"`
+		InvoiceTitle        string  `json:"invoice_title" binding:"max=128"`
+		TaxNumber           string  `json:"tax_number" binding:"max=32"`
+		InvoiceAddress      string  `json:"invoice_address" binding:"max=255"`
+		InvoicePhone        string  `json:"invoice_phone" binding:"max=32"`
+		InvoiceBank         string  `json:"invoice_bank" binding:"max=128"`
+		InvoiceBankAccount  string  `json:"invoice_bank_account" binding:"max=64"`
+		UploadIDs           []int64 `json:"upload_ids" binding:"required,min=1,max=3,dive,gt=0"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	record, err := h.realnameSvc.SubmitCompany(c, getUserID(c), appshared.CompanyVerificationInput{
+		CompanyName:         payload.CompanyName,
+		CreditCode:          payload.CreditCode,
+		LegalPersonName:     payload.LegalPersonName,
+		LegalPersonIDNumber: payload.LegalPersonIDNumber,
+		InvoiceTitle:        payload.InvoiceTitle,
+		TaxNumber:           payload.TaxNumber,
+		InvoiceAddress:      payload.InvoiceAddress,
+		InvoicePhone:        payload.InvoicePhone,
+		InvoiceBank:         payload.InvoiceBank,
+		InvoiceBankAccount:  payload.InvoiceBankAccount,
+		UploadIDs:           payload.UploadIDs,
+	})
+	if err != nil {
+		c.JSON(companyVerificationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toCompanyVerificationDTO(record))
+}
+
+func (h *Handler) AdminRealNameCompanies(c *gin.Context) {
+	if h.realnameSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var query struct {
+		Status string `form:"status" binding:"omitempty,oneof=pending_review verified failed"`
+		UserID int64  `form:"user_id" binding:"omitempty,gt=0"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	var userID *int64
+	if query.UserID > 0 {
+		userID = &query.UserID
+	}
+	limit, offset := paging(c)
+	items, total, err := h.realnameSvc.ListCompanies(c, userID, query.Status, limit, offset)
+	if err != nil {
+		c.JSON(companyVerificationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]CompanyVerificationDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toCompanyVerificationDTO(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "total": total})
+}
+
+func (h *Handler) AdminRealNameCompanyApprove(c *gin.Context) {
+	h.adminRealNameCompanyReview(c, true)
+}
+
+func (h *Handler) AdminRealNameCompanyReject(c *gin.Context) {
+	h.adminRealNameCompanyReview(c, false)
+}
+
+func (h *Handler) adminRealNameCompanyReview(c *gin.Context, approve bool) {
+	if h.realnameSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var payload struct {
+		Reason string `json:"reason" binding:"max=500"`
+	}
+	if err := bindJSONOptional(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	record, err := h.realnameSvc.ReviewCompany(c, getUserID(c), uri.ID, approve, payload.Reason)
+	if err != nil {
+		c.JSON(companyVerificationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toCompanyVerificationDTO(record))
+}
+
+func (h *Handler) AdminRealNameCompanyFile(c *gin.Context) {
+	if h.realnameSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri struct {
+		ID       int64 `uri:"id" binding:"required,gt=0"`
+		UploadID int64 `uri:"upload_id" binding:"required,gt=0"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	upload, err := h.realnameSvc.CompanyImage(c, uri.ID, uri.UploadID)
+	if err != nil {
+		c.JSON(companyVerificationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("Content-Type", upload.Mime)
+	c.File(upload.Path)
+}
@@ -0,0 +1,67 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+	"xiaoheiplay/internal/testutilhttp"
+)
+
+func TestHandlers_CompanyRequirementBlocksPurchase(t *testing.T) {
+	env := testutilhttp.NewTestEnv(t, false)
+	ctx := context.Background()
+	seed := testutil.SeedCatalog(t, env.Repo)
+	user := testutil.CreateUser(t, env.Repo, "corp", "corp@example.com", "pass")
+	token := testutil.IssueJWT(t, env.JWTSecret, user.ID, "user", time.Hour)
+	if err := env.Repo.CreateBillingCycle(ctx, &domain.BillingCycle{Name: "monthly", Months: 1, Multiplier: 1, MinQty: 1, MaxQty: 12, Active: true, SortOrder: 1}); err != nil {
+		t.Fatalf("create cycle: %v", err)
+	}
+	for key, value := range map[string]string{
+		"realname_company_enabled":      "true",
+		"realname_company_requirements": `[{"action":"purchase_vps","goods_type_ids":[],"min_amount":0}]`,
+	} {
+		if err := env.Repo.UpsertSetting(ctx, domain.Setting{Key: key, ValueJSON: value}); err != nil {
+			t.Fatalf("set %s: %v", key, err)
+		}
+	}
+	order := map[string]any{"items": []map[string]any{{"package_id": seed.Package.ID, "system_id": seed.SystemImage.ID, "qty": 1}}}
+
+	rec := testutil.DoJSON(t, env.Router, http.MethodPost, "/api/v1/orders/items", order, token)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected company requirement to block purchase: %d %s", rec.Code, rec.Body.String())
+	}
+
+	record := domain.CompanyVerification{
+		UserID: user.ID, CompanyName: "Corp Ltd", CreditCode: "91110000MA01ABCD3X", LegalPersonName: "Bob",
+		LegalPersonIDNumber: "110101199001011234", Status: "verified", Provider: domain.RealNameProviderManual,
+	}
+	if err := env.Repo.CreateCompanyVerification(ctx, &record); err != nil {
+		t.Fatalf("create company verification: %v", err)
+	}
+	rec = testutil.DoJSON(t, env.Router, http.MethodGet, "/api/v1/realname/company", nil, token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("company status: %d", rec.Code)
+	}
+	var status struct {
+		Verified     bool `json:"verified"`
+		Verification struct {
+			LegalPersonIDNumber string `json:"legal_person_id_number"`
+		} `json:"verification"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if !status.Verified || status.Verification.LegalPersonIDNumber != "1101****1234" {
+		t.Fatalf("unexpected company status: %s", rec.Body.String())
+	}
+
+	rec = testutil.DoJSON(t, env.Router, http.MethodPost, "/api/v1/orders/items", order, token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("verified company should be able to purchase: %d %s", rec.Code, rec.Body.String())
+	}
+}
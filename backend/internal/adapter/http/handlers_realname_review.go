@@ -0,0 +1,156 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+// realNameUploadDir keeps ID images out of the public uploads directory.
+var realNameUploadDir = filepath.Join("data", "realname")
+
+func realNameReviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, appshared.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, appshared.ErrForbidden), errors.Is(err, domain.ErrRealNameManualReviewDisabled):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrRealNameReviewInProgress), errors.Is(err, domain.ErrRealNameNotPendingReview), errors.Is(err, appshared.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, appshared.ErrInvalidInput), errors.Is(err, domain.ErrInvalidRealNameImages):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *Handler) RealNameUpload(c *gin.Context) {
+	if h.realnameSvc == nil || h.uploadSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	if !h.realnameSvc.ChainConfig(c).ManualReview && !h.realnameSvc.CompanyConfig(c).Enabled {
+		c.JSON(http.StatusForbidden, gin.H{"error": domain.ErrRealNameManualReviewDisabled.Error()})
+		return
+	}
+	item, ok := saveImageUpload(c, realNameUploadDir)
+	if !ok {
+		return
+	}
+	if err := h.uploadSvc.Create(c, &item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": item.ID, "name": item.Name, "mime": item.Mime, "size": item.Size})
+}
+
+func (h *Handler) RealNameManualSubmit(c *gin.Context) {
+	if h.realnameSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var payload struct {
+		RealName  string  `json:"real_name" binding:"required,max=64"`
+		IDNumber  string  `json:"id_number" binding:"required,max=64"`
+		UploadIDs []int64 `json:"upload_ids" binding:"required,min=1,max=3,dive,gt=0"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	record, err := h.realnameSvc.SubmitManual(c, getUserID(c), appshared.RealNameManualInput{
+		RealName:  payload.RealName,
+		IDNumber:  payload.IDNumber,
+		UploadIDs: payload.UploadIDs,
+	})
+	if err != nil {
+		c.JSON(realNameReviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toRealNameVerificationDTO(record))
+}
+
+func (h *Handler) AdminRealNameReviews(c *gin.Context) {
+	if h.realnameSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var query struct {
+		Status string `form:"status" binding:"omitempty,oneof=pending_review verified failed"`
+	}
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	limit, offset := paging(c)
+	items, total, err := h.realnameSvc.ListReviews(c, query.Status, limit, offset)
+	if err != nil {
+		c.JSON(realNameReviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]RealNameVerificationDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toRealNameVerificationDTO(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "total": total})
+}
+
+func (h *Handler) AdminRealNameReviewApprove(c *gin.Context) {
+	h.adminRealNameReview(c, true)
+}
+
+func (h *Handler) AdminRealNameReviewReject(c *gin.Context) {
+	h.adminRealNameReview(c, false)
+}
+
+func (h *Handler) adminRealNameReview(c *gin.Context, approve bool) {
+	if h.realnameSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var payload struct {
+		Reason string `json:"reason" binding:"max=500"`
+	}
+	if err := bindJSONOptional(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	record, err := h.realnameSvc.Review(c, getUserID(c), uri.ID, approve, payload.Reason)
+	if err != nil {
+		c.JSON(realNameReviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toRealNameVerificationDTO(record))
+}
+
+func (h *Handler) AdminRealNameReviewFile(c *gin.Context) {
+	if h.realnameSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri struct {
+		ID       int64 `uri:"id" binding:"required,gt=0"`
+		UploadID int64 `uri:"upload_id" binding:"required,gt=0"`
+	}
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	upload, err := h.realnameSvc.ReviewImage(c, uri.ID, uri.UploadID)
+	if err != nil {
+		c.JSON(realNameReviewErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("Content-Type", upload.Mime)
+	c.File(upload.Path)
+}
@@ -13,6 +13,8 @@ import (
 	"testing"
 	"time"
 	adapterhttp "xiaoheiplay/internal/adapter/http"
+	plugins "xiaoheiplay/internal/adapter/plugins/core"
+	apppluginadmin "xiaoheiplay/internal/app/pluginadmin"
 	"xiaoheiplay/internal/domain"
 	"xiaoheiplay/internal/testutil"
 	"xiaoheiplay/internal/testutilhttp"
@@ -185,6 +187,39 @@ func TestHandlers_AdminPaymentPluginUpload_RequiresConfiguredPassword(t *testing
 	}
 }
 
+func TestHandlers_AdminPluginRepositoryInstall_VersionNotFound(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	repoDir := t.TempDir()
+	index := `{"plugins":[{"category":"payment","plugin_id":"demo","name":"Demo","versions":[{"version":"1.0.0","url":"demo-1.0.0.tar.gz","sha256":"00"}]}]}`
+	if err := os.WriteFile(filepath.Join(repoDir, "index.json"), []byte(index), 0o644); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+	if err := repo.UpsertSetting(context.Background(), domain.Setting{
+		Key:       "plugin_repositories",
+		ValueJSON: `[{"name":"local","url":"` + repoDir + `"}]`,
+	}); err != nil {
+		t.Fatalf("set repositories: %v", err)
+	}
+	pluginAdmin := apppluginadmin.NewService(plugins.NewAdminManager(plugins.NewManager(t.TempDir(), repo, nil, nil)), repo, repo)
+	handler := adapterhttp.NewHandler(adapterhttp.HandlerDeps{PluginAdmin: pluginAdmin})
+
+	install := func(body string) int {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(rec)
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/admin/api/v1/plugins/repository/install", bytes.NewBufferString(body))
+		ctx.Request.Header.Set("Content-Type", "application/json")
+		handler.AdminPluginRepositoryInstall(ctx)
+		return rec.Code
+	}
+	if code := install(`{"repository":"local","category":"payment","plugin_id":"demo","version":"2.0.0"}`); code != http.StatusNotFound {
+		t.Fatalf("missing version should be 404: %d", code)
+	}
+	if code := install(`{"repository":"missing","category":"payment","plugin_id":"demo"}`); code != http.StatusNotFound {
+		t.Fatalf("missing repository should be 404: %d", code)
+	}
+}
+
 func getPluginUploadPassword(t *testing.T, env *testutilhttp.Env) string {
 	t.Helper()
 	setting, err := env.Repo.GetSetting(context.Background(), "payment_plugin_upload_password")
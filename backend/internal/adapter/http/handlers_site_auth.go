@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -645,17 +646,7 @@ func (h *Handler) resolveGeoByIP(ctx context.Context, ip, mmdbPath string) (stri
 		resolver = NewMMDBGeoResolver()
 		h.geoResolver = resolver
 	}
-	path := strings.TrimSpace(mmdbPath)
-	if path == "" {
-		path = strings.TrimSpace(os.Getenv("AUTH_GEOIP_MMDB_PATH"))
-	}
-	if path == "" {
-		path = strings.TrimSpace(os.Getenv("GEOIP_MMDB_PATH"))
-	}
-	if path == "" {
-		path = strings.TrimSpace(os.Getenv("GEOIP_DB_PATH"))
-	}
-	city, tz, err := resolver.Resolve(ctx, ip, path)
+	city, tz, err := resolver.Resolve(ctx, ip, geoIPMMDBPath(mmdbPath))
 	if err != nil {
 		return "未知地区", defaultTZ
 	}
@@ -668,6 +659,39 @@ func (h *Handler) resolveGeoByIP(ctx context.Context, ip, mmdbPath string) (stri
 	return city, tz
 }
 
+// resolveCountryByIP returns the ISO country code of ip, or "" when the
+// resolver cannot tell.
+func (h *Handler) resolveCountryByIP(ctx context.Context, ip, mmdbPath string) string {
+	resolver := h.geoResolver
+	if resolver == nil {
+		resolver = NewMMDBGeoResolver()
+		h.geoResolver = resolver
+	}
+	countries, ok := resolver.(CountryResolver)
+	if !ok {
+		return ""
+	}
+	code, err := countries.ResolveCountry(ctx, ip, geoIPMMDBPath(mmdbPath))
+	if err != nil {
+		return ""
+	}
+	return code
+}
+
+func geoIPMMDBPath(configured string) string {
+	path := strings.TrimSpace(configured)
+	if path == "" {
+		path = strings.TrimSpace(os.Getenv("AUTH_GEOIP_MMDB_PATH"))
+	}
+	if path == "" {
+		path = strings.TrimSpace(os.Getenv("GEOIP_MMDB_PATH"))
+	}
+	if path == "" {
+		path = strings.TrimSpace(os.Getenv("GEOIP_DB_PATH"))
+	}
+	return path
+}
+
 func (h *Handler) sendSecurityMessage(c *gin.Context, channels []string, templateName string, user domain.User, vars map[string]string) error {
 	if len(channels) == 0 {
 		return domain.ErrNoMessageChannelConfigured
@@ -1060,6 +1084,7 @@ func (h *Handler) RealNameStatus(c *gin.Context) {
 		"enabled":       enabled,
 		"provider":      provider,
 		"block_actions": actions,
+		"manual_review": h.realnameSvc.ChainConfig(c).ManualReview,
 		"verified":      verified,
 		"verification":  nil,
 	}
@@ -1108,6 +1133,9 @@ func (h *Handler) RealNameVerify(c *gin.Context) {
 		status := http.StatusBadRequest
 		if err == appshared.ErrForbidden {
 			status = http.StatusForbidden
+		} else if errors.Is(err, domain.ErrRealNameProvidersUnavailable) {
+			status = http.StatusServiceUnavailable
+			err = domain.ErrRealNameProvidersUnavailable
 		}
 		c.JSON(status, gin.H{"error": err.Error()})
 		return
@@ -21,6 +21,10 @@ type walletProviderURI struct {
 	Provider string `uri:"provider" binding:"required,max=64"`
 }
 
+type walletHoldQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=active captured released"`
+}
+
 type notificationStatusQuery struct {
 	Status string `form:"status" binding:"omitempty,oneof=read unread all"`
 }
@@ -56,6 +60,25 @@ func (h *Handler) WalletTransactions(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"items": toWalletTransactionDTOs(items), "total": total})
 }
 
+func (h *Handler) WalletHolds(c *gin.Context) {
+	if h.walletSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrWalletDisabled.Error()})
+		return
+	}
+	var query walletHoldQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	limit, offset := paging(c)
+	items, total, err := h.walletSvc.ListHolds(c, getUserID(c), query.Status, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": toWalletHoldDTOs(items), "total": total})
+}
+
 func (h *Handler) WalletRecharge(c *gin.Context) {
 	if h.walletOrder == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrWalletOrdersDisabled.Error()})
@@ -176,10 +199,11 @@ func (h *Handler) WalletWithdraw(c *gin.Context) {
 		return
 	}
 	var payload struct {
-		Amount   any            `json:"amount"`
-		Currency string         `json:"currency"`
-		Note     string         `json:"note"`
-		Meta     map[string]any `json:"meta"`
+		Amount          any            `json:"amount"`
+		Currency        string         `json:"currency"`
+		Note            string         `json:"note"`
+		PayoutAccountID int64          `json:"payout_account_id" binding:"omitempty,gt=0"`
+		Meta            map[string]any `json:"meta"`
 	}
 	if err := bindJSON(c, &payload); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
@@ -190,6 +214,12 @@ func (h *Handler) WalletWithdraw(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidAmount.Error()})
 		return
 	}
+	if payload.PayoutAccountID > 0 {
+		if payload.Meta == nil {
+			payload.Meta = map[string]any{}
+		}
+		payload.Meta["payout_account_id"] = payload.PayoutAccountID
+	}
 	order, err := h.walletOrder.CreateWithdraw(c, getUserID(c), appshared.WalletOrderCreateInput{
 		Amount:   amount,
 		Currency: payload.Currency,
@@ -0,0 +1,122 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+type payoutAccountDTO struct {
+	ID           int64      `json:"id"`
+	UserID       int64      `json:"user_id"`
+	Channel      string     `json:"channel"`
+	AccountName  string     `json:"account_name"`
+	AccountNo    string     `json:"account_no"`
+	BankName     string     `json:"bank_name"`
+	BankBranch   string     `json:"bank_branch"`
+	Status       string     `json:"status"`
+	ReviewReason string     `json:"review_reason"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// toPayoutAccountDTO masks the account number unless full is set; only
+// admins reviewing the account see it in clear.
+func toPayoutAccountDTO(account domain.PayoutAccount, full bool) payoutAccountDTO {
+	accountNo := account.MaskedAccountNo()
+	if full {
+		accountNo = account.AccountNo
+	}
+	return payoutAccountDTO{
+		ID:           account.ID,
+		UserID:       account.UserID,
+		Channel:      string(account.Channel),
+		AccountName:  account.AccountName,
+		AccountNo:    accountNo,
+		BankName:     account.BankName,
+		BankBranch:   account.BankBranch,
+		Status:       string(account.Status),
+		ReviewReason: account.ReviewReason,
+		ReviewedAt:   account.ReviewedAt,
+		CreatedAt:    account.CreatedAt,
+	}
+}
+
+func payoutErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, appshared.ErrNotFound), errors.Is(err, appshared.ErrForbidden):
+		return http.StatusNotFound
+	case errors.Is(err, appshared.ErrConflict), errors.Is(err, domain.ErrPayoutBatchSettled), errors.Is(err, domain.ErrNoPayableWithdrawals):
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+func (h *Handler) WalletPayoutAccounts(c *gin.Context) {
+	if h.payoutSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	items, err := h.payoutSvc.ListUserAccounts(c, getUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]payoutAccountDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toPayoutAccountDTO(item, false))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "total": len(resp)})
+}
+
+func (h *Handler) WalletPayoutAccountCreate(c *gin.Context) {
+	if h.payoutSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var payload struct {
+		Channel     string `json:"channel" binding:"required,oneof=bank alipay"`
+		AccountName string `json:"account_name" binding:"required,max=128"`
+		AccountNo   string `json:"account_no" binding:"required,max=128"`
+		BankName    string `json:"bank_name" binding:"omitempty,max=128"`
+		BankBranch  string `json:"bank_branch" binding:"omitempty,max=255"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	account, err := h.payoutSvc.CreateAccount(c, getUserID(c), appshared.PayoutAccountInput{
+		Channel:     payload.Channel,
+		AccountName: payload.AccountName,
+		AccountNo:   payload.AccountNo,
+		BankName:    payload.BankName,
+		BankBranch:  payload.BankBranch,
+	})
+	if err != nil {
+		c.JSON(payoutErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toPayoutAccountDTO(account, false))
+}
+
+func (h *Handler) WalletPayoutAccountDelete(c *gin.Context) {
+	if h.payoutSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri siteIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	if err := h.payoutSvc.DeleteAccount(c, getUserID(c), uri.ID); err != nil {
+		c.JSON(payoutErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
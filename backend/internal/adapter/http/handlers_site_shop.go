@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"io"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"strings"
 	"time"
 	apporder "xiaoheiplay/internal/app/order"
+	apporderapproval "xiaoheiplay/internal/app/orderapproval"
 	appshared "xiaoheiplay/internal/app/shared"
 	"xiaoheiplay/internal/domain"
 )
@@ -40,6 +42,10 @@ type sitePackagesQuery struct {
 	GoodsTypeID *int64 `form:"goods_type_id" binding:"omitempty,gt=0"`
 }
 
+type siteAddonsQuery struct {
+	PlanGroupID int64 `form:"plan_group_id" binding:"required,gt=0"`
+}
+
 type sitePaymentMethodsQuery struct {
 	Scene string `form:"scene" binding:"omitempty,max=64"`
 }
@@ -314,6 +320,20 @@ func (h *Handler) BillingCycles(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"items": toBillingCycleDTOs(items)})
 }
 
+func (h *Handler) Addons(c *gin.Context) {
+	var query siteAddonsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	items, err := h.addonSvc.ListActive(c, getUserID(c), query.PlanGroupID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrListError.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": toAddonDTOs(items)})
+}
+
 func (h *Handler) Dashboard(c *gin.Context) {
 	userID := getUserID(c)
 	orders, _, _ := h.orderSvc.ListOrders(c, appshared.OrderFilter{UserID: userID}, 1000, 0)
@@ -351,7 +371,17 @@ func (h *Handler) CartList(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrCartError.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"items": toCartItemDTOs(items)})
+	resp := gin.H{"items": toCartItemDTOs(items)}
+	if h.inventorySvc != nil {
+		shortages, err := h.inventorySvc.CartShortages(c, items)
+		if err == nil {
+			if shortages == nil {
+				shortages = []appshared.StockShortage{}
+			}
+			resp["shortages"] = shortages
+		}
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 func (h *Handler) CartAdd(c *gin.Context) {
@@ -365,6 +395,9 @@ func (h *Handler) CartAdd(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
 		return
 	}
+	if h.denyIfPackageProvisionOptionsUnsupported(c, payload.PackageID, payload.Spec) {
+		return
+	}
 	item, err := h.cartSvc.Add(c, getUserID(c), payload.PackageID, payload.SystemID, payload.Spec, payload.Qty)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -387,6 +420,18 @@ func (h *Handler) CartUpdate(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
 		return
 	}
+	if len(payload.Spec.SSHKeyIDs) > 0 || payload.Spec.UserData != "" {
+		items, err := h.cartSvc.List(c, getUserID(c))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		for _, item := range items {
+			if item.ID == uri.ID && h.denyIfPackageProvisionOptionsUnsupported(c, item.PackageID, payload.Spec) {
+				return
+			}
+		}
+	}
 	item, err := h.cartSvc.Update(c, getUserID(c), uri.ID, payload.Spec, payload.Qty)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -428,16 +473,17 @@ func (h *Handler) OrderCreate(c *gin.Context) {
 		}
 	}
 	idem := c.GetHeader("Idempotency-Key")
+	ctx := h.withOrderClient(c, c)
 	var order domain.Order
 	var items []domain.OrderItem
 	var err error
 	if len(payload.Items) > 0 {
-		order, items, err = h.orderSvc.CreateOrderFromItems(c, getUserID(c), "CNY", payload.Items, idem, payload.CouponCode)
+		order, items, err = h.orderSvc.CreateOrderFromItems(ctx, getUserID(c), "CNY", payload.Items, idem, payload.CouponCode)
 	} else {
-		order, items, err = h.orderSvc.CreateOrderFromCart(c, getUserID(c), "CNY", idem, payload.CouponCode)
+		order, items, err = h.orderSvc.CreateOrderFromCart(ctx, getUserID(c), "CNY", idem, payload.CouponCode)
 	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeOrderCreateError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"order": toOrderDTO(order), "items": toOrderItemDTOs(items)})
@@ -452,15 +498,47 @@ func (h *Handler) OrderCreateItems(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
 		return
 	}
+	for _, item := range payload.Items {
+		if h.denyIfPackageProvisionOptionsUnsupported(c, item.PackageID, item.Spec) {
+			return
+		}
+	}
 	idem := c.GetHeader("Idempotency-Key")
-	order, items, err := h.orderSvc.CreateOrderFromItems(c, getUserID(c), "CNY", payload.Items, idem, payload.CouponCode)
+	order, items, err := h.orderSvc.CreateOrderFromItems(h.withOrderClient(c, c), getUserID(c), "CNY", payload.Items, idem, payload.CouponCode)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		writeOrderCreateError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"order": toOrderDTO(order), "items": toOrderItemDTOs(items)})
 }
 
+// withOrderClient passes the client IP and its country to the order
+// approval rules.
+func (h *Handler) withOrderClient(c *gin.Context, ctx context.Context) context.Context {
+	if h.orderApprovalSvc == nil {
+		return ctx
+	}
+	ip := strings.TrimSpace(c.ClientIP())
+	mmdbPath := ""
+	if setting, err := h.getSettingByContext(c, "auth_geoip_mmdb_path"); err == nil {
+		mmdbPath = strings.TrimSpace(setting.ValueJSON)
+	}
+	return apporderapproval.WithClient(ctx, ip, h.resolveCountryByIP(c, ip, mmdbPath))
+}
+
+func writeOrderCreateError(c *gin.Context, err error) {
+	var shortage *appshared.StockShortageError
+	if errors.As(err, &shortage) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "shortages": shortage.Shortages})
+		return
+	}
+	if errors.Is(err, domain.ErrOrderRejectedByPolicy) || errors.Is(err, domain.ErrCompanyVerificationRequired) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
 func (h *Handler) CouponPreview(c *gin.Context) {
 	var payload struct {
 		CouponCode string                     `json:"coupon_code" binding:"required,max=64"`
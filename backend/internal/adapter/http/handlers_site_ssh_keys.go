@@ -0,0 +1,93 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+type sshKeyDTO struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	PublicKey   string    `json:"public_key"`
+	Fingerprint string    `json:"fingerprint"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func toSSHKeyDTO(k domain.SSHKey) sshKeyDTO {
+	return sshKeyDTO{
+		ID:          k.ID,
+		Name:        k.Name,
+		PublicKey:   k.PublicKey,
+		Fingerprint: k.Fingerprint,
+		CreatedAt:   k.CreatedAt,
+	}
+}
+
+func (h *Handler) MeSSHKeys(c *gin.Context) {
+	if h.sshKeySvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrSSHKeysNotSupported.Error()})
+		return
+	}
+	items, err := h.sshKeySvc.List(c, getUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]sshKeyDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toSSHKeyDTO(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "total": len(resp)})
+}
+
+func (h *Handler) MeSSHKeyCreate(c *gin.Context) {
+	if h.sshKeySvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrSSHKeysNotSupported.Error()})
+		return
+	}
+	var payload struct {
+		Name      string `json:"name" binding:"omitempty,max=64"`
+		PublicKey string `json:"public_key" binding:"required,max=16384"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	key, err := h.sshKeySvc.Create(c, getUserID(c), payload.Name, payload.PublicKey)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, domain.ErrSSHKeyExists) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toSSHKeyDTO(key))
+}
+
+func (h *Handler) MeSSHKeyDelete(c *gin.Context) {
+	if h.sshKeySvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrSSHKeysNotSupported.Error()})
+		return
+	}
+	var uri siteIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	if err := h.sshKeySvc.Delete(c, getUserID(c), uri.ID); err != nil {
+		if errors.Is(err, appshared.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrNotFound.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
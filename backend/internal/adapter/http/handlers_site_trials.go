@@ -0,0 +1,106 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	apptrial "xiaoheiplay/internal/app/trial"
+	"xiaoheiplay/internal/domain"
+)
+
+type vpsTrialDTO struct {
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"user_id"`
+	PackageID   int64      `json:"package_id"`
+	OrderID     int64      `json:"order_id"`
+	OrderItemID int64      `json:"order_item_id"`
+	Days        int        `json:"days"`
+	Status      string     `json:"status"`
+	ExpireAt    time.Time  `json:"expire_at"`
+	ConvertedAt *time.Time `json:"converted_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type adminVPSTrialDTO struct {
+	vpsTrialDTO
+	Phone    string `json:"phone"`
+	ClientIP string `json:"client_ip"`
+	DeviceID string `json:"device_id"`
+}
+
+func toVPSTrialDTO(trial domain.VPSTrial) vpsTrialDTO {
+	return vpsTrialDTO{
+		ID:          trial.ID,
+		UserID:      trial.UserID,
+		PackageID:   trial.PackageID,
+		OrderID:     trial.OrderID,
+		OrderItemID: trial.OrderItemID,
+		Days:        trial.Days,
+		Status:      string(trial.Status),
+		ExpireAt:    trial.ExpireAt,
+		ConvertedAt: trial.ConvertedAt,
+		CreatedAt:   trial.CreatedAt,
+	}
+}
+
+func trialErrorStatus(err error) int {
+	var shortage *appshared.StockShortageError
+	switch {
+	case errors.Is(err, appshared.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrTrialVerificationRequired):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrTrialLimitReached), errors.As(err, &shortage):
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+func (h *Handler) TrialStart(c *gin.Context) {
+	if h.trialSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var payload struct {
+		PackageID int64  `json:"package_id" binding:"required,gt=0"`
+		SystemID  int64  `json:"system_id" binding:"required,gt=0"`
+		DeviceID  string `json:"device_id" binding:"max=128"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	trial, err := h.trialSvc.Start(c, getUserID(c), apptrial.StartInput{
+		PackageID: payload.PackageID,
+		SystemID:  payload.SystemID,
+		ClientIP:  c.ClientIP(),
+		DeviceID:  payload.DeviceID,
+	})
+	if err != nil {
+		c.JSON(trialErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toVPSTrialDTO(trial))
+}
+
+func (h *Handler) TrialList(c *gin.Context) {
+	if h.trialSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	limit, offset := paging(c)
+	items, total, err := h.trialSvc.List(c, appshared.VPSTrialFilter{UserID: getUserID(c)}, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": domain.ErrListError.Error()})
+		return
+	}
+	resp := make([]vpsTrialDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toVPSTrialDTO(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp, "total": total})
+}
@@ -168,10 +168,16 @@ type dynamicAutomationCapability struct {
 }
 
 func (h *Handler) resolveVPSAutomationCapabilityStatic(c *gin.Context, inst domain.VPSInstance) *VPSAutomationCapabilityDTO {
-	if h.goodsTypes == nil || h.pluginAdmin == nil || inst.GoodsTypeID <= 0 {
+	return h.goodsTypeAutomationCapability(c, inst.GoodsTypeID)
+}
+
+// goodsTypeAutomationCapability returns the automation capability declared by
+// the plugin instance bound to a goods type.
+func (h *Handler) goodsTypeAutomationCapability(c *gin.Context, goodsTypeID int64) *VPSAutomationCapabilityDTO {
+	if h.goodsTypes == nil || h.pluginAdmin == nil || goodsTypeID <= 0 {
 		return nil
 	}
-	gt, err := h.goodsTypes.Get(c, inst.GoodsTypeID)
+	gt, err := h.goodsTypes.Get(c, goodsTypeID)
 	if err != nil {
 		return nil
 	}
@@ -0,0 +1,154 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	appbackuppolicy "xiaoheiplay/internal/app/backuppolicy"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+type vpsBackupPolicyURI struct {
+	ID   int64  `uri:"id" binding:"required,gt=0"`
+	Kind string `uri:"kind" binding:"required,oneof=snapshot backup"`
+}
+
+type vpsBackupPolicyDTO struct {
+	Kind       string     `json:"kind"`
+	Enabled    bool       `json:"enabled"`
+	Weekday    int        `json:"weekday"`
+	RunAt      string     `json:"run_at"`
+	Retention  int        `json:"retention"`
+	NextRunAt  time.Time  `json:"next_run_at"`
+	LastRunAt  *time.Time `json:"last_run_at,omitempty"`
+	LastStatus string     `json:"last_status,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func toVPSBackupPolicyDTO(p domain.VPSBackupPolicy) vpsBackupPolicyDTO {
+	return vpsBackupPolicyDTO{
+		Kind:       string(p.Kind),
+		Enabled:    p.Enabled,
+		Weekday:    p.Weekday,
+		RunAt:      p.RunAt,
+		Retention:  p.Retention,
+		NextRunAt:  p.NextRunAt,
+		LastRunAt:  p.LastRunAt,
+		LastStatus: p.LastStatus,
+		LastError:  p.LastError,
+		UpdatedAt:  p.UpdatedAt,
+	}
+}
+
+// backupPolicyFeature maps a policy kind to the package feature switch that
+// guards the matching manual operation.
+func backupPolicyFeature(kind domain.VPSBackupPolicyKind) (string, string) {
+	if kind == domain.VPSBackupPolicyKindBackup {
+		return "backup", "备份"
+	}
+	return "snapshot", "快照"
+}
+
+func (h *Handler) VPSBackupPolicies(c *gin.Context) {
+	if h.backupPolicySvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri vpsIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	inst, err := h.vpsSvc.Get(c, uri.ID, getUserID(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrNotFound.Error()})
+		return
+	}
+	items, err := h.backupPolicySvc.List(c, inst)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]vpsBackupPolicyDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toVPSBackupPolicyDTO(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp})
+}
+
+func (h *Handler) VPSBackupPolicySave(c *gin.Context) {
+	if h.backupPolicySvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri vpsBackupPolicyURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	var payload struct {
+		Enabled   bool   `json:"enabled"`
+		Weekday   *int   `json:"weekday" binding:"omitempty,min=-1,max=6"`
+		RunAt     string `json:"run_at" binding:"required,max=5"`
+		Retention int    `json:"retention" binding:"required,min=1,max=100"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	inst, err := h.vpsSvc.Get(c, uri.ID, getUserID(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrNotFound.Error()})
+		return
+	}
+	kind := domain.VPSBackupPolicyKind(uri.Kind)
+	if feature, label := backupPolicyFeature(kind); h.denyIfFeatureDisabled(c, inst, feature, label) {
+		return
+	}
+	weekday := -1
+	if payload.Weekday != nil {
+		weekday = *payload.Weekday
+	}
+	policy, err := h.backupPolicySvc.Save(c, inst, kind, appbackuppolicy.Input{
+		Enabled:   payload.Enabled,
+		Weekday:   weekday,
+		RunAt:     payload.RunAt,
+		Retention: payload.Retention,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toVPSBackupPolicyDTO(policy))
+}
+
+func (h *Handler) VPSBackupPolicyDelete(c *gin.Context) {
+	if h.backupPolicySvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri vpsBackupPolicyURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	inst, err := h.vpsSvc.Get(c, uri.ID, getUserID(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrNotFound.Error()})
+		return
+	}
+	if err := h.backupPolicySvc.Delete(c, inst, domain.VPSBackupPolicyKind(uri.Kind)); err != nil {
+		if errors.Is(err, appshared.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": domain.ErrNotFound.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+type vpsIPAddressDTO struct {
+	Address   string    `json:"address"`
+	Version   int       `json:"version"`
+	Primary   bool      `json:"primary"`
+	PTR       string    `json:"ptr"`
+	Blocked   bool      `json:"blocked"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func toVPSIPAddressDTO(ip domain.IPAddress) vpsIPAddressDTO {
+	return vpsIPAddressDTO{
+		Address:   ip.Address,
+		Version:   ip.Version,
+		Primary:   ip.Primary,
+		PTR:       ip.PTR,
+		Blocked:   ip.Status == domain.IPAddressStatusBlocked,
+		UpdatedAt: ip.UpdatedAt,
+	}
+}
+
+func ipAddressErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, appshared.ErrNotFound), errors.Is(err, appshared.ErrForbidden):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrReverseDNSNotSupported):
+		return http.StatusNotImplemented
+	case errors.Is(err, domain.ErrIPAddressExists), errors.Is(err, domain.ErrIPAddressInUse), errors.Is(err, domain.ErrIPAddressBlocked):
+		return http.StatusConflict
+	}
+	return http.StatusBadRequest
+}
+
+func (h *Handler) VPSIPAddresses(c *gin.Context) {
+	if h.ipAddressSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri vpsIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	items, err := h.ipAddressSvc.ListForVPS(c, getUserID(c), uri.ID)
+	if err != nil {
+		c.JSON(ipAddressErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	resp := make([]vpsIPAddressDTO, 0, len(items))
+	for _, item := range items {
+		resp = append(resp, toVPSIPAddressDTO(item))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": resp})
+}
+
+func (h *Handler) VPSIPAddressReverseDNS(c *gin.Context) {
+	if h.ipAddressSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri vpsIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var payload struct {
+		IP  string `json:"ip" binding:"required,max=64"`
+		PTR string `json:"ptr" binding:"max=254"`
+	}
+	if err := bindJSON(c, &payload); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidBody.Error()})
+		return
+	}
+	ip, err := h.ipAddressSvc.SetPTR(c, getUserID(c), uri.ID, payload.IP, payload.PTR)
+	if err != nil {
+		c.JSON(ipAddressErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, toVPSIPAddressDTO(ip))
+}
@@ -269,6 +269,16 @@ func (h *Handler) VPSResetOS(c *gin.Context) {
 	hostID := parseInt(payload["host_id"])
 	templateID := parseInt(payload["template_id"])
 	password, _ := payload["password"].(string)
+	userData, _ := payload["user_data"].(string)
+	var sshKeyIDs []int64
+	if rawIDs, ok := payload["ssh_key_ids"].([]any); ok {
+		for _, raw := range rawIDs {
+			sshKeyIDs = append(sshKeyIDs, parseInt(raw))
+		}
+	}
+	if h.denyIfProvisionOptionsUnsupported(c, h.resolveVPSAutomationCapability(c, inst), sshKeyIDs, userData) {
+		return
+	}
 	if hostID != 0 && hostID != uri.ID {
 		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
 		return
@@ -314,7 +324,7 @@ func (h *Handler) VPSResetOS(c *gin.Context) {
 			matchedSystemID = img.ID
 		}
 	}
-	if err := h.vpsSvc.ResetOS(c, inst, templateID, strings.TrimSpace(password)); err != nil {
+	if err := h.vpsSvc.ResetOS(c, inst, templateID, strings.TrimSpace(password), sshKeyIDs, userData); err != nil {
 		if err == appshared.ErrInvalidInput {
 			c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
 			return
@@ -952,7 +962,7 @@ func (h *Handler) VPSRenewOrder(c *gin.Context) {
 	order, err := h.orderSvc.CreateRenewOrder(c, getUserID(c), uri.ID, payload.RenewDays, payload.DurationMonths)
 	if err != nil {
 		status := http.StatusBadRequest
-		if err == appshared.ErrRealNameRequired || err == appshared.ErrForbidden {
+		if err == appshared.ErrRealNameRequired || err == appshared.ErrForbidden || errors.Is(err, domain.ErrCompanyVerificationRequired) {
 			status = http.StatusForbidden
 		} else if errors.Is(err, appshared.ErrConflict) {
 			status = http.StatusConflict
@@ -1073,6 +1083,40 @@ func (h *Handler) VPSRefund(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"order": toOrderDTO(order), "refund_amount": centsToFloat(amount)})
 }
 
+// denyIfProvisionOptionsUnsupported rejects SSH keys or cloud-init user-data
+// when the automation plugin does not declare the matching feature.
+func (h *Handler) denyIfProvisionOptionsUnsupported(c *gin.Context, cap *VPSAutomationCapabilityDTO, sshKeyIDs []int64, userData string) bool {
+	if len(sshKeyIDs) == 0 && userData == "" {
+		return false
+	}
+	declared := map[string]bool{}
+	if cap != nil {
+		for _, f := range normalizeFeatureList(cap.Features) {
+			declared[f] = true
+		}
+	}
+	if len(sshKeyIDs) > 0 && !declared["ssh_keys"] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrSSHKeysNotSupported.Error()})
+		return true
+	}
+	if userData != "" && !declared["cloud_init"] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrCloudInitNotSupported.Error()})
+		return true
+	}
+	return false
+}
+
+func (h *Handler) denyIfPackageProvisionOptionsUnsupported(c *gin.Context, packageID int64, spec appshared.CartSpec) bool {
+	if len(spec.SSHKeyIDs) == 0 && spec.UserData == "" {
+		return false
+	}
+	var cap *VPSAutomationCapabilityDTO
+	if pkg, err := h.catalogSvc.GetPackage(c, packageID); err == nil {
+		cap = h.goodsTypeAutomationCapability(c, pkg.GoodsTypeID)
+	}
+	return h.denyIfProvisionOptionsUnsupported(c, cap, spec.SSHKeyIDs, spec.UserData)
+}
+
 func (h *Handler) denyIfFeatureDisabled(c *gin.Context, inst domain.VPSInstance, feature, label string) bool {
 	if h.packageFeatureAllowed(c, inst, feature, true) {
 		return false
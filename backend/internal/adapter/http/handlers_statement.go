@@ -0,0 +1,103 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	appstatement "xiaoheiplay/internal/app/statement"
+	"xiaoheiplay/internal/domain"
+)
+
+type statementQuery struct {
+	FromAt string `form:"from_at" binding:"required"`
+	ToAt   string `form:"to_at" binding:"required"`
+	Format string `form:"format" binding:"omitempty,oneof=csv pdf"`
+}
+
+func (q statementQuery) parse() (time.Time, time.Time, string, error) {
+	from, err := parseQueryTime(q.FromAt)
+	if err != nil {
+		return time.Time{}, time.Time{}, "", err
+	}
+	to, err := parseQueryTime(q.ToAt)
+	if err != nil {
+		return time.Time{}, time.Time{}, "", err
+	}
+	format := q.Format
+	if format == "" {
+		format = domain.StatementFormatCSV
+	}
+	return from, to, format, nil
+}
+
+func statementErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, appshared.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, appshared.ErrInvalidInput), errors.Is(err, domain.ErrInvalidStatementRange):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func writeStatementFile(c *gin.Context, file appstatement.File) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", file.Name))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, file.ContentType, file.Content)
+}
+
+func (h *Handler) UserStatement(c *gin.Context) {
+	if h.statementSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var query statementQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	from, to, format, err := query.parse()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	file, err := h.statementSvc.Export(c, getUserID(c), from, to, format)
+	if err != nil {
+		c.JSON(statementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	writeStatementFile(c, file)
+}
+
+func (h *Handler) AdminUserStatement(c *gin.Context) {
+	if h.statementSvc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrNotSupported.Error()})
+		return
+	}
+	var uri adminIDURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidId.Error()})
+		return
+	}
+	var query statementQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	from, to, format, err := query.parse()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": domain.ErrInvalidInput.Error()})
+		return
+	}
+	file, err := h.statementSvc.AdminExport(c, getUserID(c), uri.ID, from, to, format)
+	if err != nil {
+		c.JSON(statementErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	writeStatementFile(c, file)
+}
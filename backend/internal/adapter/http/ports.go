@@ -105,7 +105,7 @@ type VPSService interface {
 	Start(ctx context.Context, inst domain.VPSInstance) error
 	Shutdown(ctx context.Context, inst domain.VPSInstance) error
 	Reboot(ctx context.Context, inst domain.VPSInstance) error
-	ResetOS(ctx context.Context, inst domain.VPSInstance, templateID int64, password string) error
+	ResetOS(ctx context.Context, inst domain.VPSInstance, templateID int64, password string, sshKeyIDs []int64, userData string) error
 	UpdateLocalSystemID(ctx context.Context, inst domain.VPSInstance, systemID int64) error
 	ResetOSPassword(ctx context.Context, inst domain.VPSInstance, password string) error
 	ListSnapshots(ctx context.Context, inst domain.VPSInstance) ([]appshared.AutomationSnapshot, error)
@@ -163,6 +163,9 @@ type PluginAdminService interface {
 	UpdateConfigInstance(ctx context.Context, category, pluginID, instanceID, configJSON string) error
 	CreateInstance(ctx context.Context, category, pluginID, instanceID string) (domain.PluginInstallation, error)
 	DeletePluginFiles(ctx context.Context, category, pluginID string) error
+	ListRepositories(ctx context.Context) ([]appshared.PluginRepository, error)
+	BrowseRepositories(ctx context.Context) ([]appshared.PluginRepositoryItem, []appshared.PluginRepositoryError, error)
+	InstallFromRepository(ctx context.Context, repository, category, pluginID, version string, allowUntrusted bool) (domain.PluginInstallation, error)
 }
 
 type UserTierService interface {
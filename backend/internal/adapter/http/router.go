@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 	"xiaoheiplay/internal/domain"
 )
 
@@ -22,8 +23,23 @@ func securityHeadersMiddleware() gin.HandlerFunc {
 	}
 }
 
+// httpMetricsMiddleware records request counts and latency by route template
+// (c.FullPath) rather than raw path to keep label cardinality bounded.
+func httpMetricsMiddleware(handler *Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if handler == nil || handler.httpMetrics == nil {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		c.Next()
+		handler.httpMetrics.Observe(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
+
 func NewServer(handler *Handler, middleware *Middleware) *Server {
 	r := gin.Default()
+	r.Use(httpMetricsMiddleware(handler))
 	r.Use(securityHeadersMiddleware())
 	r.Use(corsMiddleware())
 	r.Static("/uploads", "./uploads")
@@ -15,5 +15,6 @@ func defaultRouteRegistrars() []RouteRegistrar {
 		integrationRoutesRegistrar{},
 		adminRoutesRegistrar{},
 		adminPublicAuthRoutesRegistrar{},
+		metricsRoutesRegistrar{},
 	}
 }
@@ -19,6 +19,7 @@ func (adminRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware
 		admin.GET("/users/:id", handler.AdminUserDetail)
 		admin.PATCH("/users/:id", handler.AdminUserUpdate)
 		admin.PATCH("/users/:id/tier", handler.AdminUserSetTier)
+		admin.GET("/users/:id/statement", handler.AdminUserStatement)
 		admin.POST("/users/:id/reset-password", handler.AdminUserResetPassword)
 		admin.PATCH("/users/:id/status", handler.AdminUserStatus)
 		admin.PATCH("/users/:id/realname-status", handler.AdminUserRealNameStatus)
@@ -34,6 +35,8 @@ func (adminRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware
 		admin.PATCH("/plugins/payment-methods", handler.AdminPluginPaymentMethodsUpdate)
 		admin.GET("/plugins", handler.AdminPluginsList)
 		admin.GET("/plugins/discover", handler.AdminPluginsDiscover)
+		admin.GET("/plugins/repository", handler.AdminPluginRepositoryList)
+		admin.POST("/plugins/repository/install", handler.AdminPluginRepositoryInstall)
 		admin.POST("/plugins/install", handler.AdminPluginInstall)
 		admin.POST("/plugins/:category/:plugin_id/import", handler.AdminPluginImportFromDisk)
 		admin.POST("/plugins/:category/:plugin_id/instances", handler.AdminPluginInstanceCreate)
@@ -60,6 +63,12 @@ func (adminRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware
 		admin.DELETE("/orders/:id", handler.AdminOrderDelete)
 		admin.POST("/orders/:id/mark-paid", handler.AdminOrderMarkPaid)
 		admin.POST("/orders/:id/retry", handler.AdminOrderRetry)
+		admin.GET("/orders/:id/approval", handler.AdminOrderApproval)
+		admin.GET("/order-approval-rules", handler.AdminOrderApprovalRules)
+		admin.POST("/order-approval-rules", handler.AdminOrderApprovalRuleCreate)
+		admin.PATCH("/order-approval-rules/:id", handler.AdminOrderApprovalRuleUpdate)
+		admin.DELETE("/order-approval-rules/:id", handler.AdminOrderApprovalRuleDelete)
+		admin.POST("/order-approval-rules/dry-run", handler.AdminOrderApprovalDryRun)
 		admin.GET("/tickets", handler.AdminTickets)
 		admin.GET("/tickets/:id", handler.AdminTicketDetail)
 		admin.PATCH("/tickets/:id", handler.AdminTicketUpdate)
@@ -67,6 +76,24 @@ func (adminRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware
 		admin.DELETE("/tickets/:id", handler.AdminTicketDelete)
 		admin.GET("/vps", handler.AdminVPSList)
 		admin.POST("/vps", handler.AdminVPSCreate)
+		admin.GET("/vps/bulk-jobs", handler.AdminVPSBulkJobs)
+		admin.POST("/vps/bulk-jobs", handler.AdminVPSBulkJobCreate)
+		admin.GET("/vps/bulk-jobs/:id", handler.AdminVPSBulkJobDetail)
+		admin.GET("/vps/bulk-jobs/:id/stream", handler.AdminVPSBulkJobStream)
+		admin.POST("/vps/bulk-jobs/:id/cancel", handler.AdminVPSBulkJobCancel)
+		admin.GET("/vps/migrations", handler.AdminVPSMigrations)
+		admin.POST("/vps/migrations", handler.AdminVPSMigrationCreate)
+		admin.GET("/vps/migrations/:id", handler.AdminVPSMigrationDetail)
+		admin.POST("/vps/migrations/:id/retry", handler.AdminVPSMigrationRetry)
+		admin.POST("/vps/migrations/:id/cancel", handler.AdminVPSMigrationCancel)
+		admin.GET("/ip-addresses", handler.AdminIPAddresses)
+		admin.POST("/ip-addresses", handler.AdminIPAddressCreate)
+		admin.GET("/ip-addresses/:id", handler.AdminIPAddressDetail)
+		admin.PATCH("/ip-addresses/:id", handler.AdminIPAddressUpdate)
+		admin.DELETE("/ip-addresses/:id", handler.AdminIPAddressDelete)
+		admin.GET("/ip-addresses/:id/events", handler.AdminIPAddressEvents)
+		admin.POST("/ip-addresses/:id/abuse", handler.AdminIPAddressAbuse)
+		admin.GET("/trials", handler.AdminTrials)
 		admin.GET("/vps/:id", handler.AdminVPSDetail)
 		admin.PATCH("/vps/:id", handler.AdminVPSUpdate)
 		admin.POST("/vps/:id/lock", handler.AdminVPSLock)
@@ -77,6 +104,17 @@ func (adminRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware
 		admin.POST("/vps/:id/emergency-renew", handler.AdminVPSEmergencyRenew)
 		admin.POST("/vps/:id/refresh", handler.AdminVPSRefresh)
 		admin.PATCH("/vps/:id/expire-at", handler.AdminVPSUpdateExpire)
+		admin.GET("/vps/:id/dunning", handler.AdminVPSDunning)
+		admin.GET("/dunning-policies", handler.AdminDunningPolicies)
+		admin.PATCH("/dunning-policies/:goods_type_id", handler.AdminDunningPolicySave)
+		admin.DELETE("/dunning-policies/:goods_type_id", handler.AdminDunningPolicyDelete)
+		admin.GET("/dunning-records", handler.AdminDunningRecords)
+		admin.GET("/broadcasts", handler.AdminBroadcasts)
+		admin.POST("/broadcasts", handler.AdminBroadcastCreate)
+		admin.POST("/broadcasts/preview", handler.AdminBroadcastPreview)
+		admin.GET("/broadcasts/:id", handler.AdminBroadcastDetail)
+		admin.GET("/broadcasts/:id/deliveries", handler.AdminBroadcastDeliveries)
+		admin.POST("/broadcasts/:id/cancel", handler.AdminBroadcastCancel)
 		admin.GET("/audit-logs", handler.AdminAuditLogs)
 		admin.GET("/regions", handler.AdminRegions)
 		admin.POST("/regions", handler.AdminRegionCreate)
@@ -105,6 +143,10 @@ func (adminRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware
 		admin.PATCH("/billing-cycles/:id", handler.AdminBillingCycleUpdate)
 		admin.DELETE("/billing-cycles/:id", handler.AdminBillingCycleDelete)
 		admin.POST("/billing-cycles/bulk-delete", handler.AdminBillingCycleBulkDelete)
+		admin.GET("/addons", handler.AdminAddons)
+		admin.POST("/addons", handler.AdminAddonCreate)
+		admin.PATCH("/addons/:id", handler.AdminAddonUpdate)
+		admin.DELETE("/addons/:id", handler.AdminAddonDelete)
 		admin.GET("/system-images", handler.AdminSystemImages)
 		admin.POST("/system-images", handler.AdminSystemImageCreate)
 		admin.PATCH("/system-images/:id", handler.AdminSystemImageUpdate)
@@ -130,6 +172,14 @@ func (adminRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware
 		admin.PATCH("/realname/config", handler.AdminRealNameConfigUpdate)
 		admin.GET("/realname/providers", handler.AdminRealNameProviders)
 		admin.GET("/realname/records", handler.AdminRealNameRecords)
+		admin.GET("/realname/reviews", handler.AdminRealNameReviews)
+		admin.POST("/realname/reviews/:id/approve", handler.AdminRealNameReviewApprove)
+		admin.POST("/realname/reviews/:id/reject", handler.AdminRealNameReviewReject)
+		admin.GET("/realname/reviews/:id/files/:upload_id", handler.AdminRealNameReviewFile)
+		admin.GET("/realname/companies", handler.AdminRealNameCompanies)
+		admin.POST("/realname/companies/:id/approve", handler.AdminRealNameCompanyApprove)
+		admin.POST("/realname/companies/:id/reject", handler.AdminRealNameCompanyReject)
+		admin.GET("/realname/companies/:id/files/:upload_id", handler.AdminRealNameCompanyFile)
 		admin.GET("/integrations/smtp", handler.AdminSMTPConfig)
 		admin.PATCH("/integrations/smtp", handler.AdminSMTPConfigUpdate)
 		admin.POST("/integrations/smtp/test", handler.AdminSMTPTest)
@@ -140,15 +190,41 @@ func (adminRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware
 		admin.GET("/integrations/sms/config", handler.AdminSMSConfig)
 		admin.PATCH("/integrations/sms/config", handler.AdminSMSConfigUpdate)
 		admin.POST("/integrations/sms/send-test", handler.AdminSMSTest)
+		admin.GET("/integrations/notify-channels", handler.AdminNotifyChannels)
+		admin.PATCH("/integrations/notify-channels", handler.AdminNotifyChannelsUpdate)
+		admin.POST("/integrations/notify-channels/test", handler.AdminNotifyChannelTest)
 		admin.GET("/api-keys", handler.AdminAPIKeys)
 		admin.POST("/api-keys", handler.AdminAPIKeyCreate)
 		admin.PATCH("/api-keys/:id", handler.AdminAPIKeyUpdate)
 		admin.GET("/wallets/:user_id", handler.AdminWalletInfo)
 		admin.POST("/wallets/:user_id/adjust", handler.AdminWalletAdjust)
 		admin.GET("/wallets/:user_id/transactions", handler.AdminWalletTransactions)
+		admin.GET("/wallets/:user_id/holds", handler.AdminWalletHolds)
+		admin.GET("/ledger/accounts", handler.AdminLedgerAccounts)
+		admin.GET("/ledger/entries", handler.AdminLedgerEntries)
+		admin.POST("/ledger/verify", handler.AdminLedgerVerify)
+		admin.POST("/ledger/opening-balances", handler.AdminLedgerOpeningBalances)
 		admin.GET("/wallet/orders", handler.AdminWalletOrders)
 		admin.POST("/wallet/orders/:id/approve", handler.AdminWalletOrderApprove)
 		admin.POST("/wallet/orders/:id/reject", handler.AdminWalletOrderReject)
+		admin.GET("/payout-accounts", handler.AdminPayoutAccounts)
+		admin.POST("/payout-accounts/:id/verify", handler.AdminPayoutAccountVerify)
+		admin.POST("/payout-accounts/:id/reject", handler.AdminPayoutAccountReject)
+		admin.GET("/payout-batches", handler.AdminPayoutBatches)
+		admin.POST("/payout-batches", handler.AdminPayoutBatchCreate)
+		admin.GET("/payout-batches/:id", handler.AdminPayoutBatchDetail)
+		admin.GET("/payout-batches/:id/export", handler.AdminPayoutBatchExport)
+		admin.POST("/payout-batches/:id/paid", handler.AdminPayoutBatchPaid)
+		admin.POST("/payout-batches/:id/failed", handler.AdminPayoutBatchFailed)
+		admin.GET("/finance-reports", handler.AdminFinanceReports)
+		admin.POST("/finance-reports", handler.AdminFinanceReportCreate)
+		admin.PATCH("/finance-reports/:id", handler.AdminFinanceReportUpdate)
+		admin.DELETE("/finance-reports/:id", handler.AdminFinanceReportDelete)
+		admin.POST("/finance-reports/:id/run", handler.AdminFinanceReportRun)
+		admin.GET("/finance-report-runs", handler.AdminFinanceReportRuns)
+		admin.GET("/finance-report-runs/:id", handler.AdminFinanceReportRunDetail)
+		admin.GET("/finance-report-runs/:id/files/:file_id", handler.AdminFinanceReportRunFile)
+		admin.POST("/finance-report-runs/:id/resend", handler.AdminFinanceReportRunResend)
 		admin.GET("/settings", handler.AdminSettingsList)
 		admin.PATCH("/settings", handler.AdminSettingsUpdate)
 		admin.POST("/push-tokens", handler.AdminPushTokenRegister)
@@ -219,9 +295,12 @@ func (adminRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware
 		admin.POST("/coupons/batch-generate", handler.AdminCouponBatchGenerate)
 		admin.PATCH("/profile", handler.AdminProfileUpdate)
 		admin.POST("/profile/change-password", handler.AdminProfileChangePassword)
+		admin.GET("/profile/notification-preferences", handler.NotificationPreferences)
+		admin.PATCH("/profile/notification-preferences", handler.NotificationPreferencesUpdate)
 		admin.POST("/dashboard/overview", handler.AdminDashboardOverview)
 		admin.POST("/dashboard/revenue", handler.AdminDashboardRevenue)
 		admin.GET("/dashboard/vps-status", handler.AdminDashboardVPSStatus)
+		admin.GET("/dashboard/trials", handler.AdminDashboardTrials)
 		admin.POST("/dashboard/revenue-analytics/overview", handler.AdminRevenueAnalyticsOverview)
 		admin.POST("/dashboard/revenue-analytics/trend", handler.AdminRevenueAnalyticsTrend)
 		admin.POST("/dashboard/revenue-analytics/top", handler.AdminRevenueAnalyticsTop)
@@ -229,6 +308,8 @@ func (adminRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware
 		admin.POST("/dashboard/revenue-analytics/export", handler.AdminRevenueAnalyticsExport)
 		admin.GET("/probes", handler.AdminProbes)
 		admin.POST("/probes", handler.AdminProbeCreate)
+		admin.GET("/probes/agent-releases", handler.AdminProbeAgentReleases)
+		admin.PUT("/probes/agent-version", handler.AdminProbeAgentVersionUpdate)
 		admin.GET("/probes/:id", handler.AdminProbeDetail)
 		admin.PATCH("/probes/:id", handler.AdminProbeUpdate)
 		admin.DELETE("/probes/:id", handler.AdminProbeDelete)
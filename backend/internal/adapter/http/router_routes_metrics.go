@@ -0,0 +1,9 @@
+package http
+
+import "github.com/gin-gonic/gin"
+
+type metricsRoutesRegistrar struct{}
+
+func (metricsRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware *Middleware) {
+	r.GET("/metrics", handler.Metrics)
+}
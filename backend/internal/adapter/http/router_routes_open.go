@@ -44,6 +44,8 @@ func (openRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware
 		openSigned.GET("/vps/:id/firewall", handler.VPSFirewallRules)
 		openSigned.POST("/vps/:id/firewall", handler.VPSFirewallRules)
 		openSigned.DELETE("/vps/:id/firewall/:ruleId", handler.VPSFirewallDelete)
+		openSigned.GET("/vps/:id/ips", handler.VPSIPAddresses)
+		openSigned.PUT("/vps/:id/ips/rdns", handler.VPSIPAddressReverseDNS)
 		openSigned.GET("/vps/:id/ports", handler.VPSPortMappings)
 		openSigned.POST("/vps/:id/ports", handler.VPSPortMappings)
 		openSigned.GET("/vps/:id/ports/candidates", handler.VPSPortCandidates)
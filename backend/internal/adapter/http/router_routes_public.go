@@ -30,8 +30,10 @@ func (publicRoutesRegistrar) Register(r *gin.Engine, handler *Handler, _ *Middle
 		public.GET("/cms/blocks", handler.CMSBlocksPublic)
 		public.GET("/cms/posts", handler.CMSPostsPublic)
 		public.GET("/cms/posts/:slug", handler.CMSPostDetailPublic)
+		public.GET("/broadcasts/links/:token", handler.BroadcastLink)
 		public.POST("/probe/enroll", handler.ProbeEnroll)
 		public.POST("/probe/auth/token", handler.ProbeAuthToken)
 		public.GET("/probe/ws", handler.ProbeWS)
+		public.GET("/probe/releases/:version/files/*path", handler.ProbeReleaseFile)
 	}
 }
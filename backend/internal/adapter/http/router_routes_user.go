@@ -12,6 +12,8 @@ func (userRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware
 		user.GET("/me/user-tier", handler.MeUserTier)
 		user.PATCH("/me", handler.UpdateProfile)
 		user.POST("/me/password/change", handler.MePasswordChange)
+		user.GET("/me/notification-preferences", handler.NotificationPreferences)
+		user.PATCH("/me/notification-preferences", handler.NotificationPreferencesUpdate)
 		user.GET("/me/security/contacts", handler.MeSecurityContacts)
 		user.POST("/me/security/email/verify-2fa", handler.MeSecurityEmailVerify2FA)
 		user.POST("/me/security/email/send-code", handler.MeSecurityEmailSendCode)
@@ -22,8 +24,15 @@ func (userRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware
 		user.GET("/me/security/2fa/status", handler.MeTwoFAStatus)
 		user.POST("/me/security/2fa/setup", handler.MeTwoFASetup)
 		user.POST("/me/security/2fa/confirm", handler.MeTwoFAConfirm)
+		user.GET("/me/ssh-keys", handler.MeSSHKeys)
+		user.POST("/me/ssh-keys", handler.MeSSHKeyCreate)
+		user.DELETE("/me/ssh-keys/:id", handler.MeSSHKeyDelete)
 		user.GET("/realname/status", handler.RealNameStatus)
 		user.POST("/realname/verify", handler.RealNameVerify)
+		user.POST("/realname/uploads", handler.RealNameUpload)
+		user.POST("/realname/manual", handler.RealNameManualSubmit)
+		user.GET("/realname/company", handler.RealNameCompanyStatus)
+		user.POST("/realname/company", handler.RealNameCompanySubmit)
 		user.GET("/dashboard", handler.Dashboard)
 		user.GET("/goods-types", handler.GoodsTypes)
 		user.GET("/catalog", handler.Catalog)
@@ -31,6 +40,7 @@ func (userRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware
 		user.GET("/packages", handler.Packages)
 		user.GET("/system-images", handler.SystemImages)
 		user.GET("/billing-cycles", handler.BillingCycles)
+		user.GET("/addons", handler.Addons)
 		user.GET("/payments/providers", handler.PaymentMethods)
 		user.POST("/auth/logout", handler.Logout)
 		user.GET("/cart", handler.CartList)
@@ -41,6 +51,8 @@ func (userRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware
 		user.POST("/orders", handler.OrderCreate)
 		user.POST("/orders/items", handler.OrderCreateItems)
 		user.POST("/coupons/preview", handler.CouponPreview)
+		user.GET("/trials", handler.TrialList)
+		user.POST("/trials", handler.TrialStart)
 		user.GET("/orders", handler.OrderList)
 		user.GET("/orders/:id", handler.OrderDetail)
 		user.POST("/orders/:id/pay", handler.OrderPay)
@@ -59,8 +71,13 @@ func (userRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware
 		user.POST("/notifications/read-all", handler.NotificationReadAll)
 		user.GET("/wallet", handler.WalletInfo)
 		user.GET("/wallet/transactions", handler.WalletTransactions)
+		user.GET("/wallet/holds", handler.WalletHolds)
+		user.GET("/statement", handler.UserStatement)
 		user.POST("/wallet/recharge", handler.WalletRecharge)
 		user.POST("/wallet/withdraw", handler.WalletWithdraw)
+		user.GET("/wallet/payout-accounts", handler.WalletPayoutAccounts)
+		user.POST("/wallet/payout-accounts", handler.WalletPayoutAccountCreate)
+		user.DELETE("/wallet/payout-accounts/:id", handler.WalletPayoutAccountDelete)
 		user.GET("/wallet/orders", handler.WalletOrders)
 		user.POST("/wallet/orders/:id/pay", handler.WalletOrderPay)
 		user.POST("/wallet/orders/:id/cancel", handler.WalletOrderCancel)
@@ -83,14 +100,20 @@ func (userRoutesRegistrar) Register(r *gin.Engine, handler *Handler, middleware
 		user.POST("/vps/:id/backups", handler.VPSBackups)
 		user.DELETE("/vps/:id/backups/:backupId", handler.VPSBackupDelete)
 		user.POST("/vps/:id/backups/:backupId/restore", handler.VPSBackupRestore)
+		user.GET("/vps/:id/backup-policies", handler.VPSBackupPolicies)
+		user.PUT("/vps/:id/backup-policies/:kind", handler.VPSBackupPolicySave)
+		user.DELETE("/vps/:id/backup-policies/:kind", handler.VPSBackupPolicyDelete)
 		user.GET("/vps/:id/firewall", handler.VPSFirewallRules)
 		user.POST("/vps/:id/firewall", handler.VPSFirewallRules)
 		user.DELETE("/vps/:id/firewall/:ruleId", handler.VPSFirewallDelete)
+		user.GET("/vps/:id/ips", handler.VPSIPAddresses)
+		user.PUT("/vps/:id/ips/rdns", handler.VPSIPAddressReverseDNS)
 		user.GET("/vps/:id/ports", handler.VPSPortMappings)
 		user.POST("/vps/:id/ports", handler.VPSPortMappings)
 		user.GET("/vps/:id/ports/candidates", handler.VPSPortCandidates)
 		user.DELETE("/vps/:id/ports/:mappingId", handler.VPSPortMappingDelete)
 		user.POST("/vps/:id/renew", handler.VPSRenewOrder)
+		user.GET("/vps/:id/dunning", handler.VPSDunning)
 		user.POST("/vps/:id/resize/quote", handler.VPSResizeQuote)
 		user.POST("/vps/:id/resize", handler.VPSResizeOrder)
 		user.POST("/vps/:id/emergency-renew", handler.VPSEmergencyRenew)
@@ -38,6 +38,9 @@ func (p *grpcPaymentProvider) CreatePayment(ctx context.Context, req appshared.P
 	}
 	client, ok := p.mgr.GetPaymentClient(p.category, p.pluginID, plugins.DefaultInstanceID)
 	if !ok {
+		if err := p.mgr.UnavailableError(p.category, p.pluginID, plugins.DefaultInstanceID); err != nil {
+			return appshared.PaymentCreateResult{}, err
+		}
 		return appshared.PaymentCreateResult{}, appshared.ErrForbidden
 	}
 	cctx, cancel := context.WithTimeout(ctx, 60*time.Second)
@@ -80,6 +83,9 @@ func (p *grpcPaymentProvider) VerifyNotify(ctx context.Context, req appshared.Ra
 	}
 	client, ok := p.mgr.GetPaymentClient(p.category, p.pluginID, plugins.DefaultInstanceID)
 	if !ok {
+		if err := p.mgr.UnavailableError(p.category, p.pluginID, plugins.DefaultInstanceID); err != nil {
+			return appshared.PaymentNotifyResult{}, err
+		}
 		return appshared.PaymentNotifyResult{}, appshared.ErrForbidden
 	}
 	headers := map[string]*pluginv1.StringList{}
@@ -177,7 +183,13 @@ func (r *Registry) grpcProviderByKey(ctx context.Context, key string) appshared.
 		return nil
 	}
 	for _, it := range items {
-		if !it.Enabled || !it.Loaded || it.InstanceID != plugins.DefaultInstanceID || it.PluginID != pluginID || it.Capabilities.Capabilities.Payment == nil {
+		if !it.Enabled || it.InstanceID != plugins.DefaultInstanceID || it.PluginID != pluginID || it.Capabilities.Capabilities.Payment == nil {
+			continue
+		}
+		// A supervised plugin that is restarting or behind an open circuit keeps
+		// its provider so calls fail fast with that error instead of "not found".
+		down := r.grpcPlugins.UnavailableError(it.Category, it.PluginID, it.InstanceID) != nil
+		if !it.Loaded && !down {
 			continue
 		}
 		enabledMap := r.pluginPaymentMethodEnabledMap(ctx, it.Category, it.PluginID, it.InstanceID)
@@ -186,7 +198,7 @@ func (r *Registry) grpcProviderByKey(ctx context.Context, key string) appshared.
 				if ok, exists := enabledMap[method]; exists && !ok {
 					return nil
 				}
-				if _, ok := r.grpcPlugins.GetPaymentClient(it.Category, it.PluginID, it.InstanceID); !ok {
+				if _, ok := r.grpcPlugins.GetPaymentClient(it.Category, it.PluginID, it.InstanceID); !ok && !down {
 					return nil
 				}
 				return &grpcPaymentProvider{
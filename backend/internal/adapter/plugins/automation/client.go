@@ -116,6 +116,8 @@ func (c *PluginInstanceClient) CreateHost(ctx context.Context, req appshared.Aut
 		MemoryGb:      int32(req.MemoryGB),
 		DiskGb:        int32(req.DiskGB),
 		BandwidthMbps: int32(req.Bandwidth),
+		SshPublicKeys: req.SSHKeys,
+		UserData:      req.UserData,
 	}
 	respAny, err := c.call(ctx, "automation.CreateInstance", pb, func(cctx context.Context, cli pluginv1.AutomationServiceClient) (proto.Message, error) {
 		return cli.CreateInstance(cctx, pb)
@@ -137,6 +139,18 @@ func (c *PluginInstanceClient) GetHostInfo(ctx context.Context, hostID int64) (a
 	}
 	resp := respAny.(*pluginv1.GetInstanceResponse)
 	inst := resp.GetInstance()
+	var ips []appshared.AutomationIP
+	for _, ip := range inst.GetIps() {
+		if ip == nil || strings.TrimSpace(ip.GetAddress()) == "" {
+			continue
+		}
+		ips = append(ips, appshared.AutomationIP{
+			Address: strings.TrimSpace(ip.GetAddress()),
+			Version: int(ip.GetVersion()),
+			Primary: ip.GetPrimary(),
+			PTR:     ip.GetPtr(),
+		})
+	}
 	var expire *time.Time
 	if inst.GetExpireAtUnix() > 0 {
 		t := time.Unix(inst.GetExpireAtUnix(), 0)
@@ -154,6 +168,7 @@ func (c *PluginInstanceClient) GetHostInfo(ctx context.Context, hostID int64) (a
 		VNCPassword:   inst.GetVncPassword(),
 		OSPassword:    inst.GetOsPassword(),
 		RemoteIP:      inst.GetRemoteIp(),
+		IPs:           ips,
 		ExpireAt:      expire,
 	}, nil
 }
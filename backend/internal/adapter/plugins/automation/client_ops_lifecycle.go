@@ -87,8 +87,14 @@ func (c *PluginInstanceClient) RebootHost(ctx context.Context, hostID int64) err
 	return ensureOpOK(respAny)
 }
 
-func (c *PluginInstanceClient) ResetOS(ctx context.Context, hostID int64, templateID int64, password string) error {
-	pb := &pluginv1.RebuildRequest{InstanceId: hostID, ImageId: templateID, Password: password}
+func (c *PluginInstanceClient) ResetOS(ctx context.Context, req appshared.AutomationResetOSRequest) error {
+	pb := &pluginv1.RebuildRequest{
+		InstanceId:    req.HostID,
+		ImageId:       req.TemplateID,
+		Password:      req.Password,
+		SshPublicKeys: req.SSHKeys,
+		UserData:      req.UserData,
+	}
 	respAny, err := c.call(ctx, "automation.Rebuild", pb, func(cctx context.Context, cli pluginv1.AutomationServiceClient) (proto.Message, error) {
 		return cli.Rebuild(cctx, pb)
 	})
@@ -177,6 +177,17 @@ func (c *PluginInstanceClient) DeleteFirewallRule(ctx context.Context, hostID in
 	return ensureOpOK(respAny)
 }
 
+func (c *PluginInstanceClient) SetReverseDNS(ctx context.Context, hostID int64, ip string, ptr string) error {
+	pb := &pluginv1.SetReverseDNSRequest{InstanceId: hostID, Ip: strings.TrimSpace(ip), Ptr: strings.TrimSpace(ptr)}
+	respAny, err := c.call(ctx, "automation.SetReverseDNS", pb, func(cctx context.Context, cli pluginv1.AutomationServiceClient) (proto.Message, error) {
+		return cli.SetReverseDNS(cctx, pb)
+	})
+	if err != nil {
+		return err
+	}
+	return ensureOpOK(respAny)
+}
+
 func (c *PluginInstanceClient) ListPortMappings(ctx context.Context, hostID int64) ([]appshared.AutomationPortMapping, error) {
 	pb := &pluginv1.ListPortMappingsRequest{InstanceId: hostID}
 	respAny, err := c.call(ctx, "automation.ListPortMappings", pb, func(cctx context.Context, cli pluginv1.AutomationServiceClient) (proto.Message, error) {
@@ -157,6 +157,43 @@ func (m *AdminManager) DeletePluginFiles(ctx context.Context, category, pluginID
 	return m.inner.DeletePluginFiles(ctx, category, pluginID)
 }
 
+func (m *AdminManager) FetchRepositoryIndex(ctx context.Context, repoURL string) ([]appshared.PluginRepositoryItem, error) {
+	if m == nil || m.inner == nil {
+		return nil, fmt.Errorf("plugins disabled")
+	}
+	idx, err := FetchRepositoryIndex(ctx, repoURL)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]appshared.PluginRepositoryItem, 0, len(idx.Plugins))
+	for _, p := range idx.Plugins {
+		item := appshared.PluginRepositoryItem{
+			Category:    p.Category,
+			PluginID:    p.PluginID,
+			Name:        p.Name,
+			Description: p.Description,
+			Versions:    make([]appshared.PluginRepositoryVersion, 0, len(p.Versions)),
+		}
+		for _, v := range p.Versions {
+			item.Versions = append(item.Versions, appshared.PluginRepositoryVersion{
+				Version:     v.Version,
+				Platforms:   v.Platforms,
+				Changelog:   v.Changelog,
+				PublishedAt: v.PublishedAt,
+			})
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+func (m *AdminManager) InstallFromRepository(ctx context.Context, repoURL, category, pluginID, version string, allowUntrusted bool) (domain.PluginInstallation, error) {
+	if m == nil || m.inner == nil {
+		return domain.PluginInstallation{}, fmt.Errorf("plugins disabled")
+	}
+	return m.inner.InstallFromRepository(ctx, repoURL, category, pluginID, version, allowUntrusted)
+}
+
 func mapListItem(it ListItem) appshared.PluginListItem {
 	out := appshared.PluginListItem{
 		Category:        it.Category,
@@ -172,6 +209,9 @@ func mapListItem(it ListItem) appshared.PluginListItem {
 		LastHealthAt:    it.LastHealthAt,
 		HealthStatus:    it.HealthStatus,
 		HealthMessage:   it.HealthMessage,
+		Restarts:        it.Restarts,
+		CircuitState:    it.CircuitState,
+		LastCrashAt:     it.LastCrashAt,
 		Capabilities: appshared.PluginManifest{
 			PluginID:    it.Capabilities.PluginID,
 			Name:        it.Capabilities.Name,
@@ -185,6 +225,7 @@ func mapListItem(it ListItem) appshared.PluginListItem {
 				},
 				KYC:        mapKYCCapability(it.Capabilities.Capabilities.KYC),
 				Automation: mapAutomationCapability(it.Capabilities.Capabilities.Automation),
+				Notify:     mapNotifyCapability(it.Capabilities.Capabilities.Notify),
 			},
 		},
 		Entry: appshared.PluginEntryInfo{
@@ -235,3 +276,14 @@ func mapAutomationCapability(in *struct {
 		CatalogReadonly:     in.CatalogReadonly,
 	}
 }
+
+func mapNotifyCapability(in *struct {
+	Channel    string "json:\"channel\""
+	Recipients bool   "json:\"recipients,omitempty\""
+	HTML       bool   "json:\"html,omitempty\""
+}) *appshared.PluginNotifyCapability {
+	if in == nil {
+		return nil
+	}
+	return &appshared.PluginNotifyCapability{Channel: in.Channel, Recipients: in.Recipients, HTML: in.HTML}
+}
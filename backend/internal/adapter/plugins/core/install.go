@@ -24,81 +24,118 @@ type InstallResult struct {
 	Manifest        Manifest
 }
 
-func InstallPackage(baseDir string, filename string, r io.Reader, officialKeys []ed25519.PublicKey) (InstallResult, error) {
-	baseDir = strings.TrimSpace(baseDir)
-	if baseDir == "" {
-		return InstallResult{}, fmt.Errorf("missing base dir")
+// stagedPackage is an extracted and verified package that has not been moved
+// into the plugins dir yet. Cleanup removes the extraction root.
+type stagedPackage struct {
+	root            string
+	Category        string
+	PluginID        string
+	PluginDir       string
+	SignatureStatus domain.PluginSignatureStatus
+	Manifest        Manifest
+}
+
+func (p *stagedPackage) Cleanup() {
+	if p != nil && p.root != "" {
+		_ = os.RemoveAll(p.root)
 	}
+}
 
+func stagePackage(filename string, r io.Reader, officialKeys []ed25519.PublicKey) (*stagedPackage, error) {
 	tmpRoot, err := os.MkdirTemp("", "xiaoheiplay-plugin-install-*")
 	if err != nil {
-		return InstallResult{}, err
+		return nil, err
+	}
+	staged := &stagedPackage{root: tmpRoot}
+	fail := func(err error) (*stagedPackage, error) {
+		staged.Cleanup()
+		return nil, err
 	}
-	defer os.RemoveAll(tmpRoot)
 
 	ext := strings.ToLower(filename)
 	switch {
 	case strings.HasSuffix(ext, ".zip"):
 		if err := extractZip(tmpRoot, r); err != nil {
-			return InstallResult{}, err
+			return fail(err)
 		}
 	case strings.HasSuffix(ext, ".tar.gz") || strings.HasSuffix(ext, ".tgz"):
 		if err := extractTarGz(tmpRoot, r); err != nil {
-			return InstallResult{}, err
+			return fail(err)
 		}
 	default:
-		return InstallResult{}, fmt.Errorf("unsupported package type")
+		return fail(fmt.Errorf("unsupported package type"))
 	}
 
 	manifestPath, err := findSingleManifest(tmpRoot)
 	if err != nil {
-		return InstallResult{}, err
+		return fail(err)
 	}
 	pluginDir := filepath.Dir(manifestPath)
 	rel, _ := filepath.Rel(tmpRoot, pluginDir)
 	rel = filepath.ToSlash(rel)
 	category, pluginID, err := parsePluginDirFromRel(rel)
 	if err != nil {
-		return InstallResult{}, err
+		return fail(err)
 	}
 
 	m, err := ReadManifest(pluginDir)
 	if err != nil {
-		return InstallResult{}, err
+		return fail(err)
 	}
 	if m.PluginID != pluginID {
-		return InstallResult{}, fmt.Errorf("manifest plugin_id mismatch")
+		return fail(fmt.Errorf("manifest plugin_id mismatch"))
 	}
 	entry, err := ResolveEntry(pluginDir, m)
 	if err != nil {
 		if len(entry.SupportedPlatforms) > 0 {
-			return InstallResult{}, fmt.Errorf("%s", "unsupported platform "+entry.Platform+", supported: "+strings.Join(entry.SupportedPlatforms, ", "))
+			return fail(fmt.Errorf("%s", "unsupported platform "+entry.Platform+", supported: "+strings.Join(entry.SupportedPlatforms, ", ")))
 		}
-		return InstallResult{}, err
+		return fail(err)
 	}
 
 	sigStatus, err := VerifySignature(pluginDir, officialKeys)
+	if err != nil {
+		return fail(err)
+	}
+	staged.Category = category
+	staged.PluginID = pluginID
+	staged.PluginDir = pluginDir
+	staged.SignatureStatus = sigStatus
+	staged.Manifest = m
+	return staged, nil
+}
+
+func InstallPackage(baseDir string, filename string, r io.Reader, officialKeys []ed25519.PublicKey) (InstallResult, error) {
+	baseDir = strings.TrimSpace(baseDir)
+	if baseDir == "" {
+		return InstallResult{}, fmt.Errorf("missing base dir")
+	}
+	staged, err := stagePackage(filename, r, officialKeys)
 	if err != nil {
 		return InstallResult{}, err
 	}
+	defer staged.Cleanup()
+	return placeStagedPackage(baseDir, staged)
+}
 
-	finalDir := filepath.Join(baseDir, category, pluginID)
+func placeStagedPackage(baseDir string, staged *stagedPackage) (InstallResult, error) {
+	finalDir := filepath.Join(baseDir, staged.Category, staged.PluginID)
 	if fileExists(finalDir) {
 		return InstallResult{}, fmt.Errorf("plugin already installed")
 	}
 	if err := os.MkdirAll(filepath.Dir(finalDir), 0o755); err != nil {
 		return InstallResult{}, err
 	}
-	if err := copyDir(pluginDir, finalDir); err != nil {
+	if err := copyDir(staged.PluginDir, finalDir); err != nil {
 		_ = os.RemoveAll(finalDir)
 		return InstallResult{}, err
 	}
 	return InstallResult{
-		Category:        category,
-		PluginID:        pluginID,
+		Category:        staged.Category,
+		PluginID:        staged.PluginID,
 		PluginDir:       finalDir,
-		SignatureStatus: sigStatus,
-		Manifest:        m,
+		SignatureStatus: staged.SignatureStatus,
+		Manifest:        staged.Manifest,
 	}, nil
 }
 
@@ -14,6 +14,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	appports "xiaoheiplay/internal/app/ports"
@@ -33,6 +34,7 @@ type Manager struct {
 	cipher       *cryptox.AESGCM
 	repo         appports.PluginInstallationRepository
 	runtime      *Runtime
+	installMu    sync.Mutex
 }
 
 func NewManager(baseDir string, repo appports.PluginInstallationRepository, cipher *cryptox.AESGCM, officialKeys []ed25519.PublicKey) *Manager {
@@ -117,6 +119,9 @@ type ListItem struct {
 	LastHealthAt    *time.Time                   `json:"last_health_at"`
 	HealthStatus    string                       `json:"health_status"`
 	HealthMessage   string                       `json:"health_message"`
+	Restarts        int                          `json:"restarts"`
+	CircuitState    string                       `json:"circuit_state"`
+	LastCrashAt     *time.Time                   `json:"last_crash_at"`
 	Capabilities    Manifest                     `json:"manifest"`
 	Entry           EntryInfo                    `json:"entry"`
 }
@@ -183,6 +188,7 @@ func (m *Manager) List(ctx context.Context) ([]ListItem, error) {
 			}
 			rp.mu.Unlock()
 		}
+		sup := m.runtime.Status(inst.Category, inst.PluginID, inst.InstanceID)
 		out = append(out, ListItem{
 			Category:        inst.Category,
 			PluginID:        inst.PluginID,
@@ -197,6 +203,9 @@ func (m *Manager) List(ctx context.Context) ([]ListItem, error) {
 			LastHealthAt:    lastHealthAt,
 			HealthStatus:    healthStatus,
 			HealthMessage:   healthMessage,
+			Restarts:        sup.Restarts,
+			CircuitState:    sup.CircuitState,
+			LastCrashAt:     sup.LastCrashAt,
 			Capabilities:    manifest,
 			Entry:           entry,
 		})
@@ -724,6 +733,21 @@ func setPath(obj any, path []string, value any) any {
 	return m
 }
 
+// SetHealthEventHandler forwards supervisor events (crashes, restarts, circuit
+// changes, health transitions) to fn.
+func (m *Manager) SetHealthEventHandler(fn func(context.Context, domain.PluginHealthEvent)) {
+	m.runtime.SetHealthEventHandler(fn)
+}
+
+// UnavailableError returns domain.ErrPluginCircuitOpen or domain.ErrPluginRestarting
+// when a supervised instance is down, so callers can fail fast with a clear error.
+func (m *Manager) UnavailableError(category, pluginID, instanceID string) error {
+	if strings.TrimSpace(instanceID) == "" {
+		instanceID = DefaultInstanceID
+	}
+	return m.runtime.Unavailable(category, pluginID, instanceID)
+}
+
 func (m *Manager) GetPaymentClient(category, pluginID, instanceID string) (pluginv1.PaymentServiceClient, bool) {
 	if strings.TrimSpace(instanceID) == "" {
 		instanceID = DefaultInstanceID
@@ -754,6 +778,16 @@ func (m *Manager) GetKYCClient(category, pluginID, instanceID string) (pluginv1.
 	return nil, false
 }
 
+func (m *Manager) GetNotifyClient(category, pluginID, instanceID string) (pluginv1.NotifyServiceClient, bool) {
+	if strings.TrimSpace(instanceID) == "" {
+		instanceID = DefaultInstanceID
+	}
+	if rp, ok := m.runtime.GetRunning(category, pluginID, instanceID); ok && rp.notify != nil {
+		return rp.notify, true
+	}
+	return nil, false
+}
+
 func (m *Manager) decryptConfig(cipherText string) (string, error) {
 	if strings.TrimSpace(cipherText) == "" {
 		return "", nil
@@ -30,6 +30,11 @@ type Manifest struct {
 			NotSupportedReason map[string]string `json:"not_supported_reasons,omitempty"`
 			CatalogReadonly    bool              `json:"catalog_readonly,omitempty"`
 		} `json:"automation,omitempty"`
+		Notify *struct {
+			Channel    string `json:"channel"`
+			Recipients bool   `json:"recipients,omitempty"`
+			HTML       bool   `json:"html,omitempty"`
+		} `json:"notify,omitempty"`
 	} `json:"capabilities"`
 }
 
@@ -0,0 +1,95 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	pluginv1 "xiaoheiplay/plugin/v1"
+)
+
+const notifyCategory = "notify"
+
+type NotifySender struct {
+	manager *Manager
+}
+
+func NewNotifySender(manager *Manager) *NotifySender {
+	return &NotifySender{manager: manager}
+}
+
+func (s *NotifySender) Send(ctx context.Context, pluginID, instanceID string, msg appshared.NotifyMessage) (appshared.NotifyDelivery, error) {
+	if s == nil || s.manager == nil {
+		return appshared.NotifyDelivery{}, fmt.Errorf("plugin manager unavailable")
+	}
+	pluginID = strings.TrimSpace(pluginID)
+	instanceID = strings.TrimSpace(instanceID)
+	if instanceID == "" {
+		instanceID = DefaultInstanceID
+	}
+	if pluginID == "" {
+		return appshared.NotifyDelivery{}, fmt.Errorf("notify plugin not configured")
+	}
+	if _, err := s.manager.EnsureRunning(ctx, notifyCategory, pluginID, instanceID); err != nil {
+		return appshared.NotifyDelivery{}, err
+	}
+	client, ok := s.manager.GetNotifyClient(notifyCategory, pluginID, instanceID)
+	if !ok || client == nil {
+		return appshared.NotifyDelivery{}, fmt.Errorf("notify plugin not running")
+	}
+	resp, err := client.Send(ctx, &pluginv1.SendNotifyRequest{
+		Type:           strings.TrimSpace(msg.Type),
+		Title:          strings.TrimSpace(msg.Title),
+		Content:        msg.Content,
+		Recipients:     msg.Recipients,
+		Vars:           msg.Vars,
+		IdempotencyKey: strings.TrimSpace(msg.IdempotencyKey),
+	})
+	if err != nil {
+		return appshared.NotifyDelivery{}, MapRPCError(err, "notify plugin")
+	}
+	if resp == nil || !resp.Ok {
+		errMsg := "notify send failed"
+		errCode := ""
+		if resp != nil && strings.TrimSpace(resp.Error) != "" {
+			errMsg = strings.TrimSpace(resp.Error)
+		}
+		if resp != nil {
+			errCode = strings.TrimSpace(resp.ErrorCode)
+		}
+		if errCode != "" {
+			return appshared.NotifyDelivery{}, fmt.Errorf("%s (%s)", errMsg, errCode)
+		}
+		return appshared.NotifyDelivery{}, fmt.Errorf("%s", errMsg)
+	}
+	return appshared.NotifyDelivery{MessageID: strings.TrimSpace(resp.MessageId)}, nil
+}
+
+// ListChannels returns every enabled notify plugin instance.
+func (s *NotifySender) ListChannels(ctx context.Context) ([]appshared.NotifyChannel, error) {
+	if s == nil || s.manager == nil {
+		return nil, fmt.Errorf("plugin manager unavailable")
+	}
+	items, err := s.manager.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]appshared.NotifyChannel, 0)
+	for _, it := range items {
+		if it.Category != notifyCategory || !it.Enabled || it.Capabilities.Capabilities.Notify == nil {
+			continue
+		}
+		capability := it.Capabilities.Capabilities.Notify
+		out = append(out, appshared.NotifyChannel{
+			PluginID:   it.PluginID,
+			InstanceID: it.InstanceID,
+			Name:       it.Name,
+			Channel:    capability.Channel,
+			Recipients: capability.Recipients,
+			HTML:       capability.HTML,
+			Loaded:     it.Loaded,
+		})
+	}
+	return out, nil
+}
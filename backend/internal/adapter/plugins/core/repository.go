@@ -0,0 +1,226 @@
+package plugins
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	repositoryIndexFile       = "index.json"
+	repositoryIndexMaxBytes   = 4 << 20
+	repositoryArchiveMaxBytes = 256 << 20
+)
+
+// RepositoryIndex is the static index.json served by a plugin repository.
+//
+//	{"plugins":[{"category":"payment","plugin_id":"ezpay","name":"EZPay",
+//	  "versions":[{"version":"1.2.0","url":"payment/ezpay-1.2.0.tar.gz","sha256":"..."}]}]}
+//
+// Archive URLs may be absolute or relative to the repository root. Archives use
+// the same layout and checksums.json/signature.sig signing as uploaded packages.
+type RepositoryIndex struct {
+	Plugins []RepositoryPlugin `json:"plugins"`
+}
+
+type RepositoryPlugin struct {
+	Category    string              `json:"category"`
+	PluginID    string              `json:"plugin_id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Versions    []RepositoryVersion `json:"versions"`
+}
+
+type RepositoryVersion struct {
+	Version     string   `json:"version"`
+	URL         string   `json:"url"`
+	SHA256      string   `json:"sha256"`
+	Platforms   []string `json:"platforms,omitempty"`
+	Changelog   string   `json:"changelog,omitempty"`
+	PublishedAt string   `json:"published_at,omitempty"`
+}
+
+var repositoryHTTPClient = &http.Client{Timeout: 5 * time.Minute}
+
+func isHTTPRepository(repoURL string) bool {
+	lower := strings.ToLower(repoURL)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+func localRepositoryDir(repoURL string) string {
+	return filepath.Clean(strings.TrimPrefix(repoURL, "file://"))
+}
+
+// FetchRepositoryIndex loads index.json from an http(s) URL or a local directory.
+func FetchRepositoryIndex(ctx context.Context, repoURL string) (RepositoryIndex, error) {
+	repoURL = strings.TrimSpace(repoURL)
+	if repoURL == "" {
+		return RepositoryIndex{}, fmt.Errorf("missing repository url")
+	}
+	var raw []byte
+	if isHTTPRepository(repoURL) {
+		indexURL := repoURL
+		if !strings.HasSuffix(strings.ToLower(indexURL), ".json") {
+			indexURL = strings.TrimRight(indexURL, "/") + "/" + repositoryIndexFile
+		}
+		rc, err := httpGet(ctx, indexURL)
+		if err != nil {
+			return RepositoryIndex{}, err
+		}
+		defer rc.Close()
+		raw, err = readLimited(rc, repositoryIndexMaxBytes)
+		if err != nil {
+			return RepositoryIndex{}, err
+		}
+	} else {
+		f, err := os.Open(filepath.Join(localRepositoryDir(repoURL), repositoryIndexFile))
+		if err != nil {
+			return RepositoryIndex{}, err
+		}
+		defer f.Close()
+		raw, err = readLimited(f, repositoryIndexMaxBytes)
+		if err != nil {
+			return RepositoryIndex{}, err
+		}
+	}
+	var idx RepositoryIndex
+	if err := json.Unmarshal(raw, &idx); err != nil {
+		return RepositoryIndex{}, fmt.Errorf("invalid repository index: %w", err)
+	}
+	return idx, nil
+}
+
+func (idx RepositoryIndex) Find(category, pluginID, version string) (RepositoryPlugin, RepositoryVersion, bool) {
+	for _, p := range idx.Plugins {
+		if p.Category != category || p.PluginID != pluginID {
+			continue
+		}
+		for _, v := range p.Versions {
+			if strings.TrimSpace(v.Version) == version {
+				return p, v, true
+			}
+		}
+	}
+	return RepositoryPlugin{}, RepositoryVersion{}, false
+}
+
+// downloadRepositoryArchive fetches one archive into a temp file and checks its
+// sha256 against the index. The caller removes the returned file.
+func downloadRepositoryArchive(ctx context.Context, repoURL string, v RepositoryVersion) (string, string, error) {
+	want := strings.ToLower(strings.TrimSpace(v.SHA256))
+	if len(want) != 64 {
+		return "", "", fmt.Errorf("repository entry missing sha256")
+	}
+	if _, err := hex.DecodeString(want); err != nil {
+		return "", "", fmt.Errorf("repository entry missing sha256")
+	}
+	ref := strings.TrimSpace(v.URL)
+	if ref == "" {
+		return "", "", fmt.Errorf("repository entry missing url")
+	}
+
+	var src io.ReadCloser
+	var name string
+	if isHTTPRepository(repoURL) || isHTTPRepository(ref) {
+		archiveURL, err := resolveRepositoryURL(repoURL, ref)
+		if err != nil {
+			return "", "", err
+		}
+		src, err = httpGet(ctx, archiveURL)
+		if err != nil {
+			return "", "", err
+		}
+		name = path.Base(strings.SplitN(archiveURL, "?", 2)[0])
+	} else {
+		full, err := safeJoin(localRepositoryDir(repoURL), ref)
+		if err != nil {
+			return "", "", err
+		}
+		f, err := os.Open(full)
+		if err != nil {
+			return "", "", err
+		}
+		src = f
+		name = filepath.Base(full)
+	}
+	defer src.Close()
+
+	tmp, err := os.CreateTemp("", "xiaoheiplay-plugin-download-*")
+	if err != nil {
+		return "", "", err
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(src, repositoryArchiveMaxBytes+1))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n > repositoryArchiveMaxBytes {
+		err = fmt.Errorf("repository archive too large")
+	}
+	if err == nil && hex.EncodeToString(h.Sum(nil)) != want {
+		err = fmt.Errorf("repository archive checksum mismatch")
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", "", err
+	}
+	return tmp.Name(), name, nil
+}
+
+func resolveRepositoryURL(repoURL, ref string) (string, error) {
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid archive url: %w", err)
+	}
+	if refURL.IsAbs() {
+		return refURL.String(), nil
+	}
+	base := strings.TrimSpace(repoURL)
+	if strings.HasSuffix(strings.ToLower(base), ".json") {
+		base = base[:strings.LastIndex(base, "/")+1]
+	} else if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid repository url: %w", err)
+	}
+	return baseURL.ResolveReference(refURL).String(), nil
+}
+
+func httpGet(ctx context.Context, rawURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := repositoryHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("repository request failed: %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func readLimited(r io.Reader, max int64) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > max {
+		return nil, fmt.Errorf("repository index too large")
+	}
+	return b, nil
+}
@@ -0,0 +1,118 @@
+package plugins
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"xiaoheiplay/internal/domain"
+)
+
+// writeRepositoryArchive packs an unsigned payment/demo plugin whose binary
+// exits immediately, so any attempt to start it fails.
+func writeRepositoryArchive(t *testing.T, repoDir, version string) RepositoryVersion {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	files := map[string]string{
+		"payment/demo/manifest.json": `{"plugin_id":"demo","name":"Demo","version":"` + version + `","capabilities":{}}`,
+		"payment/demo/plugin":        "#!/bin/sh\nexit 1\n",
+	}
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("tar header: %v", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("tar write: %v", err)
+		}
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	rel := "demo-" + version + ".tar.gz"
+	if err := os.WriteFile(filepath.Join(repoDir, rel), buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("write archive: %v", err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return RepositoryVersion{Version: version, URL: rel, SHA256: hex.EncodeToString(sum[:])}
+}
+
+func writeRepositoryIndex(t *testing.T, repoDir string, versions ...RepositoryVersion) {
+	t.Helper()
+	idx := RepositoryIndex{Plugins: []RepositoryPlugin{{Category: "payment", PluginID: "demo", Name: "Demo", Versions: versions}}}
+	b, _ := json.Marshal(idx)
+	if err := os.WriteFile(filepath.Join(repoDir, repositoryIndexFile), b, 0o644); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+}
+
+func TestInstallFromRepositoryLocalDir(t *testing.T) {
+	repoDir := t.TempDir()
+	v1 := writeRepositoryArchive(t, repoDir, "1.0.0")
+	bad := writeRepositoryArchive(t, repoDir, "1.0.1")
+	bad.SHA256 = strings.Repeat("0", 64)
+	writeRepositoryIndex(t, repoDir, v1, bad)
+
+	repo := &fakePluginInstallationRepo{}
+	m := NewManager(t.TempDir(), repo, nil, nil)
+	ctx := context.Background()
+
+	if _, err := m.InstallFromRepository(ctx, repoDir, "payment", "demo", "1.0.0", false); !errors.Is(err, domain.ErrAdminPasswordRequiredForUntrustedPlugin) {
+		t.Fatalf("expected untrusted package to be rejected, got %v", err)
+	}
+	if fileExists(m.PluginDir("payment", "demo")) {
+		t.Fatalf("rejected package must not be written")
+	}
+	if _, err := m.InstallFromRepository(ctx, repoDir, "payment", "demo", "1.0.1", true); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	inst, err := m.InstallFromRepository(ctx, "file://"+repoDir, "payment", "demo", "1.0.0", true)
+	if err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	if inst.InstanceID != DefaultInstanceID || inst.SignatureStatus != domain.PluginSignatureUnsigned {
+		t.Fatalf("unexpected installation: %+v", inst)
+	}
+	if mf, err := ReadManifest(m.PluginDir("payment", "demo")); err != nil || mf.Version != "1.0.0" {
+		t.Fatalf("unexpected manifest on disk: %+v %v", mf, err)
+	}
+}
+
+func TestInstallFromRepositoryUpgradeRollsBack(t *testing.T) {
+	repoDir := t.TempDir()
+	v1 := writeRepositoryArchive(t, repoDir, "1.0.0")
+	v2 := writeRepositoryArchive(t, repoDir, "2.0.0")
+	writeRepositoryIndex(t, repoDir, v1, v2)
+
+	repo := &fakePluginInstallationRepo{}
+	m := NewManager(t.TempDir(), repo, nil, nil)
+	ctx := context.Background()
+	if _, err := m.InstallFromRepository(ctx, repoDir, "payment", "demo", "1.0.0", true); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	repo.inst.ConfigCipher = "kept"
+
+	// The 2.0.0 binary cannot start, so the upgrade check fails and 1.0.0 is restored.
+	if _, err := m.InstallFromRepository(ctx, repoDir, "payment", "demo", "2.0.0", true); err == nil || !strings.Contains(err.Error(), "rolled back") {
+		t.Fatalf("expected rollback, got %v", err)
+	}
+	dir := m.PluginDir("payment", "demo")
+	if mf, err := ReadManifest(dir); err != nil || mf.Version != "1.0.0" {
+		t.Fatalf("expected previous version restored: %+v %v", mf, err)
+	}
+	if fileExists(filepath.Join(filepath.Dir(dir), ".demo.rollback")) {
+		t.Fatalf("rollback dir should be consumed")
+	}
+	if repo.inst.ConfigCipher != "kept" {
+		t.Fatalf("instance config must be preserved, got %q", repo.inst.ConfigCipher)
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/hashicorp/go-plugin"
 
+	"xiaoheiplay/internal/domain"
 	"xiaoheiplay/pkg/pluginsdk"
 	pluginv1 "xiaoheiplay/plugin/v1"
 )
@@ -21,8 +22,16 @@ import (
 type Runtime struct {
 	baseDir string
 
-	mu      sync.Mutex
-	running map[string]*runningPlugin
+	mu         sync.Mutex
+	running    map[string]*runningPlugin
+	supervised map[string]*supervisedPlugin
+	policy     supervisorPolicy
+	superOnce  sync.Once
+	onEvent    func(context.Context, domain.PluginHealthEvent)
+
+	// launch and now are swapped out by tests.
+	launch func(ctx context.Context, category, pluginID, instanceID, configJSON string) (*runningPlugin, error)
+	now    func() time.Time
 }
 
 type runningPlugin struct {
@@ -38,15 +47,25 @@ type runningPlugin struct {
 	payment    pluginv1.PaymentServiceClient
 	kyc        pluginv1.KycServiceClient
 	automation pluginv1.AutomationServiceClient
+	notify     pluginv1.NotifyServiceClient
 	manifest   *pluginv1.Manifest
 
 	lastHealth time.Time
 	health     *pluginv1.HealthCheckResponse
 	cancelHB   context.CancelFunc
+	exited     func() bool
 }
 
 func NewRuntime(baseDir string) *Runtime {
-	return &Runtime{baseDir: baseDir, running: map[string]*runningPlugin{}}
+	r := &Runtime{
+		baseDir:    baseDir,
+		running:    map[string]*runningPlugin{},
+		supervised: map[string]*supervisedPlugin{},
+		policy:     defaultSupervisorPolicy,
+		now:        time.Now,
+	}
+	r.launch = r.launchProcess
+	return r
 }
 
 func automationFeatureFromString(s string) (pluginv1.AutomationFeature, bool) {
@@ -63,6 +82,12 @@ func automationFeatureFromString(s string) (pluginv1.AutomationFeature, bool) {
 		return pluginv1.AutomationFeature_AUTOMATION_FEATURE_SNAPSHOT, true
 	case "firewall":
 		return pluginv1.AutomationFeature_AUTOMATION_FEATURE_FIREWALL, true
+	case "ssh_keys":
+		return pluginv1.AutomationFeature_AUTOMATION_FEATURE_SSH_KEYS, true
+	case "cloud_init":
+		return pluginv1.AutomationFeature_AUTOMATION_FEATURE_CLOUD_INIT, true
+	case "rdns":
+		return pluginv1.AutomationFeature_AUTOMATION_FEATURE_RDNS, true
 	default:
 		return pluginv1.AutomationFeature_AUTOMATION_FEATURE_UNSPECIFIED, false
 	}
@@ -93,7 +118,9 @@ func normalizeAutomationNotSupportedReasons(m map[string]string) map[int32]strin
 	return out
 }
 
-func validateManifestConsistency(jsonM Manifest, grpcM *pluginv1.Manifest) error {
+// ValidateManifestConsistency reports the first difference between manifest.json
+// and the manifest the plugin returns over gRPC.
+func ValidateManifestConsistency(jsonM Manifest, grpcM *pluginv1.Manifest) error {
 	if grpcM == nil {
 		return fmt.Errorf("invalid manifest")
 	}
@@ -188,6 +215,17 @@ func validateManifestConsistency(jsonM Manifest, grpcM *pluginv1.Manifest) error
 		}
 	}
 
+	// notify
+	if (jsonM.Capabilities.Notify != nil) != (grpcM.Notify != nil) {
+		return fmt.Errorf("manifest mismatch: notify capability presence")
+	}
+	if jsonM.Capabilities.Notify != nil && grpcM.Notify != nil {
+		jn := jsonM.Capabilities.Notify
+		if strings.TrimSpace(grpcM.Notify.GetChannel()) != strings.TrimSpace(jn.Channel) || grpcM.Notify.GetRecipients() != jn.Recipients || grpcM.Notify.GetHtml() != jn.HTML {
+			return fmt.Errorf("manifest mismatch: notify")
+		}
+	}
+
 	return nil
 }
 
@@ -195,6 +233,9 @@ func (r *Runtime) key(category, pluginID, instanceID string) string {
 	return category + ":" + pluginID + ":" + instanceID
 }
 
+// Start launches the instance unless it is already running. A supervised
+// instance that crashed is only relaunched once its backoff has elapsed, and
+// fails fast with domain.ErrPluginCircuitOpen while its circuit is open.
 func (r *Runtime) Start(ctx context.Context, category, pluginID, instanceID, configJSON string) (*pluginv1.Manifest, error) {
 	if category == "" || pluginID == "" || instanceID == "" {
 		return nil, fmt.Errorf("invalid input")
@@ -203,11 +244,45 @@ func (r *Runtime) Start(ctx context.Context, category, pluginID, instanceID, con
 
 	r.mu.Lock()
 	if existing := r.running[k]; existing != nil {
+		if !existing.isExited() {
+			r.mu.Unlock()
+			return existing.manifest, nil
+		}
+		events := r.handleCrashLocked(k, existing)
+		r.mu.Unlock()
+		r.emit(events)
+		r.mu.Lock()
+	}
+	if err := r.gateLocked(k); err != nil {
+		r.mu.Unlock()
+		return nil, err
+	}
+	r.mu.Unlock()
+
+	rp, err := r.launch(ctx, category, pluginID, instanceID, configJSON)
+
+	r.mu.Lock()
+	if err != nil {
+		events := r.recordStartFailureLocked(k, err)
 		r.mu.Unlock()
+		r.emit(events)
+		return nil, err
+	}
+	if existing := r.running[k]; existing != nil && !existing.isExited() {
+		// Lost a race with a concurrent Start; keep the first instance.
+		r.mu.Unlock()
+		rp.kill()
 		return existing.manifest, nil
 	}
+	r.running[k] = rp
+	events := r.recordStartLocked(k, rp, configJSON)
 	r.mu.Unlock()
+	r.emit(events)
+	r.superOnce.Do(func() { go r.superviseLoop() })
+	return rp.manifest, nil
+}
 
+func (r *Runtime) launchProcess(ctx context.Context, category, pluginID, instanceID, configJSON string) (*runningPlugin, error) {
 	pluginDir := filepath.Join(r.baseDir, category, pluginID)
 	manifestJSON, err := ReadManifest(pluginDir)
 	if err != nil {
@@ -235,6 +310,7 @@ func (r *Runtime) Start(ctx context.Context, category, pluginID, instanceID, con
 			pluginsdk.PluginKeyPayment:    &pluginsdk.PaymentGRPCPlugin{},
 			pluginsdk.PluginKeyKYC:        &pluginsdk.KycGRPCPlugin{},
 			pluginsdk.PluginKeyAutomation: &pluginsdk.AutomationGRPCPlugin{},
+			pluginsdk.PluginKeyNotify:     &pluginsdk.NotifyGRPCPlugin{},
 		},
 		Cmd: cmd,
 	})
@@ -266,7 +342,7 @@ func (r *Runtime) Start(ctx context.Context, category, pluginID, instanceID, con
 		client.Kill()
 		return nil, fmt.Errorf("invalid manifest")
 	}
-	if err := validateManifestConsistency(manifestJSON, manifest); err != nil {
+	if err := ValidateManifestConsistency(manifestJSON, manifest); err != nil {
 		client.Kill()
 		return nil, err
 	}
@@ -275,6 +351,7 @@ func (r *Runtime) Start(ctx context.Context, category, pluginID, instanceID, con
 	var payment pluginv1.PaymentServiceClient
 	var kyc pluginv1.KycServiceClient
 	var automation pluginv1.AutomationServiceClient
+	var notify pluginv1.NotifyServiceClient
 
 	if manifest.Sms != nil {
 		raw, err := rpcClient.Dispense(pluginsdk.PluginKeySMS)
@@ -328,6 +405,19 @@ func (r *Runtime) Start(ctx context.Context, category, pluginID, instanceID, con
 		}
 		automation = c
 	}
+	if manifest.Notify != nil {
+		raw, err := rpcClient.Dispense(pluginsdk.PluginKeyNotify)
+		if err != nil {
+			client.Kill()
+			return nil, err
+		}
+		c, ok := raw.(pluginv1.NotifyServiceClient)
+		if !ok {
+			client.Kill()
+			return nil, fmt.Errorf("invalid notify client")
+		}
+		notify = c
+	}
 
 	ctxi, cancelInit := context.WithTimeout(ctx, 10*time.Second)
 	defer cancelInit()
@@ -355,43 +445,56 @@ func (r *Runtime) Start(ctx context.Context, category, pluginID, instanceID, con
 		payment:    payment,
 		kyc:        kyc,
 		automation: automation,
+		notify:     notify,
 		manifest:   manifest,
 		cancelHB:   hbCancel,
 		health:     nil,
 		lastHealth: time.Time{},
+		exited:     client.Exited,
 	}
 	go rp.heartbeatLoop(hbCtx)
-
-	r.mu.Lock()
-	r.running[k] = rp
-	r.mu.Unlock()
-
-	return manifest, nil
+	return rp, nil
 }
 
+// Stop kills the instance and drops its supervision state, so an admin
+// disable/enable also resets the restart counter and circuit.
 func (r *Runtime) Stop(category, pluginID, instanceID string) {
 	k := r.key(category, pluginID, instanceID)
 	r.mu.Lock()
 	rp := r.running[k]
 	delete(r.running, k)
+	delete(r.supervised, k)
 	r.mu.Unlock()
 	if rp == nil {
 		return
 	}
-	if rp.cancelHB != nil {
-		rp.cancelHB()
-	}
-	if rp.client != nil {
-		rp.client.Kill()
-	}
+	rp.kill()
 }
 
+// GetRunning reports a loaded instance; a process that has exited but not yet
+// been reaped by the supervisor is treated as not running.
 func (r *Runtime) GetRunning(category, pluginID, instanceID string) (*runningPlugin, bool) {
 	k := r.key(category, pluginID, instanceID)
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	rp := r.running[k]
-	return rp, rp != nil
+	if rp == nil || rp.isExited() {
+		return nil, false
+	}
+	return rp, true
+}
+
+func (p *runningPlugin) isExited() bool {
+	return p.exited != nil && p.exited()
+}
+
+func (p *runningPlugin) kill() {
+	if p.cancelHB != nil {
+		p.cancelHB()
+	}
+	if p.client != nil {
+		p.client.Kill()
+	}
 }
 
 func (p *runningPlugin) heartbeatLoop(ctx context.Context) {
@@ -409,6 +512,10 @@ func (p *runningPlugin) heartbeatLoop(ctx context.Context) {
 			resp, err := p.core.Health(cctx, &pluginv1.HealthCheckRequest{InstanceId: p.instanceID})
 			cancel()
 			if err != nil {
+				// Keep lastHealth as the last success so staleness stays visible.
+				p.mu.Lock()
+				p.health = &pluginv1.HealthCheckResponse{Status: pluginv1.HealthStatus_HEALTH_STATUS_ERROR, Message: err.Error()}
+				p.mu.Unlock()
 				continue
 			}
 			p.mu.Lock()
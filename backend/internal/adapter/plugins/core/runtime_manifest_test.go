@@ -32,7 +32,7 @@ func TestValidateManifestConsistencyCatalogReadonlyMatch(t *testing.T) {
 		},
 	}
 
-	if err := validateManifestConsistency(jsonM, grpcM); err != nil {
+	if err := ValidateManifestConsistency(jsonM, grpcM); err != nil {
 		t.Fatalf("expected no error, got: %v", err)
 	}
 }
@@ -62,7 +62,7 @@ func TestValidateManifestConsistencyCatalogReadonlyMismatch(t *testing.T) {
 		},
 	}
 
-	err := validateManifestConsistency(jsonM, grpcM)
+	err := ValidateManifestConsistency(jsonM, grpcM)
 	if err == nil {
 		t.Fatalf("expected mismatch error")
 	}
@@ -0,0 +1,313 @@
+package plugins
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"xiaoheiplay/internal/domain"
+	pluginv1 "xiaoheiplay/plugin/v1"
+)
+
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+type supervisorPolicy struct {
+	Interval    time.Duration
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// MaxFailures consecutive crashes or failed restarts open the circuit.
+	MaxFailures int
+	// OpenFor is how long an open circuit rejects calls before one half-open
+	// restart attempt is made.
+	OpenFor time.Duration
+	// StableAfter a healthy run resets the failure count.
+	StableAfter time.Duration
+}
+
+var defaultSupervisorPolicy = supervisorPolicy{
+	Interval:    time.Second,
+	BaseBackoff: time.Second,
+	MaxBackoff:  2 * time.Minute,
+	MaxFailures: 5,
+	OpenFor:     5 * time.Minute,
+	StableAfter: 2 * time.Minute,
+}
+
+// supervisedPlugin is the restart bookkeeping for one instance. It outlives
+// crashes of the process and is only dropped by Stop.
+type supervisedPlugin struct {
+	category   string
+	pluginID   string
+	instanceID string
+	configJSON string
+
+	restarts    int
+	failures    int
+	crashed     bool
+	nextAttempt time.Time
+	openUntil   time.Time
+	lastCrashAt time.Time
+	lastError   string
+	startedAt   time.Time
+	unhealthy   bool
+}
+
+// SupervisorStatus is the restart state reported for plugin listings.
+type SupervisorStatus struct {
+	Restarts     int
+	CircuitState string
+	LastCrashAt  *time.Time
+	LastError    string
+}
+
+// SetHealthEventHandler registers a callback for supervisor events. It is
+// invoked on its own goroutine, outside any runtime lock.
+func (r *Runtime) SetHealthEventHandler(fn func(context.Context, domain.PluginHealthEvent)) {
+	r.mu.Lock()
+	r.onEvent = fn
+	r.mu.Unlock()
+}
+
+func (r *Runtime) Status(category, pluginID, instanceID string) SupervisorStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := SupervisorStatus{CircuitState: CircuitClosed}
+	s := r.supervised[r.key(category, pluginID, instanceID)]
+	if s == nil {
+		return st
+	}
+	st.Restarts = s.restarts
+	st.CircuitState = r.circuitStateLocked(s)
+	st.LastError = s.lastError
+	if !s.lastCrashAt.IsZero() {
+		t := s.lastCrashAt
+		st.LastCrashAt = &t
+	}
+	return st
+}
+
+// Unavailable explains why an instance is not running: domain.ErrPluginCircuitOpen
+// or domain.ErrPluginRestarting. It returns nil for instances that are running
+// or were never started.
+func (r *Runtime) Unavailable(category, pluginID, instanceID string) error {
+	k := r.key(category, pluginID, instanceID)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rp := r.running[k]; rp != nil && !rp.isExited() {
+		return nil
+	}
+	s := r.supervised[k]
+	if s == nil {
+		return nil
+	}
+	if r.circuitStateLocked(s) == CircuitOpen {
+		return domain.ErrPluginCircuitOpen
+	}
+	return domain.ErrPluginRestarting
+}
+
+func (r *Runtime) circuitStateLocked(s *supervisedPlugin) string {
+	if s.openUntil.IsZero() {
+		return CircuitClosed
+	}
+	if r.now().Before(s.openUntil) {
+		return CircuitOpen
+	}
+	return CircuitHalfOpen
+}
+
+func (r *Runtime) gateLocked(k string) error {
+	s := r.supervised[k]
+	if s == nil || !s.crashed {
+		return nil
+	}
+	now := r.now()
+	if !s.openUntil.IsZero() && now.Before(s.openUntil) {
+		return domain.ErrPluginCircuitOpen
+	}
+	if now.Before(s.nextAttempt) {
+		return domain.ErrPluginRestarting
+	}
+	return nil
+}
+
+func (r *Runtime) backoff(failures int) time.Duration {
+	d := r.policy.BaseBackoff
+	for i := 1; i < failures && d < r.policy.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > r.policy.MaxBackoff {
+		d = r.policy.MaxBackoff
+	}
+	return d
+}
+
+// failLocked counts one failure and schedules the next attempt, opening the
+// circuit once MaxFailures is reached.
+func (r *Runtime) failLocked(s *supervisedPlugin, now time.Time) bool {
+	s.failures++
+	s.crashed = true
+	if s.failures >= r.policy.MaxFailures {
+		s.openUntil = now.Add(r.policy.OpenFor)
+		s.nextAttempt = s.openUntil
+		return true
+	}
+	s.nextAttempt = now.Add(r.backoff(s.failures))
+	return false
+}
+
+func (r *Runtime) event(s *supervisedPlugin, kind, msg string) domain.PluginHealthEvent {
+	return domain.PluginHealthEvent{
+		Category:   s.category,
+		PluginID:   s.pluginID,
+		InstanceID: s.instanceID,
+		Kind:       kind,
+		Message:    msg,
+		Restarts:   s.restarts,
+		At:         r.now(),
+	}
+}
+
+func (r *Runtime) handleCrashLocked(k string, rp *runningPlugin) []domain.PluginHealthEvent {
+	if r.running[k] == rp {
+		delete(r.running, k)
+	}
+	rp.kill()
+	s := r.supervised[k]
+	if s == nil {
+		return nil
+	}
+	now := r.now()
+	s.lastCrashAt = now
+	s.lastError = "plugin process exited"
+	events := []domain.PluginHealthEvent{r.event(s, domain.PluginHealthCrashed, s.lastError)}
+	if r.failLocked(s, now) {
+		events = append(events, r.event(s, domain.PluginHealthCircuitOpen, s.lastError))
+	}
+	log.Printf("plugin crashed: %s: failures=%d next_attempt=%s", k, s.failures, s.nextAttempt.Format(time.RFC3339))
+	return events
+}
+
+func (r *Runtime) recordStartFailureLocked(k string, err error) []domain.PluginHealthEvent {
+	s := r.supervised[k]
+	if s == nil {
+		// Never ran: surface the error to the caller without supervising.
+		return nil
+	}
+	s.lastError = err.Error()
+	events := []domain.PluginHealthEvent{r.event(s, domain.PluginHealthRestartFailed, s.lastError)}
+	if r.failLocked(s, r.now()) {
+		events = append(events, r.event(s, domain.PluginHealthCircuitOpen, s.lastError))
+	}
+	log.Printf("plugin restart failed: %s: failures=%d: %v", k, s.failures, err)
+	return events
+}
+
+func (r *Runtime) recordStartLocked(k string, rp *runningPlugin, configJSON string) []domain.PluginHealthEvent {
+	s := r.supervised[k]
+	if s == nil {
+		r.supervised[k] = &supervisedPlugin{
+			category:   rp.category,
+			pluginID:   rp.pluginID,
+			instanceID: rp.instanceID,
+			configJSON: configJSON,
+			startedAt:  r.now(),
+		}
+		return nil
+	}
+	s.configJSON = configJSON
+	s.startedAt = r.now()
+	s.unhealthy = false
+	if !s.crashed {
+		return nil
+	}
+	s.crashed = false
+	s.restarts++
+	events := []domain.PluginHealthEvent{r.event(s, domain.PluginHealthRestarted, "")}
+	if !s.openUntil.IsZero() {
+		s.openUntil = time.Time{}
+		events = append(events, r.event(s, domain.PluginHealthCircuitClosed, ""))
+	}
+	return events
+}
+
+func (r *Runtime) emit(events []domain.PluginHealthEvent) {
+	if len(events) == 0 {
+		return
+	}
+	r.mu.Lock()
+	fn := r.onEvent
+	r.mu.Unlock()
+	if fn == nil {
+		return
+	}
+	go func() {
+		for _, ev := range events {
+			fn(context.Background(), ev)
+		}
+	}()
+}
+
+func (r *Runtime) superviseLoop() {
+	ticker := time.NewTicker(r.policy.Interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		r.superviseOnce()
+	}
+}
+
+// superviseOnce reaps exited processes, tracks health transitions and
+// relaunches crashed instances whose backoff has elapsed.
+func (r *Runtime) superviseOnce() {
+	var events []domain.PluginHealthEvent
+	var due []supervisedPlugin
+
+	r.mu.Lock()
+	now := r.now()
+	for k, rp := range r.running {
+		if rp.isExited() {
+			events = append(events, r.handleCrashLocked(k, rp)...)
+			continue
+		}
+		s := r.supervised[k]
+		if s == nil {
+			continue
+		}
+		rp.mu.Lock()
+		health := rp.health
+		rp.mu.Unlock()
+		unhealthy := health != nil && health.GetStatus() == pluginv1.HealthStatus_HEALTH_STATUS_ERROR
+		if unhealthy != s.unhealthy {
+			s.unhealthy = unhealthy
+			if unhealthy {
+				events = append(events, r.event(s, domain.PluginHealthUnhealthy, health.GetMessage()))
+			} else {
+				events = append(events, r.event(s, domain.PluginHealthRecovered, ""))
+			}
+		}
+		if !unhealthy && s.failures > 0 && now.Sub(s.startedAt) >= r.policy.StableAfter {
+			s.failures = 0
+		}
+	}
+	for k, s := range r.supervised {
+		if _, ok := r.running[k]; ok || !s.crashed {
+			continue
+		}
+		if now.Before(s.nextAttempt) || (!s.openUntil.IsZero() && now.Before(s.openUntil)) {
+			continue
+		}
+		due = append(due, *s)
+	}
+	r.mu.Unlock()
+	r.emit(events)
+
+	for _, s := range due {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		_, _ = r.Start(ctx, s.category, s.pluginID, s.instanceID, s.configJSON)
+		cancel()
+	}
+}
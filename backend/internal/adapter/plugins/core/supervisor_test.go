@@ -0,0 +1,126 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"xiaoheiplay/internal/domain"
+	pluginv1 "xiaoheiplay/plugin/v1"
+)
+
+type fakeLauncher struct {
+	clock  time.Time
+	fail   bool
+	calls  int
+	exited atomic.Bool
+}
+
+func newSupervisedRuntime(t *testing.T) (*Runtime, *fakeLauncher) {
+	t.Helper()
+	f := &fakeLauncher{clock: time.Unix(1_700_000_000, 0)}
+	r := NewRuntime(t.TempDir())
+	r.now = func() time.Time { return f.clock }
+	r.superOnce.Do(func() {}) // tests drive superviseOnce by hand
+	r.launch = func(ctx context.Context, category, pluginID, instanceID, configJSON string) (*runningPlugin, error) {
+		f.calls++
+		if f.fail {
+			return nil, errors.New("boom")
+		}
+		f.exited.Store(false)
+		return &runningPlugin{
+			category:   category,
+			pluginID:   pluginID,
+			instanceID: instanceID,
+			manifest:   &pluginv1.Manifest{PluginId: pluginID},
+			exited:     f.exited.Load,
+		}, nil
+	}
+	return r, f
+}
+
+func TestSupervisorRestartsCrashedPluginWithBackoff(t *testing.T) {
+	r, f := newSupervisedRuntime(t)
+	if _, err := r.Start(context.Background(), "payment", "demo", "default", "{}"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	f.exited.Store(true)
+	if _, ok := r.GetRunning("payment", "demo", "default"); ok {
+		t.Fatalf("exited process must not be reported as running")
+	}
+	r.superviseOnce()
+	if err := r.Unavailable("payment", "demo", "default"); !errors.Is(err, domain.ErrPluginRestarting) {
+		t.Fatalf("expected restarting, got %v", err)
+	}
+	if _, err := r.Start(context.Background(), "payment", "demo", "default", "{}"); !errors.Is(err, domain.ErrPluginRestarting) {
+		t.Fatalf("start during backoff should fail fast, got %v", err)
+	}
+	if f.calls != 1 {
+		t.Fatalf("no relaunch expected during backoff, calls=%d", f.calls)
+	}
+
+	f.clock = f.clock.Add(r.policy.BaseBackoff)
+	r.superviseOnce()
+	if _, ok := r.GetRunning("payment", "demo", "default"); !ok {
+		t.Fatalf("expected plugin restarted after backoff")
+	}
+	st := r.Status("payment", "demo", "default")
+	if st.Restarts != 1 || st.CircuitState != CircuitClosed || st.LastCrashAt == nil {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+}
+
+func TestSupervisorOpensCircuitAfterRepeatedFailures(t *testing.T) {
+	r, f := newSupervisedRuntime(t)
+	ctx := context.Background()
+	if _, err := r.Start(ctx, "automation", "demo", "default", "{}"); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	f.exited.Store(true)
+	f.fail = true
+	r.superviseOnce() // crash: failure 1
+	for i := 1; i < r.policy.MaxFailures; i++ {
+		f.clock = f.clock.Add(r.policy.MaxBackoff)
+		r.superviseOnce() // failed restart
+	}
+	if st := r.Status("automation", "demo", "default"); st.CircuitState != CircuitOpen {
+		t.Fatalf("expected open circuit, got %+v", st)
+	}
+	calls := f.calls
+	if _, err := r.Start(ctx, "automation", "demo", "default", "{}"); !errors.Is(err, domain.ErrPluginCircuitOpen) {
+		t.Fatalf("expected circuit open error, got %v", err)
+	}
+	if f.calls != calls {
+		t.Fatalf("open circuit must not launch the plugin")
+	}
+
+	// Half-open attempt after OpenFor closes the circuit on success.
+	f.fail = false
+	f.clock = f.clock.Add(r.policy.OpenFor)
+	r.superviseOnce()
+	if st := r.Status("automation", "demo", "default"); st.CircuitState != CircuitClosed || st.Restarts != 1 {
+		t.Fatalf("expected closed circuit after recovery, got %+v", st)
+	}
+
+	// Stop (admin disable) forgets supervision state entirely.
+	r.Stop("automation", "demo", "default")
+	if err := r.Unavailable("automation", "demo", "default"); err != nil {
+		t.Fatalf("stopped plugin should not be reported as down: %v", err)
+	}
+}
+
+func TestSupervisorBackoffIsCapped(t *testing.T) {
+	r := NewRuntime(t.TempDir())
+	if got := r.backoff(1); got != r.policy.BaseBackoff {
+		t.Fatalf("backoff(1)=%s", got)
+	}
+	if got := r.backoff(3); got != 4*r.policy.BaseBackoff {
+		t.Fatalf("backoff(3)=%s", got)
+	}
+	if got := r.backoff(50); got != r.policy.MaxBackoff {
+		t.Fatalf("backoff(50)=%s", got)
+	}
+}
@@ -0,0 +1,231 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"xiaoheiplay/internal/domain"
+	pluginv1 "xiaoheiplay/plugin/v1"
+)
+
+const upgradeHealthTimeout = 10 * time.Second
+
+// InstallFromRepository downloads one version from a repository and installs it,
+// or upgrades the plugin in place when it is already on disk. Untrusted
+// packages are rejected before anything is written unless allowUntrusted.
+func (m *Manager) InstallFromRepository(ctx context.Context, repoURL, category, pluginID, version string, allowUntrusted bool) (domain.PluginInstallation, error) {
+	if m.repo == nil {
+		return domain.PluginInstallation{}, fmt.Errorf("%w: plugin repo missing", domain.ErrPluginManagerUnavailable)
+	}
+	category = strings.TrimSpace(category)
+	pluginID = strings.TrimSpace(pluginID)
+	version = strings.TrimSpace(version)
+	if category == "" || pluginID == "" || version == "" {
+		return domain.PluginInstallation{}, domain.ErrInvalidInput
+	}
+	idx, err := FetchRepositoryIndex(ctx, repoURL)
+	if err != nil {
+		return domain.PluginInstallation{}, fmt.Errorf("fetch repository index: %w", err)
+	}
+	_, entry, ok := idx.Find(category, pluginID, version)
+	if !ok {
+		return domain.PluginInstallation{}, domain.ErrPluginVersionNotFound
+	}
+	archivePath, archiveName, err := downloadRepositoryArchive(ctx, repoURL, entry)
+	if err != nil {
+		return domain.PluginInstallation{}, fmt.Errorf("download plugin package: %w", err)
+	}
+	defer os.Remove(archivePath)
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return domain.PluginInstallation{}, err
+	}
+	staged, err := stagePackage(archiveName, f, m.officialKeys)
+	_ = f.Close()
+	if err != nil {
+		return domain.PluginInstallation{}, fmt.Errorf("stage plugin package: %w", err)
+	}
+	defer staged.Cleanup()
+	if staged.Category != category || staged.PluginID != pluginID {
+		return domain.PluginInstallation{}, fmt.Errorf("%w: package does not match repository entry", domain.ErrInvalidInput)
+	}
+	if strings.TrimSpace(staged.Manifest.Version) != version {
+		return domain.PluginInstallation{}, fmt.Errorf("%w: manifest version mismatch", domain.ErrInvalidInput)
+	}
+	if staged.SignatureStatus != domain.PluginSignatureOfficial && !allowUntrusted {
+		return domain.PluginInstallation{}, domain.ErrAdminPasswordRequiredForUntrustedPlugin
+	}
+
+	m.installMu.Lock()
+	defer m.installMu.Unlock()
+	if fileExists(m.PluginDir(category, pluginID)) {
+		if err := m.upgradeInPlace(ctx, staged); err != nil {
+			return domain.PluginInstallation{}, err
+		}
+		return m.repo.GetPluginInstallation(ctx, category, pluginID, DefaultInstanceID)
+	}
+	res, err := placeStagedPackage(m.baseDir, staged)
+	if err != nil {
+		return domain.PluginInstallation{}, err
+	}
+	inst := domain.PluginInstallation{
+		Category:        res.Category,
+		PluginID:        res.PluginID,
+		InstanceID:      DefaultInstanceID,
+		Enabled:         false,
+		SignatureStatus: res.SignatureStatus,
+	}
+	if err := m.repo.UpsertPluginInstallation(ctx, &inst); err != nil {
+		_ = os.RemoveAll(res.PluginDir)
+		return domain.PluginInstallation{}, err
+	}
+	return m.repo.GetPluginInstallation(ctx, category, pluginID, DefaultInstanceID)
+}
+
+// upgradeInPlace swaps the plugin directory for the staged one while keeping
+// every instance row (and so its encrypted config). Enabled instances are
+// restarted on the new binary, which re-runs ValidateManifestConsistency, and
+// must then pass Health; otherwise the previous directory is restored.
+func (m *Manager) upgradeInPlace(ctx context.Context, staged *stagedPackage) error {
+	category, pluginID := staged.Category, staged.PluginID
+	dir := m.PluginDir(category, pluginID)
+	backup := filepath.Join(filepath.Dir(dir), "."+pluginID+".rollback")
+
+	all, err := m.repo.ListPluginInstallations(ctx)
+	if err != nil {
+		return err
+	}
+	var insts []domain.PluginInstallation
+	for _, inst := range all {
+		if inst.Category == category && inst.PluginID == pluginID {
+			insts = append(insts, inst)
+		}
+	}
+	var wasRunning []domain.PluginInstallation
+	for _, inst := range insts {
+		if _, ok := m.runtime.GetRunning(category, pluginID, inst.InstanceID); ok {
+			wasRunning = append(wasRunning, inst)
+			m.runtime.Stop(category, pluginID, inst.InstanceID)
+		}
+	}
+
+	_ = os.RemoveAll(backup)
+	if err := os.Rename(dir, backup); err != nil {
+		m.restartInstances(ctx, wasRunning)
+		return err
+	}
+	rollback := func(cause error) error {
+		for _, inst := range insts {
+			m.runtime.Stop(category, pluginID, inst.InstanceID)
+		}
+		_ = os.RemoveAll(dir)
+		if err := os.Rename(backup, dir); err != nil {
+			log.Printf("plugin upgrade rollback failed: %s/%s: %v", category, pluginID, err)
+			return fmt.Errorf("upgrade failed: %w (rollback failed: %v)", cause, err)
+		}
+		m.restartInstances(ctx, wasRunning)
+		log.Printf("plugin upgrade rolled back: %s/%s: %v", category, pluginID, cause)
+		return fmt.Errorf("upgrade rolled back: %w", cause)
+	}
+	if err := copyDir(staged.PluginDir, dir); err != nil {
+		return rollback(err)
+	}
+	if err := m.verifyUpgrade(ctx, category, pluginID, insts); err != nil {
+		return rollback(err)
+	}
+	_ = os.RemoveAll(backup)
+
+	for _, inst := range insts {
+		inst.SignatureStatus = staged.SignatureStatus
+		if err := m.repo.UpsertPluginInstallation(ctx, &inst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Manager) verifyUpgrade(ctx context.Context, category, pluginID string, insts []domain.PluginInstallation) error {
+	started := 0
+	for _, inst := range insts {
+		if !inst.Enabled {
+			continue
+		}
+		cfg, err := m.decryptConfig(inst.ConfigCipher)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(cfg) == "" {
+			cfg = "{}"
+		}
+		if _, err := m.runtime.Start(ctx, category, pluginID, inst.InstanceID, cfg); err != nil {
+			return fmt.Errorf("instance %s: %w", inst.InstanceID, err)
+		}
+		if err := m.runtime.CheckHealth(ctx, category, pluginID, inst.InstanceID, upgradeHealthTimeout); err != nil {
+			return fmt.Errorf("instance %s: %w", inst.InstanceID, err)
+		}
+		started++
+	}
+	if started > 0 {
+		return nil
+	}
+	// No enabled instance to exercise: still make sure the new binary starts
+	// and reports a manifest matching manifest.json.
+	manifestJSON, err := ReadManifest(m.PluginDir(category, pluginID))
+	if err != nil {
+		return err
+	}
+	client, _, manifest, err := m.dialCore(ctx, category, pluginID)
+	if err != nil {
+		return err
+	}
+	defer client.Kill()
+	return ValidateManifestConsistency(manifestJSON, manifest)
+}
+
+func (m *Manager) restartInstances(ctx context.Context, insts []domain.PluginInstallation) {
+	for _, inst := range insts {
+		cfg, err := m.decryptConfig(inst.ConfigCipher)
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(cfg) == "" {
+			cfg = "{}"
+		}
+		if _, err := m.runtime.Start(ctx, inst.Category, inst.PluginID, inst.InstanceID, cfg); err != nil {
+			log.Printf("plugin restart failed: %s/%s/%s: %v", inst.Category, inst.PluginID, inst.InstanceID, err)
+		}
+	}
+}
+
+// CheckHealth runs one Health call against a running instance and records the
+// result like the heartbeat loop does.
+func (r *Runtime) CheckHealth(ctx context.Context, category, pluginID, instanceID string, timeout time.Duration) error {
+	rp, ok := r.GetRunning(category, pluginID, instanceID)
+	if !ok || rp == nil || rp.core == nil {
+		return fmt.Errorf("plugin instance not running")
+	}
+	cctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	resp, err := rp.core.Health(cctx, &pluginv1.HealthCheckRequest{InstanceId: instanceID})
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	rp.mu.Lock()
+	rp.lastHealth = time.Now()
+	rp.health = resp
+	rp.mu.Unlock()
+	switch resp.GetStatus() {
+	case pluginv1.HealthStatus_HEALTH_STATUS_OK, pluginv1.HealthStatus_HEALTH_STATUS_DEGRADED:
+		return nil
+	}
+	if msg := strings.TrimSpace(resp.GetMessage()); msg != "" {
+		return fmt.Errorf("health check failed: %s", msg)
+	}
+	return fmt.Errorf("health check failed: %s", resp.GetStatus().String())
+}
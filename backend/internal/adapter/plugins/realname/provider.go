@@ -48,6 +48,9 @@ func (p *Provider) VerifyWithInput(ctx context.Context, in appshared.RealNameVer
 	}
 	client, ok := p.mgr.GetKYCClient("kyc", p.pluginID, p.instanceID)
 	if !ok || client == nil {
+		if err := p.mgr.UnavailableError("kyc", p.pluginID, p.instanceID); err != nil {
+			return false, "", err
+		}
 		return false, "plugin not loaded", nil
 	}
 	params := map[string]string{
@@ -138,6 +141,9 @@ func (p *Provider) QueryPending(ctx context.Context, token string, provider stri
 	}
 	client, ok := p.mgr.GetKYCClient("kyc", p.pluginID, p.instanceID)
 	if !ok || client == nil {
+		if err := p.mgr.UnavailableError("kyc", p.pluginID, p.instanceID); err != nil {
+			return "", "", err
+		}
 		return "", "plugin not loaded", nil
 	}
 	cctx, cancel := context.WithTimeout(ctx, 10*time.Second)
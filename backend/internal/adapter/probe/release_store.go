@@ -0,0 +1,185 @@
+package probe
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	plugins "xiaoheiplay/internal/adapter/plugins/core"
+	"xiaoheiplay/internal/domain"
+	pkgversion "xiaoheiplay/internal/pkg/version"
+)
+
+var releaseVersionRE = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z._-]{0,63}$`)
+
+// ReleaseStore serves pingbot releases from <baseDir>/<version>/.
+// Each release directory is signed with cmd/tools/pluginsign, so the same
+// checksums.json + signature.sig verification used for plugins applies.
+type ReleaseStore struct {
+	baseDir      string
+	officialKeys []ed25519.PublicKey
+}
+
+type releaseManifest struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+func NewReleaseStore(baseDir string, officialKeys []ed25519.PublicKey) *ReleaseStore {
+	return &ReleaseStore{baseDir: strings.TrimSpace(baseDir), officialKeys: officialKeys}
+}
+
+func (s *ReleaseStore) ListProbeReleases(ctx context.Context) ([]domain.ProbeAgentRelease, error) {
+	if s == nil || s.baseDir == "" {
+		return nil, domain.ErrProbeReleasesDisabled
+	}
+	entries, err := os.ReadDir(s.baseDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []domain.ProbeAgentRelease{}, nil
+		}
+		return nil, err
+	}
+	out := make([]domain.ProbeAgentRelease, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || !releaseVersionRE.MatchString(entry.Name()) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(s.baseDir, entry.Name(), "manifest.json")); err != nil {
+			continue
+		}
+		out = append(out, s.inspect(entry.Name()))
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return pkgversion.Compare(out[i].Version, out[j].Version) > 0
+	})
+	return out, nil
+}
+
+func (s *ReleaseStore) GetProbeRelease(ctx context.Context, version string) (domain.ProbeAgentRelease, error) {
+	if s == nil || s.baseDir == "" {
+		return domain.ProbeAgentRelease{}, domain.ErrProbeReleasesDisabled
+	}
+	version = strings.TrimSpace(version)
+	if !releaseVersionRE.MatchString(version) {
+		return domain.ProbeAgentRelease{}, domain.ErrProbeReleaseNotFound
+	}
+	if _, err := os.Stat(filepath.Join(s.baseDir, version, "manifest.json")); err != nil {
+		return domain.ProbeAgentRelease{}, domain.ErrProbeReleaseNotFound
+	}
+	return s.inspect(version), nil
+}
+
+func (s *ReleaseStore) OpenProbeReleaseFile(ctx context.Context, version, relPath string) (io.ReadCloser, int64, error) {
+	if s == nil || s.baseDir == "" {
+		return nil, 0, domain.ErrProbeReleasesDisabled
+	}
+	version = strings.TrimSpace(version)
+	if !releaseVersionRE.MatchString(version) {
+		return nil, 0, domain.ErrProbeReleaseNotFound
+	}
+	rel, ok := cleanReleaseFilePath(relPath)
+	if !ok {
+		return nil, 0, domain.ErrProbeReleaseNotFound
+	}
+	f, err := os.Open(filepath.Join(s.baseDir, version, filepath.FromSlash(rel)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, domain.ErrProbeReleaseNotFound
+		}
+		return nil, 0, err
+	}
+	st, err := f.Stat()
+	if err != nil || st.IsDir() {
+		_ = f.Close()
+		return nil, 0, domain.ErrProbeReleaseNotFound
+	}
+	return f, st.Size(), nil
+}
+
+func (s *ReleaseStore) inspect(dirName string) domain.ProbeAgentRelease {
+	dir := filepath.Join(s.baseDir, dirName)
+	rel := domain.ProbeAgentRelease{
+		Version:         dirName,
+		Platforms:       listReleasePlatforms(dir),
+		SignatureStatus: domain.PluginSignatureUnsigned,
+	}
+	if st, err := os.Stat(dir); err == nil {
+		rel.CreatedAt = st.ModTime()
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		rel.Error = err.Error()
+		return rel
+	}
+	var m releaseManifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		rel.Error = "invalid manifest: " + err.Error()
+		return rel
+	}
+	// The manifest is covered by checksums.json, so requiring it to match the
+	// directory name prevents an old signed release being re-published under a new version.
+	if strings.TrimSpace(m.Version) != dirName {
+		rel.Error = "manifest version mismatch"
+		return rel
+	}
+	status, err := plugins.VerifySignature(dir, s.officialKeys)
+	rel.SignatureStatus = status
+	if err != nil {
+		rel.Error = err.Error()
+		return rel
+	}
+	if len(rel.Platforms) == 0 {
+		rel.Error = "no binaries"
+		return rel
+	}
+	rel.Valid = true
+	return rel
+}
+
+func listReleasePlatforms(dir string) []string {
+	entries, err := os.ReadDir(filepath.Join(dir, "bin"))
+	if err != nil {
+		return []string{}
+	}
+	out := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		for _, name := range []string{"pingbot", "pingbot.exe"} {
+			if st, err := os.Stat(filepath.Join(dir, "bin", entry.Name(), name)); err == nil && !st.IsDir() {
+				out = append(out, entry.Name())
+				break
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// cleanReleaseFilePath only exposes the files pingbot needs to self-update.
+func cleanReleaseFilePath(raw string) (string, bool) {
+	rel := strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(raw)), "/")
+	if rel == "" || strings.Contains(rel, "..") || strings.Contains(rel, ":") || strings.Contains(rel, "\\") {
+		return "", false
+	}
+	switch rel {
+	case "manifest.json", "checksums.json", "signature.sig":
+		return rel, true
+	}
+	parts := strings.Split(rel, "/")
+	if len(parts) != 3 || parts[0] != "bin" || !releaseVersionRE.MatchString(parts[1]) {
+		return "", false
+	}
+	if parts[2] != "pingbot" && parts[2] != "pingbot.exe" {
+		return "", false
+	}
+	return rel, true
+}
@@ -0,0 +1,103 @@
+package probe
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"xiaoheiplay/internal/domain"
+)
+
+func writeSignedRelease(t *testing.T, baseDir, version, manifestVersion string, priv ed25519.PrivateKey) {
+	t.Helper()
+	dir := filepath.Join(baseDir, version)
+	files := map[string][]byte{
+		"manifest.json":           []byte(`{"name":"pingbot","version":"` + manifestVersion + `"}`),
+		"bin/linux_amd64/pingbot": []byte("binary-" + version),
+	}
+	sums := map[string]string{}
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+		sum := sha256.Sum256(content)
+		sums[rel] = hex.EncodeToString(sum[:])
+	}
+	cs, _ := json.Marshal(map[string]any{"algo": "sha256", "files": sums})
+	if err := os.WriteFile(filepath.Join(dir, "checksums.json"), cs, 0o644); err != nil {
+		t.Fatalf("write checksums: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "signature.sig"), []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, cs))), 0o644); err != nil {
+		t.Fatalf("write signature: %v", err)
+	}
+}
+
+func TestReleaseStoreListAndOpen(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	baseDir := t.TempDir()
+	writeSignedRelease(t, baseDir, "1.9.0", "1.9.0", priv)
+	writeSignedRelease(t, baseDir, "1.10.0", "1.10.0", priv)
+	writeSignedRelease(t, baseDir, "2.0.0", "1.0.0", priv)
+
+	store := NewReleaseStore(baseDir, []ed25519.PublicKey{pub})
+	items, err := store.ListProbeReleases(context.Background())
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(items) != 3 || items[0].Version != "2.0.0" || items[1].Version != "1.10.0" || items[2].Version != "1.9.0" {
+		t.Fatalf("unexpected order: %+v", items)
+	}
+	if items[0].Valid || items[0].Error != "manifest version mismatch" {
+		t.Fatalf("expected mismatched manifest to be invalid: %+v", items[0])
+	}
+	if !items[1].Valid || items[1].SignatureStatus != domain.PluginSignatureOfficial || len(items[1].Platforms) != 1 || items[1].Platforms[0] != "linux_amd64" {
+		t.Fatalf("unexpected release: %+v", items[1])
+	}
+
+	rc, size, err := store.OpenProbeReleaseFile(context.Background(), "1.10.0", "/bin/linux_amd64/pingbot")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	b, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	if string(b) != "binary-1.10.0" || size != int64(len(b)) {
+		t.Fatalf("unexpected file content=%q size=%d", b, size)
+	}
+
+	for _, rel := range []string{"../1.9.0/manifest.json", "bin/linux_amd64/other", "bin/../manifest.json", "notes.txt"} {
+		if _, _, err := store.OpenProbeReleaseFile(context.Background(), "1.10.0", rel); !errors.Is(err, domain.ErrProbeReleaseNotFound) {
+			t.Fatalf("expected not found for %q, got %v", rel, err)
+		}
+	}
+}
+
+func TestReleaseStoreUntrustedKey(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+	baseDir := t.TempDir()
+	writeSignedRelease(t, baseDir, "1.0.0", "1.0.0", priv)
+
+	rel, err := NewReleaseStore(baseDir, []ed25519.PublicKey{other}).GetProbeRelease(context.Background(), "1.0.0")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if rel.SignatureStatus == domain.PluginSignatureOfficial {
+		t.Fatalf("expected non-official signature, got %+v", rel)
+	}
+	if _, err := NewReleaseStore("", nil).ListProbeReleases(context.Background()); !errors.Is(err, domain.ErrProbeReleasesDisabled) {
+		t.Fatalf("expected disabled error, got %v", err)
+	}
+}
@@ -11,12 +11,15 @@ import (
 
 	appshared "xiaoheiplay/internal/app/shared"
 	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/pkg/cryptox"
 )
 
 type GormRepo struct {
 	db      *sql.DB
 	gdb     *gorm.DB
 	dialect string
+	// idCipher encrypts real-name ID numbers at rest when set.
+	idCipher *cryptox.AESGCM
 }
 
 func NewGormRepo(gdb *gorm.DB) *GormRepo {
@@ -24,6 +27,30 @@ func NewGormRepo(gdb *gorm.DB) *GormRepo {
 	return &GormRepo{db: sqlDB, gdb: gdb, dialect: gdb.Dialector.Name()}
 }
 
+type txKey struct{}
+
+// InTx runs fn in one transaction. Repository calls made with the ctx passed
+// to fn join it; nested calls reuse the outer transaction.
+func (r *GormRepo) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn returns the transaction started by InTx, or the shared handle.
+func (r *GormRepo) conn(ctx context.Context) *gorm.DB {
+	if ctx == nil {
+		return r.gdb.WithContext(ctx)
+	}
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return r.gdb.WithContext(ctx)
+}
+
 func nullIfEmpty(value string) any {
 	if strings.TrimSpace(value) == "" {
 		return nil
@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"xiaoheiplay/internal/domain"
+)
+
+// ListAddons returns the addons of one plan group, or all addons when
+// planGroupID is 0.
+func (r *GormRepo) ListAddons(ctx context.Context, planGroupID int64) ([]domain.Addon, error) {
+	q := r.conn(ctx).Model(&addonRow{})
+	if planGroupID > 0 {
+		q = q.Where("plan_group_id = ?", planGroupID)
+	}
+	var rows []addonRow
+	if err := q.Order("sort_order, id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.Addon, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromAddonRow(row))
+	}
+	return out, nil
+}
+
+func (r *GormRepo) GetAddon(ctx context.Context, id int64) (domain.Addon, error) {
+	var row addonRow
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+		return domain.Addon{}, r.ensure(err)
+	}
+	return fromAddonRow(row), nil
+}
+
+func (r *GormRepo) CreateAddon(ctx context.Context, addon *domain.Addon) error {
+	row := addonRow{
+		PlanGroupID: addon.PlanGroupID,
+		Kind:        string(addon.Kind),
+		Name:        addon.Name,
+		Description: addon.Description,
+		Monthly:     addon.Monthly,
+		Units:       addon.Units,
+		MaxQty:      addon.MaxQty,
+		Active:      boolToInt(addon.Active),
+		SortOrder:   addon.SortOrder,
+	}
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	addon.ID = row.ID
+	addon.CreatedAt = row.CreatedAt
+	addon.UpdatedAt = row.UpdatedAt
+	return nil
+}
+
+func (r *GormRepo) UpdateAddon(ctx context.Context, addon domain.Addon) error {
+	return r.conn(ctx).Model(&addonRow{}).Where("id = ?", addon.ID).Updates(map[string]any{
+		"plan_group_id": addon.PlanGroupID,
+		"kind":          string(addon.Kind),
+		"name":          addon.Name,
+		"description":   addon.Description,
+		"monthly":       addon.Monthly,
+		"units":         addon.Units,
+		"max_qty":       addon.MaxQty,
+		"active":        boolToInt(addon.Active),
+		"sort_order":    addon.SortOrder,
+		"updated_at":    time.Now(),
+	}).Error
+}
+
+func (r *GormRepo) DeleteAddon(ctx context.Context, id int64) error {
+	return r.conn(ctx).Delete(&addonRow{}, id).Error
+}
+
+func fromAddonRow(row addonRow) domain.Addon {
+	return domain.Addon{
+		ID:          row.ID,
+		PlanGroupID: row.PlanGroupID,
+		Kind:        domain.AddonKind(row.Kind),
+		Name:        row.Name,
+		Description: row.Description,
+		Monthly:     row.Monthly,
+		Units:       row.Units,
+		MaxQty:      row.MaxQty,
+		Active:      row.Active == 1,
+		SortOrder:   row.SortOrder,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+}
@@ -15,7 +15,7 @@ import (
 
 func (r *GormRepo) AddAuditLog(ctx context.Context, log domain.AdminAuditLog) error {
 
-	return r.gdb.WithContext(ctx).Create(&adminAuditLogRow{
+	return r.conn(ctx).Create(&adminAuditLogRow{
 		AdminID:    log.AdminID,
 		Action:     log.Action,
 		TargetType: log.TargetType,
@@ -28,11 +28,11 @@ func (r *GormRepo) AddAuditLog(ctx context.Context, log domain.AdminAuditLog) er
 func (r *GormRepo) ListAuditLogs(ctx context.Context, limit, offset int) ([]domain.AdminAuditLog, int, error) {
 
 	var total int64
-	if err := r.gdb.WithContext(ctx).Model(&adminAuditLogRow{}).Count(&total).Error; err != nil {
+	if err := r.conn(ctx).Model(&adminAuditLogRow{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 	var rows []adminAuditLogRow
-	if err := r.gdb.WithContext(ctx).Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
 		return nil, 0, err
 	}
 	out := make([]domain.AdminAuditLog, 0, len(rows))
@@ -52,7 +52,7 @@ func (r *GormRepo) ListAuditLogs(ctx context.Context, limit, offset int) ([]doma
 }
 
 func (r *GormRepo) PurgeAuditLogs(ctx context.Context, before time.Time) error {
-	return r.gdb.WithContext(ctx).
+	return r.conn(ctx).
 		Where("created_at < ?", before).
 		Delete(&adminAuditLogRow{}).Error
 }
@@ -60,7 +60,7 @@ func (r *GormRepo) PurgeAuditLogs(ctx context.Context, before time.Time) error {
 func (r *GormRepo) ListPermissionGroups(ctx context.Context) ([]domain.PermissionGroup, error) {
 
 	var rows []permissionGroupRow
-	if err := r.gdb.WithContext(ctx).Order("id ASC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("id ASC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.PermissionGroup, 0, len(rows))
@@ -85,7 +85,7 @@ func (r *GormRepo) ListPermissionGroups(ctx context.Context) ([]domain.Permissio
 func (r *GormRepo) GetPermissionGroup(ctx context.Context, id int64) (domain.PermissionGroup, error) {
 
 	var row permissionGroupRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.PermissionGroup{}, r.ensure(err)
 	}
 	permsJSON, err := r.loadPermissionGroupPermissionsJSON(ctx, row.ID, row.PermissionsJSON)
@@ -114,7 +114,7 @@ func (r *GormRepo) CreatePermissionGroup(ctx context.Context, group *domain.Perm
 		Description:     group.Description,
 		PermissionsJSON: string(permJSON),
 	}
-	return r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(&row).Error; err != nil {
 			return err
 		}
@@ -136,7 +136,7 @@ func (r *GormRepo) UpdatePermissionGroup(ctx context.Context, group domain.Permi
 		return err
 	}
 	permJSON, _ := json.Marshal(perms)
-	return r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Model(&permissionGroupRow{}).Where("id = ?", group.ID).Updates(map[string]any{
 			"name":             group.Name,
 			"description":      group.Description,
@@ -151,7 +151,7 @@ func (r *GormRepo) UpdatePermissionGroup(ctx context.Context, group domain.Permi
 }
 
 func (r *GormRepo) DeletePermissionGroup(ctx context.Context, id int64) error {
-	return r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Where("permission_group_id = ?", id).Delete(&permissionGroupPermissionRow{}).Error; err != nil {
 			return err
 		}
@@ -162,7 +162,7 @@ func (r *GormRepo) DeletePermissionGroup(ctx context.Context, id int64) error {
 
 func (r *GormRepo) loadPermissionGroupPermissionsJSON(ctx context.Context, groupID int64, fallback string) (string, error) {
 	var rows []permissionGroupPermissionRow
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Where("permission_group_id = ?", groupID).
 		Order("permission_code ASC").
 		Find(&rows).Error; err != nil {
@@ -234,7 +234,7 @@ func replacePermissionGroupPermissionsTx(tx *gorm.DB, groupID int64, perms []str
 func (r *GormRepo) ListPermissions(ctx context.Context) ([]domain.Permission, error) {
 
 	var rows []permissionModel
-	if err := r.gdb.WithContext(ctx).Order("category, sort_order, code").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("category, sort_order, code").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.Permission, 0, len(rows))
@@ -258,7 +258,7 @@ func (r *GormRepo) ListPermissions(ctx context.Context) ([]domain.Permission, er
 func (r *GormRepo) GetPermissionByCode(ctx context.Context, code string) (domain.Permission, error) {
 
 	var row permissionModel
-	if err := r.gdb.WithContext(ctx).Where("code = ?", code).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("code = ?", code).First(&row).Error; err != nil {
 		return domain.Permission{}, r.ensure(err)
 	}
 	return domain.Permission{
@@ -285,7 +285,7 @@ func (r *GormRepo) UpsertPermission(ctx context.Context, perm *domain.Permission
 		SortOrder:    perm.SortOrder,
 		UpdatedAt:    time.Now(),
 	}
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Clauses(clause.OnConflict{
 			Columns: []clause.Column{{Name: "code"}},
 			DoUpdates: clause.AssignmentColumns([]string{
@@ -296,7 +296,7 @@ func (r *GormRepo) UpsertPermission(ctx context.Context, perm *domain.Permission
 		return err
 	}
 	var got permissionModel
-	if err := r.gdb.WithContext(ctx).Where("code = ?", perm.Code).First(&got).Error; err == nil {
+	if err := r.conn(ctx).Where("code = ?", perm.Code).First(&got).Error; err == nil {
 		perm.ID = got.ID
 	}
 	return nil
@@ -304,7 +304,7 @@ func (r *GormRepo) UpsertPermission(ctx context.Context, perm *domain.Permission
 
 func (r *GormRepo) UpdatePermissionName(ctx context.Context, code string, name string) error {
 
-	return r.gdb.WithContext(ctx).Model(&permissionModel{}).Where("code = ?", code).Updates(map[string]any{
+	return r.conn(ctx).Model(&permissionModel{}).Where("code = ?", code).Updates(map[string]any{
 		"name":       name,
 		"updated_at": time.Now(),
 	}).Error
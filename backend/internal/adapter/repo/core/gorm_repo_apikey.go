@@ -15,7 +15,7 @@ func (r *GormRepo) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
 		ScopesJSON:        key.ScopesJSON,
 		PermissionGroupID: key.PermissionGroupID,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	key.ID = row.ID
@@ -28,7 +28,7 @@ func (r *GormRepo) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
 func (r *GormRepo) GetAPIKeyByHash(ctx context.Context, keyHash string) (domain.APIKey, error) {
 
 	var row apiKeyRow
-	if err := r.gdb.WithContext(ctx).Where("key_hash = ?", keyHash).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("key_hash = ?", keyHash).First(&row).Error; err != nil {
 		return domain.APIKey{}, r.ensure(err)
 	}
 	var out domain.APIKey
@@ -47,11 +47,11 @@ func (r *GormRepo) GetAPIKeyByHash(ctx context.Context, keyHash string) (domain.
 func (r *GormRepo) ListAPIKeys(ctx context.Context, limit, offset int) ([]domain.APIKey, int, error) {
 
 	var total int64
-	if err := r.gdb.WithContext(ctx).Model(&apiKeyRow{}).Count(&total).Error; err != nil {
+	if err := r.conn(ctx).Model(&apiKeyRow{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 	var rows []apiKeyRow
-	if err := r.gdb.WithContext(ctx).Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
 		return nil, 0, err
 	}
 	out := make([]domain.APIKey, 0, len(rows))
@@ -73,7 +73,7 @@ func (r *GormRepo) ListAPIKeys(ctx context.Context, limit, offset int) ([]domain
 
 func (r *GormRepo) UpdateAPIKeyStatus(ctx context.Context, id int64, status domain.APIKeyStatus) error {
 
-	return r.gdb.WithContext(ctx).Model(&apiKeyRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&apiKeyRow{}).Where("id = ?", id).Updates(map[string]any{
 		"status":     status,
 		"updated_at": time.Now(),
 	}).Error
@@ -82,6 +82,6 @@ func (r *GormRepo) UpdateAPIKeyStatus(ctx context.Context, id int64, status doma
 
 func (r *GormRepo) TouchAPIKey(ctx context.Context, id int64) error {
 
-	return r.gdb.WithContext(ctx).Model(&apiKeyRow{}).Where("id = ?", id).Update("last_used_at", time.Now()).Error
+	return r.conn(ctx).Model(&apiKeyRow{}).Where("id = ?", id).Update("last_used_at", time.Now()).Error
 
 }
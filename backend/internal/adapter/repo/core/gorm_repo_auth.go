@@ -16,7 +16,7 @@ func (r *GormRepo) CreateUser(ctx context.Context, user *domain.User) error {
 	if row.UpdatedAt.IsZero() {
 		row.UpdatedAt = row.CreatedAt
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	*user = fromUserRow(row)
@@ -27,7 +27,7 @@ func (r *GormRepo) CreateUser(ctx context.Context, user *domain.User) error {
 func (r *GormRepo) GetUserByID(ctx context.Context, id int64) (domain.User, error) {
 
 	var row userRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.User{}, r.ensure(err)
 	}
 	return fromUserRow(row), nil
@@ -37,7 +37,7 @@ func (r *GormRepo) GetUserByID(ctx context.Context, id int64) (domain.User, erro
 func (r *GormRepo) GetUserByUsernameOrEmail(ctx context.Context, usernameOrEmail string) (domain.User, error) {
 
 	var row userRow
-	if err := r.gdb.WithContext(ctx).Where("username = ? OR email = ?", usernameOrEmail, usernameOrEmail).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("username = ? OR email = ?", usernameOrEmail, usernameOrEmail).First(&row).Error; err != nil {
 		return domain.User{}, r.ensure(err)
 	}
 	return fromUserRow(row), nil
@@ -46,7 +46,7 @@ func (r *GormRepo) GetUserByUsernameOrEmail(ctx context.Context, usernameOrEmail
 
 func (r *GormRepo) GetUserByPhone(ctx context.Context, phone string) (domain.User, error) {
 	var row userRow
-	if err := r.gdb.WithContext(ctx).Where("phone = ?", strings.TrimSpace(phone)).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("phone = ?", strings.TrimSpace(phone)).First(&row).Error; err != nil {
 		return domain.User{}, r.ensure(err)
 	}
 	return fromUserRow(row), nil
@@ -55,11 +55,11 @@ func (r *GormRepo) GetUserByPhone(ctx context.Context, phone string) (domain.Use
 func (r *GormRepo) ListUsers(ctx context.Context, limit, offset int) ([]domain.User, int, error) {
 
 	var total int64
-	if err := r.gdb.WithContext(ctx).Model(&userRow{}).Count(&total).Error; err != nil {
+	if err := r.conn(ctx).Model(&userRow{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 	var rows []userRow
-	if err := r.gdb.WithContext(ctx).Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
 		return nil, 0, err
 	}
 	out := make([]domain.User, 0, len(rows))
@@ -72,7 +72,7 @@ func (r *GormRepo) ListUsers(ctx context.Context, limit, offset int) ([]domain.U
 
 func (r *GormRepo) ListUsersByRoleStatus(ctx context.Context, role string, status string, limit, offset int) ([]domain.User, int, error) {
 
-	q := r.gdb.WithContext(ctx).Model(&userRow{}).Where("role = ?", role)
+	q := r.conn(ctx).Model(&userRow{}).Where("role = ?", role)
 	if status != "" {
 		q = q.Where("status = ?", status)
 	}
@@ -97,7 +97,7 @@ func (r *GormRepo) GetMinUserIDByRole(ctx context.Context, role string) (int64,
 	var row struct {
 		ID int64 `gorm:"column:id"`
 	}
-	if err := r.gdb.WithContext(ctx).Model(&userRow{}).Select("id").Where("role = ?", role).Order("id ASC").Limit(1).Take(&row).Error; err != nil {
+	if err := r.conn(ctx).Model(&userRow{}).Select("id").Where("role = ?", role).Order("id ASC").Limit(1).Take(&row).Error; err != nil {
 		return 0, r.ensure(err)
 	}
 	return row.ID, nil
@@ -106,7 +106,7 @@ func (r *GormRepo) GetMinUserIDByRole(ctx context.Context, role string) (int64,
 
 func (r *GormRepo) UpdateUserStatus(ctx context.Context, id int64, status domain.UserStatus) error {
 
-	return r.gdb.WithContext(ctx).Model(&userRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&userRow{}).Where("id = ?", id).Updates(map[string]any{
 		"status":     status,
 		"updated_at": time.Now(),
 	}).Error
@@ -118,7 +118,7 @@ func (r *GormRepo) UpdateUser(ctx context.Context, user domain.User) error {
 	if strings.TrimSpace(user.Email) != "" {
 		email = strings.TrimSpace(user.Email)
 	}
-	return r.gdb.WithContext(ctx).Model(&userRow{}).Where("id = ?", user.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&userRow{}).Where("id = ?", user.ID).Updates(map[string]any{
 		"username":                user.Username,
 		"email":                   email,
 		"qq":                      user.QQ,
@@ -146,7 +146,7 @@ func (r *GormRepo) UpdateUser(ctx context.Context, user domain.User) error {
 func (r *GormRepo) UpdateUserPassword(ctx context.Context, id int64, passwordHash string) error {
 	now := time.Now()
 
-	return r.gdb.WithContext(ctx).Model(&userRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&userRow{}).Where("id = ?", id).Updates(map[string]any{
 		"password_hash":       passwordHash,
 		"password_changed_at": now,
 		"updated_at":          now,
@@ -165,14 +165,14 @@ func (r *GormRepo) CreateCaptcha(ctx context.Context, captcha domain.Captcha) er
 	if row.CreatedAt.IsZero() {
 		row.CreatedAt = time.Now()
 	}
-	return r.gdb.WithContext(ctx).Create(&row).Error
+	return r.conn(ctx).Create(&row).Error
 
 }
 
 func (r *GormRepo) GetCaptcha(ctx context.Context, id string) (domain.Captcha, error) {
 
 	var row captchaRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.Captcha{}, r.ensure(err)
 	}
 	return domain.Captcha{
@@ -186,7 +186,7 @@ func (r *GormRepo) GetCaptcha(ctx context.Context, id string) (domain.Captcha, e
 
 func (r *GormRepo) DeleteCaptcha(ctx context.Context, id string) error {
 
-	return r.gdb.WithContext(ctx).Delete(&captchaRow{}, "id = ?", id).Error
+	return r.conn(ctx).Delete(&captchaRow{}, "id = ?", id).Error
 
 }
 
@@ -199,7 +199,7 @@ func (r *GormRepo) CreateVerificationCode(ctx context.Context, code domain.Verif
 		CodeHash:  code.CodeHash,
 		ExpiresAt: code.ExpiresAt,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	code.ID = row.ID
@@ -210,7 +210,7 @@ func (r *GormRepo) CreateVerificationCode(ctx context.Context, code domain.Verif
 func (r *GormRepo) GetLatestVerificationCode(ctx context.Context, channel, receiver, purpose string) (domain.VerificationCode, error) {
 
 	var row verificationCodeRow
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Where("channel = ? AND receiver = ? AND purpose = ?", channel, receiver, purpose).
 		Order("id DESC").
 		Limit(1).
@@ -231,6 +231,6 @@ func (r *GormRepo) GetLatestVerificationCode(ctx context.Context, channel, recei
 
 func (r *GormRepo) DeleteVerificationCodes(ctx context.Context, channel, receiver, purpose string) error {
 
-	return r.gdb.WithContext(ctx).Where("channel = ? AND receiver = ? AND purpose = ?", channel, receiver, purpose).Delete(&verificationCodeRow{}).Error
+	return r.conn(ctx).Where("channel = ? AND receiver = ? AND purpose = ?", channel, receiver, purpose).Delete(&verificationCodeRow{}).Error
 
 }
@@ -17,7 +17,7 @@ func (r *GormRepo) CreateAutomationLog(ctx context.Context, log *domain.Automati
 		Success:      boolToInt(log.Success),
 		Message:      log.Message,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	log.ID = row.ID
@@ -26,7 +26,7 @@ func (r *GormRepo) CreateAutomationLog(ctx context.Context, log *domain.Automati
 }
 
 func (r *GormRepo) ListAutomationLogs(ctx context.Context, orderID int64, limit, offset int) ([]domain.AutomationLog, int, error) {
-	q := r.gdb.WithContext(ctx).Model(&automationLogRow{})
+	q := r.conn(ctx).Model(&automationLogRow{})
 	if orderID > 0 {
 		q = q.Where("order_id = ?", orderID)
 	}
@@ -59,7 +59,7 @@ func (r *GormRepo) ListAutomationLogs(ctx context.Context, orderID int64, limit,
 }
 
 func (r *GormRepo) PurgeAutomationLogs(ctx context.Context, before time.Time) error {
-	return r.gdb.WithContext(ctx).
+	return r.conn(ctx).
 		Where("created_at < ?", before).
 		Delete(&automationLogRow{}).Error
 }
@@ -79,7 +79,7 @@ func (r *GormRepo) CreateOrUpdateProvisionJob(ctx context.Context, job *domain.P
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Clauses(clause.OnConflict{
 			Columns: []clause.Column{{Name: "order_item_id"}},
 			DoUpdates: clause.AssignmentColumns([]string{
@@ -90,7 +90,7 @@ func (r *GormRepo) CreateOrUpdateProvisionJob(ctx context.Context, job *domain.P
 		return err
 	}
 	var got provisionJobRow
-	if err := r.gdb.WithContext(ctx).Select("id").Where("order_item_id = ?", job.OrderItemID).First(&got).Error; err == nil {
+	if err := r.conn(ctx).Select("id").Where("order_item_id = ?", job.OrderItemID).First(&got).Error; err == nil {
 		job.ID = got.ID
 	}
 	return nil
@@ -107,7 +107,7 @@ func (r *GormRepo) ListDueProvisionJobs(ctx context.Context, limit int) ([]domai
 	}
 
 	var rows []provisionJobRow
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Where("status IN ?", []string{"pending", "retry", "running"}).
 		Order("id ASC").
 		Limit(fetchLimit).
@@ -140,7 +140,7 @@ func (r *GormRepo) ListDueProvisionJobs(ctx context.Context, limit int) ([]domai
 }
 
 func (r *GormRepo) UpdateProvisionJob(ctx context.Context, job domain.ProvisionJob) error {
-	return r.gdb.WithContext(ctx).Model(&provisionJobRow{}).Where("id = ?", job.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&provisionJobRow{}).Where("id = ?", job.ID).Updates(map[string]any{
 		"status":      job.Status,
 		"attempts":    job.Attempts,
 		"next_run_at": job.NextRunAt,
@@ -149,6 +149,32 @@ func (r *GormRepo) UpdateProvisionJob(ctx context.Context, job domain.ProvisionJ
 	}).Error
 }
 
+func (r *GormRepo) ProvisionJobStats(ctx context.Context) ([]domain.ProvisionJobStat, error) {
+	var rows []struct {
+		Status      string
+		Count       int64
+		Attempts    int64
+		MaxAttempts int
+	}
+	if err := r.conn(ctx).Model(&provisionJobRow{}).
+		Select("status, COUNT(*) AS count, COALESCE(SUM(attempts), 0) AS attempts, COALESCE(MAX(attempts), 0) AS max_attempts").
+		Group("status").
+		Order("status ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.ProvisionJobStat, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, domain.ProvisionJobStat{
+			Status:      row.Status,
+			Count:       row.Count,
+			Attempts:    row.Attempts,
+			MaxAttempts: row.MaxAttempts,
+		})
+	}
+	return out, nil
+}
+
 func (r *GormRepo) CreateTaskRun(ctx context.Context, run *domain.ScheduledTaskRun) error {
 
 	row := scheduledTaskRunRow{
@@ -159,7 +185,7 @@ func (r *GormRepo) CreateTaskRun(ctx context.Context, run *domain.ScheduledTaskR
 		DurationSec: run.DurationSec,
 		Message:     run.Message,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	run.ID = row.ID
@@ -170,7 +196,7 @@ func (r *GormRepo) CreateTaskRun(ctx context.Context, run *domain.ScheduledTaskR
 
 func (r *GormRepo) UpdateTaskRun(ctx context.Context, run domain.ScheduledTaskRun) error {
 
-	return r.gdb.WithContext(ctx).Model(&scheduledTaskRunRow{}).Where("id = ?", run.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&scheduledTaskRunRow{}).Where("id = ?", run.ID).Updates(map[string]any{
 		"status":       run.Status,
 		"finished_at":  run.FinishedAt,
 		"duration_sec": run.DurationSec,
@@ -185,7 +211,7 @@ func (r *GormRepo) ListTaskRuns(ctx context.Context, key string, limit int) ([]d
 		limit = 20
 	}
 	var rows []scheduledTaskRunRow
-	if err := r.gdb.WithContext(ctx).Where("task_key = ?", key).Order("id DESC").Limit(limit).Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Where("task_key = ?", key).Order("id DESC").Limit(limit).Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.ScheduledTaskRun, 0, len(rows))
@@ -206,7 +232,7 @@ func (r *GormRepo) ListTaskRuns(ctx context.Context, key string, limit int) ([]d
 }
 
 func (r *GormRepo) PurgeTaskRuns(ctx context.Context, before time.Time) error {
-	return r.gdb.WithContext(ctx).
+	return r.conn(ctx).
 		Where("created_at < ?", before).
 		Delete(&scheduledTaskRunRow{}).Error
 }
@@ -222,7 +248,7 @@ func (r *GormRepo) CreateResizeTask(ctx context.Context, task *domain.ResizeTask
 		StartedAt:   task.StartedAt,
 		FinishedAt:  task.FinishedAt,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	task.ID = row.ID
@@ -235,7 +261,7 @@ func (r *GormRepo) CreateResizeTask(ctx context.Context, task *domain.ResizeTask
 func (r *GormRepo) GetResizeTask(ctx context.Context, id int64) (domain.ResizeTask, error) {
 
 	var row resizeTaskRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.ResizeTask{}, r.ensure(err)
 	}
 	return domain.ResizeTask{
@@ -255,7 +281,7 @@ func (r *GormRepo) GetResizeTask(ctx context.Context, id int64) (domain.ResizeTa
 
 func (r *GormRepo) UpdateResizeTask(ctx context.Context, task domain.ResizeTask) error {
 
-	return r.gdb.WithContext(ctx).Model(&resizeTaskRow{}).Where("id = ?", task.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&resizeTaskRow{}).Where("id = ?", task.ID).Updates(map[string]any{
 		"status":       task.Status,
 		"scheduled_at": task.ScheduledAt,
 		"started_at":   task.StartedAt,
@@ -271,7 +297,7 @@ func (r *GormRepo) ListDueResizeTasks(ctx context.Context, limit int) ([]domain.
 		limit = 20
 	}
 	var rows []resizeTaskRow
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Where("status = ? AND (scheduled_at IS NULL OR scheduled_at <= CURRENT_TIMESTAMP)", domain.ResizeTaskStatusPending).
 		Order("scheduled_at ASC, id ASC").
 		Limit(limit).
@@ -302,7 +328,7 @@ func (r *GormRepo) HasPendingResizeTask(ctx context.Context, vpsID int64) (bool,
 		return false, nil
 	}
 	var total int64
-	if err := r.gdb.WithContext(ctx).Model(&resizeTaskRow{}).Where("vps_id = ? AND status IN ?", vpsID, []string{string(domain.ResizeTaskStatusPending), string(domain.ResizeTaskStatusRunning)}).Count(&total).Error; err != nil {
+	if err := r.conn(ctx).Model(&resizeTaskRow{}).Where("vps_id = ? AND status IN ?", vpsID, []string{string(domain.ResizeTaskStatusPending), string(domain.ResizeTaskStatusRunning)}).Count(&total).Error; err != nil {
 		return false, err
 	}
 	return total > 0, nil
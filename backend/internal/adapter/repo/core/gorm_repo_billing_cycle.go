@@ -10,7 +10,7 @@ import (
 func (r *GormRepo) ListBillingCycles(ctx context.Context) ([]domain.BillingCycle, error) {
 
 	var rows []billingCycleRow
-	if err := r.gdb.WithContext(ctx).Order("sort_order, id").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("sort_order, id").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.BillingCycle, 0, len(rows))
@@ -35,7 +35,7 @@ func (r *GormRepo) ListBillingCycles(ctx context.Context) ([]domain.BillingCycle
 func (r *GormRepo) GetBillingCycle(ctx context.Context, id int64) (domain.BillingCycle, error) {
 
 	var row billingCycleRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.BillingCycle{}, r.ensure(err)
 	}
 	return domain.BillingCycle{
@@ -64,7 +64,7 @@ func (r *GormRepo) CreateBillingCycle(ctx context.Context, cycle *domain.Billing
 		Active:     boolToInt(cycle.Active),
 		SortOrder:  cycle.SortOrder,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	cycle.ID = row.ID
@@ -74,7 +74,7 @@ func (r *GormRepo) CreateBillingCycle(ctx context.Context, cycle *domain.Billing
 
 func (r *GormRepo) UpdateBillingCycle(ctx context.Context, cycle domain.BillingCycle) error {
 
-	return r.gdb.WithContext(ctx).Model(&billingCycleRow{}).Where("id = ?", cycle.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&billingCycleRow{}).Where("id = ?", cycle.ID).Updates(map[string]any{
 		"name":       cycle.Name,
 		"months":     cycle.Months,
 		"multiplier": cycle.Multiplier,
@@ -89,6 +89,6 @@ func (r *GormRepo) UpdateBillingCycle(ctx context.Context, cycle domain.BillingC
 
 func (r *GormRepo) DeleteBillingCycle(ctx context.Context, id int64) error {
 
-	return r.gdb.WithContext(ctx).Delete(&billingCycleRow{}, id).Error
+	return r.conn(ctx).Delete(&billingCycleRow{}, id).Error
 
 }
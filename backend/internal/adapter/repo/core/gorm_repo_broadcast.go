@@ -0,0 +1,410 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"xiaoheiplay/internal/domain"
+)
+
+type broadcastSegmentJSON struct {
+	UserTierGroupIDs []int64    `json:"user_tier_group_ids,omitempty"`
+	GoodsTypeIDs     []int64    `json:"goods_type_ids,omitempty"`
+	RegionIDs        []int64    `json:"region_ids,omitempty"`
+	RealNameStatus   string     `json:"realname_status,omitempty"`
+	RegisteredFrom   *time.Time `json:"registered_from,omitempty"`
+	RegisteredTo     *time.Time `json:"registered_to,omitempty"`
+}
+
+func toBroadcastRow(b domain.Broadcast) broadcastRow {
+	channels, _ := json.Marshal(b.Channels)
+	segment, _ := json.Marshal(broadcastSegmentJSON(b.Segment))
+	return broadcastRow{
+		ID:           b.ID,
+		Title:        b.Title,
+		Content:      b.Content,
+		LinkURL:      b.LinkURL,
+		ChannelsJSON: string(channels),
+		SegmentJSON:  string(segment),
+		Status:       string(b.Status),
+		ScheduledAt:  b.ScheduledAt,
+		StartedAt:    b.StartedAt,
+		FinishedAt:   b.FinishedAt,
+		Total:        b.Total,
+		SentCount:    b.SentCount,
+		FailedCount:  b.FailedCount,
+		CreatedBy:    b.CreatedBy,
+	}
+}
+
+func fromBroadcastRow(row broadcastRow) domain.Broadcast {
+	var channels []string
+	_ = json.Unmarshal([]byte(row.ChannelsJSON), &channels)
+	var segment broadcastSegmentJSON
+	_ = json.Unmarshal([]byte(row.SegmentJSON), &segment)
+	return domain.Broadcast{
+		ID:          row.ID,
+		Title:       row.Title,
+		Content:     row.Content,
+		LinkURL:     row.LinkURL,
+		Channels:    channels,
+		Segment:     domain.BroadcastSegment(segment),
+		Status:      domain.BroadcastStatus(row.Status),
+		ScheduledAt: row.ScheduledAt,
+		StartedAt:   row.StartedAt,
+		FinishedAt:  row.FinishedAt,
+		Total:       row.Total,
+		SentCount:   row.SentCount,
+		FailedCount: row.FailedCount,
+		CreatedBy:   row.CreatedBy,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+}
+
+func fromBroadcastDeliveryRow(row broadcastDeliveryRow) domain.BroadcastDelivery {
+	return domain.BroadcastDelivery{
+		ID:             row.ID,
+		BroadcastID:    row.BroadcastID,
+		UserID:         row.UserID,
+		Channel:        row.Channel,
+		Status:         domain.BroadcastDeliveryStatus(row.Status),
+		Error:          row.Error,
+		Token:          row.Token,
+		NotificationID: row.NotificationID,
+		SentAt:         row.SentAt,
+		ClickedAt:      row.ClickedAt,
+		CreatedAt:      row.CreatedAt,
+	}
+}
+
+func (r *GormRepo) CreateBroadcast(ctx context.Context, broadcast *domain.Broadcast) error {
+	row := toBroadcastRow(*broadcast)
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	broadcast.ID = row.ID
+	broadcast.CreatedAt = row.CreatedAt
+	broadcast.UpdatedAt = row.UpdatedAt
+	return nil
+}
+
+func (r *GormRepo) GetBroadcast(ctx context.Context, id int64) (domain.Broadcast, error) {
+	var row broadcastRow
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+		return domain.Broadcast{}, r.ensure(err)
+	}
+	return fromBroadcastRow(row), nil
+}
+
+func (r *GormRepo) ListBroadcasts(ctx context.Context, status string, limit, offset int) ([]domain.Broadcast, int, error) {
+	q := r.conn(ctx).Model(&broadcastRow{})
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	var rows []broadcastRow
+	if err := q.Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	out := make([]domain.Broadcast, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromBroadcastRow(row))
+	}
+	return out, int(total), nil
+}
+
+// ListActiveBroadcasts returns broadcasts that are sending and scheduled
+// broadcasts whose time has come, oldest first.
+func (r *GormRepo) ListActiveBroadcasts(ctx context.Context, now time.Time, limit int) ([]domain.Broadcast, error) {
+	var rows []broadcastRow
+	err := r.conn(ctx).
+		Where("status = ? OR (status = ? AND scheduled_at <= ?)", domain.BroadcastStatusSending, domain.BroadcastStatusScheduled, now).
+		Order("scheduled_at ASC, id ASC").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.Broadcast, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromBroadcastRow(row))
+	}
+	return out, nil
+}
+
+func (r *GormRepo) StartBroadcast(ctx context.Context, id int64, total int, startedAt time.Time) (bool, error) {
+	res := r.conn(ctx).Model(&broadcastRow{}).
+		Where("id = ? AND status = ?", id, domain.BroadcastStatusScheduled).
+		Updates(map[string]any{"status": domain.BroadcastStatusSending, "total": total, "started_at": startedAt})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *GormRepo) FinishBroadcast(ctx context.Context, id int64, finishedAt time.Time) (bool, error) {
+	res := r.conn(ctx).Model(&broadcastRow{}).
+		Where("id = ? AND status = ?", id, domain.BroadcastStatusSending).
+		Updates(map[string]any{"status": domain.BroadcastStatusSent, "finished_at": finishedAt})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// CancelBroadcast stops a scheduled or sending broadcast and skips the
+// deliveries that have not gone out yet.
+func (r *GormRepo) CancelBroadcast(ctx context.Context, id int64, canceledAt time.Time) (bool, error) {
+	canceled := false
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&broadcastRow{}).
+			Where("id = ? AND status IN ?", id, []domain.BroadcastStatus{domain.BroadcastStatusScheduled, domain.BroadcastStatusSending}).
+			Updates(map[string]any{"status": domain.BroadcastStatusCanceled, "finished_at": canceledAt})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		canceled = true
+		return tx.Model(&broadcastDeliveryRow{}).
+			Where("broadcast_id = ? AND status = ?", id, domain.BroadcastDeliveryPending).
+			Updates(map[string]any{"status": domain.BroadcastDeliverySkipped, "error": "canceled"}).Error
+	})
+	return canceled, err
+}
+
+func (r *GormRepo) RefreshBroadcastCounters(ctx context.Context, id int64) error {
+	var counts []struct {
+		Status string
+		Total  int
+	}
+	if err := r.conn(ctx).Model(&broadcastDeliveryRow{}).
+		Select("status, COUNT(*) AS total").
+		Where("broadcast_id = ?", id).
+		Group("status").
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+	sent, failed := 0, 0
+	for _, c := range counts {
+		switch domain.BroadcastDeliveryStatus(c.Status) {
+		case domain.BroadcastDeliverySent:
+			sent = c.Total
+		case domain.BroadcastDeliveryFailed:
+			failed = c.Total
+		}
+	}
+	return r.conn(ctx).Model(&broadcastRow{}).Where("id = ?", id).
+		Updates(map[string]any{"sent_count": sent, "failed_count": failed}).Error
+}
+
+// broadcastRecipients selects active users matching the segment.
+func (r *GormRepo) broadcastRecipients(ctx context.Context, segment domain.BroadcastSegment) *gorm.DB {
+	q := r.conn(ctx).Model(&userRow{}).
+		Where("users.role = ? AND users.status = ?", domain.UserRoleUser, domain.UserStatusActive)
+	if len(segment.UserTierGroupIDs) > 0 {
+		q = q.Where("users.user_tier_group_id IN ?", segment.UserTierGroupIDs)
+	}
+	if len(segment.GoodsTypeIDs) > 0 || len(segment.RegionIDs) > 0 {
+		sub := r.gdb.Model(&vpsInstanceRow{}).Select("1").
+			Where("vps_instances.user_id = users.id").
+			Where("(vps_instances.expire_at IS NULL OR vps_instances.expire_at > ?)", time.Now())
+		if len(segment.GoodsTypeIDs) > 0 {
+			sub = sub.Where("vps_instances.goods_type_id IN ?", segment.GoodsTypeIDs)
+		}
+		if len(segment.RegionIDs) > 0 {
+			sub = sub.Where("vps_instances.region_id IN ?", segment.RegionIDs)
+		}
+		q = q.Where("EXISTS (?)", sub)
+	}
+	verified := r.gdb.Model(&realnameVerificationRow{}).Select("1").
+		Where("realname_verifications.user_id = users.id AND realname_verifications.status = ?", "verified")
+	switch segment.RealNameStatus {
+	case domain.BroadcastRealNameVerified:
+		q = q.Where("EXISTS (?)", verified)
+	case domain.BroadcastRealNameUnverified:
+		q = q.Where("NOT EXISTS (?)", verified)
+	}
+	if segment.RegisteredFrom != nil {
+		q = q.Where("users.created_at >= ?", *segment.RegisteredFrom)
+	}
+	if segment.RegisteredTo != nil {
+		q = q.Where("users.created_at < ?", *segment.RegisteredTo)
+	}
+	return q
+}
+
+func (r *GormRepo) ListBroadcastRecipients(ctx context.Context, segment domain.BroadcastSegment, afterUserID int64, limit int) ([]int64, error) {
+	var ids []int64
+	err := r.broadcastRecipients(ctx, segment).
+		Where("users.id > ?", afterUserID).
+		Order("users.id ASC").
+		Limit(limit).
+		Pluck("users.id", &ids).Error
+	return ids, err
+}
+
+func (r *GormRepo) CountBroadcastRecipients(ctx context.Context, segment domain.BroadcastSegment) (int, error) {
+	var total int64
+	if err := r.broadcastRecipients(ctx, segment).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return int(total), nil
+}
+
+// CreateBroadcastDeliveries inserts deliveries, ignoring recipients that
+// already have one on the channel so an interrupted fan-out can resume.
+func (r *GormRepo) CreateBroadcastDeliveries(ctx context.Context, deliveries []domain.BroadcastDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	rows := make([]broadcastDeliveryRow, 0, len(deliveries))
+	for _, d := range deliveries {
+		rows = append(rows, broadcastDeliveryRow{
+			BroadcastID: d.BroadcastID,
+			UserID:      d.UserID,
+			Channel:     d.Channel,
+			Status:      string(d.Status),
+			Token:       d.Token,
+		})
+	}
+	return r.conn(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rows, 200).Error
+}
+
+func (r *GormRepo) ListPendingBroadcastDeliveries(ctx context.Context, broadcastID int64, channel string, limit int) ([]domain.BroadcastDelivery, error) {
+	var rows []broadcastDeliveryRow
+	err := r.conn(ctx).
+		Where("broadcast_id = ? AND channel = ? AND status = ?", broadcastID, channel, domain.BroadcastDeliveryPending).
+		Order("id ASC").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.BroadcastDelivery, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromBroadcastDeliveryRow(row))
+	}
+	return out, nil
+}
+
+func (r *GormRepo) UpdateBroadcastDelivery(ctx context.Context, delivery domain.BroadcastDelivery) error {
+	return r.conn(ctx).Model(&broadcastDeliveryRow{}).Where("id = ?", delivery.ID).
+		Updates(map[string]any{
+			"status":          string(delivery.Status),
+			"error":           delivery.Error,
+			"notification_id": delivery.NotificationID,
+			"sent_at":         delivery.SentAt,
+		}).Error
+}
+
+func (r *GormRepo) ListBroadcastDeliveries(ctx context.Context, broadcastID int64, channel, status string, limit, offset int) ([]domain.BroadcastDelivery, int, error) {
+	q := r.conn(ctx).Model(&broadcastDeliveryRow{}).Where("broadcast_id = ?", broadcastID)
+	if channel != "" {
+		q = q.Where("channel = ?", channel)
+	}
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	var rows []broadcastDeliveryRow
+	if err := q.Order("id ASC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	out := make([]domain.BroadcastDelivery, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromBroadcastDeliveryRow(row))
+	}
+	return out, int(total), nil
+}
+
+func (r *GormRepo) GetBroadcastStats(ctx context.Context, broadcastID int64) (domain.BroadcastStats, error) {
+	var counts []struct {
+		Channel string
+		Status  string
+		Total   int
+		Clicked int
+	}
+	if err := r.conn(ctx).Model(&broadcastDeliveryRow{}).
+		Select("channel, status, COUNT(*) AS total, SUM(CASE WHEN clicked_at IS NULL THEN 0 ELSE 1 END) AS clicked").
+		Where("broadcast_id = ?", broadcastID).
+		Group("channel, status").
+		Scan(&counts).Error; err != nil {
+		return domain.BroadcastStats{}, err
+	}
+	stats := domain.BroadcastStats{}
+	index := map[string]int{}
+	for _, c := range counts {
+		i, ok := index[c.Channel]
+		if !ok {
+			i = len(stats.Channels)
+			index[c.Channel] = i
+			stats.Channels = append(stats.Channels, domain.BroadcastChannelStats{Channel: c.Channel})
+		}
+		item := &stats.Channels[i]
+		item.Total += c.Total
+		item.Clicked += c.Clicked
+		switch domain.BroadcastDeliveryStatus(c.Status) {
+		case domain.BroadcastDeliveryPending:
+			item.Pending += c.Total
+		case domain.BroadcastDeliverySent:
+			item.Sent += c.Total
+		case domain.BroadcastDeliveryFailed:
+			item.Failed += c.Total
+		case domain.BroadcastDeliverySkipped:
+			item.Skipped += c.Total
+		}
+	}
+	var read int64
+	if err := r.conn(ctx).Model(&broadcastDeliveryRow{}).
+		Joins("JOIN notifications ON notifications.id = broadcast_deliveries.notification_id").
+		Where("broadcast_deliveries.broadcast_id = ? AND notifications.read_at IS NOT NULL", broadcastID).
+		Count(&read).Error; err != nil {
+		return domain.BroadcastStats{}, err
+	}
+	stats.Read = int(read)
+	var clicked int64
+	if err := r.conn(ctx).Model(&broadcastDeliveryRow{}).
+		Where("broadcast_id = ? AND clicked_at IS NOT NULL", broadcastID).
+		Distinct("user_id").
+		Count(&clicked).Error; err != nil {
+		return domain.BroadcastStats{}, err
+	}
+	stats.Clicked = int(clicked)
+	return stats, nil
+}
+
+// MarkBroadcastDeliveryClicked records the first click on a tracked link.
+func (r *GormRepo) MarkBroadcastDeliveryClicked(ctx context.Context, token string, clickedAt time.Time) (domain.BroadcastDelivery, error) {
+	var row broadcastDeliveryRow
+	if err := r.conn(ctx).Where("token = ?", token).First(&row).Error; err != nil {
+		return domain.BroadcastDelivery{}, r.ensure(err)
+	}
+	if row.ClickedAt == nil {
+		if err := r.conn(ctx).Model(&broadcastDeliveryRow{}).
+			Where("id = ? AND clicked_at IS NULL", row.ID).
+			Update("clicked_at", clickedAt).Error; err != nil {
+			return domain.BroadcastDelivery{}, err
+		}
+		row.ClickedAt = &clickedAt
+	}
+	return fromBroadcastDeliveryRow(row), nil
+}
@@ -9,7 +9,7 @@ import (
 func (r *GormRepo) ListCartItems(ctx context.Context, userID int64) ([]domain.CartItem, error) {
 
 	var rows []cartItemRow
-	if err := r.gdb.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Where("user_id = ?", userID).Order("id DESC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.CartItem, 0, len(rows))
@@ -23,7 +23,7 @@ func (r *GormRepo) ListCartItems(ctx context.Context, userID int64) ([]domain.Ca
 func (r *GormRepo) AddCartItem(ctx context.Context, item *domain.CartItem) error {
 
 	row := toCartItemRow(*item)
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	*item = fromCartItemRow(row)
@@ -33,7 +33,7 @@ func (r *GormRepo) AddCartItem(ctx context.Context, item *domain.CartItem) error
 
 func (r *GormRepo) UpdateCartItem(ctx context.Context, item domain.CartItem) error {
 
-	return r.gdb.WithContext(ctx).Model(&cartItemRow{}).
+	return r.conn(ctx).Model(&cartItemRow{}).
 		Where("id = ? AND user_id = ?", item.ID, item.UserID).
 		Updates(map[string]any{"spec_json": item.SpecJSON, "qty": item.Qty, "amount": item.Amount, "updated_at": time.Now()}).Error
 
@@ -41,12 +41,12 @@ func (r *GormRepo) UpdateCartItem(ctx context.Context, item domain.CartItem) err
 
 func (r *GormRepo) DeleteCartItem(ctx context.Context, id int64, userID int64) error {
 
-	return r.gdb.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&cartItemRow{}).Error
+	return r.conn(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&cartItemRow{}).Error
 
 }
 
 func (r *GormRepo) ClearCart(ctx context.Context, userID int64) error {
 
-	return r.gdb.WithContext(ctx).Where("user_id = ?", userID).Delete(&cartItemRow{}).Error
+	return r.conn(ctx).Where("user_id = ?", userID).Delete(&cartItemRow{}).Error
 
 }
@@ -11,7 +11,7 @@ import (
 func (r *GormRepo) ListGoodsTypes(ctx context.Context) ([]domain.GoodsType, error) {
 
 	var rows []goodsTypeRow
-	if err := r.gdb.WithContext(ctx).Order("sort_order, id").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("sort_order, id").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.GoodsType, 0, len(rows))
@@ -36,7 +36,7 @@ func (r *GormRepo) ListGoodsTypes(ctx context.Context) ([]domain.GoodsType, erro
 func (r *GormRepo) GetGoodsType(ctx context.Context, id int64) (domain.GoodsType, error) {
 
 	var row goodsTypeRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.GoodsType{}, r.ensure(err)
 	}
 	return domain.GoodsType{
@@ -65,7 +65,7 @@ func (r *GormRepo) CreateGoodsType(ctx context.Context, gt *domain.GoodsType) er
 		AutomationPluginID:   gt.AutomationPluginID,
 		AutomationInstanceID: gt.AutomationInstanceID,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	gt.ID = row.ID
@@ -77,7 +77,7 @@ func (r *GormRepo) CreateGoodsType(ctx context.Context, gt *domain.GoodsType) er
 
 func (r *GormRepo) UpdateGoodsType(ctx context.Context, gt domain.GoodsType) error {
 
-	return r.gdb.WithContext(ctx).Model(&goodsTypeRow{}).Where("id = ?", gt.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&goodsTypeRow{}).Where("id = ?", gt.ID).Updates(map[string]any{
 		"code":                   strings.TrimSpace(gt.Code),
 		"name":                   gt.Name,
 		"active":                 boolToInt(gt.Active),
@@ -92,14 +92,14 @@ func (r *GormRepo) UpdateGoodsType(ctx context.Context, gt domain.GoodsType) err
 
 func (r *GormRepo) DeleteGoodsType(ctx context.Context, id int64) error {
 
-	return r.gdb.WithContext(ctx).Delete(&goodsTypeRow{}, id).Error
+	return r.conn(ctx).Delete(&goodsTypeRow{}, id).Error
 
 }
 
 func (r *GormRepo) ListRegions(ctx context.Context) ([]domain.Region, error) {
 
 	var rows []regionRow
-	if err := r.gdb.WithContext(ctx).Order("id").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("id").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.Region, 0, len(rows))
@@ -124,7 +124,7 @@ func (r *GormRepo) CreateRegion(ctx context.Context, region *domain.Region) erro
 		Name:        region.Name,
 		Active:      boolToInt(region.Active),
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	region.ID = row.ID
@@ -134,7 +134,7 @@ func (r *GormRepo) CreateRegion(ctx context.Context, region *domain.Region) erro
 
 func (r *GormRepo) UpdateRegion(ctx context.Context, region domain.Region) error {
 
-	return r.gdb.WithContext(ctx).Model(&regionRow{}).Where("id = ?", region.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&regionRow{}).Where("id = ?", region.ID).Updates(map[string]any{
 		"goods_type_id": region.GoodsTypeID,
 		"code":          region.Code,
 		"name":          region.Name,
@@ -146,14 +146,14 @@ func (r *GormRepo) UpdateRegion(ctx context.Context, region domain.Region) error
 
 func (r *GormRepo) DeleteRegion(ctx context.Context, id int64) error {
 
-	return r.gdb.WithContext(ctx).Delete(&regionRow{}, id).Error
+	return r.conn(ctx).Delete(&regionRow{}, id).Error
 
 }
 
 func (r *GormRepo) ListPlanGroups(ctx context.Context) ([]domain.PlanGroup, error) {
 
 	var rows []planGroupRow
-	if err := r.gdb.WithContext(ctx).Order("sort_order, id").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("sort_order, id").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.PlanGroup, 0, len(rows))
@@ -183,6 +183,9 @@ func (r *GormRepo) ListPlanGroups(ctx context.Context) ([]domain.PlanGroup, erro
 			Active:            row.Active == 1,
 			Visible:           row.Visible == 1,
 			CapacityRemaining: row.CapacityRemaining,
+			CapacityReserved:  row.CapacityReserved,
+			SnapshotQuota:     row.SnapshotQuota,
+			BackupQuota:       row.BackupQuota,
 			SortOrder:         row.SortOrder,
 		})
 	}
@@ -216,9 +219,11 @@ func (r *GormRepo) CreatePlanGroup(ctx context.Context, plan *domain.PlanGroup)
 		Active:            boolToInt(plan.Active),
 		Visible:           boolToInt(plan.Visible),
 		CapacityRemaining: plan.CapacityRemaining,
+		SnapshotQuota:     plan.SnapshotQuota,
+		BackupQuota:       plan.BackupQuota,
 		SortOrder:         plan.SortOrder,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	plan.ID = row.ID
@@ -228,7 +233,7 @@ func (r *GormRepo) CreatePlanGroup(ctx context.Context, plan *domain.PlanGroup)
 
 func (r *GormRepo) UpdatePlanGroup(ctx context.Context, plan domain.PlanGroup) error {
 
-	return r.gdb.WithContext(ctx).Model(&planGroupRow{}).Where("id = ?", plan.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&planGroupRow{}).Where("id = ?", plan.ID).Updates(map[string]any{
 		"goods_type_id":      plan.GoodsTypeID,
 		"region_id":          plan.RegionID,
 		"name":               plan.Name,
@@ -252,6 +257,8 @@ func (r *GormRepo) UpdatePlanGroup(ctx context.Context, plan domain.PlanGroup) e
 		"active":             boolToInt(plan.Active),
 		"visible":            boolToInt(plan.Visible),
 		"capacity_remaining": plan.CapacityRemaining,
+		"snapshot_quota":     plan.SnapshotQuota,
+		"backup_quota":       plan.BackupQuota,
 		"sort_order":         plan.SortOrder,
 		"updated_at":         time.Now(),
 	}).Error
@@ -260,14 +267,14 @@ func (r *GormRepo) UpdatePlanGroup(ctx context.Context, plan domain.PlanGroup) e
 
 func (r *GormRepo) DeletePlanGroup(ctx context.Context, id int64) error {
 
-	return r.gdb.WithContext(ctx).Delete(&planGroupRow{}, id).Error
+	return r.conn(ctx).Delete(&planGroupRow{}, id).Error
 
 }
 
 func (r *GormRepo) ListPackages(ctx context.Context) ([]domain.Package, error) {
 
 	var rows []packageRow
-	if err := r.gdb.WithContext(ctx).Order("sort_order, id").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("sort_order, id").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.Package, 0, len(rows))
@@ -290,6 +297,8 @@ func (r *GormRepo) ListPackages(ctx context.Context) ([]domain.Package, error) {
 			Active:               row.Active == 1,
 			Visible:              row.Visible == 1,
 			CapacityRemaining:    row.CapacityRemaining,
+			CapacityReserved:     row.CapacityReserved,
+			TrialDays:            row.TrialDays,
 		})
 	}
 	return out, nil
@@ -315,8 +324,9 @@ func (r *GormRepo) CreatePackage(ctx context.Context, pkg *domain.Package) error
 		Active:               boolToInt(pkg.Active),
 		Visible:              boolToInt(pkg.Visible),
 		CapacityRemaining:    pkg.CapacityRemaining,
+		TrialDays:            pkg.TrialDays,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	pkg.ID = row.ID
@@ -326,7 +336,7 @@ func (r *GormRepo) CreatePackage(ctx context.Context, pkg *domain.Package) error
 
 func (r *GormRepo) UpdatePackage(ctx context.Context, pkg domain.Package) error {
 
-	return r.gdb.WithContext(ctx).Model(&packageRow{}).Where("id = ?", pkg.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&packageRow{}).Where("id = ?", pkg.ID).Updates(map[string]any{
 		"goods_type_id":          pkg.GoodsTypeID,
 		"plan_group_id":          pkg.PlanGroupID,
 		"product_id":             pkg.ProductID,
@@ -343,6 +353,7 @@ func (r *GormRepo) UpdatePackage(ctx context.Context, pkg domain.Package) error
 		"active":                 boolToInt(pkg.Active),
 		"visible":                boolToInt(pkg.Visible),
 		"capacity_remaining":     pkg.CapacityRemaining,
+		"trial_days":             pkg.TrialDays,
 		"updated_at":             time.Now(),
 	}).Error
 
@@ -350,14 +361,14 @@ func (r *GormRepo) UpdatePackage(ctx context.Context, pkg domain.Package) error
 
 func (r *GormRepo) DeletePackage(ctx context.Context, id int64) error {
 
-	return r.gdb.WithContext(ctx).Delete(&packageRow{}, id).Error
+	return r.conn(ctx).Delete(&packageRow{}, id).Error
 
 }
 
 func (r *GormRepo) GetPackage(ctx context.Context, id int64) (domain.Package, error) {
 
 	var row packageRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.Package{}, r.ensure(err)
 	}
 	return domain.Package{
@@ -378,6 +389,8 @@ func (r *GormRepo) GetPackage(ctx context.Context, id int64) (domain.Package, er
 		Active:               row.Active == 1,
 		Visible:              row.Visible == 1,
 		CapacityRemaining:    row.CapacityRemaining,
+		CapacityReserved:     row.CapacityReserved,
+		TrialDays:            row.TrialDays,
 	}, nil
 
 }
@@ -385,7 +398,7 @@ func (r *GormRepo) GetPackage(ctx context.Context, id int64) (domain.Package, er
 func (r *GormRepo) GetPlanGroup(ctx context.Context, id int64) (domain.PlanGroup, error) {
 
 	var row planGroupRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.PlanGroup{}, r.ensure(err)
 	}
 	return domain.PlanGroup{
@@ -413,6 +426,9 @@ func (r *GormRepo) GetPlanGroup(ctx context.Context, id int64) (domain.PlanGroup
 		Active:            row.Active == 1,
 		Visible:           row.Visible == 1,
 		CapacityRemaining: row.CapacityRemaining,
+		CapacityReserved:  row.CapacityReserved,
+		SnapshotQuota:     row.SnapshotQuota,
+		BackupQuota:       row.BackupQuota,
 		SortOrder:         row.SortOrder,
 	}, nil
 
@@ -421,7 +437,7 @@ func (r *GormRepo) GetPlanGroup(ctx context.Context, id int64) (domain.PlanGroup
 func (r *GormRepo) GetRegion(ctx context.Context, id int64) (domain.Region, error) {
 
 	var row regionRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.Region{}, r.ensure(err)
 	}
 	return domain.Region{
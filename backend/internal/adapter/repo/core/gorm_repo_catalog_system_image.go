@@ -12,7 +12,7 @@ import (
 func (r *GormRepo) ListSystemImages(ctx context.Context, lineID int64) ([]domain.SystemImage, error) {
 
 	var rows []systemImageRow
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Table("system_images si").
 		Select("si.id, si.image_id, si.name, si.type, si.enabled, si.created_at, si.updated_at").
 		Joins("JOIN line_system_images lsi ON lsi.system_image_id = si.id").
@@ -40,7 +40,7 @@ func (r *GormRepo) ListSystemImages(ctx context.Context, lineID int64) ([]domain
 func (r *GormRepo) ListAllSystemImages(ctx context.Context) ([]domain.SystemImage, error) {
 
 	var rows []systemImageRow
-	if err := r.gdb.WithContext(ctx).Order("id DESC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("id DESC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.SystemImage, 0, len(rows))
@@ -62,7 +62,7 @@ func (r *GormRepo) ListAllSystemImages(ctx context.Context) ([]domain.SystemImag
 func (r *GormRepo) GetSystemImage(ctx context.Context, id int64) (domain.SystemImage, error) {
 
 	var row systemImageRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.SystemImage{}, r.ensure(err)
 	}
 	return domain.SystemImage{
@@ -85,7 +85,7 @@ func (r *GormRepo) CreateSystemImage(ctx context.Context, img *domain.SystemImag
 		Type:    img.Type,
 		Enabled: boolToInt(img.Enabled),
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	img.ID = row.ID
@@ -97,7 +97,7 @@ func (r *GormRepo) CreateSystemImage(ctx context.Context, img *domain.SystemImag
 
 func (r *GormRepo) UpdateSystemImage(ctx context.Context, img domain.SystemImage) error {
 
-	return r.gdb.WithContext(ctx).Model(&systemImageRow{}).Where("id = ?", img.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&systemImageRow{}).Where("id = ?", img.ID).Updates(map[string]any{
 		"image_id":   img.ImageID,
 		"name":       img.Name,
 		"type":       img.Type,
@@ -109,7 +109,7 @@ func (r *GormRepo) UpdateSystemImage(ctx context.Context, img domain.SystemImage
 
 func (r *GormRepo) DeleteSystemImage(ctx context.Context, id int64) error {
 
-	return r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Where("system_image_id = ?", id).Delete(&lineSystemImageRow{}).Error; err != nil {
 			return err
 		}
@@ -120,7 +120,7 @@ func (r *GormRepo) DeleteSystemImage(ctx context.Context, id int64) error {
 
 func (r *GormRepo) SetLineSystemImages(ctx context.Context, lineID int64, systemImageIDs []int64) error {
 
-	return r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Where("line_id = ?", lineID).Delete(&lineSystemImageRow{}).Error; err != nil {
 			return err
 		}
@@ -159,7 +159,7 @@ func (r *GormRepo) ListLineIDsBySystemImageIDs(ctx context.Context, systemImageI
 		SystemImageID int64 `gorm:"column:system_image_id"`
 	}
 	var rows []joinRow
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Table("line_system_images").
 		Select("line_id, system_image_id").
 		Where("system_image_id IN ?", systemImageIDs).
@@ -9,7 +9,7 @@ import (
 )
 
 func (r *GormRepo) ListCMSCategories(ctx context.Context, lang string, includeHidden bool) ([]domain.CMSCategory, error) {
-	q := r.gdb.WithContext(ctx).Model(&cmsCategoryRow{})
+	q := r.conn(ctx).Model(&cmsCategoryRow{})
 	if lang != "" {
 		q = q.Where("lang = ?", lang)
 	}
@@ -38,7 +38,7 @@ func (r *GormRepo) ListCMSCategories(ctx context.Context, lang string, includeHi
 
 func (r *GormRepo) GetCMSCategory(ctx context.Context, id int64) (domain.CMSCategory, error) {
 	var row cmsCategoryRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.CMSCategory{}, r.ensure(err)
 	}
 	return domain.CMSCategory{
@@ -55,7 +55,7 @@ func (r *GormRepo) GetCMSCategory(ctx context.Context, id int64) (domain.CMSCate
 
 func (r *GormRepo) GetCMSCategoryByKey(ctx context.Context, key, lang string) (domain.CMSCategory, error) {
 	var row cmsCategoryRow
-	if err := r.gdb.WithContext(ctx).Where("`key` = ? AND lang = ?", key, lang).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("`key` = ? AND lang = ?", key, lang).First(&row).Error; err != nil {
 		return domain.CMSCategory{}, r.ensure(err)
 	}
 	return domain.CMSCategory{
@@ -78,7 +78,7 @@ func (r *GormRepo) CreateCMSCategory(ctx context.Context, category *domain.CMSCa
 		SortOrder: category.SortOrder,
 		Visible:   boolToInt(category.Visible),
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	category.ID = row.ID
@@ -88,7 +88,7 @@ func (r *GormRepo) CreateCMSCategory(ctx context.Context, category *domain.CMSCa
 }
 
 func (r *GormRepo) UpdateCMSCategory(ctx context.Context, category domain.CMSCategory) error {
-	return r.gdb.WithContext(ctx).Model(&cmsCategoryRow{}).Where("id = ?", category.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&cmsCategoryRow{}).Where("id = ?", category.ID).Updates(map[string]any{
 		"key":        category.Key,
 		"name":       category.Name,
 		"lang":       category.Lang,
@@ -99,11 +99,11 @@ func (r *GormRepo) UpdateCMSCategory(ctx context.Context, category domain.CMSCat
 }
 
 func (r *GormRepo) DeleteCMSCategory(ctx context.Context, id int64) error {
-	return r.gdb.WithContext(ctx).Delete(&cmsCategoryRow{}, id).Error
+	return r.conn(ctx).Delete(&cmsCategoryRow{}, id).Error
 }
 
 func (r *GormRepo) ListCMSPosts(ctx context.Context, filter appshared.CMSPostFilter) ([]domain.CMSPost, int, error) {
-	q := r.gdb.WithContext(ctx).Model(&cmsPostRow{})
+	q := r.conn(ctx).Model(&cmsPostRow{})
 	if filter.CategoryID != nil {
 		q = q.Where("cms_posts.category_id = ?", *filter.CategoryID)
 	}
@@ -159,7 +159,7 @@ func (r *GormRepo) ListCMSPosts(ctx context.Context, filter appshared.CMSPostFil
 
 func (r *GormRepo) GetCMSPost(ctx context.Context, id int64) (domain.CMSPost, error) {
 	var row cmsPostRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.CMSPost{}, r.ensure(err)
 	}
 	return domain.CMSPost{
@@ -182,7 +182,7 @@ func (r *GormRepo) GetCMSPost(ctx context.Context, id int64) (domain.CMSPost, er
 
 func (r *GormRepo) GetCMSPostBySlug(ctx context.Context, slug string) (domain.CMSPost, error) {
 	var row cmsPostRow
-	if err := r.gdb.WithContext(ctx).Where("slug = ?", slug).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("slug = ?", slug).First(&row).Error; err != nil {
 		return domain.CMSPost{}, r.ensure(err)
 	}
 	return domain.CMSPost{
@@ -222,7 +222,7 @@ func (r *GormRepo) CreateCMSPost(ctx context.Context, post *domain.CMSPost) erro
 		SortOrder:   post.SortOrder,
 		PublishedAt: publishedAt,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	post.ID = row.ID
@@ -238,7 +238,7 @@ func (r *GormRepo) UpdateCMSPost(ctx context.Context, post domain.CMSPost) error
 		utc := post.PublishedAt.UTC()
 		publishedAt = &utc
 	}
-	return r.gdb.WithContext(ctx).Model(&cmsPostRow{}).Where("id = ?", post.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&cmsPostRow{}).Where("id = ?", post.ID).Updates(map[string]any{
 		"category_id":  post.CategoryID,
 		"title":        post.Title,
 		"slug":         post.Slug,
@@ -255,11 +255,11 @@ func (r *GormRepo) UpdateCMSPost(ctx context.Context, post domain.CMSPost) error
 }
 
 func (r *GormRepo) DeleteCMSPost(ctx context.Context, id int64) error {
-	return r.gdb.WithContext(ctx).Delete(&cmsPostRow{}, id).Error
+	return r.conn(ctx).Delete(&cmsPostRow{}, id).Error
 }
 
 func (r *GormRepo) ListCMSBlocks(ctx context.Context, page, lang string, includeHidden bool) ([]domain.CMSBlock, error) {
-	q := r.gdb.WithContext(ctx).Model(&cmsBlockRow{})
+	q := r.conn(ctx).Model(&cmsBlockRow{})
 	if page != "" {
 		q = q.Where("page = ?", page)
 	}
@@ -295,7 +295,7 @@ func (r *GormRepo) ListCMSBlocks(ctx context.Context, page, lang string, include
 
 func (r *GormRepo) GetCMSBlock(ctx context.Context, id int64) (domain.CMSBlock, error) {
 	var row cmsBlockRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.CMSBlock{}, r.ensure(err)
 	}
 	return domain.CMSBlock{
@@ -326,7 +326,7 @@ func (r *GormRepo) CreateCMSBlock(ctx context.Context, block *domain.CMSBlock) e
 		Visible:     boolToInt(block.Visible),
 		SortOrder:   block.SortOrder,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	block.ID = row.ID
@@ -336,7 +336,7 @@ func (r *GormRepo) CreateCMSBlock(ctx context.Context, block *domain.CMSBlock) e
 }
 
 func (r *GormRepo) UpdateCMSBlock(ctx context.Context, block domain.CMSBlock) error {
-	return r.gdb.WithContext(ctx).Model(&cmsBlockRow{}).Where("id = ?", block.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&cmsBlockRow{}).Where("id = ?", block.ID).Updates(map[string]any{
 		"page":         block.Page,
 		"type":         block.Type,
 		"title":        block.Title,
@@ -351,5 +351,5 @@ func (r *GormRepo) UpdateCMSBlock(ctx context.Context, block domain.CMSBlock) er
 }
 
 func (r *GormRepo) DeleteCMSBlock(ctx context.Context, id int64) error {
-	return r.gdb.WithContext(ctx).Delete(&cmsBlockRow{}, id).Error
+	return r.conn(ctx).Delete(&cmsBlockRow{}, id).Error
 }
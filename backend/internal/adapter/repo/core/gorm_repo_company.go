@@ -0,0 +1,147 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"xiaoheiplay/internal/domain"
+)
+
+func (r *GormRepo) fromCompanyRow(row companyVerificationRow) (domain.CompanyVerification, error) {
+	idNumber, err := r.openIDNumber(row.LegalPersonIDNumber)
+	if err != nil {
+		return domain.CompanyVerification{}, err
+	}
+	var uploadIDs []int64
+	if row.UploadIDs != "" {
+		_ = json.Unmarshal([]byte(row.UploadIDs), &uploadIDs)
+	}
+	return domain.CompanyVerification{
+		ID:                  row.ID,
+		UserID:              row.UserID,
+		CompanyName:         row.CompanyName,
+		CreditCode:          row.CreditCode,
+		LegalPersonName:     row.LegalPersonName,
+		LegalPersonIDNumber: idNumber,
+		InvoiceTitle:        row.InvoiceTitle,
+		TaxNumber:           row.TaxNumber,
+		InvoiceAddress:      row.InvoiceAddress,
+		InvoicePhone:        row.InvoicePhone,
+		InvoiceBank:         row.InvoiceBank,
+		InvoiceBankAccount:  row.InvoiceBankAccount,
+		UploadIDs:           uploadIDs,
+		Status:              row.Status,
+		Provider:            row.Provider,
+		Reason:              row.Reason,
+		ReviewedBy:          row.ReviewedBy,
+		ReviewedAt:          row.ReviewedAt,
+		CreatedAt:           row.CreatedAt,
+		VerifiedAt:          row.VerifiedAt,
+	}, nil
+}
+
+func (r *GormRepo) CreateCompanyVerification(ctx context.Context, record *domain.CompanyVerification) error {
+	idNumber, err := r.sealIDNumber(record.LegalPersonIDNumber)
+	if err != nil {
+		return err
+	}
+	uploadIDs := ""
+	if len(record.UploadIDs) > 0 {
+		raw, _ := json.Marshal(record.UploadIDs)
+		uploadIDs = string(raw)
+	}
+	row := companyVerificationRow{
+		UserID:              record.UserID,
+		CompanyName:         record.CompanyName,
+		CreditCode:          record.CreditCode,
+		LegalPersonName:     record.LegalPersonName,
+		LegalPersonIDNumber: idNumber,
+		InvoiceTitle:        record.InvoiceTitle,
+		TaxNumber:           record.TaxNumber,
+		InvoiceAddress:      record.InvoiceAddress,
+		InvoicePhone:        record.InvoicePhone,
+		InvoiceBank:         record.InvoiceBank,
+		InvoiceBankAccount:  record.InvoiceBankAccount,
+		UploadIDs:           uploadIDs,
+		Status:              record.Status,
+		Provider:            record.Provider,
+		Reason:              record.Reason,
+		VerifiedAt:          record.VerifiedAt,
+	}
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	record.ID = row.ID
+	record.CreatedAt = row.CreatedAt
+	return nil
+}
+
+func (r *GormRepo) GetCompanyVerification(ctx context.Context, id int64) (domain.CompanyVerification, error) {
+	var row companyVerificationRow
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+		return domain.CompanyVerification{}, r.ensure(err)
+	}
+	return r.fromCompanyRow(row)
+}
+
+func (r *GormRepo) GetLatestCompanyVerification(ctx context.Context, userID int64) (domain.CompanyVerification, error) {
+	var row companyVerificationRow
+	if err := r.conn(ctx).Where("user_id = ?", userID).Order("id DESC").Limit(1).First(&row).Error; err != nil {
+		return domain.CompanyVerification{}, r.ensure(err)
+	}
+	return r.fromCompanyRow(row)
+}
+
+func (r *GormRepo) ListCompanyVerifications(ctx context.Context, userID *int64, status string, limit, offset int) ([]domain.CompanyVerification, int, error) {
+	q := r.conn(ctx).Model(&companyVerificationRow{})
+	if userID != nil {
+		q = q.Where("user_id = ?", *userID)
+	}
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	order := "id DESC"
+	if status == domain.RealNameStatusPendingReview {
+		order = "id ASC"
+	}
+	var rows []companyVerificationRow
+	if err := q.Order(order).Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	out := make([]domain.CompanyVerification, 0, len(rows))
+	for _, row := range rows {
+		item, err := r.fromCompanyRow(row)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, item)
+	}
+	return out, int(total), nil
+}
+
+func (r *GormRepo) ReviewCompanyVerification(ctx context.Context, id int64, status, reason string, reviewerID int64, reviewedAt time.Time) (bool, error) {
+	updates := map[string]any{
+		"status":      status,
+		"reason":      reason,
+		"reviewed_by": reviewerID,
+		"reviewed_at": reviewedAt,
+	}
+	if status == "verified" {
+		updates["verified_at"] = reviewedAt
+	}
+	res := r.conn(ctx).Model(&companyVerificationRow{}).
+		Where("id = ? AND status = ?", id, domain.RealNameStatusPendingReview).
+		Updates(updates)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
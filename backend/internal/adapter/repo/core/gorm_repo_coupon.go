@@ -12,7 +12,7 @@ import (
 
 func (r *GormRepo) ListCouponProductGroups(ctx context.Context) ([]domain.CouponProductGroup, error) {
 	var rows []couponProductGroupRow
-	if err := r.gdb.WithContext(ctx).Order("id DESC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("id DESC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.CouponProductGroup, 0, len(rows))
@@ -39,7 +39,7 @@ func (r *GormRepo) ListCouponProductGroups(ctx context.Context) ([]domain.Coupon
 
 func (r *GormRepo) GetCouponProductGroup(ctx context.Context, id int64) (domain.CouponProductGroup, error) {
 	var row couponProductGroupRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.CouponProductGroup{}, r.ensure(err)
 	}
 	return domain.CouponProductGroup{
@@ -74,7 +74,7 @@ func (r *GormRepo) CreateCouponProductGroup(ctx context.Context, group *domain.C
 		AddonDiskGB: group.AddonDiskGB,
 		AddonBWMbps: group.AddonBWMbps,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	group.ID = row.ID
@@ -84,7 +84,7 @@ func (r *GormRepo) CreateCouponProductGroup(ctx context.Context, group *domain.C
 }
 
 func (r *GormRepo) UpdateCouponProductGroup(ctx context.Context, group domain.CouponProductGroup) error {
-	return r.gdb.WithContext(ctx).Model(&couponProductGroupRow{}).Where("id = ?", group.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&couponProductGroupRow{}).Where("id = ?", group.ID).Updates(map[string]any{
 		"name":          group.Name,
 		"rules_json":    normalizeCouponGroupRulesJSON(group.RulesJSON, string(group.Scope), group.GoodsTypeID, group.RegionID, group.PlanGroupID, group.PackageID, group.AddonCore, group.AddonMemGB, group.AddonDiskGB, group.AddonBWMbps),
 		"scope":         string(group.Scope),
@@ -101,11 +101,11 @@ func (r *GormRepo) UpdateCouponProductGroup(ctx context.Context, group domain.Co
 }
 
 func (r *GormRepo) DeleteCouponProductGroup(ctx context.Context, id int64) error {
-	return r.gdb.WithContext(ctx).Delete(&couponProductGroupRow{}, "id = ?", id).Error
+	return r.conn(ctx).Delete(&couponProductGroupRow{}, "id = ?", id).Error
 }
 
 func (r *GormRepo) ListCoupons(ctx context.Context, filter appshared.CouponFilter, limit, offset int) ([]domain.Coupon, int, error) {
-	q := r.gdb.WithContext(ctx).Model(&couponRow{})
+	q := r.conn(ctx).Model(&couponRow{})
 	if v := strings.TrimSpace(filter.Keyword); v != "" {
 		like := "%" + strings.ToUpper(v) + "%"
 		q = q.Where("UPPER(code) LIKE ? OR note LIKE ?", like, "%"+v+"%")
@@ -133,7 +133,7 @@ func (r *GormRepo) ListCoupons(ctx context.Context, filter appshared.CouponFilte
 
 func (r *GormRepo) GetCoupon(ctx context.Context, id int64) (domain.Coupon, error) {
 	var row couponRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.Coupon{}, r.ensure(err)
 	}
 	return couponFromRow(row), nil
@@ -141,7 +141,7 @@ func (r *GormRepo) GetCoupon(ctx context.Context, id int64) (domain.Coupon, erro
 
 func (r *GormRepo) GetCouponByCode(ctx context.Context, code string) (domain.Coupon, error) {
 	var row couponRow
-	if err := r.gdb.WithContext(ctx).Where("code = ?", strings.ToUpper(strings.TrimSpace(code))).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("code = ?", strings.ToUpper(strings.TrimSpace(code))).First(&row).Error; err != nil {
 		return domain.Coupon{}, r.ensure(err)
 	}
 	return couponFromRow(row), nil
@@ -160,7 +160,7 @@ func (r *GormRepo) CreateCoupon(ctx context.Context, coupon *domain.Coupon) erro
 		Active:           boolToInt(coupon.Active),
 		Note:             coupon.Note,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	*coupon = couponFromRow(row)
@@ -168,7 +168,7 @@ func (r *GormRepo) CreateCoupon(ctx context.Context, coupon *domain.Coupon) erro
 }
 
 func (r *GormRepo) UpdateCoupon(ctx context.Context, coupon domain.Coupon) error {
-	return r.gdb.WithContext(ctx).Model(&couponRow{}).Where("id = ?", coupon.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&couponRow{}).Where("id = ?", coupon.ID).Updates(map[string]any{
 		"code":              strings.ToUpper(strings.TrimSpace(coupon.Code)),
 		"discount_permille": coupon.DiscountPermille,
 		"product_group_id":  coupon.ProductGroupID,
@@ -184,11 +184,11 @@ func (r *GormRepo) UpdateCoupon(ctx context.Context, coupon domain.Coupon) error
 }
 
 func (r *GormRepo) DeleteCoupon(ctx context.Context, id int64) error {
-	return r.gdb.WithContext(ctx).Delete(&couponRow{}, "id = ?", id).Error
+	return r.conn(ctx).Delete(&couponRow{}, "id = ?", id).Error
 }
 
 func (r *GormRepo) CountCouponRedemptions(ctx context.Context, couponID int64, userID *int64, statuses []string) (int64, error) {
-	q := r.gdb.WithContext(ctx).Model(&couponRedemptionRow{}).Where("coupon_id = ?", couponID)
+	q := r.conn(ctx).Model(&couponRedemptionRow{}).Where("coupon_id = ?", couponID)
 	if userID != nil && *userID > 0 {
 		q = q.Where("user_id = ?", *userID)
 	}
@@ -210,7 +210,7 @@ func (r *GormRepo) CreateCouponRedemption(ctx context.Context, redemption *domai
 		Status:         redemption.Status,
 		DiscountAmount: redemption.DiscountAmount,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	redemption.ID = row.ID
@@ -220,7 +220,7 @@ func (r *GormRepo) CreateCouponRedemption(ctx context.Context, redemption *domai
 }
 
 func (r *GormRepo) UpdateCouponRedemptionStatusByOrder(ctx context.Context, orderID int64, fromStatuses []string, toStatus string) error {
-	q := r.gdb.WithContext(ctx).Model(&couponRedemptionRow{}).Where("order_id = ?", orderID)
+	q := r.conn(ctx).Model(&couponRedemptionRow{}).Where("order_id = ?", orderID)
 	if len(fromStatuses) > 0 {
 		q = q.Where("status IN ?", fromStatuses)
 	}
@@ -232,7 +232,7 @@ func (r *GormRepo) UpdateCouponRedemptionStatusByOrder(ctx context.Context, orde
 
 func (r *GormRepo) CountUserSuccessfulOrders(ctx context.Context, userID int64) (int64, error) {
 	var total int64
-	if err := r.gdb.WithContext(ctx).Model(&orderRow{}).
+	if err := r.conn(ctx).Model(&orderRow{}).
 		Where("user_id = ? AND status IN ?", userID, []string{
 			string(domain.OrderStatusApproved),
 			string(domain.OrderStatusProvisioning),
@@ -0,0 +1,229 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+func (r *GormRepo) ListDunningPolicies(ctx context.Context) ([]domain.DunningPolicy, error) {
+	var rows []dunningPolicyRow
+	if err := r.conn(ctx).Order("goods_type_id ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.DunningPolicy, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromDunningPolicyRow(row))
+	}
+	return out, nil
+}
+
+func (r *GormRepo) GetDunningPolicy(ctx context.Context, goodsTypeID int64) (domain.DunningPolicy, error) {
+	var row dunningPolicyRow
+	if err := r.conn(ctx).Where("goods_type_id = ?", goodsTypeID).First(&row).Error; err != nil {
+		return domain.DunningPolicy{}, r.ensure(err)
+	}
+	return fromDunningPolicyRow(row), nil
+}
+
+func (r *GormRepo) UpsertDunningPolicy(ctx context.Context, policy *domain.DunningPolicy) error {
+	steps, _ := json.Marshal(toDunningStepsJSON(policy.Steps))
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		var row dunningPolicyRow
+		err := tx.Where("goods_type_id = ?", policy.GoodsTypeID).First(&row).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			row = dunningPolicyRow{
+				GoodsTypeID: policy.GoodsTypeID,
+				Enabled:     boolToInt(policy.Enabled),
+				StepsJSON:   string(steps),
+				UpdatedBy:   policy.UpdatedBy,
+			}
+			if err := tx.Create(&row).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			row.Enabled = boolToInt(policy.Enabled)
+			row.StepsJSON = string(steps)
+			row.UpdatedBy = policy.UpdatedBy
+			row.UpdatedAt = time.Now()
+			if err := tx.Save(&row).Error; err != nil {
+				return err
+			}
+		}
+		*policy = fromDunningPolicyRow(row)
+		return nil
+	})
+}
+
+func (r *GormRepo) DeleteDunningPolicy(ctx context.Context, goodsTypeID int64) error {
+	res := r.conn(ctx).Where("goods_type_id = ?", goodsTypeID).Delete(&dunningPolicyRow{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return appshared.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormRepo) ListDunningCycleRecords(ctx context.Context, instanceID int64, cycleExpireAt time.Time) ([]domain.DunningRecord, error) {
+	var rows []dunningRecordRow
+	if err := r.conn(ctx).
+		Where("instance_id = ? AND cycle_expire_at = ?", instanceID, cycleExpireAt).
+		Order("id ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.DunningRecord, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromDunningRecordRow(row))
+	}
+	return out, nil
+}
+
+func (r *GormRepo) SaveDunningRecord(ctx context.Context, record *domain.DunningRecord) error {
+	channels, _ := json.Marshal(record.Channels)
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		var row dunningRecordRow
+		err := tx.Where("instance_id = ? AND cycle_expire_at = ? AND step_key = ?", record.InstanceID, record.CycleExpireAt, record.StepKey).First(&row).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			row = dunningRecordRow{
+				InstanceID:    record.InstanceID,
+				UserID:        record.UserID,
+				GoodsTypeID:   record.GoodsTypeID,
+				CycleExpireAt: record.CycleExpireAt,
+				StepKey:       record.StepKey,
+				Action:        string(record.Action),
+				OffsetDays:    record.OffsetDays,
+				Status:        string(record.Status),
+				ChannelsJSON:  string(channels),
+				ErrorMessage:  record.Error,
+				Attempts:      1,
+				ExecutedAt:    record.ExecutedAt,
+			}
+			if err := tx.Create(&row).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			row.Status = string(record.Status)
+			row.ChannelsJSON = string(channels)
+			row.ErrorMessage = record.Error
+			row.Attempts++
+			row.ExecutedAt = record.ExecutedAt
+			if err := tx.Save(&row).Error; err != nil {
+				return err
+			}
+		}
+		*record = fromDunningRecordRow(row)
+		return nil
+	})
+}
+
+func (r *GormRepo) ListDunningRecords(ctx context.Context, filter appshared.DunningRecordFilter, limit, offset int) ([]domain.DunningRecord, int, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	q := r.conn(ctx).Model(&dunningRecordRow{})
+	if filter.InstanceID > 0 {
+		q = q.Where("instance_id = ?", filter.InstanceID)
+	}
+	if filter.Action != "" {
+		q = q.Where("action = ?", filter.Action)
+	}
+	if filter.Status != "" {
+		q = q.Where("status = ?", filter.Status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []dunningRecordRow
+	if err := q.Order("executed_at DESC, id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	out := make([]domain.DunningRecord, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromDunningRecordRow(row))
+	}
+	return out, int(total), nil
+}
+
+func (r *GormRepo) ListPaidRenewalItems(ctx context.Context, userID int64) ([]domain.OrderItem, error) {
+	var rows []orderItemRow
+	err := r.conn(ctx).Model(&orderItemRow{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.user_id = ? AND order_items.action = ?", userID, "renew").
+		Where("orders.status IN ?", []string{string(domain.OrderStatusPendingReview), string(domain.OrderStatusApproved), string(domain.OrderStatusProvisioning)}).
+		Where("order_items.status IN ?", []string{string(domain.OrderItemStatusPendingReview), string(domain.OrderItemStatusApproved), string(domain.OrderItemStatusProvisioning)}).
+		Order("order_items.id ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.OrderItem, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromOrderItemRow(row))
+	}
+	return out, nil
+}
+
+type dunningStepJSON struct {
+	OffsetDays int      `json:"offset_days"`
+	Action     string   `json:"action"`
+	Channels   []string `json:"channels"`
+}
+
+func toDunningStepsJSON(steps []domain.DunningStep) []dunningStepJSON {
+	out := make([]dunningStepJSON, 0, len(steps))
+	for _, step := range steps {
+		out = append(out, dunningStepJSON{OffsetDays: step.OffsetDays, Action: string(step.Action), Channels: step.Channels})
+	}
+	return out
+}
+
+func fromDunningPolicyRow(row dunningPolicyRow) domain.DunningPolicy {
+	policy := domain.DunningPolicy{
+		ID:          row.ID,
+		GoodsTypeID: row.GoodsTypeID,
+		Enabled:     row.Enabled == 1,
+		UpdatedBy:   row.UpdatedBy,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+	var steps []dunningStepJSON
+	_ = json.Unmarshal([]byte(row.StepsJSON), &steps)
+	for _, step := range steps {
+		policy.Steps = append(policy.Steps, domain.DunningStep{OffsetDays: step.OffsetDays, Action: domain.DunningAction(step.Action), Channels: step.Channels})
+	}
+	return policy
+}
+
+func fromDunningRecordRow(row dunningRecordRow) domain.DunningRecord {
+	record := domain.DunningRecord{
+		ID:            row.ID,
+		InstanceID:    row.InstanceID,
+		UserID:        row.UserID,
+		GoodsTypeID:   row.GoodsTypeID,
+		CycleExpireAt: row.CycleExpireAt,
+		StepKey:       row.StepKey,
+		Action:        domain.DunningAction(row.Action),
+		OffsetDays:    row.OffsetDays,
+		Status:        domain.DunningStepStatus(row.Status),
+		Error:         row.ErrorMessage,
+		Attempts:      row.Attempts,
+		ExecutedAt:    row.ExecutedAt,
+	}
+	_ = json.Unmarshal([]byte(row.ChannelsJSON), &record.Channels)
+	return record
+}
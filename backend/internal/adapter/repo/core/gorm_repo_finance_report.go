@@ -0,0 +1,250 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+func (r *GormRepo) CreateFinanceReportDefinition(ctx context.Context, def *domain.FinanceReportDefinition) error {
+	row := toFinanceReportDefinitionRow(*def)
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	*def = fromFinanceReportDefinitionRow(row)
+	return nil
+}
+
+func (r *GormRepo) GetFinanceReportDefinition(ctx context.Context, id int64) (domain.FinanceReportDefinition, error) {
+	var row financeReportDefinitionRow
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+		return domain.FinanceReportDefinition{}, r.ensure(err)
+	}
+	return fromFinanceReportDefinitionRow(row), nil
+}
+
+func (r *GormRepo) ListFinanceReportDefinitions(ctx context.Context) ([]domain.FinanceReportDefinition, error) {
+	var rows []financeReportDefinitionRow
+	if err := r.conn(ctx).Order("id ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.FinanceReportDefinition, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromFinanceReportDefinitionRow(row))
+	}
+	return out, nil
+}
+
+func (r *GormRepo) UpdateFinanceReportDefinition(ctx context.Context, def domain.FinanceReportDefinition) error {
+	row := toFinanceReportDefinitionRow(def)
+	res := r.conn(ctx).Model(&financeReportDefinitionRow{}).Where("id = ?", def.ID).Updates(map[string]any{
+		"name":            row.Name,
+		"period_type":     row.Period,
+		"formats_json":    row.FormatsJSON,
+		"recipients_json": row.RecipientsJSON,
+		"enabled":         row.Enabled,
+		"updated_at":      time.Now(),
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return appshared.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormRepo) DeleteFinanceReportDefinition(ctx context.Context, id int64) error {
+	res := r.conn(ctx).Where("id = ?", id).Delete(&financeReportDefinitionRow{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return appshared.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormRepo) TouchFinanceReportDefinition(ctx context.Context, id int64, ranAt time.Time) error {
+	return r.conn(ctx).Model(&financeReportDefinitionRow{}).Where("id = ?", id).Update("last_run_at", ranAt).Error
+}
+
+// CreateFinanceReportRun stores the run together with its files.
+func (r *GormRepo) CreateFinanceReportRun(ctx context.Context, run *domain.FinanceReportRun) error {
+	recipients, _ := json.Marshal(run.Recipients)
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		row := financeReportRunRow{
+			DefinitionID:   run.DefinitionID,
+			Name:           run.Name,
+			Period:         string(run.Period),
+			PeriodStart:    run.PeriodStart,
+			PeriodEnd:      run.PeriodEnd,
+			Trigger:        run.Trigger,
+			Status:         string(run.Status),
+			Error:          run.Error,
+			RecipientsJSON: string(recipients),
+			CreatedBy:      run.CreatedBy,
+			DeliveredAt:    run.DeliveredAt,
+		}
+		if err := tx.Create(&row).Error; err != nil {
+			return err
+		}
+		files := make([]financeReportFileRow, 0, len(run.Files))
+		for _, file := range run.Files {
+			files = append(files, financeReportFileRow{
+				RunID:       row.ID,
+				Name:        file.Name,
+				ContentType: file.ContentType,
+				Size:        int64(len(file.Content)),
+				Content:     file.Content,
+			})
+		}
+		if len(files) > 0 {
+			if err := tx.Create(&files).Error; err != nil {
+				return err
+			}
+		}
+		stored := fromFinanceReportRunRow(row)
+		for i, file := range files {
+			stored.Files = append(stored.Files, fromFinanceReportFileRow(file))
+			stored.Files[i].Content = file.Content
+		}
+		*run = stored
+		return nil
+	})
+}
+
+// GetFinanceReportRun returns the run with file metadata; file contents are
+// loaded separately by GetFinanceReportFile.
+func (r *GormRepo) GetFinanceReportRun(ctx context.Context, id int64) (domain.FinanceReportRun, error) {
+	var row financeReportRunRow
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+		return domain.FinanceReportRun{}, r.ensure(err)
+	}
+	var files []financeReportFileRow
+	if err := r.conn(ctx).Omit("content").Where("run_id = ?", id).Order("id ASC").Find(&files).Error; err != nil {
+		return domain.FinanceReportRun{}, err
+	}
+	run := fromFinanceReportRunRow(row)
+	for _, file := range files {
+		run.Files = append(run.Files, fromFinanceReportFileRow(file))
+	}
+	return run, nil
+}
+
+func (r *GormRepo) ListFinanceReportRuns(ctx context.Context, definitionID int64, limit, offset int) ([]domain.FinanceReportRun, int, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	q := r.conn(ctx).Model(&financeReportRunRow{})
+	if definitionID > 0 {
+		q = q.Where("definition_id = ?", definitionID)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []financeReportRunRow
+	if err := q.Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	out := make([]domain.FinanceReportRun, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromFinanceReportRunRow(row))
+	}
+	return out, int(total), nil
+}
+
+// HasScheduledFinanceReportRun reports whether a scheduled run for the period
+// exists that got as far as producing files.
+func (r *GormRepo) HasScheduledFinanceReportRun(ctx context.Context, definitionID int64, periodStart time.Time) (bool, error) {
+	var count int64
+	err := r.conn(ctx).Model(&financeReportRunRow{}).
+		Where("definition_id = ? AND period_start = ? AND trigger_type = ? AND status <> ?", definitionID, periodStart, domain.FinanceReportTriggerSchedule, string(domain.FinanceReportRunFailed)).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *GormRepo) UpdateFinanceReportRunDelivery(ctx context.Context, id int64, status domain.FinanceReportRunStatus, errMsg string, deliveredAt *time.Time) error {
+	updates := map[string]any{"status": string(status), "error_message": errMsg}
+	if deliveredAt != nil {
+		updates["delivered_at"] = *deliveredAt
+	}
+	return r.conn(ctx).Model(&financeReportRunRow{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *GormRepo) GetFinanceReportFile(ctx context.Context, runID, fileID int64) (domain.FinanceReportFile, error) {
+	var row financeReportFileRow
+	if err := r.conn(ctx).Where("id = ? AND run_id = ?", fileID, runID).First(&row).Error; err != nil {
+		return domain.FinanceReportFile{}, r.ensure(err)
+	}
+	file := fromFinanceReportFileRow(row)
+	file.Content = row.Content
+	return file, nil
+}
+
+func toFinanceReportDefinitionRow(def domain.FinanceReportDefinition) financeReportDefinitionRow {
+	formats, _ := json.Marshal(def.Formats)
+	recipients, _ := json.Marshal(def.Recipients)
+	return financeReportDefinitionRow{
+		ID:             def.ID,
+		Name:           def.Name,
+		Period:         string(def.Period),
+		FormatsJSON:    string(formats),
+		RecipientsJSON: string(recipients),
+		Enabled:        boolToInt(def.Enabled),
+		CreatedBy:      def.CreatedBy,
+		LastRunAt:      def.LastRunAt,
+	}
+}
+
+func fromFinanceReportDefinitionRow(row financeReportDefinitionRow) domain.FinanceReportDefinition {
+	def := domain.FinanceReportDefinition{
+		ID:        row.ID,
+		Name:      row.Name,
+		Period:    domain.FinanceReportPeriod(row.Period),
+		Enabled:   row.Enabled == 1,
+		CreatedBy: row.CreatedBy,
+		LastRunAt: row.LastRunAt,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+	_ = json.Unmarshal([]byte(row.FormatsJSON), &def.Formats)
+	_ = json.Unmarshal([]byte(row.RecipientsJSON), &def.Recipients)
+	return def
+}
+
+func fromFinanceReportRunRow(row financeReportRunRow) domain.FinanceReportRun {
+	run := domain.FinanceReportRun{
+		ID:           row.ID,
+		DefinitionID: row.DefinitionID,
+		Name:         row.Name,
+		Period:       domain.FinanceReportPeriod(row.Period),
+		PeriodStart:  row.PeriodStart,
+		PeriodEnd:    row.PeriodEnd,
+		Trigger:      row.Trigger,
+		Status:       domain.FinanceReportRunStatus(row.Status),
+		Error:        row.Error,
+		CreatedBy:    row.CreatedBy,
+		DeliveredAt:  row.DeliveredAt,
+		CreatedAt:    row.CreatedAt,
+	}
+	_ = json.Unmarshal([]byte(row.RecipientsJSON), &run.Recipients)
+	return run
+}
+
+func fromFinanceReportFileRow(row financeReportFileRow) domain.FinanceReportFile {
+	return domain.FinanceReportFile{
+		ID:          row.ID,
+		RunID:       row.RunID,
+		Name:        row.Name,
+		ContentType: row.ContentType,
+		Size:        row.Size,
+		CreatedAt:   row.CreatedAt,
+	}
+}
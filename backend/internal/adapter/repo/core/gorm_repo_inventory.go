@@ -0,0 +1,255 @@
+package repo
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"xiaoheiplay/internal/domain"
+)
+
+func (r *GormRepo) ReserveInventory(ctx context.Context, reservations []domain.InventoryReservation) error {
+	if len(reservations) == 0 {
+		return nil
+	}
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		return reserveInventoryGorm(tx, reservations)
+	})
+}
+
+// reserveInventoryGorm holds stock for the reservations inside tx; it fails
+// with domain.ErrInsufficientStock when any package or plan group is short.
+func reserveInventoryGorm(tx *gorm.DB, reservations []domain.InventoryReservation) error {
+	byPackage := map[int64]int{}
+	byPlan := map[int64]int{}
+	for _, res := range reservations {
+		qty := res.Qty
+		if qty <= 0 {
+			qty = 1
+		}
+		byPackage[res.PackageID] += qty
+		if res.PlanGroupID > 0 {
+			byPlan[res.PlanGroupID] += qty
+		}
+	}
+	// Lock rows in id order so concurrent checkouts cannot deadlock.
+	for _, id := range sortedInventoryKeys(byPackage) {
+		if err := reserveCapacityGorm(tx, &packageRow{}, id, byPackage[id]); err != nil {
+			return err
+		}
+	}
+	for _, id := range sortedInventoryKeys(byPlan) {
+		if err := reserveCapacityGorm(tx, &planGroupRow{}, id, byPlan[id]); err != nil {
+			return err
+		}
+	}
+	rows := make([]inventoryReservationRow, 0, len(reservations))
+	for _, res := range reservations {
+		qty := res.Qty
+		if qty <= 0 {
+			qty = 1
+		}
+		rows = append(rows, inventoryReservationRow{
+			OrderID:     res.OrderID,
+			OrderItemID: res.OrderItemID,
+			PackageID:   res.PackageID,
+			PlanGroupID: res.PlanGroupID,
+			Qty:         qty,
+			Status:      string(domain.InventoryReservationReserved),
+			ExpiresAt:   res.ExpiresAt,
+		})
+	}
+	return tx.Create(&rows).Error
+}
+
+func (r *GormRepo) ReleaseInventoryReservations(ctx context.Context, orderID int64) (int, error) {
+	released := 0
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		var rows []inventoryReservationRow
+		if err := tx.Where("order_id = ? AND status = ?", orderID, domain.InventoryReservationReserved).Find(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := releaseCapacityGorm(tx, row); err != nil {
+				return err
+			}
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		res := tx.Model(&inventoryReservationRow{}).
+			Where("order_id = ? AND status = ?", orderID, domain.InventoryReservationReserved).
+			Updates(map[string]any{"status": string(domain.InventoryReservationReleased), "updated_at": time.Now()})
+		released = int(res.RowsAffected)
+		return res.Error
+	})
+	return released, err
+}
+
+func (r *GormRepo) CommitInventoryReservation(ctx context.Context, orderItemID int64, consume bool) (bool, error) {
+	committed := false
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		var row inventoryReservationRow
+		res := tx.Where("order_item_id = ? AND status = ?", orderItemID, domain.InventoryReservationReserved).Limit(1).Find(&row)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		if err := releaseCapacityGorm(tx, row); err != nil {
+			return err
+		}
+		if consume {
+			if err := consumeCapacityGorm(tx, &packageRow{}, row.PackageID, row.Qty); err != nil {
+				return err
+			}
+			if row.PlanGroupID > 0 {
+				if err := consumeCapacityGorm(tx, &planGroupRow{}, row.PlanGroupID, row.Qty); err != nil {
+					return err
+				}
+			}
+		}
+		committed = true
+		return tx.Model(&inventoryReservationRow{}).Where("id = ?", row.ID).
+			Updates(map[string]any{"status": string(domain.InventoryReservationCommitted), "updated_at": time.Now()}).Error
+	})
+	return committed, err
+}
+
+func (r *GormRepo) ListInventoryReservations(ctx context.Context, status domain.InventoryReservationStatus, limit int) ([]domain.InventoryReservation, error) {
+	if limit <= 0 {
+		limit = 500
+	}
+	q := r.conn(ctx).Model(&inventoryReservationRow{})
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var rows []inventoryReservationRow
+	if err := q.Order("id ASC").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return toInventoryReservations(rows), nil
+}
+
+func (r *GormRepo) ListExpiredInventoryReservations(ctx context.Context, now time.Time, limit int) ([]domain.InventoryReservation, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	var rows []inventoryReservationRow
+	if err := r.conn(ctx).
+		Where("status = ? AND expires_at IS NOT NULL AND expires_at <= ?", domain.InventoryReservationReserved, now).
+		Order("expires_at ASC, id ASC").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return toInventoryReservations(rows), nil
+}
+
+func (r *GormRepo) ClearInventoryReservationExpiry(ctx context.Context, orderID int64) error {
+	return r.conn(ctx).Model(&inventoryReservationRow{}).
+		Where("order_id = ? AND status = ?", orderID, domain.InventoryReservationReserved).
+		Updates(map[string]any{"expires_at": nil, "updated_at": time.Now()}).Error
+}
+
+func (r *GormRepo) RecountInventoryReserved(ctx context.Context) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&packageRow{}).Where("capacity_reserved <> 0").UpdateColumn("capacity_reserved", 0).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&planGroupRow{}).Where("capacity_reserved <> 0").UpdateColumn("capacity_reserved", 0).Error; err != nil {
+			return err
+		}
+		type sumRow struct {
+			ID  int64
+			Qty int
+		}
+		var pkgSums []sumRow
+		if err := tx.Model(&inventoryReservationRow{}).
+			Select("package_id AS id, SUM(qty) AS qty").
+			Where("status = ?", domain.InventoryReservationReserved).
+			Group("package_id").
+			Scan(&pkgSums).Error; err != nil {
+			return err
+		}
+		for _, sum := range pkgSums {
+			if err := tx.Model(&packageRow{}).Where("id = ?", sum.ID).UpdateColumn("capacity_reserved", sum.Qty).Error; err != nil {
+				return err
+			}
+		}
+		var planSums []sumRow
+		if err := tx.Model(&inventoryReservationRow{}).
+			Select("plan_group_id AS id, SUM(qty) AS qty").
+			Where("status = ? AND plan_group_id > 0", domain.InventoryReservationReserved).
+			Group("plan_group_id").
+			Scan(&planSums).Error; err != nil {
+			return err
+		}
+		for _, sum := range planSums {
+			if err := tx.Model(&planGroupRow{}).Where("id = ?", sum.ID).UpdateColumn("capacity_reserved", sum.Qty).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// reserveCapacityGorm bumps capacity_reserved only when the row is unlimited
+// (capacity_remaining < 0) or still has qty units free.
+func reserveCapacityGorm(tx *gorm.DB, model any, id int64, qty int) error {
+	res := tx.Model(model).
+		Where("id = ? AND (capacity_remaining < 0 OR capacity_remaining - capacity_reserved >= ?)", id, qty).
+		UpdateColumn("capacity_reserved", gorm.Expr("capacity_reserved + ?", qty))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrInsufficientStock
+	}
+	return nil
+}
+
+func releaseCapacityGorm(tx *gorm.DB, row inventoryReservationRow) error {
+	expr := gorm.Expr("CASE WHEN capacity_reserved > ? THEN capacity_reserved - ? ELSE 0 END", row.Qty, row.Qty)
+	if err := tx.Model(&packageRow{}).Where("id = ?", row.PackageID).UpdateColumn("capacity_reserved", expr).Error; err != nil {
+		return err
+	}
+	if row.PlanGroupID <= 0 {
+		return nil
+	}
+	return tx.Model(&planGroupRow{}).Where("id = ?", row.PlanGroupID).UpdateColumn("capacity_reserved", expr).Error
+}
+
+func consumeCapacityGorm(tx *gorm.DB, model any, id int64, qty int) error {
+	return tx.Model(model).
+		Where("id = ? AND capacity_remaining >= 0", id).
+		UpdateColumn("capacity_remaining", gorm.Expr("CASE WHEN capacity_remaining > ? THEN capacity_remaining - ? ELSE 0 END", qty, qty)).Error
+}
+
+func sortedInventoryKeys(m map[int64]int) []int64 {
+	keys := make([]int64, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+func toInventoryReservations(rows []inventoryReservationRow) []domain.InventoryReservation {
+	out := make([]domain.InventoryReservation, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, domain.InventoryReservation{
+			ID:          row.ID,
+			OrderID:     row.OrderID,
+			OrderItemID: row.OrderItemID,
+			PackageID:   row.PackageID,
+			PlanGroupID: row.PlanGroupID,
+			Qty:         row.Qty,
+			Status:      domain.InventoryReservationStatus(row.Status),
+			ExpiresAt:   row.ExpiresAt,
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+		})
+	}
+	return out
+}
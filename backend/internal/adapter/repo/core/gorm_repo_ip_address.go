@@ -0,0 +1,214 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+func (r *GormRepo) CreateIPAddress(ctx context.Context, ip *domain.IPAddress) error {
+	row := toIPAddressRow(*ip)
+	row.ID = 0
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	*ip = fromIPAddressRow(row)
+	return nil
+}
+
+func (r *GormRepo) GetIPAddress(ctx context.Context, id int64) (domain.IPAddress, error) {
+	var row ipAddressRow
+	if err := r.conn(ctx).First(&row, id).Error; err != nil {
+		return domain.IPAddress{}, r.ensure(err)
+	}
+	return fromIPAddressRow(row), nil
+}
+
+func (r *GormRepo) GetIPAddressByAddress(ctx context.Context, address string) (domain.IPAddress, error) {
+	var row ipAddressRow
+	if err := r.conn(ctx).Where("address = ?", address).First(&row).Error; err != nil {
+		return domain.IPAddress{}, r.ensure(err)
+	}
+	return fromIPAddressRow(row), nil
+}
+
+func (r *GormRepo) ListIPAddresses(ctx context.Context, filter appshared.IPAddressFilter, limit, offset int) ([]domain.IPAddress, int, error) {
+	q := r.conn(ctx).Model(&ipAddressRow{})
+	if v := strings.TrimSpace(filter.Keyword); v != "" {
+		like := "%" + v + "%"
+		q = q.Where("address LIKE ? OR ptr LIKE ?", like, like)
+	}
+	if filter.LineID > 0 {
+		q = q.Where("line_id = ?", filter.LineID)
+	}
+	if filter.VPSID > 0 {
+		q = q.Where("vps_id = ?", filter.VPSID)
+	}
+	if v := strings.TrimSpace(filter.Status); v != "" {
+		q = q.Where("status = ?", v)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []ipAddressRow
+	if err := q.Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	out := make([]domain.IPAddress, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromIPAddressRow(row))
+	}
+	return out, int(total), nil
+}
+
+func (r *GormRepo) ListIPAddressesByVPS(ctx context.Context, vpsID int64) ([]domain.IPAddress, error) {
+	var rows []ipAddressRow
+	if err := r.conn(ctx).
+		Where("vps_id = ?", vpsID).
+		Order("is_primary DESC, version ASC, id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.IPAddress, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromIPAddressRow(row))
+	}
+	return out, nil
+}
+
+func (r *GormRepo) UpdateIPAddress(ctx context.Context, ip domain.IPAddress) error {
+	return r.conn(ctx).Model(&ipAddressRow{}).Where("id = ?", ip.ID).Updates(map[string]any{
+		"version":    ip.Version,
+		"line_id":    ip.LineID,
+		"vps_id":     ip.VPSID,
+		"user_id":    ip.UserID,
+		"is_primary": boolToInt(ip.Primary),
+		"ptr":        ip.PTR,
+		"status":     string(ip.Status),
+		"note":       ip.Note,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+func (r *GormRepo) DeleteIPAddress(ctx context.Context, id int64) error {
+	return r.conn(ctx).Delete(&ipAddressRow{}, id).Error
+}
+
+func (r *GormRepo) CreateIPAddressEvent(ctx context.Context, ev *domain.IPAddressEvent) error {
+	row := ipAddressEventRow{
+		IPAddressID: ev.IPAddressID,
+		Address:     ev.Address,
+		Type:        string(ev.Type),
+		VPSID:       ev.VPSID,
+		UserID:      ev.UserID,
+		AdminID:     ev.AdminID,
+		Detail:      ev.Detail,
+	}
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	ev.ID = row.ID
+	ev.CreatedAt = row.CreatedAt
+	return nil
+}
+
+func (r *GormRepo) ListIPAddressEvents(ctx context.Context, ipAddressID int64, limit, offset int) ([]domain.IPAddressEvent, int, error) {
+	q := r.conn(ctx).Model(&ipAddressEventRow{}).Where("ip_address_id = ?", ipAddressID)
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []ipAddressEventRow
+	if err := q.Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	out := make([]domain.IPAddressEvent, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, domain.IPAddressEvent{
+			ID:          row.ID,
+			IPAddressID: row.IPAddressID,
+			Address:     row.Address,
+			Type:        domain.IPAddressEventType(row.Type),
+			VPSID:       row.VPSID,
+			UserID:      row.UserID,
+			AdminID:     row.AdminID,
+			Detail:      row.Detail,
+			CreatedAt:   row.CreatedAt,
+		})
+	}
+	return out, int(total), nil
+}
+
+func toIPAddressRow(ip domain.IPAddress) ipAddressRow {
+	return ipAddressRow{
+		ID:        ip.ID,
+		Address:   ip.Address,
+		Version:   ip.Version,
+		LineID:    ip.LineID,
+		VPSID:     ip.VPSID,
+		UserID:    ip.UserID,
+		IsPrimary: boolToInt(ip.Primary),
+		PTR:       ip.PTR,
+		Status:    string(ip.Status),
+		Note:      ip.Note,
+	}
+}
+
+func fromIPAddressRow(row ipAddressRow) domain.IPAddress {
+	return domain.IPAddress{
+		ID:        row.ID,
+		Address:   row.Address,
+		Version:   row.Version,
+		LineID:    row.LineID,
+		VPSID:     row.VPSID,
+		UserID:    row.UserID,
+		Primary:   row.IsPrimary == 1,
+		PTR:       row.PTR,
+		Status:    domain.IPAddressStatus(row.Status),
+		Note:      row.Note,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+}
+
+// releaseInstanceIPAddresses frees the addresses of a deleted instance and
+// records the release so the allocation history stays complete.
+func releaseInstanceIPAddresses(tx *gorm.DB, vpsID int64) error {
+	var rows []ipAddressRow
+	if err := tx.Where("vps_id = ?", vpsID).Find(&rows).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		status := row.Status
+		if status == string(domain.IPAddressStatusAssigned) {
+			status = string(domain.IPAddressStatusAvailable)
+		}
+		if err := tx.Model(&ipAddressRow{}).Where("id = ?", row.ID).Updates(map[string]any{
+			"vps_id":     0,
+			"user_id":    0,
+			"is_primary": 0,
+			"ptr":        "",
+			"status":     status,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&ipAddressEventRow{
+			IPAddressID: row.ID,
+			Address:     row.Address,
+			Type:        string(domain.IPAddressEventReleased),
+			VPSID:       row.VPSID,
+			UserID:      row.UserID,
+			Detail:      "instance deleted",
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package repo
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+// CreateLedgerEntry stores the entry with its lines and registers unknown
+// accounts. It returns false without error when an entry with the same key
+// already exists.
+func (r *GormRepo) CreateLedgerEntry(ctx context.Context, entry *domain.LedgerEntry) (bool, error) {
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&ledgerEntryRow{}).Where("entry_key = ?", entry.Key).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return appshared.ErrConflict
+		}
+		for _, line := range entry.Lines {
+			userID, _ := domain.LedgerWalletUserID(line.Account)
+			account := ledgerAccountRow{Code: line.Account, Type: string(domain.LedgerAccountTypeOf(line.Account)), UserID: userID}
+			if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "code"}}, DoNothing: true}).Create(&account).Error; err != nil {
+				return err
+			}
+		}
+		row := ledgerEntryRow{
+			EntryKey: entry.Key,
+			Kind:     entry.Kind,
+			RefType:  entry.RefType,
+			RefID:    entry.RefID,
+			UserID:   entry.UserID,
+			Memo:     entry.Memo,
+		}
+		if err := tx.Create(&row).Error; err != nil {
+			return err
+		}
+		lines := make([]ledgerLineRow, 0, len(entry.Lines))
+		for _, line := range entry.Lines {
+			userID, _ := domain.LedgerWalletUserID(line.Account)
+			lines = append(lines, ledgerLineRow{EntryID: row.ID, Account: line.Account, UserID: userID, Debit: line.Debit, Credit: line.Credit})
+		}
+		if err := tx.Create(&lines).Error; err != nil {
+			return err
+		}
+		entry.ID = row.ID
+		entry.CreatedAt = row.CreatedAt
+		for i := range entry.Lines {
+			entry.Lines[i].ID = lines[i].ID
+			entry.Lines[i].EntryID = row.ID
+		}
+		return nil
+	})
+	if err == nil {
+		return true, nil
+	}
+	// A concurrent post with the same key loses on the unique index.
+	var existing int64
+	if cerr := r.conn(ctx).Model(&ledgerEntryRow{}).Where("entry_key = ?", entry.Key).Count(&existing).Error; cerr == nil && existing > 0 {
+		return false, nil
+	}
+	return false, err
+}
+
+func (r *GormRepo) ListLedgerEntries(ctx context.Context, filter appshared.LedgerEntryFilter, limit, offset int) ([]domain.LedgerEntry, int, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	q := r.conn(ctx).Model(&ledgerEntryRow{})
+	if filter.Account != "" {
+		q = q.Where("id IN (?)", r.gdb.Model(&ledgerLineRow{}).Select("entry_id").Where("account = ?", filter.Account))
+	}
+	if filter.Kind != "" {
+		q = q.Where("kind = ?", filter.Kind)
+	}
+	if filter.RefType != "" {
+		q = q.Where("ref_type = ?", filter.RefType)
+	}
+	if filter.RefID > 0 {
+		q = q.Where("ref_id = ?", filter.RefID)
+	}
+	if filter.UserID > 0 {
+		q = q.Where("user_id = ?", filter.UserID)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []ledgerEntryRow
+	if err := q.Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	if len(rows) == 0 {
+		return []domain.LedgerEntry{}, int(total), nil
+	}
+	ids := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	var lineRows []ledgerLineRow
+	if err := r.conn(ctx).Where("entry_id IN ?", ids).Order("id ASC").Find(&lineRows).Error; err != nil {
+		return nil, 0, err
+	}
+	lines := map[int64][]domain.LedgerLine{}
+	for _, line := range lineRows {
+		lines[line.EntryID] = append(lines[line.EntryID], domain.LedgerLine{ID: line.ID, EntryID: line.EntryID, Account: line.Account, Debit: line.Debit, Credit: line.Credit})
+	}
+	out := make([]domain.LedgerEntry, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, domain.LedgerEntry{
+			ID:        row.ID,
+			Key:       row.EntryKey,
+			Kind:      row.Kind,
+			RefType:   row.RefType,
+			RefID:     row.RefID,
+			UserID:    row.UserID,
+			Memo:      row.Memo,
+			CreatedAt: row.CreatedAt,
+			Lines:     lines[row.ID],
+		})
+	}
+	return out, int(total), nil
+}
+
+func (r *GormRepo) ListLedgerAccountBalances(ctx context.Context) ([]domain.LedgerAccountBalance, error) {
+	var rows []struct {
+		Code   string
+		Type   string
+		Debit  int64
+		Credit int64
+	}
+	if err := r.conn(ctx).Model(&ledgerAccountRow{}).
+		Select("ledger_accounts.code AS code, ledger_accounts.type AS type, COALESCE(SUM(ledger_lines.debit), 0) AS debit, COALESCE(SUM(ledger_lines.credit), 0) AS credit").
+		Joins("LEFT JOIN ledger_lines ON ledger_lines.account = ledger_accounts.code").
+		Group("ledger_accounts.code, ledger_accounts.type").
+		Order("ledger_accounts.code ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.LedgerAccountBalance, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, domain.LedgerAccountBalance{Account: row.Code, Type: domain.LedgerAccountType(row.Type), Debit: row.Debit, Credit: row.Credit})
+	}
+	return out, nil
+}
+
+// LedgerWalletBalances returns the ledger balance of every wallet account,
+// keyed by user.
+func (r *GormRepo) LedgerWalletBalances(ctx context.Context) (map[int64]int64, error) {
+	var rows []struct {
+		UserID  int64
+		Balance int64
+	}
+	if err := r.conn(ctx).Model(&ledgerLineRow{}).
+		Select("user_id, COALESCE(SUM(credit), 0) - COALESCE(SUM(debit), 0) AS balance").
+		Where("user_id > 0").
+		Group("user_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		out[row.UserID] = row.Balance
+	}
+	return out, nil
+}
+
+func (r *GormRepo) ListUnbalancedLedgerEntries(ctx context.Context, limit int) ([]int64, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	var ids []int64
+	if err := r.conn(ctx).Model(&ledgerLineRow{}).
+		Select("entry_id").
+		Group("entry_id").
+		Having("SUM(debit) <> SUM(credit)").
+		Order("entry_id ASC").
+		Limit(limit).
+		Pluck("entry_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *GormRepo) ListWalletBalances(ctx context.Context) (map[int64]int64, error) {
+	var rows []walletRow
+	if err := r.conn(ctx).Select("user_id, balance").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		out[row.UserID] = row.Balance
+	}
+	return out, nil
+}
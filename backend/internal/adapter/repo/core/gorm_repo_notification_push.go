@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm/clause"
@@ -18,7 +19,7 @@ func (r *GormRepo) CreateNotification(ctx context.Context, notification *domain.
 		Content: notification.Content,
 		ReadAt:  notification.ReadAt,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	notification.ID = row.ID
@@ -27,7 +28,7 @@ func (r *GormRepo) CreateNotification(ctx context.Context, notification *domain.
 }
 
 func (r *GormRepo) ListNotifications(ctx context.Context, filter appshared.NotificationFilter) ([]domain.Notification, int, error) {
-	q := r.gdb.WithContext(ctx).Model(&notificationRow{})
+	q := r.conn(ctx).Model(&notificationRow{})
 	if filter.UserID != nil {
 		q = q.Where("user_id = ?", *filter.UserID)
 	}
@@ -67,18 +68,18 @@ func (r *GormRepo) ListNotifications(ctx context.Context, filter appshared.Notif
 
 func (r *GormRepo) CountUnread(ctx context.Context, userID int64) (int, error) {
 	var total int64
-	if err := r.gdb.WithContext(ctx).Model(&notificationRow{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&total).Error; err != nil {
+	if err := r.conn(ctx).Model(&notificationRow{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&total).Error; err != nil {
 		return 0, err
 	}
 	return int(total), nil
 }
 
 func (r *GormRepo) MarkNotificationRead(ctx context.Context, userID, notificationID int64) error {
-	return r.gdb.WithContext(ctx).Model(&notificationRow{}).Where("id = ? AND user_id = ?", notificationID, userID).Update("read_at", time.Now()).Error
+	return r.conn(ctx).Model(&notificationRow{}).Where("id = ? AND user_id = ?", notificationID, userID).Update("read_at", time.Now()).Error
 }
 
 func (r *GormRepo) MarkAllRead(ctx context.Context, userID int64) error {
-	return r.gdb.WithContext(ctx).Model(&notificationRow{}).Where("user_id = ? AND read_at IS NULL", userID).Update("read_at", time.Now()).Error
+	return r.conn(ctx).Model(&notificationRow{}).Where("user_id = ? AND read_at IS NULL", userID).Update("read_at", time.Now()).Error
 }
 
 func (r *GormRepo) UpsertPushToken(ctx context.Context, token *domain.PushToken) error {
@@ -99,7 +100,7 @@ func (r *GormRepo) UpsertPushToken(ctx context.Context, token *domain.PushToken)
 		CreatedAt: token.CreatedAt,
 		UpdatedAt: token.UpdatedAt,
 	}
-	return r.gdb.WithContext(ctx).
+	return r.conn(ctx).
 		Clauses(clause.OnConflict{
 			Columns: []clause.Column{{Name: "user_id"}, {Name: "token"}},
 			DoUpdates: clause.AssignmentColumns([]string{
@@ -110,7 +111,7 @@ func (r *GormRepo) UpsertPushToken(ctx context.Context, token *domain.PushToken)
 }
 
 func (r *GormRepo) DeletePushToken(ctx context.Context, userID int64, token string) error {
-	return r.gdb.WithContext(ctx).Where("user_id = ? AND token = ?", userID, token).Delete(&pushTokenRow{}).Error
+	return r.conn(ctx).Where("user_id = ? AND token = ?", userID, token).Delete(&pushTokenRow{}).Error
 }
 
 func (r *GormRepo) ListPushTokensByUserIDs(ctx context.Context, userIDs []int64) ([]domain.PushToken, error) {
@@ -118,7 +119,7 @@ func (r *GormRepo) ListPushTokensByUserIDs(ctx context.Context, userIDs []int64)
 		return nil, nil
 	}
 	var rows []pushTokenRow
-	if err := r.gdb.WithContext(ctx).Where("user_id IN ?", userIDs).Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Where("user_id IN ?", userIDs).Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.PushToken, 0, len(rows))
@@ -135,3 +136,108 @@ func (r *GormRepo) ListPushTokensByUserIDs(ctx context.Context, userIDs []int64)
 	}
 	return out, nil
 }
+
+func (r *GormRepo) GetNotificationPreferences(ctx context.Context, userID int64) (domain.NotificationPreferences, error) {
+	var row notificationPreferenceRow
+	if err := r.conn(ctx).Where("user_id = ?", userID).First(&row).Error; err != nil {
+		return domain.NotificationPreferences{}, r.ensure(err)
+	}
+	disabled := map[string][]string{}
+	if row.DisabledJSON != "" {
+		_ = json.Unmarshal([]byte(row.DisabledJSON), &disabled)
+	}
+	return domain.NotificationPreferences{
+		UserID:            row.UserID,
+		Disabled:          disabled,
+		QuietHoursEnabled: row.QuietHoursEnabled == 1,
+		QuietStart:        row.QuietStart,
+		QuietEnd:          row.QuietEnd,
+		Timezone:          row.Timezone,
+		UpdatedAt:         row.UpdatedAt,
+	}, nil
+}
+
+func (r *GormRepo) UpsertNotificationPreferences(ctx context.Context, prefs *domain.NotificationPreferences) error {
+	if prefs == nil {
+		return nil
+	}
+	disabled := prefs.Disabled
+	if disabled == nil {
+		disabled = map[string][]string{}
+	}
+	raw, err := json.Marshal(disabled)
+	if err != nil {
+		return err
+	}
+	row := notificationPreferenceRow{
+		UserID:            prefs.UserID,
+		DisabledJSON:      string(raw),
+		QuietHoursEnabled: boolToInt(prefs.QuietHoursEnabled),
+		QuietStart:        prefs.QuietStart,
+		QuietEnd:          prefs.QuietEnd,
+		Timezone:          prefs.Timezone,
+		UpdatedAt:         time.Now(),
+	}
+	if err := r.conn(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"disabled_json", "quiet_hours_enabled", "quiet_start", "quiet_end", "timezone", "updated_at",
+			}),
+		}).
+		Create(&row).Error; err != nil {
+		return err
+	}
+	prefs.UpdatedAt = row.UpdatedAt
+	return nil
+}
+
+func (r *GormRepo) CreateDeferredNotification(ctx context.Context, item *domain.DeferredNotification) error {
+	if item == nil {
+		return nil
+	}
+	row := deferredNotificationRow{
+		UserID:      item.UserID,
+		Channel:     item.Channel,
+		Type:        item.Type,
+		PayloadJSON: item.PayloadJSON,
+		SendAfter:   item.SendAfter.UTC(),
+	}
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	item.ID = row.ID
+	item.CreatedAt = row.CreatedAt
+	return nil
+}
+
+func (r *GormRepo) ListDueDeferredNotifications(ctx context.Context, channel string, now time.Time, limit int) ([]domain.DeferredNotification, error) {
+	if limit <= 0 {
+		limit = 200
+	}
+	var rows []deferredNotificationRow
+	if err := r.conn(ctx).
+		Where("channel = ? AND send_after <= ?", channel, now.UTC()).
+		Order("send_after ASC, id ASC").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.DeferredNotification, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, domain.DeferredNotification{
+			ID:          row.ID,
+			UserID:      row.UserID,
+			Channel:     row.Channel,
+			Type:        row.Type,
+			PayloadJSON: row.PayloadJSON,
+			SendAfter:   row.SendAfter,
+			CreatedAt:   row.CreatedAt,
+		})
+	}
+	return out, nil
+}
+
+func (r *GormRepo) DeleteDeferredNotification(ctx context.Context, id int64) error {
+	return r.conn(ctx).Where("id = ?", id).Delete(&deferredNotificationRow{}).Error
+}
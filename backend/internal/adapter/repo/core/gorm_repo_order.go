@@ -12,7 +12,7 @@ import (
 func (r *GormRepo) CreateOrder(ctx context.Context, order *domain.Order) error {
 
 	row := toOrderRow(*order)
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	*order = fromOrderRow(row)
@@ -20,7 +20,10 @@ func (r *GormRepo) CreateOrder(ctx context.Context, order *domain.Order) error {
 
 }
 
-func (r *GormRepo) CreateOrderFromCartAtomic(ctx context.Context, order domain.Order, items []domain.OrderItem) (created domain.Order, createdItems []domain.OrderItem, err error) {
+// CreateOrderFromCartAtomic creates the order and its items, holds the stock
+// planned for them and clears the cart in one transaction. reservations is
+// parallel to items; entries without a PackageID are skipped.
+func (r *GormRepo) CreateOrderFromCartAtomic(ctx context.Context, order domain.Order, items []domain.OrderItem, reservations []domain.InventoryReservation) (created domain.Order, createdItems []domain.OrderItem, err error) {
 
 	tx := r.gdb.WithContext(ctx).Begin()
 	if tx.Error != nil {
@@ -53,6 +56,21 @@ func (r *GormRepo) CreateOrderFromCartAtomic(ctx context.Context, order domain.O
 		}
 	}
 
+	held := make([]domain.InventoryReservation, 0, len(reservations))
+	for i, res := range reservations {
+		if res.PackageID <= 0 || i >= len(createdItems) {
+			continue
+		}
+		res.OrderID = order.ID
+		res.OrderItemID = createdItems[i].ID
+		held = append(held, res)
+	}
+	if len(held) > 0 {
+		if err = reserveInventoryGorm(tx, held); err != nil {
+			return domain.Order{}, nil, err
+		}
+	}
+
 	if err = tx.Where("user_id = ?", order.UserID).Delete(&cartItemRow{}).Error; err != nil {
 		return domain.Order{}, nil, err
 	}
@@ -66,7 +84,7 @@ func (r *GormRepo) CreateOrderFromCartAtomic(ctx context.Context, order domain.O
 func (r *GormRepo) GetOrder(ctx context.Context, id int64) (domain.Order, error) {
 
 	var row orderRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.Order{}, r.ensure(err)
 	}
 	return fromOrderRow(row), nil
@@ -76,7 +94,7 @@ func (r *GormRepo) GetOrder(ctx context.Context, id int64) (domain.Order, error)
 func (r *GormRepo) GetOrderByNo(ctx context.Context, orderNo string) (domain.Order, error) {
 
 	var row orderRow
-	if err := r.gdb.WithContext(ctx).Where("order_no = ?", orderNo).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("order_no = ?", orderNo).First(&row).Error; err != nil {
 		return domain.Order{}, r.ensure(err)
 	}
 	return fromOrderRow(row), nil
@@ -86,7 +104,7 @@ func (r *GormRepo) GetOrderByNo(ctx context.Context, orderNo string) (domain.Ord
 func (r *GormRepo) GetOrderByIdempotencyKey(ctx context.Context, userID int64, key string) (domain.Order, error) {
 
 	var row orderRow
-	if err := r.gdb.WithContext(ctx).Where("user_id = ? AND idempotency_key = ?", userID, key).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("user_id = ? AND idempotency_key = ?", userID, key).First(&row).Error; err != nil {
 		return domain.Order{}, r.ensure(err)
 	}
 	return fromOrderRow(row), nil
@@ -95,7 +113,7 @@ func (r *GormRepo) GetOrderByIdempotencyKey(ctx context.Context, userID int64, k
 
 func (r *GormRepo) UpdateOrderStatus(ctx context.Context, id int64, status domain.OrderStatus) error {
 
-	return r.gdb.WithContext(ctx).Model(&orderRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&orderRow{}).Where("id = ?", id).Updates(map[string]any{
 		"status":     status,
 		"updated_at": time.Now(),
 	}).Error
@@ -104,7 +122,7 @@ func (r *GormRepo) UpdateOrderStatus(ctx context.Context, id int64, status domai
 
 func (r *GormRepo) UpdateOrderMeta(ctx context.Context, order domain.Order) error {
 
-	return r.gdb.WithContext(ctx).Model(&orderRow{}).Where("id = ?", order.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&orderRow{}).Where("id = ?", order.ID).Updates(map[string]any{
 		"status":          order.Status,
 		"pending_reason":  order.PendingReason,
 		"approved_by":     order.ApprovedBy,
@@ -117,7 +135,7 @@ func (r *GormRepo) UpdateOrderMeta(ctx context.Context, order domain.Order) erro
 
 func (r *GormRepo) ApproveResizeOrderWithTasks(ctx context.Context, order domain.Order, items []domain.OrderItem, tasks []*domain.ResizeTask) error {
 
-	return r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		for _, task := range tasks {
 			if task == nil {
 				continue
@@ -182,7 +200,7 @@ func (r *GormRepo) ApproveResizeOrderWithTasks(ctx context.Context, order domain
 
 func (r *GormRepo) ListOrders(ctx context.Context, filter appshared.OrderFilter, limit, offset int) ([]domain.Order, int, error) {
 
-	q := r.gdb.WithContext(ctx).Model(&orderRow{})
+	q := r.conn(ctx).Model(&orderRow{})
 	if filter.Status != "" {
 		q = q.Where("status = ?", filter.Status)
 	}
@@ -267,7 +285,7 @@ func (r *GormRepo) CreateOrderItems(ctx context.Context, items []domain.OrderIte
 	for _, item := range items {
 		rows = append(rows, toOrderItemRow(item))
 	}
-	if err := r.gdb.WithContext(ctx).Create(&rows).Error; err != nil {
+	if err := r.conn(ctx).Create(&rows).Error; err != nil {
 		return err
 	}
 	for i := range rows {
@@ -282,7 +300,7 @@ func (r *GormRepo) CreateOrderItems(ctx context.Context, items []domain.OrderIte
 func (r *GormRepo) ListOrderItems(ctx context.Context, orderID int64) ([]domain.OrderItem, error) {
 
 	var rows []orderItemRow
-	if err := r.gdb.WithContext(ctx).Where("order_id = ?", orderID).Order("id ASC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Where("order_id = ?", orderID).Order("id ASC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.OrderItem, 0, len(rows))
@@ -296,7 +314,7 @@ func (r *GormRepo) ListOrderItems(ctx context.Context, orderID int64) ([]domain.
 func (r *GormRepo) GetOrderItem(ctx context.Context, id int64) (domain.OrderItem, error) {
 
 	var row orderItemRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.OrderItem{}, r.ensure(err)
 	}
 	return fromOrderItemRow(row), nil
@@ -327,7 +345,7 @@ func (r *GormRepo) hasExclusiveVPSOrderInProgress(ctx context.Context, userID, v
 	}
 	actions := []string{"renew", "emergency_renew", "resize", "refund"}
 	var rows []orderItemRow
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Joins("JOIN orders o ON o.id = order_items.order_id").
 		Where("o.user_id = ? AND order_items.action IN ? AND o.status IN ?",
 			userID, actions, progressStatuses).
@@ -350,7 +368,7 @@ func (r *GormRepo) hasExclusiveVPSOrderInProgress(ctx context.Context, userID, v
 
 func (r *GormRepo) UpdateOrderItemStatus(ctx context.Context, id int64, status domain.OrderItemStatus) error {
 
-	return r.gdb.WithContext(ctx).Model(&orderItemRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&orderItemRow{}).Where("id = ?", id).Updates(map[string]any{
 		"status":     status,
 		"updated_at": time.Now(),
 	}).Error
@@ -359,7 +377,7 @@ func (r *GormRepo) UpdateOrderItemStatus(ctx context.Context, id int64, status d
 
 func (r *GormRepo) UpdateOrderItemAutomation(ctx context.Context, id int64, automationID string) error {
 
-	return r.gdb.WithContext(ctx).Model(&orderItemRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&orderItemRow{}).Where("id = ?", id).Updates(map[string]any{
 		"automation_instance_id": automationID,
 		"updated_at":             time.Now(),
 	}).Error
@@ -0,0 +1,167 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"xiaoheiplay/internal/domain"
+)
+
+func (r *GormRepo) ListOrderApprovalRules(ctx context.Context) ([]domain.OrderApprovalRule, error) {
+	var rows []orderApprovalRuleRow
+	if err := r.conn(ctx).Order("priority DESC, id ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.OrderApprovalRule, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromOrderApprovalRuleRow(row))
+	}
+	return out, nil
+}
+
+func (r *GormRepo) GetOrderApprovalRule(ctx context.Context, id int64) (domain.OrderApprovalRule, error) {
+	var row orderApprovalRuleRow
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+		return domain.OrderApprovalRule{}, r.ensure(err)
+	}
+	return fromOrderApprovalRuleRow(row), nil
+}
+
+func (r *GormRepo) CreateOrderApprovalRule(ctx context.Context, rule *domain.OrderApprovalRule) error {
+	row := toOrderApprovalRuleRow(*rule)
+	row.ID = 0
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	*rule = fromOrderApprovalRuleRow(row)
+	return nil
+}
+
+func (r *GormRepo) UpdateOrderApprovalRule(ctx context.Context, rule domain.OrderApprovalRule) error {
+	return r.conn(ctx).Model(&orderApprovalRuleRow{}).Where("id = ?", rule.ID).Updates(map[string]any{
+		"name":                 rule.Name,
+		"priority":             rule.Priority,
+		"enabled":              boolToInt(rule.Enabled),
+		"decision":             string(rule.Decision),
+		"min_amount":           rule.MinAmount,
+		"max_account_age_days": rule.MaxAccountAgeDays,
+		"realname_unverified":  boolToInt(rule.RealNameUnverified),
+		"countries":            strings.Join(rule.Countries, ","),
+		"min_instances":        rule.MinInstances,
+		"min_recent_refunds":   rule.MinRecentRefunds,
+		"reason":               rule.Reason,
+		"updated_at":           time.Now(),
+	}).Error
+}
+
+func (r *GormRepo) DeleteOrderApprovalRule(ctx context.Context, id int64) error {
+	return r.conn(ctx).Where("id = ?", id).Delete(&orderApprovalRuleRow{}).Error
+}
+
+func (r *GormRepo) CreateOrderApprovalEvaluation(ctx context.Context, eval *domain.OrderApprovalEvaluation) error {
+	row := orderApprovalEvaluationRow{
+		OrderID:          eval.OrderID,
+		UserID:           eval.UserID,
+		Decision:         string(eval.Decision),
+		RuleID:           eval.RuleID,
+		RuleName:         eval.RuleName,
+		Reason:           eval.Reason,
+		Amount:           eval.Facts.Amount,
+		AccountAgeDays:   eval.Facts.AccountAgeDays,
+		RealNameVerified: boolToInt(eval.Facts.RealNameVerified),
+		ClientIP:         eval.Facts.ClientIP,
+		Country:          eval.Facts.Country,
+		Instances:        eval.Facts.Instances,
+		RecentRefunds:    eval.Facts.RecentRefunds,
+	}
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	eval.ID = row.ID
+	eval.CreatedAt = row.CreatedAt
+	return nil
+}
+
+func (r *GormRepo) GetOrderApprovalEvaluation(ctx context.Context, orderID int64) (domain.OrderApprovalEvaluation, error) {
+	var row orderApprovalEvaluationRow
+	if err := r.conn(ctx).Where("order_id = ?", orderID).First(&row).Error; err != nil {
+		return domain.OrderApprovalEvaluation{}, r.ensure(err)
+	}
+	return domain.OrderApprovalEvaluation{
+		ID:       row.ID,
+		OrderID:  row.OrderID,
+		UserID:   row.UserID,
+		Decision: domain.OrderApprovalDecision(row.Decision),
+		RuleID:   row.RuleID,
+		RuleName: row.RuleName,
+		Reason:   row.Reason,
+		Facts: domain.OrderApprovalFacts{
+			Amount:           row.Amount,
+			AccountAgeDays:   row.AccountAgeDays,
+			RealNameVerified: row.RealNameVerified == 1,
+			ClientIP:         row.ClientIP,
+			Country:          row.Country,
+			Instances:        row.Instances,
+			RecentRefunds:    row.RecentRefunds,
+		},
+		CreatedAt: row.CreatedAt,
+	}, nil
+}
+
+// CountRefundOrders counts the refund orders a user placed in [from, to),
+// leaving out the ones that were rejected or canceled.
+func (r *GormRepo) CountRefundOrders(ctx context.Context, userID int64, from, to time.Time) (int, error) {
+	var total int64
+	err := r.conn(ctx).Model(&orderRow{}).
+		Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, from, to).
+		Where("status NOT IN ?", []string{string(domain.OrderStatusRejected), string(domain.OrderStatusCanceled)}).
+		Where("id IN (?)", r.gdb.Model(&orderItemRow{}).Select("order_id").Where("action = ?", "refund")).
+		Count(&total).Error
+	if err != nil {
+		return 0, err
+	}
+	return int(total), nil
+}
+
+func toOrderApprovalRuleRow(rule domain.OrderApprovalRule) orderApprovalRuleRow {
+	return orderApprovalRuleRow{
+		ID:                 rule.ID,
+		Name:               rule.Name,
+		Priority:           rule.Priority,
+		Enabled:            boolToInt(rule.Enabled),
+		Decision:           string(rule.Decision),
+		MinAmount:          rule.MinAmount,
+		MaxAccountAgeDays:  rule.MaxAccountAgeDays,
+		RealNameUnverified: boolToInt(rule.RealNameUnverified),
+		Countries:          strings.Join(rule.Countries, ","),
+		MinInstances:       rule.MinInstances,
+		MinRecentRefunds:   rule.MinRecentRefunds,
+		Reason:             rule.Reason,
+	}
+}
+
+func fromOrderApprovalRuleRow(row orderApprovalRuleRow) domain.OrderApprovalRule {
+	var countries []string
+	for _, code := range strings.Split(row.Countries, ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			countries = append(countries, code)
+		}
+	}
+	return domain.OrderApprovalRule{
+		ID:                 row.ID,
+		Name:               row.Name,
+		Priority:           row.Priority,
+		Enabled:            row.Enabled == 1,
+		Decision:           domain.OrderApprovalDecision(row.Decision),
+		MinAmount:          row.MinAmount,
+		MaxAccountAgeDays:  row.MaxAccountAgeDays,
+		RealNameUnverified: row.RealNameUnverified == 1,
+		Countries:          countries,
+		MinInstances:       row.MinInstances,
+		MinRecentRefunds:   row.MinRecentRefunds,
+		Reason:             row.Reason,
+		CreatedAt:          row.CreatedAt,
+		UpdatedAt:          row.UpdatedAt,
+	}
+}
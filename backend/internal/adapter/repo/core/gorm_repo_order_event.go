@@ -34,7 +34,7 @@ func (r *GormRepo) AppendEvent(ctx context.Context, orderID int64, eventType str
 func (r *GormRepo) ListEventsAfter(ctx context.Context, orderID int64, afterSeq int64, limit int) ([]domain.OrderEvent, error) {
 
 	var rows []orderEventRow
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Where("order_id = ? AND seq > ?", orderID, afterSeq).
 		Order("seq ASC").
 		Limit(limit).
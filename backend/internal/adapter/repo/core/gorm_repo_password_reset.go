@@ -13,7 +13,7 @@ func (r *GormRepo) CreatePasswordResetToken(ctx context.Context, token *domain.P
 		ExpiresAt: token.ExpiresAt,
 		Used:      boolToInt(token.Used),
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	token.ID = row.ID
@@ -25,7 +25,7 @@ func (r *GormRepo) CreatePasswordResetToken(ctx context.Context, token *domain.P
 func (r *GormRepo) GetPasswordResetToken(ctx context.Context, token string) (domain.PasswordResetToken, error) {
 
 	var row passwordResetTokenRow
-	if err := r.gdb.WithContext(ctx).Where("token = ?", token).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("token = ?", token).First(&row).Error; err != nil {
 		return domain.PasswordResetToken{}, r.ensure(err)
 	}
 	return domain.PasswordResetToken{
@@ -41,13 +41,13 @@ func (r *GormRepo) GetPasswordResetToken(ctx context.Context, token string) (dom
 
 func (r *GormRepo) MarkPasswordResetTokenUsed(ctx context.Context, tokenID int64) error {
 
-	return r.gdb.WithContext(ctx).Model(&passwordResetTokenRow{}).Where("id = ?", tokenID).Update("used", 1).Error
+	return r.conn(ctx).Model(&passwordResetTokenRow{}).Where("id = ?", tokenID).Update("used", 1).Error
 
 }
 
 func (r *GormRepo) DeleteExpiredTokens(ctx context.Context) error {
 
-	return r.gdb.WithContext(ctx).Where("expires_at < CURRENT_TIMESTAMP").Delete(&passwordResetTokenRow{}).Error
+	return r.conn(ctx).Where("expires_at < CURRENT_TIMESTAMP").Delete(&passwordResetTokenRow{}).Error
 
 }
 
@@ -60,7 +60,7 @@ func (r *GormRepo) CreatePasswordResetTicket(ctx context.Context, ticket *domain
 		ExpiresAt: ticket.ExpiresAt,
 		Used:      boolToInt(ticket.Used),
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	ticket.ID = row.ID
@@ -70,7 +70,7 @@ func (r *GormRepo) CreatePasswordResetTicket(ctx context.Context, ticket *domain
 
 func (r *GormRepo) GetPasswordResetTicket(ctx context.Context, token string) (domain.PasswordResetTicket, error) {
 	var row passwordResetTicketRow
-	if err := r.gdb.WithContext(ctx).Where("token = ?", token).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("token = ?", token).First(&row).Error; err != nil {
 		return domain.PasswordResetTicket{}, r.ensure(err)
 	}
 	return domain.PasswordResetTicket{
@@ -86,9 +86,9 @@ func (r *GormRepo) GetPasswordResetTicket(ctx context.Context, token string) (do
 }
 
 func (r *GormRepo) MarkPasswordResetTicketUsed(ctx context.Context, ticketID int64) error {
-	return r.gdb.WithContext(ctx).Model(&passwordResetTicketRow{}).Where("id = ?", ticketID).Update("used", 1).Error
+	return r.conn(ctx).Model(&passwordResetTicketRow{}).Where("id = ?", ticketID).Update("used", 1).Error
 }
 
 func (r *GormRepo) DeleteExpiredPasswordResetTickets(ctx context.Context) error {
-	return r.gdb.WithContext(ctx).Where("expires_at < CURRENT_TIMESTAMP").Delete(&passwordResetTicketRow{}).Error
+	return r.conn(ctx).Where("expires_at < CURRENT_TIMESTAMP").Delete(&passwordResetTicketRow{}).Error
 }
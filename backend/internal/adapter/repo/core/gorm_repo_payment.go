@@ -18,7 +18,7 @@ func (r *GormRepo) CreatePayment(ctx context.Context, payment *domain.OrderPayme
 	}
 	row := toOrderPaymentRow(*payment)
 	row.TradeNo = tradeNo
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	*payment = fromOrderPaymentRow(row)
@@ -28,7 +28,7 @@ func (r *GormRepo) CreatePayment(ctx context.Context, payment *domain.OrderPayme
 func (r *GormRepo) ListPaymentsByOrder(ctx context.Context, orderID int64) ([]domain.OrderPayment, error) {
 
 	var rows []orderPaymentRow
-	if err := r.gdb.WithContext(ctx).Where("order_id = ?", orderID).Order("id DESC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Where("order_id = ?", orderID).Order("id DESC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.OrderPayment, 0, len(rows))
@@ -44,7 +44,7 @@ func (r *GormRepo) GetPaymentByTradeNo(ctx context.Context, tradeNo string) (dom
 		return domain.OrderPayment{}, sql.ErrNoRows
 	}
 	var row orderPaymentRow
-	if err := r.gdb.WithContext(ctx).Where("trade_no = ?", tradeNo).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("trade_no = ?", tradeNo).First(&row).Error; err != nil {
 		return domain.OrderPayment{}, r.ensure(err)
 	}
 	return fromOrderPaymentRow(row), nil
@@ -53,7 +53,7 @@ func (r *GormRepo) GetPaymentByTradeNo(ctx context.Context, tradeNo string) (dom
 func (r *GormRepo) GetPaymentByIdempotencyKey(ctx context.Context, orderID int64, key string) (domain.OrderPayment, error) {
 
 	var row orderPaymentRow
-	if err := r.gdb.WithContext(ctx).Where("order_id = ? AND idempotency_key = ?", orderID, key).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("order_id = ? AND idempotency_key = ?", orderID, key).First(&row).Error; err != nil {
 		return domain.OrderPayment{}, r.ensure(err)
 	}
 	return fromOrderPaymentRow(row), nil
@@ -62,7 +62,7 @@ func (r *GormRepo) GetPaymentByIdempotencyKey(ctx context.Context, orderID int64
 
 func (r *GormRepo) UpdatePaymentStatus(ctx context.Context, id int64, status domain.PaymentStatus, reviewedBy *int64, reason string) error {
 
-	return r.gdb.WithContext(ctx).Model(&orderPaymentRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&orderPaymentRow{}).Where("id = ?", id).Updates(map[string]any{
 		"status":        status,
 		"reviewed_by":   reviewedBy,
 		"review_reason": reason,
@@ -73,7 +73,7 @@ func (r *GormRepo) UpdatePaymentStatus(ctx context.Context, id int64, status dom
 
 func (r *GormRepo) UpdatePaymentTradeNo(ctx context.Context, id int64, tradeNo string) error {
 
-	return r.gdb.WithContext(ctx).Model(&orderPaymentRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&orderPaymentRow{}).Where("id = ?", id).Updates(map[string]any{
 		"trade_no":   tradeNo,
 		"updated_at": time.Now(),
 	}).Error
@@ -82,10 +82,13 @@ func (r *GormRepo) UpdatePaymentTradeNo(ctx context.Context, id int64, tradeNo s
 
 func (r *GormRepo) ListPayments(ctx context.Context, filter appshared.PaymentFilter, limit, offset int) ([]domain.OrderPayment, int, error) {
 
-	q := r.gdb.WithContext(ctx).Model(&orderPaymentRow{})
+	q := r.conn(ctx).Model(&orderPaymentRow{})
 	if filter.Status != "" {
 		q = q.Where("status = ?", filter.Status)
 	}
+	if filter.UserID > 0 {
+		q = q.Where("user_id = ?", filter.UserID)
+	}
 	if filter.From != nil {
 		q = q.Where("created_at >= ?", filter.From)
 	}
@@ -0,0 +1,313 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+func (r *GormRepo) CreatePayoutAccount(ctx context.Context, account *domain.PayoutAccount) error {
+	row := payoutAccountRow{
+		UserID:      account.UserID,
+		Channel:     string(account.Channel),
+		AccountName: account.AccountName,
+		AccountNo:   account.AccountNo,
+		BankName:    account.BankName,
+		BankBranch:  account.BankBranch,
+		Status:      string(account.Status),
+	}
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	*account = fromPayoutAccountRow(row)
+	return nil
+}
+
+func (r *GormRepo) GetPayoutAccount(ctx context.Context, id int64) (domain.PayoutAccount, error) {
+	var row payoutAccountRow
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+		return domain.PayoutAccount{}, r.ensure(err)
+	}
+	return fromPayoutAccountRow(row), nil
+}
+
+func (r *GormRepo) ListPayoutAccounts(ctx context.Context, userID int64, status string, limit, offset int) ([]domain.PayoutAccount, int, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	q := r.conn(ctx).Model(&payoutAccountRow{})
+	if userID > 0 {
+		q = q.Where("user_id = ?", userID)
+	}
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []payoutAccountRow
+	if err := q.Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	out := make([]domain.PayoutAccount, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromPayoutAccountRow(row))
+	}
+	return out, int(total), nil
+}
+
+func (r *GormRepo) UpdatePayoutAccountStatus(ctx context.Context, id int64, status domain.PayoutAccountStatus, reviewedBy int64, reason string) error {
+	now := time.Now()
+	res := r.conn(ctx).Model(&payoutAccountRow{}).Where("id = ?", id).Updates(map[string]any{
+		"status":        string(status),
+		"review_reason": reason,
+		"reviewed_by":   reviewedBy,
+		"reviewed_at":   now,
+		"updated_at":    now,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return appshared.ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormRepo) DeletePayoutAccount(ctx context.Context, userID, id int64) error {
+	res := r.conn(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&payoutAccountRow{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return appshared.ErrNotFound
+	}
+	return nil
+}
+
+// ListUnbatchedWithdrawOrders returns approved withdrawals that are not part
+// of any payout batch yet, oldest first. When orderIDs is set only those
+// orders are considered.
+func (r *GormRepo) ListUnbatchedWithdrawOrders(ctx context.Context, orderIDs []int64, limit int) ([]domain.WalletOrder, error) {
+	if limit <= 0 {
+		limit = 500
+	}
+	q := r.conn(ctx).Model(&walletOrderRow{}).
+		Where("type = ? AND status = ?", string(domain.WalletOrderWithdraw), string(domain.WalletOrderApproved)).
+		Where("id NOT IN (?)", r.gdb.Model(&payoutBatchItemRow{}).Select("wallet_order_id"))
+	if len(orderIDs) > 0 {
+		q = q.Where("id IN ?", orderIDs)
+	}
+	var rows []walletOrderRow
+	if err := q.Order("id ASC").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.WalletOrder, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, domain.WalletOrder{
+			ID:           row.ID,
+			UserID:       row.UserID,
+			Type:         domain.WalletOrderType(row.Type),
+			Amount:       row.Amount,
+			Currency:     row.Currency,
+			Status:       domain.WalletOrderStatus(row.Status),
+			Note:         row.Note,
+			MetaJSON:     row.MetaJSON,
+			ReviewedBy:   row.ReviewedBy,
+			ReviewReason: row.ReviewReason,
+			CreatedAt:    row.CreatedAt,
+			UpdatedAt:    row.UpdatedAt,
+		})
+	}
+	return out, nil
+}
+
+// CreatePayoutBatch stores the batch with its items. It fails with
+// ErrConflict when one of the withdrawals is already in another batch.
+func (r *GormRepo) CreatePayoutBatch(ctx context.Context, batch *domain.PayoutBatch) error {
+	if len(batch.Items) == 0 {
+		return appshared.ErrInvalidInput
+	}
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		orderIDs := make([]int64, 0, len(batch.Items))
+		for _, item := range batch.Items {
+			orderIDs = append(orderIDs, item.WalletOrderID)
+		}
+		var existing int64
+		if err := tx.Model(&payoutBatchItemRow{}).Where("wallet_order_id IN ?", orderIDs).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return appshared.ErrConflict
+		}
+		row := payoutBatchRow{
+			Channel:     string(batch.Channel),
+			Status:      string(batch.Status),
+			ItemCount:   batch.ItemCount,
+			TotalAmount: batch.TotalAmount,
+			Note:        batch.Note,
+			CreatedBy:   batch.CreatedBy,
+		}
+		if err := tx.Create(&row).Error; err != nil {
+			return err
+		}
+		items := make([]payoutBatchItemRow, 0, len(batch.Items))
+		for _, item := range batch.Items {
+			items = append(items, payoutBatchItemRow{
+				BatchID:         row.ID,
+				WalletOrderID:   item.WalletOrderID,
+				UserID:          item.UserID,
+				Amount:          item.Amount,
+				PayoutAccountID: item.PayoutAccountID,
+				AccountName:     item.AccountName,
+				AccountNo:       item.AccountNo,
+				BankName:        item.BankName,
+				BankBranch:      item.BankBranch,
+				Status:          string(domain.PayoutItemPending),
+			})
+		}
+		if err := tx.Create(&items).Error; err != nil {
+			return err
+		}
+		*batch = fromPayoutBatchRow(row)
+		for _, item := range items {
+			batch.Items = append(batch.Items, fromPayoutBatchItemRow(item))
+		}
+		return nil
+	})
+}
+
+func (r *GormRepo) GetPayoutBatch(ctx context.Context, id int64) (domain.PayoutBatch, error) {
+	var row payoutBatchRow
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+		return domain.PayoutBatch{}, r.ensure(err)
+	}
+	var items []payoutBatchItemRow
+	if err := r.conn(ctx).Where("batch_id = ?", id).Order("id ASC").Find(&items).Error; err != nil {
+		return domain.PayoutBatch{}, err
+	}
+	batch := fromPayoutBatchRow(row)
+	for _, item := range items {
+		batch.Items = append(batch.Items, fromPayoutBatchItemRow(item))
+	}
+	return batch, nil
+}
+
+func (r *GormRepo) ListPayoutBatches(ctx context.Context, status string, limit, offset int) ([]domain.PayoutBatch, int, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	q := r.conn(ctx).Model(&payoutBatchRow{})
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []payoutBatchRow
+	if err := q.Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	out := make([]domain.PayoutBatch, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromPayoutBatchRow(row))
+	}
+	return out, int(total), nil
+}
+
+func (r *GormRepo) MarkPayoutBatchExported(ctx context.Context, id int64) error {
+	now := time.Now()
+	return r.conn(ctx).Model(&payoutBatchRow{}).Where("id = ?", id).Updates(map[string]any{
+		"exported_at": now,
+		"updated_at":  now,
+	}).Error
+}
+
+// SettlePayoutBatchItem moves a pending item to paid or failed. It returns
+// false when the item was already settled.
+func (r *GormRepo) SettlePayoutBatchItem(ctx context.Context, itemID int64, status domain.PayoutItemStatus, reference, reason string) (bool, error) {
+	res := r.conn(ctx).Model(&payoutBatchItemRow{}).
+		Where("id = ? AND status = ?", itemID, string(domain.PayoutItemPending)).
+		Updates(map[string]any{
+			"status":      string(status),
+			"reference":   reference,
+			"fail_reason": reason,
+			"settled_at":  time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *GormRepo) UpdatePayoutBatchStatus(ctx context.Context, id int64, status domain.PayoutBatchStatus, reference string) error {
+	now := time.Now()
+	updates := map[string]any{"status": string(status), "updated_at": now}
+	if reference != "" {
+		updates["reference"] = reference
+	}
+	if status != domain.PayoutBatchPending {
+		updates["settled_at"] = now
+	}
+	return r.conn(ctx).Model(&payoutBatchRow{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func fromPayoutAccountRow(row payoutAccountRow) domain.PayoutAccount {
+	return domain.PayoutAccount{
+		ID:           row.ID,
+		UserID:       row.UserID,
+		Channel:      domain.PayoutChannel(row.Channel),
+		AccountName:  row.AccountName,
+		AccountNo:    row.AccountNo,
+		BankName:     row.BankName,
+		BankBranch:   row.BankBranch,
+		Status:       domain.PayoutAccountStatus(row.Status),
+		ReviewReason: row.ReviewReason,
+		ReviewedBy:   row.ReviewedBy,
+		ReviewedAt:   row.ReviewedAt,
+		CreatedAt:    row.CreatedAt,
+		UpdatedAt:    row.UpdatedAt,
+	}
+}
+
+func fromPayoutBatchRow(row payoutBatchRow) domain.PayoutBatch {
+	return domain.PayoutBatch{
+		ID:          row.ID,
+		Channel:     domain.PayoutChannel(row.Channel),
+		Status:      domain.PayoutBatchStatus(row.Status),
+		ItemCount:   row.ItemCount,
+		TotalAmount: row.TotalAmount,
+		Reference:   row.Reference,
+		Note:        row.Note,
+		CreatedBy:   row.CreatedBy,
+		ExportedAt:  row.ExportedAt,
+		SettledAt:   row.SettledAt,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+}
+
+func fromPayoutBatchItemRow(row payoutBatchItemRow) domain.PayoutBatchItem {
+	return domain.PayoutBatchItem{
+		ID:              row.ID,
+		BatchID:         row.BatchID,
+		WalletOrderID:   row.WalletOrderID,
+		UserID:          row.UserID,
+		Amount:          row.Amount,
+		PayoutAccountID: row.PayoutAccountID,
+		AccountName:     row.AccountName,
+		AccountNo:       row.AccountNo,
+		BankName:        row.BankName,
+		BankBranch:      row.BankBranch,
+		Status:          domain.PayoutItemStatus(row.Status),
+		Reference:       row.Reference,
+		FailReason:      row.FailReason,
+		SettledAt:       row.SettledAt,
+	}
+}
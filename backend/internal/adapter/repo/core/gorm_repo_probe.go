@@ -33,7 +33,7 @@ func (r *GormRepo) CreateProbeNode(ctx context.Context, node *domain.ProbeNode)
 	if row.LastSnapshotJSON == "" {
 		row.LastSnapshotJSON = "{}"
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	node.ID = row.ID
@@ -44,7 +44,7 @@ func (r *GormRepo) CreateProbeNode(ctx context.Context, node *domain.ProbeNode)
 
 func (r *GormRepo) GetProbeNode(ctx context.Context, id int64) (domain.ProbeNode, error) {
 	var row probeNodeRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.ProbeNode{}, r.ensure(err)
 	}
 	return fromProbeNodeRow(row), nil
@@ -52,7 +52,7 @@ func (r *GormRepo) GetProbeNode(ctx context.Context, id int64) (domain.ProbeNode
 
 func (r *GormRepo) GetProbeNodeByAgentID(ctx context.Context, agentID string) (domain.ProbeNode, error) {
 	var row probeNodeRow
-	if err := r.gdb.WithContext(ctx).Where("agent_id = ?", agentID).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("agent_id = ?", agentID).First(&row).Error; err != nil {
 		return domain.ProbeNode{}, r.ensure(err)
 	}
 	return fromProbeNodeRow(row), nil
@@ -65,7 +65,7 @@ func (r *GormRepo) ListProbeNodes(ctx context.Context, filter appshared.ProbeNod
 	if limit > 500 {
 		limit = 500
 	}
-	q := r.gdb.WithContext(ctx).Model(&probeNodeRow{})
+	q := r.conn(ctx).Model(&probeNodeRow{})
 	if strings.TrimSpace(filter.Status) != "" {
 		q = q.Where("status = ?", strings.TrimSpace(filter.Status))
 	}
@@ -89,7 +89,7 @@ func (r *GormRepo) ListProbeNodes(ctx context.Context, filter appshared.ProbeNod
 }
 
 func (r *GormRepo) UpdateProbeNode(ctx context.Context, node domain.ProbeNode) error {
-	return r.gdb.WithContext(ctx).Model(&probeNodeRow{}).Where("id = ?", node.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&probeNodeRow{}).Where("id = ?", node.ID).Updates(map[string]any{
 		"name":        strings.TrimSpace(node.Name),
 		"agent_id":    strings.TrimSpace(node.AgentID),
 		"secret_hash": strings.TrimSpace(node.SecretHash),
@@ -100,7 +100,7 @@ func (r *GormRepo) UpdateProbeNode(ctx context.Context, node domain.ProbeNode) e
 }
 
 func (r *GormRepo) DeleteProbeNode(ctx context.Context, id int64) error {
-	return r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Where("probe_id = ?", id).Delete(&probeLogSessionRow{}).Error; err != nil {
 			return err
 		}
@@ -116,7 +116,7 @@ func (r *GormRepo) DeleteProbeNode(ctx context.Context, id int64) error {
 
 func (r *GormRepo) UpdateProbeNodeStatus(ctx context.Context, id int64, status domain.ProbeStatus, reason string, at time.Time) error {
 	_ = reason
-	return r.gdb.WithContext(ctx).Model(&probeNodeRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&probeNodeRow{}).Where("id = ?", id).Updates(map[string]any{
 		"status":            string(status),
 		"updated_at":        time.Now(),
 		"last_heartbeat_at": clause.Expr{SQL: "COALESCE(last_heartbeat_at, ?)", Vars: []any{at}},
@@ -124,7 +124,7 @@ func (r *GormRepo) UpdateProbeNodeStatus(ctx context.Context, id int64, status d
 }
 
 func (r *GormRepo) UpdateProbeNodeHeartbeat(ctx context.Context, id int64, at time.Time) error {
-	return r.gdb.WithContext(ctx).Model(&probeNodeRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&probeNodeRow{}).Where("id = ?", id).Updates(map[string]any{
 		"last_heartbeat_at": at,
 		"updated_at":        time.Now(),
 	}).Error
@@ -139,7 +139,23 @@ func (r *GormRepo) UpdateProbeNodeSnapshot(ctx context.Context, id int64, at tim
 	if strings.TrimSpace(osType) != "" {
 		updates["os_type"] = strings.TrimSpace(osType)
 	}
-	return r.gdb.WithContext(ctx).Model(&probeNodeRow{}).Where("id = ?", id).Updates(updates).Error
+	return r.conn(ctx).Model(&probeNodeRow{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *GormRepo) UpdateProbeNodeAgentVersion(ctx context.Context, id int64, version string) error {
+	return r.conn(ctx).Model(&probeNodeRow{}).Where("id = ?", id).Updates(map[string]any{
+		"agent_version": strings.TrimSpace(version),
+		"updated_at":    time.Now(),
+	}).Error
+}
+
+func (r *GormRepo) UpdateProbeNodeAgentUpdate(ctx context.Context, id int64, status domain.ProbeAgentUpdateStatus, message string, at time.Time) error {
+	return r.conn(ctx).Model(&probeNodeRow{}).Where("id = ?", id).Updates(map[string]any{
+		"agent_update_status":  string(status),
+		"agent_update_message": strings.TrimSpace(message),
+		"agent_updated_at":     at,
+		"updated_at":           time.Now(),
+	}).Error
 }
 
 func (r *GormRepo) CreateProbeEnrollToken(ctx context.Context, token *domain.ProbeEnrollToken) error {
@@ -149,7 +165,7 @@ func (r *GormRepo) CreateProbeEnrollToken(ctx context.Context, token *domain.Pro
 		ExpiresAt: token.ExpiresAt,
 		UsedAt:    token.UsedAt,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	token.ID = row.ID
@@ -159,7 +175,7 @@ func (r *GormRepo) CreateProbeEnrollToken(ctx context.Context, token *domain.Pro
 
 func (r *GormRepo) GetValidProbeEnrollTokenByHash(ctx context.Context, tokenHash string, now time.Time) (domain.ProbeEnrollToken, error) {
 	var row probeEnrollTokenRow
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
 		First(&row).Error; err != nil {
 		return domain.ProbeEnrollToken{}, r.ensure(err)
@@ -168,11 +184,11 @@ func (r *GormRepo) GetValidProbeEnrollTokenByHash(ctx context.Context, tokenHash
 }
 
 func (r *GormRepo) MarkProbeEnrollTokenUsed(ctx context.Context, id int64, usedAt time.Time) error {
-	return r.gdb.WithContext(ctx).Model(&probeEnrollTokenRow{}).Where("id = ?", id).Update("used_at", usedAt).Error
+	return r.conn(ctx).Model(&probeEnrollTokenRow{}).Where("id = ?", id).Update("used_at", usedAt).Error
 }
 
 func (r *GormRepo) DeleteProbeEnrollTokensByProbe(ctx context.Context, probeID int64) error {
-	return r.gdb.WithContext(ctx).Where("probe_id = ? AND used_at IS NULL", probeID).Delete(&probeEnrollTokenRow{}).Error
+	return r.conn(ctx).Where("probe_id = ? AND used_at IS NULL", probeID).Delete(&probeEnrollTokenRow{}).Error
 }
 
 func (r *GormRepo) CreateProbeStatusEvent(ctx context.Context, ev *domain.ProbeStatusEvent) error {
@@ -182,7 +198,7 @@ func (r *GormRepo) CreateProbeStatusEvent(ctx context.Context, ev *domain.ProbeS
 		At:      ev.At,
 		Reason:  ev.Reason,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	ev.ID = row.ID
@@ -192,7 +208,7 @@ func (r *GormRepo) CreateProbeStatusEvent(ctx context.Context, ev *domain.ProbeS
 
 func (r *GormRepo) ListProbeStatusEvents(ctx context.Context, probeID int64, from, to time.Time) ([]domain.ProbeStatusEvent, error) {
 	var rows []probeStatusEventRow
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Where("probe_id = ? AND at >= ? AND at <= ?", probeID, from, to).
 		Order("at ASC, id ASC").
 		Find(&rows).Error; err != nil {
@@ -207,7 +223,7 @@ func (r *GormRepo) ListProbeStatusEvents(ctx context.Context, probeID int64, fro
 
 func (r *GormRepo) GetLatestProbeStatusEventBefore(ctx context.Context, probeID int64, before time.Time) (domain.ProbeStatusEvent, error) {
 	var row probeStatusEventRow
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Where("probe_id = ? AND at < ?", probeID, before).
 		Order("at DESC, id DESC").
 		First(&row).Error; err != nil {
@@ -217,7 +233,7 @@ func (r *GormRepo) GetLatestProbeStatusEventBefore(ctx context.Context, probeID
 }
 
 func (r *GormRepo) DeleteProbeStatusEventsBefore(ctx context.Context, before time.Time) error {
-	return r.gdb.WithContext(ctx).Where("at < ?", before).Delete(&probeStatusEventRow{}).Error
+	return r.conn(ctx).Where("at < ?", before).Delete(&probeStatusEventRow{}).Error
 }
 
 func (r *GormRepo) CreateProbeLogSession(ctx context.Context, session *domain.ProbeLogSession) error {
@@ -229,7 +245,7 @@ func (r *GormRepo) CreateProbeLogSession(ctx context.Context, session *domain.Pr
 		StartedAt:  session.StartedAt,
 		EndedAt:    session.EndedAt,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	session.ID = row.ID
@@ -239,39 +255,43 @@ func (r *GormRepo) CreateProbeLogSession(ctx context.Context, session *domain.Pr
 
 func (r *GormRepo) GetProbeLogSession(ctx context.Context, id int64) (domain.ProbeLogSession, error) {
 	var row probeLogSessionRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.ProbeLogSession{}, r.ensure(err)
 	}
 	return fromProbeLogSessionRow(row), nil
 }
 
 func (r *GormRepo) UpdateProbeLogSession(ctx context.Context, session domain.ProbeLogSession) error {
-	return r.gdb.WithContext(ctx).Model(&probeLogSessionRow{}).Where("id = ?", session.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&probeLogSessionRow{}).Where("id = ?", session.ID).Updates(map[string]any{
 		"status":   session.Status,
 		"ended_at": session.EndedAt,
 	}).Error
 }
 
 func (r *GormRepo) PurgeProbeLogSessions(ctx context.Context, before time.Time) error {
-	return r.gdb.WithContext(ctx).
+	return r.conn(ctx).
 		Where("created_at < ?", before).
 		Delete(&probeLogSessionRow{}).Error
 }
 
 func fromProbeNodeRow(row probeNodeRow) domain.ProbeNode {
 	return domain.ProbeNode{
-		ID:               row.ID,
-		Name:             row.Name,
-		AgentID:          row.AgentID,
-		SecretHash:       row.SecretHash,
-		Status:           domain.ProbeStatus(row.Status),
-		OSType:           row.OSType,
-		TagsJSON:         row.TagsJSON,
-		LastHeartbeatAt:  row.LastHeartbeatAt,
-		LastSnapshotAt:   row.LastSnapshotAt,
-		LastSnapshotJSON: row.LastSnapshotJSON,
-		CreatedAt:        row.CreatedAt,
-		UpdatedAt:        row.UpdatedAt,
+		ID:                 row.ID,
+		Name:               row.Name,
+		AgentID:            row.AgentID,
+		SecretHash:         row.SecretHash,
+		Status:             domain.ProbeStatus(row.Status),
+		OSType:             row.OSType,
+		TagsJSON:           row.TagsJSON,
+		LastHeartbeatAt:    row.LastHeartbeatAt,
+		LastSnapshotAt:     row.LastSnapshotAt,
+		LastSnapshotJSON:   row.LastSnapshotJSON,
+		AgentVersion:       row.AgentVersion,
+		AgentUpdateStatus:  domain.ProbeAgentUpdateStatus(row.AgentUpdateState),
+		AgentUpdateMessage: row.AgentUpdateMsg,
+		AgentUpdatedAt:     row.AgentUpdatedAt,
+		CreatedAt:          row.CreatedAt,
+		UpdatedAt:          row.UpdatedAt,
 	}
 }
 
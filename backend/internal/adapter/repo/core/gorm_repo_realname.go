@@ -2,22 +2,131 @@ package repo
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"time"
 
 	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/pkg/cryptox"
 )
 
+// encryptedIDPrefix marks ID numbers sealed with the real-name cipher. Rows
+// without it were written before encryption was enabled and are read as is.
+const encryptedIDPrefix = "enc:"
+
+// SetRealNameCipher enables encryption of real-name ID numbers at rest.
+func (r *GormRepo) SetRealNameCipher(cipher *cryptox.AESGCM) {
+	r.idCipher = cipher
+}
+
+func (r *GormRepo) sealIDNumber(idNumber string) (string, error) {
+	if r.idCipher == nil || idNumber == "" || strings.HasPrefix(idNumber, encryptedIDPrefix) {
+		return idNumber, nil
+	}
+	sealed, err := r.idCipher.EncryptToString([]byte(idNumber))
+	if err != nil {
+		return "", err
+	}
+	return encryptedIDPrefix + sealed, nil
+}
+
+func (r *GormRepo) openIDNumber(stored string) (string, error) {
+	if !strings.HasPrefix(stored, encryptedIDPrefix) {
+		return stored, nil
+	}
+	if r.idCipher == nil {
+		return "", domain.ErrNotSupported
+	}
+	plain, err := r.idCipher.DecryptString(strings.TrimPrefix(stored, encryptedIDPrefix))
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// EncryptLegacyIDNumbers seals the ID numbers stored before encryption was
+// enabled and returns how many rows it updated.
+func (r *GormRepo) EncryptLegacyIDNumbers(ctx context.Context) (int, error) {
+	if r.idCipher == nil {
+		return 0, nil
+	}
+	var rows []realnameVerificationRow
+	if err := r.conn(ctx).Select("id", "id_number").
+		Where("id_number <> '' AND id_number NOT LIKE ?", encryptedIDPrefix+"%").
+		Find(&rows).Error; err != nil {
+		return 0, err
+	}
+	for _, row := range rows {
+		sealed, err := r.sealIDNumber(row.IDNumber)
+		if err != nil {
+			return 0, err
+		}
+		if err := r.conn(ctx).Model(&realnameVerificationRow{}).Where("id = ? AND id_number = ?", row.ID, row.IDNumber).
+			Update("id_number", sealed).Error; err != nil {
+			return 0, err
+		}
+	}
+	return len(rows), nil
+}
+
+func (r *GormRepo) fromRealNameRow(row realnameVerificationRow) (domain.RealNameVerification, error) {
+	idNumber, err := r.openIDNumber(row.IDNumber)
+	if err != nil {
+		return domain.RealNameVerification{}, err
+	}
+	var uploadIDs []int64
+	if row.UploadIDs != "" {
+		_ = json.Unmarshal([]byte(row.UploadIDs), &uploadIDs)
+	}
+	return domain.RealNameVerification{
+		ID:         row.ID,
+		UserID:     row.UserID,
+		RealName:   row.RealName,
+		IDNumber:   idNumber,
+		Status:     row.Status,
+		Provider:   row.Provider,
+		Reason:     row.Reason,
+		UploadIDs:  uploadIDs,
+		ReviewedBy: row.ReviewedBy,
+		ReviewedAt: row.ReviewedAt,
+		CreatedAt:  row.CreatedAt,
+		VerifiedAt: row.VerifiedAt,
+	}, nil
+}
+
+func (r *GormRepo) fromRealNameRows(rows []realnameVerificationRow) ([]domain.RealNameVerification, error) {
+	out := make([]domain.RealNameVerification, 0, len(rows))
+	for _, row := range rows {
+		item, err := r.fromRealNameRow(row)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
 func (r *GormRepo) CreateRealNameVerification(ctx context.Context, record *domain.RealNameVerification) error {
+	idNumber, err := r.sealIDNumber(record.IDNumber)
+	if err != nil {
+		return err
+	}
+	uploadIDs := ""
+	if len(record.UploadIDs) > 0 {
+		raw, _ := json.Marshal(record.UploadIDs)
+		uploadIDs = string(raw)
+	}
 	row := realnameVerificationRow{
 		UserID:     record.UserID,
 		RealName:   record.RealName,
-		IDNumber:   record.IDNumber,
+		IDNumber:   idNumber,
 		Status:     record.Status,
 		Provider:   record.Provider,
 		Reason:     record.Reason,
+		UploadIDs:  uploadIDs,
 		VerifiedAt: record.VerifiedAt,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	record.ID = row.ID
@@ -27,24 +136,22 @@ func (r *GormRepo) CreateRealNameVerification(ctx context.Context, record *domai
 
 func (r *GormRepo) GetLatestRealNameVerification(ctx context.Context, userID int64) (domain.RealNameVerification, error) {
 	var row realnameVerificationRow
-	if err := r.gdb.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Limit(1).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("user_id = ?", userID).Order("id DESC").Limit(1).First(&row).Error; err != nil {
 		return domain.RealNameVerification{}, r.ensure(err)
 	}
-	return domain.RealNameVerification{
-		ID:         row.ID,
-		UserID:     row.UserID,
-		RealName:   row.RealName,
-		IDNumber:   row.IDNumber,
-		Status:     row.Status,
-		Provider:   row.Provider,
-		Reason:     row.Reason,
-		CreatedAt:  row.CreatedAt,
-		VerifiedAt: row.VerifiedAt,
-	}, nil
+	return r.fromRealNameRow(row)
+}
+
+func (r *GormRepo) GetRealNameVerification(ctx context.Context, id int64) (domain.RealNameVerification, error) {
+	var row realnameVerificationRow
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+		return domain.RealNameVerification{}, r.ensure(err)
+	}
+	return r.fromRealNameRow(row)
 }
 
 func (r *GormRepo) ListRealNameVerifications(ctx context.Context, userID *int64, limit, offset int) ([]domain.RealNameVerification, int, error) {
-	q := r.gdb.WithContext(ctx).Model(&realnameVerificationRow{})
+	q := r.conn(ctx).Model(&realnameVerificationRow{})
 	if userID != nil {
 		q = q.Where("user_id = ?", *userID)
 	}
@@ -59,27 +166,59 @@ func (r *GormRepo) ListRealNameVerifications(ctx context.Context, userID *int64,
 	if err := q.Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
 		return nil, 0, err
 	}
-	out := make([]domain.RealNameVerification, 0, len(rows))
-	for _, row := range rows {
-		out = append(out, domain.RealNameVerification{
-			ID:         row.ID,
-			UserID:     row.UserID,
-			RealName:   row.RealName,
-			IDNumber:   row.IDNumber,
-			Status:     row.Status,
-			Provider:   row.Provider,
-			Reason:     row.Reason,
-			CreatedAt:  row.CreatedAt,
-			VerifiedAt: row.VerifiedAt,
-		})
+	out, err := r.fromRealNameRows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return out, int(total), nil
+}
+
+func (r *GormRepo) ListRealNameVerificationsByStatus(ctx context.Context, status string, limit, offset int) ([]domain.RealNameVerification, int, error) {
+	q := r.conn(ctx).Model(&realnameVerificationRow{})
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	var rows []realnameVerificationRow
+	if err := q.Order("id ASC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	out, err := r.fromRealNameRows(rows)
+	if err != nil {
+		return nil, 0, err
 	}
 	return out, int(total), nil
 }
 
 func (r *GormRepo) UpdateRealNameStatus(ctx context.Context, id int64, status string, reason string, verifiedAt *time.Time) error {
-	return r.gdb.WithContext(ctx).Model(&realnameVerificationRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&realnameVerificationRow{}).Where("id = ?", id).Updates(map[string]any{
 		"status":      status,
 		"reason":      reason,
 		"verified_at": verifiedAt,
 	}).Error
 }
+
+func (r *GormRepo) ReviewRealNameVerification(ctx context.Context, id int64, status, reason string, reviewerID int64, reviewedAt time.Time) (bool, error) {
+	updates := map[string]any{
+		"status":      status,
+		"reason":      reason,
+		"reviewed_by": reviewerID,
+		"reviewed_at": reviewedAt,
+	}
+	if status == "verified" {
+		updates["verified_at"] = reviewedAt
+	}
+	res := r.conn(ctx).Model(&realnameVerificationRow{}).
+		Where("id = ? AND status = ?", id, domain.RealNameStatusPendingReview).
+		Updates(updates)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
@@ -24,7 +24,7 @@ type revenueAnalyticsJoinRow struct {
 // ListRevenueAnalyticsRows provides a reusable join baseline for analytics queries.
 func (r *GormRepo) ListRevenueAnalyticsRows(ctx context.Context, fromAt, toAt time.Time) ([]revenueAnalyticsJoinRow, error) {
 	var rows []revenueAnalyticsJoinRow
-	err := r.gdb.WithContext(ctx).
+	err := r.conn(ctx).
 		Table("order_payments op").
 		Select(`
 			op.id as payment_id,
@@ -57,7 +57,7 @@ func (r *GormRepo) ListRevenueAnalyticsDetails(
 	limit int,
 	offset int,
 ) ([]revenueAnalyticsJoinRow, int, error) {
-	q := r.gdb.WithContext(ctx).
+	q := r.conn(ctx).
 		Table("order_payments op").
 		Select(`
 			op.id as payment_id,
@@ -36,7 +36,7 @@ func (r *GormRepo) GetSetting(ctx context.Context, key string) (domain.Setting,
 	}
 
 	var m settingModel
-	if err := r.gdb.WithContext(ctx).Where("`key` = ?", key).First(&m).Error; err != nil {
+	if err := r.conn(ctx).Where("`key` = ?", key).First(&m).Error; err != nil {
 		return domain.Setting{}, r.ensure(err)
 	}
 	return domain.Setting{Key: m.Key, ValueJSON: m.ValueJSON, UpdatedAt: m.UpdatedAt}, nil
@@ -48,7 +48,7 @@ func (r *GormRepo) UpsertSetting(ctx context.Context, setting domain.Setting) er
 		return err
 	}
 	m := settingModel{Key: setting.Key, ValueJSON: setting.ValueJSON, UpdatedAt: time.Now()}
-	return r.gdb.WithContext(ctx).
+	return r.conn(ctx).
 		Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "key"}},
 			DoUpdates: clause.AssignmentColumns([]string{"value_json", "updated_at"}),
@@ -59,7 +59,7 @@ func (r *GormRepo) UpsertSetting(ctx context.Context, setting domain.Setting) er
 func (r *GormRepo) ListSettings(ctx context.Context) ([]domain.Setting, error) {
 
 	var models []settingModel
-	if err := r.gdb.WithContext(ctx).Order("`key` ASC").Find(&models).Error; err != nil {
+	if err := r.conn(ctx).Order("`key` ASC").Find(&models).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.Setting, 0, len(models))
@@ -294,7 +294,7 @@ func (r *GormRepo) upsertSMSTemplatesSetting(ctx context.Context, raw string) er
 	if err := json.Unmarshal([]byte(strings.TrimSpace(raw)), &items); err != nil {
 		return appshared.ErrInvalidInput
 	}
-	return r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Where("1 = 1").Delete(&smsTemplateRow{}).Error; err != nil {
 			return err
 		}
@@ -342,7 +342,7 @@ func (r *GormRepo) upsertRobotWebhooksSetting(ctx context.Context, raw string) e
 	if err := json.Unmarshal([]byte(strings.TrimSpace(raw)), &items); err != nil {
 		return appshared.ErrInvalidInput
 	}
-	return r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Where("1 = 1").Delete(&robotWebhookRow{}).Error; err != nil {
 			return err
 		}
@@ -376,7 +376,7 @@ func (r *GormRepo) upsertPackageCapabilitiesSetting(ctx context.Context, raw str
 	if err := json.Unmarshal([]byte(strings.TrimSpace(raw)), &payload); err != nil {
 		return appshared.ErrInvalidInput
 	}
-	return r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Where("1 = 1").Delete(&packageCapabilityRow{}).Error; err != nil {
 			return err
 		}
@@ -429,7 +429,7 @@ func (r *GormRepo) upsertListSetting(ctx context.Context, key string, raw string
 		uniq[v] = struct{}{}
 		normalized = append(normalized, v)
 	}
-	return r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Where("setting_key = ?", key).Delete(&settingListValueRow{}).Error; err != nil {
 			return err
 		}
@@ -483,7 +483,7 @@ func (r *GormRepo) upsertTaskSetting(ctx context.Context, taskKey string, raw st
 	if row.IntervalSec <= 0 {
 		row.IntervalSec = 60
 	}
-	return r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "task_key"}},
 			DoUpdates: clause.AssignmentColumns([]string{"enabled", "strategy", "interval_sec", "daily_at", "updated_at"}),
@@ -496,7 +496,7 @@ func (r *GormRepo) upsertTaskSetting(ctx context.Context, taskKey string, raw st
 
 func (r *GormRepo) upsertLegacySettingOnly(ctx context.Context, key string, value string) error {
 	m := settingModel{Key: key, ValueJSON: value, UpdatedAt: time.Now()}
-	return r.gdb.WithContext(ctx).
+	return r.conn(ctx).
 		Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "key"}},
 			DoUpdates: clause.AssignmentColumns([]string{"value_json", "updated_at"}),
@@ -506,7 +506,7 @@ func (r *GormRepo) upsertLegacySettingOnly(ctx context.Context, key string, valu
 
 func (r *GormRepo) listSMSTemplateRows(ctx context.Context) ([]smsTemplateRow, error) {
 	var rows []smsTemplateRow
-	if err := r.gdb.WithContext(ctx).Order("id ASC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("id ASC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	return rows, nil
@@ -514,7 +514,7 @@ func (r *GormRepo) listSMSTemplateRows(ctx context.Context) ([]smsTemplateRow, e
 
 func (r *GormRepo) listRobotWebhookRows(ctx context.Context) ([]robotWebhookRow, error) {
 	var rows []robotWebhookRow
-	if err := r.gdb.WithContext(ctx).Order("sort_order ASC, id ASC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("sort_order ASC, id ASC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	return rows, nil
@@ -522,7 +522,7 @@ func (r *GormRepo) listRobotWebhookRows(ctx context.Context) ([]robotWebhookRow,
 
 func (r *GormRepo) listPackageCapabilityRows(ctx context.Context) ([]packageCapabilityRow, error) {
 	var rows []packageCapabilityRow
-	if err := r.gdb.WithContext(ctx).Order("package_id ASC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("package_id ASC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	return rows, nil
@@ -530,7 +530,7 @@ func (r *GormRepo) listPackageCapabilityRows(ctx context.Context) ([]packageCapa
 
 func (r *GormRepo) listSettingListValues(ctx context.Context, key string) ([]settingListValueRow, error) {
 	var rows []settingListValueRow
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Where("setting_key = ?", key).
 		Order("sort_order ASC, id ASC").
 		Find(&rows).Error; err != nil {
@@ -541,7 +541,7 @@ func (r *GormRepo) listSettingListValues(ctx context.Context, key string) ([]set
 
 func (r *GormRepo) getScheduledTaskConfigRow(ctx context.Context, taskKey string) (scheduledTaskConfigRow, bool, error) {
 	var row scheduledTaskConfigRow
-	if err := r.gdb.WithContext(ctx).Where("task_key = ?", taskKey).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("task_key = ?", taskKey).First(&row).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return scheduledTaskConfigRow{}, false, nil
 		}
@@ -552,7 +552,7 @@ func (r *GormRepo) getScheduledTaskConfigRow(ctx context.Context, taskKey string
 
 func (r *GormRepo) listScheduledTaskConfigRows(ctx context.Context) ([]scheduledTaskConfigRow, error) {
 	var rows []scheduledTaskConfigRow
-	if err := r.gdb.WithContext(ctx).Order("task_key ASC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("task_key ASC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	return rows, nil
@@ -584,7 +584,7 @@ func (r *GormRepo) UpsertPluginInstallation(ctx context.Context, inst *domain.Pl
 		SignatureStatus: string(inst.SignatureStatus),
 		ConfigCipher:    inst.ConfigCipher,
 	}
-	return r.gdb.WithContext(ctx).
+	return r.conn(ctx).
 		Clauses(clause.OnConflict{
 			Columns: []clause.Column{{Name: "category"}, {Name: "plugin_id"}, {Name: "instance_id"}},
 			DoUpdates: clause.AssignmentColumns([]string{
@@ -599,7 +599,7 @@ func (r *GormRepo) UpsertPluginInstallation(ctx context.Context, inst *domain.Pl
 
 func (r *GormRepo) GetPluginInstallation(ctx context.Context, category, pluginID, instanceID string) (domain.PluginInstallation, error) {
 	var row pluginInstallationRow
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Where("category = ? AND plugin_id = ? AND instance_id = ?", category, pluginID, instanceID).
 		First(&row).Error; err != nil {
 		return domain.PluginInstallation{}, r.ensure(err)
@@ -619,7 +619,7 @@ func (r *GormRepo) GetPluginInstallation(ctx context.Context, category, pluginID
 
 func (r *GormRepo) ListPluginInstallations(ctx context.Context) ([]domain.PluginInstallation, error) {
 	var rows []pluginInstallationRow
-	if err := r.gdb.WithContext(ctx).Order("category ASC, plugin_id ASC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("category ASC, plugin_id ASC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.PluginInstallation, 0, len(rows))
@@ -640,14 +640,14 @@ func (r *GormRepo) ListPluginInstallations(ctx context.Context) ([]domain.Plugin
 }
 
 func (r *GormRepo) DeletePluginInstallation(ctx context.Context, category, pluginID, instanceID string) error {
-	return r.gdb.WithContext(ctx).
+	return r.conn(ctx).
 		Where("category = ? AND plugin_id = ? AND instance_id = ?", category, pluginID, instanceID).
 		Delete(&pluginInstallationRow{}).Error
 }
 
 func (r *GormRepo) ListPluginPaymentMethods(ctx context.Context, category, pluginID, instanceID string) ([]domain.PluginPaymentMethod, error) {
 	var rows []pluginPaymentMethodRow
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Where("category = ? AND plugin_id = ? AND instance_id = ?", category, pluginID, instanceID).
 		Order("method ASC").
 		Find(&rows).Error; err != nil {
@@ -681,7 +681,7 @@ func (r *GormRepo) UpsertPluginPaymentMethod(ctx context.Context, m *domain.Plug
 		Enabled:    boolToInt(m.Enabled),
 		UpdatedAt:  time.Now(),
 	}
-	return r.gdb.WithContext(ctx).
+	return r.conn(ctx).
 		Clauses(clause.OnConflict{
 			Columns: []clause.Column{
 				{Name: "category"},
@@ -696,7 +696,7 @@ func (r *GormRepo) UpsertPluginPaymentMethod(ctx context.Context, m *domain.Plug
 
 func (r *GormRepo) DeletePluginPaymentMethod(ctx context.Context, category, pluginID, instanceID, method string) error {
 
-	return r.gdb.WithContext(ctx).
+	return r.conn(ctx).
 		Where("category = ? AND plugin_id = ? AND instance_id = ? AND method = ?", category, pluginID, instanceID, method).
 		Delete(&pluginPaymentMethodModel{}).Error
 
@@ -705,7 +705,7 @@ func (r *GormRepo) DeletePluginPaymentMethod(ctx context.Context, category, plug
 func (r *GormRepo) ListEmailTemplates(ctx context.Context) ([]domain.EmailTemplate, error) {
 
 	var rows []emailTemplateRow
-	if err := r.gdb.WithContext(ctx).Order("id DESC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("id DESC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.EmailTemplate, 0, len(rows))
@@ -727,7 +727,7 @@ func (r *GormRepo) ListEmailTemplates(ctx context.Context) ([]domain.EmailTempla
 func (r *GormRepo) GetEmailTemplate(ctx context.Context, id int64) (domain.EmailTemplate, error) {
 
 	var row emailTemplateRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.EmailTemplate{}, r.ensure(err)
 	}
 	return domain.EmailTemplate{
@@ -751,20 +751,20 @@ func (r *GormRepo) UpsertEmailTemplate(ctx context.Context, tmpl *domain.EmailTe
 			Body:    tmpl.Body,
 			Enabled: boolToInt(tmpl.Enabled),
 		}
-		if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+		if err := r.conn(ctx).Create(&row).Error; err != nil {
 			return err
 		}
 		tmpl.ID = row.ID
 		return nil
 	}
 	var count int64
-	if err := r.gdb.WithContext(ctx).Model(&emailTemplateRow{}).Where("name = ? AND id != ?", tmpl.Name, tmpl.ID).Count(&count).Error; err != nil {
+	if err := r.conn(ctx).Model(&emailTemplateRow{}).Where("name = ? AND id != ?", tmpl.Name, tmpl.ID).Count(&count).Error; err != nil {
 		return err
 	}
 	if count > 0 {
 		return fmt.Errorf("email template name already exists")
 	}
-	return r.gdb.WithContext(ctx).Model(&emailTemplateRow{}).Where("id = ?", tmpl.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&emailTemplateRow{}).Where("id = ?", tmpl.ID).Updates(map[string]any{
 		"name":       tmpl.Name,
 		"subject":    tmpl.Subject,
 		"body":       tmpl.Body,
@@ -776,14 +776,14 @@ func (r *GormRepo) UpsertEmailTemplate(ctx context.Context, tmpl *domain.EmailTe
 
 func (r *GormRepo) DeleteEmailTemplate(ctx context.Context, id int64) error {
 
-	return r.gdb.WithContext(ctx).Delete(&emailTemplateRow{}, id).Error
+	return r.conn(ctx).Delete(&emailTemplateRow{}, id).Error
 
 }
 
 func (r *GormRepo) CreateSyncLog(ctx context.Context, log *domain.IntegrationSyncLog) error {
 
 	row := integrationSyncLogRow{Target: log.Target, Mode: log.Mode, Status: log.Status, Message: log.Message}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	log.ID = row.ID
@@ -794,7 +794,7 @@ func (r *GormRepo) CreateSyncLog(ctx context.Context, log *domain.IntegrationSyn
 
 func (r *GormRepo) ListSyncLogs(ctx context.Context, target string, limit, offset int) ([]domain.IntegrationSyncLog, int, error) {
 
-	q := r.gdb.WithContext(ctx).Model(&integrationSyncLogRow{})
+	q := r.conn(ctx).Model(&integrationSyncLogRow{})
 	if target != "" {
 		q = q.Where("target = ?", target)
 	}
@@ -822,7 +822,7 @@ func (r *GormRepo) ListSyncLogs(ctx context.Context, target string, limit, offse
 }
 
 func (r *GormRepo) PurgeSyncLogs(ctx context.Context, before time.Time) error {
-	return r.gdb.WithContext(ctx).
+	return r.conn(ctx).
 		Where("created_at < ?", before).
 		Delete(&integrationSyncLogRow{}).Error
 }
@@ -0,0 +1,54 @@
+package repo
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"xiaoheiplay/internal/domain"
+)
+
+func (r *GormRepo) CreateSSHKey(ctx context.Context, key *domain.SSHKey) error {
+	row := sshKeyRow{
+		UserID:      key.UserID,
+		Name:        key.Name,
+		PublicKey:   key.PublicKey,
+		Fingerprint: key.Fingerprint,
+	}
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	key.ID = row.ID
+	key.CreatedAt = row.CreatedAt
+	return nil
+}
+
+func (r *GormRepo) ListSSHKeys(ctx context.Context, userID int64) ([]domain.SSHKey, error) {
+	var rows []sshKeyRow
+	if err := r.conn(ctx).Where("user_id = ?", userID).Order("id ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.SSHKey, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, domain.SSHKey{
+			ID:          row.ID,
+			UserID:      row.UserID,
+			Name:        row.Name,
+			PublicKey:   row.PublicKey,
+			Fingerprint: row.Fingerprint,
+			CreatedAt:   row.CreatedAt,
+		})
+	}
+	return out, nil
+}
+
+func (r *GormRepo) DeleteSSHKey(ctx context.Context, userID, id int64) error {
+	res := r.conn(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&sshKeyRow{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return r.ensure(gorm.ErrRecordNotFound)
+	}
+	return nil
+}
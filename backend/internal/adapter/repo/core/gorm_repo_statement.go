@@ -0,0 +1,43 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"xiaoheiplay/internal/domain"
+)
+
+// ListWalletTransactionsBetween returns the user's transactions in [from, to)
+// in the order they were applied.
+func (r *GormRepo) ListWalletTransactionsBetween(ctx context.Context, userID int64, from, to time.Time) ([]domain.WalletTransaction, error) {
+	var rows []walletTransactionRow
+	if err := r.conn(ctx).
+		Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, from, to).
+		Order("created_at ASC, id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.WalletTransaction, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, domain.WalletTransaction{
+			ID:        row.ID,
+			UserID:    row.UserID,
+			Amount:    row.Amount,
+			Type:      row.Type,
+			RefType:   row.RefType,
+			RefID:     row.RefID,
+			Note:      row.Note,
+			CreatedAt: row.CreatedAt,
+		})
+	}
+	return out, nil
+}
+
+func (r *GormRepo) SumWalletTransactionsSince(ctx context.Context, userID int64, since time.Time) (int64, error) {
+	var sum int64
+	err := r.conn(ctx).Model(&walletTransactionRow{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&sum).Error
+	return sum, err
+}
@@ -11,7 +11,7 @@ import (
 )
 
 func (r *GormRepo) ListTickets(ctx context.Context, filter appshared.TicketFilter) ([]domain.Ticket, int, error) {
-	q := r.gdb.WithContext(ctx).Model(&ticketRow{})
+	q := r.conn(ctx).Model(&ticketRow{})
 	if filter.UserID != nil {
 		q = q.Where("user_id = ?", *filter.UserID)
 	}
@@ -45,7 +45,7 @@ func (r *GormRepo) ListTickets(ctx context.Context, filter appshared.TicketFilte
 			Total    int   `gorm:"column:total"`
 		}
 		var aggs []resourceAgg
-		if err := r.gdb.WithContext(ctx).
+		if err := r.conn(ctx).
 			Model(&ticketResourceRow{}).
 			Select("ticket_id, COUNT(1) AS total").
 			Where("ticket_id IN ?", ids).
@@ -78,11 +78,11 @@ func (r *GormRepo) ListTickets(ctx context.Context, filter appshared.TicketFilte
 
 func (r *GormRepo) GetTicket(ctx context.Context, id int64) (domain.Ticket, error) {
 	var row ticketRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.Ticket{}, r.ensure(err)
 	}
 	var resourceCount int64
-	if err := r.gdb.WithContext(ctx).Model(&ticketResourceRow{}).Where("ticket_id = ?", id).Count(&resourceCount).Error; err != nil {
+	if err := r.conn(ctx).Model(&ticketResourceRow{}).Where("ticket_id = ?", id).Count(&resourceCount).Error; err != nil {
 		return domain.Ticket{}, err
 	}
 	return domain.Ticket{
@@ -101,7 +101,7 @@ func (r *GormRepo) GetTicket(ctx context.Context, id int64) (domain.Ticket, erro
 }
 
 func (r *GormRepo) CreateTicketWithDetails(ctx context.Context, ticket *domain.Ticket, message *domain.TicketMessage, resources []domain.TicketResource) error {
-	return r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		tRow := ticketRow{
 			UserID:        ticket.UserID,
 			Subject:       ticket.Subject,
@@ -158,13 +158,13 @@ func (r *GormRepo) AddTicketMessage(ctx context.Context, message *domain.TicketM
 		SenderQQ:   message.SenderQQ,
 		Content:    message.Content,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	now := time.Now()
 	message.ID = row.ID
 	message.CreatedAt = row.CreatedAt
-	return r.gdb.WithContext(ctx).Model(&ticketRow{}).Where("id = ?", message.TicketID).Updates(map[string]any{
+	return r.conn(ctx).Model(&ticketRow{}).Where("id = ?", message.TicketID).Updates(map[string]any{
 		"last_reply_at":   now,
 		"last_reply_by":   message.SenderID,
 		"last_reply_role": message.SenderRole,
@@ -174,7 +174,7 @@ func (r *GormRepo) AddTicketMessage(ctx context.Context, message *domain.TicketM
 
 func (r *GormRepo) ListTicketMessages(ctx context.Context, ticketID int64) ([]domain.TicketMessage, error) {
 	var rows []ticketMessageRow
-	if err := r.gdb.WithContext(ctx).Where("ticket_id = ?", ticketID).Order("id ASC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Where("ticket_id = ?", ticketID).Order("id ASC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.TicketMessage, 0, len(rows))
@@ -195,7 +195,7 @@ func (r *GormRepo) ListTicketMessages(ctx context.Context, ticketID int64) ([]do
 
 func (r *GormRepo) ListTicketResources(ctx context.Context, ticketID int64) ([]domain.TicketResource, error) {
 	var rows []ticketResourceRow
-	if err := r.gdb.WithContext(ctx).Where("ticket_id = ?", ticketID).Order("id ASC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Where("ticket_id = ?", ticketID).Order("id ASC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.TicketResource, 0, len(rows))
@@ -213,7 +213,7 @@ func (r *GormRepo) ListTicketResources(ctx context.Context, ticketID int64) ([]d
 }
 
 func (r *GormRepo) UpdateTicket(ctx context.Context, ticket domain.Ticket) error {
-	return r.gdb.WithContext(ctx).Model(&ticketRow{}).Where("id = ?", ticket.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&ticketRow{}).Where("id = ?", ticket.ID).Updates(map[string]any{
 		"subject":    ticket.Subject,
 		"status":     ticket.Status,
 		"closed_at":  ticket.ClosedAt,
@@ -222,7 +222,7 @@ func (r *GormRepo) UpdateTicket(ctx context.Context, ticket domain.Ticket) error
 }
 
 func (r *GormRepo) DeleteTicket(ctx context.Context, id int64) error {
-	return r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Where("ticket_id = ?", id).Delete(&ticketMessageRow{}).Error; err != nil {
 			return err
 		}
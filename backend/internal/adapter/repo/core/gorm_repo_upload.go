@@ -15,7 +15,7 @@ func (r *GormRepo) CreateUpload(ctx context.Context, upload *domain.Upload) erro
 		Size:       upload.Size,
 		UploaderID: upload.UploaderID,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	upload.ID = row.ID
@@ -27,7 +27,9 @@ func (r *GormRepo) ListUploads(ctx context.Context, limit, offset int) ([]domain
 	if limit <= 0 {
 		limit = 20
 	}
-	q := r.gdb.WithContext(ctx).Model(&uploadRow{})
+	// Private uploads such as real-name ID images have no public URL and
+	// stay out of the media library.
+	q := r.conn(ctx).Model(&uploadRow{}).Where("url <> ''")
 	var total int64
 	if err := q.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -51,3 +53,20 @@ func (r *GormRepo) ListUploads(ctx context.Context, limit, offset int) ([]domain
 	}
 	return out, int(total), nil
 }
+
+func (r *GormRepo) GetUpload(ctx context.Context, id int64) (domain.Upload, error) {
+	var row uploadRow
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+		return domain.Upload{}, r.ensure(err)
+	}
+	return domain.Upload{
+		ID:         row.ID,
+		Name:       row.Name,
+		Path:       row.Path,
+		URL:        row.URL,
+		Mime:       row.Mime,
+		Size:       row.Size,
+		UploaderID: row.UploaderID,
+		CreatedAt:  row.CreatedAt,
+	}, nil
+}
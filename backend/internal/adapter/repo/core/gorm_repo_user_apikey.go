@@ -16,7 +16,7 @@ func (r *GormRepo) CreateUserAPIKey(ctx context.Context, key *domain.UserAPIKey)
 		Status:     string(key.Status),
 		ScopesJSON: key.ScopesJSON,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	key.ID = row.ID
@@ -27,7 +27,7 @@ func (r *GormRepo) CreateUserAPIKey(ctx context.Context, key *domain.UserAPIKey)
 
 func (r *GormRepo) GetUserAPIKeyByAKID(ctx context.Context, akid string) (domain.UserAPIKey, error) {
 	var row userAPIKeyRow
-	if err := r.gdb.WithContext(ctx).Where("akid = ?", akid).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("akid = ?", akid).First(&row).Error; err != nil {
 		return domain.UserAPIKey{}, r.ensure(err)
 	}
 	return domain.UserAPIKey{
@@ -46,11 +46,11 @@ func (r *GormRepo) GetUserAPIKeyByAKID(ctx context.Context, akid string) (domain
 
 func (r *GormRepo) ListUserAPIKeys(ctx context.Context, userID int64, limit, offset int) ([]domain.UserAPIKey, int, error) {
 	var total int64
-	if err := r.gdb.WithContext(ctx).Model(&userAPIKeyRow{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+	if err := r.conn(ctx).Model(&userAPIKeyRow{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 	var rows []userAPIKeyRow
-	if err := r.gdb.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Where("user_id = ?", userID).Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
 		return nil, 0, err
 	}
 	out := make([]domain.UserAPIKey, 0, len(rows))
@@ -72,16 +72,16 @@ func (r *GormRepo) ListUserAPIKeys(ctx context.Context, userID int64, limit, off
 }
 
 func (r *GormRepo) UpdateUserAPIKeyStatus(ctx context.Context, userID, id int64, status domain.APIKeyStatus) error {
-	return r.gdb.WithContext(ctx).Model(&userAPIKeyRow{}).Where("id = ? AND user_id = ?", id, userID).Updates(map[string]any{
+	return r.conn(ctx).Model(&userAPIKeyRow{}).Where("id = ? AND user_id = ?", id, userID).Updates(map[string]any{
 		"status":     string(status),
 		"updated_at": time.Now(),
 	}).Error
 }
 
 func (r *GormRepo) DeleteUserAPIKey(ctx context.Context, userID, id int64) error {
-	return r.gdb.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&userAPIKeyRow{}).Error
+	return r.conn(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&userAPIKeyRow{}).Error
 }
 
 func (r *GormRepo) TouchUserAPIKey(ctx context.Context, id int64) error {
-	return r.gdb.WithContext(ctx).Model(&userAPIKeyRow{}).Where("id = ?", id).Update("last_used_at", time.Now()).Error
+	return r.conn(ctx).Model(&userAPIKeyRow{}).Where("id = ?", id).Update("last_used_at", time.Now()).Error
 }
@@ -11,7 +11,7 @@ import (
 
 func (r *GormRepo) ListUserTierGroups(ctx context.Context) ([]domain.UserTierGroup, error) {
 	var rows []userTierGroupRow
-	if err := r.gdb.WithContext(ctx).Order("priority DESC, id ASC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("priority DESC, id ASC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.UserTierGroup, 0, len(rows))
@@ -33,7 +33,7 @@ func (r *GormRepo) ListUserTierGroups(ctx context.Context) ([]domain.UserTierGro
 
 func (r *GormRepo) GetUserTierGroup(ctx context.Context, id int64) (domain.UserTierGroup, error) {
 	var row userTierGroupRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.UserTierGroup{}, r.ensure(err)
 	}
 	return domain.UserTierGroup{
@@ -58,7 +58,7 @@ func (r *GormRepo) CreateUserTierGroup(ctx context.Context, group *domain.UserTi
 		AutoApproveEnabled: boolToInt(group.AutoApproveEnabled),
 		IsDefault:          boolToInt(group.IsDefault),
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	group.ID = row.ID
@@ -68,7 +68,7 @@ func (r *GormRepo) CreateUserTierGroup(ctx context.Context, group *domain.UserTi
 }
 
 func (r *GormRepo) UpdateUserTierGroup(ctx context.Context, group domain.UserTierGroup) error {
-	return r.gdb.WithContext(ctx).Model(&userTierGroupRow{}).Where("id = ?", group.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&userTierGroupRow{}).Where("id = ?", group.ID).Updates(map[string]any{
 		"name":                 group.Name,
 		"color":                group.Color,
 		"icon":                 group.Icon,
@@ -80,12 +80,12 @@ func (r *GormRepo) UpdateUserTierGroup(ctx context.Context, group domain.UserTie
 }
 
 func (r *GormRepo) DeleteUserTierGroup(ctx context.Context, id int64) error {
-	return r.gdb.WithContext(ctx).Delete(&userTierGroupRow{}, "id = ?", id).Error
+	return r.conn(ctx).Delete(&userTierGroupRow{}, "id = ?", id).Error
 }
 
 func (r *GormRepo) ListUserTierDiscountRules(ctx context.Context, groupID int64) ([]domain.UserTierDiscountRule, error) {
 	var rows []userTierDiscountRuleRow
-	if err := r.gdb.WithContext(ctx).Where("group_id = ?", groupID).Order("id ASC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Where("group_id = ?", groupID).Order("id ASC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.UserTierDiscountRule, 0, len(rows))
@@ -98,6 +98,7 @@ func (r *GormRepo) ListUserTierDiscountRules(ctx context.Context, groupID int64)
 			RegionID:         row.RegionID,
 			PlanGroupID:      row.PlanGroupID,
 			PackageID:        row.PackageID,
+			AddonID:          row.AddonID,
 			DiscountPermille: row.DiscountPermille,
 			FixedPrice:       row.FixedPrice,
 			AddCorePermille:  row.AddCorePermille,
@@ -119,6 +120,7 @@ func (r *GormRepo) CreateUserTierDiscountRule(ctx context.Context, rule *domain.
 		RegionID:         rule.RegionID,
 		PlanGroupID:      rule.PlanGroupID,
 		PackageID:        rule.PackageID,
+		AddonID:          rule.AddonID,
 		DiscountPermille: rule.DiscountPermille,
 		FixedPrice:       rule.FixedPrice,
 		AddCorePermille:  rule.AddCorePermille,
@@ -126,7 +128,7 @@ func (r *GormRepo) CreateUserTierDiscountRule(ctx context.Context, rule *domain.
 		AddDiskPermille:  rule.AddDiskPermille,
 		AddBWPermille:    rule.AddBWPermille,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	rule.ID = row.ID
@@ -136,13 +138,14 @@ func (r *GormRepo) CreateUserTierDiscountRule(ctx context.Context, rule *domain.
 }
 
 func (r *GormRepo) UpdateUserTierDiscountRule(ctx context.Context, rule domain.UserTierDiscountRule) error {
-	return r.gdb.WithContext(ctx).Model(&userTierDiscountRuleRow{}).Where("id = ?", rule.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&userTierDiscountRuleRow{}).Where("id = ?", rule.ID).Updates(map[string]any{
 		"group_id":          rule.GroupID,
 		"scope":             string(rule.Scope),
 		"goods_type_id":     rule.GoodsTypeID,
 		"region_id":         rule.RegionID,
 		"plan_group_id":     rule.PlanGroupID,
 		"package_id":        rule.PackageID,
+		"addon_id":          rule.AddonID,
 		"discount_permille": rule.DiscountPermille,
 		"fixed_price":       rule.FixedPrice,
 		"add_core_permille": rule.AddCorePermille,
@@ -154,12 +157,12 @@ func (r *GormRepo) UpdateUserTierDiscountRule(ctx context.Context, rule domain.U
 }
 
 func (r *GormRepo) DeleteUserTierDiscountRule(ctx context.Context, id int64) error {
-	return r.gdb.WithContext(ctx).Delete(&userTierDiscountRuleRow{}, "id = ?", id).Error
+	return r.conn(ctx).Delete(&userTierDiscountRuleRow{}, "id = ?", id).Error
 }
 
 func (r *GormRepo) ListUserTierAutoRules(ctx context.Context, groupID int64) ([]domain.UserTierAutoRule, error) {
 	var rows []userTierAutoRuleRow
-	if err := r.gdb.WithContext(ctx).Where("group_id = ?", groupID).Order("sort_order ASC, id ASC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Where("group_id = ?", groupID).Order("sort_order ASC, id ASC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.UserTierAutoRule, 0, len(rows))
@@ -184,7 +187,7 @@ func (r *GormRepo) CreateUserTierAutoRule(ctx context.Context, rule *domain.User
 		ConditionsJSON: rule.ConditionsJSON,
 		SortOrder:      rule.SortOrder,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	rule.ID = row.ID
@@ -194,7 +197,7 @@ func (r *GormRepo) CreateUserTierAutoRule(ctx context.Context, rule *domain.User
 }
 
 func (r *GormRepo) UpdateUserTierAutoRule(ctx context.Context, rule domain.UserTierAutoRule) error {
-	return r.gdb.WithContext(ctx).Model(&userTierAutoRuleRow{}).Where("id = ?", rule.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&userTierAutoRuleRow{}).Where("id = ?", rule.ID).Updates(map[string]any{
 		"group_id":        rule.GroupID,
 		"duration_days":   rule.DurationDays,
 		"conditions_json": rule.ConditionsJSON,
@@ -204,12 +207,12 @@ func (r *GormRepo) UpdateUserTierAutoRule(ctx context.Context, rule domain.UserT
 }
 
 func (r *GormRepo) DeleteUserTierAutoRule(ctx context.Context, id int64) error {
-	return r.gdb.WithContext(ctx).Delete(&userTierAutoRuleRow{}, "id = ?", id).Error
+	return r.conn(ctx).Delete(&userTierAutoRuleRow{}, "id = ?", id).Error
 }
 
 func (r *GormRepo) GetUserTierMembership(ctx context.Context, userID int64) (domain.UserTierMembership, error) {
 	var row userTierMembershipRow
-	if err := r.gdb.WithContext(ctx).Where("user_id = ?", userID).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("user_id = ?", userID).First(&row).Error; err != nil {
 		return domain.UserTierMembership{}, r.ensure(err)
 	}
 	return domain.UserTierMembership{
@@ -229,7 +232,7 @@ func (r *GormRepo) UpsertUserTierMembership(ctx context.Context, item *domain.Us
 		ExpiresAt: item.ExpiresAt,
 		UpdatedAt: time.Now(),
 	}
-	if err := r.gdb.WithContext(ctx).Clauses(clause.OnConflict{
+	if err := r.conn(ctx).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "user_id"}},
 		DoUpdates: clause.AssignmentColumns([]string{"group_id", "source", "expires_at", "updated_at"}),
 	}).Create(&row).Error; err != nil {
@@ -240,7 +243,7 @@ func (r *GormRepo) UpsertUserTierMembership(ctx context.Context, item *domain.Us
 }
 
 func (r *GormRepo) ClearUserTierMembership(ctx context.Context, userID int64) error {
-	return r.gdb.WithContext(ctx).Delete(&userTierMembershipRow{}, "user_id = ?", userID).Error
+	return r.conn(ctx).Delete(&userTierMembershipRow{}, "user_id = ?", userID).Error
 }
 
 func (r *GormRepo) ListExpiredUserTierMemberships(ctx context.Context, now time.Time, limit int) ([]domain.UserTierMembership, error) {
@@ -248,7 +251,7 @@ func (r *GormRepo) ListExpiredUserTierMemberships(ctx context.Context, now time.
 		limit = 200
 	}
 	var rows []userTierMembershipRow
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Where("expires_at IS NOT NULL AND expires_at <= ?", now).
 		Order("updated_at ASC").
 		Limit(limit).
@@ -270,7 +273,7 @@ func (r *GormRepo) ListExpiredUserTierMemberships(ctx context.Context, now time.
 
 func (r *GormRepo) GetUserTierPriceCache(ctx context.Context, groupID int64, packageID int64) (domain.UserTierPriceCache, error) {
 	var row userTierPriceCacheRow
-	if err := r.gdb.WithContext(ctx).Where("group_id = ? AND package_id = ?", groupID, packageID).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("group_id = ? AND package_id = ?", groupID, packageID).First(&row).Error; err != nil {
 		return domain.UserTierPriceCache{}, r.ensure(err)
 	}
 	return domain.UserTierPriceCache{
@@ -287,7 +290,7 @@ func (r *GormRepo) GetUserTierPriceCache(ctx context.Context, groupID int64, pac
 }
 
 func (r *GormRepo) DeleteUserTierPriceCachesByGroup(ctx context.Context, groupID int64) error {
-	return r.gdb.WithContext(ctx).Where("group_id = ?", groupID).Delete(&userTierPriceCacheRow{}).Error
+	return r.conn(ctx).Where("group_id = ?", groupID).Delete(&userTierPriceCacheRow{}).Error
 }
 
 func (r *GormRepo) UpsertUserTierPriceCaches(ctx context.Context, items []domain.UserTierPriceCache) error {
@@ -308,7 +311,7 @@ func (r *GormRepo) UpsertUserTierPriceCaches(ctx context.Context, items []domain
 			UpdatedAt:    now,
 		})
 	}
-	return r.gdb.WithContext(ctx).Clauses(clause.OnConflict{
+	return r.conn(ctx).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "group_id"}, {Name: "package_id"}},
 		DoUpdates: clause.AssignmentColumns([]string{"monthly_price", "unit_core", "unit_mem", "unit_disk", "unit_bw", "updated_at"}),
 	}).Create(&rows).Error
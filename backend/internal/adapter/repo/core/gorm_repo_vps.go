@@ -10,7 +10,7 @@ import (
 func (r *GormRepo) CreateInstance(ctx context.Context, inst *domain.VPSInstance) error {
 
 	row := toVPSInstanceRow(*inst)
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	*inst = fromVPSInstanceRow(row)
@@ -21,7 +21,7 @@ func (r *GormRepo) CreateInstance(ctx context.Context, inst *domain.VPSInstance)
 func (r *GormRepo) GetInstance(ctx context.Context, id int64) (domain.VPSInstance, error) {
 
 	var row vpsInstanceRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.VPSInstance{}, r.ensure(err)
 	}
 	return fromVPSInstanceRow(row), nil
@@ -31,7 +31,7 @@ func (r *GormRepo) GetInstance(ctx context.Context, id int64) (domain.VPSInstanc
 func (r *GormRepo) GetInstanceByOrderItem(ctx context.Context, orderItemID int64) (domain.VPSInstance, error) {
 
 	var row vpsInstanceRow
-	if err := r.gdb.WithContext(ctx).Where("order_item_id = ?", orderItemID).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("order_item_id = ?", orderItemID).First(&row).Error; err != nil {
 		return domain.VPSInstance{}, r.ensure(err)
 	}
 	return fromVPSInstanceRow(row), nil
@@ -41,7 +41,7 @@ func (r *GormRepo) GetInstanceByOrderItem(ctx context.Context, orderItemID int64
 func (r *GormRepo) ListInstancesByUser(ctx context.Context, userID int64) ([]domain.VPSInstance, error) {
 
 	var rows []vpsInstanceRow
-	if err := r.gdb.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Where("user_id = ?", userID).Order("id DESC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.VPSInstance, 0, len(rows))
@@ -55,11 +55,11 @@ func (r *GormRepo) ListInstancesByUser(ctx context.Context, userID int64) ([]dom
 func (r *GormRepo) ListInstances(ctx context.Context, limit, offset int) ([]domain.VPSInstance, int, error) {
 
 	var total int64
-	if err := r.gdb.WithContext(ctx).Model(&vpsInstanceRow{}).Count(&total).Error; err != nil {
+	if err := r.conn(ctx).Model(&vpsInstanceRow{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 	var rows []vpsInstanceRow
-	if err := r.gdb.WithContext(ctx).Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
 		return nil, 0, err
 	}
 	out := make([]domain.VPSInstance, 0, len(rows))
@@ -73,7 +73,7 @@ func (r *GormRepo) ListInstances(ctx context.Context, limit, offset int) ([]doma
 func (r *GormRepo) ListInstancesExpiring(ctx context.Context, before time.Time) ([]domain.VPSInstance, error) {
 
 	var rows []vpsInstanceRow
-	if err := r.gdb.WithContext(ctx).Where("expire_at IS NOT NULL AND expire_at <= ?", before).Order("expire_at ASC").Find(&rows).Error; err != nil {
+	if err := r.conn(ctx).Where("expire_at IS NOT NULL AND expire_at <= ?", before).Order("expire_at ASC").Find(&rows).Error; err != nil {
 		return nil, err
 	}
 	out := make([]domain.VPSInstance, 0, len(rows))
@@ -86,13 +86,21 @@ func (r *GormRepo) ListInstancesExpiring(ctx context.Context, before time.Time)
 
 func (r *GormRepo) DeleteInstance(ctx context.Context, id int64) error {
 
-	return r.gdb.WithContext(ctx).Delete(&vpsInstanceRow{}, id).Error
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("vps_id = ?", id).Delete(&vpsBackupPolicyRow{}).Error; err != nil {
+			return err
+		}
+		if err := releaseInstanceIPAddresses(tx, id); err != nil {
+			return err
+		}
+		return tx.Delete(&vpsInstanceRow{}, id).Error
+	})
 
 }
 
 func (r *GormRepo) UpdateInstanceStatus(ctx context.Context, id int64, status domain.VPSStatus, automationState int) error {
 
-	return r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Model(&vpsInstanceRow{}).Where("id = ?", id).Updates(map[string]any{
 			"status":           status,
 			"automation_state": automationState,
@@ -132,7 +140,7 @@ func (r *GormRepo) UpdateInstanceStatus(ctx context.Context, id int64, status do
 
 func (r *GormRepo) UpdateInstanceAdminStatus(ctx context.Context, id int64, status domain.VPSAdminStatus) error {
 
-	return r.gdb.WithContext(ctx).Model(&vpsInstanceRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&vpsInstanceRow{}).Where("id = ?", id).Updates(map[string]any{
 		"admin_status": status,
 		"updated_at":   time.Now(),
 	}).Error
@@ -141,7 +149,7 @@ func (r *GormRepo) UpdateInstanceAdminStatus(ctx context.Context, id int64, stat
 
 func (r *GormRepo) UpdateInstanceExpireAt(ctx context.Context, id int64, expireAt time.Time) error {
 
-	return r.gdb.WithContext(ctx).Model(&vpsInstanceRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&vpsInstanceRow{}).Where("id = ?", id).Updates(map[string]any{
 		"expire_at":  expireAt,
 		"updated_at": time.Now(),
 	}).Error
@@ -150,7 +158,7 @@ func (r *GormRepo) UpdateInstanceExpireAt(ctx context.Context, id int64, expireA
 
 func (r *GormRepo) UpdateInstancePanelCache(ctx context.Context, id int64, panelURL string) error {
 
-	return r.gdb.WithContext(ctx).Model(&vpsInstanceRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&vpsInstanceRow{}).Where("id = ?", id).Updates(map[string]any{
 		"panel_url_cache": panelURL,
 		"updated_at":      time.Now(),
 	}).Error
@@ -159,7 +167,7 @@ func (r *GormRepo) UpdateInstancePanelCache(ctx context.Context, id int64, panel
 
 func (r *GormRepo) UpdateInstanceSpec(ctx context.Context, id int64, specJSON string) error {
 
-	return r.gdb.WithContext(ctx).Model(&vpsInstanceRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&vpsInstanceRow{}).Where("id = ?", id).Updates(map[string]any{
 		"spec_json":  specJSON,
 		"updated_at": time.Now(),
 	}).Error
@@ -168,7 +176,7 @@ func (r *GormRepo) UpdateInstanceSpec(ctx context.Context, id int64, specJSON st
 
 func (r *GormRepo) UpdateInstanceAccessInfo(ctx context.Context, id int64, accessJSON string) error {
 
-	return r.gdb.WithContext(ctx).Model(&vpsInstanceRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&vpsInstanceRow{}).Where("id = ?", id).Updates(map[string]any{
 		"access_info_json": accessJSON,
 		"updated_at":       time.Now(),
 	}).Error
@@ -177,7 +185,7 @@ func (r *GormRepo) UpdateInstanceAccessInfo(ctx context.Context, id int64, acces
 
 func (r *GormRepo) UpdateInstanceEmergencyRenewAt(ctx context.Context, id int64, at time.Time) error {
 
-	return r.gdb.WithContext(ctx).Model(&vpsInstanceRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&vpsInstanceRow{}).Where("id = ?", id).Updates(map[string]any{
 		"last_emergency_renew_at": at,
 		"updated_at":              time.Now(),
 	}).Error
@@ -186,7 +194,7 @@ func (r *GormRepo) UpdateInstanceEmergencyRenewAt(ctx context.Context, id int64,
 
 func (r *GormRepo) UpdateInstanceLocal(ctx context.Context, inst domain.VPSInstance) error {
 
-	return r.gdb.WithContext(ctx).Model(&vpsInstanceRow{}).Where("id = ?", inst.ID).Updates(map[string]any{
+	return r.conn(ctx).Model(&vpsInstanceRow{}).Where("id = ?", inst.ID).Updates(map[string]any{
 		"automation_instance_id": inst.AutomationInstanceID,
 		"goods_type_id":          inst.GoodsTypeID,
 		"name":                   inst.Name,
@@ -0,0 +1,134 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"xiaoheiplay/internal/domain"
+)
+
+func (r *GormRepo) ListVPSBackupPolicies(ctx context.Context, vpsID int64) ([]domain.VPSBackupPolicy, error) {
+	var rows []vpsBackupPolicyRow
+	if err := r.conn(ctx).Where("vps_id = ?", vpsID).Order("kind ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.VPSBackupPolicy, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromVPSBackupPolicyRow(row))
+	}
+	return out, nil
+}
+
+func (r *GormRepo) GetVPSBackupPolicy(ctx context.Context, vpsID int64, kind domain.VPSBackupPolicyKind) (domain.VPSBackupPolicy, error) {
+	var row vpsBackupPolicyRow
+	if err := r.conn(ctx).Where("vps_id = ? AND kind = ?", vpsID, string(kind)).First(&row).Error; err != nil {
+		return domain.VPSBackupPolicy{}, r.ensure(err)
+	}
+	return fromVPSBackupPolicyRow(row), nil
+}
+
+func (r *GormRepo) UpsertVPSBackupPolicy(ctx context.Context, policy *domain.VPSBackupPolicy) error {
+	row := vpsBackupPolicyRow{
+		VPSID:     policy.VPSID,
+		UserID:    policy.UserID,
+		Kind:      string(policy.Kind),
+		Enabled:   boolToInt(policy.Enabled),
+		Weekday:   policy.Weekday,
+		RunAt:     policy.RunAt,
+		Retention: policy.Retention,
+		NextRunAt: policy.NextRunAt,
+	}
+	if err := r.conn(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "vps_id"}, {Name: "kind"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"user_id", "enabled", "weekday", "run_at", "retention", "next_run_at", "updated_at",
+			}),
+		}).
+		Create(&row).Error; err != nil {
+		return err
+	}
+	got, err := r.GetVPSBackupPolicy(ctx, policy.VPSID, policy.Kind)
+	if err != nil {
+		return err
+	}
+	*policy = got
+	return nil
+}
+
+func (r *GormRepo) DeleteVPSBackupPolicy(ctx context.Context, vpsID int64, kind domain.VPSBackupPolicyKind) error {
+	res := r.conn(ctx).Where("vps_id = ? AND kind = ?", vpsID, string(kind)).Delete(&vpsBackupPolicyRow{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return r.ensure(gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+func (r *GormRepo) ListDueVPSBackupPolicies(ctx context.Context, now time.Time, limit int) ([]domain.VPSBackupPolicy, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	var rows []vpsBackupPolicyRow
+	if err := r.conn(ctx).
+		Where("enabled = ? AND next_run_at <= ?", 1, now).
+		Order("next_run_at ASC, id ASC").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.VPSBackupPolicy, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromVPSBackupPolicyRow(row))
+	}
+	return out, nil
+}
+
+func (r *GormRepo) UpdateVPSBackupPolicyRun(ctx context.Context, policy domain.VPSBackupPolicy) error {
+	return r.conn(ctx).Model(&vpsBackupPolicyRow{}).Where("id = ?", policy.ID).Updates(map[string]any{
+		"next_run_at":      policy.NextRunAt,
+		"last_run_at":      policy.LastRunAt,
+		"last_status":      policy.LastStatus,
+		"last_error":       policy.LastError,
+		"managed_ids_json": managedIDsJSON(policy.ManagedIDs),
+		"updated_at":       time.Now(),
+	}).Error
+}
+
+func fromVPSBackupPolicyRow(row vpsBackupPolicyRow) domain.VPSBackupPolicy {
+	var managed []int64
+	if row.ManagedIDs != "" {
+		_ = json.Unmarshal([]byte(row.ManagedIDs), &managed)
+	}
+	return domain.VPSBackupPolicy{
+		ID:         row.ID,
+		VPSID:      row.VPSID,
+		UserID:     row.UserID,
+		Kind:       domain.VPSBackupPolicyKind(row.Kind),
+		Enabled:    row.Enabled == 1,
+		Weekday:    row.Weekday,
+		RunAt:      row.RunAt,
+		Retention:  row.Retention,
+		NextRunAt:  row.NextRunAt,
+		LastRunAt:  row.LastRunAt,
+		LastStatus: row.LastStatus,
+		LastError:  row.LastError,
+		ManagedIDs: managed,
+		CreatedAt:  row.CreatedAt,
+		UpdatedAt:  row.UpdatedAt,
+	}
+}
+
+func managedIDsJSON(ids []int64) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	raw, _ := json.Marshal(ids)
+	return string(raw)
+}
@@ -0,0 +1,197 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"xiaoheiplay/internal/domain"
+)
+
+func (r *GormRepo) MatchVPSBulkTargets(ctx context.Context, filter domain.VPSBulkFilter, limit int) ([]int64, error) {
+	q := r.conn(ctx).Model(&vpsInstanceRow{})
+	if len(filter.IDs) > 0 {
+		q = q.Where("id IN ?", filter.IDs)
+	}
+	if filter.LineID > 0 {
+		q = q.Where("line_id = ?", filter.LineID)
+	}
+	if filter.RegionID > 0 {
+		q = q.Where("region_id = ?", filter.RegionID)
+	}
+	if filter.PackageID > 0 {
+		q = q.Where("package_id = ?", filter.PackageID)
+	}
+	if filter.UserID > 0 {
+		q = q.Where("user_id = ?", filter.UserID)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	var ids []int64
+	if err := q.Order("id ASC").Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *GormRepo) CreateVPSBulkJob(ctx context.Context, job *domain.VPSBulkJob, vpsIDs []int64) error {
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		row := vpsBulkJobRow{
+			AdminID:     job.AdminID,
+			Action:      string(job.Action),
+			ParamsJSON:  job.ParamsJSON,
+			FilterJSON:  job.FilterJSON,
+			Concurrency: job.Concurrency,
+			Status:      string(job.Status),
+			Total:       len(vpsIDs),
+		}
+		if err := tx.Create(&row).Error; err != nil {
+			return err
+		}
+		items := make([]vpsBulkJobItemRow, 0, len(vpsIDs))
+		for _, id := range vpsIDs {
+			items = append(items, vpsBulkJobItemRow{JobID: row.ID, VPSID: id, Status: string(domain.VPSBulkItemStatusPending)})
+		}
+		if len(items) > 0 {
+			if err := tx.CreateInBatches(&items, 200).Error; err != nil {
+				return err
+			}
+		}
+		*job = fromVPSBulkJobRow(row)
+		return nil
+	})
+}
+
+func (r *GormRepo) GetVPSBulkJob(ctx context.Context, id int64) (domain.VPSBulkJob, error) {
+	var row vpsBulkJobRow
+	if err := r.conn(ctx).First(&row, id).Error; err != nil {
+		return domain.VPSBulkJob{}, r.ensure(err)
+	}
+	return fromVPSBulkJobRow(row), nil
+}
+
+func (r *GormRepo) ListVPSBulkJobs(ctx context.Context, limit, offset int) ([]domain.VPSBulkJob, int, error) {
+	var total int64
+	if err := r.conn(ctx).Model(&vpsBulkJobRow{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []vpsBulkJobRow
+	if err := r.conn(ctx).Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	out := make([]domain.VPSBulkJob, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromVPSBulkJobRow(row))
+	}
+	return out, int(total), nil
+}
+
+func (r *GormRepo) ListRunningVPSBulkJobs(ctx context.Context) ([]domain.VPSBulkJob, error) {
+	var rows []vpsBulkJobRow
+	if err := r.conn(ctx).Where("status = ?", string(domain.VPSBulkJobStatusRunning)).Order("id ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.VPSBulkJob, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromVPSBulkJobRow(row))
+	}
+	return out, nil
+}
+
+func (r *GormRepo) UpdateVPSBulkJob(ctx context.Context, job domain.VPSBulkJob) error {
+	return r.conn(ctx).Model(&vpsBulkJobRow{}).Where("id = ?", job.ID).Updates(map[string]any{
+		"status":      string(job.Status),
+		"succeeded":   job.Succeeded,
+		"failed":      job.Failed,
+		"canceled":    job.Canceled,
+		"finished_at": job.FinishedAt,
+		"updated_at":  time.Now(),
+	}).Error
+}
+
+func (r *GormRepo) ListVPSBulkJobItems(ctx context.Context, jobID int64, status domain.VPSBulkItemStatus) ([]domain.VPSBulkJobItem, error) {
+	q := r.conn(ctx).Where("job_id = ?", jobID)
+	if status != "" {
+		q = q.Where("status = ?", string(status))
+	}
+	var rows []vpsBulkJobItemRow
+	if err := q.Order("id ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.VPSBulkJobItem, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromVPSBulkJobItemRow(row))
+	}
+	return out, nil
+}
+
+func (r *GormRepo) ListVPSBulkJobItemsAfter(ctx context.Context, jobID, afterSeq int64, limit int) ([]domain.VPSBulkJobItem, error) {
+	if limit <= 0 {
+		limit = 200
+	}
+	var rows []vpsBulkJobItemRow
+	if err := r.conn(ctx).
+		Where("job_id = ? AND seq > ?", jobID, afterSeq).
+		Order("seq ASC").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.VPSBulkJobItem, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromVPSBulkJobItemRow(row))
+	}
+	return out, nil
+}
+
+func (r *GormRepo) UpdateVPSBulkJobItem(ctx context.Context, item domain.VPSBulkJobItem) error {
+	return r.conn(ctx).Model(&vpsBulkJobItemRow{}).Where("id = ?", item.ID).Updates(map[string]any{
+		"status":      string(item.Status),
+		"error":       item.Error,
+		"seq":         item.Seq,
+		"finished_at": item.FinishedAt,
+	}).Error
+}
+
+func (r *GormRepo) CancelPendingVPSBulkJobItems(ctx context.Context, jobID int64, at time.Time) (int, error) {
+	res := r.conn(ctx).Model(&vpsBulkJobItemRow{}).
+		Where("job_id = ? AND status = ?", jobID, string(domain.VPSBulkItemStatusPending)).
+		Updates(map[string]any{
+			"status":      string(domain.VPSBulkItemStatusCanceled),
+			"finished_at": at,
+		})
+	return int(res.RowsAffected), res.Error
+}
+
+func fromVPSBulkJobRow(row vpsBulkJobRow) domain.VPSBulkJob {
+	return domain.VPSBulkJob{
+		ID:          row.ID,
+		AdminID:     row.AdminID,
+		Action:      domain.VPSBulkAction(row.Action),
+		ParamsJSON:  row.ParamsJSON,
+		FilterJSON:  row.FilterJSON,
+		Concurrency: row.Concurrency,
+		Status:      domain.VPSBulkJobStatus(row.Status),
+		Total:       row.Total,
+		Succeeded:   row.Succeeded,
+		Failed:      row.Failed,
+		Canceled:    row.Canceled,
+		CreatedAt:   row.CreatedAt,
+		FinishedAt:  row.FinishedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+}
+
+func fromVPSBulkJobItemRow(row vpsBulkJobItemRow) domain.VPSBulkJobItem {
+	return domain.VPSBulkJobItem{
+		ID:         row.ID,
+		JobID:      row.JobID,
+		VPSID:      row.VPSID,
+		Status:     domain.VPSBulkItemStatus(row.Status),
+		Error:      row.Error,
+		Seq:        row.Seq,
+		FinishedAt: row.FinishedAt,
+	}
+}
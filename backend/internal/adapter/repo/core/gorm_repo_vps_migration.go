@@ -0,0 +1,160 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"xiaoheiplay/internal/domain"
+)
+
+func (r *GormRepo) CreateVPSMigration(ctx context.Context, m *domain.VPSMigration) error {
+	row := toVPSMigrationRow(*m)
+	row.ID = 0
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	*m = fromVPSMigrationRow(row)
+	return nil
+}
+
+func (r *GormRepo) GetVPSMigration(ctx context.Context, id int64) (domain.VPSMigration, error) {
+	var row vpsMigrationRow
+	if err := r.conn(ctx).First(&row, id).Error; err != nil {
+		return domain.VPSMigration{}, r.ensure(err)
+	}
+	return fromVPSMigrationRow(row), nil
+}
+
+func (r *GormRepo) GetRunningVPSMigration(ctx context.Context, vpsID int64) (domain.VPSMigration, error) {
+	var row vpsMigrationRow
+	if err := r.conn(ctx).
+		Where("vps_id = ? AND status = ?", vpsID, string(domain.VPSMigrationStatusRunning)).
+		Order("id DESC").
+		First(&row).Error; err != nil {
+		return domain.VPSMigration{}, r.ensure(err)
+	}
+	return fromVPSMigrationRow(row), nil
+}
+
+func (r *GormRepo) ListVPSMigrations(ctx context.Context, vpsID int64, limit, offset int) ([]domain.VPSMigration, int, error) {
+	q := r.conn(ctx).Model(&vpsMigrationRow{})
+	if vpsID > 0 {
+		q = q.Where("vps_id = ?", vpsID)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []vpsMigrationRow
+	if err := q.Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	out := make([]domain.VPSMigration, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromVPSMigrationRow(row))
+	}
+	return out, int(total), nil
+}
+
+func (r *GormRepo) ListDueVPSMigrations(ctx context.Context, now time.Time, limit int) ([]domain.VPSMigration, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	var rows []vpsMigrationRow
+	if err := r.conn(ctx).
+		Where("status = ? AND next_run_at <= ?", string(domain.VPSMigrationStatusRunning), now).
+		Order("next_run_at ASC, id ASC").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.VPSMigration, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromVPSMigrationRow(row))
+	}
+	return out, nil
+}
+
+func (r *GormRepo) UpdateVPSMigration(ctx context.Context, m domain.VPSMigration) error {
+	return r.conn(ctx).Model(&vpsMigrationRow{}).Where("id = ?", m.ID).Updates(map[string]any{
+		"target_instance_id":  m.TargetInstanceID,
+		"target_access_json":  m.TargetAccessJSON,
+		"target_host_name":    m.TargetHostName,
+		"create_requested_at": m.CreateRequestedAt,
+		"backup_id":           m.BackupID,
+		"backup_requested_at": m.BackupRequestedAt,
+		"status":              string(m.Status),
+		"step":                string(m.Step),
+		"attempts":            m.Attempts,
+		"last_error":          m.LastError,
+		"next_run_at":         m.NextRunAt,
+		"finished_at":         m.FinishedAt,
+		"updated_at":          time.Now(),
+	}).Error
+}
+
+func toVPSMigrationRow(m domain.VPSMigration) vpsMigrationRow {
+	return vpsMigrationRow{
+		ID:                m.ID,
+		VPSID:             m.VPSID,
+		UserID:            m.UserID,
+		AdminID:           m.AdminID,
+		SourceGoodsTypeID: m.SourceGoodsTypeID,
+		SourceInstanceID:  m.SourceInstanceID,
+		SourceLineID:      m.SourceLineID,
+		SourcePackageID:   m.SourcePackageID,
+		TargetGoodsTypeID: m.TargetGoodsTypeID,
+		TargetLineID:      m.TargetLineID,
+		TargetRegionID:    m.TargetRegionID,
+		TargetPackageID:   m.TargetPackageID,
+		TargetSystemID:    m.TargetSystemID,
+		TargetInstanceID:  m.TargetInstanceID,
+		TargetAccessJSON:  m.TargetAccessJSON,
+		TargetHostName:    m.TargetHostName,
+		CreateRequestedAt: m.CreateRequestedAt,
+		TransferData:      boolToInt(m.TransferData),
+		BackupID:          m.BackupID,
+		BackupRequestedAt: m.BackupRequestedAt,
+		Status:            string(m.Status),
+		Step:              string(m.Step),
+		Attempts:          m.Attempts,
+		MaxAttempts:       m.MaxAttempts,
+		LastError:         m.LastError,
+		NextRunAt:         m.NextRunAt,
+		FinishedAt:        m.FinishedAt,
+	}
+}
+
+func fromVPSMigrationRow(row vpsMigrationRow) domain.VPSMigration {
+	return domain.VPSMigration{
+		ID:                row.ID,
+		VPSID:             row.VPSID,
+		UserID:            row.UserID,
+		AdminID:           row.AdminID,
+		SourceGoodsTypeID: row.SourceGoodsTypeID,
+		SourceInstanceID:  row.SourceInstanceID,
+		SourceLineID:      row.SourceLineID,
+		SourcePackageID:   row.SourcePackageID,
+		TargetGoodsTypeID: row.TargetGoodsTypeID,
+		TargetLineID:      row.TargetLineID,
+		TargetRegionID:    row.TargetRegionID,
+		TargetPackageID:   row.TargetPackageID,
+		TargetSystemID:    row.TargetSystemID,
+		TargetInstanceID:  row.TargetInstanceID,
+		TargetAccessJSON:  row.TargetAccessJSON,
+		TargetHostName:    row.TargetHostName,
+		CreateRequestedAt: row.CreateRequestedAt,
+		TransferData:      row.TransferData == 1,
+		BackupID:          row.BackupID,
+		BackupRequestedAt: row.BackupRequestedAt,
+		Status:            domain.VPSMigrationStatus(row.Status),
+		Step:              domain.VPSMigrationStep(row.Step),
+		Attempts:          row.Attempts,
+		MaxAttempts:       row.MaxAttempts,
+		LastError:         row.LastError,
+		NextRunAt:         row.NextRunAt,
+		CreatedAt:         row.CreatedAt,
+		UpdatedAt:         row.UpdatedAt,
+		FinishedAt:        row.FinishedAt,
+	}
+}
@@ -0,0 +1,186 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+func (r *GormRepo) CreateVPSTrial(ctx context.Context, trial *domain.VPSTrial) error {
+	row := toVPSTrialRow(*trial)
+	row.ID = 0
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
+		return err
+	}
+	*trial = fromVPSTrialRow(row)
+	return nil
+}
+
+func (r *GormRepo) GetVPSTrialByOrderItem(ctx context.Context, orderItemID int64) (domain.VPSTrial, error) {
+	var row vpsTrialRow
+	if err := r.conn(ctx).Where("order_item_id = ?", orderItemID).First(&row).Error; err != nil {
+		return domain.VPSTrial{}, r.ensure(err)
+	}
+	return fromVPSTrialRow(row), nil
+}
+
+func (r *GormRepo) UpdateVPSTrial(ctx context.Context, trial domain.VPSTrial) error {
+	return r.conn(ctx).Model(&vpsTrialRow{}).Where("id = ?", trial.ID).Updates(map[string]any{
+		"order_id":      trial.OrderID,
+		"order_item_id": trial.OrderItemID,
+		"status":        string(trial.Status),
+		"expire_at":     trial.ExpireAt,
+		"converted_at":  trial.ConvertedAt,
+		"updated_at":    time.Now(),
+	}).Error
+}
+
+func (r *GormRepo) CountVPSTrials(ctx context.Context, filter appshared.VPSTrialFilter) (int, error) {
+	var total int64
+	if err := r.vpsTrialQuery(ctx, filter).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return int(total), nil
+}
+
+func (r *GormRepo) ListVPSTrials(ctx context.Context, filter appshared.VPSTrialFilter, limit, offset int) ([]domain.VPSTrial, int, error) {
+	q := r.vpsTrialQuery(ctx, filter)
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []vpsTrialRow
+	if err := q.Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	out := make([]domain.VPSTrial, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromVPSTrialRow(row))
+	}
+	return out, int(total), nil
+}
+
+func (r *GormRepo) ListDueVPSTrials(ctx context.Context, now time.Time, limit int) ([]domain.VPSTrial, error) {
+	var rows []vpsTrialRow
+	if err := r.conn(ctx).
+		Where("status = ? AND expire_at <= ?", string(domain.VPSTrialStatusActive), now).
+		Order("expire_at ASC, id ASC").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.VPSTrial, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromVPSTrialRow(row))
+	}
+	return out, nil
+}
+
+func (r *GormRepo) VPSTrialStats(ctx context.Context, from, to time.Time) ([]domain.VPSTrialStat, error) {
+	var rows []struct {
+		PackageID int64
+		Status    string
+		Total     int
+	}
+	if err := r.conn(ctx).Model(&vpsTrialRow{}).
+		Select("package_id, status, COUNT(*) AS total").
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("package_id, status").
+		Order("package_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]domain.VPSTrialStat, 0)
+	index := map[int64]int{}
+	for _, row := range rows {
+		idx, ok := index[row.PackageID]
+		if !ok {
+			idx = len(out)
+			index[row.PackageID] = idx
+			out = append(out, domain.VPSTrialStat{PackageID: row.PackageID})
+		}
+		stat := &out[idx]
+		stat.Total += row.Total
+		switch domain.VPSTrialStatus(row.Status) {
+		case domain.VPSTrialStatusActive:
+			stat.Active += row.Total
+		case domain.VPSTrialStatusConverted:
+			stat.Converted += row.Total
+		case domain.VPSTrialStatusExpired:
+			stat.Expired += row.Total
+		}
+	}
+	return out, nil
+}
+
+func (r *GormRepo) vpsTrialQuery(ctx context.Context, filter appshared.VPSTrialFilter) *gorm.DB {
+	q := r.conn(ctx).Model(&vpsTrialRow{})
+	if filter.UserID > 0 {
+		q = q.Where("user_id = ?", filter.UserID)
+	}
+	if filter.PackageID > 0 {
+		q = q.Where("package_id = ?", filter.PackageID)
+	}
+	if v := strings.TrimSpace(filter.Status); v != "" {
+		q = q.Where("status = ?", v)
+	}
+	if filter.IdentityHash != "" {
+		q = q.Where("identity_hash = ?", filter.IdentityHash)
+	}
+	if filter.Phone != "" {
+		q = q.Where("phone = ?", filter.Phone)
+	}
+	if filter.ClientIP != "" {
+		q = q.Where("client_ip = ?", filter.ClientIP)
+	}
+	if filter.DeviceID != "" {
+		q = q.Where("device_id = ?", filter.DeviceID)
+	}
+	if filter.Since != nil {
+		q = q.Where("created_at >= ?", *filter.Since)
+	}
+	return q
+}
+
+func toVPSTrialRow(trial domain.VPSTrial) vpsTrialRow {
+	return vpsTrialRow{
+		ID:           trial.ID,
+		UserID:       trial.UserID,
+		PackageID:    trial.PackageID,
+		OrderID:      trial.OrderID,
+		OrderItemID:  trial.OrderItemID,
+		Days:         trial.Days,
+		IdentityHash: trial.IdentityHash,
+		Phone:        trial.Phone,
+		ClientIP:     trial.ClientIP,
+		DeviceID:     trial.DeviceID,
+		Status:       string(trial.Status),
+		ExpireAt:     trial.ExpireAt,
+		ConvertedAt:  trial.ConvertedAt,
+	}
+}
+
+func fromVPSTrialRow(row vpsTrialRow) domain.VPSTrial {
+	return domain.VPSTrial{
+		ID:           row.ID,
+		UserID:       row.UserID,
+		PackageID:    row.PackageID,
+		OrderID:      row.OrderID,
+		OrderItemID:  row.OrderItemID,
+		Days:         row.Days,
+		IdentityHash: row.IdentityHash,
+		Phone:        row.Phone,
+		ClientIP:     row.ClientIP,
+		DeviceID:     row.DeviceID,
+		Status:       domain.VPSTrialStatus(row.Status),
+		ExpireAt:     row.ExpireAt,
+		ConvertedAt:  row.ConvertedAt,
+		CreatedAt:    row.CreatedAt,
+		UpdatedAt:    row.UpdatedAt,
+	}
+}
@@ -14,7 +14,7 @@ import (
 
 func (r *GormRepo) GetWallet(ctx context.Context, userID int64) (domain.Wallet, error) {
 	var row walletRow
-	if err := r.gdb.WithContext(ctx).Where("user_id = ?", userID).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("user_id = ?", userID).First(&row).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			w := domain.Wallet{UserID: userID, Balance: 0}
 			if err := r.UpsertWallet(ctx, &w); err != nil {
@@ -24,10 +24,15 @@ func (r *GormRepo) GetWallet(ctx context.Context, userID int64) (domain.Wallet,
 		}
 		return domain.Wallet{}, err
 	}
+	frozen, err := walletFrozenAmount(r.conn(ctx), userID)
+	if err != nil {
+		return domain.Wallet{}, err
+	}
 	return domain.Wallet{
 		ID:        row.ID,
 		UserID:    row.UserID,
 		Balance:   row.Balance,
+		Frozen:    frozen,
 		UpdatedAt: row.UpdatedAt,
 	}, nil
 }
@@ -39,7 +44,7 @@ func (r *GormRepo) UpsertWallet(ctx context.Context, wallet *domain.Wallet) erro
 		Balance:   wallet.Balance,
 		UpdatedAt: time.Now(),
 	}
-	if err := r.gdb.WithContext(ctx).
+	if err := r.conn(ctx).
 		Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "user_id"}},
 			DoUpdates: clause.AssignmentColumns([]string{"balance", "updated_at"}),
@@ -48,7 +53,7 @@ func (r *GormRepo) UpsertWallet(ctx context.Context, wallet *domain.Wallet) erro
 		return err
 	}
 	var got walletModel
-	if err := r.gdb.WithContext(ctx).Select("id").Where("user_id = ?", wallet.UserID).First(&got).Error; err == nil {
+	if err := r.conn(ctx).Select("id").Where("user_id = ?", wallet.UserID).First(&got).Error; err == nil {
 		wallet.ID = got.ID
 	}
 	return nil
@@ -63,7 +68,7 @@ func (r *GormRepo) AddWalletTransaction(ctx context.Context, txItem *domain.Wall
 		RefID:   txItem.RefID,
 		Note:    txItem.Note,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	txItem.ID = row.ID
@@ -75,7 +80,7 @@ func (r *GormRepo) ListWalletTransactions(ctx context.Context, userID int64, lim
 	if limit <= 0 {
 		limit = 20
 	}
-	q := r.gdb.WithContext(ctx).Model(&walletTransactionRow{}).Where("user_id = ?", userID)
+	q := r.conn(ctx).Model(&walletTransactionRow{}).Where("user_id = ?", userID)
 	var total int64
 	if err := q.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -101,21 +106,18 @@ func (r *GormRepo) ListWalletTransactions(ctx context.Context, userID int64, lim
 }
 
 func (r *GormRepo) AdjustWalletBalance(ctx context.Context, userID int64, amount int64, txType, refType string, refID int64, note string) (wallet domain.Wallet, err error) {
-	err = r.gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		var w walletRow
-		lock := clause.Locking{Strength: "UPDATE"}
-		if e := tx.Clauses(lock).Where("user_id = ?", userID).First(&w).Error; e != nil {
-			if errors.Is(e, gorm.ErrRecordNotFound) {
-				w = walletRow{UserID: userID, Balance: 0, UpdatedAt: time.Now()}
-				if e = tx.Create(&w).Error; e != nil {
-					return e
-				}
-			} else {
-				return e
-			}
+	err = r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		w, e := lockWalletRow(tx, userID)
+		if e != nil {
+			return e
+		}
+		frozen, e := walletFrozenAmount(tx, userID)
+		if e != nil {
+			return e
 		}
 		newBalance := w.Balance + amount
-		if newBalance < 0 {
+		// Debits may not touch funds reserved by active holds.
+		if newBalance < 0 || (amount < 0 && newBalance < frozen) {
 			return appshared.ErrInsufficientBalance
 		}
 		now := time.Now()
@@ -140,6 +142,7 @@ func (r *GormRepo) AdjustWalletBalance(ctx context.Context, userID int64, amount
 			ID:        w.ID,
 			UserID:    userID,
 			Balance:   newBalance,
+			Frozen:    frozen,
 			UpdatedAt: now,
 		}
 		return nil
@@ -150,9 +153,26 @@ func (r *GormRepo) AdjustWalletBalance(ctx context.Context, userID int64, amount
 	return wallet, nil
 }
 
+// lockWalletRow loads the wallet row for update inside tx, creating an
+// empty wallet on first use.
+func lockWalletRow(tx *gorm.DB, userID int64) (walletRow, error) {
+	var w walletRow
+	lock := clause.Locking{Strength: "UPDATE"}
+	if err := tx.Clauses(lock).Where("user_id = ?", userID).First(&w).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return walletRow{}, err
+		}
+		w = walletRow{UserID: userID, Balance: 0, UpdatedAt: time.Now()}
+		if err := tx.Create(&w).Error; err != nil {
+			return walletRow{}, err
+		}
+	}
+	return w, nil
+}
+
 func (r *GormRepo) HasWalletTransaction(ctx context.Context, userID int64, refType string, refID int64) (bool, error) {
 	var total int64
-	if err := r.gdb.WithContext(ctx).Model(&walletTransactionRow{}).
+	if err := r.conn(ctx).Model(&walletTransactionRow{}).
 		Where("user_id = ? AND ref_type = ? AND ref_id = ?", userID, refType, refID).
 		Count(&total).Error; err != nil {
 		return false, err
@@ -160,6 +180,20 @@ func (r *GormRepo) HasWalletTransaction(ctx context.Context, userID int64, refTy
 	return total > 0, nil
 }
 
+func (r *GormRepo) WalletTotals(ctx context.Context) (domain.WalletTotals, error) {
+	var row struct {
+		Wallets       int64
+		Balance       int64
+		NegativeCount int64
+	}
+	if err := r.conn(ctx).Model(&walletRow{}).
+		Select("COUNT(*) AS wallets, COALESCE(SUM(balance), 0) AS balance, COALESCE(SUM(CASE WHEN balance < 0 THEN 1 ELSE 0 END), 0) AS negative_count").
+		Scan(&row).Error; err != nil {
+		return domain.WalletTotals{}, err
+	}
+	return domain.WalletTotals{Wallets: row.Wallets, Balance: row.Balance, NegativeCount: row.NegativeCount}, nil
+}
+
 func (r *GormRepo) CreateWalletOrder(ctx context.Context, order *domain.WalletOrder) error {
 	row := walletOrderRow{
 		UserID:   order.UserID,
@@ -170,7 +204,7 @@ func (r *GormRepo) CreateWalletOrder(ctx context.Context, order *domain.WalletOr
 		Note:     order.Note,
 		MetaJSON: order.MetaJSON,
 	}
-	if err := r.gdb.WithContext(ctx).Create(&row).Error; err != nil {
+	if err := r.conn(ctx).Create(&row).Error; err != nil {
 		return err
 	}
 	order.ID = row.ID
@@ -181,7 +215,7 @@ func (r *GormRepo) CreateWalletOrder(ctx context.Context, order *domain.WalletOr
 
 func (r *GormRepo) GetWalletOrder(ctx context.Context, id int64) (domain.WalletOrder, error) {
 	var row walletOrderRow
-	if err := r.gdb.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+	if err := r.conn(ctx).Where("id = ?", id).First(&row).Error; err != nil {
 		return domain.WalletOrder{}, r.ensure(err)
 	}
 	return domain.WalletOrder{
@@ -204,7 +238,7 @@ func (r *GormRepo) ListWalletOrders(ctx context.Context, userID int64, limit, of
 	if limit <= 0 {
 		limit = 20
 	}
-	q := r.gdb.WithContext(ctx).Model(&walletOrderRow{}).Where("user_id = ?", userID)
+	q := r.conn(ctx).Model(&walletOrderRow{}).Where("user_id = ?", userID)
 	var total int64
 	if err := q.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -237,7 +271,7 @@ func (r *GormRepo) ListAllWalletOrders(ctx context.Context, status string, limit
 	if limit <= 0 {
 		limit = 20
 	}
-	q := r.gdb.WithContext(ctx).Model(&walletOrderRow{})
+	q := r.conn(ctx).Model(&walletOrderRow{})
 	if status != "" {
 		q = q.Where("status = ?", status)
 	}
@@ -270,7 +304,7 @@ func (r *GormRepo) ListAllWalletOrders(ctx context.Context, status string, limit
 }
 
 func (r *GormRepo) UpdateWalletOrderStatus(ctx context.Context, id int64, status domain.WalletOrderStatus, reviewedBy *int64, reason string) error {
-	return r.gdb.WithContext(ctx).Model(&walletOrderRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&walletOrderRow{}).Where("id = ?", id).Updates(map[string]any{
 		"status":        string(status),
 		"reviewed_by":   reviewedBy,
 		"review_reason": reason,
@@ -279,7 +313,7 @@ func (r *GormRepo) UpdateWalletOrderStatus(ctx context.Context, id int64, status
 }
 
 func (r *GormRepo) UpdateWalletOrderStatusIfCurrent(ctx context.Context, id int64, currentStatus, targetStatus domain.WalletOrderStatus, reviewedBy *int64, reason string) (bool, error) {
-	res := r.gdb.WithContext(ctx).Model(&walletOrderRow{}).
+	res := r.conn(ctx).Model(&walletOrderRow{}).
 		Where("id = ? AND status = ?", id, string(currentStatus)).
 		Updates(map[string]any{
 			"status":        string(targetStatus),
@@ -294,7 +328,7 @@ func (r *GormRepo) UpdateWalletOrderStatusIfCurrent(ctx context.Context, id int6
 }
 
 func (r *GormRepo) UpdateWalletOrderMeta(ctx context.Context, id int64, metaJSON string) error {
-	return r.gdb.WithContext(ctx).Model(&walletOrderRow{}).Where("id = ?", id).Updates(map[string]any{
+	return r.conn(ctx).Model(&walletOrderRow{}).Where("id = ?", id).Updates(map[string]any{
 		"meta_json":  metaJSON,
 		"updated_at": time.Now(),
 	}).Error
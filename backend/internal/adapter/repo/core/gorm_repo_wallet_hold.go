@@ -0,0 +1,187 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+func walletFrozenAmount(tx *gorm.DB, userID int64) (int64, error) {
+	var total int64
+	if err := tx.Model(&walletHoldRow{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("user_id = ? AND status = ?", userID, string(domain.WalletHoldActive)).
+		Scan(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *GormRepo) CreateWalletHold(ctx context.Context, hold *domain.WalletHold) error {
+	if hold.UserID <= 0 || hold.Amount <= 0 || hold.RefType == "" {
+		return appshared.ErrInvalidInput
+	}
+	return r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		w, err := lockWalletRow(tx, hold.UserID)
+		if err != nil {
+			return err
+		}
+		var existing int64
+		if err := tx.Model(&walletHoldRow{}).
+			Where("ref_type = ? AND ref_id = ? AND status = ?", hold.RefType, hold.RefID, string(domain.WalletHoldActive)).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return appshared.ErrConflict
+		}
+		frozen, err := walletFrozenAmount(tx, hold.UserID)
+		if err != nil {
+			return err
+		}
+		if w.Balance-frozen < hold.Amount {
+			return appshared.ErrInsufficientBalance
+		}
+		row := walletHoldRow{
+			UserID:  hold.UserID,
+			Amount:  hold.Amount,
+			RefType: hold.RefType,
+			RefID:   hold.RefID,
+			Status:  string(domain.WalletHoldActive),
+			Note:    hold.Note,
+		}
+		if err := tx.Create(&row).Error; err != nil {
+			return err
+		}
+		*hold = fromWalletHoldRow(row)
+		return nil
+	})
+}
+
+func (r *GormRepo) GetActiveWalletHold(ctx context.Context, refType string, refID int64) (domain.WalletHold, error) {
+	var row walletHoldRow
+	if err := r.conn(ctx).
+		Where("ref_type = ? AND ref_id = ? AND status = ?", refType, refID, string(domain.WalletHoldActive)).
+		First(&row).Error; err != nil {
+		return domain.WalletHold{}, r.ensure(err)
+	}
+	return fromWalletHoldRow(row), nil
+}
+
+// CaptureWalletHold debits the held amount and records it as a wallet
+// transaction with the hold's reference, in one transaction.
+func (r *GormRepo) CaptureWalletHold(ctx context.Context, refType string, refID int64, txType, note string) (wallet domain.Wallet, err error) {
+	err = r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		var hold walletHoldRow
+		if e := tx.Where("ref_type = ? AND ref_id = ? AND status = ?", refType, refID, string(domain.WalletHoldActive)).
+			First(&hold).Error; e != nil {
+			return r.ensure(e)
+		}
+		w, e := lockWalletRow(tx, hold.UserID)
+		if e != nil {
+			return e
+		}
+		now := time.Now()
+		res := tx.Model(&walletHoldRow{}).
+			Where("id = ? AND status = ?", hold.ID, string(domain.WalletHoldActive)).
+			Updates(map[string]any{"status": string(domain.WalletHoldCaptured), "settled_at": now})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return appshared.ErrConflict
+		}
+		newBalance := w.Balance - hold.Amount
+		if newBalance < 0 {
+			return appshared.ErrInsufficientBalance
+		}
+		if e := tx.Model(&walletRow{}).Where("user_id = ?", hold.UserID).Updates(map[string]any{
+			"balance":    newBalance,
+			"updated_at": now,
+		}).Error; e != nil {
+			return e
+		}
+		txRow := walletTransactionRow{
+			UserID:  hold.UserID,
+			Amount:  -hold.Amount,
+			Type:    txType,
+			RefType: refType,
+			RefID:   refID,
+			Note:    note,
+		}
+		if e := tx.Create(&txRow).Error; e != nil {
+			return e
+		}
+		frozen, e := walletFrozenAmount(tx, hold.UserID)
+		if e != nil {
+			return e
+		}
+		wallet = domain.Wallet{
+			ID:        w.ID,
+			UserID:    hold.UserID,
+			Balance:   newBalance,
+			Frozen:    frozen,
+			UpdatedAt: now,
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.Wallet{}, err
+	}
+	return wallet, nil
+}
+
+func (r *GormRepo) ReleaseWalletHold(ctx context.Context, refType string, refID int64, note string) (bool, error) {
+	updates := map[string]any{"status": string(domain.WalletHoldReleased), "settled_at": time.Now()}
+	if note != "" {
+		updates["note"] = note
+	}
+	res := r.conn(ctx).Model(&walletHoldRow{}).
+		Where("ref_type = ? AND ref_id = ? AND status = ?", refType, refID, string(domain.WalletHoldActive)).
+		Updates(updates)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *GormRepo) ListWalletHolds(ctx context.Context, userID int64, status string, limit, offset int) ([]domain.WalletHold, int, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	q := r.conn(ctx).Model(&walletHoldRow{}).Where("user_id = ?", userID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []walletHoldRow
+	if err := q.Order("id DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	out := make([]domain.WalletHold, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, fromWalletHoldRow(row))
+	}
+	return out, int(total), nil
+}
+
+func fromWalletHoldRow(row walletHoldRow) domain.WalletHold {
+	return domain.WalletHold{
+		ID:        row.ID,
+		UserID:    row.UserID,
+		Amount:    row.Amount,
+		RefType:   row.RefType,
+		RefID:     row.RefID,
+		Status:    domain.WalletHoldStatus(row.Status),
+		Note:      row.Note,
+		CreatedAt: row.CreatedAt,
+		SettledAt: row.SettledAt,
+	}
+}
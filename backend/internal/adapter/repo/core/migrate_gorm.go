@@ -20,6 +20,7 @@ func migrateGorm(db *gorm.DB) error {
 		&regionRow{},
 		&planGroupRow{},
 		&packageRow{},
+		&addonRow{},
 		&systemImageRow{},
 		&lineSystemImageRow{},
 		&cartItemRow{},
@@ -30,6 +31,18 @@ func migrateGorm(db *gorm.DB) error {
 		&adminAuditLogRow{},
 		&apiKeyRow{},
 		&userAPIKeyRow{},
+		&sshKeyRow{},
+		&vpsBackupPolicyRow{},
+		&vpsBulkJobRow{},
+		&vpsBulkJobItemRow{},
+		&vpsMigrationRow{},
+		&ipAddressRow{},
+		&ipAddressEventRow{},
+		&vpsTrialRow{},
+		&dunningPolicyRow{},
+		&dunningRecordRow{},
+		&orderApprovalRuleRow{},
+		&orderApprovalEvaluationRow{},
 		&settingRow{},
 		&settingListValueRow{},
 		&scheduledTaskConfigRow{},
@@ -41,6 +54,7 @@ func migrateGorm(db *gorm.DB) error {
 		&billingCycleRow{},
 		&automationLogRow{},
 		&provisionJobRow{},
+		&inventoryReservationRow{},
 		&resizeTaskRow{},
 		&integrationSyncLogRow{},
 		&permissionGroupRow{},
@@ -65,11 +79,26 @@ func migrateGorm(db *gorm.DB) error {
 		&ticketResourceRow{},
 		&walletRow{},
 		&walletTransactionRow{},
+		&walletHoldRow{},
+		&ledgerAccountRow{},
+		&ledgerEntryRow{},
+		&ledgerLineRow{},
+		&payoutAccountRow{},
+		&payoutBatchRow{},
+		&payoutBatchItemRow{},
+		&financeReportDefinitionRow{},
+		&financeReportRunRow{},
+		&financeReportFileRow{},
 		&walletOrderRow{},
 		&scheduledTaskRunRow{},
 		&notificationRow{},
 		&pushTokenRow{},
+		&notificationPreferenceRow{},
+		&deferredNotificationRow{},
+		&broadcastRow{},
+		&broadcastDeliveryRow{},
 		&realnameVerificationRow{},
+		&companyVerificationRow{},
 		&pluginInstallationRow{},
 		&pluginPaymentMethodRow{},
 		&probeNodeRow{},
@@ -85,6 +114,9 @@ func migrateGorm(db *gorm.DB) error {
 			return err
 		}
 	} else {
+		if err := dropLegacyIndexes(db); err != nil {
+			return err
+		}
 		if err := db.AutoMigrate(models...); err != nil {
 			return err
 		}
@@ -199,6 +231,26 @@ func createMissingTablesOnly(db *gorm.DB, models []any) error {
 	return nil
 }
 
+// dropLegacyIndexes removes indexes whose columns changed under a new name,
+// so AutoMigrate can create the replacement.
+func dropLegacyIndexes(db *gorm.DB) error {
+	legacy := []struct {
+		model any
+		name  string
+	}{
+		{&userTierDiscountRuleRow{}, "idx_user_tier_rule_scope_unique"},
+	}
+	for _, idx := range legacy {
+		if !db.Migrator().HasTable(idx.model) || !db.Migrator().HasIndex(idx.model, idx.name) {
+			continue
+		}
+		if err := db.Migrator().DropIndex(idx.model, idx.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func repairTimestampNulls(db *gorm.DB, models []any) error {
 	for _, model := range models {
 		if db.Migrator().HasColumn(model, "created_at") {
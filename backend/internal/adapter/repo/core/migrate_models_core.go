@@ -106,6 +106,9 @@ type planGroupRow struct {
 	Active            int       `gorm:"column:active;not null;default:1"`
 	Visible           int       `gorm:"column:visible;not null;default:1"`
 	CapacityRemaining int       `gorm:"column:capacity_remaining;not null;default:-1"`
+	CapacityReserved  int       `gorm:"column:capacity_reserved;not null;default:0"`
+	SnapshotQuota     int       `gorm:"column:snapshot_quota;not null;default:0"`
+	BackupQuota       int       `gorm:"column:backup_quota;not null;default:0"`
 	SortOrder         int       `gorm:"column:sort_order;not null;default:0"`
 	CreatedAt         time.Time `gorm:"column:created_at;not null;autoCreateTime"`
 	UpdatedAt         time.Time `gorm:"column:updated_at;not null;autoUpdateTime"`
@@ -113,6 +116,23 @@ type planGroupRow struct {
 
 func (planGroupRow) TableName() string { return "plan_groups" }
 
+type addonRow struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	PlanGroupID int64     `gorm:"column:plan_group_id;not null;index"`
+	Kind        string    `gorm:"size:32;column:kind;not null"`
+	Name        string    `gorm:"column:name;not null"`
+	Description string    `gorm:"column:description;not null;default:''"`
+	Monthly     int64     `gorm:"column:monthly;not null;default:0"`
+	Units       int       `gorm:"column:units;not null;default:1"`
+	MaxQty      int       `gorm:"column:max_qty;not null;default:0"`
+	Active      int       `gorm:"column:active;not null;default:0"`
+	SortOrder   int       `gorm:"column:sort_order;not null;default:0"`
+	CreatedAt   time.Time `gorm:"column:created_at;not null;autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;not null;autoUpdateTime"`
+}
+
+func (addonRow) TableName() string { return "addons" }
+
 type packageRow struct {
 	ID                   int64     `gorm:"primaryKey;autoIncrement;column:id"`
 	GoodsTypeID          int64     `gorm:"column:goods_type_id;not null;default:0;index;uniqueIndex:idx_packages_gt_product_unique,where:product_id > 0"`
@@ -131,6 +151,8 @@ type packageRow struct {
 	Active               int       `gorm:"column:active;not null;default:1"`
 	Visible              int       `gorm:"column:visible;not null;default:1"`
 	CapacityRemaining    int       `gorm:"column:capacity_remaining;not null;default:-1"`
+	CapacityReserved     int       `gorm:"column:capacity_reserved;not null;default:0"`
+	TrialDays            int       `gorm:"column:trial_days;not null;default:0"`
 	CreatedAt            time.Time `gorm:"column:created_at;not null;autoCreateTime"`
 	UpdatedAt            time.Time `gorm:"column:updated_at;not null;autoUpdateTime"`
 }
@@ -298,6 +320,222 @@ type userAPIKeyRow struct {
 
 func (userAPIKeyRow) TableName() string { return "user_api_keys" }
 
+type sshKeyRow struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	UserID      int64     `gorm:"column:user_id;not null;uniqueIndex:idx_ssh_keys_user_fingerprint,priority:1"`
+	Name        string    `gorm:"column:name;not null"`
+	PublicKey   string    `gorm:"type:text;column:public_key;not null"`
+	Fingerprint string    `gorm:"size:191;column:fingerprint;not null;uniqueIndex:idx_ssh_keys_user_fingerprint,priority:2"`
+	CreatedAt   time.Time `gorm:"column:created_at;not null;autoCreateTime"`
+}
+
+func (sshKeyRow) TableName() string { return "ssh_keys" }
+
+type vpsBackupPolicyRow struct {
+	ID         int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	VPSID      int64      `gorm:"column:vps_id;not null;uniqueIndex:idx_vps_backup_policies_vps_kind,priority:1"`
+	UserID     int64      `gorm:"column:user_id;not null;index"`
+	Kind       string     `gorm:"size:16;column:kind;not null;uniqueIndex:idx_vps_backup_policies_vps_kind,priority:2"`
+	Enabled    int        `gorm:"column:enabled;not null;default:0;index:idx_vps_backup_policies_due,priority:1"`
+	Weekday    int        `gorm:"column:weekday;not null;default:0"`
+	RunAt      string     `gorm:"size:5;column:run_at;not null"`
+	Retention  int        `gorm:"column:retention;not null;default:1"`
+	NextRunAt  time.Time  `gorm:"column:next_run_at;not null;index:idx_vps_backup_policies_due,priority:2"`
+	LastRunAt  *time.Time `gorm:"column:last_run_at"`
+	LastStatus string     `gorm:"size:16;column:last_status;not null;default:''"`
+	LastError  string     `gorm:"type:text;column:last_error"`
+	ManagedIDs string     `gorm:"type:text;column:managed_ids_json"`
+	CreatedAt  time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at;not null;autoUpdateTime"`
+}
+
+func (vpsBackupPolicyRow) TableName() string { return "vps_backup_policies" }
+
+type vpsBulkJobRow struct {
+	ID          int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	AdminID     int64      `gorm:"column:admin_id;not null;index"`
+	Action      string     `gorm:"size:32;column:action;not null"`
+	ParamsJSON  string     `gorm:"type:text;column:params_json"`
+	FilterJSON  string     `gorm:"type:text;column:filter_json"`
+	Concurrency int        `gorm:"column:concurrency;not null;default:0"`
+	Status      string     `gorm:"size:16;column:status;not null;index"`
+	Total       int        `gorm:"column:total;not null;default:0"`
+	Succeeded   int        `gorm:"column:succeeded;not null;default:0"`
+	Failed      int        `gorm:"column:failed;not null;default:0"`
+	Canceled    int        `gorm:"column:canceled;not null;default:0"`
+	CreatedAt   time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
+	FinishedAt  *time.Time `gorm:"column:finished_at"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at;not null;autoUpdateTime"`
+}
+
+func (vpsBulkJobRow) TableName() string { return "vps_bulk_jobs" }
+
+type vpsBulkJobItemRow struct {
+	ID         int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	JobID      int64      `gorm:"column:job_id;not null;index:idx_vps_bulk_job_items_job_seq,priority:1"`
+	VPSID      int64      `gorm:"column:vps_id;not null"`
+	Status     string     `gorm:"size:16;column:status;not null"`
+	Error      string     `gorm:"type:text;column:error"`
+	Seq        int64      `gorm:"column:seq;not null;default:0;index:idx_vps_bulk_job_items_job_seq,priority:2"`
+	FinishedAt *time.Time `gorm:"column:finished_at"`
+}
+
+func (vpsBulkJobItemRow) TableName() string { return "vps_bulk_job_items" }
+
+type vpsMigrationRow struct {
+	ID                int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	VPSID             int64      `gorm:"column:vps_id;not null;index"`
+	UserID            int64      `gorm:"column:user_id;not null"`
+	AdminID           int64      `gorm:"column:admin_id;not null"`
+	SourceGoodsTypeID int64      `gorm:"column:source_goods_type_id;not null;default:0"`
+	SourceInstanceID  string     `gorm:"size:64;column:source_instance_id"`
+	SourceLineID      int64      `gorm:"column:source_line_id;not null;default:0"`
+	SourcePackageID   int64      `gorm:"column:source_package_id;not null;default:0"`
+	TargetGoodsTypeID int64      `gorm:"column:target_goods_type_id;not null;default:0"`
+	TargetLineID      int64      `gorm:"column:target_line_id;not null;default:0"`
+	TargetRegionID    int64      `gorm:"column:target_region_id;not null;default:0"`
+	TargetPackageID   int64      `gorm:"column:target_package_id;not null;default:0"`
+	TargetSystemID    int64      `gorm:"column:target_system_id;not null;default:0"`
+	TargetInstanceID  string     `gorm:"size:64;column:target_instance_id"`
+	TargetAccessJSON  string     `gorm:"type:text;column:target_access_json"`
+	TargetHostName    string     `gorm:"size:128;column:target_host_name"`
+	CreateRequestedAt *time.Time `gorm:"column:create_requested_at"`
+	TransferData      int        `gorm:"column:transfer_data;not null;default:0"`
+	BackupID          int64      `gorm:"column:backup_id;not null;default:0"`
+	BackupRequestedAt *time.Time `gorm:"column:backup_requested_at"`
+	Status            string     `gorm:"size:16;column:status;not null;index:idx_vps_migrations_status_next,priority:1"`
+	Step              string     `gorm:"size:16;column:step;not null"`
+	Attempts          int        `gorm:"column:attempts;not null;default:0"`
+	MaxAttempts       int        `gorm:"column:max_attempts;not null;default:0"`
+	LastError         string     `gorm:"type:text;column:last_error"`
+	NextRunAt         time.Time  `gorm:"column:next_run_at;not null;index:idx_vps_migrations_status_next,priority:2"`
+	CreatedAt         time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
+	UpdatedAt         time.Time  `gorm:"column:updated_at;not null;autoUpdateTime"`
+	FinishedAt        *time.Time `gorm:"column:finished_at"`
+}
+
+func (vpsMigrationRow) TableName() string { return "vps_migrations" }
+
+type ipAddressRow struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	Address   string    `gorm:"size:64;column:address;not null;uniqueIndex"`
+	Version   int       `gorm:"column:version;not null;default:0"`
+	LineID    int64     `gorm:"column:line_id;not null;default:0;index"`
+	VPSID     int64     `gorm:"column:vps_id;not null;default:0;index"`
+	UserID    int64     `gorm:"column:user_id;not null;default:0"`
+	IsPrimary int       `gorm:"column:is_primary;not null;default:0"`
+	PTR       string    `gorm:"size:255;column:ptr"`
+	Status    string    `gorm:"size:16;column:status;not null;index"`
+	Note      string    `gorm:"type:text;column:note"`
+	CreatedAt time.Time `gorm:"column:created_at;not null;autoCreateTime"`
+	UpdatedAt time.Time `gorm:"column:updated_at;not null;autoUpdateTime"`
+}
+
+func (ipAddressRow) TableName() string { return "ip_addresses" }
+
+type ipAddressEventRow struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	IPAddressID int64     `gorm:"column:ip_address_id;not null;index"`
+	Address     string    `gorm:"size:64;column:address;not null"`
+	Type        string    `gorm:"size:16;column:type;not null"`
+	VPSID       int64     `gorm:"column:vps_id;not null;default:0"`
+	UserID      int64     `gorm:"column:user_id;not null;default:0"`
+	AdminID     int64     `gorm:"column:admin_id;not null;default:0"`
+	Detail      string    `gorm:"type:text;column:detail"`
+	CreatedAt   time.Time `gorm:"column:created_at;not null;autoCreateTime"`
+}
+
+func (ipAddressEventRow) TableName() string { return "ip_address_events" }
+
+type vpsTrialRow struct {
+	ID           int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	UserID       int64      `gorm:"column:user_id;not null;index"`
+	PackageID    int64      `gorm:"column:package_id;not null;index"`
+	OrderID      int64      `gorm:"column:order_id;not null;default:0"`
+	OrderItemID  int64      `gorm:"column:order_item_id;not null;default:0;index"`
+	Days         int        `gorm:"column:days;not null;default:0"`
+	IdentityHash string     `gorm:"size:64;column:identity_hash;not null;index"`
+	Phone        string     `gorm:"size:32;column:phone;not null;index"`
+	ClientIP     string     `gorm:"size:64;column:client_ip;index"`
+	DeviceID     string     `gorm:"size:128;column:device_id;index"`
+	Status       string     `gorm:"size:16;column:status;not null;index"`
+	ExpireAt     time.Time  `gorm:"column:expire_at;not null;index"`
+	ConvertedAt  *time.Time `gorm:"column:converted_at"`
+	CreatedAt    time.Time  `gorm:"column:created_at;not null;autoCreateTime;index"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at;not null;autoUpdateTime"`
+}
+
+func (vpsTrialRow) TableName() string { return "vps_trials" }
+
+type dunningPolicyRow struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	GoodsTypeID int64     `gorm:"column:goods_type_id;not null;uniqueIndex"`
+	Enabled     int       `gorm:"column:enabled;not null;default:1"`
+	StepsJSON   string    `gorm:"type:text;column:steps_json;not null"`
+	UpdatedBy   int64     `gorm:"column:updated_by;not null;default:0"`
+	CreatedAt   time.Time `gorm:"column:created_at;not null;autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;not null;autoUpdateTime"`
+}
+
+func (dunningPolicyRow) TableName() string { return "dunning_policies" }
+
+type dunningRecordRow struct {
+	ID            int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	InstanceID    int64     `gorm:"column:instance_id;not null;uniqueIndex:idx_dunning_records_step,priority:1"`
+	UserID        int64     `gorm:"column:user_id;not null;index"`
+	GoodsTypeID   int64     `gorm:"column:goods_type_id;not null;default:0"`
+	CycleExpireAt time.Time `gorm:"column:cycle_expire_at;not null;uniqueIndex:idx_dunning_records_step,priority:2"`
+	StepKey       string    `gorm:"size:64;column:step_key;not null;uniqueIndex:idx_dunning_records_step,priority:3"`
+	Action        string    `gorm:"size:32;column:action;not null;index"`
+	OffsetDays    int       `gorm:"column:offset_days;not null;default:0"`
+	Status        string    `gorm:"size:16;column:status;not null;index"`
+	ChannelsJSON  string    `gorm:"type:text;column:channels_json"`
+	ErrorMessage  string    `gorm:"type:text;column:error_message"`
+	Attempts      int       `gorm:"column:attempts;not null;default:1"`
+	ExecutedAt    time.Time `gorm:"column:executed_at;not null;index"`
+}
+
+func (dunningRecordRow) TableName() string { return "dunning_records" }
+
+type orderApprovalRuleRow struct {
+	ID                 int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	Name               string    `gorm:"size:128;column:name;not null"`
+	Priority           int       `gorm:"column:priority;not null;default:0"`
+	Enabled            int       `gorm:"column:enabled;not null;default:1"`
+	Decision           string    `gorm:"size:16;column:decision;not null"`
+	MinAmount          int64     `gorm:"column:min_amount;not null;default:0"`
+	MaxAccountAgeDays  int       `gorm:"column:max_account_age_days;not null;default:0"`
+	RealNameUnverified int       `gorm:"column:realname_unverified;not null;default:0"`
+	Countries          string    `gorm:"size:1000;column:countries;not null;default:''"`
+	MinInstances       int       `gorm:"column:min_instances;not null;default:0"`
+	MinRecentRefunds   int       `gorm:"column:min_recent_refunds;not null;default:0"`
+	Reason             string    `gorm:"size:500;column:reason;not null;default:''"`
+	CreatedAt          time.Time `gorm:"column:created_at;not null;autoCreateTime"`
+	UpdatedAt          time.Time `gorm:"column:updated_at;not null;autoUpdateTime"`
+}
+
+func (orderApprovalRuleRow) TableName() string { return "order_approval_rules" }
+
+type orderApprovalEvaluationRow struct {
+	ID               int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	OrderID          int64     `gorm:"column:order_id;not null;uniqueIndex"`
+	UserID           int64     `gorm:"column:user_id;not null;index"`
+	Decision         string    `gorm:"size:16;column:decision;not null"`
+	RuleID           int64     `gorm:"column:rule_id;not null;default:0"`
+	RuleName         string    `gorm:"size:128;column:rule_name;not null;default:''"`
+	Reason           string    `gorm:"size:500;column:reason;not null;default:''"`
+	Amount           int64     `gorm:"column:amount;not null;default:0"`
+	AccountAgeDays   int       `gorm:"column:account_age_days;not null;default:0"`
+	RealNameVerified int       `gorm:"column:realname_verified;not null;default:0"`
+	ClientIP         string    `gorm:"size:64;column:client_ip;not null;default:''"`
+	Country          string    `gorm:"size:8;column:country;not null;default:''"`
+	Instances        int       `gorm:"column:instances;not null;default:0"`
+	RecentRefunds    int       `gorm:"column:recent_refunds;not null;default:0"`
+	CreatedAt        time.Time `gorm:"column:created_at;not null;autoCreateTime"`
+}
+
+func (orderApprovalEvaluationRow) TableName() string { return "order_approval_evaluations" }
+
 type settingRow struct {
 	Key       string    `gorm:"size:191;primaryKey;column:key"`
 	ValueJSON string    `gorm:"column:value_json;not null"`
@@ -383,6 +621,21 @@ type provisionJobRow struct {
 
 func (provisionJobRow) TableName() string { return "provision_jobs" }
 
+type inventoryReservationRow struct {
+	ID          int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	OrderID     int64      `gorm:"column:order_id;not null;index"`
+	OrderItemID int64      `gorm:"column:order_item_id;not null;uniqueIndex:idx_inventory_reservations_item"`
+	PackageID   int64      `gorm:"column:package_id;not null;index"`
+	PlanGroupID int64      `gorm:"column:plan_group_id;not null;default:0;index"`
+	Qty         int        `gorm:"column:qty;not null;default:1"`
+	Status      string     `gorm:"column:status;not null;index:idx_inventory_reservations_status_expires"`
+	ExpiresAt   *time.Time `gorm:"column:expires_at;index:idx_inventory_reservations_status_expires"`
+	CreatedAt   time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at;not null;autoUpdateTime"`
+}
+
+func (inventoryReservationRow) TableName() string { return "inventory_reservations" }
+
 type resizeTaskRow struct {
 	ID          int64      `gorm:"primaryKey;autoIncrement;column:id"`
 	VPSID       int64      `gorm:"column:vps_id;not null;index"`
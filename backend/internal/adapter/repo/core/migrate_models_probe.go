@@ -15,6 +15,10 @@ type probeNodeRow struct {
 	LastHeartbeatAt  *time.Time `gorm:"column:last_heartbeat_at;index:idx_probe_nodes_heartbeat"`
 	LastSnapshotAt   *time.Time `gorm:"column:last_snapshot_at"`
 	LastSnapshotJSON string     `gorm:"type:longtext;column:last_snapshot_json;not null"`
+	AgentVersion     string     `gorm:"size:64;column:agent_version;not null;default:''"`
+	AgentUpdateState string     `gorm:"size:32;column:agent_update_status;not null;default:''"`
+	AgentUpdateMsg   string     `gorm:"column:agent_update_message;not null;default:''"`
+	AgentUpdatedAt   *time.Time `gorm:"column:agent_updated_at"`
 	CreatedAt        time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
 	UpdatedAt        time.Time  `gorm:"column:updated_at;not null;autoUpdateTime"`
 }
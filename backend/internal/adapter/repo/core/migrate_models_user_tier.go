@@ -18,12 +18,13 @@ func (userTierGroupRow) TableName() string { return "user_tier_groups" }
 
 type userTierDiscountRuleRow struct {
 	ID               int64     `gorm:"primaryKey;autoIncrement;column:id"`
-	GroupID          int64     `gorm:"column:group_id;not null;index;uniqueIndex:idx_user_tier_rule_scope_unique,priority:1"`
-	Scope            string    `gorm:"size:64;column:scope;not null;uniqueIndex:idx_user_tier_rule_scope_unique,priority:2"`
-	GoodsTypeID      int64     `gorm:"column:goods_type_id;not null;default:0;uniqueIndex:idx_user_tier_rule_scope_unique,priority:3"`
-	RegionID         int64     `gorm:"column:region_id;not null;default:0;uniqueIndex:idx_user_tier_rule_scope_unique,priority:4"`
-	PlanGroupID      int64     `gorm:"column:plan_group_id;not null;default:0;uniqueIndex:idx_user_tier_rule_scope_unique,priority:5"`
-	PackageID        int64     `gorm:"column:package_id;not null;default:0;uniqueIndex:idx_user_tier_rule_scope_unique,priority:6"`
+	GroupID          int64     `gorm:"column:group_id;not null;index;uniqueIndex:idx_user_tier_rule_scope_addon_unique,priority:1"`
+	Scope            string    `gorm:"size:64;column:scope;not null;uniqueIndex:idx_user_tier_rule_scope_addon_unique,priority:2"`
+	GoodsTypeID      int64     `gorm:"column:goods_type_id;not null;default:0;uniqueIndex:idx_user_tier_rule_scope_addon_unique,priority:3"`
+	RegionID         int64     `gorm:"column:region_id;not null;default:0;uniqueIndex:idx_user_tier_rule_scope_addon_unique,priority:4"`
+	PlanGroupID      int64     `gorm:"column:plan_group_id;not null;default:0;uniqueIndex:idx_user_tier_rule_scope_addon_unique,priority:5"`
+	PackageID        int64     `gorm:"column:package_id;not null;default:0;uniqueIndex:idx_user_tier_rule_scope_addon_unique,priority:6"`
+	AddonID          int64     `gorm:"column:addon_id;not null;default:0;uniqueIndex:idx_user_tier_rule_scope_addon_unique,priority:7"`
 	DiscountPermille int       `gorm:"column:discount_permille;not null;default:0"`
 	FixedPrice       *int64    `gorm:"column:fixed_price"`
 	AddCorePermille  int       `gorm:"column:add_core_permille;not null;default:0"`
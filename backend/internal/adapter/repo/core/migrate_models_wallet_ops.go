@@ -26,6 +26,153 @@ type walletTransactionRow struct {
 
 func (walletTransactionRow) TableName() string { return "wallet_transactions" }
 
+type walletHoldRow struct {
+	ID        int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	UserID    int64      `gorm:"column:user_id;not null;index:idx_wallet_holds_user_status,priority:1"`
+	Amount    int64      `gorm:"column:amount;not null"`
+	RefType   string     `gorm:"size:64;column:ref_type;not null;index:idx_wallet_holds_ref,priority:1"`
+	RefID     int64      `gorm:"column:ref_id;not null;default:0;index:idx_wallet_holds_ref,priority:2"`
+	Status    string     `gorm:"size:32;column:status;not null;index:idx_wallet_holds_user_status,priority:2"`
+	Note      string     `gorm:"size:500;column:note;not null;default:''"`
+	CreatedAt time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
+	SettledAt *time.Time `gorm:"column:settled_at"`
+}
+
+func (walletHoldRow) TableName() string { return "wallet_holds" }
+
+type ledgerAccountRow struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	Code      string    `gorm:"size:128;column:code;not null;uniqueIndex"`
+	Type      string    `gorm:"size:32;column:type;not null"`
+	UserID    int64     `gorm:"column:user_id;not null;default:0;index"`
+	CreatedAt time.Time `gorm:"column:created_at;not null;autoCreateTime"`
+}
+
+func (ledgerAccountRow) TableName() string { return "ledger_accounts" }
+
+type ledgerEntryRow struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	EntryKey  string    `gorm:"size:191;column:entry_key;not null;uniqueIndex"`
+	Kind      string    `gorm:"size:64;column:kind;not null;index"`
+	RefType   string    `gorm:"size:64;column:ref_type;not null;index:idx_ledger_entries_ref,priority:1"`
+	RefID     int64     `gorm:"column:ref_id;not null;default:0;index:idx_ledger_entries_ref,priority:2"`
+	UserID    int64     `gorm:"column:user_id;not null;default:0;index"`
+	Memo      string    `gorm:"size:500;column:memo;not null;default:''"`
+	CreatedAt time.Time `gorm:"column:created_at;not null;autoCreateTime"`
+}
+
+func (ledgerEntryRow) TableName() string { return "ledger_entries" }
+
+type ledgerLineRow struct {
+	ID      int64  `gorm:"primaryKey;autoIncrement;column:id"`
+	EntryID int64  `gorm:"column:entry_id;not null;index"`
+	Account string `gorm:"size:128;column:account;not null;index"`
+	UserID  int64  `gorm:"column:user_id;not null;default:0;index"`
+	Debit   int64  `gorm:"column:debit;not null;default:0"`
+	Credit  int64  `gorm:"column:credit;not null;default:0"`
+}
+
+func (ledgerLineRow) TableName() string { return "ledger_lines" }
+
+type payoutAccountRow struct {
+	ID           int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	UserID       int64      `gorm:"column:user_id;not null;index"`
+	Channel      string     `gorm:"size:32;column:channel;not null"`
+	AccountName  string     `gorm:"size:128;column:account_name;not null"`
+	AccountNo    string     `gorm:"size:128;column:account_no;not null"`
+	BankName     string     `gorm:"size:128;column:bank_name;not null;default:''"`
+	BankBranch   string     `gorm:"size:255;column:bank_branch;not null;default:''"`
+	Status       string     `gorm:"size:32;column:status;not null;index"`
+	ReviewReason string     `gorm:"size:1000;column:review_reason;not null;default:''"`
+	ReviewedBy   *int64     `gorm:"column:reviewed_by"`
+	ReviewedAt   *time.Time `gorm:"column:reviewed_at"`
+	CreatedAt    time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at;not null;autoUpdateTime"`
+}
+
+func (payoutAccountRow) TableName() string { return "payout_accounts" }
+
+type payoutBatchRow struct {
+	ID          int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	Channel     string     `gorm:"size:32;column:channel;not null"`
+	Status      string     `gorm:"size:32;column:status;not null;index"`
+	ItemCount   int        `gorm:"column:item_count;not null;default:0"`
+	TotalAmount int64      `gorm:"column:total_amount;not null;default:0"`
+	Reference   string     `gorm:"size:255;column:reference;not null;default:''"`
+	Note        string     `gorm:"size:1000;column:note;not null;default:''"`
+	CreatedBy   int64      `gorm:"column:created_by;not null;default:0"`
+	ExportedAt  *time.Time `gorm:"column:exported_at"`
+	SettledAt   *time.Time `gorm:"column:settled_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at;not null;autoUpdateTime"`
+}
+
+func (payoutBatchRow) TableName() string { return "payout_batches" }
+
+type payoutBatchItemRow struct {
+	ID              int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	BatchID         int64      `gorm:"column:batch_id;not null;index"`
+	WalletOrderID   int64      `gorm:"column:wallet_order_id;not null;uniqueIndex"`
+	UserID          int64      `gorm:"column:user_id;not null;index"`
+	Amount          int64      `gorm:"column:amount;not null"`
+	PayoutAccountID int64      `gorm:"column:payout_account_id;not null"`
+	AccountName     string     `gorm:"size:128;column:account_name;not null"`
+	AccountNo       string     `gorm:"size:128;column:account_no;not null"`
+	BankName        string     `gorm:"size:128;column:bank_name;not null;default:''"`
+	BankBranch      string     `gorm:"size:255;column:bank_branch;not null;default:''"`
+	Status          string     `gorm:"size:32;column:status;not null"`
+	Reference       string     `gorm:"size:255;column:reference;not null;default:''"`
+	FailReason      string     `gorm:"size:1000;column:fail_reason;not null;default:''"`
+	SettledAt       *time.Time `gorm:"column:settled_at"`
+}
+
+func (payoutBatchItemRow) TableName() string { return "payout_batch_items" }
+
+type financeReportDefinitionRow struct {
+	ID             int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	Name           string     `gorm:"size:128;column:name;not null"`
+	Period         string     `gorm:"size:32;column:period_type;not null"`
+	FormatsJSON    string     `gorm:"size:255;column:formats_json;not null;default:''"`
+	RecipientsJSON string     `gorm:"type:text;column:recipients_json;not null"`
+	Enabled        int        `gorm:"column:enabled;not null;default:1"`
+	CreatedBy      int64      `gorm:"column:created_by;not null;default:0"`
+	LastRunAt      *time.Time `gorm:"column:last_run_at"`
+	CreatedAt      time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at;not null;autoUpdateTime"`
+}
+
+func (financeReportDefinitionRow) TableName() string { return "finance_report_definitions" }
+
+type financeReportRunRow struct {
+	ID             int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	DefinitionID   int64      `gorm:"column:definition_id;not null;index:idx_finance_report_runs_period,priority:1"`
+	Name           string     `gorm:"size:128;column:name;not null"`
+	Period         string     `gorm:"size:32;column:period_type;not null"`
+	PeriodStart    time.Time  `gorm:"column:period_start;not null;index:idx_finance_report_runs_period,priority:2"`
+	PeriodEnd      time.Time  `gorm:"column:period_end;not null"`
+	Trigger        string     `gorm:"size:32;column:trigger_type;not null"`
+	Status         string     `gorm:"size:32;column:status;not null"`
+	Error          string     `gorm:"type:text;column:error_message"`
+	RecipientsJSON string     `gorm:"type:text;column:recipients_json;not null"`
+	CreatedBy      int64      `gorm:"column:created_by;not null;default:0"`
+	DeliveredAt    *time.Time `gorm:"column:delivered_at"`
+	CreatedAt      time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
+}
+
+func (financeReportRunRow) TableName() string { return "finance_report_runs" }
+
+type financeReportFileRow struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	RunID       int64     `gorm:"column:run_id;not null;index"`
+	Name        string    `gorm:"size:255;column:name;not null"`
+	ContentType string    `gorm:"size:128;column:content_type;not null"`
+	Size        int64     `gorm:"column:size;not null;default:0"`
+	Content     []byte    `gorm:"column:content"`
+	CreatedAt   time.Time `gorm:"column:created_at;not null;autoCreateTime"`
+}
+
+func (financeReportFileRow) TableName() string { return "finance_report_files" }
+
 type walletOrderRow struct {
 	ID           int64     `gorm:"primaryKey;autoIncrement;column:id"`
 	UserID       int64     `gorm:"column:user_id;not null;index"`
@@ -80,6 +227,67 @@ type pushTokenRow struct {
 
 func (pushTokenRow) TableName() string { return "push_tokens" }
 
+type notificationPreferenceRow struct {
+	UserID            int64     `gorm:"primaryKey;autoIncrement:false;column:user_id"`
+	DisabledJSON      string    `gorm:"column:disabled_json;type:text;not null"`
+	QuietHoursEnabled int       `gorm:"column:quiet_hours_enabled;not null;default:0"`
+	QuietStart        string    `gorm:"column:quiet_start;not null;default:''"`
+	QuietEnd          string    `gorm:"column:quiet_end;not null;default:''"`
+	Timezone          string    `gorm:"column:timezone;not null;default:''"`
+	UpdatedAt         time.Time `gorm:"column:updated_at;not null;autoUpdateTime"`
+}
+
+func (notificationPreferenceRow) TableName() string { return "notification_preferences" }
+
+type deferredNotificationRow struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement;column:id"`
+	UserID      int64     `gorm:"column:user_id;not null"`
+	Channel     string    `gorm:"size:16;column:channel;not null;index:idx_deferred_notifications_due,priority:1"`
+	Type        string    `gorm:"size:64;column:type;not null"`
+	PayloadJSON string    `gorm:"column:payload_json;type:text;not null"`
+	SendAfter   time.Time `gorm:"column:send_after;not null;index:idx_deferred_notifications_due,priority:2"`
+	CreatedAt   time.Time `gorm:"column:created_at;not null;autoCreateTime"`
+}
+
+func (deferredNotificationRow) TableName() string { return "deferred_notifications" }
+
+type broadcastRow struct {
+	ID           int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	Title        string     `gorm:"column:title;not null"`
+	Content      string     `gorm:"column:content;type:text;not null"`
+	LinkURL      string     `gorm:"size:1024;column:link_url;not null;default:''"`
+	ChannelsJSON string     `gorm:"column:channels_json;type:text;not null"`
+	SegmentJSON  string     `gorm:"column:segment_json;type:text;not null"`
+	Status       string     `gorm:"size:16;column:status;not null;index:idx_broadcasts_due,priority:1"`
+	ScheduledAt  time.Time  `gorm:"column:scheduled_at;not null;index:idx_broadcasts_due,priority:2"`
+	StartedAt    *time.Time `gorm:"column:started_at"`
+	FinishedAt   *time.Time `gorm:"column:finished_at"`
+	Total        int        `gorm:"column:total;not null;default:0"`
+	SentCount    int        `gorm:"column:sent_count;not null;default:0"`
+	FailedCount  int        `gorm:"column:failed_count;not null;default:0"`
+	CreatedBy    int64      `gorm:"column:created_by;not null;default:0"`
+	CreatedAt    time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at;not null;autoUpdateTime"`
+}
+
+func (broadcastRow) TableName() string { return "broadcasts" }
+
+type broadcastDeliveryRow struct {
+	ID             int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	BroadcastID    int64      `gorm:"column:broadcast_id;not null;uniqueIndex:idx_broadcast_deliveries_unique,priority:1;index:idx_broadcast_deliveries_pending,priority:1"`
+	UserID         int64      `gorm:"column:user_id;not null;uniqueIndex:idx_broadcast_deliveries_unique,priority:2"`
+	Channel        string     `gorm:"size:16;column:channel;not null;uniqueIndex:idx_broadcast_deliveries_unique,priority:3;index:idx_broadcast_deliveries_pending,priority:2"`
+	Status         string     `gorm:"size:16;column:status;not null;index:idx_broadcast_deliveries_pending,priority:3"`
+	Error          string     `gorm:"column:error;type:text"`
+	Token          string     `gorm:"size:64;column:token;not null;uniqueIndex"`
+	NotificationID int64      `gorm:"column:notification_id;not null;default:0"`
+	SentAt         *time.Time `gorm:"column:sent_at"`
+	ClickedAt      *time.Time `gorm:"column:clicked_at"`
+	CreatedAt      time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
+}
+
+func (broadcastDeliveryRow) TableName() string { return "broadcast_deliveries" }
+
 type realnameVerificationRow struct {
 	ID         int64      `gorm:"primaryKey;autoIncrement;column:id"`
 	UserID     int64      `gorm:"column:user_id;not null;index"`
@@ -88,8 +296,36 @@ type realnameVerificationRow struct {
 	Status     string     `gorm:"column:status;not null"`
 	Provider   string     `gorm:"column:provider;not null"`
 	Reason     string     `gorm:"column:reason;not null;default:''"`
+	UploadIDs  string     `gorm:"column:upload_ids_json;not null;default:''"`
+	ReviewedBy *int64     `gorm:"column:reviewed_by"`
+	ReviewedAt *time.Time `gorm:"column:reviewed_at"`
 	CreatedAt  time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
 	VerifiedAt *time.Time `gorm:"column:verified_at"`
 }
 
 func (realnameVerificationRow) TableName() string { return "realname_verifications" }
+
+type companyVerificationRow struct {
+	ID                  int64      `gorm:"primaryKey;autoIncrement;column:id"`
+	UserID              int64      `gorm:"column:user_id;not null;index"`
+	CompanyName         string     `gorm:"column:company_name;not null"`
+	CreditCode          string     `gorm:"column:credit_code;not null;index"`
+	LegalPersonName     string     `gorm:"column:legal_person_name;not null"`
+	LegalPersonIDNumber string     `gorm:"column:legal_person_id_number;not null"`
+	InvoiceTitle        string     `gorm:"column:invoice_title;not null;default:''"`
+	TaxNumber           string     `gorm:"column:tax_number;not null;default:''"`
+	InvoiceAddress      string     `gorm:"column:invoice_address;not null;default:''"`
+	InvoicePhone        string     `gorm:"column:invoice_phone;not null;default:''"`
+	InvoiceBank         string     `gorm:"column:invoice_bank;not null;default:''"`
+	InvoiceBankAccount  string     `gorm:"column:invoice_bank_account;not null;default:''"`
+	UploadIDs           string     `gorm:"column:upload_ids_json;not null;default:''"`
+	Status              string     `gorm:"column:status;not null;index"`
+	Provider            string     `gorm:"column:provider;not null"`
+	Reason              string     `gorm:"column:reason;not null;default:''"`
+	ReviewedBy          *int64     `gorm:"column:reviewed_by"`
+	ReviewedAt          *time.Time `gorm:"column:reviewed_at"`
+	CreatedAt           time.Time  `gorm:"column:created_at;not null;autoCreateTime"`
+	VerifiedAt          *time.Time `gorm:"column:verified_at"`
+}
+
+func (companyVerificationRow) TableName() string { return "company_verifications" }
@@ -100,42 +100,63 @@ func NewProbeLogSessionRepo(gdb *gorm.DB) *ProbeLogSessionRepo {
 }
 
 var (
-	_ appports.UserRepository                = (*UserRepo)(nil)
-	_ appports.CaptchaRepository             = (*CaptchaRepo)(nil)
-	_ appports.CatalogRepository             = (*CatalogRepo)(nil)
-	_ appports.SystemImageRepository         = (*SystemImageRepo)(nil)
-	_ appports.CartRepository                = (*CartRepo)(nil)
-	_ appports.OrderRepository               = (*OrderRepo)(nil)
-	_ appports.OrderItemRepository           = (*OrderItemRepo)(nil)
-	_ appports.PaymentRepository             = (*PaymentRepo)(nil)
-	_ appports.VPSRepository                 = (*VPSRepo)(nil)
-	_ appports.EventRepository               = (*EventRepo)(nil)
-	_ appports.APIKeyRepository              = (*APIKeyRepo)(nil)
-	_ appports.UserAPIKeyRepository          = (*APIKeyRepo)(nil)
-	_ appports.SettingsRepository            = (*SettingsRepo)(nil)
-	_ appports.AuditRepository               = (*AuditRepo)(nil)
-	_ appports.BillingCycleRepository        = (*BillingCycleRepo)(nil)
-	_ appports.AutomationLogRepository       = (*AutomationLogRepo)(nil)
-	_ appports.ProvisionJobRepository        = (*ProvisionJobRepo)(nil)
-	_ appports.ResizeTaskRepository          = (*ResizeTaskRepo)(nil)
-	_ appports.IntegrationLogRepository      = (*IntegrationLogRepo)(nil)
-	_ appports.PermissionGroupRepository     = (*PermissionGroupRepo)(nil)
-	_ appports.UserTierRepository            = (*UserTierRepo)(nil)
-	_ appports.CouponRepository              = (*CouponRepo)(nil)
-	_ appports.PasswordResetTokenRepository  = (*PasswordResetTokenRepo)(nil)
-	_ appports.PasswordResetTicketRepository = (*PasswordResetTicketRepo)(nil)
-	_ appports.PermissionRepository          = (*PermissionRepo)(nil)
-	_ appports.CMSCategoryRepository         = (*CMSCategoryRepo)(nil)
-	_ appports.CMSPostRepository             = (*CMSPostRepo)(nil)
-	_ appports.CMSBlockRepository            = (*CMSBlockRepo)(nil)
-	_ appports.UploadRepository              = (*UploadRepo)(nil)
-	_ appports.TicketRepository              = (*TicketRepo)(nil)
-	_ appports.NotificationRepository        = (*NotificationRepo)(nil)
-	_ appports.PushTokenRepository           = (*PushTokenRepo)(nil)
-	_ appports.WalletRepository              = (*WalletRepo)(nil)
-	_ appports.WalletOrderRepository         = (*WalletOrderRepo)(nil)
-	_ appports.ProbeNodeRepository           = (*ProbeNodeRepo)(nil)
-	_ appports.ProbeEnrollTokenRepository    = (*ProbeEnrollTokenRepo)(nil)
-	_ appports.ProbeStatusEventRepository    = (*ProbeStatusEventRepo)(nil)
-	_ appports.ProbeLogSessionRepository     = (*ProbeLogSessionRepo)(nil)
+	_ appports.UserRepository                   = (*UserRepo)(nil)
+	_ appports.RealNameReviewRepository         = (*UserRepo)(nil)
+	_ appports.CompanyVerificationRepository    = (*UserRepo)(nil)
+	_ appports.CaptchaRepository                = (*CaptchaRepo)(nil)
+	_ appports.CatalogRepository                = (*CatalogRepo)(nil)
+	_ appports.AddonRepository                  = (*CatalogRepo)(nil)
+	_ appports.InventoryRepository              = (*CatalogRepo)(nil)
+	_ appports.SystemImageRepository            = (*SystemImageRepo)(nil)
+	_ appports.CartRepository                   = (*CartRepo)(nil)
+	_ appports.OrderRepository                  = (*OrderRepo)(nil)
+	_ appports.OrderApprovalRepository          = (*OrderRepo)(nil)
+	_ appports.OrderItemRepository              = (*OrderItemRepo)(nil)
+	_ appports.PaymentRepository                = (*PaymentRepo)(nil)
+	_ appports.VPSRepository                    = (*VPSRepo)(nil)
+	_ appports.VPSBackupPolicyRepository        = (*VPSRepo)(nil)
+	_ appports.VPSBulkJobRepository             = (*VPSRepo)(nil)
+	_ appports.VPSMigrationRepository           = (*VPSRepo)(nil)
+	_ appports.IPAddressRepository              = (*VPSRepo)(nil)
+	_ appports.VPSTrialRepository               = (*VPSRepo)(nil)
+	_ appports.DunningRepository                = (*VPSRepo)(nil)
+	_ appports.EventRepository                  = (*EventRepo)(nil)
+	_ appports.APIKeyRepository                 = (*APIKeyRepo)(nil)
+	_ appports.UserAPIKeyRepository             = (*APIKeyRepo)(nil)
+	_ appports.SSHKeyRepository                 = (*UserRepo)(nil)
+	_ appports.SettingsRepository               = (*SettingsRepo)(nil)
+	_ appports.AuditRepository                  = (*AuditRepo)(nil)
+	_ appports.BillingCycleRepository           = (*BillingCycleRepo)(nil)
+	_ appports.AutomationLogRepository          = (*AutomationLogRepo)(nil)
+	_ appports.ProvisionJobRepository           = (*ProvisionJobRepo)(nil)
+	_ appports.ResizeTaskRepository             = (*ResizeTaskRepo)(nil)
+	_ appports.IntegrationLogRepository         = (*IntegrationLogRepo)(nil)
+	_ appports.PermissionGroupRepository        = (*PermissionGroupRepo)(nil)
+	_ appports.UserTierRepository               = (*UserTierRepo)(nil)
+	_ appports.CouponRepository                 = (*CouponRepo)(nil)
+	_ appports.PasswordResetTokenRepository     = (*PasswordResetTokenRepo)(nil)
+	_ appports.PasswordResetTicketRepository    = (*PasswordResetTicketRepo)(nil)
+	_ appports.PermissionRepository             = (*PermissionRepo)(nil)
+	_ appports.CMSCategoryRepository            = (*CMSCategoryRepo)(nil)
+	_ appports.CMSPostRepository                = (*CMSPostRepo)(nil)
+	_ appports.CMSBlockRepository               = (*CMSBlockRepo)(nil)
+	_ appports.UploadRepository                 = (*UploadRepo)(nil)
+	_ appports.TicketRepository                 = (*TicketRepo)(nil)
+	_ appports.NotificationRepository           = (*NotificationRepo)(nil)
+	_ appports.NotificationPreferenceRepository = (*NotificationRepo)(nil)
+	_ appports.DeferredNotificationRepository   = (*NotificationRepo)(nil)
+	_ appports.BroadcastRepository              = (*NotificationRepo)(nil)
+	_ appports.PushTokenRepository              = (*PushTokenRepo)(nil)
+	_ appports.WalletRepository                 = (*WalletRepo)(nil)
+	_ appports.WalletStatsRepository            = (*WalletRepo)(nil)
+	_ appports.WalletHoldRepository             = (*WalletRepo)(nil)
+	_ appports.LedgerRepository                 = (*WalletRepo)(nil)
+	_ appports.PayoutRepository                 = (*WalletRepo)(nil)
+	_ appports.FinanceReportRepository          = (*WalletRepo)(nil)
+	_ appports.StatementRepository              = (*WalletRepo)(nil)
+	_ appports.WalletOrderRepository            = (*WalletOrderRepo)(nil)
+	_ appports.ProbeNodeRepository              = (*ProbeNodeRepo)(nil)
+	_ appports.ProbeEnrollTokenRepository       = (*ProbeEnrollTokenRepo)(nil)
+	_ appports.ProbeStatusEventRepository       = (*ProbeStatusEventRepo)(nil)
+	_ appports.ProbeLogSessionRepository        = (*ProbeLogSessionRepo)(nil)
 )
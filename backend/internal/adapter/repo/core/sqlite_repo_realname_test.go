@@ -0,0 +1,113 @@
+package repo_test
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/pkg/cryptox"
+	"xiaoheiplay/internal/testutil"
+)
+
+func TestSQLiteRepo_RealNameIDNumberEncryption(t *testing.T) {
+	conn, r := newTestRepo(t)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, r, "rn", "rn@example.com", "pass")
+
+	legacy := domain.RealNameVerification{UserID: user.ID, RealName: "Legacy", IDNumber: "110101199001011234", Status: "verified", Provider: "fake"}
+	if err := r.CreateRealNameVerification(ctx, &legacy); err != nil {
+		t.Fatalf("create legacy: %v", err)
+	}
+
+	cipher, err := cryptox.NewAESGCM(base64.RawURLEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+	if err != nil {
+		t.Fatalf("cipher: %v", err)
+	}
+	r.SetRealNameCipher(cipher)
+	if n, err := r.EncryptLegacyIDNumbers(ctx); err != nil || n != 1 {
+		t.Fatalf("encrypt legacy: n=%d err=%v", n, err)
+	}
+
+	pending := domain.RealNameVerification{UserID: user.ID, RealName: "Manual", IDNumber: "110101199001015678", Status: domain.RealNameStatusPendingReview, Provider: domain.RealNameProviderManual, UploadIDs: []int64{3, 4}}
+	if err := r.CreateRealNameVerification(ctx, &pending); err != nil {
+		t.Fatalf("create pending: %v", err)
+	}
+	rows, err := conn.Query("SELECT id_number FROM realname_verifications")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var stored string
+		if err := rows.Scan(&stored); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		if !strings.HasPrefix(stored, "enc:") || strings.Contains(stored, "1101011990") {
+			t.Fatalf("id number stored in plaintext: %s", stored)
+		}
+	}
+
+	got, err := r.GetRealNameVerification(ctx, pending.ID)
+	if err != nil || got.IDNumber != pending.IDNumber || len(got.UploadIDs) != 2 {
+		t.Fatalf("unexpected pending record: %+v %v", got, err)
+	}
+	items, total, err := r.ListRealNameVerificationsByStatus(ctx, domain.RealNameStatusPendingReview, 10, 0)
+	if err != nil || total != 1 || items[0].ID != pending.ID {
+		t.Fatalf("list by status: %+v total=%d err=%v", items, total, err)
+	}
+
+	updated, err := r.ReviewRealNameVerification(ctx, pending.ID, "verified", "", 9, time.Now())
+	if err != nil || !updated {
+		t.Fatalf("review: %v %v", updated, err)
+	}
+	if updated, _ := r.ReviewRealNameVerification(ctx, pending.ID, "failed", "again", 9, time.Now()); updated {
+		t.Fatalf("reviewed record must not be reviewed twice")
+	}
+	got, err = r.GetLatestRealNameVerification(ctx, user.ID)
+	if err != nil || got.Status != "verified" || got.VerifiedAt == nil || got.ReviewedBy == nil || *got.ReviewedBy != 9 {
+		t.Fatalf("unexpected reviewed record: %+v %v", got, err)
+	}
+}
+
+func TestSQLiteRepo_CompanyVerification(t *testing.T) {
+	conn, r := newTestRepo(t)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, r, "co", "co@example.com", "pass")
+	cipher, err := cryptox.NewAESGCM(base64.RawURLEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+	if err != nil {
+		t.Fatalf("cipher: %v", err)
+	}
+	r.SetRealNameCipher(cipher)
+
+	record := domain.CompanyVerification{
+		UserID: user.ID, CompanyName: "Acme Ltd", CreditCode: "91110000MA01ABCD3X", LegalPersonName: "Alice",
+		LegalPersonIDNumber: "110101199001011234", TaxNumber: "91110000MA01ABCD3X",
+		Status: domain.RealNameStatusPendingReview, Provider: domain.RealNameProviderManual, UploadIDs: []int64{5},
+	}
+	if err := r.CreateCompanyVerification(ctx, &record); err != nil {
+		t.Fatalf("create company: %v", err)
+	}
+	var stored string
+	if err := conn.QueryRow("SELECT legal_person_id_number FROM company_verifications WHERE id = ?", record.ID).Scan(&stored); err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if !strings.HasPrefix(stored, "enc:") {
+		t.Fatalf("legal person id number stored in plaintext: %s", stored)
+	}
+
+	userID := user.ID
+	items, total, err := r.ListCompanyVerifications(ctx, &userID, domain.RealNameStatusPendingReview, 10, 0)
+	if err != nil || total != 1 || items[0].LegalPersonIDNumber != record.LegalPersonIDNumber || len(items[0].UploadIDs) != 1 {
+		t.Fatalf("list companies: %+v total=%d err=%v", items, total, err)
+	}
+	if updated, err := r.ReviewCompanyVerification(ctx, record.ID, "verified", "", 3, time.Now()); err != nil || !updated {
+		t.Fatalf("review company: %v %v", updated, err)
+	}
+	latest, err := r.GetLatestCompanyVerification(ctx, user.ID)
+	if err != nil || latest.Status != "verified" || latest.VerifiedAt == nil {
+		t.Fatalf("latest company: %+v %v", latest, err)
+	}
+}
@@ -251,6 +251,9 @@ func EnsureSettings(gdb *gorm.DB) error {
 		"payment_providers_config":                 `{}`,
 		"payment_plugins":                          "[]",
 		"payment_plugin_dir":                       "plugins/payment",
+		"plugin_repositories":                      "[]",
+		"notify_channel_routes":                    "[]",
+		"notify_email_channel":                     "",
 		"payment_plugin_upload_password":           defaultPluginUploadPassword,
 		"robot_webhook_url":                        "",
 		"robot_webhook_secret":                     "",
@@ -293,6 +296,7 @@ func EnsureSettings(gdb *gorm.DB) error {
 		"resize_min_refund":                        "0",
 		"resize_charge_curve_json":                 "[]",
 		"resize_refund_to_wallet":                  "true",
+		"inventory_reservation_ttl_min":            "30",
 		"debug_enabled":                            "false",
 		"automation_base_url":                      "",
 		"automation_api_key":                       "",
@@ -379,6 +383,12 @@ func EnsureSettings(gdb *gorm.DB) error {
 		"probe_log_session_ttl_sec":                "600",
 		"probe_log_chunk_max_bytes":                "16384",
 		"probe_log_file_source":                    "file:logs",
+		"probe_agent_desired_version":              "",
+		"probe_agent_update_rollback_sec":          "120",
+		"metrics_enabled":                          "false",
+		"metrics_api_key":                          "",
+		"metrics_ip_allowlist":                     "",
+		"metrics_probe_host_enabled":               "false",
 		"admin_path":                               "",
 	}
 
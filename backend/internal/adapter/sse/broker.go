@@ -59,6 +59,17 @@ func (b *Broker) Unsubscribe(orderID int64, ch chan domain.OrderEvent) {
 	}
 }
 
+// SubscriberCount returns the number of open order event streams.
+func (b *Broker) SubscriberCount() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	n := 0
+	for _, subs := range b.subs {
+		n += len(subs)
+	}
+	return n
+}
+
 func (b *Broker) Stream(ctx context.Context, w http.ResponseWriter, orderID int64, lastSeq int64) error {
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
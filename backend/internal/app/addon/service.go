@@ -0,0 +1,205 @@
+package addon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+const maxNameLen = 64
+
+type CartAddon = appshared.CartAddon
+
+// Service manages the addon SKUs sold with plan groups, prices the ones picked
+// in a cart spec and answers the resource quotas they grant to an instance.
+type Service struct {
+	repo    appports.AddonRepository
+	catalog appports.CatalogRepository
+	pricer  addonPricer
+}
+
+type addonPricer interface {
+	ResolveAddonPrice(ctx context.Context, userID int64, addon domain.Addon) (int64, error)
+}
+
+func NewService(repo appports.AddonRepository, catalog appports.CatalogRepository) *Service {
+	return &Service{repo: repo, catalog: catalog}
+}
+
+func (s *Service) SetAddonPricer(pricer addonPricer) {
+	s.pricer = pricer
+}
+
+// List returns the addons of a plan group, or every addon when planGroupID
+// is 0.
+func (s *Service) List(ctx context.Context, planGroupID int64) ([]domain.Addon, error) {
+	return s.repo.ListAddons(ctx, planGroupID)
+}
+
+// ListActive returns the addons a buyer can pick for a plan group, priced
+// for that buyer.
+func (s *Service) ListActive(ctx context.Context, userID, planGroupID int64) ([]domain.Addon, error) {
+	if planGroupID <= 0 {
+		return nil, appshared.ErrInvalidInput
+	}
+	items, err := s.repo.ListAddons(ctx, planGroupID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.Addon, 0, len(items))
+	for _, item := range items {
+		if !item.Active {
+			continue
+		}
+		item.Monthly = s.price(ctx, userID, item)
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+func (s *Service) Get(ctx context.Context, id int64) (domain.Addon, error) {
+	return s.repo.GetAddon(ctx, id)
+}
+
+func (s *Service) Create(ctx context.Context, addon *domain.Addon) error {
+	if err := s.validate(ctx, addon); err != nil {
+		return err
+	}
+	return s.repo.CreateAddon(ctx, addon)
+}
+
+func (s *Service) Update(ctx context.Context, addon domain.Addon) error {
+	if addon.ID <= 0 {
+		return appshared.ErrInvalidInput
+	}
+	if _, err := s.repo.GetAddon(ctx, addon.ID); err != nil {
+		return err
+	}
+	if err := s.validate(ctx, &addon); err != nil {
+		return err
+	}
+	return s.repo.UpdateAddon(ctx, addon)
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return appshared.ErrInvalidInput
+	}
+	return s.repo.DeleteAddon(ctx, id)
+}
+
+func (s *Service) validate(ctx context.Context, addon *domain.Addon) error {
+	addon.Name = strings.TrimSpace(addon.Name)
+	addon.Description = strings.TrimSpace(addon.Description)
+	switch addon.Kind {
+	case domain.AddonKindIPv4, domain.AddonKindSnapshotQuota, domain.AddonKindBackupPlan:
+	default:
+		return domain.ErrInvalidAddon
+	}
+	if addon.Name == "" || len([]rune(addon.Name)) > maxNameLen {
+		return domain.ErrInvalidAddon
+	}
+	if addon.Monthly < 0 || addon.MaxQty < 0 {
+		return domain.ErrInvalidAddon
+	}
+	if addon.Units <= 0 {
+		addon.Units = 1
+	}
+	if addon.PlanGroupID <= 0 {
+		return domain.ErrInvalidAddon
+	}
+	if _, err := s.catalog.GetPlanGroup(ctx, addon.PlanGroupID); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Resolve checks the picks against the plan group and fills in kind, name,
+// units and the user's monthly price for each of them. Picks must be
+// normalized first.
+func (s *Service) Resolve(ctx context.Context, userID int64, plan domain.PlanGroup, picks []CartAddon) ([]CartAddon, error) {
+	if len(picks) == 0 {
+		return nil, nil
+	}
+	out := make([]CartAddon, 0, len(picks))
+	for _, pick := range picks {
+		addon, err := s.repo.GetAddon(ctx, pick.AddonID)
+		if err != nil {
+			if errors.Is(err, appshared.ErrNotFound) {
+				return nil, domain.ErrInvalidAddon
+			}
+			return nil, err
+		}
+		if !addon.Active || addon.PlanGroupID != plan.ID {
+			return nil, domain.ErrInvalidAddon
+		}
+		if pick.Qty <= 0 || (addon.MaxQty > 0 && pick.Qty > addon.MaxQty) {
+			return nil, domain.ErrInvalidAddon
+		}
+		out = append(out, CartAddon{
+			AddonID: addon.ID,
+			Qty:     pick.Qty,
+			Kind:    addon.Kind,
+			Name:    addon.Name,
+			Units:   addon.Units,
+			Monthly: s.price(ctx, userID, addon),
+		})
+	}
+	return out, nil
+}
+
+func (s *Service) price(ctx context.Context, userID int64, addon domain.Addon) int64 {
+	if s.pricer == nil || userID <= 0 {
+		return addon.Monthly
+	}
+	price, err := s.pricer.ResolveAddonPrice(ctx, userID, addon)
+	if err != nil {
+		return addon.Monthly
+	}
+	return price
+}
+
+// Quota returns how many snapshots or backups an instance may keep. The plan
+// group quota applies first (0 = unlimited, -1 = none) and addons bought with
+// the instance add to it. The bool is false when the instance is unlimited.
+func (s *Service) Quota(ctx context.Context, inst domain.VPSInstance, kind domain.AddonKind) (int, bool, error) {
+	pkg, err := s.catalog.GetPackage(ctx, inst.PackageID)
+	if err != nil {
+		if errors.Is(err, appshared.ErrNotFound) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	plan, err := s.catalog.GetPlanGroup(ctx, pkg.PlanGroupID)
+	if err != nil {
+		if errors.Is(err, appshared.ErrNotFound) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	base := 0
+	switch kind {
+	case domain.AddonKindSnapshotQuota:
+		base = plan.SnapshotQuota
+	case domain.AddonKindBackupPlan:
+		base = plan.BackupQuota
+	default:
+		return 0, false, appshared.ErrInvalidInput
+	}
+	if base == 0 {
+		return 0, false, nil
+	}
+	if base < 0 {
+		base = 0
+	}
+	var spec appshared.CartSpec
+	if strings.TrimSpace(inst.SpecJSON) != "" {
+		_ = json.Unmarshal([]byte(inst.SpecJSON), &spec)
+	}
+	return base + spec.AddonUnits(kind), true, nil
+}
@@ -0,0 +1,101 @@
+package addon_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	appaddon "xiaoheiplay/internal/app/addon"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+type fixedPricer struct{ price int64 }
+
+func (p fixedPricer) ResolveAddonPrice(ctx context.Context, userID int64, addon domain.Addon) (int64, error) {
+	return p.price, nil
+}
+
+func TestAddonService_Resolve(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	seed := testutil.SeedCatalog(t, repo)
+	svc := appaddon.NewService(repo, repo)
+
+	ip := domain.Addon{PlanGroupID: seed.PlanGroup.ID, Kind: domain.AddonKindIPv4, Name: " 额外 IPv4 ", Monthly: 1500, MaxQty: 2, Active: true}
+	if err := svc.Create(ctx, &ip); err != nil {
+		t.Fatalf("create addon: %v", err)
+	}
+	if ip.Name != "额外 IPv4" || ip.Units != 1 {
+		t.Fatalf("unexpected addon: %+v", ip)
+	}
+	if err := svc.Create(ctx, &domain.Addon{PlanGroupID: seed.PlanGroup.ID, Kind: "gpu", Name: "x"}); !errors.Is(err, domain.ErrInvalidAddon) {
+		t.Fatalf("expected invalid kind, got %v", err)
+	}
+	hidden := domain.Addon{PlanGroupID: seed.PlanGroup.ID, Kind: domain.AddonKindBackupPlan, Name: "backup", Monthly: 500}
+	if err := svc.Create(ctx, &hidden); err != nil {
+		t.Fatalf("create inactive addon: %v", err)
+	}
+
+	out, err := svc.Resolve(ctx, 1, seed.PlanGroup, []appshared.CartAddon{{AddonID: ip.ID, Qty: 2}})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if len(out) != 1 || out[0].Kind != domain.AddonKindIPv4 || out[0].Monthly != 1500 || out[0].Units != 1 {
+		t.Fatalf("unexpected resolved addons: %+v", out)
+	}
+	if _, err := svc.Resolve(ctx, 1, seed.PlanGroup, []appshared.CartAddon{{AddonID: ip.ID, Qty: 3}}); !errors.Is(err, domain.ErrInvalidAddon) {
+		t.Fatalf("expected max qty error, got %v", err)
+	}
+	if _, err := svc.Resolve(ctx, 1, seed.PlanGroup, []appshared.CartAddon{{AddonID: hidden.ID, Qty: 1}}); !errors.Is(err, domain.ErrInvalidAddon) {
+		t.Fatalf("expected inactive addon error, got %v", err)
+	}
+	other := seed.PlanGroup
+	other.ID++
+	if _, err := svc.Resolve(ctx, 1, other, []appshared.CartAddon{{AddonID: ip.ID, Qty: 1}}); !errors.Is(err, domain.ErrInvalidAddon) {
+		t.Fatalf("expected plan group mismatch, got %v", err)
+	}
+
+	svc.SetAddonPricer(fixedPricer{price: 900})
+	out, err = svc.Resolve(ctx, 1, seed.PlanGroup, []appshared.CartAddon{{AddonID: ip.ID, Qty: 1}})
+	if err != nil || out[0].Monthly != 900 {
+		t.Fatalf("expected tier price, got %+v err=%v", out, err)
+	}
+	active, err := svc.ListActive(ctx, 1, seed.PlanGroup.ID)
+	if err != nil || len(active) != 1 || active[0].Monthly != 900 {
+		t.Fatalf("unexpected active addons: %+v err=%v", active, err)
+	}
+}
+
+func TestAddonService_Quota(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	seed := testutil.SeedCatalog(t, repo)
+	svc := appaddon.NewService(repo, repo)
+	inst := domain.VPSInstance{PackageID: seed.Package.ID}
+
+	if _, limited, err := svc.Quota(ctx, inst, domain.AddonKindSnapshotQuota); err != nil || limited {
+		t.Fatalf("expected unlimited snapshots, limited=%v err=%v", limited, err)
+	}
+
+	plan := seed.PlanGroup
+	plan.SnapshotQuota = 2
+	plan.BackupQuota = -1
+	if err := repo.UpdatePlanGroup(ctx, plan); err != nil {
+		t.Fatalf("update plan group: %v", err)
+	}
+	spec, _ := json.Marshal(appshared.CartSpec{Addons: []appshared.CartAddon{
+		{AddonID: 1, Qty: 2, Kind: domain.AddonKindSnapshotQuota, Units: 3},
+	}})
+	inst.SpecJSON = string(spec)
+	limit, limited, err := svc.Quota(ctx, inst, domain.AddonKindSnapshotQuota)
+	if err != nil || !limited || limit != 8 {
+		t.Fatalf("expected snapshot quota 8, got %d limited=%v err=%v", limit, limited, err)
+	}
+	limit, limited, err = svc.Quota(ctx, inst, domain.AddonKindBackupPlan)
+	if err != nil || !limited || limit != 0 {
+		t.Fatalf("expected no backups, got %d limited=%v err=%v", limit, limited, err)
+	}
+}
@@ -239,7 +239,7 @@ func (f *usecaseTestAutomation) ShutdownHost(ctx context.Context, hostID int64)
 func (f *usecaseTestAutomation) RebootHost(ctx context.Context, hostID int64) error {
 	return nil
 }
-func (f *usecaseTestAutomation) ResetOS(ctx context.Context, hostID int64, templateID int64, password string) error {
+func (f *usecaseTestAutomation) ResetOS(ctx context.Context, req appshared.AutomationResetOSRequest) error {
 	return nil
 }
 func (f *usecaseTestAutomation) ResetOSPassword(ctx context.Context, hostID int64, password string) error {
@@ -274,6 +274,9 @@ func (f *usecaseTestAutomation) AddFirewallRule(ctx context.Context, req appshar
 func (f *usecaseTestAutomation) DeleteFirewallRule(ctx context.Context, hostID int64, ruleID int64) error {
 	return nil
 }
+func (f *usecaseTestAutomation) SetReverseDNS(ctx context.Context, hostID int64, ip string, ptr string) error {
+	return nil
+}
 func (f *usecaseTestAutomation) ListPortMappings(ctx context.Context, hostID int64) ([]appshared.AutomationPortMapping, error) {
 	return nil, nil
 }
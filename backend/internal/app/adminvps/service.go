@@ -290,6 +290,28 @@ func (s *Service) SetAdminStatus(ctx context.Context, adminID int64, vpsID int64
 	return nil
 }
 
+func (s *Service) Reboot(ctx context.Context, adminID int64, vpsID int64) error {
+	inst, err := s.vps.GetInstance(ctx, vpsID)
+	if err != nil {
+		return err
+	}
+	hostID := parseHostID(inst.AutomationInstanceID)
+	if hostID == 0 {
+		return appshared.ErrInvalidInput
+	}
+	cli, err := s.automation.ClientForGoodsType(ctx, inst.GoodsTypeID)
+	if err != nil {
+		return err
+	}
+	if err := cli.RebootHost(ctx, hostID); err != nil {
+		return err
+	}
+	if s.audit != nil {
+		_ = s.audit.AddAuditLog(ctx, domain.AdminAuditLog{AdminID: adminID, Action: "vps.reboot", TargetType: "vps", TargetID: fmt.Sprintf("%d", inst.ID), DetailJSON: "{}"})
+	}
+	return nil
+}
+
 func (s *Service) EmergencyRenew(ctx context.Context, adminID int64, vpsID int64) (domain.VPSInstance, error) {
 	inst, err := s.vps.GetInstance(ctx, vpsID)
 	if err != nil {
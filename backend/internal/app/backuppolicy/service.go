@@ -0,0 +1,406 @@
+package backuppolicy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+const (
+	maxRetention = 100
+
+	statusSuccess = "success"
+	statusFailed  = "failed"
+	statusSkipped = "skipped"
+)
+
+// vpsRunner is the slice of vps.Service the policies drive. Going through it
+// keeps the snapshot and backup quotas enforced for scheduled runs too.
+type vpsRunner interface {
+	ListSnapshots(ctx context.Context, inst domain.VPSInstance) ([]appshared.AutomationSnapshot, error)
+	CreateSnapshot(ctx context.Context, inst domain.VPSInstance) error
+	DeleteSnapshot(ctx context.Context, inst domain.VPSInstance, snapshotID int64) error
+	ListBackups(ctx context.Context, inst domain.VPSInstance) ([]appshared.AutomationBackup, error)
+	CreateBackup(ctx context.Context, inst domain.VPSInstance) error
+	DeleteBackup(ctx context.Context, inst domain.VPSInstance, backupID int64) error
+}
+
+type messageNotifier interface {
+	NotifyUser(ctx context.Context, userID int64, typ, title, content string) error
+}
+
+// Input is what a user sets on a policy. Weekday is -1 for daily runs.
+type Input struct {
+	Enabled   bool
+	Weekday   int
+	RunAt     string
+	Retention int
+}
+
+// Service keeps the per-instance snapshot and backup schedules and runs the
+// due ones from the vps_backup_policy scheduled task.
+type Service struct {
+	repo     appports.VPSBackupPolicyRepository
+	vps      appports.VPSRepository
+	runner   vpsRunner
+	autoLogs appports.AutomationLogRepository
+	messages messageNotifier
+	now      func() time.Time
+}
+
+func NewService(repo appports.VPSBackupPolicyRepository, vps appports.VPSRepository, runner vpsRunner, autoLogs appports.AutomationLogRepository) *Service {
+	return &Service{repo: repo, vps: vps, runner: runner, autoLogs: autoLogs, now: time.Now}
+}
+
+func (s *Service) SetMessageService(messages messageNotifier) {
+	s.messages = messages
+}
+
+func (s *Service) List(ctx context.Context, inst domain.VPSInstance) ([]domain.VPSBackupPolicy, error) {
+	if inst.ID <= 0 {
+		return nil, appshared.ErrInvalidInput
+	}
+	return s.repo.ListVPSBackupPolicies(ctx, inst.ID)
+}
+
+// Save creates or replaces the policy of one kind on an instance and
+// schedules its next run.
+func (s *Service) Save(ctx context.Context, inst domain.VPSInstance, kind domain.VPSBackupPolicyKind, in Input) (domain.VPSBackupPolicy, error) {
+	if inst.ID <= 0 {
+		return domain.VPSBackupPolicy{}, appshared.ErrInvalidInput
+	}
+	if !validKind(kind) {
+		return domain.VPSBackupPolicy{}, domain.ErrInvalidBackupPolicy
+	}
+	runAt := strings.TrimSpace(in.RunAt)
+	if _, _, ok := parseRunAt(runAt); !ok {
+		return domain.VPSBackupPolicy{}, domain.ErrInvalidBackupPolicy
+	}
+	if in.Weekday < -1 || in.Weekday > 6 {
+		return domain.VPSBackupPolicy{}, domain.ErrInvalidBackupPolicy
+	}
+	if in.Retention < 1 || in.Retention > maxRetention {
+		return domain.VPSBackupPolicy{}, domain.ErrInvalidBackupPolicy
+	}
+	policy := domain.VPSBackupPolicy{
+		VPSID:     inst.ID,
+		UserID:    inst.UserID,
+		Kind:      kind,
+		Enabled:   in.Enabled,
+		Weekday:   in.Weekday,
+		RunAt:     runAt,
+		Retention: in.Retention,
+	}
+	policy.NextRunAt = NextRun(s.now(), policy.Weekday, policy.RunAt)
+	if err := s.repo.UpsertVPSBackupPolicy(ctx, &policy); err != nil {
+		return domain.VPSBackupPolicy{}, err
+	}
+	return policy, nil
+}
+
+func (s *Service) Delete(ctx context.Context, inst domain.VPSInstance, kind domain.VPSBackupPolicyKind) error {
+	if inst.ID <= 0 || !validKind(kind) {
+		return appshared.ErrInvalidInput
+	}
+	return s.repo.DeleteVPSBackupPolicy(ctx, inst.ID, kind)
+}
+
+// RunDue runs up to limit policies whose next run has passed and returns how
+// many were attempted. A failing policy does not stop the others; it is
+// recorded in the automation log and reported to the owner.
+func (s *Service) RunDue(ctx context.Context, limit int) (int, error) {
+	now := s.now()
+	items, err := s.repo.ListDueVPSBackupPolicies(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+	ran := 0
+	for _, policy := range items {
+		inst, err := s.vps.GetInstance(ctx, policy.VPSID)
+		if err != nil {
+			if errors.Is(err, appshared.ErrNotFound) {
+				_ = s.repo.DeleteVPSBackupPolicy(ctx, policy.VPSID, policy.Kind)
+			}
+			continue
+		}
+		ran++
+		policy.LastRunAt = &now
+		policy.NextRunAt = NextRun(now, policy.Weekday, policy.RunAt)
+		policy.LastError = ""
+		if !runnable(inst) {
+			policy.LastStatus = statusSkipped
+		} else if pruned, runErr := s.run(ctx, inst, &policy); runErr != nil {
+			policy.LastStatus = statusFailed
+			policy.LastError = runErr.Error()
+			s.reportFailure(ctx, inst, policy, runErr)
+		} else {
+			policy.LastStatus = statusSuccess
+			s.log(ctx, inst, policy, pruned, true, "")
+		}
+		if err := s.repo.UpdateVPSBackupPolicyRun(ctx, policy); err != nil {
+			return ran, err
+		}
+	}
+	return ran, nil
+}
+
+// run creates one snapshot or backup and prunes the oldest ones this policy
+// created beyond the retention; snapshots and backups the user made by hand
+// are never touched. When the instance quota is already full, room is made
+// first so that a policy keeping as many items as the quota allows keeps
+// rotating.
+func (s *Service) run(ctx context.Context, inst domain.VPSInstance, policy *domain.VPSBackupPolicy) ([]int64, error) {
+	var pruned []int64
+	before, err := s.list(ctx, inst, policy.Kind)
+	if err != nil {
+		return nil, err
+	}
+	policy.ManagedIDs = existingIDs(before, policy.ManagedIDs)
+	err = s.create(ctx, inst, policy.Kind)
+	if errors.Is(err, domain.ErrSnapshotQuotaExceeded) || errors.Is(err, domain.ErrBackupQuotaExceeded) {
+		ids, pruneErr := s.prune(ctx, inst, policy, before, policy.Retention-1)
+		pruned = append(pruned, ids...)
+		if pruneErr != nil {
+			return pruned, pruneErr
+		}
+		err = s.create(ctx, inst, policy.Kind)
+	}
+	if err != nil {
+		return pruned, err
+	}
+	after, err := s.list(ctx, inst, policy.Kind)
+	if err != nil {
+		return pruned, err
+	}
+	known := map[int64]bool{}
+	for _, item := range before {
+		known[itemInt(item, "id")] = true
+	}
+	for _, item := range after {
+		if id := itemInt(item, "id"); id > 0 && !known[id] {
+			policy.ManagedIDs = append(policy.ManagedIDs, id)
+		}
+	}
+	ids, err := s.prune(ctx, inst, policy, after, policy.Retention)
+	pruned = append(pruned, ids...)
+	return pruned, err
+}
+
+func (s *Service) create(ctx context.Context, inst domain.VPSInstance, kind domain.VPSBackupPolicyKind) error {
+	if kind == domain.VPSBackupPolicyKindBackup {
+		return s.runner.CreateBackup(ctx, inst)
+	}
+	return s.runner.CreateSnapshot(ctx, inst)
+}
+
+func (s *Service) list(ctx context.Context, inst domain.VPSInstance, kind domain.VPSBackupPolicyKind) ([]map[string]any, error) {
+	var items []map[string]any
+	if kind == domain.VPSBackupPolicyKindBackup {
+		list, err := s.runner.ListBackups(ctx, inst)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			items = append(items, item)
+		}
+		return items, nil
+	}
+	list, err := s.runner.ListSnapshots(ctx, inst)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range list {
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// prune deletes the oldest items of the policy until at most keep of them
+// remain, dropping the deleted ids from policy.ManagedIDs.
+func (s *Service) prune(ctx context.Context, inst domain.VPSInstance, policy *domain.VPSBackupPolicy, all []map[string]any, keep int) ([]int64, error) {
+	managed := map[int64]bool{}
+	for _, id := range policy.ManagedIDs {
+		managed[id] = true
+	}
+	var items []map[string]any
+	for _, item := range all {
+		if managed[itemInt(item, "id")] {
+			items = append(items, item)
+		}
+	}
+	if keep < 0 {
+		keep = 0
+	}
+	if len(items) <= keep {
+		return nil, nil
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		ti, tj := itemInt(items[i], "created_at_unix"), itemInt(items[j], "created_at_unix")
+		if ti != tj {
+			return ti < tj
+		}
+		return itemInt(items[i], "id") < itemInt(items[j], "id")
+	})
+	var deleted []int64
+	for _, item := range items[:len(items)-keep] {
+		id := itemInt(item, "id")
+		var err error
+		if policy.Kind == domain.VPSBackupPolicyKindBackup {
+			err = s.runner.DeleteBackup(ctx, inst, id)
+		} else {
+			err = s.runner.DeleteSnapshot(ctx, inst, id)
+		}
+		if err != nil {
+			policy.ManagedIDs = withoutIDs(policy.ManagedIDs, deleted)
+			return deleted, err
+		}
+		deleted = append(deleted, id)
+	}
+	policy.ManagedIDs = withoutIDs(policy.ManagedIDs, deleted)
+	return deleted, nil
+}
+
+// existingIDs keeps the managed ids still present upstream, so items the
+// user deleted by hand stop counting towards the retention.
+func existingIDs(items []map[string]any, ids []int64) []int64 {
+	present := map[int64]bool{}
+	for _, item := range items {
+		present[itemInt(item, "id")] = true
+	}
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if present[id] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func withoutIDs(ids, remove []int64) []int64 {
+	if len(remove) == 0 {
+		return ids
+	}
+	drop := map[int64]bool{}
+	for _, id := range remove {
+		drop[id] = true
+	}
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !drop[id] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func (s *Service) reportFailure(ctx context.Context, inst domain.VPSInstance, policy domain.VPSBackupPolicy, runErr error) {
+	s.log(ctx, inst, policy, nil, false, runErr.Error())
+	if s.messages == nil {
+		return
+	}
+	label, title := "snapshot", "Scheduled Snapshot Failed"
+	if policy.Kind == domain.VPSBackupPolicyKindBackup {
+		label, title = "backup", "Scheduled Backup Failed"
+	}
+	content := fmt.Sprintf("The scheduled %s of VPS %s failed: %s", label, inst.Name, runErr.Error())
+	_ = s.messages.NotifyUser(ctx, inst.UserID, "vps_"+label+"_policy_failed", title, content)
+}
+
+func (s *Service) log(ctx context.Context, inst domain.VPSInstance, policy domain.VPSBackupPolicy, pruned []int64, success bool, message string) {
+	if s.autoLogs == nil {
+		return
+	}
+	req := map[string]any{
+		"vps_id":    inst.ID,
+		"host_id":   inst.AutomationInstanceID,
+		"policy_id": policy.ID,
+		"retention": policy.Retention,
+	}
+	resp := map[string]any{"pruned": pruned}
+	if !success {
+		resp = map[string]any{"error": message}
+	}
+	_ = s.autoLogs.CreateAutomationLog(ctx, &domain.AutomationLog{
+		OrderItemID:  inst.OrderItemID,
+		Action:       "backup_policy." + string(policy.Kind),
+		RequestJSON:  mustJSON(req),
+		ResponseJSON: mustJSON(resp),
+		Success:      success,
+		Message:      message,
+	})
+}
+
+// NextRun returns the first run time strictly after from.
+func NextRun(from time.Time, weekday int, runAt string) time.Time {
+	hour, minute, ok := parseRunAt(runAt)
+	if !ok {
+		return time.Time{}
+	}
+	next := time.Date(from.Year(), from.Month(), from.Day(), hour, minute, 0, 0, from.Location())
+	if !next.After(from) {
+		next = next.AddDate(0, 0, 1)
+	}
+	if weekday >= 0 && weekday <= 6 {
+		for int(next.Weekday()) != weekday {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+	return next
+}
+
+func runnable(inst domain.VPSInstance) bool {
+	if inst.AdminStatus != "" && inst.AdminStatus != domain.VPSAdminStatusNormal {
+		return false
+	}
+	switch inst.Status {
+	case domain.VPSStatusExpiredLocked, domain.VPSStatusLocked, domain.VPSStatusProvisioning, domain.VPSStatusReinstalling:
+		return false
+	}
+	return true
+}
+
+func validKind(kind domain.VPSBackupPolicyKind) bool {
+	return kind == domain.VPSBackupPolicyKindSnapshot || kind == domain.VPSBackupPolicyKindBackup
+}
+
+func parseRunAt(value string) (int, int, bool) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, false
+	}
+	return t.Hour(), t.Minute(), true
+}
+
+func itemInt(item map[string]any, key string) int64 {
+	switch v := item[key].(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case float64:
+		return int64(v)
+	case json.Number:
+		n, _ := v.Int64()
+		return n
+	case string:
+		n, _ := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return n
+	}
+	return 0
+}
+
+func mustJSON(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
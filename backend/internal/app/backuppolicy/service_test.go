@@ -0,0 +1,217 @@
+package backuppolicy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appbackuppolicy "xiaoheiplay/internal/app/backuppolicy"
+	appshared "xiaoheiplay/internal/app/shared"
+	appvps "xiaoheiplay/internal/app/vps"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+// fakeRunner keeps an in-memory list so created snapshots show up on the
+// next listing like they do upstream.
+type fakeRunner struct {
+	snapshots []appshared.AutomationSnapshot
+	nextID    int64
+	clock     int64
+	deleted   []int64
+}
+
+func (f *fakeRunner) ListSnapshots(ctx context.Context, inst domain.VPSInstance) ([]appshared.AutomationSnapshot, error) {
+	return append([]appshared.AutomationSnapshot(nil), f.snapshots...), nil
+}
+
+func (f *fakeRunner) CreateSnapshot(ctx context.Context, inst domain.VPSInstance) error {
+	f.nextID++
+	f.clock++
+	f.snapshots = append(f.snapshots, appshared.AutomationSnapshot{"id": f.nextID, "created_at_unix": f.clock})
+	return nil
+}
+
+func (f *fakeRunner) DeleteSnapshot(ctx context.Context, inst domain.VPSInstance, snapshotID int64) error {
+	f.deleted = append(f.deleted, snapshotID)
+	kept := f.snapshots[:0]
+	for _, item := range f.snapshots {
+		if item["id"] != snapshotID {
+			kept = append(kept, item)
+		}
+	}
+	f.snapshots = kept
+	return nil
+}
+
+func (f *fakeRunner) ListBackups(ctx context.Context, inst domain.VPSInstance) ([]appshared.AutomationBackup, error) {
+	return nil, nil
+}
+
+func (f *fakeRunner) CreateBackup(ctx context.Context, inst domain.VPSInstance) error {
+	return nil
+}
+
+func (f *fakeRunner) DeleteBackup(ctx context.Context, inst domain.VPSInstance, backupID int64) error {
+	return nil
+}
+
+type recordedMessage struct {
+	userID int64
+	typ    string
+}
+
+type fakeMessages struct{ sent []recordedMessage }
+
+func (f *fakeMessages) NotifyUser(ctx context.Context, userID int64, typ, title, content string) error {
+	f.sent = append(f.sent, recordedMessage{userID: userID, typ: typ})
+	return nil
+}
+
+func TestNextRun(t *testing.T) {
+	loc := time.UTC
+	from := time.Date(2026, 3, 4, 5, 0, 0, 0, loc) // Wednesday
+	if got := appbackuppolicy.NextRun(from, -1, "03:00"); !got.Equal(time.Date(2026, 3, 5, 3, 0, 0, 0, loc)) {
+		t.Fatalf("daily after run time: %v", got)
+	}
+	if got := appbackuppolicy.NextRun(from, -1, "06:30"); !got.Equal(time.Date(2026, 3, 4, 6, 30, 0, 0, loc)) {
+		t.Fatalf("daily before run time: %v", got)
+	}
+	if got := appbackuppolicy.NextRun(from, 0, "03:00"); !got.Equal(time.Date(2026, 3, 8, 3, 0, 0, 0, loc)) {
+		t.Fatalf("weekly sunday: %v", got)
+	}
+	if got := appbackuppolicy.NextRun(from, 3, "05:00"); !got.Equal(time.Date(2026, 3, 11, 5, 0, 0, 0, loc)) {
+		t.Fatalf("weekly same slot must move a week: %v", got)
+	}
+}
+
+func TestBackupPolicy_RunDuePrunesAndReportsFailures(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "bp", "bp@example.com", "pass")
+	inst := domain.VPSInstance{
+		UserID:               user.ID,
+		AutomationInstanceID: "7",
+		Name:                 "vm",
+		Status:               domain.VPSStatusRunning,
+		SpecJSON:             "{}",
+	}
+	if err := repo.CreateInstance(ctx, &inst); err != nil {
+		t.Fatalf("create vps: %v", err)
+	}
+	client := &testutil.FakeAutomationClient{SnapshotList: []appshared.AutomationSnapshot{
+		{"id": int64(11), "created_at_unix": int64(300)},
+		{"id": int64(12), "created_at_unix": int64(100)},
+		{"id": int64(13), "created_at_unix": int64(200)},
+	}}
+	vpsSvc := appvps.NewService(repo, &testutil.FakeAutomationResolver{Client: client}, repo)
+	messages := &fakeMessages{}
+	svc := appbackuppolicy.NewService(repo, repo, vpsSvc, repo)
+	svc.SetMessageService(messages)
+
+	if _, err := svc.Save(ctx, inst, domain.VPSBackupPolicyKindSnapshot, appbackuppolicy.Input{Enabled: true, Weekday: -1, RunAt: "25:00", Retention: 2}); !errors.Is(err, domain.ErrInvalidBackupPolicy) {
+		t.Fatalf("expected invalid run_at, got %v", err)
+	}
+	saved, err := svc.Save(ctx, inst, domain.VPSBackupPolicyKindSnapshot, appbackuppolicy.Input{Enabled: true, Weekday: -1, RunAt: "03:00", Retention: 2})
+	if err != nil {
+		t.Fatalf("save policy: %v", err)
+	}
+	if !saved.NextRunAt.After(time.Now()) {
+		t.Fatalf("next run must be in the future: %v", saved.NextRunAt)
+	}
+	if ran, err := svc.RunDue(ctx, 10); err != nil || ran != 0 {
+		t.Fatalf("policy must not be due yet: ran=%d err=%v", ran, err)
+	}
+
+	saved.NextRunAt = time.Now().Add(-time.Minute)
+	if err := repo.UpsertVPSBackupPolicy(ctx, &saved); err != nil {
+		t.Fatalf("make policy due: %v", err)
+	}
+	if ran, err := svc.RunDue(ctx, 10); err != nil || ran != 1 {
+		t.Fatalf("run due: ran=%d err=%v", ran, err)
+	}
+	if len(client.SnapshotCreateCalls) != 1 || client.SnapshotCreateCalls[0] != 7 {
+		t.Fatalf("unexpected create calls: %v", client.SnapshotCreateCalls)
+	}
+	if len(client.SnapshotDeleteCalls) != 0 {
+		t.Fatalf("snapshots made by hand must not be pruned, got %v", client.SnapshotDeleteCalls)
+	}
+	got, err := repo.GetVPSBackupPolicy(ctx, inst.ID, domain.VPSBackupPolicyKindSnapshot)
+	if err != nil {
+		t.Fatalf("get policy: %v", err)
+	}
+	if got.LastStatus != "success" || got.LastRunAt == nil || !got.NextRunAt.After(time.Now()) {
+		t.Fatalf("unexpected policy after run: %+v", got)
+	}
+
+	client.SnapshotCreateErr = appshared.ErrNotSupported
+	got.NextRunAt = time.Now().Add(-time.Minute)
+	if err := repo.UpsertVPSBackupPolicy(ctx, &got); err != nil {
+		t.Fatalf("make policy due again: %v", err)
+	}
+	if _, err := svc.RunDue(ctx, 10); err != nil {
+		t.Fatalf("run due with failure: %v", err)
+	}
+	got, _ = repo.GetVPSBackupPolicy(ctx, inst.ID, domain.VPSBackupPolicyKindSnapshot)
+	if got.LastStatus != "failed" || got.LastError == "" {
+		t.Fatalf("expected failed run recorded, got %+v", got)
+	}
+	if len(messages.sent) != 1 || messages.sent[0].userID != user.ID || messages.sent[0].typ != "vps_snapshot_policy_failed" {
+		t.Fatalf("expected failure message, got %+v", messages.sent)
+	}
+	logs, total, err := repo.ListAutomationLogs(ctx, 0, 10, 0)
+	if err != nil || total == 0 {
+		t.Fatalf("expected automation logs, total=%d err=%v", total, err)
+	}
+	failed := false
+	for _, entry := range logs {
+		if entry.Action == "backup_policy.snapshot" && !entry.Success {
+			failed = true
+		}
+	}
+	if !failed {
+		t.Fatalf("expected failed automation log, got %+v", logs)
+	}
+}
+
+func TestBackupPolicy_PrunesOnlyItsOwnSnapshots(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "bp2", "bp2@example.com", "pass")
+	inst := domain.VPSInstance{UserID: user.ID, AutomationInstanceID: "8", Name: "vm2", Status: domain.VPSStatusRunning, SpecJSON: "{}"}
+	if err := repo.CreateInstance(ctx, &inst); err != nil {
+		t.Fatalf("create vps: %v", err)
+	}
+	// The manual snapshot is the oldest one on the instance.
+	runner := &fakeRunner{nextID: 100}
+	runner.snapshots = []appshared.AutomationSnapshot{{"id": int64(1), "created_at_unix": int64(0)}}
+	svc := appbackuppolicy.NewService(repo, repo, runner, repo)
+	policy, err := svc.Save(ctx, inst, domain.VPSBackupPolicyKindSnapshot, appbackuppolicy.Input{Enabled: true, Weekday: -1, RunAt: "03:00", Retention: 2})
+	if err != nil {
+		t.Fatalf("save policy: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		policy.NextRunAt = time.Now().Add(-time.Minute)
+		if err := repo.UpsertVPSBackupPolicy(ctx, &policy); err != nil {
+			t.Fatalf("make policy due: %v", err)
+		}
+		if ran, err := svc.RunDue(ctx, 10); err != nil || ran != 1 {
+			t.Fatalf("run %d: ran=%d err=%v", i, ran, err)
+		}
+		policy, _ = repo.GetVPSBackupPolicy(ctx, inst.ID, domain.VPSBackupPolicyKindSnapshot)
+	}
+	if len(runner.deleted) != 1 || runner.deleted[0] != 101 {
+		t.Fatalf("expected only the first policy snapshot pruned, got %v", runner.deleted)
+	}
+	ids := map[int64]bool{}
+	for _, item := range runner.snapshots {
+		ids[item["id"].(int64)] = true
+	}
+	if len(ids) != 3 || !ids[1] || !ids[102] || !ids[103] {
+		t.Fatalf("expected the manual snapshot and the two newest kept, got %v", runner.snapshots)
+	}
+	if len(policy.ManagedIDs) != 2 || policy.ManagedIDs[0] != 102 || policy.ManagedIDs[1] != 103 {
+		t.Fatalf("unexpected managed ids: %v", policy.ManagedIDs)
+	}
+}
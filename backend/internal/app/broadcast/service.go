@@ -0,0 +1,473 @@
+package broadcast
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+// MessageType is the notification type of broadcasts; it falls in the
+// marketing category of notification preferences.
+const MessageType = "announcement"
+
+// Channels lists the channels a broadcast can use.
+var Channels = []string{
+	domain.NotificationChannelInApp,
+	domain.NotificationChannelEmail,
+	domain.NotificationChannelSMS,
+	domain.NotificationChannelPush,
+}
+
+// defaultRates are deliveries per channel per run of the broadcast task,
+// which runs every minute by default. Email and SMS are kept low to stay
+// within provider quotas; admins can change them in settings.
+var defaultRates = map[string]int{
+	domain.NotificationChannelInApp: 1000,
+	domain.NotificationChannelEmail: 60,
+	domain.NotificationChannelSMS:   20,
+	domain.NotificationChannelPush:  200,
+}
+
+const (
+	recipientPage      = 500
+	broadcastsPerRun   = 10
+	maxTitleLength     = 128
+	maxContentLength   = 5000
+	deliveryTokenBytes = 24
+	clickPath          = "/api/v1/broadcasts/links/"
+)
+
+// RateSettingKey is the setting holding the per-run limit of a channel.
+func RateSettingKey(channel string) string {
+	return "broadcast_" + channel + "_per_minute"
+}
+
+// messageSender and pushNotifier check the user's notification preferences
+// and return domain.ErrNotificationMuted for channels the user turned off.
+type messageSender interface {
+	SendInApp(ctx context.Context, userID int64, typ, title, content string) (domain.Notification, error)
+	SendEmail(ctx context.Context, userID int64, typ, subject, body string) error
+	SendSMS(ctx context.Context, userID int64, typ string, msg appshared.SMSMessage) error
+}
+
+type pushNotifier interface {
+	NotifyUser(ctx context.Context, userID int64, typ string, payload appshared.PushPayload) error
+}
+
+type CreateInput struct {
+	Title       string
+	Content     string
+	LinkURL     string
+	Channels    []string
+	Segment     domain.BroadcastSegment
+	ScheduledAt *time.Time
+}
+
+type Service struct {
+	repo     appports.BroadcastRepository
+	messages messageSender
+	settings appports.SettingsRepository
+	audit    appports.AuditRepository
+	push     pushNotifier
+	now      func() time.Time
+}
+
+func NewService(repo appports.BroadcastRepository, messages messageSender, settings appports.SettingsRepository, audit appports.AuditRepository) *Service {
+	return &Service{repo: repo, messages: messages, settings: settings, audit: audit, now: time.Now}
+}
+
+func (s *Service) SetPushService(push pushNotifier) {
+	s.push = push
+}
+
+// Create schedules a broadcast. Without ScheduledAt, or with a time in the
+// past, it goes out on the next run of the broadcast task.
+func (s *Service) Create(ctx context.Context, adminID int64, in CreateInput) (domain.Broadcast, error) {
+	b := domain.Broadcast{
+		Title:     strings.TrimSpace(in.Title),
+		Content:   strings.TrimSpace(in.Content),
+		LinkURL:   strings.TrimSpace(in.LinkURL),
+		Status:    domain.BroadcastStatusScheduled,
+		CreatedBy: adminID,
+	}
+	if b.Title == "" || len([]rune(b.Title)) > maxTitleLength || b.Content == "" || len([]rune(b.Content)) > maxContentLength {
+		return domain.Broadcast{}, domain.ErrInvalidBroadcast
+	}
+	if b.LinkURL != "" && !validLink(b.LinkURL) {
+		return domain.Broadcast{}, domain.ErrInvalidBroadcast
+	}
+	channels, err := normalizeChannels(in.Channels)
+	if err != nil {
+		return domain.Broadcast{}, err
+	}
+	b.Channels = channels
+	segment, err := normalizeSegment(in.Segment)
+	if err != nil {
+		return domain.Broadcast{}, err
+	}
+	b.Segment = segment
+	now := s.now()
+	b.ScheduledAt = now
+	if in.ScheduledAt != nil && in.ScheduledAt.After(now) {
+		b.ScheduledAt = *in.ScheduledAt
+	}
+	if err := s.repo.CreateBroadcast(ctx, &b); err != nil {
+		return domain.Broadcast{}, err
+	}
+	s.auditLog(ctx, adminID, "broadcast.create", b.ID, map[string]any{"title": b.Title, "channels": b.Channels, "scheduled_at": b.ScheduledAt})
+	return b, nil
+}
+
+// Preview counts the users a segment currently selects.
+func (s *Service) Preview(ctx context.Context, segment domain.BroadcastSegment) (int, error) {
+	segment, err := normalizeSegment(segment)
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.CountBroadcastRecipients(ctx, segment)
+}
+
+func (s *Service) List(ctx context.Context, status string, limit, offset int) ([]domain.Broadcast, int, error) {
+	return s.repo.ListBroadcasts(ctx, strings.TrimSpace(status), limit, offset)
+}
+
+func (s *Service) Get(ctx context.Context, id int64) (domain.Broadcast, domain.BroadcastStats, error) {
+	b, err := s.repo.GetBroadcast(ctx, id)
+	if err != nil {
+		return domain.Broadcast{}, domain.BroadcastStats{}, err
+	}
+	stats, err := s.repo.GetBroadcastStats(ctx, id)
+	if err != nil {
+		return domain.Broadcast{}, domain.BroadcastStats{}, err
+	}
+	return b, stats, nil
+}
+
+func (s *Service) Deliveries(ctx context.Context, id int64, channel, status string, limit, offset int) ([]domain.BroadcastDelivery, int, error) {
+	if _, err := s.repo.GetBroadcast(ctx, id); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListBroadcastDeliveries(ctx, id, strings.TrimSpace(channel), strings.TrimSpace(status), limit, offset)
+}
+
+// Cancel stops a broadcast that has not finished; deliveries already sent
+// stay as they are and the rest are skipped.
+func (s *Service) Cancel(ctx context.Context, adminID, id int64) (domain.Broadcast, error) {
+	if _, err := s.repo.GetBroadcast(ctx, id); err != nil {
+		return domain.Broadcast{}, err
+	}
+	ok, err := s.repo.CancelBroadcast(ctx, id, s.now())
+	if err != nil {
+		return domain.Broadcast{}, err
+	}
+	if !ok {
+		return domain.Broadcast{}, domain.ErrBroadcastNotCancelable
+	}
+	_ = s.repo.RefreshBroadcastCounters(ctx, id)
+	s.auditLog(ctx, adminID, "broadcast.cancel", id, map[string]any{})
+	return s.repo.GetBroadcast(ctx, id)
+}
+
+// Click records a click on a tracked link and returns where to send the
+// user.
+func (s *Service) Click(ctx context.Context, token string) (string, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", appshared.ErrNotFound
+	}
+	delivery, err := s.repo.MarkBroadcastDeliveryClicked(ctx, token, s.now())
+	if err != nil {
+		return "", err
+	}
+	b, err := s.repo.GetBroadcast(ctx, delivery.BroadcastID)
+	if err != nil {
+		return "", err
+	}
+	if b.LinkURL == "" {
+		return "", appshared.ErrNotFound
+	}
+	return b.LinkURL, nil
+}
+
+// RunDue starts broadcasts whose time has come and sends pending
+// deliveries within each channel's rate. It returns the number of
+// deliveries processed.
+func (s *Service) RunDue(ctx context.Context) (int, error) {
+	now := s.now()
+	list, err := s.repo.ListActiveBroadcasts(ctx, now, broadcastsPerRun)
+	if err != nil {
+		return 0, err
+	}
+	quota := s.rates(ctx)
+	processed := 0
+	var errs []error
+	for _, b := range list {
+		if b.Status == domain.BroadcastStatusScheduled {
+			if err := s.start(ctx, b, now); err != nil {
+				errs = append(errs, fmt.Errorf("broadcast %d: %w", b.ID, err))
+				continue
+			}
+		}
+		n, done, err := s.deliver(ctx, b, quota)
+		processed += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("broadcast %d: %w", b.ID, err))
+		}
+		_ = s.repo.RefreshBroadcastCounters(ctx, b.ID)
+		if done && err == nil {
+			_, _ = s.repo.FinishBroadcast(ctx, b.ID, s.now())
+		}
+	}
+	return processed, errors.Join(errs...)
+}
+
+// start resolves the segment into one delivery per recipient and channel.
+// Deliveries that already exist are kept, so a start that was interrupted
+// resumes without sending twice.
+func (s *Service) start(ctx context.Context, b domain.Broadcast, now time.Time) error {
+	total := 0
+	var after int64
+	for {
+		ids, err := s.repo.ListBroadcastRecipients(ctx, b.Segment, after, recipientPage)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		deliveries := make([]domain.BroadcastDelivery, 0, len(ids)*len(b.Channels))
+		for _, userID := range ids {
+			for _, ch := range b.Channels {
+				deliveries = append(deliveries, domain.BroadcastDelivery{
+					BroadcastID: b.ID,
+					UserID:      userID,
+					Channel:     ch,
+					Status:      domain.BroadcastDeliveryPending,
+					Token:       newToken(),
+				})
+			}
+		}
+		if err := s.repo.CreateBroadcastDeliveries(ctx, deliveries); err != nil {
+			return err
+		}
+		total += len(deliveries)
+		after = ids[len(ids)-1]
+		if len(ids) < recipientPage {
+			break
+		}
+	}
+	_, err := s.repo.StartBroadcast(ctx, b.ID, total, now)
+	return err
+}
+
+// deliver sends pending deliveries while the channel quota lasts and
+// reports whether none are left.
+func (s *Service) deliver(ctx context.Context, b domain.Broadcast, quota map[string]int) (int, bool, error) {
+	processed := 0
+	done := true
+	for _, ch := range b.Channels {
+		if quota[ch] > 0 {
+			pending, err := s.repo.ListPendingBroadcastDeliveries(ctx, b.ID, ch, quota[ch])
+			if err != nil {
+				return processed, false, err
+			}
+			for _, d := range pending {
+				s.send(ctx, b, &d)
+				if err := s.repo.UpdateBroadcastDelivery(ctx, d); err != nil {
+					return processed, false, err
+				}
+				quota[ch]--
+				processed++
+			}
+		}
+		left, err := s.repo.ListPendingBroadcastDeliveries(ctx, b.ID, ch, 1)
+		if err != nil {
+			return processed, false, err
+		}
+		if len(left) > 0 {
+			done = false
+		}
+	}
+	return processed, done, nil
+}
+
+// send delivers one message and records the outcome on d. Users who muted
+// marketing messages on the channel are skipped.
+func (s *Service) send(ctx context.Context, b domain.Broadcast, d *domain.BroadcastDelivery) {
+	var err error
+	switch d.Channel {
+	case domain.NotificationChannelInApp:
+		err = s.sendInApp(ctx, b, d)
+	case domain.NotificationChannelEmail:
+		err = s.messages.SendEmail(ctx, d.UserID, MessageType, b.Title, withLink(b.Content, s.link(ctx, b, *d, false)))
+	case domain.NotificationChannelSMS:
+		err = s.messages.SendSMS(ctx, d.UserID, MessageType, appshared.SMSMessage{
+			Content: withLink(b.Content, s.link(ctx, b, *d, false)),
+			Vars:    map[string]string{"title": b.Title, "content": b.Content},
+		})
+	case domain.NotificationChannelPush:
+		err = s.sendPush(ctx, b, *d)
+	default:
+		err = domain.ErrNotSupported
+	}
+	if errors.Is(err, domain.ErrNotificationMuted) {
+		d.Status = domain.BroadcastDeliverySkipped
+		d.Error = err.Error()
+		return
+	}
+	if err != nil {
+		d.Status = domain.BroadcastDeliveryFailed
+		d.Error = err.Error()
+		return
+	}
+	now := s.now()
+	d.Status = domain.BroadcastDeliverySent
+	d.Error = ""
+	d.SentAt = &now
+}
+
+func (s *Service) sendInApp(ctx context.Context, b domain.Broadcast, d *domain.BroadcastDelivery) error {
+	n, err := s.messages.SendInApp(ctx, d.UserID, MessageType, b.Title, withLink(b.Content, s.link(ctx, b, *d, true)))
+	if err != nil {
+		return err
+	}
+	d.NotificationID = n.ID
+	return nil
+}
+
+func (s *Service) sendPush(ctx context.Context, b domain.Broadcast, d domain.BroadcastDelivery) error {
+	if s.push == nil {
+		return domain.ErrPushNotConfigured
+	}
+	data := map[string]string{"broadcast_id": strconv.FormatInt(b.ID, 10)}
+	if link := s.link(ctx, b, d, false); link != "" {
+		data["link"] = link
+	}
+	return s.push.NotifyUser(ctx, d.UserID, MessageType, appshared.PushPayload{Title: b.Title, Body: b.Content, Data: data})
+}
+
+// link returns the tracked link of a delivery. Without a site URL only
+// in-app messages can use the relative tracking path; other channels get
+// the plain link and their clicks are not counted.
+func (s *Service) link(ctx context.Context, b domain.Broadcast, d domain.BroadcastDelivery, relative bool) string {
+	if b.LinkURL == "" {
+		return ""
+	}
+	base := strings.TrimRight(s.settingValue(ctx, "site_url"), "/")
+	if base == "" && !relative {
+		return b.LinkURL
+	}
+	return base + clickPath + d.Token
+}
+
+func (s *Service) rates(ctx context.Context) map[string]int {
+	out := make(map[string]int, len(defaultRates))
+	for ch, rate := range defaultRates {
+		out[ch] = rate
+		if v, err := strconv.Atoi(s.settingValue(ctx, RateSettingKey(ch))); err == nil && v >= 0 {
+			out[ch] = v
+		}
+	}
+	return out
+}
+
+func (s *Service) settingValue(ctx context.Context, key string) string {
+	if s.settings == nil {
+		return ""
+	}
+	setting, err := s.settings.GetSetting(ctx, key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(setting.ValueJSON)
+}
+
+func (s *Service) auditLog(ctx context.Context, adminID int64, action string, id int64, detail map[string]any) {
+	if s.audit == nil {
+		return
+	}
+	b, _ := json.Marshal(detail)
+	_ = s.audit.AddAuditLog(ctx, domain.AdminAuditLog{AdminID: adminID, Action: action, TargetType: "broadcast", TargetID: strconv.FormatInt(id, 10), DetailJSON: string(b)})
+}
+
+func normalizeChannels(channels []string) ([]string, error) {
+	out := make([]string, 0, len(channels))
+	for _, ch := range channels {
+		ch = strings.ToLower(strings.TrimSpace(ch))
+		known := false
+		for _, item := range Channels {
+			if item == ch {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, domain.ErrInvalidBroadcast
+		}
+		dup := false
+		for _, item := range out {
+			if item == ch {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			out = append(out, ch)
+		}
+	}
+	if len(out) == 0 {
+		return nil, domain.ErrInvalidBroadcast
+	}
+	return out, nil
+}
+
+func normalizeSegment(segment domain.BroadcastSegment) (domain.BroadcastSegment, error) {
+	for _, ids := range [][]int64{segment.UserTierGroupIDs, segment.GoodsTypeIDs, segment.RegionIDs} {
+		for _, id := range ids {
+			if id <= 0 {
+				return segment, domain.ErrInvalidBroadcast
+			}
+		}
+	}
+	segment.RealNameStatus = strings.ToLower(strings.TrimSpace(segment.RealNameStatus))
+	switch segment.RealNameStatus {
+	case "", domain.BroadcastRealNameVerified, domain.BroadcastRealNameUnverified:
+	default:
+		return segment, domain.ErrInvalidBroadcast
+	}
+	if segment.RegisteredFrom != nil && segment.RegisteredTo != nil && !segment.RegisteredFrom.Before(*segment.RegisteredTo) {
+		return segment, domain.ErrInvalidBroadcast
+	}
+	return segment, nil
+}
+
+func validLink(link string) bool {
+	if strings.HasPrefix(link, "/") && !strings.HasPrefix(link, "//") {
+		return true
+	}
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func withLink(content, link string) string {
+	if link == "" {
+		return content
+	}
+	return content + "\n" + link
+}
+
+func newToken() string {
+	buf := make([]byte, deliveryTokenBytes)
+	_, _ = rand.Read(buf)
+	return fmt.Sprintf("%x", buf)
+}
@@ -0,0 +1,198 @@
+package broadcast_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	appbroadcast "xiaoheiplay/internal/app/broadcast"
+	appmessage "xiaoheiplay/internal/app/message"
+	appnotifypref "xiaoheiplay/internal/app/notifypref"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+func TestBroadcast_SegmentFiltersRecipients(t *testing.T) {
+	db, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	svc := appbroadcast.NewService(repo, appmessage.NewService(repo, repo), repo, repo)
+
+	gold := domain.UserTierGroup{Name: "gold"}
+	if err := repo.CreateUserTierGroup(ctx, &gold); err != nil {
+		t.Fatalf("create tier: %v", err)
+	}
+	tiered := testutil.CreateUser(t, repo, "tiered", "tiered@example.com", "pass")
+	tiered.UserTierGroupID = &gold.ID
+	if err := repo.UpdateUser(ctx, tiered); err != nil {
+		t.Fatalf("update user: %v", err)
+	}
+	verified := testutil.CreateUser(t, repo, "verified", "verified@example.com", "pass")
+	if err := repo.CreateRealNameVerification(ctx, &domain.RealNameVerification{UserID: verified.ID, RealName: "Zhang", IDNumber: "110101199001011234", Status: "verified", Provider: "fake"}); err != nil {
+		t.Fatalf("create verification: %v", err)
+	}
+	old := testutil.CreateUser(t, repo, "old", "old@example.com", "pass")
+	if _, err := db.Exec("UPDATE users SET created_at = ? WHERE id = ?", time.Now().AddDate(-1, 0, 0), old.ID); err != nil {
+		t.Fatalf("backdate user: %v", err)
+	}
+
+	count := func(segment domain.BroadcastSegment) int {
+		t.Helper()
+		n, err := svc.Preview(ctx, segment)
+		if err != nil {
+			t.Fatalf("preview: %v", err)
+		}
+		return n
+	}
+	if n := count(domain.BroadcastSegment{}); n != 3 {
+		t.Fatalf("expected all users, got %d", n)
+	}
+	if n := count(domain.BroadcastSegment{UserTierGroupIDs: []int64{gold.ID}}); n != 1 {
+		t.Fatalf("expected one tiered user, got %d", n)
+	}
+	if n := count(domain.BroadcastSegment{RealNameStatus: domain.BroadcastRealNameVerified}); n != 1 {
+		t.Fatalf("expected one verified user, got %d", n)
+	}
+	if n := count(domain.BroadcastSegment{RealNameStatus: domain.BroadcastRealNameUnverified}); n != 2 {
+		t.Fatalf("expected two unverified users, got %d", n)
+	}
+	from := time.Now().AddDate(0, -1, 0)
+	if n := count(domain.BroadcastSegment{RegisteredFrom: &from}); n != 2 {
+		t.Fatalf("expected two recent users, got %d", n)
+	}
+	if n := count(domain.BroadcastSegment{RegisteredTo: &from}); n != 1 {
+		t.Fatalf("expected one old user, got %d", n)
+	}
+	if n := count(domain.BroadcastSegment{GoodsTypeIDs: []int64{999}}); n != 0 {
+		t.Fatalf("expected nobody without instances, got %d", n)
+	}
+	if _, err := svc.Preview(ctx, domain.BroadcastSegment{RealNameStatus: "maybe"}); !errors.Is(err, domain.ErrInvalidBroadcast) {
+		t.Fatalf("expected invalid realname status, got %v", err)
+	}
+}
+
+func TestBroadcast_ScheduleRateLimitAndStats(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	email := &testutil.FakeEmailSender{}
+	messages := appmessage.NewService(repo, repo)
+	messages.SetEmailSender(email)
+	prefs := appnotifypref.NewService(repo)
+	messages.SetNotificationPreferences(prefs)
+	svc := appbroadcast.NewService(repo, messages, repo, repo)
+
+	alice := testutil.CreateUser(t, repo, "alice", "alice@example.com", "pass")
+	bob := testutil.CreateUser(t, repo, "bob", "bob@example.com", "pass")
+	muted := testutil.CreateUser(t, repo, "muted", "muted@example.com", "pass")
+	if _, err := prefs.Update(ctx, muted.ID, domain.NotificationPreferences{
+		Disabled: map[string][]string{domain.NotificationCategoryMarketing: {domain.NotificationChannelEmail}},
+	}); err != nil {
+		t.Fatalf("update prefs: %v", err)
+	}
+	if err := repo.UpsertSetting(ctx, domain.Setting{Key: appbroadcast.RateSettingKey(domain.NotificationChannelEmail), ValueJSON: "1"}); err != nil {
+		t.Fatalf("set rate: %v", err)
+	}
+	if err := repo.UpsertSetting(ctx, domain.Setting{Key: "site_url", ValueJSON: "https://example.com/"}); err != nil {
+		t.Fatalf("set site url: %v", err)
+	}
+
+	if _, err := svc.Create(ctx, 1, appbroadcast.CreateInput{Title: "Sale", Content: "50% off", Channels: []string{"fax"}}); !errors.Is(err, domain.ErrInvalidBroadcast) {
+		t.Fatalf("expected unknown channel rejected, got %v", err)
+	}
+	later := time.Now().Add(time.Hour)
+	b, err := svc.Create(ctx, 1, appbroadcast.CreateInput{
+		Title:       "Sale",
+		Content:     "50% off",
+		LinkURL:     "https://example.com/sale",
+		Channels:    []string{domain.NotificationChannelInApp, domain.NotificationChannelEmail},
+		ScheduledAt: &later,
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if n, err := svc.RunDue(ctx); err != nil || n != 0 {
+		t.Fatalf("expected nothing due yet, got %d %v", n, err)
+	}
+
+	b, err = svc.Create(ctx, 1, appbroadcast.CreateInput{
+		Title:    "Sale",
+		Content:  "50% off",
+		LinkURL:  "https://example.com/sale",
+		Channels: []string{domain.NotificationChannelInApp, domain.NotificationChannelEmail},
+	})
+	if err != nil {
+		t.Fatalf("create now: %v", err)
+	}
+	if _, err := svc.RunDue(ctx); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	got, _, err := svc.Get(ctx, b.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Status != domain.BroadcastStatusSending || got.Total != 6 {
+		t.Fatalf("expected sending with 6 deliveries, got %+v", got)
+	}
+	if len(email.Sends) != 1 {
+		t.Fatalf("expected email rate limited to 1, got %d", len(email.Sends))
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := svc.RunDue(ctx); err != nil {
+			t.Fatalf("run %d: %v", i, err)
+		}
+	}
+	got, stats, err := svc.Get(ctx, b.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.Status != domain.BroadcastStatusSent || got.SentCount != 5 || got.FailedCount != 0 {
+		t.Fatalf("expected sent broadcast, got %+v", got)
+	}
+	if len(email.Sends) != 2 {
+		t.Fatalf("expected muted user skipped, got %d emails", len(email.Sends))
+	}
+	skipped, total, err := svc.Deliveries(ctx, b.ID, domain.NotificationChannelEmail, string(domain.BroadcastDeliverySkipped), 10, 0)
+	if err != nil || total != 1 || skipped[0].UserID != muted.ID {
+		t.Fatalf("expected muted email skipped, got %+v %d %v", skipped, total, err)
+	}
+
+	link := email.Sends[0].Body[strings.Index(email.Sends[0].Body, "https://example.com/api/v1/broadcasts/links/"):]
+	token := strings.TrimSpace(strings.TrimPrefix(link, "https://example.com/api/v1/broadcasts/links/"))
+	target, err := svc.Click(ctx, token)
+	if err != nil || target != "https://example.com/sale" {
+		t.Fatalf("click: %q %v", target, err)
+	}
+	if _, err := svc.Click(ctx, "missing"); !errors.Is(err, appshared.ErrNotFound) {
+		t.Fatalf("expected unknown token not found, got %v", err)
+	}
+
+	inapp, _, err := svc.Deliveries(ctx, b.ID, domain.NotificationChannelInApp, "", 10, 0)
+	if err != nil || len(inapp) != 3 {
+		t.Fatalf("expected 3 in-app deliveries, got %d %v", len(inapp), err)
+	}
+	for _, d := range inapp {
+		if d.UserID == alice.ID || d.UserID == bob.ID {
+			if err := repo.MarkNotificationRead(ctx, d.UserID, d.NotificationID); err != nil {
+				t.Fatalf("mark read: %v", err)
+			}
+		}
+	}
+	_, stats, _ = svc.Get(ctx, b.ID)
+	if stats.Read != 2 || stats.Clicked != 1 {
+		t.Fatalf("expected 2 reads and 1 click, got %+v", stats)
+	}
+
+	if _, err := svc.Cancel(ctx, 1, b.ID); !errors.Is(err, domain.ErrBroadcastNotCancelable) {
+		t.Fatalf("expected finished broadcast not cancelable, got %v", err)
+	}
+	list, total, err := svc.List(ctx, string(domain.BroadcastStatusScheduled), 10, 0)
+	if err != nil || total != 1 {
+		t.Fatalf("expected one scheduled broadcast, got %d %v", total, err)
+	}
+	canceled, err := svc.Cancel(ctx, 1, list[0].ID)
+	if err != nil || canceled.Status != domain.BroadcastStatusCanceled {
+		t.Fatalf("cancel: %+v %v", canceled, err)
+	}
+}
@@ -15,6 +15,7 @@ type Service struct {
 	catalog appports.CatalogRepository
 	billing appports.BillingCycleRepository
 	pricer  userTierPricingResolver
+	addons  addonResolver
 }
 
 type CartSpec = appshared.CartSpec
@@ -31,6 +32,14 @@ func (s *Service) SetUserTierPricingResolver(resolver userTierPricingResolver) {
 	s.pricer = resolver
 }
 
+type addonResolver interface {
+	Resolve(ctx context.Context, userID int64, plan domain.PlanGroup, picks []appshared.CartAddon) ([]appshared.CartAddon, error)
+}
+
+func (s *Service) SetAddonService(addons addonResolver) {
+	s.addons = addons
+}
+
 func (s *Service) List(ctx context.Context, userID int64) ([]domain.CartItem, error) {
 	return s.cart.ListCartItems(ctx, userID)
 }
@@ -53,6 +62,9 @@ func (s *Service) Add(ctx context.Context, userID int64, packageID int64, system
 	if err := validateAddonSpec(spec, plan); err != nil {
 		return domain.CartItem{}, err
 	}
+	if err := s.resolveAddons(ctx, userID, plan, &spec); err != nil {
+		return domain.CartItem{}, err
+	}
 	months, multiplier, err := s.resolveBilling(ctx, spec)
 	if err != nil {
 		return domain.CartItem{}, err
@@ -72,7 +84,7 @@ func (s *Service) Add(ctx context.Context, userID int64, packageID int64, system
 			unitBW = pricing.UnitBW
 		}
 	}
-	addonMonthly := int64(spec.AddCores)*unitCore + int64(spec.AddMemGB)*unitMem + int64(spec.AddDiskGB)*unitDisk + int64(spec.AddBWMbps)*unitBW
+	addonMonthly := int64(spec.AddCores)*unitCore + int64(spec.AddMemGB)*unitMem + int64(spec.AddDiskGB)*unitDisk + int64(spec.AddBWMbps)*unitBW + spec.AddonsMonthly()
 	unitAmount := int64(math.Round(float64(baseMonthly+addonMonthly) * multiplier))
 	specJSON := mustJSON(spec)
 	item := domain.CartItem{
@@ -121,6 +133,9 @@ func (s *Service) Update(ctx context.Context, userID int64, itemID int64, spec C
 	if err := validateAddonSpec(spec, plan); err != nil {
 		return domain.CartItem{}, err
 	}
+	if err := s.resolveAddons(ctx, userID, plan, &spec); err != nil {
+		return domain.CartItem{}, err
+	}
 	months, multiplier, err := s.resolveBilling(ctx, spec)
 	if err != nil {
 		return domain.CartItem{}, err
@@ -140,7 +155,7 @@ func (s *Service) Update(ctx context.Context, userID int64, itemID int64, spec C
 			unitBW = pricing.UnitBW
 		}
 	}
-	addonMonthly := int64(spec.AddCores)*unitCore + int64(spec.AddMemGB)*unitMem + int64(spec.AddDiskGB)*unitDisk + int64(spec.AddBWMbps)*unitBW
+	addonMonthly := int64(spec.AddCores)*unitCore + int64(spec.AddMemGB)*unitMem + int64(spec.AddDiskGB)*unitDisk + int64(spec.AddBWMbps)*unitBW + spec.AddonsMonthly()
 	unitAmount := int64(math.Round(float64(baseMonthly+addonMonthly) * multiplier))
 	updated := domain.CartItem{
 		ID:        itemID,
@@ -193,6 +208,21 @@ func (s *Service) resolveBilling(ctx context.Context, spec CartSpec) (int, float
 	return months, multiplier, nil
 }
 
+func (s *Service) resolveAddons(ctx context.Context, userID int64, plan domain.PlanGroup, spec *CartSpec) error {
+	if len(spec.Addons) == 0 {
+		return nil
+	}
+	if s.addons == nil {
+		return domain.ErrInvalidAddon
+	}
+	resolved, err := s.addons.Resolve(ctx, userID, plan, spec.Addons)
+	if err != nil {
+		return err
+	}
+	spec.Addons = resolved
+	return nil
+}
+
 func validateAddonSpec(spec CartSpec, plan domain.PlanGroup) error {
 	if err := validateAddonValue(spec.AddCores, plan.AddCoreMin, plan.AddCoreMax, plan.AddCoreStep); err != nil {
 		return err
@@ -241,7 +271,10 @@ func normalizeCartSpec(spec *CartSpec) error {
 	if spec.CycleQty < 0 {
 		return appshared.ErrInvalidInput
 	}
-	return nil
+	if err := spec.NormalizeAddons(); err != nil {
+		return err
+	}
+	return spec.NormalizeProvisionOptions()
 }
 
 func mustJSON(v any) string {
@@ -95,14 +95,14 @@ func (s *Service) DeletePlanGroup(ctx context.Context, id int64) error {
 }
 
 func (s *Service) CreatePackage(ctx context.Context, pkg *domain.Package) error {
-	if pkg.PlanGroupID <= 0 {
+	if pkg.PlanGroupID <= 0 || pkg.TrialDays < 0 {
 		return appshared.ErrInvalidInput
 	}
 	return s.catalog.CreatePackage(ctx, pkg)
 }
 
 func (s *Service) UpdatePackage(ctx context.Context, pkg domain.Package) error {
-	if pkg.PlanGroupID <= 0 {
+	if pkg.PlanGroupID <= 0 || pkg.TrialDays < 0 {
 		return appshared.ErrInvalidInput
 	}
 	return s.catalog.UpdatePackage(ctx, pkg)
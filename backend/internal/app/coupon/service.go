@@ -35,6 +35,9 @@ type QuoteItem struct {
 	UnitAddonMem    int64
 	UnitAddonDisk   int64
 	UnitAddonBW     int64
+	// UnitAddonSKUs is the per-unit amount of each addon SKU by addon ID. It
+	// is already part of UnitAddonAmount.
+	UnitAddonSKUs   map[int64]int64
 	UnitTotalAmount int64
 	Qty             int
 }
@@ -367,6 +370,11 @@ func validateCouponRule(rule domain.CouponProductRule) error {
 			return appshared.ErrInvalidInput
 		}
 		return nil
+	case domain.CouponGroupScopeAddon:
+		if rule.AddonID <= 0 {
+			return appshared.ErrInvalidInput
+		}
+		return nil
 	default:
 		return appshared.ErrInvalidInput
 	}
@@ -460,6 +468,9 @@ func ruleDiscountableAmount(rule domain.CouponProductRule, item QuoteItem) (int6
 			part = item.UnitAddonAmount
 		}
 		return part, part > 0
+	case domain.CouponGroupScopeAddon:
+		amount := item.UnitAddonSKUs[rule.AddonID]
+		return amount, amount > 0
 	default:
 		return 0, false
 	}
@@ -467,6 +478,8 @@ func ruleDiscountableAmount(rule domain.CouponProductRule, item QuoteItem) (int6
 
 func couponRuleSpecificity(scope domain.CouponGroupScope) int {
 	switch scope {
+	case domain.CouponGroupScopeAddon:
+		return 70
 	case domain.CouponGroupScopePackage:
 		return 60
 	case domain.CouponGroupScopePlanGroup, domain.CouponGroupScopeAddonConfig:
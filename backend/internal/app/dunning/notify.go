@@ -0,0 +1,180 @@
+package dunning
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+// simpleVarRE matches the {{name}} placeholders SMS templates use.
+var simpleVarRE = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}`)
+
+// notice is the text of one step. Admins can override the email with an
+// enabled email template named "dunning_<action>", and the SMS with an
+// enabled SMS template of the same name.
+type notice struct {
+	title   string
+	content string
+	vars    map[string]string
+}
+
+func buildNotice(inst domain.VPSInstance, policy domain.DunningPolicy, step domain.DunningStep) notice {
+	expireAt := *inst.ExpireAt
+	vars := map[string]string{
+		"name":      inst.Name,
+		"expire_at": expireAt.Format("2006-01-02"),
+	}
+	var suspendAt, terminateAt string
+	for _, st := range policy.Steps {
+		switch st.Action {
+		case domain.DunningActionSuspend:
+			suspendAt = dueAt(expireAt, st).Format("2006-01-02")
+		case domain.DunningActionTerminate:
+			terminateAt = dueAt(expireAt, st).Format("2006-01-02")
+		}
+	}
+	vars["suspend_at"] = suspendAt
+	vars["terminate_at"] = terminateAt
+
+	n := notice{vars: vars}
+	switch step.Action {
+	case domain.DunningActionRemind:
+		n.title = "服务即将到期"
+		n.content = fmt.Sprintf("您的实例 %s 将于 %s 到期，请及时续费。", inst.Name, vars["expire_at"])
+		if suspendAt != "" {
+			n.content += fmt.Sprintf("逾期未续费将于 %s 暂停服务。", suspendAt)
+		}
+	case domain.DunningActionSuspend:
+		n.title = "服务已暂停"
+		n.content = fmt.Sprintf("您的实例 %s 已于 %s 到期，现已暂停，续费后自动恢复。", inst.Name, vars["expire_at"])
+		if terminateAt != "" {
+			n.content += fmt.Sprintf("如在 %s 前仍未续费，实例将被删除。", terminateAt)
+		}
+	case domain.DunningActionFinalWarning:
+		n.title = "服务即将删除"
+		if terminateAt != "" {
+			n.content = fmt.Sprintf("您的实例 %s 已于 %s 到期，将于 %s 删除，数据无法恢复，请尽快续费。", inst.Name, vars["expire_at"], terminateAt)
+		} else {
+			n.content = fmt.Sprintf("您的实例 %s 已于 %s 到期，请尽快续费以免服务中断。", inst.Name, vars["expire_at"])
+		}
+	case domain.DunningActionTerminate:
+		n.title = "服务已删除"
+		n.content = fmt.Sprintf("您的实例 %s 因到期未续费已被删除。", inst.Name)
+	}
+	return n
+}
+
+// notify sends the step's notice on each channel and returns the channels
+// that succeeded and an error per channel that failed. Channels the user
+// muted are skipped without counting as a failure. Every channel uses the
+// step's type, dunning_<action>, so only reminders can be muted.
+func (s *Service) notify(ctx context.Context, inst domain.VPSInstance, user domain.User, policy domain.DunningPolicy, step domain.DunningStep) ([]string, []error) {
+	n := buildNotice(inst, policy, step)
+	typ := "dunning_" + string(step.Action)
+	var sent []string
+	var failures []error
+	for _, ch := range step.Channels {
+		var err error
+		if s.messages == nil {
+			err = domain.ErrNotSupported
+		} else {
+			switch ch {
+			case domain.DunningChannelEmail:
+				subject, body := s.renderEmail(ctx, user, typ, n)
+				err = s.messages.SendEmail(ctx, user.ID, typ, subject, body)
+			case domain.DunningChannelSMS:
+				err = s.messages.SendSMS(ctx, user.ID, typ, appshared.SMSMessage{Content: s.renderSMS(ctx, typ, n), Vars: n.vars})
+			case domain.DunningChannelInApp:
+				_, err = s.messages.SendInApp(ctx, user.ID, typ, n.title, n.content)
+			}
+		}
+		if errors.Is(err, domain.ErrNotificationMuted) {
+			continue
+		}
+		if err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", ch, err))
+			continue
+		}
+		sent = append(sent, ch)
+	}
+	return sent, failures
+}
+
+func (s *Service) renderEmail(ctx context.Context, user domain.User, templateName string, n notice) (string, string) {
+	if s.settings != nil {
+		templates, _ := s.settings.ListEmailTemplates(ctx)
+		for _, tmpl := range templates {
+			if tmpl.Name == templateName && tmpl.Enabled {
+				data := templateData(user, n)
+				return appshared.RenderTemplate(tmpl.Subject, data, false), appshared.RenderTemplate(tmpl.Body, data, appshared.IsHTMLContent(tmpl.Body))
+			}
+		}
+	}
+	return n.title, n.content
+}
+
+func (s *Service) renderSMS(ctx context.Context, templateName string, n notice) string {
+	if tmpl, ok := s.smsTemplate(ctx, templateName); ok {
+		return strings.TrimSpace(appshared.RenderTemplate(simpleVarRE.ReplaceAllString(tmpl, "{{.$1}}"), n.vars, false))
+	}
+	return n.content
+}
+
+func (s *Service) settingValue(ctx context.Context, key string) string {
+	if s.settings == nil {
+		return ""
+	}
+	setting, err := s.settings.GetSetting(ctx, key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(setting.ValueJSON)
+}
+
+// smsTemplate looks the template up in the SMS template list kept in
+// settings by the admin SMS template editor.
+func (s *Service) smsTemplate(ctx context.Context, name string) (string, bool) {
+	raw := s.settingValue(ctx, "sms_templates_json")
+	if raw == "" {
+		return "", false
+	}
+	var items []struct {
+		Name    string `json:"name"`
+		Content string `json:"content"`
+		Enabled bool   `json:"enabled"`
+	}
+	if err := json.Unmarshal([]byte(raw), &items); err != nil {
+		return "", false
+	}
+	for _, item := range items {
+		if strings.TrimSpace(item.Name) == name && item.Enabled {
+			return item.Content, true
+		}
+	}
+	return "", false
+}
+
+func templateData(user domain.User, n notice) map[string]any {
+	dunning := map[string]any{"title": n.title, "content": n.content}
+	for k, v := range n.vars {
+		dunning[k] = v
+	}
+	return map[string]any{
+		"user": map[string]any{
+			"id":       user.ID,
+			"username": user.Username,
+			"email":    user.Email,
+		},
+		"vps": map[string]any{
+			"name":      n.vars["name"],
+			"expire_at": n.vars["expire_at"],
+		},
+		"dunning": dunning,
+	}
+}
@@ -0,0 +1,425 @@
+package dunning
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+const (
+	maxSteps      = 20
+	minOffsetDays = -30
+	maxOffsetDays = 365
+	maxAttempts   = 5
+	historyLimit  = 50
+	// terminateDelay is the least time between the previous step of a cycle
+	// and termination, so a user always hears about it before deletion.
+	terminateDelay = 24 * time.Hour
+)
+
+// instanceActions is the slice of vps.Service the suspend and terminate
+// steps use.
+type instanceActions interface {
+	SuspendExpired(ctx context.Context, inst domain.VPSInstance) error
+	TerminateExpired(ctx context.Context, inst domain.VPSInstance) error
+}
+
+// messageSender is the slice of message.Service dunning notices go through;
+// it returns domain.ErrNotificationMuted for channels the user turned off.
+type messageSender interface {
+	SendInApp(ctx context.Context, userID int64, typ, title, content string) (domain.Notification, error)
+	SendEmail(ctx context.Context, userID int64, typ, subject, body string) error
+	SendSMS(ctx context.Context, userID int64, typ string, msg appshared.SMSMessage) error
+}
+
+// Service runs per goods type dunning schedules against expiring instances
+// from the dunning scheduled task.
+type Service struct {
+	repo     appports.DunningRepository
+	vps      appports.VPSRepository
+	users    appports.UserRepository
+	settings appports.SettingsRepository
+	audit    appports.AuditRepository
+	messages messageSender
+	actions  instanceActions
+	now      func() time.Time
+}
+
+func NewService(repo appports.DunningRepository, vps appports.VPSRepository, users appports.UserRepository, settings appports.SettingsRepository, audit appports.AuditRepository) *Service {
+	return &Service{repo: repo, vps: vps, users: users, settings: settings, audit: audit, now: time.Now}
+}
+
+func (s *Service) SetMessageService(messages messageSender) {
+	s.messages = messages
+}
+
+func (s *Service) SetInstanceActions(actions instanceActions) {
+	s.actions = actions
+}
+
+// DefaultSteps is used when a policy is saved without steps: reminders 7, 3
+// and 1 days before expiry, suspension after a 3 day grace period, a final
+// warning and termination two weeks after expiry.
+func DefaultSteps() []domain.DunningStep {
+	all := []string{domain.DunningChannelEmail, domain.DunningChannelSMS, domain.DunningChannelInApp}
+	return []domain.DunningStep{
+		{OffsetDays: -7, Action: domain.DunningActionRemind, Channels: []string{domain.DunningChannelEmail, domain.DunningChannelInApp}},
+		{OffsetDays: -3, Action: domain.DunningActionRemind, Channels: []string{domain.DunningChannelEmail, domain.DunningChannelInApp}},
+		{OffsetDays: -1, Action: domain.DunningActionRemind, Channels: all},
+		{OffsetDays: 3, Action: domain.DunningActionSuspend, Channels: all},
+		{OffsetDays: 10, Action: domain.DunningActionFinalWarning, Channels: all},
+		{OffsetDays: 14, Action: domain.DunningActionTerminate, Channels: []string{domain.DunningChannelEmail, domain.DunningChannelInApp}},
+	}
+}
+
+func (s *Service) ListPolicies(ctx context.Context) ([]domain.DunningPolicy, error) {
+	if s.repo == nil {
+		return nil, appshared.ErrInvalidInput
+	}
+	return s.repo.ListDunningPolicies(ctx)
+}
+
+// SavePolicy creates or replaces the policy of a goods type; goodsTypeID 0 is
+// the fallback for goods types without their own policy.
+func (s *Service) SavePolicy(ctx context.Context, adminID, goodsTypeID int64, input appshared.DunningPolicyInput) (domain.DunningPolicy, error) {
+	if s.repo == nil || goodsTypeID < 0 {
+		return domain.DunningPolicy{}, appshared.ErrInvalidInput
+	}
+	steps := input.Steps
+	if len(steps) == 0 {
+		steps = DefaultSteps()
+	}
+	steps, err := normalizeSteps(steps)
+	if err != nil {
+		return domain.DunningPolicy{}, err
+	}
+	policy := domain.DunningPolicy{GoodsTypeID: goodsTypeID, Enabled: input.Enabled, Steps: steps, UpdatedBy: adminID}
+	if err := s.repo.UpsertDunningPolicy(ctx, &policy); err != nil {
+		return domain.DunningPolicy{}, err
+	}
+	s.addAudit(ctx, adminID, "dunning_policy.save", goodsTypeID, map[string]any{"enabled": policy.Enabled, "steps": len(policy.Steps)})
+	return policy, nil
+}
+
+func (s *Service) DeletePolicy(ctx context.Context, adminID, goodsTypeID int64) error {
+	if s.repo == nil {
+		return appshared.ErrInvalidInput
+	}
+	if err := s.repo.DeleteDunningPolicy(ctx, goodsTypeID); err != nil {
+		return err
+	}
+	s.addAudit(ctx, adminID, "dunning_policy.delete", goodsTypeID, map[string]any{})
+	return nil
+}
+
+func (s *Service) ListRecords(ctx context.Context, filter appshared.DunningRecordFilter, limit, offset int) ([]domain.DunningRecord, int, error) {
+	if s.repo == nil {
+		return nil, 0, appshared.ErrInvalidInput
+	}
+	return s.repo.ListDunningRecords(ctx, filter, limit, offset)
+}
+
+// Manages reports whether an enabled policy applies to the goods type. Such
+// instances are left alone by the expire reminder, lock and cleanup tasks.
+func (s *Service) Manages(ctx context.Context, goodsTypeID int64) bool {
+	policy, ok := s.policyFor(ctx, goodsTypeID)
+	return ok && policy.Enabled
+}
+
+// policyFor returns the goods type's own policy, or the fallback policy when
+// it has none.
+func (s *Service) policyFor(ctx context.Context, goodsTypeID int64) (domain.DunningPolicy, bool) {
+	if s.repo == nil {
+		return domain.DunningPolicy{}, false
+	}
+	policy, err := s.repo.GetDunningPolicy(ctx, goodsTypeID)
+	if err == nil {
+		return policy, true
+	}
+	if goodsTypeID == 0 || !errors.Is(err, appshared.ErrNotFound) {
+		return domain.DunningPolicy{}, false
+	}
+	policy, err = s.repo.GetDunningPolicy(ctx, 0)
+	return policy, err == nil
+}
+
+// Run executes the steps that are due for every instance under an enabled
+// policy and returns how many steps ran.
+func (s *Service) Run(ctx context.Context) (int, error) {
+	if s.repo == nil || s.vps == nil {
+		return 0, nil
+	}
+	now := s.now()
+	instances, err := s.vps.ListInstancesExpiring(ctx, now.AddDate(0, 0, -minOffsetDays))
+	if err != nil {
+		return 0, err
+	}
+	policies := map[int64]*domain.DunningPolicy{}
+	ran := 0
+	var errs []error
+	for _, inst := range instances {
+		if inst.ExpireAt == nil {
+			continue
+		}
+		policy, cached := policies[inst.GoodsTypeID]
+		if !cached {
+			if p, ok := s.policyFor(ctx, inst.GoodsTypeID); ok && p.Enabled {
+				policy = &p
+			}
+			policies[inst.GoodsTypeID] = policy
+		}
+		if policy == nil {
+			continue
+		}
+		n, err := s.runInstance(ctx, inst, *policy, now)
+		ran += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("instance %d: %w", inst.ID, err))
+		}
+	}
+	return ran, errors.Join(errs...)
+}
+
+func (s *Service) runInstance(ctx context.Context, inst domain.VPSInstance, policy domain.DunningPolicy, now time.Time) (int, error) {
+	paid, err := s.renewalPaid(ctx, inst)
+	if err != nil || paid {
+		return 0, err
+	}
+	records, err := s.repo.ListDunningCycleRecords(ctx, inst.ID, *inst.ExpireAt)
+	if err != nil {
+		return 0, err
+	}
+	done := map[string]domain.DunningRecord{}
+	var lastRun time.Time
+	for _, rec := range records {
+		done[rec.StepKey] = rec
+		if rec.Status != domain.DunningStepSkipped && rec.ExecutedAt.After(lastRun) {
+			lastRun = rec.ExecutedAt
+		}
+	}
+	due := dueSteps(policy.Steps, *inst.ExpireAt, now)
+	lastNotice := -1
+	for i, step := range due {
+		if isNotice(step.Action) {
+			lastNotice = i
+		}
+	}
+
+	var user *domain.User
+	ran := 0
+	var errs []error
+	for i, step := range due {
+		if rec, ok := done[step.Key()]; ok {
+			if rec.Status != domain.DunningStepFailed || isNotice(step.Action) || rec.Attempts >= maxAttempts {
+				continue
+			}
+		}
+		record := domain.DunningRecord{
+			InstanceID:    inst.ID,
+			UserID:        inst.UserID,
+			GoodsTypeID:   inst.GoodsTypeID,
+			CycleExpireAt: *inst.ExpireAt,
+			StepKey:       step.Key(),
+			Action:        step.Action,
+			OffsetDays:    step.OffsetDays,
+			ExecutedAt:    now,
+		}
+		switch {
+		case isNotice(step.Action) && i != lastNotice:
+			// A later notice is due as well; only the latest one is sent.
+			record.Status = domain.DunningStepSkipped
+			record.Error = "superseded"
+		case step.Action == domain.DunningActionTerminate && (lastRun.IsZero() || now.Sub(lastRun) < terminateDelay):
+			// Wait until the earlier steps of this cycle have had a day to
+			// reach the user.
+			continue
+		default:
+			if user == nil {
+				u, err := s.users.GetUserByID(ctx, inst.UserID)
+				if err != nil {
+					return ran, err
+				}
+				user = &u
+			}
+			if err := s.execute(ctx, inst, *user, policy, step, &record); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", record.StepKey, err))
+			}
+			lastRun = now
+		}
+		if err := s.repo.SaveDunningRecord(ctx, &record); err != nil {
+			return ran, err
+		}
+		if record.Status != domain.DunningStepSkipped {
+			ran++
+		}
+		if step.Action == domain.DunningActionTerminate && record.Status == domain.DunningStepSuccess {
+			break
+		}
+	}
+	return ran, errors.Join(errs...)
+}
+
+// execute performs the step and fills in the record's outcome, returning an
+// error when the step failed. Suspension and termination notices are only
+// sent once the action succeeded.
+func (s *Service) execute(ctx context.Context, inst domain.VPSInstance, user domain.User, policy domain.DunningPolicy, step domain.DunningStep, record *domain.DunningRecord) error {
+	var actionErr error
+	switch step.Action {
+	case domain.DunningActionSuspend:
+		if s.actions == nil {
+			actionErr = domain.ErrNotSupported
+		} else {
+			actionErr = s.actions.SuspendExpired(ctx, inst)
+		}
+	case domain.DunningActionTerminate:
+		if s.actions == nil {
+			actionErr = domain.ErrNotSupported
+		} else {
+			actionErr = s.actions.TerminateExpired(ctx, inst)
+		}
+	}
+	if actionErr != nil {
+		record.Status = domain.DunningStepFailed
+		record.Error = actionErr.Error()
+		return actionErr
+	}
+	sent, failures := s.notify(ctx, inst, user, policy, step)
+	messages := make([]string, 0, len(failures))
+	for _, err := range failures {
+		messages = append(messages, err.Error())
+	}
+	record.Channels = sent
+	record.Error = strings.Join(messages, "; ")
+	record.Status = domain.DunningStepSuccess
+	if isNotice(step.Action) && len(failures) > 0 && len(sent) == 0 {
+		record.Status = domain.DunningStepFailed
+		return errors.Join(failures...)
+	}
+	return nil
+}
+
+// renewalPaid reports whether a renewal of the instance has been paid and is
+// waiting for review or provisioning; dunning pauses until it lands.
+func (s *Service) renewalPaid(ctx context.Context, inst domain.VPSInstance) (bool, error) {
+	items, err := s.repo.ListPaidRenewalItems(ctx, inst.UserID)
+	if err != nil {
+		return false, err
+	}
+	for _, item := range items {
+		var spec struct {
+			VPSID int64 `json:"vps_id"`
+		}
+		if json.Unmarshal([]byte(item.SpecJSON), &spec) == nil && spec.VPSID == inst.ID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func dueSteps(steps []domain.DunningStep, expireAt, now time.Time) []domain.DunningStep {
+	var out []domain.DunningStep
+	for _, step := range steps {
+		if !dueAt(expireAt, step).After(now) {
+			out = append(out, step)
+		}
+	}
+	return out
+}
+
+func dueAt(expireAt time.Time, step domain.DunningStep) time.Time {
+	return expireAt.AddDate(0, 0, step.OffsetDays)
+}
+
+func isNotice(action domain.DunningAction) bool {
+	return action == domain.DunningActionRemind || action == domain.DunningActionFinalWarning
+}
+
+var actionOrder = map[domain.DunningAction]int{
+	domain.DunningActionRemind:       0,
+	domain.DunningActionSuspend:      1,
+	domain.DunningActionFinalWarning: 2,
+	domain.DunningActionTerminate:    3,
+}
+
+func normalizeSteps(steps []domain.DunningStep) ([]domain.DunningStep, error) {
+	if len(steps) > maxSteps {
+		return nil, domain.ErrInvalidDunningPolicy
+	}
+	out := make([]domain.DunningStep, 0, len(steps))
+	seen := map[string]bool{}
+	counts := map[domain.DunningAction]int{}
+	offsets := map[domain.DunningAction]int{}
+	for _, step := range steps {
+		step.Action = domain.DunningAction(strings.TrimSpace(string(step.Action)))
+		if _, ok := actionOrder[step.Action]; !ok {
+			return nil, domain.ErrInvalidDunningPolicy
+		}
+		if step.OffsetDays < minOffsetDays || step.OffsetDays > maxOffsetDays {
+			return nil, domain.ErrInvalidDunningPolicy
+		}
+		if !isNotice(step.Action) && step.OffsetDays < 0 {
+			return nil, domain.ErrInvalidDunningPolicy
+		}
+		channels, err := normalizeChannels(step.Channels)
+		if err != nil {
+			return nil, err
+		}
+		step.Channels = channels
+		if seen[step.Key()] {
+			return nil, domain.ErrInvalidDunningPolicy
+		}
+		seen[step.Key()] = true
+		counts[step.Action]++
+		offsets[step.Action] = step.OffsetDays
+		out = append(out, step)
+	}
+	if counts[domain.DunningActionSuspend] > 1 || counts[domain.DunningActionTerminate] > 1 {
+		return nil, domain.ErrInvalidDunningPolicy
+	}
+	if counts[domain.DunningActionSuspend] == 1 && counts[domain.DunningActionTerminate] == 1 &&
+		offsets[domain.DunningActionTerminate] <= offsets[domain.DunningActionSuspend] {
+		return nil, domain.ErrInvalidDunningPolicy
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].OffsetDays != out[j].OffsetDays {
+			return out[i].OffsetDays < out[j].OffsetDays
+		}
+		return actionOrder[out[i].Action] < actionOrder[out[j].Action]
+	})
+	return out, nil
+}
+
+func normalizeChannels(channels []string) ([]string, error) {
+	out := make([]string, 0, len(channels))
+	seen := map[string]bool{}
+	for _, ch := range channels {
+		ch = strings.ToLower(strings.TrimSpace(ch))
+		switch ch {
+		case domain.DunningChannelEmail, domain.DunningChannelSMS, domain.DunningChannelInApp:
+		default:
+			return nil, domain.ErrInvalidDunningPolicy
+		}
+		if !seen[ch] {
+			seen[ch] = true
+			out = append(out, ch)
+		}
+	}
+	return out, nil
+}
+
+func (s *Service) addAudit(ctx context.Context, adminID int64, action string, goodsTypeID int64, detail map[string]any) {
+	if s.audit == nil || adminID <= 0 {
+		return
+	}
+	b, _ := json.Marshal(detail)
+	_ = s.audit.AddAuditLog(ctx, domain.AdminAuditLog{AdminID: adminID, Action: action, TargetType: "goods_type", TargetID: strconv.FormatInt(goodsTypeID, 10), DetailJSON: string(b)})
+}
@@ -0,0 +1,153 @@
+package dunning_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	appdunning "xiaoheiplay/internal/app/dunning"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+type fakeActions struct {
+	suspended  []int64
+	terminated []int64
+}
+
+func (f *fakeActions) SuspendExpired(ctx context.Context, inst domain.VPSInstance) error {
+	f.suspended = append(f.suspended, inst.ID)
+	return nil
+}
+
+func (f *fakeActions) TerminateExpired(ctx context.Context, inst domain.VPSInstance) error {
+	f.terminated = append(f.terminated, inst.ID)
+	return nil
+}
+
+type fakeMessages struct{ titles []string }
+
+func (f *fakeMessages) SendInApp(ctx context.Context, userID int64, typ, title, content string) (domain.Notification, error) {
+	f.titles = append(f.titles, title)
+	return domain.Notification{UserID: userID, Type: typ, Title: title, Content: content}, nil
+}
+
+func (f *fakeMessages) SendEmail(ctx context.Context, userID int64, typ, subject, body string) error {
+	return nil
+}
+
+func (f *fakeMessages) SendSMS(ctx context.Context, userID int64, typ string, msg appshared.SMSMessage) error {
+	return nil
+}
+
+func TestDunning_SavePolicyValidates(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	svc := appdunning.NewService(repo, repo, repo, repo, repo)
+
+	_, err := svc.SavePolicy(ctx, 1, 0, appshared.DunningPolicyInput{Enabled: true, Steps: []domain.DunningStep{
+		{OffsetDays: 5, Action: domain.DunningActionSuspend},
+		{OffsetDays: 3, Action: domain.DunningActionTerminate},
+	}})
+	if !errors.Is(err, domain.ErrInvalidDunningPolicy) {
+		t.Fatalf("expected terminate before suspend to fail, got %v", err)
+	}
+	policy, err := svc.SavePolicy(ctx, 1, 0, appshared.DunningPolicyInput{Enabled: true})
+	if err != nil {
+		t.Fatalf("save default policy: %v", err)
+	}
+	if len(policy.Steps) != len(appdunning.DefaultSteps()) {
+		t.Fatalf("expected default steps, got %+v", policy.Steps)
+	}
+	if !svc.Manages(ctx, 42) {
+		t.Fatalf("fallback policy should manage every goods type")
+	}
+}
+
+func TestDunning_RunExecutesDueSteps(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "dun", "dun@example.com", "pass")
+	inApp := []string{domain.DunningChannelInApp}
+	actions := &fakeActions{}
+	messages := &fakeMessages{}
+	svc := appdunning.NewService(repo, repo, repo, repo, repo)
+	svc.SetInstanceActions(actions)
+	svc.SetMessageService(messages)
+	if _, err := svc.SavePolicy(ctx, 1, 0, appshared.DunningPolicyInput{Enabled: true, Steps: []domain.DunningStep{
+		{OffsetDays: -7, Action: domain.DunningActionRemind, Channels: inApp},
+		{OffsetDays: -3, Action: domain.DunningActionRemind, Channels: inApp},
+		{OffsetDays: 3, Action: domain.DunningActionSuspend, Channels: inApp},
+		{OffsetDays: 5, Action: domain.DunningActionTerminate, Channels: inApp},
+	}}); err != nil {
+		t.Fatalf("save policy: %v", err)
+	}
+
+	now := time.Now()
+	newInstance := func(name string, expiredDays int) domain.VPSInstance {
+		expireAt := now.AddDate(0, 0, -expiredDays).Add(-time.Hour)
+		inst := domain.VPSInstance{UserID: user.ID, AutomationInstanceID: name, Name: name, Status: domain.VPSStatusRunning, SpecJSON: "{}", ExpireAt: &expireAt}
+		if err := repo.CreateInstance(ctx, &inst); err != nil {
+			t.Fatalf("create vps: %v", err)
+		}
+		return inst
+	}
+	suspended := newInstance("suspended", 4)
+	waiting := newInstance("waiting", 6)
+	renewed := newInstance("renewed", 4)
+
+	order := domain.Order{UserID: user.ID, OrderNo: "ORD-DUN-1", Status: domain.OrderStatusPendingReview, TotalAmount: 1000, Currency: "CNY"}
+	if err := repo.CreateOrder(ctx, &order); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	if err := repo.CreateOrderItems(ctx, []domain.OrderItem{{OrderID: order.ID, Action: "renew", Status: domain.OrderItemStatusPendingReview, Qty: 1, SpecJSON: `{"vps_id":` + strconv.FormatInt(renewed.ID, 10) + `}`}}); err != nil {
+		t.Fatalf("create renew item: %v", err)
+	}
+
+	ran, err := svc.Run(ctx)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	// Both expired instances get the latest reminder and are suspended; the
+	// paid renewal pauses the third and termination waits a day.
+	if ran != 4 || len(actions.suspended) != 2 || len(actions.terminated) != 0 {
+		t.Fatalf("unexpected first run: ran=%d actions=%+v", ran, actions)
+	}
+	records, total, err := svc.ListRecords(ctx, appshared.DunningRecordFilter{InstanceID: suspended.ID, Status: string(domain.DunningStepSkipped)}, 10, 0)
+	if err != nil || total != 1 || records[0].Error != "superseded" || records[0].OffsetDays != -7 {
+		t.Fatalf("expected the -7 reminder to be superseded: %+v %v", records, err)
+	}
+	if ran, err := svc.Run(ctx); err != nil || ran != 0 {
+		t.Fatalf("second run should be idle: ran=%d err=%v", ran, err)
+	}
+
+	view, err := svc.InstanceView(ctx, suspended.ID)
+	if err != nil || view.Stage != appdunning.StageSuspended {
+		t.Fatalf("unexpected view: %+v %v", view, err)
+	}
+	view, err = svc.InstanceView(ctx, renewed.ID)
+	if err != nil || view.Stage != appdunning.StageRenewalPaid {
+		t.Fatalf("unexpected renewed view: %+v %v", view, err)
+	}
+
+	// Once the earlier steps are a day old the terminate step runs.
+	earlier := now.Add(-25 * time.Hour)
+	for _, step := range []domain.DunningStep{{OffsetDays: -3, Action: domain.DunningActionRemind}, {OffsetDays: 3, Action: domain.DunningActionSuspend}} {
+		if err := repo.SaveDunningRecord(ctx, &domain.DunningRecord{
+			InstanceID: waiting.ID, UserID: user.ID, CycleExpireAt: *waiting.ExpireAt, StepKey: step.Key(),
+			Action: step.Action, OffsetDays: step.OffsetDays, Status: domain.DunningStepSuccess, ExecutedAt: earlier,
+		}); err != nil {
+			t.Fatalf("backdate %s: %v", step.Key(), err)
+		}
+	}
+	ran, err = svc.Run(ctx)
+	if err != nil || ran != 1 || len(actions.terminated) != 1 || actions.terminated[0] != waiting.ID {
+		t.Fatalf("expected termination: ran=%d err=%v actions=%+v", ran, err, actions)
+	}
+	if len(messages.titles) != 5 {
+		t.Fatalf("unexpected messages: %v", messages.titles)
+	}
+}
@@ -0,0 +1,121 @@
+package dunning
+
+import (
+	"context"
+	"time"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+// Stages of an instance in its current cycle.
+const (
+	StageNone         = "none"
+	StageRenewalPaid  = "renewal_paid"
+	StageScheduled    = "scheduled"
+	StageReminded     = "reminded"
+	StageSuspended    = "suspended"
+	StageFinalWarning = "final_warning"
+	StageTerminated   = "terminated"
+)
+
+// Step states in the current cycle; executed steps carry the record status.
+const (
+	StepPending = "pending"
+	StepDue     = "due"
+)
+
+type StepView struct {
+	Step   domain.DunningStep
+	DueAt  time.Time
+	State  string
+	Record *domain.DunningRecord
+}
+
+// View is where an instance is in its dunning pipeline. Policy is nil when
+// no enabled policy covers the instance.
+type View struct {
+	Instance domain.VPSInstance
+	Policy   *domain.DunningPolicy
+	Stage    string
+	Steps    []StepView
+	History  []domain.DunningRecord
+}
+
+func (s *Service) InstanceView(ctx context.Context, instanceID int64) (View, error) {
+	if s.repo == nil || s.vps == nil {
+		return View{}, appshared.ErrInvalidInput
+	}
+	inst, err := s.vps.GetInstance(ctx, instanceID)
+	if err != nil {
+		return View{}, err
+	}
+	return s.view(ctx, inst)
+}
+
+// ViewOf is InstanceView for an instance the caller already loaded, such as
+// one checked for ownership.
+func (s *Service) ViewOf(ctx context.Context, inst domain.VPSInstance) (View, error) {
+	if s.repo == nil {
+		return View{}, appshared.ErrInvalidInput
+	}
+	return s.view(ctx, inst)
+}
+
+func (s *Service) view(ctx context.Context, inst domain.VPSInstance) (View, error) {
+	view := View{Instance: inst, Stage: StageNone}
+	history, _, err := s.repo.ListDunningRecords(ctx, appshared.DunningRecordFilter{InstanceID: inst.ID}, historyLimit, 0)
+	if err != nil {
+		return View{}, err
+	}
+	view.History = history
+	policy, ok := s.policyFor(ctx, inst.GoodsTypeID)
+	if !ok || !policy.Enabled || inst.ExpireAt == nil {
+		return view, nil
+	}
+	view.Policy = &policy
+
+	records, err := s.repo.ListDunningCycleRecords(ctx, inst.ID, *inst.ExpireAt)
+	if err != nil {
+		return View{}, err
+	}
+	byKey := map[string]domain.DunningRecord{}
+	for _, rec := range records {
+		byKey[rec.StepKey] = rec
+	}
+	now := s.now()
+	view.Stage = StageScheduled
+	for _, step := range policy.Steps {
+		sv := StepView{Step: step, DueAt: dueAt(*inst.ExpireAt, step), State: StepPending}
+		if rec, ok := byKey[step.Key()]; ok {
+			sv.Record = &rec
+			sv.State = string(rec.Status)
+			if rec.Status == domain.DunningStepSuccess {
+				view.Stage = stageOf(step.Action)
+			}
+		} else if !sv.DueAt.After(now) {
+			sv.State = StepDue
+		}
+		view.Steps = append(view.Steps, sv)
+	}
+	paid, err := s.renewalPaid(ctx, inst)
+	if err != nil {
+		return View{}, err
+	}
+	if paid {
+		view.Stage = StageRenewalPaid
+	}
+	return view, nil
+}
+
+func stageOf(action domain.DunningAction) string {
+	switch action {
+	case domain.DunningActionSuspend:
+		return StageSuspended
+	case domain.DunningActionFinalWarning:
+		return StageFinalWarning
+	case domain.DunningActionTerminate:
+		return StageTerminated
+	}
+	return StageReminded
+}
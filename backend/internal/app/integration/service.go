@@ -20,6 +20,11 @@ type Service struct {
 	goodsTypes appports.GoodsTypeRepository
 	automation appports.AutomationClientResolver
 	logs       appports.IntegrationLogRepository
+	inventory  inventoryReconciler
+}
+
+type inventoryReconciler interface {
+	Reconcile(ctx context.Context) (int, int, error)
 }
 
 type SyncResult struct {
@@ -32,6 +37,12 @@ func NewService(settings appports.SettingsRepository, catalog appports.CatalogRe
 	return &Service{settings: settings, catalog: catalog, images: images, goodsTypes: goodsTypes, automation: automation, logs: logs}
 }
 
+// SetInventoryReconciler settles local stock reservations after every
+// inventory sync.
+func (s *Service) SetInventoryReconciler(inventory inventoryReconciler) {
+	s.inventory = inventory
+}
+
 func (s *Service) SyncAutomation(ctx context.Context, mode string) (SyncResult, error) {
 	if s.goodsTypes == nil {
 		return SyncResult{}, appshared.ErrInvalidInput
@@ -440,6 +451,14 @@ func (s *Service) SyncAutomationInventoryForGoodsType(ctx context.Context, goods
 		s.appendSyncLog(ctx, "inventory", "inventory_only", "warn", fmt.Sprintf("goods_type_id=%d unsupported=%d", goodsTypeID, unsupported))
 	}
 	s.appendSyncLog(ctx, "inventory", "inventory_only", "ok", fmt.Sprintf("goods_type_id=%d updated=%d", goodsTypeID, updated))
+	if s.inventory != nil {
+		released, committed, err := s.inventory.Reconcile(ctx)
+		if err != nil {
+			s.appendSyncLog(ctx, "inventory", "reconcile", "failed", err.Error())
+			return updated, err
+		}
+		s.appendSyncLog(ctx, "inventory", "reconcile", "ok", fmt.Sprintf("goods_type_id=%d released=%d committed=%d", goodsTypeID, released, committed))
+	}
 	return updated, nil
 }
 
@@ -0,0 +1,311 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+const (
+	settingReservationTTL = "inventory_reservation_ttl_min"
+	defaultReservationTTL = 30 * time.Minute
+
+	ScopePackage   = "package"
+	ScopePlanGroup = "plan_group"
+)
+
+// Service keeps local stock reservations for packages and plan groups so
+// orders cannot oversell what the upstream reported at the last sync.
+type Service struct {
+	repo     appports.InventoryRepository
+	catalog  appports.CatalogRepository
+	orders   appports.OrderRepository
+	items    appports.OrderItemRepository
+	settings appports.SettingsRepository
+	now      func() time.Time
+}
+
+func NewService(repo appports.InventoryRepository, catalog appports.CatalogRepository, orders appports.OrderRepository, items appports.OrderItemRepository, settings appports.SettingsRepository) *Service {
+	return &Service{repo: repo, catalog: catalog, orders: orders, items: items, settings: settings, now: time.Now}
+}
+
+// Shortages reports every package or plan group that cannot cover demand, a
+// quantity per package id.
+func (s *Service) Shortages(ctx context.Context, demand map[int64]int) ([]appshared.StockShortage, error) {
+	if s.catalog == nil || len(demand) == 0 {
+		return nil, nil
+	}
+	var out []appshared.StockShortage
+	planDemand := map[int64]int{}
+	for _, packageID := range sortedKeys(demand) {
+		qty := demand[packageID]
+		if qty <= 0 {
+			continue
+		}
+		pkg, err := s.catalog.GetPackage(ctx, packageID)
+		if err != nil {
+			return nil, err
+		}
+		if avail := pkg.CapacityAvailable(); avail >= 0 && avail < qty {
+			out = append(out, appshared.StockShortage{PackageID: pkg.ID, PlanGroupID: pkg.PlanGroupID, Scope: ScopePackage, Requested: qty, Available: avail})
+		}
+		if pkg.PlanGroupID > 0 {
+			planDemand[pkg.PlanGroupID] += qty
+		}
+	}
+	for _, planID := range sortedKeys(planDemand) {
+		plan, err := s.catalog.GetPlanGroup(ctx, planID)
+		if err != nil {
+			return nil, err
+		}
+		if avail := plan.CapacityAvailable(); avail >= 0 && avail < planDemand[planID] {
+			out = append(out, appshared.StockShortage{PlanGroupID: plan.ID, Scope: ScopePlanGroup, Requested: planDemand[planID], Available: avail})
+		}
+	}
+	return out, nil
+}
+
+// CartShortages is Shortages for the quantities currently in a cart.
+func (s *Service) CartShortages(ctx context.Context, items []domain.CartItem) ([]appshared.StockShortage, error) {
+	demand := map[int64]int{}
+	for _, item := range items {
+		qty := item.Qty
+		if qty <= 0 {
+			qty = 1
+		}
+		demand[item.PackageID] += qty
+	}
+	return s.Shortages(ctx, demand)
+}
+
+// Check returns a *appshared.StockShortageError when demand exceeds the
+// available stock.
+func (s *Service) Check(ctx context.Context, demand map[int64]int) error {
+	shortages, err := s.Shortages(ctx, demand)
+	if err != nil {
+		return err
+	}
+	if len(shortages) > 0 {
+		return &appshared.StockShortageError{Shortages: shortages}
+	}
+	return nil
+}
+
+// Reserve holds stock for the create items of a freshly created order.
+// Unpaid orders get an expiry after which the order is canceled.
+func (s *Service) Reserve(ctx context.Context, order domain.Order, items []domain.OrderItem) error {
+	if s.repo == nil || s.catalog == nil {
+		return nil
+	}
+	planned, err := s.Plan(ctx, order, items)
+	if err != nil {
+		return err
+	}
+	reservations := make([]domain.InventoryReservation, 0, len(planned))
+	for i, res := range planned {
+		if res.PackageID <= 0 {
+			continue
+		}
+		res.OrderID = order.ID
+		res.OrderItemID = items[i].ID
+		reservations = append(reservations, res)
+	}
+	return s.ShortageError(ctx, planned, s.repo.ReserveInventory(ctx, reservations))
+}
+
+// Plan builds the reservations for order items that are not created yet, so
+// they can be held in the same transaction as the order. The result is
+// parallel to items; entries without a PackageID need no stock.
+func (s *Service) Plan(ctx context.Context, order domain.Order, items []domain.OrderItem) ([]domain.InventoryReservation, error) {
+	if s.repo == nil || s.catalog == nil {
+		return nil, nil
+	}
+	var expiresAt *time.Time
+	if order.Status == domain.OrderStatusPendingPayment {
+		if ttl := s.reservationTTL(ctx); ttl > 0 {
+			at := s.now().Add(ttl)
+			expiresAt = &at
+		}
+	}
+	planByPackage := map[int64]int64{}
+	out := make([]domain.InventoryReservation, len(items))
+	for i, item := range items {
+		if item.Action != "create" || item.PackageID <= 0 {
+			continue
+		}
+		planID, ok := planByPackage[item.PackageID]
+		if !ok {
+			pkg, err := s.catalog.GetPackage(ctx, item.PackageID)
+			if err != nil {
+				return nil, err
+			}
+			planID = pkg.PlanGroupID
+			planByPackage[item.PackageID] = planID
+		}
+		qty := item.Qty
+		if qty <= 0 {
+			qty = 1
+		}
+		out[i] = domain.InventoryReservation{
+			PackageID:   item.PackageID,
+			PlanGroupID: planID,
+			Qty:         qty,
+			ExpiresAt:   expiresAt,
+		}
+	}
+	return out, nil
+}
+
+// ShortageError turns domain.ErrInsufficientStock from holding the planned
+// reservations into a *appshared.StockShortageError with the shortages.
+func (s *Service) ShortageError(ctx context.Context, planned []domain.InventoryReservation, err error) error {
+	if !errors.Is(err, domain.ErrInsufficientStock) {
+		return err
+	}
+	demand := map[int64]int{}
+	for _, res := range planned {
+		if res.PackageID > 0 {
+			demand[res.PackageID] += res.Qty
+		}
+	}
+	shortages, _ := s.Shortages(ctx, demand)
+	return &appshared.StockShortageError{Shortages: shortages}
+}
+
+func (s *Service) Release(ctx context.Context, orderID int64) error {
+	if s.repo == nil {
+		return nil
+	}
+	_, err := s.repo.ReleaseInventoryReservations(ctx, orderID)
+	return err
+}
+
+// Commit finalizes the reservation once the host exists upstream and takes
+// the unit out of the local capacity until the next inventory sync.
+func (s *Service) Commit(ctx context.Context, orderItemID int64) error {
+	if s.repo == nil {
+		return nil
+	}
+	_, err := s.repo.CommitInventoryReservation(ctx, orderItemID, true)
+	return err
+}
+
+// ExpiredOrders returns the orders holding reservations past their expiry.
+func (s *Service) ExpiredOrders(ctx context.Context, limit int) ([]int64, error) {
+	if s.repo == nil {
+		return nil, nil
+	}
+	items, err := s.repo.ListExpiredInventoryReservations(ctx, s.now(), limit)
+	if err != nil {
+		return nil, err
+	}
+	var out []int64
+	seen := map[int64]bool{}
+	for _, item := range items {
+		if !seen[item.OrderID] {
+			seen[item.OrderID] = true
+			out = append(out, item.OrderID)
+		}
+	}
+	return out, nil
+}
+
+// KeepOrder drops the expiry of an order that moved past payment.
+func (s *Service) KeepOrder(ctx context.Context, orderID int64) error {
+	if s.repo == nil {
+		return nil
+	}
+	return s.repo.ClearInventoryReservationExpiry(ctx, orderID)
+}
+
+// Reconcile settles reservations that the order flow left behind: orders that
+// ended without provisioning are released, items that already own an upstream
+// host are committed without touching capacity (the upstream sync already
+// counts them), and the reserved counters are rebuilt from what remains.
+func (s *Service) Reconcile(ctx context.Context) (int, int, error) {
+	if s.repo == nil {
+		return 0, 0, nil
+	}
+	active, err := s.repo.ListInventoryReservations(ctx, domain.InventoryReservationReserved, 5000)
+	if err != nil {
+		return 0, 0, err
+	}
+	released, committed := 0, 0
+	abandoned := map[int64]bool{}
+	for _, res := range active {
+		gone, checked := abandoned[res.OrderID]
+		if !checked {
+			gone = s.orderAbandoned(ctx, res.OrderID)
+			abandoned[res.OrderID] = gone
+			if gone {
+				n, err := s.repo.ReleaseInventoryReservations(ctx, res.OrderID)
+				if err != nil {
+					return released, committed, err
+				}
+				released += n
+			}
+		}
+		if gone {
+			continue
+		}
+		if s.items == nil {
+			continue
+		}
+		item, err := s.items.GetOrderItem(ctx, res.OrderItemID)
+		if err != nil {
+			continue
+		}
+		if item.Status == domain.OrderItemStatusActive || strings.TrimSpace(item.AutomationInstanceID) != "" {
+			ok, err := s.repo.CommitInventoryReservation(ctx, res.OrderItemID, false)
+			if err != nil {
+				return released, committed, err
+			}
+			if ok {
+				committed++
+			}
+		}
+	}
+	return released, committed, s.repo.RecountInventoryReserved(ctx)
+}
+
+func (s *Service) orderAbandoned(ctx context.Context, orderID int64) bool {
+	if s.orders == nil {
+		return false
+	}
+	order, err := s.orders.GetOrder(ctx, orderID)
+	if err != nil {
+		return errors.Is(err, appshared.ErrNotFound)
+	}
+	return order.Status == domain.OrderStatusCanceled || order.Status == domain.OrderStatusRejected
+}
+
+func (s *Service) reservationTTL(ctx context.Context) time.Duration {
+	if s.settings == nil {
+		return defaultReservationTTL
+	}
+	setting, err := s.settings.GetSetting(ctx, settingReservationTTL)
+	if err != nil {
+		return defaultReservationTTL
+	}
+	minutes, err := strconv.Atoi(strings.TrimSpace(setting.ValueJSON))
+	if err != nil || minutes < 0 {
+		return defaultReservationTTL
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+func sortedKeys(m map[int64]int) []int64 {
+	keys := make([]int64, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
@@ -0,0 +1,111 @@
+package inventory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appinventory "xiaoheiplay/internal/app/inventory"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+func TestInventoryService_ReserveReleaseCommit(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	seed := testutil.SeedCatalog(t, repo)
+	pkg := seed.Package
+	pkg.CapacityRemaining = 2
+	if err := repo.UpdatePackage(ctx, pkg); err != nil {
+		t.Fatalf("update package: %v", err)
+	}
+	user := testutil.CreateUser(t, repo, "stock", "stock@example.com", "pass")
+	svc := appinventory.NewService(repo, repo, repo, repo, repo)
+
+	newOrder := func(no string, qty int) (domain.Order, []domain.OrderItem) {
+		order := domain.Order{UserID: user.ID, OrderNo: no, Status: domain.OrderStatusPendingPayment, Currency: "CNY"}
+		if err := repo.CreateOrder(ctx, &order); err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+		items := []domain.OrderItem{{OrderID: order.ID, PackageID: pkg.ID, Qty: qty, Status: domain.OrderItemStatusPendingPayment, Action: "create"}}
+		if err := repo.CreateOrderItems(ctx, items); err != nil {
+			t.Fatalf("create items: %v", err)
+		}
+		return order, items
+	}
+
+	first, firstItems := newOrder("INV-1", 2)
+	if err := svc.Reserve(ctx, first, firstItems); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	second, secondItems := newOrder("INV-2", 1)
+	err := svc.Reserve(ctx, second, secondItems)
+	var shortage *appshared.StockShortageError
+	if !errors.As(err, &shortage) || !errors.Is(err, domain.ErrInsufficientStock) {
+		t.Fatalf("expected stock shortage, got %v", err)
+	}
+	if len(shortage.Shortages) != 1 || shortage.Shortages[0].Available != 0 {
+		t.Fatalf("unexpected shortages: %+v", shortage.Shortages)
+	}
+
+	if err := svc.Release(ctx, first.ID); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if err := svc.Reserve(ctx, second, secondItems); err != nil {
+		t.Fatalf("reserve after release: %v", err)
+	}
+	if err := svc.Commit(ctx, secondItems[0].ID); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	got, err := repo.GetPackage(ctx, pkg.ID)
+	if err != nil {
+		t.Fatalf("get package: %v", err)
+	}
+	if got.CapacityRemaining != 1 || got.CapacityReserved != 0 || got.CapacityAvailable() != 1 {
+		t.Fatalf("unexpected capacity: remaining=%d reserved=%d", got.CapacityRemaining, got.CapacityReserved)
+	}
+}
+
+func TestInventoryService_ReconcileReleasesCanceledOrders(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	seed := testutil.SeedCatalog(t, repo)
+	plan := seed.PlanGroup
+	plan.CapacityRemaining = 1
+	if err := repo.UpdatePlanGroup(ctx, plan); err != nil {
+		t.Fatalf("update plan group: %v", err)
+	}
+	user := testutil.CreateUser(t, repo, "stock2", "stock2@example.com", "pass")
+	svc := appinventory.NewService(repo, repo, repo, repo, repo)
+
+	order := domain.Order{UserID: user.ID, OrderNo: "INV-3", Status: domain.OrderStatusPendingPayment, Currency: "CNY"}
+	if err := repo.CreateOrder(ctx, &order); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	items := []domain.OrderItem{{OrderID: order.ID, PackageID: seed.Package.ID, Qty: 1, Status: domain.OrderItemStatusPendingPayment, Action: "create"}}
+	if err := repo.CreateOrderItems(ctx, items); err != nil {
+		t.Fatalf("create items: %v", err)
+	}
+	if err := svc.Reserve(ctx, order, items); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	if err := svc.Check(ctx, map[int64]int{seed.Package.ID: 1}); err == nil {
+		t.Fatalf("expected plan group shortage")
+	}
+
+	order.Status = domain.OrderStatusCanceled
+	if err := repo.UpdateOrderStatus(ctx, order.ID, order.Status); err != nil {
+		t.Fatalf("cancel order: %v", err)
+	}
+	released, _, err := svc.Reconcile(ctx)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if released != 1 {
+		t.Fatalf("expected 1 released, got %d", released)
+	}
+	if err := svc.Check(ctx, map[int64]int{seed.Package.ID: 1}); err != nil {
+		t.Fatalf("expected stock after reconcile: %v", err)
+	}
+}
@@ -0,0 +1,430 @@
+package ipaddress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+const maxNoteLen = 2000
+
+type CreateInput struct {
+	Address string
+	LineID  int64
+	Status  domain.IPAddressStatus
+	Note    string
+}
+
+type UpdateInput struct {
+	LineID *int64
+	Status *domain.IPAddressStatus
+	Note   *string
+}
+
+// Service keeps the IP address pool in sync with automation instance info and
+// handles reverse DNS. Addresses are created on first sight; admins may also
+// add free or reserved addresses to a line's pool by hand.
+type Service struct {
+	repo       appports.IPAddressRepository
+	vps        appports.VPSRepository
+	automation appports.AutomationClientResolver
+}
+
+func NewService(repo appports.IPAddressRepository, vps appports.VPSRepository, automation appports.AutomationClientResolver) *Service {
+	return &Service{repo: repo, vps: vps, automation: automation}
+}
+
+// SyncInstance reconciles the addresses bound to inst with info. Addresses no
+// longer reported are released. When the plugin reports neither ips nor
+// remote_ip nothing is released, since older plugins never report ips.
+func (s *Service) SyncInstance(ctx context.Context, inst domain.VPSInstance, info appshared.AutomationHostInfo) error {
+	reported := info.IPs
+	if len(reported) == 0 && strings.TrimSpace(info.RemoteIP) != "" {
+		reported = []appshared.AutomationIP{{Address: info.RemoteIP, Primary: true}}
+	}
+	if len(reported) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(reported))
+	for _, item := range reported {
+		addr, version, err := normalizeAddress(item.Address)
+		if err != nil || seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		if err := s.assign(ctx, inst, addr, version, item); err != nil {
+			return err
+		}
+	}
+	if len(seen) == 0 {
+		return nil
+	}
+	current, err := s.repo.ListIPAddressesByVPS(ctx, inst.ID)
+	if err != nil {
+		return err
+	}
+	for _, ip := range current {
+		if seen[ip.Address] {
+			continue
+		}
+		if err := s.release(ctx, ip, "no longer reported by automation"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) assign(ctx context.Context, inst domain.VPSInstance, addr string, version int, item appshared.AutomationIP) error {
+	ptr := strings.TrimSuffix(strings.TrimSpace(item.PTR), ".")
+	ip, err := s.repo.GetIPAddressByAddress(ctx, addr)
+	if errors.Is(err, appshared.ErrNotFound) {
+		ip = domain.IPAddress{
+			Address: addr,
+			Version: version,
+			LineID:  inst.LineID,
+			VPSID:   inst.ID,
+			UserID:  inst.UserID,
+			Primary: item.Primary,
+			PTR:     ptr,
+			Status:  domain.IPAddressStatusAssigned,
+		}
+		if err := s.repo.CreateIPAddress(ctx, &ip); err != nil {
+			return err
+		}
+		return s.event(ctx, ip, domain.IPAddressEventAssigned, 0, "")
+	}
+	if err != nil {
+		return err
+	}
+	if ip.VPSID != 0 && ip.VPSID != inst.ID {
+		if err := s.event(ctx, ip, domain.IPAddressEventReleased, 0, "reassigned to another instance"); err != nil {
+			return err
+		}
+	}
+	assigned := ip.VPSID != inst.ID
+	ip.Version = version
+	ip.VPSID = inst.ID
+	ip.UserID = inst.UserID
+	ip.Primary = item.Primary
+	if ip.LineID == 0 {
+		ip.LineID = inst.LineID
+	}
+	if ptr != "" {
+		ip.PTR = ptr
+	}
+	if ip.Status != domain.IPAddressStatusBlocked {
+		ip.Status = domain.IPAddressStatusAssigned
+	}
+	if err := s.repo.UpdateIPAddress(ctx, ip); err != nil {
+		return err
+	}
+	if assigned {
+		return s.event(ctx, ip, domain.IPAddressEventAssigned, 0, "")
+	}
+	return nil
+}
+
+func (s *Service) release(ctx context.Context, ip domain.IPAddress, detail string) error {
+	if err := s.event(ctx, ip, domain.IPAddressEventReleased, 0, detail); err != nil {
+		return err
+	}
+	ip.VPSID = 0
+	ip.UserID = 0
+	ip.Primary = false
+	ip.PTR = ""
+	if ip.Status == domain.IPAddressStatusAssigned {
+		ip.Status = domain.IPAddressStatusAvailable
+	}
+	return s.repo.UpdateIPAddress(ctx, ip)
+}
+
+// Refresh pulls the instance info from automation and syncs its addresses.
+func (s *Service) Refresh(ctx context.Context, inst domain.VPSInstance) error {
+	hostID := parseHostID(inst.AutomationInstanceID)
+	if hostID == 0 || s.automation == nil {
+		return appshared.ErrInvalidInput
+	}
+	cli, err := s.automation.ClientForGoodsType(ctx, inst.GoodsTypeID)
+	if err != nil {
+		return err
+	}
+	info, err := cli.GetHostInfo(ctx, hostID)
+	if err != nil {
+		return err
+	}
+	return s.SyncInstance(ctx, inst, info)
+}
+
+// ListForVPS returns the addresses of a user's instance. Instances that were
+// never synced are refreshed once on demand.
+func (s *Service) ListForVPS(ctx context.Context, userID, vpsID int64) ([]domain.IPAddress, error) {
+	inst, err := s.ownedInstance(ctx, userID, vpsID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.ListIPAddressesByVPS(ctx, inst.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > 0 {
+		return items, nil
+	}
+	if err := s.Refresh(ctx, inst); err != nil {
+		return items, nil
+	}
+	return s.repo.ListIPAddressesByVPS(ctx, inst.ID)
+}
+
+// SetPTR updates the reverse DNS record of one address of a user's instance.
+// An empty ptr resets the record to the provider default.
+func (s *Service) SetPTR(ctx context.Context, userID, vpsID int64, address, ptr string) (domain.IPAddress, error) {
+	inst, err := s.ownedInstance(ctx, userID, vpsID)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	addr, _, err := normalizeAddress(address)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	ptr, err = NormalizePTR(ptr)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	ip, err := s.repo.GetIPAddressByAddress(ctx, addr)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	if ip.VPSID != inst.ID {
+		return domain.IPAddress{}, appshared.ErrNotFound
+	}
+	if ip.Status == domain.IPAddressStatusBlocked {
+		return domain.IPAddress{}, domain.ErrIPAddressBlocked
+	}
+	hostID := parseHostID(inst.AutomationInstanceID)
+	if hostID == 0 || s.automation == nil {
+		return domain.IPAddress{}, appshared.ErrInvalidInput
+	}
+	cli, err := s.automation.ClientForGoodsType(ctx, inst.GoodsTypeID)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	if err := cli.SetReverseDNS(ctx, hostID, ip.Address, ptr); err != nil {
+		if errors.Is(err, appshared.ErrNotSupported) {
+			return domain.IPAddress{}, domain.ErrReverseDNSNotSupported
+		}
+		return domain.IPAddress{}, err
+	}
+	old := ip.PTR
+	ip.PTR = ptr
+	if err := s.repo.UpdateIPAddress(ctx, ip); err != nil {
+		return domain.IPAddress{}, err
+	}
+	if err := s.event(ctx, ip, domain.IPAddressEventPTR, 0, fmt.Sprintf("%s -> %s", old, ptr)); err != nil {
+		return domain.IPAddress{}, err
+	}
+	return ip, nil
+}
+
+func (s *Service) List(ctx context.Context, filter appshared.IPAddressFilter, limit, offset int) ([]domain.IPAddress, int, error) {
+	return s.repo.ListIPAddresses(ctx, filter, limit, offset)
+}
+
+func (s *Service) Get(ctx context.Context, id int64) (domain.IPAddress, error) {
+	return s.repo.GetIPAddress(ctx, id)
+}
+
+func (s *Service) Events(ctx context.Context, id int64, limit, offset int) ([]domain.IPAddressEvent, int, error) {
+	if _, err := s.repo.GetIPAddress(ctx, id); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListIPAddressEvents(ctx, id, limit, offset)
+}
+
+// Create adds a free address to a line's pool.
+func (s *Service) Create(ctx context.Context, adminID int64, in CreateInput) (domain.IPAddress, error) {
+	addr, version, err := normalizeAddress(in.Address)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	status := in.Status
+	if status == "" {
+		status = domain.IPAddressStatusAvailable
+	}
+	if !freeStatus(status) || in.LineID < 0 || len(in.Note) > maxNoteLen {
+		return domain.IPAddress{}, appshared.ErrInvalidInput
+	}
+	if _, err := s.repo.GetIPAddressByAddress(ctx, addr); err == nil {
+		return domain.IPAddress{}, domain.ErrIPAddressExists
+	} else if !errors.Is(err, appshared.ErrNotFound) {
+		return domain.IPAddress{}, err
+	}
+	ip := domain.IPAddress{
+		Address: addr,
+		Version: version,
+		LineID:  in.LineID,
+		Status:  status,
+		Note:    strings.TrimSpace(in.Note),
+	}
+	if err := s.repo.CreateIPAddress(ctx, &ip); err != nil {
+		return domain.IPAddress{}, err
+	}
+	if err := s.event(ctx, ip, domain.IPAddressEventStatus, adminID, string(status)); err != nil {
+		return domain.IPAddress{}, err
+	}
+	return ip, nil
+}
+
+// Update changes the line, status or note of an address. Assigned addresses
+// can only be blocked or unblocked; the other statuses follow allocation.
+func (s *Service) Update(ctx context.Context, adminID, id int64, in UpdateInput) (domain.IPAddress, error) {
+	ip, err := s.repo.GetIPAddress(ctx, id)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	if in.LineID != nil {
+		if *in.LineID < 0 {
+			return domain.IPAddress{}, appshared.ErrInvalidInput
+		}
+		ip.LineID = *in.LineID
+	}
+	if in.Note != nil {
+		if len(*in.Note) > maxNoteLen {
+			return domain.IPAddress{}, appshared.ErrInvalidInput
+		}
+		ip.Note = strings.TrimSpace(*in.Note)
+	}
+	old := ip.Status
+	if in.Status != nil && *in.Status != ip.Status {
+		next := *in.Status
+		switch {
+		case ip.VPSID > 0 && next != domain.IPAddressStatusAssigned && next != domain.IPAddressStatusBlocked:
+			return domain.IPAddress{}, domain.ErrIPAddressInUse
+		case ip.VPSID == 0 && !freeStatus(next):
+			return domain.IPAddress{}, appshared.ErrInvalidInput
+		}
+		ip.Status = next
+	}
+	if err := s.repo.UpdateIPAddress(ctx, ip); err != nil {
+		return domain.IPAddress{}, err
+	}
+	if ip.Status != old {
+		if err := s.event(ctx, ip, domain.IPAddressEventStatus, adminID, fmt.Sprintf("%s -> %s", old, ip.Status)); err != nil {
+			return domain.IPAddress{}, err
+		}
+	}
+	return ip, nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	ip, err := s.repo.GetIPAddress(ctx, id)
+	if err != nil {
+		return err
+	}
+	if ip.VPSID > 0 {
+		return domain.ErrIPAddressInUse
+	}
+	return s.repo.DeleteIPAddress(ctx, id)
+}
+
+// ReportAbuse records an abuse report against the current holder of the
+// address and optionally blocks it.
+func (s *Service) ReportAbuse(ctx context.Context, adminID, id int64, detail string, block bool) (domain.IPAddress, error) {
+	detail = strings.TrimSpace(detail)
+	if detail == "" || len(detail) > maxNoteLen {
+		return domain.IPAddress{}, appshared.ErrInvalidInput
+	}
+	ip, err := s.repo.GetIPAddress(ctx, id)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	if err := s.event(ctx, ip, domain.IPAddressEventAbuse, adminID, detail); err != nil {
+		return domain.IPAddress{}, err
+	}
+	if !block || ip.Status == domain.IPAddressStatusBlocked {
+		return ip, nil
+	}
+	blocked := domain.IPAddressStatusBlocked
+	return s.Update(ctx, adminID, id, UpdateInput{Status: &blocked})
+}
+
+func (s *Service) ownedInstance(ctx context.Context, userID, vpsID int64) (domain.VPSInstance, error) {
+	inst, err := s.vps.GetInstance(ctx, vpsID)
+	if err != nil {
+		return domain.VPSInstance{}, err
+	}
+	if inst.UserID != userID {
+		return domain.VPSInstance{}, appshared.ErrForbidden
+	}
+	return inst, nil
+}
+
+func (s *Service) event(ctx context.Context, ip domain.IPAddress, typ domain.IPAddressEventType, adminID int64, detail string) error {
+	return s.repo.CreateIPAddressEvent(ctx, &domain.IPAddressEvent{
+		IPAddressID: ip.ID,
+		Address:     ip.Address,
+		Type:        typ,
+		VPSID:       ip.VPSID,
+		UserID:      ip.UserID,
+		AdminID:     adminID,
+		Detail:      detail,
+	})
+}
+
+func freeStatus(status domain.IPAddressStatus) bool {
+	switch status {
+	case domain.IPAddressStatusAvailable, domain.IPAddressStatusReserved, domain.IPAddressStatusBlocked:
+		return true
+	}
+	return false
+}
+
+func normalizeAddress(v string) (string, int, error) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(v))
+	if err != nil || addr.Zone() != "" || addr.IsUnspecified() {
+		return "", 0, domain.ErrInvalidIPAddress
+	}
+	addr = addr.Unmap()
+	if addr.Is4() {
+		return addr.String(), 4, nil
+	}
+	return addr.String(), 6, nil
+}
+
+// NormalizePTR lowercases ptr and checks it is a fully qualified hostname.
+// The trailing dot is optional; an empty value is allowed.
+func NormalizePTR(v string) (string, error) {
+	v = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(v), "."))
+	if v == "" {
+		return "", nil
+	}
+	if len(v) > 253 {
+		return "", domain.ErrInvalidPTR
+	}
+	labels := strings.Split(v, ".")
+	if len(labels) < 2 {
+		return "", domain.ErrInvalidPTR
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return "", domain.ErrInvalidPTR
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return "", domain.ErrInvalidPTR
+			}
+		}
+	}
+	return v, nil
+}
+
+func parseHostID(v string) int64 {
+	id, _ := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	return id
+}
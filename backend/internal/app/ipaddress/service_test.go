@@ -0,0 +1,185 @@
+package ipaddress_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appipaddress "xiaoheiplay/internal/app/ipaddress"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+func createIPTestInstance(t *testing.T, repo interface {
+	CreateInstance(ctx context.Context, inst *domain.VPSInstance) error
+}, userID int64) domain.VPSInstance {
+	t.Helper()
+	inst := domain.VPSInstance{
+		UserID:               userID,
+		AutomationInstanceID: "7",
+		GoodsTypeID:          1,
+		LineID:               3,
+		Name:                 "vm",
+		Status:               domain.VPSStatusRunning,
+		SpecJSON:             "{}",
+	}
+	if err := repo.CreateInstance(context.Background(), &inst); err != nil {
+		t.Fatalf("create vps: %v", err)
+	}
+	return inst
+}
+
+func TestIPAddress_SyncAssignsAndReleases(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "ip", "ip@example.com", "pass")
+	inst := createIPTestInstance(t, repo, user.ID)
+	svc := appipaddress.NewService(repo, repo, &testutil.FakeAutomationResolver{Client: &testutil.FakeAutomationClient{}})
+
+	err := svc.SyncInstance(ctx, inst, appshared.AutomationHostInfo{
+		RemoteIP: "203.0.113.10",
+		IPs: []appshared.AutomationIP{
+			{Address: "203.0.113.10", Version: 4, Primary: true, PTR: "vm.example.com."},
+			{Address: "2001:DB8::10", Version: 6},
+			{Address: "not-an-ip"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	items, err := svc.ListForVPS(ctx, user.ID, inst.ID)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(items) != 2 || items[0].Address != "203.0.113.10" || !items[0].Primary || items[0].PTR != "vm.example.com" || items[0].LineID != 3 {
+		t.Fatalf("unexpected ips: %+v", items)
+	}
+	if items[1].Address != "2001:db8::10" || items[1].Version != 6 || items[1].Status != domain.IPAddressStatusAssigned {
+		t.Fatalf("unexpected ipv6: %+v", items[1])
+	}
+	if _, err := svc.ListForVPS(ctx, user.ID+1, inst.ID); !errors.Is(err, appshared.ErrForbidden) {
+		t.Fatalf("expected forbidden, got %v", err)
+	}
+
+	// An empty report must not release anything.
+	if err := svc.SyncInstance(ctx, inst, appshared.AutomationHostInfo{}); err != nil {
+		t.Fatalf("empty sync: %v", err)
+	}
+	if err := svc.SyncInstance(ctx, inst, appshared.AutomationHostInfo{RemoteIP: "203.0.113.10"}); err != nil {
+		t.Fatalf("resync: %v", err)
+	}
+	items, _ = svc.ListForVPS(ctx, user.ID, inst.ID)
+	if len(items) != 1 || items[0].PTR != "vm.example.com" {
+		t.Fatalf("expected ipv6 released and ptr kept, got %+v", items)
+	}
+	v6, err := repo.GetIPAddressByAddress(ctx, "2001:db8::10")
+	if err != nil {
+		t.Fatalf("get ipv6: %v", err)
+	}
+	if v6.VPSID != 0 || v6.Status != domain.IPAddressStatusAvailable {
+		t.Fatalf("unexpected released ip: %+v", v6)
+	}
+	events, total, err := svc.Events(ctx, v6.ID, 10, 0)
+	if err != nil || total != 2 || events[0].Type != domain.IPAddressEventReleased || events[0].VPSID != inst.ID {
+		t.Fatalf("unexpected events: %+v total=%d err=%v", events, total, err)
+	}
+
+	if err := repo.DeleteInstance(ctx, inst.ID); err != nil {
+		t.Fatalf("delete vps: %v", err)
+	}
+	v4, _ := repo.GetIPAddressByAddress(ctx, "203.0.113.10")
+	if v4.VPSID != 0 || v4.PTR != "" || v4.Status != domain.IPAddressStatusAvailable {
+		t.Fatalf("expected ip released on delete, got %+v", v4)
+	}
+}
+
+func TestIPAddress_SetPTR(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "ptr", "ptr@example.com", "pass")
+	inst := createIPTestInstance(t, repo, user.ID)
+	client := &testutil.FakeAutomationClient{}
+	svc := appipaddress.NewService(repo, repo, &testutil.FakeAutomationResolver{Client: client})
+	if err := svc.SyncInstance(ctx, inst, appshared.AutomationHostInfo{RemoteIP: "198.51.100.7"}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	for _, ptr := range []string{"localhost", "-bad.example.com", "bad_label.example.com"} {
+		if _, err := svc.SetPTR(ctx, user.ID, inst.ID, "198.51.100.7", ptr); !errors.Is(err, domain.ErrInvalidPTR) {
+			t.Fatalf("expected invalid ptr for %q, got %v", ptr, err)
+		}
+	}
+	if _, err := svc.SetPTR(ctx, user.ID, inst.ID, "198.51.100.8", "a.example.com"); !errors.Is(err, appshared.ErrNotFound) {
+		t.Fatalf("expected foreign ip to be rejected, got %v", err)
+	}
+	ip, err := svc.SetPTR(ctx, user.ID, inst.ID, "198.51.100.7", "Mail.Example.com.")
+	if err != nil {
+		t.Fatalf("set ptr: %v", err)
+	}
+	if ip.PTR != "mail.example.com" {
+		t.Fatalf("unexpected ptr: %q", ip.PTR)
+	}
+	if len(client.ReverseDNSCalls) != 1 || client.ReverseDNSCalls[0].HostID != 7 || client.ReverseDNSCalls[0].PTR != "mail.example.com" {
+		t.Fatalf("unexpected rdns calls: %+v", client.ReverseDNSCalls)
+	}
+
+	client.ReverseDNSErr = appshared.ErrNotSupported
+	if _, err := svc.SetPTR(ctx, user.ID, inst.ID, "198.51.100.7", ""); !errors.Is(err, domain.ErrReverseDNSNotSupported) {
+		t.Fatalf("expected not supported, got %v", err)
+	}
+	client.ReverseDNSErr = nil
+
+	if _, err := svc.ReportAbuse(ctx, 1, ip.ID, "spam", true); err != nil {
+		t.Fatalf("report abuse: %v", err)
+	}
+	if _, err := svc.SetPTR(ctx, user.ID, inst.ID, "198.51.100.7", ""); !errors.Is(err, domain.ErrIPAddressBlocked) {
+		t.Fatalf("expected blocked, got %v", err)
+	}
+	// Sync keeps the block in place.
+	if err := svc.SyncInstance(ctx, inst, appshared.AutomationHostInfo{RemoteIP: "198.51.100.7"}); err != nil {
+		t.Fatalf("resync: %v", err)
+	}
+	ip, _ = svc.Get(ctx, ip.ID)
+	if ip.Status != domain.IPAddressStatusBlocked {
+		t.Fatalf("expected blocked after sync, got %s", ip.Status)
+	}
+}
+
+func TestIPAddress_AdminPool(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	svc := appipaddress.NewService(repo, repo, nil)
+
+	ip, err := svc.Create(ctx, 1, appipaddress.CreateInput{Address: " 192.0.2.1 ", LineID: 5, Status: domain.IPAddressStatusReserved})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if ip.Address != "192.0.2.1" || ip.Version != 4 || ip.Status != domain.IPAddressStatusReserved {
+		t.Fatalf("unexpected ip: %+v", ip)
+	}
+	if _, err := svc.Create(ctx, 1, appipaddress.CreateInput{Address: "192.0.2.1"}); !errors.Is(err, domain.ErrIPAddressExists) {
+		t.Fatalf("expected exists, got %v", err)
+	}
+	if _, err := svc.Create(ctx, 1, appipaddress.CreateInput{Address: "192.0.2.300"}); !errors.Is(err, domain.ErrInvalidIPAddress) {
+		t.Fatalf("expected invalid address, got %v", err)
+	}
+	assigned := domain.IPAddressStatusAssigned
+	if _, err := svc.Update(ctx, 1, ip.ID, appipaddress.UpdateInput{Status: &assigned}); !errors.Is(err, appshared.ErrInvalidInput) {
+		t.Fatalf("expected free ip not assignable by hand, got %v", err)
+	}
+	available := domain.IPAddressStatusAvailable
+	if ip, err = svc.Update(ctx, 1, ip.ID, appipaddress.UpdateInput{Status: &available}); err != nil || ip.Status != available {
+		t.Fatalf("update: %+v %v", ip, err)
+	}
+	items, total, err := svc.List(ctx, appshared.IPAddressFilter{LineID: 5, Status: "available"}, 10, 0)
+	if err != nil || total != 1 || len(items) != 1 {
+		t.Fatalf("list: %v total=%d err=%v", items, total, err)
+	}
+	if err := svc.Delete(ctx, ip.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := svc.Get(ctx, ip.ID); !errors.Is(err, appshared.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
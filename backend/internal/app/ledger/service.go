@@ -0,0 +1,183 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+// AlertType is the notify channel message type sent when verification fails.
+const AlertType = "ledger.drift"
+
+const maxUnbalancedReported = 100
+
+type alerter interface {
+	Dispatch(ctx context.Context, msg appshared.NotifyMessage) error
+}
+
+type Service struct {
+	repo   appports.LedgerRepository
+	alerts alerter
+}
+
+func NewService(repo appports.LedgerRepository) *Service {
+	return &Service{repo: repo}
+}
+
+func (s *Service) SetAlerter(alerts alerter) {
+	s.alerts = alerts
+}
+
+// Post records a balanced entry. Posting an entry whose key was already
+// posted is a no-op.
+func (s *Service) Post(ctx context.Context, entry domain.LedgerEntry) error {
+	if s.repo == nil {
+		return appshared.ErrInvalidInput
+	}
+	entry.Key = strings.TrimSpace(entry.Key)
+	if entry.Key == "" || entry.Kind == "" || !entry.Balanced() {
+		return domain.ErrUnbalancedLedgerEntry
+	}
+	_, err := s.repo.CreateLedgerEntry(ctx, &entry)
+	return err
+}
+
+// InTx runs fn in one database transaction, so a balance change and the
+// entries posted for it are committed together or not at all.
+func (s *Service) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.repo == nil {
+		return appshared.ErrInvalidInput
+	}
+	return s.repo.InTx(ctx, fn)
+}
+
+func (s *Service) ListEntries(ctx context.Context, filter appshared.LedgerEntryFilter, limit, offset int) ([]domain.LedgerEntry, int, error) {
+	if s.repo == nil {
+		return nil, 0, appshared.ErrInvalidInput
+	}
+	return s.repo.ListLedgerEntries(ctx, filter, limit, offset)
+}
+
+func (s *Service) ListAccounts(ctx context.Context) ([]domain.LedgerAccountBalance, error) {
+	if s.repo == nil {
+		return nil, appshared.ErrInvalidInput
+	}
+	return s.repo.ListLedgerAccountBalances(ctx)
+}
+
+// Verify compares every wallet balance with its ledger account and checks
+// that all entries balance.
+func (s *Service) Verify(ctx context.Context) (domain.LedgerVerification, error) {
+	if s.repo == nil {
+		return domain.LedgerVerification{}, appshared.ErrInvalidInput
+	}
+	result := domain.LedgerVerification{CheckedAt: time.Now()}
+	wallets, err := s.repo.ListWalletBalances(ctx)
+	if err != nil {
+		return result, err
+	}
+	ledger, err := s.repo.LedgerWalletBalances(ctx)
+	if err != nil {
+		return result, err
+	}
+	result.Wallets = len(wallets)
+	for userID, balance := range wallets {
+		if ledger[userID] != balance {
+			result.Drifts = append(result.Drifts, domain.LedgerDrift{UserID: userID, WalletBalance: balance, LedgerBalance: ledger[userID]})
+		}
+	}
+	for userID, balance := range ledger {
+		if _, ok := wallets[userID]; !ok && balance != 0 {
+			result.Drifts = append(result.Drifts, domain.LedgerDrift{UserID: userID, LedgerBalance: balance})
+		}
+	}
+	sort.Slice(result.Drifts, func(i, j int) bool { return result.Drifts[i].UserID < result.Drifts[j].UserID })
+	result.UnbalancedEntries, err = s.repo.ListUnbalancedLedgerEntries(ctx, maxUnbalancedReported)
+	if err != nil {
+		return result, err
+	}
+	accounts, err := s.repo.ListLedgerAccountBalances(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, account := range accounts {
+		result.TotalDebit += account.Debit
+		result.TotalCredit += account.Credit
+	}
+	return result, nil
+}
+
+// VerifyAndAlert runs Verify for the scheduled task and dispatches a
+// ledger.drift notification when it finds problems.
+func (s *Service) VerifyAndAlert(ctx context.Context) (domain.LedgerVerification, error) {
+	result, err := s.Verify(ctx)
+	if err != nil || result.OK() {
+		return result, err
+	}
+	summary := fmt.Sprintf("%d wallets drift, %d unbalanced entries, debit %d / credit %d", len(result.Drifts), len(result.UnbalancedEntries), result.TotalDebit, result.TotalCredit)
+	if s.alerts != nil {
+		lines := []string{summary}
+		for i, drift := range result.Drifts {
+			if i == 20 {
+				lines = append(lines, "...")
+				break
+			}
+			lines = append(lines, fmt.Sprintf("user %d: wallet %d, ledger %d", drift.UserID, drift.WalletBalance, drift.LedgerBalance))
+		}
+		_ = s.alerts.Dispatch(ctx, appshared.NotifyMessage{
+			Type:    AlertType,
+			Title:   "Ledger verification failed",
+			Content: strings.Join(lines, "\n"),
+			Vars: map[string]string{
+				"drifts":     strconv.Itoa(len(result.Drifts)),
+				"unbalanced": strconv.Itoa(len(result.UnbalancedEntries)),
+			},
+			IdempotencyKey: "ledger:" + result.CheckedAt.Format("2006-01-02T15:04"),
+		})
+	}
+	return result, fmt.Errorf("%w: %s", domain.ErrLedgerDrift, summary)
+}
+
+// PostOpeningBalances books, once per wallet, the part of its balance that
+// predates the ledger against the opening balance account. It is meant to
+// run once after the ledger is introduced.
+func (s *Service) PostOpeningBalances(ctx context.Context) (int, error) {
+	if s.repo == nil {
+		return 0, appshared.ErrInvalidInput
+	}
+	wallets, err := s.repo.ListWalletBalances(ctx)
+	if err != nil {
+		return 0, err
+	}
+	ledger, err := s.repo.LedgerWalletBalances(ctx)
+	if err != nil {
+		return 0, err
+	}
+	posted := 0
+	for userID, balance := range wallets {
+		diff := balance - ledger[userID]
+		if diff == 0 {
+			continue
+		}
+		wallet := domain.LedgerWalletAccount(userID)
+		entry := domain.NewLedgerTransfer(domain.LedgerKindOpeningBalance, "wallet", userID, userID, domain.LedgerAccountOpening, wallet, diff, "opening balance")
+		if diff < 0 {
+			entry = domain.NewLedgerTransfer(domain.LedgerKindOpeningBalance, "wallet", userID, userID, wallet, domain.LedgerAccountOpening, -diff, "opening balance")
+		}
+		created, err := s.repo.CreateLedgerEntry(ctx, &entry)
+		if err != nil {
+			return posted, err
+		}
+		if created {
+			posted++
+		}
+	}
+	return posted, nil
+}
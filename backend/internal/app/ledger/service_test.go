@@ -0,0 +1,200 @@
+package ledger_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appledger "xiaoheiplay/internal/app/ledger"
+	appshared "xiaoheiplay/internal/app/shared"
+	appwallet "xiaoheiplay/internal/app/wallet"
+	appwalletorder "xiaoheiplay/internal/app/walletorder"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+type captureAlerter struct {
+	msgs []appshared.NotifyMessage
+}
+
+func (c *captureAlerter) Dispatch(ctx context.Context, msg appshared.NotifyMessage) error {
+	c.msgs = append(c.msgs, msg)
+	return nil
+}
+
+func TestLedger_WalletFlowsStayBalanced(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "ledger", "ledger@example.com", "pass")
+
+	ledger := appledger.NewService(repo)
+	wallets := appwallet.NewService(repo, repo)
+	wallets.SetLedger(ledger)
+	orders := appwalletorder.NewService(repo, repo, repo, repo, repo, nil, repo)
+	orders.SetWalletHolds(repo)
+	orders.SetLedger(ledger)
+
+	recharge, err := orders.CreateRecharge(ctx, user.ID, appshared.WalletOrderCreateInput{Amount: 10000, Meta: map[string]any{"payment_method": "alipay"}})
+	if err != nil {
+		t.Fatalf("create recharge: %v", err)
+	}
+	if _, _, err := orders.Approve(ctx, 1, recharge.ID); err != nil {
+		t.Fatalf("approve recharge: %v", err)
+	}
+	if _, err := wallets.AdjustBalance(ctx, 1, user.ID, -500, "fee correction"); err != nil {
+		t.Fatalf("adjust: %v", err)
+	}
+	withdraw, err := orders.CreateWithdraw(ctx, user.ID, appshared.WalletOrderCreateInput{Amount: 3000})
+	if err != nil {
+		t.Fatalf("create withdraw: %v", err)
+	}
+	if _, _, err := orders.Approve(ctx, 1, withdraw.ID); err != nil {
+		t.Fatalf("approve withdraw: %v", err)
+	}
+	approvedRefund, err := orders.CreateRefundOrder(ctx, user.ID, 2000, "refund", nil)
+	if err != nil {
+		t.Fatalf("create refund: %v", err)
+	}
+	if _, _, err := orders.Approve(ctx, 1, approvedRefund.ID); err != nil {
+		t.Fatalf("approve refund: %v", err)
+	}
+	rejectedRefund, err := orders.CreateRefundOrder(ctx, user.ID, 700, "refund", nil)
+	if err != nil {
+		t.Fatalf("create refund: %v", err)
+	}
+	if err := orders.Reject(ctx, 1, rejectedRefund.ID, "not eligible"); err != nil {
+		t.Fatalf("reject refund: %v", err)
+	}
+
+	result, err := ledger.Verify(ctx)
+	if err != nil || !result.OK() || result.Wallets != 1 {
+		t.Fatalf("expected clean verification, got %+v err=%v", result, err)
+	}
+	accounts, err := ledger.ListAccounts(ctx)
+	if err != nil {
+		t.Fatalf("list accounts: %v", err)
+	}
+	balances := map[string]int64{}
+	for _, account := range accounts {
+		balances[account.Account] = account.Balance()
+	}
+	// 100 recharged - 5 adjusted - 30 withdrawn + 20 refunded.
+	want := map[string]int64{
+		domain.LedgerWalletAccount(user.ID):   8500,
+		domain.LedgerGatewayAccount("alipay"): 10000,
+		domain.LedgerGatewayAccount(""):       -3000,
+		domain.LedgerAccountRevenue:           -2000,
+		domain.LedgerAccountRefundsPayable:    0,
+		domain.LedgerAccountAdjustments:       500,
+	}
+	for account, balance := range want {
+		if balances[account] != balance {
+			t.Fatalf("account %s: expected %d, got %d (all %+v)", account, balance, balances[account], balances)
+		}
+	}
+
+	// Approving again must not post twice.
+	entries, total, err := ledger.ListEntries(ctx, appshared.LedgerEntryFilter{RefType: "wallet_order", RefID: recharge.ID}, 10, 0)
+	if err != nil || total != 1 || len(entries[0].Lines) != 2 {
+		t.Fatalf("expected one recharge entry, got %+v total=%d err=%v", entries, total, err)
+	}
+	if err := ledger.Post(ctx, domain.NewLedgerTransfer(domain.LedgerKindRecharge, "wallet_order", recharge.ID, user.ID, domain.LedgerGatewayAccount("alipay"), domain.LedgerWalletAccount(user.ID), 10000, "retry")); err != nil {
+		t.Fatalf("repost: %v", err)
+	}
+	if _, total, _ := ledger.ListEntries(ctx, appshared.LedgerEntryFilter{Kind: domain.LedgerKindRecharge}, 10, 0); total != 1 {
+		t.Fatalf("expected idempotent post, got %d entries", total)
+	}
+	unbalanced := domain.LedgerEntry{Key: "bad", Kind: "test", Lines: []domain.LedgerLine{{Account: "revenue", Debit: 1}, {Account: "adjustments", Credit: 2}}}
+	if err := ledger.Post(ctx, unbalanced); !errors.Is(err, domain.ErrUnbalancedLedgerEntry) {
+		t.Fatalf("expected unbalanced entry error, got %v", err)
+	}
+}
+
+func TestLedger_VerifyDetectsDriftAndOpeningBalances(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	legacy := testutil.CreateUser(t, repo, "legacy", "legacy@example.com", "pass")
+	ledger := appledger.NewService(repo)
+	alerts := &captureAlerter{}
+	ledger.SetAlerter(alerts)
+
+	// A balance booked without the ledger, as before the upgrade.
+	if _, err := repo.AdjustWalletBalance(ctx, legacy.ID, 4200, "credit", "admin_adjust", 1, "legacy"); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	result, err := ledger.VerifyAndAlert(ctx)
+	if !errors.Is(err, domain.ErrLedgerDrift) {
+		t.Fatalf("expected drift error, got %v", err)
+	}
+	if len(result.Drifts) != 1 || result.Drifts[0].UserID != legacy.ID || result.Drifts[0].WalletBalance != 4200 || result.Drifts[0].LedgerBalance != 0 {
+		t.Fatalf("unexpected drifts: %+v", result.Drifts)
+	}
+	if len(alerts.msgs) != 1 || alerts.msgs[0].Type != appledger.AlertType {
+		t.Fatalf("expected one drift alert, got %+v", alerts.msgs)
+	}
+
+	posted, err := ledger.PostOpeningBalances(ctx)
+	if err != nil || posted != 1 {
+		t.Fatalf("expected one opening balance, got %d err=%v", posted, err)
+	}
+	if posted, _ := ledger.PostOpeningBalances(ctx); posted != 0 {
+		t.Fatalf("expected opening balances to be posted once, got %d", posted)
+	}
+	if _, err := ledger.VerifyAndAlert(ctx); err != nil {
+		t.Fatalf("expected clean ledger after opening balances, got %v", err)
+	}
+	if len(alerts.msgs) != 1 {
+		t.Fatalf("expected no alert for a clean run, got %d", len(alerts.msgs))
+	}
+}
+
+// failingPoster runs in the ledger's transaction but cannot post.
+type failingPoster struct {
+	*appledger.Service
+}
+
+func (failingPoster) Post(ctx context.Context, entry domain.LedgerEntry) error {
+	return errors.New("ledger unavailable")
+}
+
+func TestLedger_FailedPostRollsBackBalanceChange(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "atomic", "atomic@example.com", "pass")
+
+	ledger := appledger.NewService(repo)
+	broken := failingPoster{ledger}
+	wallets := appwallet.NewService(repo, repo)
+	wallets.SetLedger(broken)
+	orders := appwalletorder.NewService(repo, repo, repo, repo, repo, nil, repo)
+	orders.SetWalletHolds(repo)
+	orders.SetLedger(broken)
+
+	if _, err := wallets.AdjustBalance(ctx, 1, user.ID, 500, "bonus"); err == nil {
+		t.Fatalf("expected adjust to fail with the ledger")
+	}
+	recharge, err := orders.CreateRecharge(ctx, user.ID, appshared.WalletOrderCreateInput{Amount: 1000})
+	if err != nil {
+		t.Fatalf("create recharge: %v", err)
+	}
+	if _, _, err := orders.Approve(ctx, 1, recharge.ID); err == nil {
+		t.Fatalf("expected approve to fail with the ledger")
+	}
+	wallet, err := repo.GetWallet(ctx, user.ID)
+	if err == nil && wallet.Balance != 0 {
+		t.Fatalf("balance changed without ledger entries: %+v", wallet)
+	}
+	got, err := repo.GetWalletOrder(ctx, recharge.ID)
+	if err != nil || got.Status != domain.WalletOrderPendingReview {
+		t.Fatalf("expected recharge still pending, got %+v err=%v", got, err)
+	}
+
+	orders.SetLedger(ledger)
+	if _, _, err := orders.Approve(ctx, 1, recharge.ID); err != nil {
+		t.Fatalf("retry approve: %v", err)
+	}
+	result, err := ledger.Verify(ctx)
+	if err != nil || !result.OK() {
+		t.Fatalf("expected clean verification after retry, got %+v err=%v", result, err)
+	}
+}
@@ -2,6 +2,9 @@ package message
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -10,15 +13,46 @@ import (
 	"xiaoheiplay/internal/domain"
 )
 
+// Service is where user notifications are sent. Every channel it sends on
+// checks the user's notification preferences first, so callers do not.
 type Service struct {
-	repo  appports.NotificationRepository
-	users appports.UserRepository
+	repo     appports.NotificationRepository
+	users    appports.UserRepository
+	prefs    appports.NotificationPreferenceGate
+	email    appports.EmailSender
+	sms      appports.SMSSender
+	settings appports.SettingsRepository
+	deferred appports.DeferredNotificationRepository
 }
 
+// deferredPerRun caps how many held SMS SendDeferred sends per call.
+const deferredPerRun = 200
+
 func NewService(repo appports.NotificationRepository, users appports.UserRepository) *Service {
 	return &Service{repo: repo, users: users}
 }
 
+func (s *Service) SetNotificationPreferences(prefs appports.NotificationPreferenceGate) {
+	s.prefs = prefs
+}
+
+func (s *Service) SetEmailSender(email appports.EmailSender) {
+	s.email = email
+}
+
+// SetSMSSender enables SendSMS; the plugin and template come from the SMS
+// settings used for security messages.
+func (s *Service) SetSMSSender(sms appports.SMSSender, settings appports.SettingsRepository) {
+	s.sms = sms
+	s.settings = settings
+}
+
+// SetDeferredQueue stores SMS that arrive during the user's quiet hours so
+// SendDeferred can send them when the window ends.
+func (s *Service) SetDeferredQueue(deferred appports.DeferredNotificationRepository) {
+	s.deferred = deferred
+}
+
 func (s *Service) List(ctx context.Context, userID int64, status string, limit, offset int) ([]domain.Notification, int, error) {
 	filter := appshared.NotificationFilter{UserID: &userID, Status: strings.TrimSpace(status), Limit: limit, Offset: offset}
 	return s.repo.ListNotifications(ctx, filter)
@@ -36,9 +70,23 @@ func (s *Service) MarkAllRead(ctx context.Context, userID int64) error {
 	return s.repo.MarkAllRead(ctx, userID)
 }
 
+// NotifyUser sends an in-app message; a muted message is not an error.
 func (s *Service) NotifyUser(ctx context.Context, userID int64, typ, title, content string) error {
+	_, err := s.SendInApp(ctx, userID, typ, title, content)
+	if errors.Is(err, domain.ErrNotificationMuted) {
+		return nil
+	}
+	return err
+}
+
+// SendInApp creates an in-app message and returns it, or
+// domain.ErrNotificationMuted when the user muted typ in-app.
+func (s *Service) SendInApp(ctx context.Context, userID int64, typ, title, content string) (domain.Notification, error) {
 	if userID == 0 {
-		return appshared.ErrInvalidInput
+		return domain.Notification{}, appshared.ErrInvalidInput
+	}
+	if !s.allow(ctx, userID, typ, domain.NotificationChannelInApp) {
+		return domain.Notification{}, domain.ErrNotificationMuted
 	}
 	title = strings.TrimSpace(title)
 	if title == "" {
@@ -51,7 +99,134 @@ func (s *Service) NotifyUser(ctx context.Context, userID int64, typ, title, cont
 		Content:   strings.TrimSpace(content),
 		CreatedAt: time.Now(),
 	}
-	return s.repo.CreateNotification(ctx, &notification)
+	if err := s.repo.CreateNotification(ctx, &notification); err != nil {
+		return domain.Notification{}, err
+	}
+	return notification, nil
+}
+
+// SendEmail mails the user at their bound address, or returns
+// domain.ErrNotificationMuted when the user muted typ by email.
+func (s *Service) SendEmail(ctx context.Context, userID int64, typ, subject, body string) error {
+	if s.email == nil {
+		return domain.ErrEmailSenderNotConfigured
+	}
+	if !s.allow(ctx, userID, typ, domain.NotificationChannelEmail) {
+		return domain.ErrNotificationMuted
+	}
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	addr := strings.TrimSpace(user.Email)
+	if addr == "" {
+		return domain.ErrEmailNotBound
+	}
+	return s.email.Send(ctx, addr, subject, body)
+}
+
+// SendSMS texts the user's bound phone through the configured SMS plugin, or
+// returns domain.ErrNotificationMuted when the user muted typ by SMS. During
+// the user's quiet hours the message is queued and nil is returned; without
+// a queue it is reported as muted.
+func (s *Service) SendSMS(ctx context.Context, userID int64, typ string, msg appshared.SMSMessage) error {
+	if s.sms == nil {
+		return domain.ErrSMSPluginManagerUnavailable
+	}
+	if !s.allow(ctx, userID, typ, domain.NotificationChannelSMS) {
+		return domain.ErrNotificationMuted
+	}
+	if until, quiet := s.quietUntil(ctx, userID, typ); quiet {
+		if s.deferred == nil {
+			return domain.ErrNotificationMuted
+		}
+		raw, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		return s.deferred.CreateDeferredNotification(ctx, &domain.DeferredNotification{
+			UserID:      userID,
+			Channel:     domain.NotificationChannelSMS,
+			Type:        typ,
+			PayloadJSON: string(raw),
+			SendAfter:   until,
+		})
+	}
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	phone := strings.TrimSpace(user.Phone)
+	if phone == "" {
+		return domain.ErrPhoneNotBound
+	}
+	pluginID := s.settingValue(ctx, "sms_plugin_id")
+	if pluginID == "" {
+		return domain.ErrSMSPluginNotConfigured
+	}
+	instanceID := s.settingValue(ctx, "sms_instance_id")
+	if instanceID == "" {
+		instanceID = "default"
+	}
+	if msg.TemplateID == "" {
+		msg.TemplateID = s.settingValue(ctx, "sms_provider_template_id")
+	}
+	msg.Phones = []string{phone}
+	_, err = s.sms.Send(ctx, pluginID, instanceID, msg)
+	return err
+}
+
+func (s *Service) allow(ctx context.Context, userID int64, typ, channel string) bool {
+	return s.prefs == nil || s.prefs.Allow(ctx, userID, typ, channel)
+}
+
+func (s *Service) quietUntil(ctx context.Context, userID int64, typ string) (time.Time, bool) {
+	if s.prefs == nil {
+		return time.Time{}, false
+	}
+	return s.prefs.QuietUntil(ctx, userID, typ, domain.NotificationChannelSMS)
+}
+
+// SendDeferred sends the SMS held by quiet hours whose window has ended and
+// returns how many were sent. Each message goes through SendSMS again, so a
+// user who has since muted the type does not get it. Delivered or not, the
+// queued row is removed.
+func (s *Service) SendDeferred(ctx context.Context) (int, error) {
+	if s.deferred == nil {
+		return 0, nil
+	}
+	items, err := s.deferred.ListDueDeferredNotifications(ctx, domain.NotificationChannelSMS, time.Now(), deferredPerRun)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	var errs []error
+	for _, item := range items {
+		var msg appshared.SMSMessage
+		if err := json.Unmarshal([]byte(item.PayloadJSON), &msg); err == nil {
+			switch err := s.SendSMS(ctx, item.UserID, item.Type, msg); {
+			case err == nil:
+				sent++
+			case !errors.Is(err, domain.ErrNotificationMuted):
+				errs = append(errs, fmt.Errorf("deferred sms %d: %w", item.ID, err))
+			}
+		}
+		if err := s.deferred.DeleteDeferredNotification(ctx, item.ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (s *Service) settingValue(ctx context.Context, key string) string {
+	if s.settings == nil {
+		return ""
+	}
+	setting, err := s.settings.GetSetting(ctx, key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(setting.ValueJSON)
 }
 
 func (s *Service) NotifyUsers(ctx context.Context, userIDs []int64, typ, title, content string) error {
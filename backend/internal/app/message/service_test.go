@@ -2,8 +2,13 @@ package message_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 	appmessage "xiaoheiplay/internal/app/message"
+	appnotifypref "xiaoheiplay/internal/app/notifypref"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
 	"xiaoheiplay/internal/testutil"
 )
 
@@ -27,3 +32,101 @@ func TestMessageCenterService_NotifyAndRead(t *testing.T) {
 		t.Fatalf("mark read: %v", err)
 	}
 }
+
+func TestMessageCenterService_PreferencesGateEveryChannel(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "m2", "m2@example.com", "pass")
+	email := &testutil.FakeEmailSender{}
+	prefs := appnotifypref.NewService(repo)
+	svc := appmessage.NewService(repo, repo)
+	svc.SetEmailSender(email)
+	svc.SetNotificationPreferences(prefs)
+	if _, err := prefs.Update(ctx, user.ID, domain.NotificationPreferences{
+		Disabled: map[string][]string{domain.NotificationCategoryOrder: {domain.NotificationChannelInApp, domain.NotificationChannelEmail}},
+	}); err != nil {
+		t.Fatalf("update prefs: %v", err)
+	}
+
+	if err := svc.SendEmail(ctx, user.ID, "order_approved", "approved", "body"); !errors.Is(err, domain.ErrNotificationMuted) {
+		t.Fatalf("expected muted email, got %v", err)
+	}
+	if _, err := svc.SendInApp(ctx, user.ID, "order_approved", "approved", "body"); !errors.Is(err, domain.ErrNotificationMuted) {
+		t.Fatalf("expected muted in-app, got %v", err)
+	}
+	if err := svc.NotifyUser(ctx, user.ID, "order_approved", "approved", "body"); err != nil {
+		t.Fatalf("muted notify should not fail: %v", err)
+	}
+	if err := svc.SendEmail(ctx, user.ID, "security", "login", "body"); err != nil {
+		t.Fatalf("send security email: %v", err)
+	}
+	if len(email.Sends) != 1 || email.Sends[0].To != "m2@example.com" {
+		t.Fatalf("expected only the security email, got %+v", email.Sends)
+	}
+	if count, _ := svc.UnreadCount(ctx, user.ID); count != 0 {
+		t.Fatalf("expected no in-app messages, got %d", count)
+	}
+}
+
+type fakeSMSSender struct {
+	sent []appshared.SMSMessage
+}
+
+func (f *fakeSMSSender) Send(_ context.Context, _, _ string, msg appshared.SMSMessage) (appshared.SMSDelivery, error) {
+	f.sent = append(f.sent, msg)
+	return appshared.SMSDelivery{}, nil
+}
+
+func TestMessageCenterService_QuietHoursQueueSMS(t *testing.T) {
+	db, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "m3", "m3@example.com", "pass")
+	if _, err := db.Exec("UPDATE users SET phone = ? WHERE id = ?", "13800000000", user.ID); err != nil {
+		t.Fatalf("set phone: %v", err)
+	}
+	if err := repo.UpsertSetting(ctx, domain.Setting{Key: "sms_plugin_id", ValueJSON: "sms-demo"}); err != nil {
+		t.Fatalf("setting: %v", err)
+	}
+	sms := &fakeSMSSender{}
+	prefs := appnotifypref.NewService(repo)
+	svc := appmessage.NewService(repo, repo)
+	svc.SetSMSSender(sms, repo)
+	svc.SetNotificationPreferences(prefs)
+	svc.SetDeferredQueue(repo)
+
+	now := time.Now().UTC()
+	if _, err := prefs.Update(ctx, user.ID, domain.NotificationPreferences{
+		QuietHoursEnabled: true,
+		QuietStart:        now.Add(-time.Hour).Format("15:04"),
+		QuietEnd:          now.Add(time.Hour).Format("15:04"),
+		Timezone:          "UTC",
+	}); err != nil {
+		t.Fatalf("update prefs: %v", err)
+	}
+	if err := svc.SendSMS(ctx, user.ID, "expire", appshared.SMSMessage{Content: "renew soon"}); err != nil {
+		t.Fatalf("quiet sms should be queued, got %v", err)
+	}
+	if len(sms.sent) != 0 {
+		t.Fatalf("expected nothing sent during quiet hours, got %+v", sms.sent)
+	}
+	if n, err := svc.SendDeferred(ctx); err != nil || n != 0 {
+		t.Fatalf("expected nothing due yet, got %d %v", n, err)
+	}
+
+	// The window ends and quiet hours are off again.
+	if _, err := prefs.Update(ctx, user.ID, domain.NotificationPreferences{}); err != nil {
+		t.Fatalf("update prefs: %v", err)
+	}
+	if _, err := db.Exec("UPDATE deferred_notifications SET send_after = ?", now.Add(-time.Minute)); err != nil {
+		t.Fatalf("age queue: %v", err)
+	}
+	if n, err := svc.SendDeferred(ctx); err != nil || n != 1 {
+		t.Fatalf("expected the held sms sent, got %d %v", n, err)
+	}
+	if len(sms.sent) != 1 || sms.sent[0].Content != "renew soon" || sms.sent[0].Phones[0] != "13800000000" {
+		t.Fatalf("unexpected sms: %+v", sms.sent)
+	}
+	if n, err := svc.SendDeferred(ctx); err != nil || n != 0 {
+		t.Fatalf("expected the queue drained, got %d %v", n, err)
+	}
+}
@@ -0,0 +1,393 @@
+package metrics
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appscheduledtask "xiaoheiplay/internal/app/scheduledtask"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+	pkgmetrics "xiaoheiplay/internal/pkg/metrics"
+)
+
+const (
+	settingEnabled     = "metrics_enabled"
+	settingAPIKey      = "metrics_api_key"
+	settingIPAllowlist = "metrics_ip_allowlist"
+	settingProbeHostOn = "metrics_probe_host_enabled"
+	probeListPageSize  = 200
+	pluginHealthOK     = "HEALTH_STATUS_OK"
+)
+
+// provisionQueueStatuses mirror the statuses ListDueProvisionJobs picks up.
+var provisionQueueStatuses = map[string]bool{"pending": true, "retry": true, "running": true}
+
+type taskLister interface {
+	ListTasks(ctx context.Context) ([]appscheduledtask.ScheduledTaskConfig, error)
+}
+
+type pluginLister interface {
+	List(ctx context.Context) ([]appshared.PluginListItem, error)
+}
+
+type subscriberCounter interface {
+	SubscriberCount() int
+}
+
+type probeConnCounter interface {
+	OnlineCount() int
+}
+
+type sseSource struct {
+	stream string
+	src    subscriberCounter
+}
+
+// Service renders backend state as OpenMetrics gauges for the /metrics endpoint.
+type Service struct {
+	settings  appports.SettingsRepository
+	provision appports.ProvisionJobRepository
+	wallets   appports.WalletStatsRepository
+	probes    appports.ProbeNodeRepository
+	tasks     taskLister
+	plugins   pluginLister
+	probeHub  probeConnCounter
+	sse       []sseSource
+}
+
+func NewService(settings appports.SettingsRepository, provision appports.ProvisionJobRepository, wallets appports.WalletStatsRepository, probes appports.ProbeNodeRepository) *Service {
+	return &Service{settings: settings, provision: provision, wallets: wallets, probes: probes}
+}
+
+func (s *Service) SetTaskLister(tasks taskLister) {
+	s.tasks = tasks
+}
+
+func (s *Service) SetPluginLister(plugins pluginLister) {
+	s.plugins = plugins
+}
+
+func (s *Service) SetProbeHub(hub probeConnCounter) {
+	s.probeHub = hub
+}
+
+func (s *Service) AddSSESource(stream string, src subscriberCounter) {
+	if src == nil {
+		return
+	}
+	s.sse = append(s.sse, sseSource{stream: stream, src: src})
+}
+
+// Authorize accepts a request carrying the configured API key or coming from an
+// allowlisted IP/CIDR. With neither configured the endpoint stays closed.
+func (s *Service) Authorize(ctx context.Context, apiKey, clientIP string) error {
+	if !s.boolSetting(ctx, settingEnabled) {
+		return domain.ErrMetricsDisabled
+	}
+	if want := s.stringSetting(ctx, settingAPIKey); want != "" && apiKey != "" {
+		if subtle.ConstantTimeCompare([]byte(want), []byte(strings.TrimSpace(apiKey))) == 1 {
+			return nil
+		}
+		return domain.ErrUnauthorized
+	}
+	if ipAllowed(s.stringSetting(ctx, settingIPAllowlist), clientIP) {
+		return nil
+	}
+	return domain.ErrForbidden
+}
+
+// Collect writes every backend metric family except HTTP traffic, which the
+// HTTP adapter records in-process.
+func (s *Service) Collect(ctx context.Context, w *pkgmetrics.Writer) {
+	s.collectProvisionJobs(ctx, w)
+	s.collectTasks(ctx, w)
+	s.collectPlugins(ctx, w)
+	s.collectProbes(ctx, w)
+	s.collectSSE(w)
+	s.collectWallets(ctx, w)
+}
+
+func (s *Service) collectProvisionJobs(ctx context.Context, w *pkgmetrics.Writer) {
+	if s.provision == nil {
+		return
+	}
+	stats, err := s.provision.ProvisionJobStats(ctx)
+	if err != nil {
+		return
+	}
+	var depth int64
+	for _, st := range stats {
+		if provisionQueueStatuses[st.Status] {
+			depth += st.Count
+		}
+	}
+	w.Family("provision_queue_depth", "gauge", "Provision jobs waiting or running (pending, retry, running).")
+	w.Sample("provision_queue_depth", float64(depth))
+	w.Family("provision_jobs", "gauge", "Provision jobs by status.")
+	for _, st := range stats {
+		w.Sample("provision_jobs", float64(st.Count), pkgmetrics.L("status", st.Status))
+	}
+	w.Family("provision_job_attempts", "gauge", "Sum of attempts across provision jobs by status.")
+	for _, st := range stats {
+		w.Sample("provision_job_attempts", float64(st.Attempts), pkgmetrics.L("status", st.Status))
+	}
+	w.Family("provision_job_attempts_max", "gauge", "Highest attempt count of any provision job by status.")
+	for _, st := range stats {
+		w.Sample("provision_job_attempts_max", float64(st.MaxAttempts), pkgmetrics.L("status", st.Status))
+	}
+}
+
+func (s *Service) collectTasks(ctx context.Context, w *pkgmetrics.Writer) {
+	if s.tasks == nil {
+		return
+	}
+	tasks, err := s.tasks.ListTasks(ctx)
+	if err != nil {
+		return
+	}
+	w.Family("scheduled_task_enabled", "gauge", "Whether the scheduled task is enabled.")
+	for _, t := range tasks {
+		w.Sample("scheduled_task_enabled", pkgmetrics.Bool(t.Enabled), pkgmetrics.L("task", t.Key))
+	}
+	w.Family("scheduled_task_running", "gauge", "Whether the scheduled task is running right now.")
+	for _, t := range tasks {
+		w.Sample("scheduled_task_running", pkgmetrics.Bool(t.Running), pkgmetrics.L("task", t.Key))
+	}
+	w.Family("scheduled_task_last_success", "gauge", "1 if the last run succeeded, 0 if it failed; absent before the first run.")
+	for _, t := range tasks {
+		if t.LastStatus == "" {
+			continue
+		}
+		w.Sample("scheduled_task_last_success", pkgmetrics.Bool(t.LastStatus == "success"), pkgmetrics.L("task", t.Key))
+	}
+	w.Family("scheduled_task_last_duration_seconds", "gauge", "Duration of the last completed run.")
+	for _, t := range tasks {
+		if t.LastStatus == "" {
+			continue
+		}
+		w.Sample("scheduled_task_last_duration_seconds", t.LastDuration.Seconds(), pkgmetrics.L("task", t.Key))
+	}
+	w.Family("scheduled_task_last_run_timestamp_seconds", "gauge", "Unix time of the last run.")
+	for _, t := range tasks {
+		if t.LastRunAt == nil {
+			continue
+		}
+		w.Sample("scheduled_task_last_run_timestamp_seconds", float64(t.LastRunAt.Unix()), pkgmetrics.L("task", t.Key))
+	}
+}
+
+func (s *Service) collectPlugins(ctx context.Context, w *pkgmetrics.Writer) {
+	if s.plugins == nil {
+		return
+	}
+	items, err := s.plugins.List(ctx)
+	if err != nil {
+		return
+	}
+	labels := func(it appshared.PluginListItem) []pkgmetrics.Label {
+		return []pkgmetrics.Label{
+			pkgmetrics.L("category", it.Category),
+			pkgmetrics.L("plugin", it.PluginID),
+			pkgmetrics.L("instance", it.InstanceID),
+		}
+	}
+	w.Family("plugin_loaded", "gauge", "Whether the plugin instance process is running.")
+	for _, it := range items {
+		w.Sample("plugin_loaded", pkgmetrics.Bool(it.Loaded), labels(it)...)
+	}
+	w.Family("plugin_healthy", "gauge", "1 if the last heartbeat Health call reported OK.")
+	for _, it := range items {
+		if !it.Enabled {
+			continue
+		}
+		w.Sample("plugin_healthy", pkgmetrics.Bool(it.Loaded && it.HealthStatus == pluginHealthOK), labels(it)...)
+	}
+	w.Family("plugin_last_health_timestamp_seconds", "gauge", "Unix time of the last successful heartbeat Health call.")
+	for _, it := range items {
+		if it.LastHealthAt == nil {
+			continue
+		}
+		w.Sample("plugin_last_health_timestamp_seconds", float64(it.LastHealthAt.Unix()), labels(it)...)
+	}
+}
+
+func (s *Service) collectProbes(ctx context.Context, w *pkgmetrics.Writer) {
+	if s.probeHub != nil {
+		w.Family("probe_connections", "gauge", "Probes with an open websocket on this backend instance.")
+		w.Sample("probe_connections", float64(s.probeHub.OnlineCount()))
+	}
+	if s.probes == nil {
+		return
+	}
+	nodes := make([]domain.ProbeNode, 0, probeListPageSize)
+	for offset := 0; ; offset += probeListPageSize {
+		page, total, err := s.probes.ListProbeNodes(ctx, appshared.ProbeNodeFilter{}, probeListPageSize, offset)
+		if err != nil {
+			return
+		}
+		nodes = append(nodes, page...)
+		if len(page) < probeListPageSize || len(nodes) >= total {
+			break
+		}
+	}
+	byStatus := map[string]int{string(domain.ProbeStatusOnline): 0, string(domain.ProbeStatusOffline): 0}
+	for _, node := range nodes {
+		byStatus[string(node.Status)]++
+	}
+	statuses := make([]string, 0, len(byStatus))
+	for status := range byStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	w.Family("probe_nodes", "gauge", "Registered probes by status.")
+	for _, status := range statuses {
+		w.Sample("probe_nodes", float64(byStatus[status]), pkgmetrics.L("status", status))
+	}
+	if s.boolSetting(ctx, settingProbeHostOn) {
+		writeProbeHostMetrics(w, nodes)
+	}
+}
+
+func (s *Service) collectSSE(w *pkgmetrics.Writer) {
+	if len(s.sse) == 0 {
+		return
+	}
+	w.Family("sse_subscribers", "gauge", "Open server-sent event subscriptions by stream.")
+	for _, src := range s.sse {
+		w.Sample("sse_subscribers", float64(src.src.SubscriberCount()), pkgmetrics.L("stream", src.stream))
+	}
+}
+
+func (s *Service) collectWallets(ctx context.Context, w *pkgmetrics.Writer) {
+	if s.wallets == nil {
+		return
+	}
+	totals, err := s.wallets.WalletTotals(ctx)
+	if err != nil {
+		return
+	}
+	w.Family("wallets", "gauge", "Number of user wallets.")
+	w.Sample("wallets", float64(totals.Wallets))
+	w.Family("wallet_balance_cents", "gauge", "Sum of all wallet balances in cents.")
+	w.Sample("wallet_balance_cents", float64(totals.Balance))
+	w.Family("wallets_negative", "gauge", "Wallets with a negative balance.")
+	w.Sample("wallets_negative", float64(totals.NegativeCount))
+}
+
+type probeHostSnapshot struct {
+	CPU struct {
+		UsagePercent float64 `json:"usage_percent"`
+	} `json:"cpu"`
+	Memory struct {
+		Total        float64 `json:"total"`
+		Used         float64 `json:"used"`
+		UsagePercent float64 `json:"usage_percent"`
+	} `json:"memory"`
+	Disks []struct {
+		Mount        string  `json:"mount"`
+		Total        float64 `json:"total"`
+		Used         float64 `json:"used"`
+		UsagePercent float64 `json:"usage_percent"`
+	} `json:"disks"`
+}
+
+type probeHostSample struct {
+	labels   []pkgmetrics.Label
+	at       *time.Time
+	snapshot probeHostSnapshot
+}
+
+// writeProbeHostMetrics re-exports the latest pingbot snapshot of each probe.
+func writeProbeHostMetrics(w *pkgmetrics.Writer, nodes []domain.ProbeNode) {
+	samples := make([]probeHostSample, 0, len(nodes))
+	for _, node := range nodes {
+		raw := strings.TrimSpace(node.LastSnapshotJSON)
+		if raw == "" || raw == "{}" {
+			continue
+		}
+		var snap probeHostSnapshot
+		if err := json.Unmarshal([]byte(raw), &snap); err != nil {
+			continue
+		}
+		samples = append(samples, probeHostSample{
+			labels:   []pkgmetrics.Label{pkgmetrics.L("probe_id", strconv.FormatInt(node.ID, 10)), pkgmetrics.L("probe", node.Name)},
+			at:       node.LastSnapshotAt,
+			snapshot: snap,
+		})
+	}
+	if len(samples) == 0 {
+		return
+	}
+	w.Family("probe_host_snapshot_timestamp_seconds", "gauge", "Unix time of the probe snapshot the host metrics come from.")
+	for _, p := range samples {
+		if p.at != nil {
+			w.Sample("probe_host_snapshot_timestamp_seconds", float64(p.at.Unix()), p.labels...)
+		}
+	}
+	w.Family("probe_host_cpu_usage_percent", "gauge", "CPU usage reported by the probe.")
+	for _, p := range samples {
+		w.Sample("probe_host_cpu_usage_percent", p.snapshot.CPU.UsagePercent, p.labels...)
+	}
+	w.Family("probe_host_memory_usage_percent", "gauge", "Memory usage reported by the probe.")
+	for _, p := range samples {
+		w.Sample("probe_host_memory_usage_percent", p.snapshot.Memory.UsagePercent, p.labels...)
+	}
+	w.Family("probe_host_memory_bytes", "gauge", "Memory total and used bytes reported by the probe.")
+	for _, p := range samples {
+		w.Sample("probe_host_memory_bytes", p.snapshot.Memory.Total, append(p.labels, pkgmetrics.L("kind", "total"))...)
+		w.Sample("probe_host_memory_bytes", p.snapshot.Memory.Used, append(p.labels, pkgmetrics.L("kind", "used"))...)
+	}
+	w.Family("probe_host_disk_usage_percent", "gauge", "Disk usage per mount reported by the probe.")
+	for _, p := range samples {
+		for _, d := range p.snapshot.Disks {
+			w.Sample("probe_host_disk_usage_percent", d.UsagePercent, append(p.labels, pkgmetrics.L("mount", d.Mount))...)
+		}
+	}
+}
+
+func ipAllowed(allowlist, clientIP string) bool {
+	ip := net.ParseIP(strings.TrimSpace(clientIP))
+	if ip == nil {
+		return false
+	}
+	for _, entry := range strings.FieldsFunc(allowlist, func(r rune) bool {
+		return r == ',' || r == '\n' || r == ' ' || r == ';'
+	}) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			if _, cidr, err := net.ParseCIDR(entry); err == nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) stringSetting(ctx context.Context, key string) string {
+	if s.settings == nil {
+		return ""
+	}
+	item, err := s.settings.GetSetting(ctx, key)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(item.ValueJSON)
+}
+
+func (s *Service) boolSetting(ctx context.Context, key string) bool {
+	return strings.EqualFold(s.stringSetting(ctx, key), "true")
+}
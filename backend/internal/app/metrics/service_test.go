@@ -0,0 +1,75 @@
+package metrics_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	appmetrics "xiaoheiplay/internal/app/metrics"
+	"xiaoheiplay/internal/domain"
+	pkgmetrics "xiaoheiplay/internal/pkg/metrics"
+	"xiaoheiplay/internal/testutil"
+)
+
+func TestAuthorize(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	svc := appmetrics.NewService(repo, repo, repo, repo)
+
+	if err := svc.Authorize(ctx, "", "127.0.0.1"); !errors.Is(err, domain.ErrMetricsDisabled) {
+		t.Fatalf("expected disabled, got %v", err)
+	}
+	_ = repo.UpsertSetting(ctx, domain.Setting{Key: "metrics_enabled", ValueJSON: "true"})
+	if err := svc.Authorize(ctx, "", "127.0.0.1"); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("expected closed by default, got %v", err)
+	}
+	_ = repo.UpsertSetting(ctx, domain.Setting{Key: "metrics_api_key", ValueJSON: "scrape-key"})
+	_ = repo.UpsertSetting(ctx, domain.Setting{Key: "metrics_ip_allowlist", ValueJSON: "10.0.0.0/8, 192.168.1.5"})
+	cases := []struct {
+		key, ip string
+		want    error
+	}{
+		{"scrape-key", "8.8.8.8", nil},
+		{"wrong", "10.1.2.3", domain.ErrUnauthorized},
+		{"", "10.1.2.3", nil},
+		{"", "192.168.1.5", nil},
+		{"", "192.168.1.6", domain.ErrForbidden},
+	}
+	for _, tc := range cases {
+		err := svc.Authorize(ctx, tc.key, tc.ip)
+		if (tc.want == nil && err != nil) || (tc.want != nil && !errors.Is(err, tc.want)) {
+			t.Fatalf("key=%q ip=%q: expected %v, got %v", tc.key, tc.ip, tc.want, err)
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	for i, status := range []string{"pending", "retry", "failed"} {
+		job := domain.ProvisionJob{OrderID: int64(i + 1), OrderItemID: int64(i + 1), HostID: int64(i + 1), Status: status, Attempts: i + 1}
+		if err := repo.CreateOrUpdateProvisionJob(ctx, &job); err != nil {
+			t.Fatalf("create job: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	w := pkgmetrics.NewWriter(&buf)
+	appmetrics.NewService(repo, repo, repo, repo).Collect(ctx, w)
+	if err := w.EOF(); err != nil {
+		t.Fatalf("eof: %v", err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"xiaoheiplay_provision_queue_depth 2",
+		`xiaoheiplay_provision_jobs{status="failed"} 1`,
+		`xiaoheiplay_provision_job_attempts_max{status="failed"} 3`,
+		"xiaoheiplay_wallets_negative 0",
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Fatalf("missing %q in:\n%s", line, out)
+		}
+	}
+}
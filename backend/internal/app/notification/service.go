@@ -10,36 +10,47 @@ import (
 	appshared "xiaoheiplay/internal/app/shared"
 )
 
+// messageCenter is the slice of message.Service reminders go through; it
+// checks the user's notification preferences on each channel.
 type messageCenter interface {
 	NotifyUser(ctx context.Context, userID int64, typ, title, content string) error
+	SendEmail(ctx context.Context, userID int64, typ, subject, body string) error
+}
+
+// dunningScope reports goods types whose reminders come from a dunning
+// policy instead of the expire reminder settings.
+type dunningScope interface {
+	Manages(ctx context.Context, goodsTypeID int64) bool
 }
 
 type Service struct {
 	settings appports.SettingsRepository
 	vps      appports.VPSRepository
 	users    appports.UserRepository
-	email    appports.EmailSender
 	messages messageCenter
+	dunning  dunningScope
 }
 
 func NewService(
 	settings appports.SettingsRepository,
 	vps appports.VPSRepository,
 	users appports.UserRepository,
-	email appports.EmailSender,
 	messages messageCenter,
 ) *Service {
 	return &Service{
 		settings: settings,
 		vps:      vps,
 		users:    users,
-		email:    email,
 		messages: messages,
 	}
 }
 
+func (s *Service) SetDunningService(dunning dunningScope) {
+	s.dunning = dunning
+}
+
 func (s *Service) SendExpireReminders(ctx context.Context) error {
-	if s.email == nil {
+	if s.messages == nil {
 		return nil
 	}
 	enabled, err := s.settings.GetSetting(ctx, "email_expire_enabled")
@@ -69,6 +80,9 @@ func (s *Service) SendExpireReminders(ctx context.Context) error {
 		}
 	}
 	for _, inst := range instances {
+		if s.dunning != nil && s.dunning.Manages(ctx, inst.GoodsTypeID) {
+			continue
+		}
 		user, userErr := s.users.GetUserByID(ctx, inst.UserID)
 		if userErr != nil || user.Email == "" || inst.ExpireAt == nil {
 			continue
@@ -87,10 +101,8 @@ func (s *Service) SendExpireReminders(ctx context.Context) error {
 		}
 		renderedSubject := appshared.RenderTemplate(subject, data, false)
 		renderedBody := appshared.RenderTemplate(body, data, appshared.IsHTMLContent(body))
-		_ = s.email.Send(ctx, user.Email, renderedSubject, renderedBody)
-		if s.messages != nil {
-			_ = s.messages.NotifyUser(ctx, user.ID, "expire", "VPS Expiration Reminder", "Your VPS "+inst.Name+" will expire on "+inst.ExpireAt.Format("2006-01-02"))
-		}
+		_ = s.messages.SendEmail(ctx, user.ID, "expire", renderedSubject, renderedBody)
+		_ = s.messages.NotifyUser(ctx, user.ID, "expire", "VPS Expiration Reminder", "Your VPS "+inst.Name+" will expire on "+inst.ExpireAt.Format("2006-01-02"))
 	}
 	return nil
 }
@@ -14,7 +14,8 @@ func TestNotificationService_SendExpireReminders(t *testing.T) {
 	_, repo := testutil.NewTestDB(t, false)
 	email := &testutil.FakeEmailSender{}
 	msg := appmessage.NewService(repo, repo)
-	svc := appnotification.NewService(repo, repo, repo, email, msg)
+	msg.SetEmailSender(email)
+	svc := appnotification.NewService(repo, repo, repo, msg)
 
 	user := testutil.CreateUser(t, repo, "n1", "n1@example.com", "pass")
 	order := domain.Order{UserID: user.ID, OrderNo: "ORD-NOTIFY-1", Status: domain.OrderStatusApproved, TotalAmount: 1000, Currency: "CNY"}
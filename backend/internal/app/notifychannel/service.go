@@ -0,0 +1,280 @@
+package notifychannel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+const (
+	settingRoutes       = "notify_channel_routes"
+	settingEmailChannel = "notify_email_channel"
+
+	TypeEmail = "email"
+)
+
+// KnownTypes lists the notification types that are dispatched to channels.
+var KnownTypes = []string{
+	"order.pending_payment",
+	"order.pending_review",
+	"order.approved",
+	"order.rejected",
+	"order.canceled",
+	"order.provisioning",
+	"order.item.active",
+	"order.item.failed",
+	"order.completed",
+	"plugin." + domain.PluginHealthCrashed,
+	"plugin." + domain.PluginHealthRestarted,
+	"plugin." + domain.PluginHealthRestartFailed,
+	"plugin." + domain.PluginHealthCircuitOpen,
+	"plugin." + domain.PluginHealthCircuitClosed,
+	"plugin." + domain.PluginHealthUnhealthy,
+	"plugin." + domain.PluginHealthRecovered,
+	"ledger.drift",
+}
+
+type Service struct {
+	settings appports.SettingsRepository
+	sender   appports.NotifyChannelSender
+}
+
+func NewService(settings appports.SettingsRepository, sender appports.NotifyChannelSender) *Service {
+	return &Service{settings: settings, sender: sender}
+}
+
+func (s *Service) Channels(ctx context.Context) ([]appshared.NotifyChannel, error) {
+	if s.sender == nil {
+		return nil, domain.ErrPluginManagerUnavailable
+	}
+	return s.sender.ListChannels(ctx)
+}
+
+func (s *Service) Routes(ctx context.Context) ([]appshared.NotifyRoute, error) {
+	if s.settings == nil {
+		return nil, nil
+	}
+	setting, err := s.settings.GetSetting(ctx, settingRoutes)
+	if err != nil || strings.TrimSpace(setting.ValueJSON) == "" {
+		return []appshared.NotifyRoute{}, nil
+	}
+	routes, err := parseRoutes(setting.ValueJSON)
+	if err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+func (s *Service) UpdateRoutes(ctx context.Context, routes []appshared.NotifyRoute) ([]appshared.NotifyRoute, error) {
+	if s.settings == nil {
+		return nil, appshared.ErrInvalidInput
+	}
+	normalized, err := normalizeRoutes(routes)
+	if err != nil {
+		return nil, err
+	}
+	raw, _ := json.Marshal(normalized)
+	if err := s.settings.UpsertSetting(ctx, domain.Setting{Key: settingRoutes, ValueJSON: string(raw), UpdatedAt: time.Now()}); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// Dispatch sends msg to every channel routed for msg.Type. Each channel is
+// tried once; failures are logged and returned joined.
+func (s *Service) Dispatch(ctx context.Context, msg appshared.NotifyMessage) error {
+	if s.sender == nil {
+		return nil
+	}
+	routes, err := s.Routes(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, ref := range channelsFor(routes, msg.Type) {
+		pluginID, instanceID, _ := splitChannelRef(ref)
+		if _, err := s.sender.Send(ctx, pluginID, instanceID, msg); err != nil {
+			log.Printf("notify channel send failed: %s type=%s: %v", ref, msg.Type, err)
+			errs = append(errs, fmt.Errorf("%s: %w", ref, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Test sends a message to a single channel regardless of routing.
+func (s *Service) Test(ctx context.Context, channel string, recipients []string, title, content string) (appshared.NotifyDelivery, error) {
+	if s.sender == nil {
+		return appshared.NotifyDelivery{}, domain.ErrPluginManagerUnavailable
+	}
+	pluginID, instanceID, ok := splitChannelRef(channel)
+	if !ok {
+		return appshared.NotifyDelivery{}, domain.ErrNotifyChannelNotFound
+	}
+	if strings.TrimSpace(title) == "" {
+		title = "Test notification"
+	}
+	if strings.TrimSpace(content) == "" {
+		content = "This is a test message from the notification channel settings."
+	}
+	return s.sender.Send(ctx, pluginID, instanceID, appshared.NotifyMessage{
+		Type:       "test",
+		Title:      title,
+		Content:    content,
+		Recipients: recipients,
+	})
+}
+
+// NotifyOrderEvent lets the service act as an order event sink.
+func (s *Service) NotifyOrderEvent(ctx context.Context, ev domain.OrderEvent) error {
+	return s.Dispatch(ctx, appshared.NotifyMessage{
+		Type:           ev.Type,
+		Title:          fmt.Sprintf("Order #%d: %s", ev.OrderID, ev.Type),
+		Content:        ev.DataJSON,
+		Vars:           map[string]string{"order_id": fmt.Sprintf("%d", ev.OrderID), "event": ev.Type},
+		IdempotencyKey: fmt.Sprintf("order:%d:%d", ev.OrderID, ev.Seq),
+	})
+}
+
+func (s *Service) NotifyPluginHealth(ctx context.Context, ev domain.PluginHealthEvent) {
+	target := ev.Category + "/" + ev.PluginID + "/" + ev.InstanceID
+	content := target
+	if msg := strings.TrimSpace(ev.Message); msg != "" {
+		content += ": " + msg
+	}
+	_ = s.Dispatch(ctx, appshared.NotifyMessage{
+		Type:    "plugin." + ev.Kind,
+		Title:   "Plugin " + ev.Kind + ": " + target,
+		Content: content,
+		Vars: map[string]string{
+			"category":    ev.Category,
+			"plugin_id":   ev.PluginID,
+			"instance_id": ev.InstanceID,
+			"restarts":    fmt.Sprintf("%d", ev.Restarts),
+		},
+	})
+}
+
+// EmailSender wraps the SMTP sender: when notify_email_channel names a notify
+// plugin instance, emails go through that provider instead.
+func (s *Service) EmailSender(fallback appports.EmailSender) appports.EmailSender {
+	return &emailSender{svc: s, fallback: fallback}
+}
+
+type emailSender struct {
+	svc      *Service
+	fallback appports.EmailSender
+}
+
+func (e *emailSender) Send(ctx context.Context, to string, subject string, body string) error {
+	ref := ""
+	if e.svc.settings != nil {
+		if setting, err := e.svc.settings.GetSetting(ctx, settingEmailChannel); err == nil {
+			ref = strings.TrimSpace(setting.ValueJSON)
+		}
+	}
+	pluginID, instanceID, ok := splitChannelRef(ref)
+	if !ok || e.svc.sender == nil {
+		if e.fallback == nil {
+			return domain.ErrNotSupported
+		}
+		return e.fallback.Send(ctx, to, subject, body)
+	}
+	_, err := e.svc.sender.Send(ctx, pluginID, instanceID, appshared.NotifyMessage{
+		Type:       TypeEmail,
+		Title:      subject,
+		Content:    body,
+		Recipients: []string{to},
+	})
+	return err
+}
+
+func parseRoutes(raw string) ([]appshared.NotifyRoute, error) {
+	var routes []appshared.NotifyRoute
+	if err := json.Unmarshal([]byte(raw), &routes); err != nil {
+		return nil, domain.ErrInvalidNotifyRoutes
+	}
+	return normalizeRoutes(routes)
+}
+
+func normalizeRoutes(routes []appshared.NotifyRoute) ([]appshared.NotifyRoute, error) {
+	out := make([]appshared.NotifyRoute, 0, len(routes))
+	seenType := map[string]bool{}
+	for _, r := range routes {
+		typ := strings.ToLower(strings.TrimSpace(r.Type))
+		if typ == "" || seenType[typ] {
+			return nil, domain.ErrInvalidNotifyRoutes
+		}
+		seenType[typ] = true
+		channels := make([]string, 0, len(r.Channels))
+		seen := map[string]bool{}
+		for _, ref := range r.Channels {
+			pluginID, instanceID, ok := splitChannelRef(ref)
+			if !ok {
+				return nil, domain.ErrInvalidNotifyRoutes
+			}
+			norm := pluginID + "/" + instanceID
+			if !seen[norm] {
+				seen[norm] = true
+				channels = append(channels, norm)
+			}
+		}
+		out = append(out, appshared.NotifyRoute{Type: typ, Channels: channels})
+	}
+	return out, nil
+}
+
+func routeMatches(pattern, typ string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(typ, prefix)
+	}
+	return pattern == typ
+}
+
+// channelsFor returns the de-duplicated channels of every matching route.
+func channelsFor(routes []appshared.NotifyRoute, typ string) []string {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	var out []string
+	seen := map[string]bool{}
+	for _, r := range routes {
+		if !routeMatches(r.Type, typ) {
+			continue
+		}
+		for _, ref := range r.Channels {
+			if !seen[ref] {
+				seen[ref] = true
+				out = append(out, ref)
+			}
+		}
+	}
+	return out
+}
+
+// splitChannelRef parses "plugin_id/instance_id"; the instance defaults to
+// "default".
+func splitChannelRef(ref string) (string, string, bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", "", false
+	}
+	pluginID, instanceID, _ := strings.Cut(ref, "/")
+	pluginID = strings.TrimSpace(pluginID)
+	instanceID = strings.TrimSpace(instanceID)
+	if instanceID == "" {
+		instanceID = "default"
+	}
+	if pluginID == "" || strings.Contains(instanceID, "/") {
+		return "", "", false
+	}
+	return pluginID, instanceID, true
+}
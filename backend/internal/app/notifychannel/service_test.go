@@ -0,0 +1,95 @@
+package notifychannel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appnotifychannel "xiaoheiplay/internal/app/notifychannel"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+type notifySend struct {
+	channel string
+	msg     appshared.NotifyMessage
+}
+
+type fakeNotifySender struct {
+	sends []notifySend
+	fail  map[string]error
+}
+
+func (f *fakeNotifySender) Send(ctx context.Context, pluginID, instanceID string, msg appshared.NotifyMessage) (appshared.NotifyDelivery, error) {
+	ref := pluginID + "/" + instanceID
+	f.sends = append(f.sends, notifySend{channel: ref, msg: msg})
+	return appshared.NotifyDelivery{MessageID: "m1"}, f.fail[ref]
+}
+
+func (f *fakeNotifySender) ListChannels(ctx context.Context) ([]appshared.NotifyChannel, error) {
+	return nil, nil
+}
+
+func TestDispatchRoutesByType(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	sender := &fakeNotifySender{fail: map[string]error{"dingtalk/ops": errors.New("down")}}
+	svc := appnotifychannel.NewService(repo, sender)
+
+	if _, err := svc.UpdateRoutes(ctx, []appshared.NotifyRoute{{Type: "order.*", Channels: []string{"/x"}}}); !errors.Is(err, domain.ErrInvalidNotifyRoutes) {
+		t.Fatalf("expected invalid routes, got %v", err)
+	}
+	routes, err := svc.UpdateRoutes(ctx, []appshared.NotifyRoute{
+		{Type: "order.*", Channels: []string{"telegram", "dingtalk/ops"}},
+		{Type: "Order.Pending_Review", Channels: []string{"telegram/default", "slack/default"}},
+	})
+	if err != nil {
+		t.Fatalf("update routes: %v", err)
+	}
+	if routes[0].Channels[0] != "telegram/default" || routes[1].Type != "order.pending_review" {
+		t.Fatalf("routes not normalized: %+v", routes)
+	}
+
+	err = svc.NotifyOrderEvent(ctx, domain.OrderEvent{OrderID: 7, Seq: 3, Type: "order.pending_review", DataJSON: `{"status":"pending_review"}`})
+	if err == nil {
+		t.Fatalf("expected failing channel to be reported")
+	}
+	if len(sender.sends) != 3 {
+		t.Fatalf("expected 3 de-duplicated sends, got %+v", sender.sends)
+	}
+	if sender.sends[0].msg.IdempotencyKey != "order:7:3" {
+		t.Fatalf("unexpected message: %+v", sender.sends[0].msg)
+	}
+
+	sender.sends = nil
+	if err := svc.Dispatch(ctx, appshared.NotifyMessage{Type: "plugin.crashed"}); err != nil {
+		t.Fatalf("dispatch: %v", err)
+	}
+	if len(sender.sends) != 0 {
+		t.Fatalf("unrouted type must not be sent: %+v", sender.sends)
+	}
+}
+
+func TestEmailSenderUsesConfiguredChannel(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	sender := &fakeNotifySender{}
+	smtp := &testutil.FakeEmailSender{}
+	email := appnotifychannel.NewService(repo, sender).EmailSender(smtp)
+
+	if err := email.Send(ctx, "a@example.com", "Hi", "body"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if len(smtp.Sends) != 1 || len(sender.sends) != 0 {
+		t.Fatalf("expected SMTP fallback, smtp=%d plugin=%d", len(smtp.Sends), len(sender.sends))
+	}
+
+	_ = repo.UpsertSetting(ctx, domain.Setting{Key: "notify_email_channel", ValueJSON: "sendgrid"})
+	if err := email.Send(ctx, "b@example.com", "Hi", "body"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if len(sender.sends) != 1 || sender.sends[0].channel != "sendgrid/default" || sender.sends[0].msg.Recipients[0] != "b@example.com" {
+		t.Fatalf("expected plugin delivery, got %+v", sender.sends)
+	}
+}
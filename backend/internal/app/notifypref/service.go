@@ -0,0 +1,260 @@
+package notifypref
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+// Category describes a notification category shown in the preference center.
+type Category struct {
+	Key       string
+	Name      string
+	Mandatory bool
+	Channels  []string
+}
+
+// Categories lists the categories in display order. Mandatory categories
+// are listed so users can see them, but cannot be turned off.
+var Categories = []Category{
+	{Key: domain.NotificationCategoryOrder, Name: "订单状态", Channels: allChannels},
+	{Key: domain.NotificationCategoryExpiry, Name: "到期提醒", Channels: allChannels},
+	{Key: domain.NotificationCategoryTicket, Name: "工单回复", Channels: allChannels},
+	{Key: domain.NotificationCategoryService, Name: "实例状态", Channels: allChannels},
+	{Key: domain.NotificationCategoryMarketing, Name: "公告与活动", Channels: allChannels},
+	{Key: domain.NotificationCategorySecurity, Name: "账号安全", Mandatory: true, Channels: allChannels},
+	{Key: domain.NotificationCategoryBilling, Name: "账单与欠费", Mandatory: true, Channels: allChannels},
+}
+
+var allChannels = []string{
+	domain.NotificationChannelInApp,
+	domain.NotificationChannelEmail,
+	domain.NotificationChannelSMS,
+	domain.NotificationChannelPush,
+}
+
+// eventCategories maps notification types to categories. Types not listed
+// fall into the system category and are always delivered.
+var eventCategories = map[string]string{
+	"provisioned":           domain.NotificationCategoryOrder,
+	"provision_failed":      domain.NotificationCategoryOrder,
+	"provision_success":     domain.NotificationCategoryOrder,
+	"order_canceled":        domain.NotificationCategoryOrder,
+	"order_approved":        domain.NotificationCategoryOrder,
+	"order_rejected":        domain.NotificationCategoryOrder,
+	"order_new":             domain.NotificationCategoryOrder,
+	"expire":                domain.NotificationCategoryExpiry,
+	"dunning_remind":        domain.NotificationCategoryExpiry,
+	"dunning":               domain.NotificationCategoryBilling,
+	"dunning_suspend":       domain.NotificationCategoryBilling,
+	"dunning_final_warning": domain.NotificationCategoryBilling,
+	"dunning_terminate":     domain.NotificationCategoryBilling,
+	"ticket_reply":          domain.NotificationCategoryTicket,
+	"announcement":          domain.NotificationCategoryMarketing,
+	"marketing":             domain.NotificationCategoryMarketing,
+	"plugin_health":         domain.NotificationCategoryService,
+	"realname":              domain.NotificationCategorySecurity,
+	"security":              domain.NotificationCategorySecurity,
+}
+
+// CategoryOf returns the category of a notification type.
+func CategoryOf(event string) string {
+	event = strings.ToLower(strings.TrimSpace(event))
+	if category, ok := eventCategories[event]; ok {
+		return category
+	}
+	if strings.HasPrefix(event, "vps_") {
+		return domain.NotificationCategoryService
+	}
+	return domain.NotificationCategorySystem
+}
+
+// Mandatory reports whether a category ignores user preferences.
+func Mandatory(category string) bool {
+	switch category {
+	case domain.NotificationCategorySecurity, domain.NotificationCategoryBilling, domain.NotificationCategorySystem:
+		return true
+	}
+	return false
+}
+
+type Service struct {
+	repo appports.NotificationPreferenceRepository
+	now  func() time.Time
+}
+
+func NewService(repo appports.NotificationPreferenceRepository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// Get returns the user's preferences; users who never saved any get
+// everything enabled.
+func (s *Service) Get(ctx context.Context, userID int64) (domain.NotificationPreferences, error) {
+	if s.repo == nil {
+		return domain.NotificationPreferences{}, appshared.ErrInvalidInput
+	}
+	prefs, err := s.repo.GetNotificationPreferences(ctx, userID)
+	if errors.Is(err, appshared.ErrNotFound) {
+		return domain.NotificationPreferences{UserID: userID, Disabled: map[string][]string{}}, nil
+	}
+	if err != nil {
+		return domain.NotificationPreferences{}, err
+	}
+	if prefs.Disabled == nil {
+		prefs.Disabled = map[string][]string{}
+	}
+	return prefs, nil
+}
+
+// Update replaces the user's preferences. Turning off a mandatory category
+// or an unknown category or channel is rejected.
+func (s *Service) Update(ctx context.Context, userID int64, prefs domain.NotificationPreferences) (domain.NotificationPreferences, error) {
+	if s.repo == nil || userID <= 0 {
+		return domain.NotificationPreferences{}, appshared.ErrInvalidInput
+	}
+	disabled := make(map[string][]string, len(prefs.Disabled))
+	for category, channels := range prefs.Disabled {
+		category = strings.ToLower(strings.TrimSpace(category))
+		if !knownCategory(category) || Mandatory(category) {
+			return domain.NotificationPreferences{}, domain.ErrInvalidNotificationPreferences
+		}
+		list := make([]string, 0, len(channels))
+		for _, ch := range channels {
+			ch = strings.ToLower(strings.TrimSpace(ch))
+			if !knownChannel(ch) {
+				return domain.NotificationPreferences{}, domain.ErrInvalidNotificationPreferences
+			}
+			if !contains(list, ch) {
+				list = append(list, ch)
+			}
+		}
+		if len(list) > 0 {
+			disabled[category] = list
+		}
+	}
+	out := domain.NotificationPreferences{
+		UserID:            userID,
+		Disabled:          disabled,
+		QuietHoursEnabled: prefs.QuietHoursEnabled,
+		QuietStart:        strings.TrimSpace(prefs.QuietStart),
+		QuietEnd:          strings.TrimSpace(prefs.QuietEnd),
+		Timezone:          strings.TrimSpace(prefs.Timezone),
+	}
+	if out.QuietHoursEnabled || out.QuietStart != "" || out.QuietEnd != "" {
+		start, okStart := parseClock(out.QuietStart)
+		end, okEnd := parseClock(out.QuietEnd)
+		if !okStart || !okEnd || start == end {
+			return domain.NotificationPreferences{}, domain.ErrInvalidNotificationPreferences
+		}
+	}
+	if out.Timezone != "" {
+		if _, err := time.LoadLocation(out.Timezone); err != nil {
+			return domain.NotificationPreferences{}, domain.ErrInvalidNotificationPreferences
+		}
+	}
+	if err := s.repo.UpsertNotificationPreferences(ctx, &out); err != nil {
+		return domain.NotificationPreferences{}, err
+	}
+	return out, nil
+}
+
+// Allow is the single gate every channel asks before sending a
+// notification of the given type to a user. Mandatory notices always pass.
+// Quiet hours do not block here; see QuietUntil.
+func (s *Service) Allow(ctx context.Context, userID int64, event, channel string) bool {
+	category := CategoryOf(event)
+	if Mandatory(category) || userID <= 0 || s.repo == nil {
+		return true
+	}
+	prefs, err := s.repo.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return true
+	}
+	return !contains(prefs.Disabled[category], channel)
+}
+
+// QuietUntil reports whether an SMS or push of the given type falls in the
+// user's quiet hours, and when the window ends. Senders hold the message
+// until then instead of dropping it. Mandatory notices are never held.
+func (s *Service) QuietUntil(ctx context.Context, userID int64, event, channel string) (time.Time, bool) {
+	if channel != domain.NotificationChannelSMS && channel != domain.NotificationChannelPush {
+		return time.Time{}, false
+	}
+	if Mandatory(CategoryOf(event)) || userID <= 0 || s.repo == nil {
+		return time.Time{}, false
+	}
+	prefs, err := s.repo.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return s.quietUntil(prefs)
+}
+
+func (s *Service) quietUntil(prefs domain.NotificationPreferences) (time.Time, bool) {
+	if !prefs.QuietHoursEnabled {
+		return time.Time{}, false
+	}
+	start, okStart := parseClock(prefs.QuietStart)
+	end, okEnd := parseClock(prefs.QuietEnd)
+	if !okStart || !okEnd {
+		return time.Time{}, false
+	}
+	now := s.now()
+	if prefs.Timezone != "" {
+		if loc, err := time.LoadLocation(prefs.Timezone); err == nil {
+			now = now.In(loc)
+		}
+	}
+	minute := now.Hour()*60 + now.Minute()
+	var quiet bool
+	if start < end {
+		quiet = minute >= start && minute < end
+	} else {
+		// The window crosses midnight, e.g. 22:00-08:00.
+		quiet = minute >= start || minute < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+	until := time.Date(now.Year(), now.Month(), now.Day(), end/60, end%60, 0, 0, now.Location())
+	if !until.After(now) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(v string) (int, bool) {
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+func knownCategory(category string) bool {
+	for _, item := range Categories {
+		if item.Key == category {
+			return true
+		}
+	}
+	return false
+}
+
+func knownChannel(channel string) bool {
+	return contains(allChannels, channel)
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package notifypref_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appmessage "xiaoheiplay/internal/app/message"
+	appnotifypref "xiaoheiplay/internal/app/notifypref"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+func TestNotificationPreferences_GateByCategoryAndChannel(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "pref", "pref@example.com", "pass")
+	svc := appnotifypref.NewService(repo)
+
+	prefs, err := svc.Get(ctx, user.ID)
+	if err != nil || len(prefs.Disabled) != 0 {
+		t.Fatalf("expected empty defaults, got %+v %v", prefs, err)
+	}
+	if !svc.Allow(ctx, user.ID, "announcement", domain.NotificationChannelInApp) {
+		t.Fatalf("expected everything enabled by default")
+	}
+
+	if _, err := svc.Update(ctx, user.ID, domain.NotificationPreferences{
+		Disabled: map[string][]string{domain.NotificationCategoryBilling: {domain.NotificationChannelEmail}},
+	}); !errors.Is(err, domain.ErrInvalidNotificationPreferences) {
+		t.Fatalf("expected billing to be mandatory, got %v", err)
+	}
+	if _, err := svc.Update(ctx, user.ID, domain.NotificationPreferences{
+		Disabled: map[string][]string{domain.NotificationCategoryOrder: {"fax"}},
+	}); !errors.Is(err, domain.ErrInvalidNotificationPreferences) {
+		t.Fatalf("expected unknown channel rejected, got %v", err)
+	}
+	if _, err := svc.Update(ctx, user.ID, domain.NotificationPreferences{QuietHoursEnabled: true, QuietStart: "25:00", QuietEnd: "08:00"}); !errors.Is(err, domain.ErrInvalidNotificationPreferences) {
+		t.Fatalf("expected bad quiet hours rejected, got %v", err)
+	}
+
+	if _, err := svc.Update(ctx, user.ID, domain.NotificationPreferences{
+		Disabled: map[string][]string{
+			" Marketing ":                    {"inapp", "email", "inapp"},
+			domain.NotificationCategoryOrder: {domain.NotificationChannelEmail},
+		},
+	}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	prefs, _ = svc.Get(ctx, user.ID)
+	if got := prefs.Disabled[domain.NotificationCategoryMarketing]; len(got) != 2 {
+		t.Fatalf("expected normalized marketing channels, got %+v", prefs.Disabled)
+	}
+	cases := []struct {
+		event, channel string
+		want           bool
+	}{
+		{"announcement", domain.NotificationChannelInApp, false},
+		{"order_approved", domain.NotificationChannelEmail, false},
+		{"order_approved", domain.NotificationChannelInApp, true},
+		{"ticket_reply", domain.NotificationChannelEmail, true},
+		{"dunning_suspend", domain.NotificationChannelEmail, true},
+		{"realname", domain.NotificationChannelInApp, true},
+		{"something_new", domain.NotificationChannelInApp, true},
+	}
+	for _, tc := range cases {
+		if got := svc.Allow(ctx, user.ID, tc.event, tc.channel); got != tc.want {
+			t.Fatalf("Allow(%s, %s) = %v, want %v", tc.event, tc.channel, got, tc.want)
+		}
+	}
+
+	messages := appmessage.NewService(repo, repo)
+	messages.SetNotificationPreferences(svc)
+	if err := messages.NotifyUser(ctx, user.ID, "announcement", "Sale", "50% off"); err != nil {
+		t.Fatalf("notify muted: %v", err)
+	}
+	if err := messages.NotifyUser(ctx, user.ID, "realname", "Verified", "ok"); err != nil {
+		t.Fatalf("notify mandatory: %v", err)
+	}
+	items, total, err := messages.List(ctx, user.ID, "", 10, 0)
+	if err != nil || total != 1 || items[0].Type != "realname" {
+		t.Fatalf("expected only the mandatory message, got %+v %d %v", items, total, err)
+	}
+}
+
+func TestNotificationPreferences_QuietHoursHoldSMSAndPush(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "quiet", "quiet@example.com", "pass")
+	svc := appnotifypref.NewService(repo)
+
+	now := time.Now().UTC()
+	if _, err := svc.Update(ctx, user.ID, domain.NotificationPreferences{
+		QuietHoursEnabled: true,
+		QuietStart:        now.Add(-time.Hour).Format("15:04"),
+		QuietEnd:          now.Add(time.Hour).Format("15:04"),
+		Timezone:          "UTC",
+	}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if !svc.Allow(ctx, user.ID, "expire", domain.NotificationChannelSMS) {
+		t.Fatalf("expected quiet hours to delay sms, not mute it")
+	}
+	until, quiet := svc.QuietUntil(ctx, user.ID, "ticket_reply", domain.NotificationChannelPush)
+	if !quiet || until.Sub(now) <= 0 || until.Sub(now) > time.Hour {
+		t.Fatalf("expected push held until the window ends, got %v %v", until, quiet)
+	}
+	if _, quiet := svc.QuietUntil(ctx, user.ID, "expire", domain.NotificationChannelSMS); !quiet {
+		t.Fatalf("expected quiet hours to hold sms")
+	}
+	if _, quiet := svc.QuietUntil(ctx, user.ID, "ticket_reply", domain.NotificationChannelEmail); quiet {
+		t.Fatalf("expected email to pass during quiet hours")
+	}
+	if _, quiet := svc.QuietUntil(ctx, user.ID, "dunning_terminate", domain.NotificationChannelSMS); quiet {
+		t.Fatalf("expected billing sms to ignore quiet hours")
+	}
+	if _, err := svc.Update(ctx, user.ID, domain.NotificationPreferences{Timezone: "Mars/Base"}); !errors.Is(err, domain.ErrInvalidNotificationPreferences) {
+		t.Fatalf("expected unknown time zone rejected, got %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package order
+
+import (
+	"context"
+
+	"xiaoheiplay/internal/domain"
+)
+
+type addonResolver interface {
+	Resolve(ctx context.Context, userID int64, plan domain.PlanGroup, picks []CartAddon) ([]CartAddon, error)
+}
+
+func (s *OrderService) SetAddonService(addons addonResolver) {
+	s.addons = addons
+}
+
+// resolveSpecAddons prices the addon SKUs picked in spec for the buyer,
+// replacing whatever the client sent with catalog values.
+func (s *OrderService) resolveSpecAddons(ctx context.Context, userID int64, plan domain.PlanGroup, spec *CartSpec) error {
+	if len(spec.Addons) == 0 {
+		return nil
+	}
+	if s.addons == nil {
+		return domain.ErrInvalidAddon
+	}
+	resolved, err := s.addons.Resolve(ctx, userID, plan, spec.Addons)
+	if err != nil {
+		return err
+	}
+	spec.Addons = resolved
+	return nil
+}
@@ -0,0 +1,57 @@
+package order
+
+import (
+	"context"
+
+	"xiaoheiplay/internal/domain"
+)
+
+type approvalPolicy interface {
+	EvaluateOrder(ctx context.Context, order domain.Order) (domain.OrderApprovalEvaluation, error)
+	HoldReason(ctx context.Context, orderID int64) (string, bool)
+}
+
+func (s *OrderService) SetApprovalPolicy(policy approvalPolicy) {
+	s.approval = policy
+}
+
+// applyApprovalPolicy runs the risk rules on a new purchase order. Held
+// orders keep the rule's reason in PendingReason; rejected orders are
+// rejected right away and the caller gets ErrOrderRejectedByPolicy. When
+// the rules cannot be evaluated the order goes through unchanged.
+func (s *OrderService) applyApprovalPolicy(ctx context.Context, order domain.Order) (domain.Order, error) {
+	if s.approval == nil {
+		return order, nil
+	}
+	eval, err := s.approval.EvaluateOrder(ctx, order)
+	if err != nil {
+		return order, nil
+	}
+	switch eval.Decision {
+	case domain.OrderApprovalReview:
+		order.PendingReason = eval.Reason
+		if err := s.orders.UpdateOrderMeta(ctx, order); err != nil {
+			return domain.Order{}, err
+		}
+	case domain.OrderApprovalReject:
+		if err := s.RejectOrder(ctx, 0, order.ID, eval.Reason); err != nil {
+			return domain.Order{}, err
+		}
+		if rejected, err := s.orders.GetOrder(ctx, order.ID); err == nil {
+			rejected.PendingReason = eval.Reason
+			_ = s.orders.UpdateOrderMeta(ctx, rejected)
+		}
+		return domain.Order{}, domain.ErrOrderRejectedByPolicy
+	}
+	return order, nil
+}
+
+// approvalHoldReason returns the reason an order is held for review by the
+// risk rules, or "" when it is not held.
+func (s *OrderService) approvalHoldReason(ctx context.Context, orderID int64) string {
+	if s.approval == nil {
+		return ""
+	}
+	reason, _ := s.approval.HoldReason(ctx, orderID)
+	return reason
+}
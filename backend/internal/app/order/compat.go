@@ -37,6 +37,7 @@ type (
 	AutomationHostInfo             = appshared.AutomationHostInfo
 	AutomationCreateHostResult     = appshared.AutomationCreateHostResult
 	AutomationCreateHostRequest    = appshared.AutomationCreateHostRequest
+	AutomationResetOSRequest       = appshared.AutomationResetOSRequest
 	AutomationHostSimple           = appshared.AutomationHostSimple
 	AutomationElasticUpdateRequest = appshared.AutomationElasticUpdateRequest
 	AutomationArea                 = appshared.AutomationArea
@@ -51,6 +52,7 @@ type (
 	AutomationFirewallRuleCreate   = appshared.AutomationFirewallRuleCreate
 	AutomationPortMappingCreate    = appshared.AutomationPortMappingCreate
 	CartSpec                       = appshared.CartSpec
+	CartAddon                      = appshared.CartAddon
 	OrderFilter                    = appshared.OrderFilter
 	RobotOrderPayload              = appshared.RobotOrderPayload
 	RobotOrderItem                 = appshared.RobotOrderItem
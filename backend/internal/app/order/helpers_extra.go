@@ -34,7 +34,10 @@ func normalizeCartSpec(spec *CartSpec) error {
 	if spec.CycleQty < 0 {
 		return ErrInvalidInput
 	}
-	return nil
+	if err := spec.NormalizeAddons(); err != nil {
+		return err
+	}
+	return spec.NormalizeProvisionOptions()
 }
 
 func validateAddonSpec(spec CartSpec, plan domain.PlanGroup) error {
@@ -0,0 +1,93 @@
+package order
+
+import (
+	"context"
+
+	appcoupon "xiaoheiplay/internal/app/coupon"
+	"xiaoheiplay/internal/domain"
+)
+
+// checkStock rejects an order up front when the quoted packages are short, so
+// the cart is kept and the caller gets the shortage details.
+func (s *OrderService) checkStock(ctx context.Context, quotes []appcoupon.QuoteItem) error {
+	if s.inventory == nil {
+		return nil
+	}
+	demand := map[int64]int{}
+	for _, q := range quotes {
+		demand[q.PackageID] += q.Qty
+	}
+	return s.inventory.Check(ctx, demand)
+}
+
+// reserveStock holds stock for a created order; when a concurrent checkout
+// took the last units the order is rolled back.
+func (s *OrderService) reserveStock(ctx context.Context, order domain.Order, items []domain.OrderItem) error {
+	if s.inventory == nil {
+		return nil
+	}
+	if err := s.inventory.Reserve(ctx, order, items); err != nil {
+		if order.CouponID != nil && s.coupon != nil {
+			_ = s.coupon.MarkOrderCanceled(ctx, order.ID)
+		}
+		_ = s.orders.DeleteOrder(ctx, order.ID)
+		return err
+	}
+	return nil
+}
+
+// planStock prepares the reservations of a cart order so they can be held in
+// the transaction that creates it.
+func (s *OrderService) planStock(ctx context.Context, order domain.Order, items []domain.OrderItem) ([]domain.InventoryReservation, error) {
+	if s.inventory == nil {
+		return nil, nil
+	}
+	return s.inventory.Plan(ctx, order, items)
+}
+
+func (s *OrderService) stockError(ctx context.Context, planned []domain.InventoryReservation, err error) error {
+	if s.inventory == nil {
+		return err
+	}
+	return s.inventory.ShortageError(ctx, planned, err)
+}
+
+func (s *OrderService) releaseStock(ctx context.Context, orderID int64) {
+	if s.inventory != nil {
+		_ = s.inventory.Release(ctx, orderID)
+	}
+}
+
+// ExpireInventoryReservations cancels unpaid orders whose reservation timed
+// out, then reconciles the remaining reservations.
+func (s *OrderService) ExpireInventoryReservations(ctx context.Context, limit int) (int, error) {
+	if s.inventory == nil {
+		return 0, nil
+	}
+	orderIDs, err := s.inventory.ExpiredOrders(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	canceled := 0
+	for _, orderID := range orderIDs {
+		order, err := s.orders.GetOrder(ctx, orderID)
+		if err != nil {
+			continue
+		}
+		if order.Status != domain.OrderStatusPendingPayment {
+			_ = s.inventory.KeepOrder(ctx, orderID)
+			continue
+		}
+		if err := s.cancelOrder(ctx, order, "reservation_expired"); err != nil {
+			continue
+		}
+		canceled++
+		if s.messages != nil {
+			_ = s.messages.NotifyUser(ctx, order.UserID, "order_canceled", "Order Canceled", "Order "+order.OrderNo+" was canceled because it was not paid in time.")
+		}
+	}
+	if _, _, err := s.inventory.Reconcile(ctx); err != nil {
+		return canceled, err
+	}
+	return canceled, nil
+}
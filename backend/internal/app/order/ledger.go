@@ -0,0 +1,47 @@
+package order
+
+import (
+	"context"
+	"fmt"
+
+	"xiaoheiplay/internal/domain"
+)
+
+type ledgerPoster interface {
+	Post(ctx context.Context, entry domain.LedgerEntry) error
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+func (s *OrderService) SetLedger(ledger ledgerPoster) {
+	s.ledger = ledger
+}
+
+// postApprovedPayment books a manually reviewed payment as revenue. Balance
+// payments are booked by the payment service when the wallet is debited.
+func (s *OrderService) postApprovedPayment(ctx context.Context, pay domain.OrderPayment) error {
+	if s.ledger == nil || pay.Method == "balance" || pay.Amount <= 0 {
+		return nil
+	}
+	return s.ledger.Post(ctx, domain.NewLedgerTransfer(domain.LedgerKindOrderPayment, "payment", pay.ID, pay.UserID, domain.LedgerGatewayAccount(pay.Method), domain.LedgerAccountRevenue, pay.Amount, fmt.Sprintf("order %d", pay.OrderID)))
+}
+
+// postWalletRefund books a refund credited straight to the wallet: the
+// amount is accrued from revenue and settled from refunds payable.
+func (s *OrderService) postWalletRefund(ctx context.Context, userID int64, refType string, refID int64, amount int64, memo string) error {
+	if s.ledger == nil || amount <= 0 {
+		return nil
+	}
+	if err := s.ledger.Post(ctx, domain.NewLedgerTransfer(domain.LedgerKindRefundAccrual, refType, refID, userID, domain.LedgerAccountRevenue, domain.LedgerAccountRefundsPayable, amount, memo)); err != nil {
+		return err
+	}
+	return s.ledger.Post(ctx, domain.NewLedgerTransfer(domain.LedgerKindRefundSettlement, refType, refID, userID, domain.LedgerAccountRefundsPayable, domain.LedgerWalletAccount(userID), amount, memo))
+}
+
+// inTx runs fn in the ledger's transaction. Callers put the balance or
+// payment change and its entries in fn so neither is committed alone.
+func (s *OrderService) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.ledger == nil {
+		return fn(ctx)
+	}
+	return s.ledger.InTx(ctx, fn)
+}
@@ -33,7 +33,6 @@ type OrderService struct {
 	robot       RobotNotifier
 	audit       AuditRepository
 	users       UserRepository
-	email       EmailSender
 	settings    SettingsRepository
 	payments    PaymentRepository
 	autoLogs    AutomationLogRepository
@@ -44,16 +43,31 @@ type OrderService struct {
 	pricer      userTierPricingResolver
 	userTiers   userTierAutoApprover
 	coupon      couponEngine
+	inventory   inventoryReserver
+	sshKeys     sshKeyResolver
+	addons      addonResolver
+	trials      trialTracker
+	approval    approvalPolicy
+	ledger      ledgerPoster
 }
 
+// messageNotifier is the slice of message.Service order notices go
+// through; it applies the user's notification preferences.
 type messageNotifier interface {
 	NotifyUser(ctx context.Context, userID int64, typ, title, content string) error
+	SendEmail(ctx context.Context, userID int64, typ, subject, body string) error
 }
 
 type realNameActionChecker interface {
 	RequireAction(ctx context.Context, userID int64, action string) error
 }
 
+// companyActionChecker is implemented by real-name services that can demand
+// company verification for specific goods types or order amounts.
+type companyActionChecker interface {
+	RequireCompanyAction(ctx context.Context, userID int64, action string, scope appshared.RealNameActionScope) error
+}
+
 type OrderItemInput = appshared.OrderItemInput
 
 type PaymentInput = appshared.PaymentInput
@@ -65,8 +79,8 @@ type CouponPreview struct {
 	Final      int64
 }
 
-func NewOrderService(orders OrderRepository, items OrderItemRepository, cart CartRepository, catalog CatalogRepository, images SystemImageRepository, billing BillingCycleRepository, vps VPSRepository, wallets WalletRepository, payments PaymentRepository, events EventPublisher, automation AutomationClientResolver, robot RobotNotifier, audit AuditRepository, users UserRepository, email EmailSender, settings SettingsRepository, autoLogs AutomationLogRepository, provision ProvisionJobRepository, resizeTasks ResizeTaskRepository, messages messageNotifier, realname realNameActionChecker) *OrderService {
-	return &OrderService{orders: orders, items: items, cart: cart, catalog: catalog, images: images, billing: billing, vps: vps, wallets: wallets, payments: payments, events: events, automation: automation, robot: robot, audit: audit, users: users, email: email, settings: settings, autoLogs: autoLogs, provision: provision, resizeTasks: resizeTasks, messages: messages, realname: realname}
+func NewOrderService(orders OrderRepository, items OrderItemRepository, cart CartRepository, catalog CatalogRepository, images SystemImageRepository, billing BillingCycleRepository, vps VPSRepository, wallets WalletRepository, payments PaymentRepository, events EventPublisher, automation AutomationClientResolver, robot RobotNotifier, audit AuditRepository, users UserRepository, settings SettingsRepository, autoLogs AutomationLogRepository, provision ProvisionJobRepository, resizeTasks ResizeTaskRepository, messages messageNotifier, realname realNameActionChecker) *OrderService {
+	return &OrderService{orders: orders, items: items, cart: cart, catalog: catalog, images: images, billing: billing, vps: vps, wallets: wallets, payments: payments, events: events, automation: automation, robot: robot, audit: audit, users: users, settings: settings, autoLogs: autoLogs, provision: provision, resizeTasks: resizeTasks, messages: messages, realname: realname}
 }
 
 type userTierPricingResolver interface {
@@ -84,6 +98,18 @@ type couponEngine interface {
 	MarkOrderConfirmed(ctx context.Context, orderID int64) error
 }
 
+type inventoryReserver interface {
+	Check(ctx context.Context, demand map[int64]int) error
+	Reserve(ctx context.Context, order domain.Order, items []domain.OrderItem) error
+	Plan(ctx context.Context, order domain.Order, items []domain.OrderItem) ([]domain.InventoryReservation, error)
+	ShortageError(ctx context.Context, planned []domain.InventoryReservation, err error) error
+	Release(ctx context.Context, orderID int64) error
+	Commit(ctx context.Context, orderItemID int64) error
+	ExpiredOrders(ctx context.Context, limit int) ([]int64, error)
+	KeepOrder(ctx context.Context, orderID int64) error
+	Reconcile(ctx context.Context) (int, int, error)
+}
+
 func (s *OrderService) SetUserTierPricingResolver(resolver userTierPricingResolver) {
 	s.pricer = resolver
 }
@@ -96,6 +122,28 @@ func (s *OrderService) SetCouponService(coupon couponEngine) {
 	s.coupon = coupon
 }
 
+func (s *OrderService) SetInventoryService(inventory inventoryReserver) {
+	s.inventory = inventory
+}
+
+// requireCompanyForQuotes checks company requirements against the goods
+// types and the pre-discount total of an order.
+func (s *OrderService) requireCompanyForQuotes(ctx context.Context, userID int64, action string, quotes []appcoupon.QuoteItem, total int64) error {
+	goodsTypeIDs := make([]int64, 0, len(quotes))
+	for _, quote := range quotes {
+		goodsTypeIDs = append(goodsTypeIDs, quote.GoodsTypeID)
+	}
+	return s.requireCompany(ctx, userID, action, appshared.RealNameActionScope{GoodsTypeIDs: goodsTypeIDs, Amount: total})
+}
+
+func (s *OrderService) requireCompany(ctx context.Context, userID int64, action string, scope appshared.RealNameActionScope) error {
+	checker, ok := s.realname.(companyActionChecker)
+	if !ok {
+		return nil
+	}
+	return checker.RequireCompanyAction(ctx, userID, action, scope)
+}
+
 func (s *OrderService) client(ctx context.Context, goodsTypeID int64) (AutomationClient, error) {
 	if s.automation == nil {
 		return nil, ErrInvalidInput
@@ -159,10 +207,14 @@ func (s *OrderService) CreateOrderFromCart(ctx context.Context, userID int64, cu
 		if err := normalizeCartSpec(&spec); err != nil {
 			return domain.Order{}, nil, err
 		}
-		unitTotal, unitBase, addonCore, addonMem, addonDisk, addonBW, months, err := s.priceBreakdownForPackage(ctx, userID, pkg, plan, spec)
+		if err := s.validateSSHKeys(ctx, userID, spec); err != nil {
+			return domain.Order{}, nil, err
+		}
+		price, err := s.priceBreakdownForPackage(ctx, userID, pkg, plan, &spec)
 		if err != nil {
 			return domain.Order{}, nil, err
 		}
+		months := price.Months
 		spec.DurationMonths = months
 		specJSON := mustJSON(spec)
 		qty := item.Qty
@@ -182,27 +234,16 @@ func (s *OrderService) CreateOrderFromCart(ctx context.Context, userID int64, cu
 			SpecJSON:  specJSON,
 			Months:    months,
 			Qty:       qty,
-			UnitTotal: unitTotal,
-		})
-		quotes = append(quotes, appcoupon.QuoteItem{
-			PackageID:       pkg.ID,
-			GoodsTypeID:     pkg.GoodsTypeID,
-			RegionID:        plan.RegionID,
-			PlanGroupID:     plan.ID,
-			AddonCore:       spec.AddCores,
-			AddonMemGB:      spec.AddMemGB,
-			AddonDiskGB:     spec.AddDiskGB,
-			AddonBWMbps:     spec.AddBWMbps,
-			UnitBaseAmount:  unitBase,
-			UnitAddonAmount: addonCore + addonMem + addonDisk + addonBW,
-			UnitAddonCore:   addonCore,
-			UnitAddonMem:    addonMem,
-			UnitAddonDisk:   addonDisk,
-			UnitAddonBW:     addonBW,
-			UnitTotalAmount: unitTotal,
-			Qty:             qty,
+			UnitTotal: price.Total,
 		})
-		total += unitTotal * int64(qty)
+		quotes = append(quotes, price.quoteItem(pkg, plan, spec, qty))
+		total += price.Total * int64(qty)
+	}
+	if err := s.checkStock(ctx, quotes); err != nil {
+		return domain.Order{}, nil, err
+	}
+	if err := s.requireCompanyForQuotes(ctx, userID, "purchase_vps", quotes, total); err != nil {
+		return domain.Order{}, nil, err
 	}
 	order.TotalAmount = total
 	var couponResult *appcoupon.ApplyResult
@@ -250,13 +291,17 @@ func (s *OrderService) CreateOrderFromCart(ctx context.Context, userID int64, cu
 	}
 
 	type orderFromCartAtomicCreator interface {
-		CreateOrderFromCartAtomic(ctx context.Context, order domain.Order, items []domain.OrderItem) (domain.Order, []domain.OrderItem, error)
+		CreateOrderFromCartAtomic(ctx context.Context, order domain.Order, items []domain.OrderItem, reservations []domain.InventoryReservation) (domain.Order, []domain.OrderItem, error)
 	}
 	if atomic, ok := s.orders.(orderFromCartAtomicCreator); ok {
-		createdOrder, createdItems, err := atomic.CreateOrderFromCartAtomic(ctx, order, orderItems)
+		planned, err := s.planStock(ctx, order, orderItems)
 		if err != nil {
 			return domain.Order{}, nil, err
 		}
+		createdOrder, createdItems, err := atomic.CreateOrderFromCartAtomic(ctx, order, orderItems, planned)
+		if err != nil {
+			return domain.Order{}, nil, s.stockError(ctx, planned, err)
+		}
 		order = createdOrder
 		orderItems = createdItems
 	} else {
@@ -269,6 +314,9 @@ func (s *OrderService) CreateOrderFromCart(ctx context.Context, userID int64, cu
 		if err := s.items.CreateOrderItems(ctx, orderItems); err != nil {
 			return domain.Order{}, nil, err
 		}
+		if err := s.reserveStock(ctx, order, orderItems); err != nil {
+			return domain.Order{}, nil, err
+		}
 		if err := s.cart.ClearCart(ctx, userID); err != nil {
 			return domain.Order{}, nil, err
 		}
@@ -281,10 +329,15 @@ func (s *OrderService) CreateOrderFromCart(ctx context.Context, userID int64, cu
 			Status:         domain.CouponRedemptionStatusApplied,
 			DiscountAmount: order.CouponDiscount,
 		}); err != nil {
+			s.releaseStock(ctx, order.ID)
 			_ = s.orders.DeleteOrder(ctx, order.ID)
 			return domain.Order{}, nil, err
 		}
 	}
+	order, err = s.applyApprovalPolicy(ctx, order)
+	if err != nil {
+		return domain.Order{}, nil, err
+	}
 	if s.events != nil {
 		_, _ = s.events.Publish(ctx, order.ID, "order.pending_payment", map[string]any{
 			"status": order.Status,
@@ -329,6 +382,9 @@ func (s *OrderService) CreateOrderFromItems(ctx context.Context, userID int64, c
 		if err := normalizeCartSpec(&in.Spec); err != nil {
 			return domain.Order{}, nil, err
 		}
+		if err := s.validateSSHKeys(ctx, userID, in.Spec); err != nil {
+			return domain.Order{}, nil, err
+		}
 		pkg, err := s.catalog.GetPackage(ctx, in.PackageID)
 		if err != nil {
 			return domain.Order{}, nil, err
@@ -337,10 +393,11 @@ func (s *OrderService) CreateOrderFromItems(ctx context.Context, userID int64, c
 		if err != nil {
 			return domain.Order{}, nil, err
 		}
-		unitTotal, unitBase, addonCore, addonMem, addonDisk, addonBW, months, err := s.priceBreakdownForPackage(ctx, userID, pkg, plan, in.Spec)
+		price, err := s.priceBreakdownForPackage(ctx, userID, pkg, plan, &in.Spec)
 		if err != nil {
 			return domain.Order{}, nil, err
 		}
+		months := price.Months
 		qty := in.Qty
 		if qty <= 0 {
 			qty = 1
@@ -360,27 +417,16 @@ func (s *OrderService) CreateOrderFromItems(ctx context.Context, userID int64, c
 			SpecJSON:  specJSON,
 			Months:    months,
 			Qty:       qty,
-			UnitTotal: unitTotal,
-		})
-		quotes = append(quotes, appcoupon.QuoteItem{
-			PackageID:       pkg.ID,
-			GoodsTypeID:     pkg.GoodsTypeID,
-			RegionID:        plan.RegionID,
-			PlanGroupID:     plan.ID,
-			AddonCore:       in.Spec.AddCores,
-			AddonMemGB:      in.Spec.AddMemGB,
-			AddonDiskGB:     in.Spec.AddDiskGB,
-			AddonBWMbps:     in.Spec.AddBWMbps,
-			UnitBaseAmount:  unitBase,
-			UnitAddonAmount: addonCore + addonMem + addonDisk + addonBW,
-			UnitAddonCore:   addonCore,
-			UnitAddonMem:    addonMem,
-			UnitAddonDisk:   addonDisk,
-			UnitAddonBW:     addonBW,
-			UnitTotalAmount: unitTotal,
-			Qty:             qty,
+			UnitTotal: price.Total,
 		})
-		total += unitTotal * int64(qty)
+		quotes = append(quotes, price.quoteItem(pkg, plan, in.Spec, qty))
+		total += price.Total * int64(qty)
+	}
+	if err := s.checkStock(ctx, quotes); err != nil {
+		return domain.Order{}, nil, err
+	}
+	if err := s.requireCompanyForQuotes(ctx, userID, "purchase_vps", quotes, total); err != nil {
+		return domain.Order{}, nil, err
 	}
 	couponCode = strings.ToUpper(strings.TrimSpace(couponCode))
 	var couponResult *appcoupon.ApplyResult
@@ -458,6 +504,13 @@ func (s *OrderService) CreateOrderFromItems(ctx context.Context, userID int64, c
 			return domain.Order{}, nil, err
 		}
 	}
+	if err := s.reserveStock(ctx, order, orderItems); err != nil {
+		return domain.Order{}, nil, err
+	}
+	order, err := s.applyApprovalPolicy(ctx, order)
+	if err != nil {
+		return domain.Order{}, nil, err
+	}
 	if s.events != nil {
 		_, _ = s.events.Publish(ctx, order.ID, "order.pending_payment", map[string]any{
 			"status": order.Status,
@@ -533,7 +586,7 @@ func (s *OrderService) buildCouponQuotesFromInputs(ctx context.Context, userID i
 		if err != nil {
 			return nil, 0, err
 		}
-		unitTotal, unitBase, addonCore, addonMem, addonDisk, addonBW, _, err := s.priceBreakdownForPackage(ctx, userID, pkg, plan, in.Spec)
+		price, err := s.priceBreakdownForPackage(ctx, userID, pkg, plan, &in.Spec)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -541,25 +594,8 @@ func (s *OrderService) buildCouponQuotesFromInputs(ctx context.Context, userID i
 		if qty <= 0 {
 			qty = 1
 		}
-		quotes = append(quotes, appcoupon.QuoteItem{
-			PackageID:       pkg.ID,
-			GoodsTypeID:     pkg.GoodsTypeID,
-			RegionID:        plan.RegionID,
-			PlanGroupID:     plan.ID,
-			AddonCore:       in.Spec.AddCores,
-			AddonMemGB:      in.Spec.AddMemGB,
-			AddonDiskGB:     in.Spec.AddDiskGB,
-			AddonBWMbps:     in.Spec.AddBWMbps,
-			UnitBaseAmount:  unitBase,
-			UnitAddonAmount: addonCore + addonMem + addonDisk + addonBW,
-			UnitAddonCore:   addonCore,
-			UnitAddonMem:    addonMem,
-			UnitAddonDisk:   addonDisk,
-			UnitAddonBW:     addonBW,
-			UnitTotalAmount: unitTotal,
-			Qty:             qty,
-		})
-		total += unitTotal * int64(qty)
+		quotes = append(quotes, price.quoteItem(pkg, plan, in.Spec, qty))
+		total += price.Total * int64(qty)
 	}
 	return quotes, total, nil
 }
@@ -690,7 +726,7 @@ func (s *OrderService) SubmitPayment(ctx context.Context, userID int64, orderID
 		return domain.OrderPayment{}, err
 	}
 	order.Status = domain.OrderStatusPendingReview
-	order.PendingReason = ""
+	order.PendingReason = s.approvalHoldReason(ctx, order.ID)
 	if err := s.orders.UpdateOrderMeta(ctx, order); err != nil {
 		return domain.OrderPayment{}, err
 	}
@@ -738,6 +774,10 @@ func (s *OrderService) CancelOrder(ctx context.Context, userID int64, orderID in
 	if order.Status != domain.OrderStatusPendingPayment && order.Status != domain.OrderStatusPendingReview {
 		return ErrConflict
 	}
+	return s.cancelOrder(ctx, order, "")
+}
+
+func (s *OrderService) cancelOrder(ctx context.Context, order domain.Order, reason string) error {
 	order.Status = domain.OrderStatusCanceled
 	if err := s.orders.UpdateOrderMeta(ctx, order); err != nil {
 		return err
@@ -746,8 +786,15 @@ func (s *OrderService) CancelOrder(ctx context.Context, userID int64, orderID in
 	for _, item := range items {
 		_ = s.items.UpdateOrderItemStatus(ctx, item.ID, domain.OrderItemStatusCanceled)
 	}
+	if s.inventory != nil {
+		_ = s.inventory.Release(ctx, order.ID)
+	}
 	if s.events != nil {
-		_, _ = s.events.Publish(ctx, order.ID, "order.canceled", map[string]any{"status": order.Status})
+		data := map[string]any{"status": order.Status}
+		if reason != "" {
+			data["reason"] = reason
+		}
+		_, _ = s.events.Publish(ctx, order.ID, "order.canceled", data)
 	}
 	if s.coupon != nil {
 		_ = s.coupon.MarkOrderCanceled(ctx, order.ID)
@@ -910,6 +957,15 @@ func (s *OrderService) ApproveOrder(ctx context.Context, adminID int64, orderID
 		order.Status != domain.OrderStatusRejected {
 		return ErrConflict
 	}
+	if adminID == 0 && s.approval != nil {
+		if reason, held := s.approval.HoldReason(ctx, order.ID); held {
+			if order.PendingReason != reason {
+				order.PendingReason = reason
+				_ = s.orders.UpdateOrderMeta(ctx, order)
+			}
+			return domain.ErrOrderHeldForReview
+		}
+	}
 	now := time.Now()
 	order.Status = domain.OrderStatusApproved
 	order.ApprovedAt = &now
@@ -995,7 +1051,15 @@ func (s *OrderService) ApproveOrder(ctx context.Context, adminID int64, orderID
 	if s.payments != nil {
 		pays, _ := s.payments.ListPaymentsByOrder(ctx, order.ID)
 		for _, pay := range pays {
-			_ = s.payments.UpdatePaymentStatus(ctx, pay.ID, domain.PaymentStatusApproved, &adminID, "")
+			err := s.inTx(ctx, func(ctx context.Context) error {
+				if err := s.payments.UpdatePaymentStatus(ctx, pay.ID, domain.PaymentStatusApproved, &adminID, ""); err != nil {
+					return err
+				}
+				return s.postApprovedPayment(ctx, pay)
+			})
+			if err != nil {
+				return err
+			}
 		}
 	}
 	if s.audit != nil {
@@ -1092,6 +1156,9 @@ func (s *OrderService) RejectOrder(ctx context.Context, adminID int64, orderID i
 	for _, item := range items {
 		_ = s.items.UpdateOrderItemStatus(ctx, item.ID, domain.OrderItemStatusRejected)
 	}
+	if s.inventory != nil {
+		_ = s.inventory.Release(ctx, order.ID)
+	}
 	if s.payments != nil {
 		pays, _ := s.payments.ListPaymentsByOrder(ctx, order.ID)
 		for _, pay := range pays {
@@ -1296,6 +1363,9 @@ func (s *OrderService) provisionItem(ctx context.Context, order domain.Order, it
 		months = 1
 	}
 	expireAt := time.Now().AddDate(0, months, 0)
+	if trialEnd, ok := s.trialExpireAt(ctx, item.ID); ok {
+		expireAt = trialEnd
+	}
 	req := AutomationCreateHostRequest{
 		LineID:     plan.LineID,
 		OS:         img.Name,
@@ -1308,6 +1378,8 @@ func (s *OrderService) provisionItem(ctx context.Context, order domain.Order, it
 		HostName:   hostName,
 		SysPwd:     sysPwd,
 		VNCPwd:     vncPwd,
+		SSHKeys:    s.provisionSSHKeys(ctx, order.UserID, spec),
+		UserData:   spec.UserData,
 	}
 	res, err := cli.CreateHost(ctx, req)
 	if err != nil {
@@ -1330,6 +1402,9 @@ func (s *OrderService) provisionItem(ctx context.Context, order domain.Order, it
 		s.logAutomation(ctx, order.ID, item.ID, "create_host", req, res.Raw, false, "host id not found")
 		return domain.VPSInstance{}, domain.ErrHostIDNotFound
 	}
+	if s.inventory != nil {
+		_ = s.inventory.Commit(ctx, item.ID)
+	}
 	info, err := s.waitHostActive(ctx, cli, hostID, 5, 6*time.Second)
 	if err != nil {
 		if errors.Is(err, ErrProvisioning) {
@@ -1435,6 +1510,9 @@ func (s *OrderService) handleRenew(ctx context.Context, item domain.OrderItem) e
 	}
 	_ = s.vps.UpdateInstanceExpireAt(ctx, inst.ID, next)
 	_ = s.vps.UpdateInstanceSpec(ctx, inst.ID, setCurrentPeriod(inst.SpecJSON, time.Now(), next))
+	if s.trials != nil && inst.OrderItemID > 0 {
+		_ = s.trials.MarkConverted(ctx, inst.OrderItemID)
+	}
 	s.logAutomation(ctx, item.OrderID, item.ID, "renew", map[string]any{"host_id": hostID, "next_due": next}, map[string]any{"status": "ok"}, true, "ok")
 	return nil
 }
@@ -1572,7 +1650,7 @@ func (s *OrderService) handleResize(ctx context.Context, item domain.OrderItem)
 		inst.DiskGB = disk
 		inst.BandwidthMB = bw
 		inst.PortNum = pkg.PortNum
-		inst.MonthlyPrice = pkg.Monthly
+		inst.MonthlyPrice = pkg.Monthly + parseCartSpecJSON(inst.SpecJSON).AddonsMonthly()
 		if plan.ID > 0 {
 			inst.MonthlyPrice += int64(payload.Spec.AddCores)*plan.UnitCore +
 				int64(payload.Spec.AddMemGB)*plan.UnitMem +
@@ -1591,7 +1669,14 @@ func (s *OrderService) handleResize(ctx context.Context, item domain.OrderItem)
 			return err
 		}
 		if !exists {
-			if _, err := s.wallets.AdjustWalletBalance(ctx, inst.UserID, payload.RefundAmount, "credit", refType, item.OrderID, fmt.Sprintf("resize refund order %d", item.OrderID)); err != nil {
+			memo := fmt.Sprintf("resize refund order %d", item.OrderID)
+			err := s.inTx(ctx, func(ctx context.Context) error {
+				if _, err := s.wallets.AdjustWalletBalance(ctx, inst.UserID, payload.RefundAmount, "credit", refType, item.OrderID, memo); err != nil {
+					return err
+				}
+				return s.postWalletRefund(ctx, inst.UserID, refType, item.OrderID, payload.RefundAmount, memo)
+			})
+			if err != nil {
 				return err
 			}
 		}
@@ -1729,21 +1814,27 @@ func (s *OrderService) createAndApproveWalletRefund(ctx context.Context, userID
 		Note:     strings.TrimSpace(note),
 		MetaJSON: mustJSON(meta),
 	}
-	if err := walletOrders.CreateWalletOrder(ctx, &order); err != nil {
-		return err
-	}
-	if _, err := s.wallets.AdjustWalletBalance(ctx, userID, amount, "credit", txRefType, txRefID, fmt.Sprintf("refund wallet order %d", order.ID)); err != nil {
-		alreadyDone := false
-		if txRefType != "" && txRefID > 0 {
-			if exists, checkErr := s.wallets.HasWalletTransaction(ctx, userID, txRefType, txRefID); checkErr == nil && exists {
-				alreadyDone = true
+	err := s.inTx(ctx, func(ctx context.Context) error {
+		if err := walletOrders.CreateWalletOrder(ctx, &order); err != nil {
+			return err
+		}
+		if _, err := s.wallets.AdjustWalletBalance(ctx, userID, amount, "credit", txRefType, txRefID, fmt.Sprintf("refund wallet order %d", order.ID)); err != nil {
+			alreadyDone := false
+			if txRefType != "" && txRefID > 0 {
+				if exists, checkErr := s.wallets.HasWalletTransaction(ctx, userID, txRefType, txRefID); checkErr == nil && exists {
+					alreadyDone = true
+				}
+			}
+			if !alreadyDone {
+				return err
 			}
 		}
-		if !alreadyDone {
+		if err := walletOrders.UpdateWalletOrderStatus(ctx, order.ID, domain.WalletOrderApproved, nil, ""); err != nil {
 			return err
 		}
-	}
-	if err := walletOrders.UpdateWalletOrderStatus(ctx, order.ID, domain.WalletOrderApproved, nil, ""); err != nil {
+		return s.postWalletRefund(ctx, userID, "wallet_order", order.ID, amount, fmt.Sprintf("refund wallet order %d", order.ID))
+	})
+	if err != nil {
 		return err
 	}
 	if s.audit != nil {
@@ -1875,7 +1966,7 @@ func (s *OrderService) buildRobotItems(ctx context.Context, items []domain.Order
 }
 
 func (s *OrderService) notifyOrderActive(ctx context.Context, userID int64, orderNo string) {
-	if s.email == nil || s.settings == nil {
+	if s.messages == nil || s.settings == nil {
 		return
 	}
 	setting, err := s.settings.GetSetting(ctx, "email_enabled")
@@ -1909,11 +2000,11 @@ func (s *OrderService) notifyOrderActive(ctx context.Context, userID int64, orde
 	}
 	subject = RenderTemplate(subject, data, false)
 	body = RenderTemplate(body, data, IsHTMLContent(body))
-	_ = s.email.Send(ctx, user.Email, subject, body)
+	_ = s.messages.SendEmail(ctx, userID, "provision_success", subject, body)
 }
 
 func (s *OrderService) notifyOrderDecision(ctx context.Context, userID int64, orderNo string, tmplName string, defaultSubject string, message string) {
-	if s.email == nil || s.settings == nil {
+	if s.messages == nil || s.settings == nil {
 		return
 	}
 	setting, err := s.settings.GetSetting(ctx, "email_enabled")
@@ -1948,7 +2039,7 @@ func (s *OrderService) notifyOrderDecision(ctx context.Context, userID int64, or
 	}
 	subject = RenderTemplate(subject, data, false)
 	body = RenderTemplate(body, data, IsHTMLContent(body))
-	_ = s.email.Send(ctx, user.Email, subject, body)
+	_ = s.messages.SendEmail(ctx, userID, tmplName, subject, body)
 }
 
 func (s *OrderService) priceForPackage(ctx context.Context, userID int64, packageID int64, spec CartSpec) (int64, int, error) {
@@ -1960,20 +2051,68 @@ func (s *OrderService) priceForPackage(ctx context.Context, userID int64, packag
 	if err != nil {
 		return 0, 0, err
 	}
-	total, _, _, _, _, _, months, err := s.priceBreakdownForPackage(ctx, userID, pkg, plan, spec)
+	price, err := s.priceBreakdownForPackage(ctx, userID, pkg, plan, &spec)
 	if err != nil {
 		return 0, 0, err
 	}
-	return total, months, nil
-}
-
-func (s *OrderService) priceBreakdownForPackage(ctx context.Context, userID int64, pkg domain.Package, plan domain.PlanGroup, spec CartSpec) (int64, int64, int64, int64, int64, int64, int, error) {
-	if err := validateAddonSpec(spec, plan); err != nil {
-		return 0, 0, 0, 0, 0, 0, 0, err
-	}
-	months, multiplier, err := resolveBillingCycle(ctx, s.billing, spec)
+	return price.Total, price.Months, nil
+}
+
+// priceBreakdown is the per-unit price of one order line over its billing
+// cycle, split the way coupon rules can target it.
+type priceBreakdown struct {
+	Total  int64
+	Base   int64
+	Core   int64
+	Mem    int64
+	Disk   int64
+	BW     int64
+	Addons map[int64]int64
+	Months int
+}
+
+func (p priceBreakdown) addonAmount() int64 {
+	amount := p.Core + p.Mem + p.Disk + p.BW
+	for _, v := range p.Addons {
+		amount += v
+	}
+	return amount
+}
+
+func (p priceBreakdown) quoteItem(pkg domain.Package, plan domain.PlanGroup, spec CartSpec, qty int) appcoupon.QuoteItem {
+	return appcoupon.QuoteItem{
+		PackageID:       pkg.ID,
+		GoodsTypeID:     pkg.GoodsTypeID,
+		RegionID:        plan.RegionID,
+		PlanGroupID:     plan.ID,
+		AddonCore:       spec.AddCores,
+		AddonMemGB:      spec.AddMemGB,
+		AddonDiskGB:     spec.AddDiskGB,
+		AddonBWMbps:     spec.AddBWMbps,
+		UnitBaseAmount:  p.Base,
+		UnitAddonAmount: p.addonAmount(),
+		UnitAddonCore:   p.Core,
+		UnitAddonMem:    p.Mem,
+		UnitAddonDisk:   p.Disk,
+		UnitAddonBW:     p.BW,
+		UnitAddonSKUs:   p.Addons,
+		UnitTotalAmount: p.Total,
+		Qty:             qty,
+	}
+}
+
+// priceBreakdownForPackage prices spec for the buyer. The addon SKUs in spec
+// are resolved in place so the stored spec carries their prices.
+func (s *OrderService) priceBreakdownForPackage(ctx context.Context, userID int64, pkg domain.Package, plan domain.PlanGroup, spec *CartSpec) (priceBreakdown, error) {
+	if err := validateAddonSpec(*spec, plan); err != nil {
+		return priceBreakdown{}, err
+	}
+	if err := s.resolveSpecAddons(ctx, userID, plan, spec); err != nil {
+		return priceBreakdown{}, err
+	}
+	months, multiplier, err := resolveBillingCycle(ctx, s.billing, *spec)
 	if err != nil {
-		return 0, 0, 0, 0, 0, 0, 0, err
+		return priceBreakdown{}, err
 	}
 	baseMonthly := pkg.Monthly
 	unitCore := plan.UnitCore
@@ -1998,9 +2137,29 @@ func (s *OrderService) priceBreakdownForPackage(ctx context.Context, userID int6
 	memAmount := int64(math.Round(float64(memMonthly) * multiplier))
 	diskAmount := int64(math.Round(float64(diskMonthly) * multiplier))
 	bwAmount := int64(math.Round(float64(bwMonthly) * multiplier))
-	addonAmount := coreAmount + memAmount + diskAmount + bwAmount
-	total := baseAmount + addonAmount
-	return total, baseAmount, coreAmount, memAmount, diskAmount, bwAmount, months, nil
+	price := priceBreakdown{
+		Base:   baseAmount,
+		Core:   coreAmount,
+		Mem:    memAmount,
+		Disk:   diskAmount,
+		BW:     bwAmount,
+		Months: months,
+	}
+	if len(spec.Addons) > 0 {
+		price.Addons = make(map[int64]int64, len(spec.Addons))
+		for _, item := range spec.Addons {
+			price.Addons[item.AddonID] += int64(math.Round(float64(item.Monthly*int64(item.Qty)) * multiplier))
+		}
+	}
+	price.Total = baseAmount + price.addonAmount()
+	listMonthly := pkg.Monthly + int64(spec.AddCores)*plan.UnitCore + int64(spec.AddMemGB)*plan.UnitMem +
+		int64(spec.AddDiskGB)*plan.UnitDisk + int64(spec.AddBWMbps)*plan.UnitBW
+	tierMonthly := baseMonthly + coreMonthly + memMonthly + diskMonthly + bwMonthly
+	spec.TierDiscount = 0
+	if discount := int64(math.Round(float64(listMonthly-tierMonthly) * multiplier)); discount > 0 {
+		spec.TierDiscount = discount
+	}
+	return price, nil
 }
 
 func resolveBillingCycle(ctx context.Context, billing BillingCycleRepository, spec CartSpec) (int, float64, error) {
@@ -2103,7 +2262,8 @@ func (s *OrderService) buildVPSLocalSnapshot(ctx context.Context, userID int64,
 			addon = int64(spec.AddCores)*unitCore +
 				int64(spec.AddMemGB)*unitMem +
 				int64(spec.AddDiskGB)*unitDisk +
-				int64(spec.AddBWMbps)*unitBW
+				int64(spec.AddBWMbps)*unitBW +
+				spec.AddonsMonthly()
 		}
 		snap.MonthlyPrice += addon
 		if region, err := s.catalog.GetRegion(ctx, plan.RegionID); err == nil {
@@ -2369,9 +2529,18 @@ func (s *OrderService) CreateRenewOrder(ctx context.Context, userID int64, vpsID
 	if amount < 0 {
 		return domain.Order{}, ErrInvalidInput
 	}
+	if err := s.requireCompany(ctx, userID, "renew_vps", appshared.RealNameActionScope{GoodsTypeIDs: []int64{s.capabilityPolicyGoodsTypeID(ctx, inst)}, Amount: amount}); err != nil {
+		return domain.Order{}, err
+	}
 	if renewDays <= 0 {
 		renewDays = 30
 	}
+	renewSpec := map[string]any{"vps_id": vpsID, "renew_days": renewDays, "duration_months": months}
+	// Addon SKUs bought with the instance are part of MonthlyPrice and renew
+	// with it; list them on the item so the invoice shows what is billed.
+	if addons := parseCartSpecJSON(inst.SpecJSON).Addons; len(addons) > 0 {
+		renewSpec["addons"] = addons
+	}
 	orderNo := fmt.Sprintf("REN-%d-%d", userID, time.Now().Unix())
 	status := domain.OrderStatusPendingPayment
 	itemStatus := domain.OrderItemStatusPendingPayment
@@ -2396,7 +2565,7 @@ func (s *OrderService) CreateRenewOrder(ctx context.Context, userID int64, vpsID
 		Amount:   amount,
 		Status:   itemStatus,
 		Action:   "renew",
-		SpecJSON: mustJSON(map[string]any{"vps_id": vpsID, "renew_days": renewDays, "duration_months": months}),
+		SpecJSON: mustJSON(renewSpec),
 	}
 	if err := s.items.CreateOrderItems(ctx, []domain.OrderItem{item}); err != nil {
 		return domain.Order{}, err
@@ -2442,6 +2611,10 @@ func (s *OrderService) CreateEmergencyRenewOrder(ctx context.Context, userID int
 	if !emergencyRenewInWindow(time.Now(), inst.ExpireAt, policy.WindowDays) {
 		return domain.Order{}, ErrForbidden
 	}
+	// A trial is only kept by paying for a regular renewal.
+	if s.isActiveTrial(ctx, inst) {
+		return domain.Order{}, ErrForbidden
+	}
 	if inst.LastEmergencyRenewAt != nil {
 		if time.Since(*inst.LastEmergencyRenewAt) < time.Duration(policy.IntervalHours)*time.Hour {
 			return domain.Order{}, ErrConflict
@@ -2530,7 +2703,7 @@ func (s *OrderService) CreateResizeOrder(ctx context.Context, userID int64, vpsI
 	if !isResizeAllowed(inst, resizeDefault) {
 		return domain.Order{}, ResizeQuote{}, ErrResizeDisabled
 	}
-	if inst.UserID != userID {
+	if inst.UserID != userID || s.isActiveTrial(ctx, inst) {
 		return domain.Order{}, ResizeQuote{}, ErrForbidden
 	}
 	if s.items != nil {
@@ -0,0 +1,50 @@
+package order
+
+import (
+	"context"
+
+	"xiaoheiplay/internal/domain"
+)
+
+type sshKeyResolver interface {
+	Validate(ctx context.Context, userID int64, ids []int64) error
+	List(ctx context.Context, userID int64) ([]domain.SSHKey, error)
+}
+
+func (s *OrderService) SetSSHKeyService(keys sshKeyResolver) {
+	s.sshKeys = keys
+}
+
+// validateSSHKeys makes sure the picked keys belong to the buyer.
+func (s *OrderService) validateSSHKeys(ctx context.Context, userID int64, spec CartSpec) error {
+	if len(spec.SSHKeyIDs) == 0 {
+		return nil
+	}
+	if s.sshKeys == nil {
+		return domain.ErrSSHKeysNotSupported
+	}
+	return s.sshKeys.Validate(ctx, userID, spec.SSHKeyIDs)
+}
+
+// provisionSSHKeys resolves the keys picked at checkout. Keys deleted since
+// then are skipped rather than failing the provision.
+func (s *OrderService) provisionSSHKeys(ctx context.Context, userID int64, spec CartSpec) []string {
+	if len(spec.SSHKeyIDs) == 0 || s.sshKeys == nil {
+		return nil
+	}
+	keys, err := s.sshKeys.List(ctx, userID)
+	if err != nil {
+		return nil
+	}
+	byID := make(map[int64]string, len(keys))
+	for _, key := range keys {
+		byID[key.ID] = key.PublicKey
+	}
+	var out []string
+	for _, id := range spec.SSHKeyIDs {
+		if line, ok := byID[id]; ok {
+			out = append(out, line)
+		}
+	}
+	return out
+}
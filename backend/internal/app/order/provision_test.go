@@ -6,6 +6,7 @@ import (
 	"time"
 	apporder "xiaoheiplay/internal/app/order"
 	appshared "xiaoheiplay/internal/app/shared"
+	apptrial "xiaoheiplay/internal/app/trial"
 	"xiaoheiplay/internal/domain"
 	"xiaoheiplay/internal/testutil"
 )
@@ -39,7 +40,7 @@ func TestOrderService_ApproveProvisionCreatesVPS(t *testing.T) {
 		},
 	}
 	autoResolver := &testutil.FakeAutomationResolver{Client: fakeAuto}
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 
 	if err := svc.ApproveOrder(context.Background(), 1, order.ID); err != nil {
 		t.Fatalf("approve order: %v", err)
@@ -88,7 +89,7 @@ func TestOrderService_ApproveProvisionFailure(t *testing.T) {
 		CreateHostErr: context.DeadlineExceeded,
 	}
 	autoResolver := &testutil.FakeAutomationResolver{Client: fakeAuto}
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 
 	if err := svc.ApproveOrder(context.Background(), 1, order.ID); err != nil {
 		t.Fatalf("approve order: %v", err)
@@ -106,3 +107,71 @@ func TestOrderService_ApproveProvisionFailure(t *testing.T) {
 	}
 	t.Fatalf("expected item failed")
 }
+
+func TestOrderService_TrialProvisionAndConversion(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	seed := testutil.SeedCatalog(t, repo)
+	pkg := seed.Package
+	pkg.TrialDays = 3
+	if err := repo.UpdatePackage(ctx, pkg); err != nil {
+		t.Fatalf("update package: %v", err)
+	}
+	user := testutil.CreateUser(t, repo, "trial", "trial@example.com", "pass")
+	user.Phone = "13800000000"
+	if err := repo.UpdateUser(ctx, user); err != nil {
+		t.Fatalf("update user: %v", err)
+	}
+	if err := repo.CreateRealNameVerification(ctx, &domain.RealNameVerification{UserID: user.ID, RealName: "张三", IDNumber: "110101199001010011", Status: "verified"}); err != nil {
+		t.Fatalf("create realname: %v", err)
+	}
+
+	fakeAuto := &testutil.FakeAutomationClient{
+		CreateHostResult: appshared.AutomationCreateHostResult{HostID: 1001},
+		HostInfo: map[int64]appshared.AutomationHostInfo{
+			1001: {HostID: 1001, HostName: "host", State: 2, RemoteIP: "1.1.1.1"},
+		},
+	}
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, &testutil.FakeAutomationResolver{Client: fakeAuto}, nil, repo, repo, repo, repo, repo, nil, nil, nil)
+	trials := apptrial.NewService(repo, repo, repo, repo, repo, svc)
+	svc.SetTrialService(trials)
+
+	trial, err := trials.Start(ctx, user.ID, apptrial.StartInput{PackageID: pkg.ID, SystemID: seed.SystemImage.ID})
+	if err != nil {
+		t.Fatalf("start trial: %v", err)
+	}
+	order, err := repo.GetOrder(ctx, trial.OrderID)
+	if err != nil || order.TotalAmount != 0 {
+		t.Fatalf("expected zero priced order, got %+v err=%v", order, err)
+	}
+	var inst domain.VPSInstance
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if inst, err = repo.GetInstanceByOrderItem(ctx, trial.OrderItemID); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if inst.ID == 0 || inst.ExpireAt == nil || !inst.ExpireAt.Equal(trial.ExpireAt) {
+		t.Fatalf("expected instance to expire with the trial at %v, got %+v", trial.ExpireAt, inst.ExpireAt)
+	}
+	if inst.MonthlyPrice != pkg.Monthly {
+		t.Fatalf("expected renewal at package price, got %d", inst.MonthlyPrice)
+	}
+
+	renew, err := svc.CreateRenewOrder(ctx, user.ID, inst.ID, 0, 1)
+	if err != nil {
+		t.Fatalf("renew order: %v", err)
+	}
+	if err := svc.ApproveOrder(ctx, 1, renew.ID); err != nil {
+		t.Fatalf("approve renew: %v", err)
+	}
+	deadline = time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if got, ok := trials.TrialForOrderItem(ctx, trial.OrderItemID); ok && got.Status == domain.VPSTrialStatusConverted {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("expected trial converted after renewal")
+}
@@ -55,7 +55,7 @@ func TestProvisionWorker_MarksFailedOnCreateFailedState(t *testing.T) {
 		},
 	}
 	autoResolver := &testutil.FakeAutomationResolver{Client: fakeAuto}
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 
 	if err := svc.ProcessProvisionJobs(context.Background(), 10); err != nil {
 		t.Fatalf("process jobs: %v", err)
@@ -88,7 +88,7 @@ func TestProvisionWorker_DoneWhenOrderMissing(t *testing.T) {
 		t.Fatalf("create job: %v", err)
 	}
 	autoResolver := &testutil.FakeAutomationResolver{Client: &testutil.FakeAutomationClient{}}
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 
 	if err := svc.ProcessProvisionJobs(context.Background(), 10); err != nil {
 		t.Fatalf("process jobs: %v", err)
@@ -121,7 +121,7 @@ func TestProvisionWorker_Reconcile_RepairsPrematureProvisioning(t *testing.T) {
 	}
 
 	autoResolver := &testutil.FakeAutomationResolver{Client: &testutil.FakeAutomationClient{}}
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 
 	if _, err := svc.ReconcileProvisioningOrders(context.Background(), 20); err != nil {
 		t.Fatalf("reconcile: %v", err)
@@ -199,7 +199,7 @@ func TestProvisionWorker_UpdatesInstanceAutomationIDOnRetryHostSwitch(t *testing
 		},
 	}
 	autoResolver := &testutil.FakeAutomationResolver{Client: fakeAuto}
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 
 	if err := svc.ProcessProvisionJobs(context.Background(), 10); err != nil {
 		t.Fatalf("process jobs: %v", err)
@@ -328,7 +328,7 @@ func (f *fakeLifecycleAutomationClient) ShutdownHost(ctx context.Context, hostID
 func (f *fakeLifecycleAutomationClient) RebootHost(ctx context.Context, hostID int64) error {
 	return nil
 }
-func (f *fakeLifecycleAutomationClient) ResetOS(ctx context.Context, hostID int64, templateID int64, password string) error {
+func (f *fakeLifecycleAutomationClient) ResetOS(ctx context.Context, req AutomationResetOSRequest) error {
 	return nil
 }
 func (f *fakeLifecycleAutomationClient) ResetOSPassword(ctx context.Context, hostID int64, password string) error {
@@ -367,6 +367,9 @@ func (f *fakeLifecycleAutomationClient) AddFirewallRule(ctx context.Context, req
 func (f *fakeLifecycleAutomationClient) DeleteFirewallRule(ctx context.Context, hostID int64, ruleID int64) error {
 	return nil
 }
+func (f *fakeLifecycleAutomationClient) SetReverseDNS(ctx context.Context, hostID int64, ip string, ptr string) error {
+	return nil
+}
 func (f *fakeLifecycleAutomationClient) ListPortMappings(ctx context.Context, hostID int64) ([]AutomationPortMapping, error) {
 	return nil, nil
 }
@@ -424,7 +427,7 @@ func TestCreateEmergencyRenewOrder_AutoApproveAndZeroAmount(t *testing.T) {
 	orders := &fakeLifecycleOrderRepo{}
 	items := &fakeLifecycleOrderItemRepo{}
 	automation := &fakeLifecycleAutomationClient{}
-	svc := NewOrderService(orders, items, nil, nil, nil, nil, vpsRepo, nil, nil, nil, automation, nil, nil, nil, nil, settings, nil, nil, nil, nil, nil)
+	svc := NewOrderService(orders, items, nil, nil, nil, nil, vpsRepo, nil, nil, nil, automation, nil, nil, nil, settings, nil, nil, nil, nil, nil)
 
 	order, err := svc.CreateEmergencyRenewOrder(context.Background(), inst.UserID, inst.ID)
 	if err != nil {
@@ -462,7 +465,7 @@ func TestHandleEmergencyRenew_RespectsWindowAndUpdatesExpire(t *testing.T) {
 	}
 	vpsRepo := &fakeLifecycleVPSRepo{inst: inst}
 	automation := &fakeLifecycleAutomationClient{}
-	svc := NewOrderService(&fakeLifecycleOrderRepo{}, &fakeLifecycleOrderItemRepo{}, nil, nil, nil, nil, vpsRepo, nil, nil, nil, automation, nil, nil, nil, nil, settings, nil, nil, nil, nil, nil)
+	svc := NewOrderService(&fakeLifecycleOrderRepo{}, &fakeLifecycleOrderItemRepo{}, nil, nil, nil, nil, vpsRepo, nil, nil, nil, automation, nil, nil, nil, settings, nil, nil, nil, nil, nil)
 
 	item := domain.OrderItem{
 		OrderID:  10,
@@ -501,7 +504,7 @@ func TestCreateEmergencyRenewOrder_ForbiddenOutsideWindow(t *testing.T) {
 		},
 	}
 	vpsRepo := &fakeLifecycleVPSRepo{inst: inst}
-	svc := NewOrderService(&fakeLifecycleOrderRepo{}, &fakeLifecycleOrderItemRepo{}, nil, nil, nil, nil, vpsRepo, nil, nil, nil, &fakeLifecycleAutomationClient{}, nil, nil, nil, nil, settings, nil, nil, nil, nil, nil)
+	svc := NewOrderService(&fakeLifecycleOrderRepo{}, &fakeLifecycleOrderItemRepo{}, nil, nil, nil, nil, vpsRepo, nil, nil, nil, &fakeLifecycleAutomationClient{}, nil, nil, nil, settings, nil, nil, nil, nil, nil)
 
 	if _, err := svc.CreateEmergencyRenewOrder(context.Background(), inst.UserID, inst.ID); err != ErrForbidden {
 		t.Fatalf("expected forbidden, got %v", err)
@@ -532,7 +535,7 @@ func TestCreateEmergencyRenewOrder_RequiresRealNameWhenRenewBlocked(t *testing.T
 	}
 	realnameSvc := apprealname.NewService(&fakeLifecycleRealNameRepo{}, nil, settings)
 	vpsRepo := &fakeLifecycleVPSRepo{inst: inst}
-	svc := NewOrderService(&fakeLifecycleOrderRepo{}, &fakeLifecycleOrderItemRepo{}, nil, nil, nil, nil, vpsRepo, nil, nil, nil, &fakeLifecycleAutomationClient{}, nil, nil, nil, nil, settings, nil, nil, nil, nil, realnameSvc)
+	svc := NewOrderService(&fakeLifecycleOrderRepo{}, &fakeLifecycleOrderItemRepo{}, nil, nil, nil, nil, vpsRepo, nil, nil, nil, &fakeLifecycleAutomationClient{}, nil, nil, nil, settings, nil, nil, nil, nil, realnameSvc)
 
 	if _, err := svc.CreateEmergencyRenewOrder(context.Background(), inst.UserID, inst.ID); err != ErrRealNameRequired {
 		t.Fatalf("expected real name required, got %v", err)
@@ -393,7 +393,6 @@ func newResizeOrderService(inst domain.VPSInstance, catalog *fakeResizeCatalogRe
 		nil,
 		nil,
 		nil,
-		nil,
 		settings,
 		nil,
 		nil,
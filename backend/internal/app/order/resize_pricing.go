@@ -59,7 +59,10 @@ func (s *OrderService) quoteResize(ctx context.Context, inst domain.VPSInstance,
 		int64(currentSpec.AddMemGB)*currentUnitMem +
 		int64(currentSpec.AddDiskGB)*currentUnitDisk +
 		int64(currentSpec.AddBWMbps)*currentUnitBW
-	currentMonthly := currentBase + currentAddon
+	// Addon SKUs are not changed by a resize; they only keep the monthly
+	// totals comparable with the instance's MonthlyPrice.
+	skuMonthly := currentSpec.AddonsMonthly()
+	currentMonthly := currentBase + currentAddon + skuMonthly
 
 	quote := ResizeQuote{
 		RefundToWallet: policy.RefundToWallet,
@@ -92,6 +95,7 @@ func (s *OrderService) quoteResize(ctx context.Context, inst domain.VPSInstance,
 	if spec != nil {
 		targetSpec = *spec
 	}
+	targetSpec.Addons = currentSpec.Addons
 
 	if err := normalizeCartSpec(&targetSpec); err != nil {
 		return ResizeQuote{}, CartSpec{}, err
@@ -122,7 +126,7 @@ func (s *OrderService) quoteResize(ctx context.Context, inst domain.VPSInstance,
 		int64(targetSpec.AddMemGB)*targetUnitMem +
 		int64(targetSpec.AddDiskGB)*targetUnitDisk +
 		int64(targetSpec.AddBWMbps)*targetUnitBW
-	targetMonthly := targetBase + targetAddon
+	targetMonthly := targetBase + targetAddon + skuMonthly
 	quote.TargetCPU = targetPkg.Cores + targetSpec.AddCores
 	quote.TargetMemGB = targetPkg.MemoryGB + targetSpec.AddMemGB
 	quote.TargetDiskGB = targetPkg.DiskGB + targetSpec.AddDiskGB
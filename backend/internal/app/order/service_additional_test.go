@@ -50,7 +50,7 @@ func TestOrderService_RefreshOrderUpdates(t *testing.T) {
 		},
 	}
 	autoResolver := &testutil.FakeAutomationResolver{Client: fakeAuto}
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 	updated, err := svc.RefreshOrder(context.Background(), user.ID, order.ID)
 	if err != nil || len(updated) != 1 {
 		t.Fatalf("refresh order: %v %d", err, len(updated))
@@ -67,7 +67,7 @@ func TestOrderService_RetryProvisionConflict(t *testing.T) {
 	if err := repo.CreateOrder(context.Background(), &order); err != nil {
 		t.Fatalf("create order: %v", err)
 	}
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 	if err := svc.RetryProvision(order.ID); err != appshared.ErrConflict {
 		t.Fatalf("expected conflict, got %v", err)
 	}
@@ -112,7 +112,7 @@ func TestOrderService_RetryProvisionFailedOrderBlockedByNewExclusiveOrder(t *tes
 		t.Fatalf("create new item: %v", err)
 	}
 
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 	if err := svc.RetryProvision(failedOrder.ID); err != appshared.ErrConflict {
 		t.Fatalf("expected conflict, got %v", err)
 	}
@@ -134,7 +134,7 @@ func TestOrderService_RejectOrderWithPayment(t *testing.T) {
 		t.Fatalf("create payment: %v", err)
 	}
 
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 	if err := svc.RejectOrder(context.Background(), 1, order.ID, "bad"); err != nil {
 		t.Fatalf("reject order: %v", err)
 	}
@@ -15,7 +15,7 @@ func TestOrderService_CreateOrderFromCartInvalid(t *testing.T) {
 	_, repo := testutil.NewTestDB(t, false)
 	user := testutil.CreateUser(t, repo, "cartbad", "cartbad@example.com", "pass")
 
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 	if _, _, err := svc.CreateOrderFromCart(context.Background(), user.ID, "CNY", "", ""); err != appshared.ErrInvalidInput {
 		t.Fatalf("expected invalid input, got %v", err)
 	}
@@ -26,7 +26,7 @@ func TestOrderService_CreateOrderFromItemsInvalidSpec(t *testing.T) {
 	seed := testutil.SeedCatalog(t, repo)
 	user := testutil.CreateUser(t, repo, "specbad", "specbad@example.com", "pass")
 
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 	_, _, err := svc.CreateOrderFromItems(context.Background(), user.ID, "CNY", []appshared.OrderItemInput{
 		{PackageID: seed.Package.ID, SystemID: seed.SystemImage.ID, Qty: 1, Spec: appshared.CartSpec{AddCores: -1}},
 	}, "", "")
@@ -45,7 +45,7 @@ func TestOrderService_CreateOrderFromItemsInvalidBillingCycle(t *testing.T) {
 		t.Fatalf("create cycle: %v", err)
 	}
 
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 	_, _, err := svc.CreateOrderFromItems(context.Background(), user.ID, "CNY", []appshared.OrderItemInput{
 		{PackageID: seed.Package.ID, SystemID: seed.SystemImage.ID, Qty: 1, Spec: appshared.CartSpec{BillingCycleID: cycle.ID, CycleQty: 1}},
 	}, "", "")
@@ -78,7 +78,7 @@ func TestOrderService_CreateOrderFromCartValidatesAddonLimit(t *testing.T) {
 		t.Fatalf("add cart: %v", err)
 	}
 
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 	if _, _, err := svc.CreateOrderFromCart(context.Background(), user.ID, "CNY", "", ""); err != appshared.ErrInvalidInput {
 		t.Fatalf("expected invalid input, got %v", err)
 	}
@@ -97,7 +97,7 @@ func TestOrderService_SubmitPaymentConflicts(t *testing.T) {
 		t.Fatalf("create items: %v", err)
 	}
 
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 	if _, err := svc.SubmitPayment(context.Background(), other.ID, order.ID, appshared.PaymentInput{Method: "manual", Amount: 1000}, ""); err != appshared.ErrForbidden {
 		t.Fatalf("expected forbidden, got %v", err)
 	}
@@ -165,7 +165,7 @@ func TestOrderService_RenewAndResizeFlow(t *testing.T) {
 
 	fakeAuto := &testutil.FakeAutomationClient{}
 	autoResolver := &testutil.FakeAutomationResolver{Client: fakeAuto}
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 	if err := svc.ApproveOrder(context.Background(), 1, renewOrder.ID); err != nil {
 		t.Fatalf("approve renew: %v", err)
 	}
@@ -250,7 +250,7 @@ func TestOrderService_CreateRenewOrder_ZeroInstancePriceAutoApproves(t *testing.
 
 	fakeAuto := &testutil.FakeAutomationClient{}
 	autoResolver := &testutil.FakeAutomationResolver{Client: fakeAuto}
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 
 	order, err := svc.CreateRenewOrder(context.Background(), user.ID, inst.ID, 0, 1)
 	if err != nil {
@@ -352,7 +352,7 @@ func TestOrderService_CreateResizeOrder_NegativeAmountAutoApproves(t *testing.T)
 
 	fakeAuto := &testutil.FakeAutomationClient{}
 	autoResolver := &testutil.FakeAutomationResolver{Client: fakeAuto}
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, nil, repo, repo, repo, repo, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, autoResolver, nil, repo, repo, repo, repo, repo, repo, nil, nil)
 
 	order, quote, err := svc.CreateResizeOrder(context.Background(), user.ID, inst.ID, nil, target.ID, false, nil)
 	if err != nil {
@@ -64,7 +64,7 @@ func TestOrderService_SubmitPaymentRobotItems(t *testing.T) {
 	}
 
 	robot := &testutil.FakeRobotNotifier{}
-	orderSvc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, robot, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	orderSvc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, robot, repo, repo, repo, repo, repo, nil, nil, nil)
 
 	if _, err := orderSvc.SubmitPayment(ctx, user.ID, order.ID, appshared.PaymentInput{
 		Method:   "manual",
@@ -2,10 +2,13 @@ package order_test
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	appcart "xiaoheiplay/internal/app/cart"
+	appinventory "xiaoheiplay/internal/app/inventory"
 	apporder "xiaoheiplay/internal/app/order"
 	appshared "xiaoheiplay/internal/app/shared"
 	"xiaoheiplay/internal/domain"
@@ -17,7 +20,7 @@ func TestOrderService_CreateOrderFromItems(t *testing.T) {
 	seed := testutil.SeedCatalog(t, repo)
 	user := testutil.CreateUser(t, repo, "buyer", "buyer@example.com", "pass")
 
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 	order, items, err := svc.CreateOrderFromItems(context.Background(), user.ID, "CNY", []appshared.OrderItemInput{
 		{PackageID: seed.Package.ID, SystemID: seed.SystemImage.ID, Qty: 1},
 	}, "idem-1", "")
@@ -32,6 +35,32 @@ func TestOrderService_CreateOrderFromItems(t *testing.T) {
 	}
 }
 
+type fixedTierPricer struct {
+	price domain.UserTierPriceCache
+}
+
+func (p fixedTierPricer) ResolvePackagePricing(ctx context.Context, userID, packageID int64) (domain.UserTierPriceCache, int64, error) {
+	return p.price, 1, nil
+}
+
+func TestOrderService_RecordsTierDiscountOnSpec(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	seed := testutil.SeedCatalog(t, repo)
+	user := testutil.CreateUser(t, repo, "tier_buyer", "tier_buyer@example.com", "pass")
+
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
+	svc.SetUserTierPricingResolver(fixedTierPricer{price: domain.UserTierPriceCache{MonthlyPrice: seed.Package.Monthly - 3, UnitCore: 1, UnitMem: 1, UnitDisk: 1, UnitBW: 1}})
+	_, items, err := svc.CreateOrderFromItems(context.Background(), user.ID, "CNY", []appshared.OrderItemInput{
+		{PackageID: seed.Package.ID, SystemID: seed.SystemImage.ID, Qty: 1, Spec: appshared.CartSpec{TierDiscount: 999}},
+	}, "idem-tier", "")
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	if !strings.Contains(items[0].SpecJSON, `"tier_discount":3`) {
+		t.Fatalf("expected server computed tier discount, got %s", items[0].SpecJSON)
+	}
+}
+
 func TestOrderService_SubmitPaymentIdempotent(t *testing.T) {
 	_, repo := testutil.NewTestDB(t, false)
 	user := testutil.CreateUser(t, repo, "pay", "pay@example.com", "pass")
@@ -56,7 +85,7 @@ func TestOrderService_SubmitPaymentIdempotent(t *testing.T) {
 		t.Fatalf("create items: %v", err)
 	}
 
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 	payment, err := svc.SubmitPayment(context.Background(), user.ID, order.ID, appshared.PaymentInput{
 		Method:   "manual",
 		Amount:   1000,
@@ -97,7 +126,7 @@ func TestOrderService_SubmitPayment_RejectsCrossOrderTradeNoReuse(t *testing.T)
 	if err := repo.CreateOrderItems(context.Background(), []domain.OrderItem{{OrderID: orderB.ID, Amount: 2000, Status: domain.OrderItemStatusPendingPayment, Action: "create", SpecJSON: "{}"}}); err != nil {
 		t.Fatalf("create items b: %v", err)
 	}
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 	if _, err := svc.SubmitPayment(context.Background(), user.ID, orderA.ID, appshared.PaymentInput{
 		Method:   "approval",
 		Amount:   1000,
@@ -126,7 +155,7 @@ func TestOrderService_CreateOrderFromCartAndCancel(t *testing.T) {
 		t.Fatalf("add cart: %v", err)
 	}
 
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 	order, items, err := svc.CreateOrderFromCart(context.Background(), user.ID, "CNY", "idem-cart", "")
 	if err != nil {
 		t.Fatalf("create order: %v", err)
@@ -196,7 +225,7 @@ func TestOrderService_CreateRefundOrder_UsesInstanceMonthlyPrice(t *testing.T) {
 		t.Fatalf("create instance: %v", err)
 	}
 
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 	refundOrder, amount, err := svc.CreateRefundOrder(context.Background(), user.ID, inst.ID, "test")
 	if err != nil {
 		t.Fatalf("create refund order: %v", err)
@@ -259,7 +288,7 @@ func TestOrderService_CreateRenewOrder_UsesInstanceMonthlyPrice(t *testing.T) {
 		t.Fatalf("create instance: %v", err)
 	}
 
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 	order, err := svc.CreateRenewOrder(context.Background(), user.ID, inst.ID, 0, 2)
 	if err != nil {
 		t.Fatalf("create renew order: %v", err)
@@ -349,7 +378,7 @@ func TestOrderService_CreateRenewOrder_RejectsOverflowInputs(t *testing.T) {
 				t.Fatalf("create instance: %v", err)
 			}
 
-			svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+			svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 			if _, err := svc.CreateRenewOrder(context.Background(), user.ID, inst.ID, 0, tt.durationMonths); err != appshared.ErrInvalidInput {
 				t.Fatalf("expected invalid input, got %v", err)
 			}
@@ -414,7 +443,7 @@ func TestOrderService_CreateRefundOrder_UserAPIKeySourceAutoApprove(t *testing.T
 	}
 
 	automationResolver := &testutil.FakeAutomationResolver{Client: &testutil.FakeAutomationClient{}}
-	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, automationResolver, nil, repo, repo, nil, repo, repo, repo, nil, nil, nil)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, automationResolver, nil, repo, repo, repo, repo, repo, nil, nil, nil)
 	ctx := apporder.WithOrderSource(context.Background(), apporder.OrderSourceUserAPIKey)
 	refundOrder, amount, err := svc.CreateRefundOrder(ctx, user.ID, inst.ID, "apikey refund")
 	if err != nil {
@@ -445,3 +474,59 @@ func TestOrderService_CreateRefundOrder_UserAPIKeySourceAutoApprove(t *testing.T
 		t.Fatalf("expected wallet credited 3000, delta=%d", wAfter.Balance-wBefore.Balance)
 	}
 }
+
+// racingInventory passes the up-front stock check, as when a concurrent
+// checkout takes the last unit between the check and the reservation.
+type racingInventory struct {
+	*appinventory.Service
+}
+
+func (racingInventory) Check(ctx context.Context, demand map[int64]int) error {
+	return nil
+}
+
+func TestOrderService_CreateOrderFromCart_ReservationFailureKeepsCart(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	seed := testutil.SeedCatalog(t, repo)
+	pkg := seed.Package
+	pkg.CapacityRemaining = 1
+	if err := repo.UpdatePackage(ctx, pkg); err != nil {
+		t.Fatalf("update package: %v", err)
+	}
+	user := testutil.CreateUser(t, repo, "racer", "racer@example.com", "pass")
+	inventory := appinventory.NewService(repo, repo, repo, repo, repo)
+
+	other := domain.Order{UserID: user.ID, OrderNo: "ORD-RACE-OTHER", Status: domain.OrderStatusPendingPayment, Currency: "CNY"}
+	if err := repo.CreateOrder(ctx, &other); err != nil {
+		t.Fatalf("create other order: %v", err)
+	}
+	otherItems := []domain.OrderItem{{OrderID: other.ID, PackageID: pkg.ID, Qty: 1, Status: domain.OrderItemStatusPendingPayment, Action: "create", SpecJSON: "{}"}}
+	if err := repo.CreateOrderItems(ctx, otherItems); err != nil {
+		t.Fatalf("create other items: %v", err)
+	}
+	if err := inventory.Reserve(ctx, other, otherItems); err != nil {
+		t.Fatalf("reserve other: %v", err)
+	}
+
+	cartSvc := appcart.NewService(repo, repo, repo)
+	if _, err := cartSvc.Add(ctx, user.ID, pkg.ID, seed.SystemImage.ID, appshared.CartSpec{}, 1); err != nil {
+		t.Fatalf("add cart: %v", err)
+	}
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
+	svc.SetInventoryService(racingInventory{inventory})
+
+	_, _, err := svc.CreateOrderFromCart(ctx, user.ID, "CNY", "idem-race", "")
+	var shortage *appshared.StockShortageError
+	if !errors.As(err, &shortage) {
+		t.Fatalf("expected stock shortage, got %v", err)
+	}
+	cart, err := repo.ListCartItems(ctx, user.ID)
+	if err != nil || len(cart) != 1 {
+		t.Fatalf("expected cart kept, got %d %v", len(cart), err)
+	}
+	_, total, err := repo.ListOrders(ctx, appshared.OrderFilter{UserID: user.ID}, 10, 0)
+	if err != nil || total != 1 {
+		t.Fatalf("expected only the other order, got %d %v", total, err)
+	}
+}
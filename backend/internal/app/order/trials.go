@@ -0,0 +1,88 @@
+package order
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appcoupon "xiaoheiplay/internal/app/coupon"
+	"xiaoheiplay/internal/domain"
+)
+
+type trialTracker interface {
+	TrialForOrderItem(ctx context.Context, orderItemID int64) (domain.VPSTrial, bool)
+	MarkConverted(ctx context.Context, orderItemID int64) error
+}
+
+func (s *OrderService) SetTrialService(trials trialTracker) {
+	s.trials = trials
+}
+
+// CreateTrialOrder creates a zero-priced order for one trial instance of pkg.
+// The order is left pending review so the caller can record the trial before
+// approving it; provisioning then takes the trial's expiry.
+func (s *OrderService) CreateTrialOrder(ctx context.Context, userID int64, pkg domain.Package, systemID int64) (domain.Order, domain.OrderItem, error) {
+	if pkg.ID <= 0 || systemID <= 0 {
+		return domain.Order{}, domain.OrderItem{}, ErrInvalidInput
+	}
+	if _, err := s.images.GetSystemImage(ctx, systemID); err != nil {
+		return domain.Order{}, domain.OrderItem{}, err
+	}
+	if err := s.checkStock(ctx, []appcoupon.QuoteItem{{PackageID: pkg.ID, GoodsTypeID: pkg.GoodsTypeID, Qty: 1}}); err != nil {
+		return domain.Order{}, domain.OrderItem{}, err
+	}
+	order := domain.Order{
+		UserID:      userID,
+		OrderNo:     fmt.Sprintf("TRL-%d-%d", userID, time.Now().Unix()),
+		Source:      resolveOrderSource(ctx),
+		Status:      domain.OrderStatusPendingReview,
+		TotalAmount: 0,
+		Currency:    "CNY",
+	}
+	if err := s.orders.CreateOrder(ctx, &order); err != nil {
+		return domain.Order{}, domain.OrderItem{}, err
+	}
+	items := []domain.OrderItem{{
+		OrderID:     order.ID,
+		PackageID:   pkg.ID,
+		SystemID:    systemID,
+		SpecJSON:    mustJSON(CartSpec{}),
+		Qty:         1,
+		Amount:      0,
+		Status:      domain.OrderItemStatusPendingReview,
+		GoodsTypeID: pkg.GoodsTypeID,
+		Action:      "create",
+	}}
+	if err := s.items.CreateOrderItems(ctx, items); err != nil {
+		_ = s.orders.DeleteOrder(ctx, order.ID)
+		return domain.Order{}, domain.OrderItem{}, err
+	}
+	if err := s.reserveStock(ctx, order, items); err != nil {
+		return domain.Order{}, domain.OrderItem{}, err
+	}
+	if s.events != nil {
+		_, _ = s.events.Publish(ctx, order.ID, "order.pending_review", map[string]any{"status": order.Status, "total": 0, "trial": true})
+	}
+	return order, items[0], nil
+}
+
+// trialExpireAt returns the hard expiry of a trial item.
+func (s *OrderService) trialExpireAt(ctx context.Context, orderItemID int64) (time.Time, bool) {
+	if s.trials == nil || orderItemID <= 0 {
+		return time.Time{}, false
+	}
+	trial, ok := s.trials.TrialForOrderItem(ctx, orderItemID)
+	if !ok {
+		return time.Time{}, false
+	}
+	return trial.ExpireAt, true
+}
+
+// isActiveTrial reports whether inst still runs on an unconverted trial.
+func (s *OrderService) isActiveTrial(ctx context.Context, inst domain.VPSInstance) bool {
+	if s.trials == nil || inst.OrderItemID <= 0 {
+		return false
+	}
+	trial, ok := s.trials.TrialForOrderItem(ctx, inst.OrderItemID)
+	return ok && trial.Status == domain.VPSTrialStatusActive
+}
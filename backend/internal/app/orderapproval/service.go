@@ -0,0 +1,438 @@
+package orderapproval
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+const (
+	// UnknownCountry is matched by rules when the client IP could not be
+	// resolved to a country.
+	UnknownCountry = "ZZ"
+
+	defaultRefundWindowDays = 30
+	maxRuleNameLen          = 128
+	maxRuleReasonLen        = 500
+	maxRuleCountries        = 100
+	maxDryRunOrders         = 500
+)
+
+type clientKey struct{}
+
+type client struct {
+	ip      string
+	country string
+}
+
+// WithClient attaches the client IP and its ISO country code to the order
+// creation context so the rules can match on them.
+func WithClient(ctx context.Context, ip, country string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, clientKey{}, client{ip: strings.TrimSpace(ip), country: strings.ToUpper(strings.TrimSpace(country))})
+}
+
+func clientFromContext(ctx context.Context) client {
+	if ctx == nil {
+		return client{}
+	}
+	c, _ := ctx.Value(clientKey{}).(client)
+	return c
+}
+
+type DryRunInput struct {
+	OrderIDs []int64
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+	// Rules replaces the saved rules when set, so drafts can be tried out
+	// before saving them.
+	Rules []domain.OrderApprovalRule
+}
+
+type DryRunResult struct {
+	Order     domain.Order
+	Result    domain.OrderApprovalEvaluation
+	Recorded  *domain.OrderApprovalEvaluation
+	Different bool
+}
+
+type DryRunSummary struct {
+	Total   int
+	Approve int
+	Review  int
+	Reject  int
+	Changed int
+	Results []DryRunResult
+}
+
+// Service evaluates purchase orders against the admin-defined risk rules.
+// Rules are checked by priority; the first match decides whether the order
+// is approved automatically after payment, held for review or rejected.
+type Service struct {
+	repo     appports.OrderApprovalRepository
+	orders   appports.OrderRepository
+	items    appports.OrderItemRepository
+	users    appports.UserRepository
+	realname appports.RealNameRepository
+	vps      appports.VPSRepository
+	settings appports.SettingsRepository
+}
+
+func NewService(repo appports.OrderApprovalRepository, orders appports.OrderRepository, items appports.OrderItemRepository, users appports.UserRepository, realname appports.RealNameRepository, vps appports.VPSRepository, settings appports.SettingsRepository) *Service {
+	return &Service{repo: repo, orders: orders, items: items, users: users, realname: realname, vps: vps, settings: settings}
+}
+
+func (s *Service) ListRules(ctx context.Context) ([]domain.OrderApprovalRule, error) {
+	return s.repo.ListOrderApprovalRules(ctx)
+}
+
+func (s *Service) CreateRule(ctx context.Context, rule domain.OrderApprovalRule) (domain.OrderApprovalRule, error) {
+	rule, err := normalizeRule(rule)
+	if err != nil {
+		return domain.OrderApprovalRule{}, err
+	}
+	if err := s.repo.CreateOrderApprovalRule(ctx, &rule); err != nil {
+		return domain.OrderApprovalRule{}, err
+	}
+	return rule, nil
+}
+
+func (s *Service) UpdateRule(ctx context.Context, rule domain.OrderApprovalRule) (domain.OrderApprovalRule, error) {
+	existing, err := s.repo.GetOrderApprovalRule(ctx, rule.ID)
+	if err != nil {
+		return domain.OrderApprovalRule{}, err
+	}
+	rule, err = normalizeRule(rule)
+	if err != nil {
+		return domain.OrderApprovalRule{}, err
+	}
+	rule.CreatedAt = existing.CreatedAt
+	if err := s.repo.UpdateOrderApprovalRule(ctx, rule); err != nil {
+		return domain.OrderApprovalRule{}, err
+	}
+	return s.repo.GetOrderApprovalRule(ctx, rule.ID)
+}
+
+func (s *Service) DeleteRule(ctx context.Context, id int64) error {
+	if _, err := s.repo.GetOrderApprovalRule(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteOrderApprovalRule(ctx, id)
+}
+
+func (s *Service) GetEvaluation(ctx context.Context, orderID int64) (domain.OrderApprovalEvaluation, error) {
+	return s.repo.GetOrderApprovalEvaluation(ctx, orderID)
+}
+
+// EvaluateOrder decides a newly created order with the enabled rules and
+// records the result. The client IP and country come from WithClient.
+func (s *Service) EvaluateOrder(ctx context.Context, order domain.Order) (domain.OrderApprovalEvaluation, error) {
+	rules, err := s.repo.ListOrderApprovalRules(ctx)
+	if err != nil {
+		return domain.OrderApprovalEvaluation{}, err
+	}
+	c := clientFromContext(ctx)
+	facts, err := s.facts(ctx, order, c.ip, c.country)
+	if err != nil {
+		return domain.OrderApprovalEvaluation{}, err
+	}
+	eval := decide(rules, facts)
+	eval.OrderID = order.ID
+	eval.UserID = order.UserID
+	if err := s.repo.CreateOrderApprovalEvaluation(ctx, &eval); err != nil {
+		return domain.OrderApprovalEvaluation{}, err
+	}
+	return eval, nil
+}
+
+// HoldReason reports whether the recorded decision for an order keeps it
+// from being approved automatically.
+func (s *Service) HoldReason(ctx context.Context, orderID int64) (string, bool) {
+	eval, err := s.repo.GetOrderApprovalEvaluation(ctx, orderID)
+	if err != nil || eval.Decision != domain.OrderApprovalReview {
+		return "", false
+	}
+	return eval.Reason, true
+}
+
+// DryRun evaluates historical purchase orders without changing them. Facts
+// are rebuilt as of each order's creation time; the client IP and country
+// are only known for orders that were evaluated when they were placed.
+func (s *Service) DryRun(ctx context.Context, in DryRunInput) (DryRunSummary, error) {
+	rules := in.Rules
+	if rules == nil {
+		saved, err := s.repo.ListOrderApprovalRules(ctx)
+		if err != nil {
+			return DryRunSummary{}, err
+		}
+		rules = saved
+	} else {
+		for i := range rules {
+			rule, err := normalizeRule(rules[i])
+			if err != nil {
+				return DryRunSummary{}, err
+			}
+			rules[i] = rule
+		}
+	}
+	orders, err := s.dryRunOrders(ctx, in)
+	if err != nil {
+		return DryRunSummary{}, err
+	}
+	summary := DryRunSummary{Results: make([]DryRunResult, 0, len(orders))}
+	for _, order := range orders {
+		var recorded *domain.OrderApprovalEvaluation
+		ip, country := "", ""
+		if eval, err := s.repo.GetOrderApprovalEvaluation(ctx, order.ID); err == nil {
+			recorded = &eval
+			ip, country = eval.Facts.ClientIP, eval.Facts.Country
+		}
+		facts, err := s.facts(ctx, order, ip, country)
+		if err != nil {
+			return DryRunSummary{}, err
+		}
+		result := decide(rules, facts)
+		result.OrderID = order.ID
+		result.UserID = order.UserID
+		item := DryRunResult{Order: order, Result: result, Recorded: recorded}
+		if recorded != nil && recorded.Decision != result.Decision {
+			item.Different = true
+			summary.Changed++
+		}
+		switch result.Decision {
+		case domain.OrderApprovalReview:
+			summary.Review++
+		case domain.OrderApprovalReject:
+			summary.Reject++
+		default:
+			summary.Approve++
+		}
+		summary.Results = append(summary.Results, item)
+	}
+	summary.Total = len(summary.Results)
+	return summary, nil
+}
+
+func (s *Service) dryRunOrders(ctx context.Context, in DryRunInput) ([]domain.Order, error) {
+	limit := in.Limit
+	if limit <= 0 || limit > maxDryRunOrders {
+		limit = maxDryRunOrders
+	}
+	var candidates []domain.Order
+	if len(in.OrderIDs) > 0 {
+		if len(in.OrderIDs) > maxDryRunOrders {
+			return nil, appshared.ErrInvalidInput
+		}
+		for _, id := range in.OrderIDs {
+			order, err := s.orders.GetOrder(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			candidates = append(candidates, order)
+		}
+	} else {
+		if in.From == nil || in.To == nil || !in.From.Before(*in.To) {
+			return nil, appshared.ErrInvalidInput
+		}
+		list, _, err := s.orders.ListOrders(ctx, appshared.OrderFilter{From: in.From, To: in.To}, limit, 0)
+		if err != nil {
+			return nil, err
+		}
+		candidates = list
+	}
+	out := make([]domain.Order, 0, len(candidates))
+	for _, order := range candidates {
+		if s.isPurchase(ctx, order.ID) {
+			out = append(out, order)
+		}
+		if len(out) >= limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// isPurchase tells new-instance orders apart from renewals, resizes and
+// refunds, which the rules do not apply to.
+func (s *Service) isPurchase(ctx context.Context, orderID int64) bool {
+	if s.items == nil {
+		return true
+	}
+	items, err := s.items.ListOrderItems(ctx, orderID)
+	if err != nil || len(items) == 0 {
+		return false
+	}
+	for _, item := range items {
+		if item.Action != "create" {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Service) facts(ctx context.Context, order domain.Order, ip, country string) (domain.OrderApprovalFacts, error) {
+	at := order.CreatedAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if country == "" {
+		country = UnknownCountry
+	}
+	facts := domain.OrderApprovalFacts{
+		Amount:   order.TotalAmount,
+		ClientIP: ip,
+		Country:  country,
+	}
+	if s.users != nil {
+		user, err := s.users.GetUserByID(ctx, order.UserID)
+		if err != nil {
+			return domain.OrderApprovalFacts{}, err
+		}
+		if age := at.Sub(user.CreatedAt); age > 0 {
+			facts.AccountAgeDays = int(age.Hours() / 24)
+		}
+	}
+	if s.realname != nil {
+		if latest, err := s.realname.GetLatestRealNameVerification(ctx, order.UserID); err == nil {
+			facts.RealNameVerified = latest.Status == "verified" && (latest.VerifiedAt == nil || !latest.VerifiedAt.After(at))
+		}
+	}
+	if s.vps != nil {
+		instances, err := s.vps.ListInstancesByUser(ctx, order.UserID)
+		if err != nil {
+			return domain.OrderApprovalFacts{}, err
+		}
+		for _, inst := range instances {
+			if inst.CreatedAt.Before(at) {
+				facts.Instances++
+			}
+		}
+	}
+	window := defaultRefundWindowDays
+	if v, ok := getSettingInt(ctx, s.settings, "order_approval_refund_window_days"); ok && v > 0 {
+		window = v
+	}
+	refunds, err := s.repo.CountRefundOrders(ctx, order.UserID, at.AddDate(0, 0, -window), at)
+	if err != nil {
+		return domain.OrderApprovalFacts{}, err
+	}
+	facts.RecentRefunds = refunds
+	return facts, nil
+}
+
+// decide returns the decision of the first enabled rule matching facts,
+// highest priority first. Orders no rule matches are approved.
+func decide(rules []domain.OrderApprovalRule, facts domain.OrderApprovalFacts) domain.OrderApprovalEvaluation {
+	sorted := append([]domain.OrderApprovalRule(nil), rules...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Priority == sorted[j].Priority {
+			return sorted[i].ID < sorted[j].ID
+		}
+		return sorted[i].Priority > sorted[j].Priority
+	})
+	for _, rule := range sorted {
+		if !rule.Enabled || !matches(rule, facts) {
+			continue
+		}
+		return domain.OrderApprovalEvaluation{
+			Decision: rule.Decision,
+			RuleID:   rule.ID,
+			RuleName: rule.Name,
+			Reason:   rule.Reason,
+			Facts:    facts,
+		}
+	}
+	return domain.OrderApprovalEvaluation{Decision: domain.OrderApprovalApprove, Facts: facts}
+}
+
+func matches(rule domain.OrderApprovalRule, facts domain.OrderApprovalFacts) bool {
+	if rule.MinAmount > 0 && facts.Amount < rule.MinAmount {
+		return false
+	}
+	if rule.MaxAccountAgeDays > 0 && facts.AccountAgeDays >= rule.MaxAccountAgeDays {
+		return false
+	}
+	if rule.RealNameUnverified && facts.RealNameVerified {
+		return false
+	}
+	if len(rule.Countries) > 0 {
+		found := false
+		for _, code := range rule.Countries {
+			if strings.EqualFold(code, facts.Country) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if rule.MinInstances > 0 && facts.Instances < rule.MinInstances {
+		return false
+	}
+	if rule.MinRecentRefunds > 0 && facts.RecentRefunds < rule.MinRecentRefunds {
+		return false
+	}
+	return true
+}
+
+func normalizeRule(rule domain.OrderApprovalRule) (domain.OrderApprovalRule, error) {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.Reason = strings.TrimSpace(rule.Reason)
+	if rule.Name == "" || utf8.RuneCountInString(rule.Name) > maxRuleNameLen || utf8.RuneCountInString(rule.Reason) > maxRuleReasonLen {
+		return domain.OrderApprovalRule{}, domain.ErrInvalidApprovalRule
+	}
+	switch rule.Decision {
+	case domain.OrderApprovalApprove, domain.OrderApprovalReview, domain.OrderApprovalReject:
+	default:
+		return domain.OrderApprovalRule{}, domain.ErrInvalidApprovalRule
+	}
+	if rule.MinAmount < 0 || rule.MaxAccountAgeDays < 0 || rule.MinInstances < 0 || rule.MinRecentRefunds < 0 {
+		return domain.OrderApprovalRule{}, domain.ErrInvalidApprovalRule
+	}
+	if len(rule.Countries) > maxRuleCountries {
+		return domain.OrderApprovalRule{}, domain.ErrInvalidApprovalRule
+	}
+	countries := make([]string, 0, len(rule.Countries))
+	seen := map[string]bool{}
+	for _, code := range rule.Countries {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+		if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+			return domain.OrderApprovalRule{}, domain.ErrInvalidApprovalRule
+		}
+		if !seen[code] {
+			seen[code] = true
+			countries = append(countries, code)
+		}
+	}
+	rule.Countries = countries
+	return rule, nil
+}
+
+func getSettingInt(ctx context.Context, repo appports.SettingsRepository, key string) (int, bool) {
+	if repo == nil {
+		return 0, false
+	}
+	setting, err := repo.GetSetting(ctx, key)
+	if err != nil {
+		return 0, false
+	}
+	val, err := strconv.Atoi(strings.TrimSpace(setting.ValueJSON))
+	if err != nil {
+		return 0, false
+	}
+	return val, true
+}
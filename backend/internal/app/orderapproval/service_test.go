@@ -0,0 +1,145 @@
+package orderapproval_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apporder "xiaoheiplay/internal/app/order"
+	apporderapproval "xiaoheiplay/internal/app/orderapproval"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+func TestOrderApproval_CreateOrderDecisions(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	seed := testutil.SeedCatalog(t, repo)
+	user := testutil.CreateUser(t, repo, "risk", "risk@example.com", "pass")
+
+	policy := apporderapproval.NewService(repo, repo, repo, repo, repo, repo, repo)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
+	svc.SetApprovalPolicy(policy)
+
+	if _, err := policy.CreateRule(ctx, domain.OrderApprovalRule{Name: "blocked", Priority: 20, Enabled: true, Decision: domain.OrderApprovalReject, Countries: []string{"kp"}, Reason: "region not served"}); err != nil {
+		t.Fatalf("create reject rule: %v", err)
+	}
+	if _, err := policy.CreateRule(ctx, domain.OrderApprovalRule{Name: "unverified", Priority: 10, Enabled: true, Decision: domain.OrderApprovalReview, RealNameUnverified: true, MaxAccountAgeDays: 7, Reason: "new unverified account"}); err != nil {
+		t.Fatalf("create review rule: %v", err)
+	}
+	items := []appshared.OrderItemInput{{PackageID: seed.Package.ID, SystemID: seed.SystemImage.ID, Qty: 1}}
+
+	order, _, err := svc.CreateOrderFromItems(apporderapproval.WithClient(ctx, "203.0.113.5", "us"), user.ID, "CNY", items, "risk-1", "")
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	if order.Status != domain.OrderStatusPendingPayment || order.PendingReason != "new unverified account" {
+		t.Fatalf("expected held order, got %+v", order)
+	}
+	eval, err := policy.GetEvaluation(ctx, order.ID)
+	if err != nil || eval.Decision != domain.OrderApprovalReview || eval.Facts.Country != "US" || eval.Facts.ClientIP != "203.0.113.5" || eval.Facts.RealNameVerified {
+		t.Fatalf("unexpected evaluation: %+v err=%v", eval, err)
+	}
+	if err := svc.ApproveOrder(ctx, 0, order.ID); !errors.Is(err, domain.ErrOrderHeldForReview) {
+		t.Fatalf("expected automatic approval to be held, got %v", err)
+	}
+
+	// Order numbers are per user and second, so each order uses its own account.
+	other := testutil.CreateUser(t, repo, "risk2", "risk2@example.com", "pass")
+	if _, _, err := svc.CreateOrderFromItems(apporderapproval.WithClient(ctx, "175.45.176.1", "KP"), other.ID, "CNY", items, "risk-2", ""); !errors.Is(err, domain.ErrOrderRejectedByPolicy) {
+		t.Fatalf("expected rejection, got %v", err)
+	}
+	rejected, total, err := repo.ListOrders(ctx, appshared.OrderFilter{UserID: other.ID, Status: string(domain.OrderStatusRejected)}, 10, 0)
+	if err != nil || total != 1 || rejected[0].PendingReason != "region not served" || rejected[0].RejectedReason != "region not served" {
+		t.Fatalf("expected rejected order with reason, got %+v total=%d err=%v", rejected, total, err)
+	}
+
+	// Orders without a resolved country fall back to ZZ and pass once the
+	// account is verified.
+	verified := testutil.CreateUser(t, repo, "risk3", "risk3@example.com", "pass")
+	now := time.Now()
+	if err := repo.CreateRealNameVerification(ctx, &domain.RealNameVerification{UserID: verified.ID, RealName: "张三", IDNumber: "110101199001010011", Status: "verified", VerifiedAt: &now}); err != nil {
+		t.Fatalf("create realname: %v", err)
+	}
+	order, _, err = svc.CreateOrderFromItems(ctx, verified.ID, "CNY", items, "risk-3", "")
+	if err != nil || order.PendingReason != "" {
+		t.Fatalf("expected approved order, got %+v err=%v", order, err)
+	}
+	eval, _ = policy.GetEvaluation(ctx, order.ID)
+	if eval.Decision != domain.OrderApprovalApprove || eval.RuleID != 0 || eval.Facts.Country != apporderapproval.UnknownCountry {
+		t.Fatalf("unexpected default evaluation: %+v", eval)
+	}
+}
+
+func TestOrderApproval_DryRunAndValidation(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	seed := testutil.SeedCatalog(t, repo)
+	user := testutil.CreateUser(t, repo, "dry", "dry@example.com", "pass")
+	policy := apporderapproval.NewService(repo, repo, repo, repo, repo, repo, repo)
+	svc := apporder.NewService(repo, repo, repo, repo, repo, repo, repo, repo, repo, nil, nil, nil, repo, repo, repo, repo, repo, nil, nil, nil)
+
+	for _, rule := range []domain.OrderApprovalRule{
+		{Name: "", Decision: domain.OrderApprovalReview},
+		{Name: "bad decision", Decision: "hold"},
+		{Name: "bad country", Decision: domain.OrderApprovalReview, Countries: []string{"USA"}},
+		{Name: "negative", Decision: domain.OrderApprovalReview, MinInstances: -1},
+	} {
+		if _, err := policy.CreateRule(ctx, rule); !errors.Is(err, domain.ErrInvalidApprovalRule) {
+			t.Fatalf("expected invalid rule for %+v, got %v", rule, err)
+		}
+	}
+
+	// Orders placed before the policy was wired have no recorded evaluation.
+	items := []appshared.OrderItemInput{{PackageID: seed.Package.ID, SystemID: seed.SystemImage.ID, Qty: 1}}
+	first, _, err := svc.CreateOrderFromItems(ctx, user.ID, "CNY", items, "dry-1", "")
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	svc.SetApprovalPolicy(policy)
+	other := testutil.CreateUser(t, repo, "dry2", "dry2@example.com", "pass")
+	second, _, err := svc.CreateOrderFromItems(ctx, other.ID, "CNY", items, "dry-2", "")
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+
+	from := time.Now().Add(-time.Hour)
+	to := time.Now().Add(time.Hour)
+	summary, err := policy.DryRun(ctx, apporderapproval.DryRunInput{
+		From:  &from,
+		To:    &to,
+		Rules: []domain.OrderApprovalRule{{ID: 1, Name: "draft", Enabled: true, Decision: domain.OrderApprovalReject, RealNameUnverified: true, Reason: "draft"}},
+	})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if summary.Total != 2 || summary.Reject != 2 || summary.Changed != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	for _, item := range summary.Results {
+		if item.Result.RuleID != 1 {
+			t.Fatalf("expected draft rule to match, got %+v", item.Result)
+		}
+		if item.Order.ID == second.ID && (item.Recorded == nil || !item.Different) {
+			t.Fatalf("expected recorded approval to differ, got %+v", item)
+		}
+		if item.Order.ID == first.ID && item.Recorded != nil {
+			t.Fatalf("expected no recorded evaluation for %d", first.ID)
+		}
+	}
+	// Dry runs leave orders untouched.
+	got, _ := repo.GetOrder(ctx, second.ID)
+	if got.Status != domain.OrderStatusPendingPayment || got.PendingReason != "" {
+		t.Fatalf("dry run changed order: %+v", got)
+	}
+
+	summary, err = policy.DryRun(ctx, apporderapproval.DryRunInput{OrderIDs: []int64{first.ID}})
+	if err != nil || summary.Total != 1 || summary.Approve != 1 {
+		t.Fatalf("unexpected saved-rule dry run: %+v err=%v", summary, err)
+	}
+	if _, err := policy.DryRun(ctx, apporderapproval.DryRunInput{}); !errors.Is(err, appshared.ErrInvalidInput) {
+		t.Fatalf("expected invalid input without range, got %v", err)
+	}
+}
@@ -28,10 +28,17 @@ type Service struct {
 	payments appports.PaymentRepository
 	registry appports.PaymentProviderRegistry
 	wallets  appports.WalletRepository
+	holds    appports.WalletHoldRepository
+	ledger   ledgerPoster
 	approver appports.OrderApprover
 	events   appports.EventPublisher
 }
 
+type ledgerPoster interface {
+	Post(ctx context.Context, entry domain.LedgerEntry) error
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 const (
 	SceneOrder  = "order"
 	SceneWallet = "wallet"
@@ -54,6 +61,38 @@ func NewService(orders appports.OrderRepository, items appports.OrderItemReposit
 	}
 }
 
+// SetWalletHolds makes balance payments reserve the amount with a hold
+// until the payment record is written.
+func (s *Service) SetWalletHolds(holds appports.WalletHoldRepository) {
+	s.holds = holds
+}
+
+func (s *Service) SetLedger(ledger ledgerPoster) {
+	s.ledger = ledger
+}
+
+// postPayment books an approved order payment as revenue, funded by the
+// user's wallet for balance payments and by the provider's clearing account
+// otherwise.
+func (s *Service) postPayment(ctx context.Context, payment domain.OrderPayment) error {
+	if s.ledger == nil || payment.Amount <= 0 {
+		return nil
+	}
+	source := domain.LedgerGatewayAccount(payment.Method)
+	if payment.Method == "balance" {
+		source = domain.LedgerWalletAccount(payment.UserID)
+	}
+	return s.ledger.Post(ctx, domain.NewLedgerTransfer(domain.LedgerKindOrderPayment, "payment", payment.ID, payment.UserID, source, domain.LedgerAccountRevenue, payment.Amount, fmt.Sprintf("order %d", payment.OrderID)))
+}
+
+// inTx commits a payment and its ledger entry together.
+func (s *Service) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.ledger == nil {
+		return fn(ctx)
+	}
+	return s.ledger.InTx(ctx, fn)
+}
+
 func (s *Service) ListProviders(ctx context.Context, includeDisabled bool) ([]PaymentProviderInfo, error) {
 	return s.ListProvidersByScene(ctx, includeDisabled, SceneOrder)
 }
@@ -153,7 +192,7 @@ func (s *Service) ListUserMethodsByScene(ctx context.Context, userID int64, scen
 	var balance int64
 	if s.wallets != nil {
 		if wallet, err := s.wallets.GetWallet(ctx, userID); err == nil {
-			balance = wallet.Balance
+			balance = wallet.Available()
 		}
 	}
 	out := make([]PaymentMethodInfo, 0, len(providers))
@@ -319,7 +358,13 @@ func (s *Service) HandleNotify(ctx context.Context, providerKey string, req RawH
 		}
 	}
 	if payment.Status != domain.PaymentStatusApproved {
-		if err := s.payments.UpdatePaymentStatus(ctx, payment.ID, domain.PaymentStatusApproved, nil, ""); err != nil {
+		err := s.inTx(ctx, func(ctx context.Context) error {
+			if err := s.payments.UpdatePaymentStatus(ctx, payment.ID, domain.PaymentStatusApproved, nil, ""); err != nil {
+				return err
+			}
+			return s.postPayment(ctx, payment)
+		})
+		if err != nil {
 			return result, err
 		}
 		if err := s.ensurePendingReview(ctx, payment.OrderID); err != nil && err != appshared.ErrConflict {
@@ -342,9 +387,14 @@ func (s *Service) payWithBalance(ctx context.Context, order domain.Order) (Payme
 	if s.wallets == nil || s.payments == nil {
 		return PaymentSelectResult{}, appshared.ErrInvalidInput
 	}
-	wallet, err := s.wallets.AdjustWalletBalance(ctx, order.UserID, -order.TotalAmount, "debit", "order", order.ID, "balance payment")
-	if err != nil {
-		return PaymentSelectResult{}, err
+	useHold := s.holds != nil && order.TotalAmount > 0
+	var wallet domain.Wallet
+	var err error
+	if useHold {
+		hold := domain.WalletHold{UserID: order.UserID, Amount: order.TotalAmount, RefType: "order", RefID: order.ID, Note: "balance payment"}
+		if err := s.holds.CreateWalletHold(ctx, &hold); err != nil {
+			return PaymentSelectResult{}, err
+		}
 	}
 	tradeNo := fmt.Sprintf("BAL-%d-%d", order.ID, time.Now().Unix())
 	payment := domain.OrderPayment{
@@ -356,7 +406,29 @@ func (s *Service) payWithBalance(ctx context.Context, order domain.Order) (Payme
 		TradeNo:  tradeNo,
 		Status:   domain.PaymentStatusApproved,
 	}
-	if err := s.payments.CreatePayment(ctx, &payment); err != nil {
+	// The debit, the payment record and its ledger entry commit together; a
+	// held amount is only released when that fails.
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		var err error
+		if !useHold {
+			if wallet, err = s.wallets.AdjustWalletBalance(ctx, order.UserID, -order.TotalAmount, "debit", "order", order.ID, "balance payment"); err != nil {
+				return err
+			}
+		}
+		if err := s.payments.CreatePayment(ctx, &payment); err != nil {
+			return err
+		}
+		if useHold {
+			if wallet, err = s.holds.CaptureWalletHold(ctx, "order", order.ID, "debit", "balance payment"); err != nil {
+				return err
+			}
+		}
+		return s.postPayment(ctx, payment)
+	})
+	if err != nil {
+		if useHold {
+			_, _ = s.holds.ReleaseWalletHold(ctx, "order", order.ID, "balance payment failed")
+		}
 		return PaymentSelectResult{}, err
 	}
 	if err := s.ensurePendingReview(ctx, order.ID); err != nil && err != appshared.ErrConflict {
@@ -0,0 +1,441 @@
+package payout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+// RefType is the wallet transaction and ledger reference of a payout batch
+// item, used when a failed payout is credited back.
+const RefType = "payout_item"
+
+const (
+	maxAccountsPerUser = 10
+	maxBatchItems      = 500
+)
+
+var (
+	bankAccountNoPattern = regexp.MustCompile(`^[0-9]{8,32}$`)
+	alipayPhonePattern   = regexp.MustCompile(`^1[0-9]{10}$`)
+	alipayEmailPattern   = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+type ledgerPoster interface {
+	Post(ctx context.Context, entry domain.LedgerEntry) error
+}
+
+type Service struct {
+	repo    appports.PayoutRepository
+	orders  appports.WalletOrderRepository
+	wallets appports.WalletRepository
+	audit   appports.AuditRepository
+	ledger  ledgerPoster
+}
+
+func NewService(repo appports.PayoutRepository, orders appports.WalletOrderRepository, wallets appports.WalletRepository, audit appports.AuditRepository) *Service {
+	return &Service{repo: repo, orders: orders, wallets: wallets, audit: audit}
+}
+
+func (s *Service) SetLedger(ledger ledgerPoster) {
+	s.ledger = ledger
+}
+
+func (s *Service) CreateAccount(ctx context.Context, userID int64, input appshared.PayoutAccountInput) (domain.PayoutAccount, error) {
+	if s.repo == nil || userID <= 0 {
+		return domain.PayoutAccount{}, appshared.ErrInvalidInput
+	}
+	account, err := normalizeAccount(input)
+	if err != nil {
+		return domain.PayoutAccount{}, err
+	}
+	_, total, err := s.repo.ListPayoutAccounts(ctx, userID, "", 1, 0)
+	if err != nil {
+		return domain.PayoutAccount{}, err
+	}
+	if total >= maxAccountsPerUser {
+		return domain.PayoutAccount{}, appshared.ErrConflict
+	}
+	account.UserID = userID
+	account.Status = domain.PayoutAccountPending
+	if err := s.repo.CreatePayoutAccount(ctx, &account); err != nil {
+		return domain.PayoutAccount{}, err
+	}
+	return account, nil
+}
+
+func (s *Service) ListUserAccounts(ctx context.Context, userID int64) ([]domain.PayoutAccount, error) {
+	if s.repo == nil || userID <= 0 {
+		return nil, appshared.ErrInvalidInput
+	}
+	items, _, err := s.repo.ListPayoutAccounts(ctx, userID, "", maxAccountsPerUser, 0)
+	return items, err
+}
+
+func (s *Service) DeleteAccount(ctx context.Context, userID, id int64) error {
+	if s.repo == nil || userID <= 0 || id <= 0 {
+		return appshared.ErrInvalidInput
+	}
+	return s.repo.DeletePayoutAccount(ctx, userID, id)
+}
+
+func (s *Service) ListAccounts(ctx context.Context, status string, limit, offset int) ([]domain.PayoutAccount, int, error) {
+	if s.repo == nil {
+		return nil, 0, appshared.ErrInvalidInput
+	}
+	switch domain.PayoutAccountStatus(status) {
+	case "", domain.PayoutAccountPending, domain.PayoutAccountVerified, domain.PayoutAccountRejected:
+	default:
+		return nil, 0, appshared.ErrInvalidInput
+	}
+	return s.repo.ListPayoutAccounts(ctx, 0, status, limit, offset)
+}
+
+// ReviewAccount verifies or rejects a payout account after the admin has
+// checked that it belongs to the user, e.g. against the real-name record.
+func (s *Service) ReviewAccount(ctx context.Context, adminID, id int64, approve bool, reason string) (domain.PayoutAccount, error) {
+	if s.repo == nil || id <= 0 {
+		return domain.PayoutAccount{}, appshared.ErrInvalidInput
+	}
+	reason = strings.TrimSpace(reason)
+	if len(reason) > 1000 {
+		return domain.PayoutAccount{}, appshared.ErrInvalidInput
+	}
+	status := domain.PayoutAccountVerified
+	if !approve {
+		status = domain.PayoutAccountRejected
+	}
+	if err := s.repo.UpdatePayoutAccountStatus(ctx, id, status, adminID, reason); err != nil {
+		return domain.PayoutAccount{}, err
+	}
+	account, err := s.repo.GetPayoutAccount(ctx, id)
+	if err != nil {
+		return domain.PayoutAccount{}, err
+	}
+	s.addAudit(ctx, adminID, "payout_account.review", "payout_account", id, map[string]any{"status": status, "reason": reason})
+	return account, nil
+}
+
+// WithdrawAccount returns the user's payout account for a new withdrawal.
+func (s *Service) WithdrawAccount(ctx context.Context, userID, id int64) (domain.PayoutAccount, error) {
+	if s.repo == nil || userID <= 0 || id <= 0 {
+		return domain.PayoutAccount{}, appshared.ErrInvalidInput
+	}
+	account, err := s.repo.GetPayoutAccount(ctx, id)
+	if err != nil {
+		return domain.PayoutAccount{}, err
+	}
+	if account.UserID != userID {
+		return domain.PayoutAccount{}, appshared.ErrForbidden
+	}
+	if account.Status != domain.PayoutAccountVerified {
+		return domain.PayoutAccount{}, domain.ErrPayoutAccountNotVerified
+	}
+	return account, nil
+}
+
+func (s *Service) ListBatches(ctx context.Context, status string, limit, offset int) ([]domain.PayoutBatch, int, error) {
+	if s.repo == nil {
+		return nil, 0, appshared.ErrInvalidInput
+	}
+	return s.repo.ListPayoutBatches(ctx, status, limit, offset)
+}
+
+func (s *Service) GetBatch(ctx context.Context, id int64) (domain.PayoutBatch, error) {
+	if s.repo == nil || id <= 0 {
+		return domain.PayoutBatch{}, appshared.ErrInvalidInput
+	}
+	return s.repo.GetPayoutBatch(ctx, id)
+}
+
+// CreateBatch collects approved, not yet batched withdrawals paid to
+// accounts of the given channel. Withdrawals without a payout account are
+// left for manual handling.
+func (s *Service) CreateBatch(ctx context.Context, adminID int64, input appshared.PayoutBatchCreateInput) (domain.PayoutBatch, error) {
+	if s.repo == nil {
+		return domain.PayoutBatch{}, appshared.ErrInvalidInput
+	}
+	channel := domain.PayoutChannel(strings.TrimSpace(input.Channel))
+	if !validChannel(channel) || len(input.OrderIDs) > maxBatchItems {
+		return domain.PayoutBatch{}, appshared.ErrInvalidInput
+	}
+	orders, err := s.repo.ListUnbatchedWithdrawOrders(ctx, input.OrderIDs, maxBatchItems)
+	if err != nil {
+		return domain.PayoutBatch{}, err
+	}
+	batch := domain.PayoutBatch{Channel: channel, Status: domain.PayoutBatchPending, Note: strings.TrimSpace(input.Note), CreatedBy: adminID}
+	for _, order := range orders {
+		item, ok := payeeFromMeta(order)
+		if !ok || domain.PayoutChannel(metaString(parseMeta(order.MetaJSON), "payout_method")) != channel {
+			continue
+		}
+		batch.Items = append(batch.Items, item)
+		batch.ItemCount++
+		batch.TotalAmount += item.Amount
+	}
+	if len(batch.Items) == 0 {
+		return domain.PayoutBatch{}, domain.ErrNoPayableWithdrawals
+	}
+	if err := s.repo.CreatePayoutBatch(ctx, &batch); err != nil {
+		return domain.PayoutBatch{}, err
+	}
+	for _, item := range batch.Items {
+		s.updateOrderMeta(ctx, item.WalletOrderID, map[string]any{"payout_status": string(domain.PayoutItemPending), "payout_batch_id": batch.ID})
+	}
+	s.addAudit(ctx, adminID, "payout_batch.create", "payout_batch", batch.ID, map[string]any{"channel": channel, "items": batch.ItemCount, "total": batch.TotalAmount})
+	return batch, nil
+}
+
+// ExportBatch returns the batch as CSV records in the bulk transfer layout
+// of its channel, header first.
+func (s *Service) ExportBatch(ctx context.Context, id int64) (domain.PayoutBatch, [][]string, error) {
+	batch, err := s.GetBatch(ctx, id)
+	if err != nil {
+		return domain.PayoutBatch{}, nil, err
+	}
+	var records [][]string
+	switch batch.Channel {
+	case domain.PayoutChannelAlipay:
+		records = append(records, []string{"序号", "收款方支付宝账号", "收款方姓名", "金额", "备注"})
+		for i, item := range batch.Items {
+			records = append(records, []string{strconv.Itoa(i + 1), appshared.EscapeCSVCell(item.AccountNo), appshared.EscapeCSVCell(item.AccountName), formatAmount(item.Amount), itemReference(item)})
+		}
+	default:
+		records = append(records, []string{"序号", "收款账号", "收款户名", "收款银行", "收款支行", "金额", "用途", "备注"})
+		for i, item := range batch.Items {
+			records = append(records, []string{strconv.Itoa(i + 1), appshared.EscapeCSVCell(item.AccountNo), appshared.EscapeCSVCell(item.AccountName), appshared.EscapeCSVCell(item.BankName), appshared.EscapeCSVCell(item.BankBranch), formatAmount(item.Amount), "提现", itemReference(item)})
+		}
+	}
+	if err := s.repo.MarkPayoutBatchExported(ctx, id); err != nil {
+		return domain.PayoutBatch{}, nil, err
+	}
+	return batch, records, nil
+}
+
+// MarkPaid settles a pending batch after the transfer was made. Items listed
+// in failed (item ID to reason) were returned by the bank and are credited
+// back to the user's wallet; all other items are marked paid.
+func (s *Service) MarkPaid(ctx context.Context, adminID, id int64, reference string, failed map[int64]string) (domain.PayoutBatch, error) {
+	return s.settle(ctx, adminID, id, strings.TrimSpace(reference), failed, "")
+}
+
+// MarkFailed fails every item of a pending batch and credits the amounts back.
+func (s *Service) MarkFailed(ctx context.Context, adminID, id int64, reason string) (domain.PayoutBatch, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return domain.PayoutBatch{}, appshared.ErrInvalidInput
+	}
+	return s.settle(ctx, adminID, id, "", nil, reason)
+}
+
+// settle is safe to retry and to race: an item is credited back only by the
+// call that moves it from pending to failed, in the same transaction.
+func (s *Service) settle(ctx context.Context, adminID, id int64, reference string, failed map[int64]string, failAll string) (domain.PayoutBatch, error) {
+	if s.wallets == nil {
+		return domain.PayoutBatch{}, appshared.ErrInvalidInput
+	}
+	batch, err := s.GetBatch(ctx, id)
+	if err != nil {
+		return domain.PayoutBatch{}, err
+	}
+	if batch.Status != domain.PayoutBatchPending {
+		return domain.PayoutBatch{}, domain.ErrPayoutBatchSettled
+	}
+	known := make(map[int64]bool, len(batch.Items))
+	for _, item := range batch.Items {
+		known[item.ID] = true
+	}
+	for itemID := range failed {
+		if !known[itemID] {
+			return domain.PayoutBatch{}, appshared.ErrInvalidInput
+		}
+	}
+	for _, item := range batch.Items {
+		if item.Status != domain.PayoutItemPending {
+			continue
+		}
+		status := domain.PayoutItemPaid
+		reason, isFailed := failed[item.ID]
+		if failAll != "" {
+			reason, isFailed = failAll, true
+		}
+		if isFailed {
+			status = domain.PayoutItemFailed
+			if strings.TrimSpace(reason) == "" {
+				reason = "payout failed"
+			}
+		}
+		var changed bool
+		err := s.repo.InTx(ctx, func(ctx context.Context) error {
+			var err error
+			changed, err = s.repo.SettlePayoutBatchItem(ctx, item.ID, status, reference, reason)
+			if err != nil || !changed || status != domain.PayoutItemFailed {
+				return err
+			}
+			item.FailReason = reason
+			return s.recredit(ctx, batch.Channel, item)
+		})
+		if err != nil {
+			return domain.PayoutBatch{}, err
+		}
+		if !changed {
+			continue
+		}
+		meta := map[string]any{"payout_status": string(status)}
+		if status == domain.PayoutItemFailed {
+			meta["payout_fail_reason"] = reason
+		} else if reference != "" {
+			meta["payout_reference"] = reference
+		}
+		s.updateOrderMeta(ctx, item.WalletOrderID, meta)
+	}
+	// Count from the stored items: a concurrent call may have settled some.
+	settled, err := s.GetBatch(ctx, batch.ID)
+	if err != nil {
+		return domain.PayoutBatch{}, err
+	}
+	paid, failedCount := 0, 0
+	for _, item := range settled.Items {
+		if item.Status == domain.PayoutItemFailed {
+			failedCount++
+		} else {
+			paid++
+		}
+	}
+	status := domain.PayoutBatchPartial
+	switch {
+	case failedCount == 0:
+		status = domain.PayoutBatchPaid
+	case paid == 0:
+		status = domain.PayoutBatchFailed
+	}
+	if err := s.repo.UpdatePayoutBatchStatus(ctx, batch.ID, status, reference); err != nil {
+		return domain.PayoutBatch{}, err
+	}
+	s.addAudit(ctx, adminID, "payout_batch.settle", "payout_batch", batch.ID, map[string]any{"status": status, "reference": reference, "paid": paid, "failed": failedCount})
+	return s.repo.GetPayoutBatch(ctx, batch.ID)
+}
+
+// recredit returns a failed payout to the user's wallet and reverses the
+// withdrawal in the ledger. It runs in the transaction that failed the item.
+func (s *Service) recredit(ctx context.Context, channel domain.PayoutChannel, item domain.PayoutBatchItem) error {
+	note := fmt.Sprintf("withdraw %d payout failed: %s", item.WalletOrderID, item.FailReason)
+	if _, err := s.wallets.AdjustWalletBalance(ctx, item.UserID, item.Amount, "credit", RefType, item.ID, note); err != nil {
+		return err
+	}
+	if s.ledger == nil {
+		return nil
+	}
+	return s.ledger.Post(ctx, domain.NewLedgerTransfer(domain.LedgerKindPayoutReversal, RefType, item.ID, item.UserID, domain.LedgerGatewayAccount(string(channel)), domain.LedgerWalletAccount(item.UserID), item.Amount, "payout failed"))
+}
+
+func (s *Service) updateOrderMeta(ctx context.Context, orderID int64, values map[string]any) {
+	if s.orders == nil {
+		return
+	}
+	order, err := s.orders.GetWalletOrder(ctx, orderID)
+	if err != nil {
+		return
+	}
+	meta := parseMeta(order.MetaJSON)
+	for k, v := range values {
+		meta[k] = v
+	}
+	b, _ := json.Marshal(meta)
+	_ = s.orders.UpdateWalletOrderMeta(ctx, orderID, string(b))
+}
+
+func (s *Service) addAudit(ctx context.Context, adminID int64, action, targetType string, targetID int64, detail map[string]any) {
+	if s.audit == nil {
+		return
+	}
+	b, _ := json.Marshal(detail)
+	_ = s.audit.AddAuditLog(ctx, domain.AdminAuditLog{AdminID: adminID, Action: action, TargetType: targetType, TargetID: strconv.FormatInt(targetID, 10), DetailJSON: string(b)})
+}
+
+func normalizeAccount(input appshared.PayoutAccountInput) (domain.PayoutAccount, error) {
+	account := domain.PayoutAccount{
+		Channel:     domain.PayoutChannel(strings.TrimSpace(input.Channel)),
+		AccountName: strings.TrimSpace(appshared.SanitizePlainText(input.AccountName)),
+		AccountNo:   strings.ReplaceAll(strings.TrimSpace(input.AccountNo), " ", ""),
+		BankName:    strings.TrimSpace(appshared.SanitizePlainText(input.BankName)),
+		BankBranch:  strings.TrimSpace(appshared.SanitizePlainText(input.BankBranch)),
+	}
+	if account.AccountName == "" || len(account.AccountName) > 128 || len(account.BankName) > 128 || len(account.BankBranch) > 255 {
+		return domain.PayoutAccount{}, domain.ErrInvalidPayoutAccount
+	}
+	switch account.Channel {
+	case domain.PayoutChannelBank:
+		if !bankAccountNoPattern.MatchString(account.AccountNo) || account.BankName == "" {
+			return domain.PayoutAccount{}, domain.ErrInvalidPayoutAccount
+		}
+	case domain.PayoutChannelAlipay:
+		if len(account.AccountNo) > 128 || (!alipayPhonePattern.MatchString(account.AccountNo) && !alipayEmailPattern.MatchString(account.AccountNo)) {
+			return domain.PayoutAccount{}, domain.ErrInvalidPayoutAccount
+		}
+		account.BankName = ""
+		account.BankBranch = ""
+	default:
+		return domain.PayoutAccount{}, domain.ErrInvalidPayoutAccount
+	}
+	return account, nil
+}
+
+func validChannel(channel domain.PayoutChannel) bool {
+	return channel == domain.PayoutChannelBank || channel == domain.PayoutChannelAlipay
+}
+
+// payeeFromMeta reads the payout account snapshot that the wallet order
+// service stores on a withdrawal.
+func payeeFromMeta(order domain.WalletOrder) (domain.PayoutBatchItem, bool) {
+	meta := parseMeta(order.MetaJSON)
+	accountID, _ := strconv.ParseInt(metaString(meta, "payout_account_id"), 10, 64)
+	item := domain.PayoutBatchItem{
+		WalletOrderID:   order.ID,
+		UserID:          order.UserID,
+		Amount:          order.Amount,
+		PayoutAccountID: accountID,
+		AccountName:     metaString(meta, "payout_account_name"),
+		AccountNo:       metaString(meta, "payout_account_no"),
+		BankName:        metaString(meta, "payout_bank_name"),
+		BankBranch:      metaString(meta, "payout_bank_branch"),
+	}
+	return item, accountID > 0 && item.AccountNo != "" && order.Amount > 0
+}
+
+func itemReference(item domain.PayoutBatchItem) string {
+	return "W" + strconv.FormatInt(item.WalletOrderID, 10)
+}
+
+func formatAmount(cents int64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+
+func parseMeta(raw string) map[string]any {
+	out := map[string]any{}
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &out)
+	}
+	if out == nil {
+		out = map[string]any{}
+	}
+	return out
+}
+
+func metaString(meta map[string]any, key string) string {
+	switch v := meta[key].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatInt(int64(v), 10)
+	default:
+		return ""
+	}
+}
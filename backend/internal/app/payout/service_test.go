@@ -0,0 +1,199 @@
+package payout_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	appledger "xiaoheiplay/internal/app/ledger"
+	apppayout "xiaoheiplay/internal/app/payout"
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	appwalletorder "xiaoheiplay/internal/app/walletorder"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+func TestPayout_BatchLifecycle(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	alice := testutil.CreateUser(t, repo, "alice", "alice@example.com", "pass")
+	bob := testutil.CreateUser(t, repo, "bob", "bob@example.com", "pass")
+
+	ledger := appledger.NewService(repo)
+	payouts := apppayout.NewService(repo, repo, repo, repo)
+	payouts.SetLedger(ledger)
+	orders := appwalletorder.NewService(repo, repo, repo, repo, repo, nil, repo)
+	orders.SetWalletHolds(repo)
+	orders.SetLedger(ledger)
+	orders.SetPayoutAccounts(payouts)
+
+	if _, err := payouts.CreateAccount(ctx, alice.ID, appshared.PayoutAccountInput{Channel: "bank", AccountName: "Alice", AccountNo: "12ab"}); !errors.Is(err, domain.ErrInvalidPayoutAccount) {
+		t.Fatalf("expected invalid account error, got %v", err)
+	}
+	accounts := map[int64]domain.PayoutAccount{}
+	for _, user := range []domain.User{alice, bob} {
+		if _, err := repo.AdjustWalletBalance(ctx, user.ID, 10000, "credit", "test", 0, "seed"); err != nil {
+			t.Fatalf("seed wallet: %v", err)
+		}
+		account, err := payouts.CreateAccount(ctx, user.ID, appshared.PayoutAccountInput{Channel: "bank", AccountName: user.Username, AccountNo: "6222 0000 1111 2222", BankName: "ICBC", BankBranch: "=1+1"})
+		if err != nil {
+			t.Fatalf("create account: %v", err)
+		}
+		if account.Status != domain.PayoutAccountPending || account.AccountNo != "6222000011112222" {
+			t.Fatalf("unexpected account: %+v", account)
+		}
+		accounts[user.ID] = account
+	}
+	if _, err := orders.CreateWithdraw(ctx, alice.ID, appshared.WalletOrderCreateInput{Amount: 1, Meta: map[string]any{"payout_account_id": accounts[alice.ID].ID}}); !errors.Is(err, domain.ErrPayoutAccountNotVerified) {
+		t.Fatalf("expected unverified account error, got %v", err)
+	}
+
+	withdrawals := map[int64]domain.WalletOrder{}
+	for _, user := range []domain.User{alice, bob} {
+		if _, err := payouts.ReviewAccount(ctx, 1, accounts[user.ID].ID, true, ""); err != nil {
+			t.Fatalf("verify account: %v", err)
+		}
+		order, err := orders.CreateWithdraw(ctx, user.ID, appshared.WalletOrderCreateInput{Amount: 4000, Meta: map[string]any{"payout_account_id": float64(accounts[user.ID].ID), "payout_account_no": "spoofed"}})
+		if err != nil {
+			t.Fatalf("create withdraw: %v", err)
+		}
+		if _, _, err := orders.Approve(ctx, 1, order.ID); err != nil {
+			t.Fatalf("approve withdraw: %v", err)
+		}
+		withdrawals[user.ID] = order
+	}
+	if _, err := ledger.PostOpeningBalances(ctx); err != nil {
+		t.Fatalf("opening balances: %v", err)
+	}
+
+	batch, err := payouts.CreateBatch(ctx, 1, appshared.PayoutBatchCreateInput{Channel: "bank"})
+	if err != nil {
+		t.Fatalf("create batch: %v", err)
+	}
+	if batch.ItemCount != 2 || batch.TotalAmount != 8000 || batch.Items[0].AccountNo != "6222000011112222" {
+		t.Fatalf("unexpected batch: %+v", batch)
+	}
+	if _, err := payouts.CreateBatch(ctx, 1, appshared.PayoutBatchCreateInput{Channel: "bank"}); !errors.Is(err, domain.ErrNoPayableWithdrawals) {
+		t.Fatalf("expected withdrawals to be batched once, got %v", err)
+	}
+	_, records, err := payouts.ExportBatch(ctx, batch.ID)
+	if err != nil || len(records) != 3 || records[1][5] != "40.00" {
+		t.Fatalf("unexpected export: %v err=%v", records, err)
+	}
+	if records[1][3] != "ICBC" || records[1][4] != "'=1+1" {
+		t.Fatalf("expected formula cells escaped in export: %v", records[1])
+	}
+
+	var bobItem int64
+	for _, item := range batch.Items {
+		if item.UserID == bob.ID {
+			bobItem = item.ID
+		}
+	}
+	settled, err := payouts.MarkPaid(ctx, 1, batch.ID, "BANK-001", map[int64]string{bobItem: "account closed"})
+	if err != nil {
+		t.Fatalf("mark paid: %v", err)
+	}
+	if settled.Status != domain.PayoutBatchPartial || settled.Reference != "BANK-001" {
+		t.Fatalf("unexpected settled batch: %+v", settled)
+	}
+	if _, err := payouts.MarkFailed(ctx, 1, batch.ID, "again"); !errors.Is(err, domain.ErrPayoutBatchSettled) {
+		t.Fatalf("expected settled batch error, got %v", err)
+	}
+	for userID, want := range map[int64]int64{alice.ID: 6000, bob.ID: 10000} {
+		wallet, err := repo.GetWallet(ctx, userID)
+		if err != nil || wallet.Balance != want {
+			t.Fatalf("user %d: expected balance %d, got %+v err=%v", userID, want, wallet, err)
+		}
+	}
+	order, err := repo.GetWalletOrder(ctx, withdrawals[bob.ID].ID)
+	if err != nil {
+		t.Fatalf("get order: %v", err)
+	}
+	if !strings.Contains(order.MetaJSON, `"payout_status":"failed"`) || !strings.Contains(order.MetaJSON, `"payout_fail_reason":"account closed"`) {
+		t.Fatalf("unexpected order meta: %s", order.MetaJSON)
+	}
+	result, err := ledger.Verify(ctx)
+	if err != nil || !result.OK() {
+		t.Fatalf("expected balanced ledger, got %+v err=%v", result, err)
+	}
+}
+
+// staleBatchRepo returns stale the first time the batch is read.
+type staleBatchRepo struct {
+	appports.PayoutRepository
+	stale *domain.PayoutBatch
+}
+
+func (r *staleBatchRepo) GetPayoutBatch(ctx context.Context, id int64) (domain.PayoutBatch, error) {
+	if r.stale != nil {
+		batch := *r.stale
+		r.stale = nil
+		return batch, nil
+	}
+	return r.PayoutRepository.GetPayoutBatch(ctx, id)
+}
+
+func TestPayout_ConcurrentFailureCreditsOnce(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	carol := testutil.CreateUser(t, repo, "carol", "carol@example.com", "pass")
+
+	ledger := appledger.NewService(repo)
+	payouts := apppayout.NewService(repo, repo, repo, repo)
+	payouts.SetLedger(ledger)
+	orders := appwalletorder.NewService(repo, repo, repo, repo, repo, nil, repo)
+	orders.SetWalletHolds(repo)
+	orders.SetLedger(ledger)
+	orders.SetPayoutAccounts(payouts)
+
+	if _, err := repo.AdjustWalletBalance(ctx, carol.ID, 10000, "credit", "test", 0, "seed"); err != nil {
+		t.Fatalf("seed wallet: %v", err)
+	}
+	if _, err := ledger.PostOpeningBalances(ctx); err != nil {
+		t.Fatalf("opening balances: %v", err)
+	}
+	account, err := payouts.CreateAccount(ctx, carol.ID, appshared.PayoutAccountInput{Channel: "bank", AccountName: "Carol", AccountNo: "6222000011113333", BankName: "ICBC"})
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	if _, err := payouts.ReviewAccount(ctx, 1, account.ID, true, ""); err != nil {
+		t.Fatalf("verify account: %v", err)
+	}
+	order, err := orders.CreateWithdraw(ctx, carol.ID, appshared.WalletOrderCreateInput{Amount: 4000, Meta: map[string]any{"payout_account_id": account.ID}})
+	if err != nil {
+		t.Fatalf("create withdraw: %v", err)
+	}
+	if _, _, err := orders.Approve(ctx, 1, order.ID); err != nil {
+		t.Fatalf("approve withdraw: %v", err)
+	}
+	batch, err := payouts.CreateBatch(ctx, 1, appshared.PayoutBatchCreateInput{Channel: "bank"})
+	if err != nil {
+		t.Fatalf("create batch: %v", err)
+	}
+	// racer read the batch while it was still pending, as a MarkPaid or
+	// MarkFailed running at the same time would.
+	racer := apppayout.NewService(&staleBatchRepo{PayoutRepository: repo, stale: &batch}, repo, repo, repo)
+	racer.SetLedger(ledger)
+	if _, err := payouts.MarkFailed(ctx, 1, batch.ID, "bank rejected"); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+	if _, err := racer.MarkFailed(ctx, 1, batch.ID, "bank rejected"); err != nil {
+		t.Fatalf("racing mark failed: %v", err)
+	}
+
+	wallet, err := repo.GetWallet(ctx, carol.ID)
+	if err != nil || wallet.Balance != 10000 {
+		t.Fatalf("expected one credit back to 10000, got %+v err=%v", wallet, err)
+	}
+	got, err := payouts.GetBatch(ctx, batch.ID)
+	if err != nil || got.Items[0].Status != domain.PayoutItemFailed {
+		t.Fatalf("expected failed item, got %+v err=%v", got, err)
+	}
+	result, err := ledger.Verify(ctx)
+	if err != nil || !result.OK() {
+		t.Fatalf("expected balanced ledger, got %+v err=%v", result, err)
+	}
+}
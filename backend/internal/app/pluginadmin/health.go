@@ -0,0 +1,79 @@
+package pluginadmin
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	appports "xiaoheiplay/internal/app/ports"
+	"xiaoheiplay/internal/domain"
+)
+
+const healthNotifyAdminLimit = 200
+
+type messageCenter interface {
+	NotifyUsers(ctx context.Context, userIDs []int64, typ, title, content string) error
+}
+
+// SetHealthNotifier enables in-site notifications to active admins for plugin
+// supervisor events.
+func (s *Service) SetHealthNotifier(users appports.UserRepository, messages messageCenter) {
+	s.users = users
+	s.messages = messages
+}
+
+// HandleHealthEvent is registered as the plugin runtime's health event handler.
+func (s *Service) HandleHealthEvent(ctx context.Context, ev domain.PluginHealthEvent) {
+	target := ev.Category + "/" + ev.PluginID + "/" + ev.InstanceID
+	log.Printf("plugin health event: %s %s restarts=%d %s", target, ev.Kind, ev.Restarts, ev.Message)
+	if s.users == nil || s.messages == nil {
+		return
+	}
+	admins, _, err := s.users.ListUsersByRoleStatus(ctx, string(domain.UserRoleAdmin), string(domain.UserStatusActive), healthNotifyAdminLimit, 0)
+	if err != nil || len(admins) == 0 {
+		return
+	}
+	ids := make([]int64, 0, len(admins))
+	for _, u := range admins {
+		ids = append(ids, u.ID)
+	}
+	title, content := healthEventText(target, ev)
+	if err := s.messages.NotifyUsers(ctx, ids, "plugin_health", title, content); err != nil {
+		log.Printf("plugin health notify failed: %s: %v", target, err)
+	}
+}
+
+func healthEventText(target string, ev domain.PluginHealthEvent) (string, string) {
+	var title, content string
+	switch ev.Kind {
+	case domain.PluginHealthCrashed:
+		title = "Plugin Crashed"
+		content = fmt.Sprintf("Plugin %s exited unexpectedly and will be restarted.", target)
+	case domain.PluginHealthRestarted:
+		title = "Plugin Restarted"
+		content = fmt.Sprintf("Plugin %s was restarted (restart #%d).", target, ev.Restarts)
+	case domain.PluginHealthRestartFailed:
+		title = "Plugin Restart Failed"
+		content = fmt.Sprintf("Plugin %s failed to restart.", target)
+	case domain.PluginHealthCircuitOpen:
+		title = "Plugin Circuit Open"
+		content = fmt.Sprintf("Plugin %s failed repeatedly; calls are rejected until the next restart attempt. Disable and re-enable it to retry immediately.", target)
+	case domain.PluginHealthCircuitClosed:
+		title = "Plugin Circuit Closed"
+		content = fmt.Sprintf("Plugin %s is running again.", target)
+	case domain.PluginHealthUnhealthy:
+		title = "Plugin Unhealthy"
+		content = fmt.Sprintf("Plugin %s failed its health check.", target)
+	case domain.PluginHealthRecovered:
+		title = "Plugin Recovered"
+		content = fmt.Sprintf("Plugin %s passed its health check again.", target)
+	default:
+		title = "Plugin Health"
+		content = fmt.Sprintf("Plugin %s: %s.", target, ev.Kind)
+	}
+	if msg := strings.TrimSpace(ev.Message); msg != "" {
+		content += " " + msg
+	}
+	return title, content
+}
@@ -2,6 +2,7 @@ package pluginadmin
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"sort"
 	"strings"
@@ -9,10 +10,13 @@ import (
 	appports "xiaoheiplay/internal/app/ports"
 	appshared "xiaoheiplay/internal/app/shared"
 	"xiaoheiplay/internal/domain"
+	pkgversion "xiaoheiplay/internal/pkg/version"
 )
 
 const DefaultInstanceID = "default"
 
+const settingPluginRepositories = "plugin_repositories"
+
 type Manager interface {
 	List(ctx context.Context) ([]appshared.PluginListItem, error)
 	DiscoverOnDisk(ctx context.Context) ([]appshared.PluginDiscoverItem, error)
@@ -28,12 +32,16 @@ type Manager interface {
 	UpdateConfigInstance(ctx context.Context, category, pluginID, instanceID string, configJSON string) error
 	CreateInstance(ctx context.Context, category, pluginID, instanceID string) (domain.PluginInstallation, error)
 	DeletePluginFiles(ctx context.Context, category, pluginID string) error
+	FetchRepositoryIndex(ctx context.Context, repoURL string) ([]appshared.PluginRepositoryItem, error)
+	InstallFromRepository(ctx context.Context, repoURL, category, pluginID, version string, allowUntrusted bool) (domain.PluginInstallation, error)
 }
 
 type Service struct {
 	manager        Manager
 	paymentMethods appports.PluginPaymentMethodRepository
 	settings       appports.SettingsRepository
+	users          appports.UserRepository
+	messages       messageCenter
 }
 
 func NewService(manager Manager, paymentMethods appports.PluginPaymentMethodRepository, settings appports.SettingsRepository) *Service {
@@ -292,3 +300,127 @@ func (s *Service) ResolveUploadDir(ctx context.Context, configuredDir string) st
 	}
 	return "plugins/payment"
 }
+
+// ListRepositories returns the plugin_repositories setting, a JSON array of
+// {"name","url"} objects. Entries without a URL are dropped; names default to the URL.
+func (s *Service) ListRepositories(ctx context.Context) ([]appshared.PluginRepository, error) {
+	if s.settings == nil {
+		return nil, nil
+	}
+	setting, err := s.settings.GetSetting(ctx, settingPluginRepositories)
+	if err != nil || strings.TrimSpace(setting.ValueJSON) == "" {
+		return nil, nil
+	}
+	var raw []appshared.PluginRepository
+	if err := json.Unmarshal([]byte(setting.ValueJSON), &raw); err != nil {
+		return nil, domain.ErrInvalidPluginRepositories
+	}
+	out := make([]appshared.PluginRepository, 0, len(raw))
+	seen := map[string]bool{}
+	for _, r := range raw {
+		r.URL = strings.TrimSpace(r.URL)
+		r.Name = strings.TrimSpace(r.Name)
+		if r.URL == "" {
+			continue
+		}
+		if r.Name == "" {
+			r.Name = r.URL
+		}
+		if seen[r.Name] {
+			continue
+		}
+		seen[r.Name] = true
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// BrowseRepositories lists what every configured repository offers, newest
+// version first, annotated with the locally installed version.
+func (s *Service) BrowseRepositories(ctx context.Context) ([]appshared.PluginRepositoryItem, []appshared.PluginRepositoryError, error) {
+	if s.manager == nil {
+		return nil, nil, domain.ErrPluginsDisabled
+	}
+	repos, err := s.ListRepositories(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	installed := map[string]string{}
+	if items, err := s.manager.List(ctx); err == nil {
+		for _, it := range items {
+			installed[it.Category+":"+it.PluginID] = it.Version
+		}
+	}
+	var out []appshared.PluginRepositoryItem
+	var failures []appshared.PluginRepositoryError
+	for _, repo := range repos {
+		items, err := s.manager.FetchRepositoryIndex(ctx, repo.URL)
+		if err != nil {
+			failures = append(failures, appshared.PluginRepositoryError{Repository: repo.Name, Error: err.Error()})
+			continue
+		}
+		for _, item := range items {
+			item.Repository = repo.Name
+			sort.SliceStable(item.Versions, func(i, j int) bool {
+				return pkgversion.Compare(item.Versions[i].Version, item.Versions[j].Version) > 0
+			})
+			if len(item.Versions) > 0 {
+				item.LatestVersion = item.Versions[0].Version
+			}
+			item.InstalledVersion = installed[item.Category+":"+item.PluginID]
+			item.UpgradeAvailable = item.InstalledVersion != "" && item.LatestVersion != "" &&
+				pkgversion.Compare(item.LatestVersion, item.InstalledVersion) > 0
+			out = append(out, item)
+		}
+	}
+	return out, failures, nil
+}
+
+// InstallFromRepository installs or upgrades a plugin from a named repository.
+// An empty version selects the newest one the repository lists.
+func (s *Service) InstallFromRepository(ctx context.Context, repository, category, pluginID, version string, allowUntrusted bool) (domain.PluginInstallation, error) {
+	if s.manager == nil {
+		return domain.PluginInstallation{}, domain.ErrPluginsDisabled
+	}
+	repository = strings.TrimSpace(repository)
+	category = strings.TrimSpace(category)
+	pluginID = strings.TrimSpace(pluginID)
+	version = strings.TrimSpace(version)
+	if repository == "" || category == "" || pluginID == "" {
+		return domain.PluginInstallation{}, domain.ErrInvalidInput
+	}
+	repos, err := s.ListRepositories(ctx)
+	if err != nil {
+		return domain.PluginInstallation{}, err
+	}
+	repoURL := ""
+	for _, r := range repos {
+		if r.Name == repository {
+			repoURL = r.URL
+			break
+		}
+	}
+	if repoURL == "" {
+		return domain.PluginInstallation{}, domain.ErrPluginRepositoryNotFound
+	}
+	if version == "" {
+		items, err := s.manager.FetchRepositoryIndex(ctx, repoURL)
+		if err != nil {
+			return domain.PluginInstallation{}, err
+		}
+		for _, it := range items {
+			if it.Category != category || it.PluginID != pluginID {
+				continue
+			}
+			for _, v := range it.Versions {
+				if version == "" || pkgversion.Compare(v.Version, version) > 0 {
+					version = v.Version
+				}
+			}
+		}
+		if version == "" {
+			return domain.PluginInstallation{}, domain.ErrPluginVersionNotFound
+		}
+	}
+	return s.manager.InstallFromRepository(ctx, repoURL, category, pluginID, version, allowUntrusted)
+}
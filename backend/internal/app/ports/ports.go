@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"io"
 	"time"
 
 	appshared "xiaoheiplay/internal/app/shared"
@@ -175,6 +176,12 @@ type UserAPIKeyRepository interface {
 	TouchUserAPIKey(ctx context.Context, id int64) error
 }
 
+type SSHKeyRepository interface {
+	CreateSSHKey(ctx context.Context, key *domain.SSHKey) error
+	ListSSHKeys(ctx context.Context, userID int64) ([]domain.SSHKey, error)
+	DeleteSSHKey(ctx context.Context, userID, id int64) error
+}
+
 type SettingsRepository interface {
 	GetSetting(ctx context.Context, key string) (domain.Setting, error)
 	UpsertSetting(ctx context.Context, setting domain.Setting) error
@@ -211,6 +218,14 @@ type BillingCycleRepository interface {
 	DeleteBillingCycle(ctx context.Context, id int64) error
 }
 
+type AddonRepository interface {
+	ListAddons(ctx context.Context, planGroupID int64) ([]domain.Addon, error)
+	GetAddon(ctx context.Context, id int64) (domain.Addon, error)
+	CreateAddon(ctx context.Context, addon *domain.Addon) error
+	UpdateAddon(ctx context.Context, addon domain.Addon) error
+	DeleteAddon(ctx context.Context, id int64) error
+}
+
 type AutomationLogRepository interface {
 	CreateAutomationLog(ctx context.Context, log *domain.AutomationLog) error
 	ListAutomationLogs(ctx context.Context, orderID int64, limit, offset int) ([]domain.AutomationLog, int, error)
@@ -221,6 +236,24 @@ type ProvisionJobRepository interface {
 	CreateOrUpdateProvisionJob(ctx context.Context, job *domain.ProvisionJob) error
 	ListDueProvisionJobs(ctx context.Context, limit int) ([]domain.ProvisionJob, error)
 	UpdateProvisionJob(ctx context.Context, job domain.ProvisionJob) error
+	ProvisionJobStats(ctx context.Context) ([]domain.ProvisionJobStat, error)
+}
+
+type InventoryRepository interface {
+	// ReserveInventory atomically holds stock for every reservation or none;
+	// it fails with domain.ErrInsufficientStock when a package or plan group
+	// cannot cover the requested quantity.
+	ReserveInventory(ctx context.Context, reservations []domain.InventoryReservation) error
+	ReleaseInventoryReservations(ctx context.Context, orderID int64) (int, error)
+	// CommitInventoryReservation finalizes the reservation of an order item;
+	// consume also decrements the local capacity until the next upstream sync.
+	CommitInventoryReservation(ctx context.Context, orderItemID int64, consume bool) (bool, error)
+	ListInventoryReservations(ctx context.Context, status domain.InventoryReservationStatus, limit int) ([]domain.InventoryReservation, error)
+	ListExpiredInventoryReservations(ctx context.Context, now time.Time, limit int) ([]domain.InventoryReservation, error)
+	ClearInventoryReservationExpiry(ctx context.Context, orderID int64) error
+	// RecountInventoryReserved rebuilds the reserved counters of packages and
+	// plan groups from active reservations.
+	RecountInventoryReserved(ctx context.Context) error
 }
 
 type ResizeTaskRepository interface {
@@ -231,6 +264,70 @@ type ResizeTaskRepository interface {
 	HasPendingResizeTask(ctx context.Context, vpsID int64) (bool, error)
 }
 
+type VPSBackupPolicyRepository interface {
+	ListVPSBackupPolicies(ctx context.Context, vpsID int64) ([]domain.VPSBackupPolicy, error)
+	GetVPSBackupPolicy(ctx context.Context, vpsID int64, kind domain.VPSBackupPolicyKind) (domain.VPSBackupPolicy, error)
+	UpsertVPSBackupPolicy(ctx context.Context, policy *domain.VPSBackupPolicy) error
+	DeleteVPSBackupPolicy(ctx context.Context, vpsID int64, kind domain.VPSBackupPolicyKind) error
+	ListDueVPSBackupPolicies(ctx context.Context, now time.Time, limit int) ([]domain.VPSBackupPolicy, error)
+	UpdateVPSBackupPolicyRun(ctx context.Context, policy domain.VPSBackupPolicy) error
+}
+
+type VPSMigrationRepository interface {
+	CreateVPSMigration(ctx context.Context, m *domain.VPSMigration) error
+	GetVPSMigration(ctx context.Context, id int64) (domain.VPSMigration, error)
+	GetRunningVPSMigration(ctx context.Context, vpsID int64) (domain.VPSMigration, error)
+	ListVPSMigrations(ctx context.Context, vpsID int64, limit, offset int) ([]domain.VPSMigration, int, error)
+	ListDueVPSMigrations(ctx context.Context, now time.Time, limit int) ([]domain.VPSMigration, error)
+	UpdateVPSMigration(ctx context.Context, m domain.VPSMigration) error
+}
+
+type IPAddressRepository interface {
+	CreateIPAddress(ctx context.Context, ip *domain.IPAddress) error
+	GetIPAddress(ctx context.Context, id int64) (domain.IPAddress, error)
+	GetIPAddressByAddress(ctx context.Context, address string) (domain.IPAddress, error)
+	ListIPAddresses(ctx context.Context, filter appshared.IPAddressFilter, limit, offset int) ([]domain.IPAddress, int, error)
+	ListIPAddressesByVPS(ctx context.Context, vpsID int64) ([]domain.IPAddress, error)
+	UpdateIPAddress(ctx context.Context, ip domain.IPAddress) error
+	DeleteIPAddress(ctx context.Context, id int64) error
+	CreateIPAddressEvent(ctx context.Context, ev *domain.IPAddressEvent) error
+	ListIPAddressEvents(ctx context.Context, ipAddressID int64, limit, offset int) ([]domain.IPAddressEvent, int, error)
+}
+
+type VPSTrialRepository interface {
+	CreateVPSTrial(ctx context.Context, trial *domain.VPSTrial) error
+	GetVPSTrialByOrderItem(ctx context.Context, orderItemID int64) (domain.VPSTrial, error)
+	UpdateVPSTrial(ctx context.Context, trial domain.VPSTrial) error
+	CountVPSTrials(ctx context.Context, filter appshared.VPSTrialFilter) (int, error)
+	ListVPSTrials(ctx context.Context, filter appshared.VPSTrialFilter, limit, offset int) ([]domain.VPSTrial, int, error)
+	ListDueVPSTrials(ctx context.Context, now time.Time, limit int) ([]domain.VPSTrial, error)
+	VPSTrialStats(ctx context.Context, from, to time.Time) ([]domain.VPSTrialStat, error)
+}
+
+type OrderApprovalRepository interface {
+	ListOrderApprovalRules(ctx context.Context) ([]domain.OrderApprovalRule, error)
+	GetOrderApprovalRule(ctx context.Context, id int64) (domain.OrderApprovalRule, error)
+	CreateOrderApprovalRule(ctx context.Context, rule *domain.OrderApprovalRule) error
+	UpdateOrderApprovalRule(ctx context.Context, rule domain.OrderApprovalRule) error
+	DeleteOrderApprovalRule(ctx context.Context, id int64) error
+	CreateOrderApprovalEvaluation(ctx context.Context, eval *domain.OrderApprovalEvaluation) error
+	GetOrderApprovalEvaluation(ctx context.Context, orderID int64) (domain.OrderApprovalEvaluation, error)
+	CountRefundOrders(ctx context.Context, userID int64, from, to time.Time) (int, error)
+}
+
+type VPSBulkJobRepository interface {
+	MatchVPSBulkTargets(ctx context.Context, filter domain.VPSBulkFilter, limit int) ([]int64, error)
+	CreateVPSBulkJob(ctx context.Context, job *domain.VPSBulkJob, vpsIDs []int64) error
+	GetVPSBulkJob(ctx context.Context, id int64) (domain.VPSBulkJob, error)
+	ListVPSBulkJobs(ctx context.Context, limit, offset int) ([]domain.VPSBulkJob, int, error)
+	UpdateVPSBulkJob(ctx context.Context, job domain.VPSBulkJob) error
+	ListVPSBulkJobItems(ctx context.Context, jobID int64, status domain.VPSBulkItemStatus) ([]domain.VPSBulkJobItem, error)
+	ListVPSBulkJobItemsAfter(ctx context.Context, jobID, afterSeq int64, limit int) ([]domain.VPSBulkJobItem, error)
+	UpdateVPSBulkJobItem(ctx context.Context, item domain.VPSBulkJobItem) error
+	CancelPendingVPSBulkJobItems(ctx context.Context, jobID int64, at time.Time) (int, error)
+	ListRunningVPSBulkJobs(ctx context.Context) ([]domain.VPSBulkJob, error)
+}
+
 type ScheduledTaskRunRepository interface {
 	CreateTaskRun(ctx context.Context, run *domain.ScheduledTaskRun) error
 	UpdateTaskRun(ctx context.Context, run domain.ScheduledTaskRun) error
@@ -348,6 +445,7 @@ type CMSBlockRepository interface {
 type UploadRepository interface {
 	CreateUpload(ctx context.Context, upload *domain.Upload) error
 	ListUploads(ctx context.Context, limit, offset int) ([]domain.Upload, int, error)
+	GetUpload(ctx context.Context, id int64) (domain.Upload, error)
 }
 
 type TicketRepository interface {
@@ -369,6 +467,45 @@ type NotificationRepository interface {
 	MarkAllRead(ctx context.Context, userID int64) error
 }
 
+type NotificationPreferenceRepository interface {
+	GetNotificationPreferences(ctx context.Context, userID int64) (domain.NotificationPreferences, error)
+	UpsertNotificationPreferences(ctx context.Context, prefs *domain.NotificationPreferences) error
+}
+
+// NotificationPreferenceGate decides whether a user wants a notification type
+// on a channel. The message and push services check it before every send, and
+// hold SMS and push back until QuietUntil when it reports quiet hours.
+type NotificationPreferenceGate interface {
+	Allow(ctx context.Context, userID int64, event, channel string) bool
+	QuietUntil(ctx context.Context, userID int64, event, channel string) (time.Time, bool)
+}
+
+// DeferredNotificationRepository stores SMS and push delayed by quiet hours.
+type DeferredNotificationRepository interface {
+	CreateDeferredNotification(ctx context.Context, item *domain.DeferredNotification) error
+	ListDueDeferredNotifications(ctx context.Context, channel string, now time.Time, limit int) ([]domain.DeferredNotification, error)
+	DeleteDeferredNotification(ctx context.Context, id int64) error
+}
+
+type BroadcastRepository interface {
+	CreateBroadcast(ctx context.Context, broadcast *domain.Broadcast) error
+	GetBroadcast(ctx context.Context, id int64) (domain.Broadcast, error)
+	ListBroadcasts(ctx context.Context, status string, limit, offset int) ([]domain.Broadcast, int, error)
+	ListActiveBroadcasts(ctx context.Context, now time.Time, limit int) ([]domain.Broadcast, error)
+	StartBroadcast(ctx context.Context, id int64, total int, startedAt time.Time) (bool, error)
+	FinishBroadcast(ctx context.Context, id int64, finishedAt time.Time) (bool, error)
+	CancelBroadcast(ctx context.Context, id int64, canceledAt time.Time) (bool, error)
+	RefreshBroadcastCounters(ctx context.Context, id int64) error
+	ListBroadcastRecipients(ctx context.Context, segment domain.BroadcastSegment, afterUserID int64, limit int) ([]int64, error)
+	CountBroadcastRecipients(ctx context.Context, segment domain.BroadcastSegment) (int, error)
+	CreateBroadcastDeliveries(ctx context.Context, deliveries []domain.BroadcastDelivery) error
+	ListPendingBroadcastDeliveries(ctx context.Context, broadcastID int64, channel string, limit int) ([]domain.BroadcastDelivery, error)
+	UpdateBroadcastDelivery(ctx context.Context, delivery domain.BroadcastDelivery) error
+	ListBroadcastDeliveries(ctx context.Context, broadcastID int64, channel, status string, limit, offset int) ([]domain.BroadcastDelivery, int, error)
+	GetBroadcastStats(ctx context.Context, broadcastID int64) (domain.BroadcastStats, error)
+	MarkBroadcastDeliveryClicked(ctx context.Context, token string, clickedAt time.Time) (domain.BroadcastDelivery, error)
+}
+
 type PushTokenRepository interface {
 	UpsertPushToken(ctx context.Context, token *domain.PushToken) error
 	DeletePushToken(ctx context.Context, userID int64, token string) error
@@ -392,6 +529,83 @@ type WalletRepository interface {
 	HasWalletTransaction(ctx context.Context, userID int64, refType string, refID int64) (bool, error)
 }
 
+type WalletHoldRepository interface {
+	CreateWalletHold(ctx context.Context, hold *domain.WalletHold) error
+	GetActiveWalletHold(ctx context.Context, refType string, refID int64) (domain.WalletHold, error)
+	CaptureWalletHold(ctx context.Context, refType string, refID int64, txType, note string) (domain.Wallet, error)
+	ReleaseWalletHold(ctx context.Context, refType string, refID int64, note string) (bool, error)
+	ListWalletHolds(ctx context.Context, userID int64, status string, limit, offset int) ([]domain.WalletHold, int, error)
+}
+
+type LedgerRepository interface {
+	// InTx runs fn in one transaction; repository calls made with the ctx
+	// passed to fn join it.
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+	CreateLedgerEntry(ctx context.Context, entry *domain.LedgerEntry) (bool, error)
+	ListLedgerEntries(ctx context.Context, filter appshared.LedgerEntryFilter, limit, offset int) ([]domain.LedgerEntry, int, error)
+	ListLedgerAccountBalances(ctx context.Context) ([]domain.LedgerAccountBalance, error)
+	LedgerWalletBalances(ctx context.Context) (map[int64]int64, error)
+	ListUnbalancedLedgerEntries(ctx context.Context, limit int) ([]int64, error)
+	ListWalletBalances(ctx context.Context) (map[int64]int64, error)
+}
+
+type PayoutRepository interface {
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+	CreatePayoutAccount(ctx context.Context, account *domain.PayoutAccount) error
+	GetPayoutAccount(ctx context.Context, id int64) (domain.PayoutAccount, error)
+	ListPayoutAccounts(ctx context.Context, userID int64, status string, limit, offset int) ([]domain.PayoutAccount, int, error)
+	UpdatePayoutAccountStatus(ctx context.Context, id int64, status domain.PayoutAccountStatus, reviewedBy int64, reason string) error
+	DeletePayoutAccount(ctx context.Context, userID, id int64) error
+	ListUnbatchedWithdrawOrders(ctx context.Context, orderIDs []int64, limit int) ([]domain.WalletOrder, error)
+	CreatePayoutBatch(ctx context.Context, batch *domain.PayoutBatch) error
+	GetPayoutBatch(ctx context.Context, id int64) (domain.PayoutBatch, error)
+	ListPayoutBatches(ctx context.Context, status string, limit, offset int) ([]domain.PayoutBatch, int, error)
+	MarkPayoutBatchExported(ctx context.Context, id int64) error
+	SettlePayoutBatchItem(ctx context.Context, itemID int64, status domain.PayoutItemStatus, reference, reason string) (bool, error)
+	UpdatePayoutBatchStatus(ctx context.Context, id int64, status domain.PayoutBatchStatus, reference string) error
+}
+
+type DunningRepository interface {
+	ListDunningPolicies(ctx context.Context) ([]domain.DunningPolicy, error)
+	GetDunningPolicy(ctx context.Context, goodsTypeID int64) (domain.DunningPolicy, error)
+	UpsertDunningPolicy(ctx context.Context, policy *domain.DunningPolicy) error
+	DeleteDunningPolicy(ctx context.Context, goodsTypeID int64) error
+	ListDunningCycleRecords(ctx context.Context, instanceID int64, cycleExpireAt time.Time) ([]domain.DunningRecord, error)
+	// SaveDunningRecord inserts the record or, for the same instance, cycle
+	// and step, replaces the outcome and bumps Attempts.
+	SaveDunningRecord(ctx context.Context, record *domain.DunningRecord) error
+	ListDunningRecords(ctx context.Context, filter appshared.DunningRecordFilter, limit, offset int) ([]domain.DunningRecord, int, error)
+	// ListPaidRenewalItems returns the user's renew order items whose order
+	// was paid but has not finished provisioning yet.
+	ListPaidRenewalItems(ctx context.Context, userID int64) ([]domain.OrderItem, error)
+}
+
+// StatementRepository exposes wallet transactions by time so statements can
+// derive balances at arbitrary points from the current balance.
+type StatementRepository interface {
+	ListWalletTransactionsBetween(ctx context.Context, userID int64, from, to time.Time) ([]domain.WalletTransaction, error)
+	SumWalletTransactionsSince(ctx context.Context, userID int64, since time.Time) (int64, error)
+}
+
+type FinanceReportRepository interface {
+	CreateFinanceReportDefinition(ctx context.Context, def *domain.FinanceReportDefinition) error
+	GetFinanceReportDefinition(ctx context.Context, id int64) (domain.FinanceReportDefinition, error)
+	ListFinanceReportDefinitions(ctx context.Context) ([]domain.FinanceReportDefinition, error)
+	UpdateFinanceReportDefinition(ctx context.Context, def domain.FinanceReportDefinition) error
+	DeleteFinanceReportDefinition(ctx context.Context, id int64) error
+	TouchFinanceReportDefinition(ctx context.Context, id int64, ranAt time.Time) error
+	CreateFinanceReportRun(ctx context.Context, run *domain.FinanceReportRun) error
+	GetFinanceReportRun(ctx context.Context, id int64) (domain.FinanceReportRun, error)
+	ListFinanceReportRuns(ctx context.Context, definitionID int64, limit, offset int) ([]domain.FinanceReportRun, int, error)
+	HasScheduledFinanceReportRun(ctx context.Context, definitionID int64, periodStart time.Time) (bool, error)
+	UpdateFinanceReportRunDelivery(ctx context.Context, id int64, status domain.FinanceReportRunStatus, errMsg string, deliveredAt *time.Time) error
+	GetFinanceReportFile(ctx context.Context, runID, fileID int64) (domain.FinanceReportFile, error)
+}
+
+type WalletStatsRepository interface {
+	WalletTotals(ctx context.Context) (domain.WalletTotals, error)
+}
+
 type WalletOrderRepository interface {
 	CreateWalletOrder(ctx context.Context, order *domain.WalletOrder) error
 	GetWalletOrder(ctx context.Context, id int64) (domain.WalletOrder, error)
@@ -412,6 +626,14 @@ type ProbeNodeRepository interface {
 	UpdateProbeNodeStatus(ctx context.Context, id int64, status domain.ProbeStatus, reason string, at time.Time) error
 	UpdateProbeNodeHeartbeat(ctx context.Context, id int64, at time.Time) error
 	UpdateProbeNodeSnapshot(ctx context.Context, id int64, at time.Time, snapshotJSON string, osType string) error
+	UpdateProbeNodeAgentVersion(ctx context.Context, id int64, version string) error
+	UpdateProbeNodeAgentUpdate(ctx context.Context, id int64, status domain.ProbeAgentUpdateStatus, message string, at time.Time) error
+}
+
+type ProbeReleaseStore interface {
+	ListProbeReleases(ctx context.Context) ([]domain.ProbeAgentRelease, error)
+	GetProbeRelease(ctx context.Context, version string) (domain.ProbeAgentRelease, error)
+	OpenProbeReleaseFile(ctx context.Context, version, relPath string) (io.ReadCloser, int64, error)
 }
 
 type ProbeEnrollTokenRepository interface {
@@ -453,6 +675,18 @@ type EmailSender interface {
 	Send(ctx context.Context, to string, subject string, body string) error
 }
 
+// EmailAttachment is a file attached to an outgoing email.
+type EmailAttachment struct {
+	Name        string
+	ContentType string
+	Content     []byte
+}
+
+// EmailAttachmentSender sends a plain-text or HTML body with attachments.
+type EmailAttachmentSender interface {
+	SendWithAttachments(ctx context.Context, to string, subject string, body string, attachments []EmailAttachment) error
+}
+
 type RealNameRepository interface {
 	CreateRealNameVerification(ctx context.Context, record *domain.RealNameVerification) error
 	GetLatestRealNameVerification(ctx context.Context, userID int64) (domain.RealNameVerification, error)
@@ -460,6 +694,28 @@ type RealNameRepository interface {
 	UpdateRealNameStatus(ctx context.Context, id int64, status string, reason string, verifiedAt *time.Time) error
 }
 
+// RealNameReviewRepository backs the manual real-name review queue.
+// ReviewRealNameVerification only updates records still pending review and
+// reports whether it did.
+type RealNameReviewRepository interface {
+	GetRealNameVerification(ctx context.Context, id int64) (domain.RealNameVerification, error)
+	ListRealNameVerificationsByStatus(ctx context.Context, status string, limit, offset int) ([]domain.RealNameVerification, int, error)
+	ReviewRealNameVerification(ctx context.Context, id int64, status, reason string, reviewerID int64, reviewedAt time.Time) (bool, error)
+}
+
+type CompanyVerificationRepository interface {
+	CreateCompanyVerification(ctx context.Context, record *domain.CompanyVerification) error
+	GetCompanyVerification(ctx context.Context, id int64) (domain.CompanyVerification, error)
+	GetLatestCompanyVerification(ctx context.Context, userID int64) (domain.CompanyVerification, error)
+	ListCompanyVerifications(ctx context.Context, userID *int64, status string, limit, offset int) ([]domain.CompanyVerification, int, error)
+	ReviewCompanyVerification(ctx context.Context, id int64, status, reason string, reviewerID int64, reviewedAt time.Time) (bool, error)
+}
+
 type SMSSender interface {
 	Send(ctx context.Context, pluginID, instanceID string, msg appshared.SMSMessage) (appshared.SMSDelivery, error)
 }
+
+type NotifyChannelSender interface {
+	Send(ctx context.Context, pluginID, instanceID string, msg appshared.NotifyMessage) (appshared.NotifyDelivery, error)
+	ListChannels(ctx context.Context) ([]appshared.NotifyChannel, error)
+}
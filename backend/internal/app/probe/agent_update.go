@@ -0,0 +1,101 @@
+package probe
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	appports "xiaoheiplay/internal/app/ports"
+	"xiaoheiplay/internal/domain"
+)
+
+const (
+	settingAgentDesiredVersion = "probe_agent_desired_version"
+	settingAgentRollbackSec    = "probe_agent_update_rollback_sec"
+)
+
+// AgentUpdateConfig is advertised to pingbot in set_config/hello_ack.
+type AgentUpdateConfig struct {
+	DesiredVersion     string
+	RollbackTimeoutSec int
+}
+
+func (s *Service) SetReleaseStore(releases appports.ProbeReleaseStore) {
+	s.releases = releases
+}
+
+func (s *Service) ListAgentReleases(ctx context.Context) ([]domain.ProbeAgentRelease, error) {
+	if s.releases == nil {
+		return nil, domain.ErrProbeReleasesDisabled
+	}
+	return s.releases.ListProbeReleases(ctx)
+}
+
+func (s *Service) AgentUpdateConfig(ctx context.Context) AgentUpdateConfig {
+	cfg := AgentUpdateConfig{
+		RollbackTimeoutSec: s.getIntSetting(ctx, settingAgentRollbackSec, 120),
+	}
+	if cfg.RollbackTimeoutSec <= 0 {
+		cfg.RollbackTimeoutSec = 120
+	}
+	if s.settings != nil {
+		if item, err := s.settings.GetSetting(ctx, settingAgentDesiredVersion); err == nil {
+			cfg.DesiredVersion = strings.TrimSpace(item.ValueJSON)
+		}
+	}
+	return cfg
+}
+
+// SetDesiredAgentVersion pins the pingbot version every probe should run.
+// An empty version disables self-update.
+func (s *Service) SetDesiredAgentVersion(ctx context.Context, version string) error {
+	if s.settings == nil {
+		return domain.ErrProbeReleasesDisabled
+	}
+	version = strings.TrimSpace(version)
+	if version != "" {
+		if s.releases == nil {
+			return domain.ErrProbeReleasesDisabled
+		}
+		release, err := s.releases.GetProbeRelease(ctx, version)
+		if err != nil {
+			return err
+		}
+		if !release.Valid {
+			return domain.ErrProbeReleaseInvalid
+		}
+		if release.SignatureStatus == domain.PluginSignatureUnsigned {
+			return domain.ErrProbeReleaseUnsigned
+		}
+	}
+	return s.settings.UpsertSetting(ctx, domain.Setting{Key: settingAgentDesiredVersion, ValueJSON: version})
+}
+
+func (s *Service) OpenAgentReleaseFile(ctx context.Context, version, relPath string) (io.ReadCloser, int64, error) {
+	if s.releases == nil {
+		return nil, 0, domain.ErrProbeReleasesDisabled
+	}
+	return s.releases.OpenProbeReleaseFile(ctx, version, relPath)
+}
+
+func (s *Service) HandleAgentHello(ctx context.Context, probeID int64, agentVersion string) error {
+	agentVersion = strings.TrimSpace(agentVersion)
+	if agentVersion == "" {
+		return nil
+	}
+	return s.nodes.UpdateProbeNodeAgentVersion(ctx, probeID, agentVersion)
+}
+
+func (s *Service) HandleAgentUpdateStatus(ctx context.Context, probeID int64, status domain.ProbeAgentUpdateStatus, message string) error {
+	switch status {
+	case domain.ProbeAgentUpdateDownloading, domain.ProbeAgentUpdateRestarting, domain.ProbeAgentUpdateApplied,
+		domain.ProbeAgentUpdateFailed, domain.ProbeAgentUpdateRolledBack:
+	default:
+		return domain.ErrInvalidStatus
+	}
+	if len(message) > 512 {
+		message = message[:512]
+	}
+	return s.nodes.UpdateProbeNodeAgentUpdate(ctx, probeID, status, message, time.Now())
+}
@@ -72,6 +72,23 @@ func (h *Hub) IsOnline(probeID int64) bool {
 	return ok
 }
 
+func (h *Hub) OnlineCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.conns)
+}
+
+// SubscriberCount returns how many SSE clients are following probe log sessions.
+func (h *Hub) SubscriberCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	n := 0
+	for _, session := range h.sessions {
+		n += len(session.subs)
+	}
+	return n
+}
+
 func (h *Hub) SendJSON(probeID int64, payload any) error {
 	h.mu.RLock()
 	conn := h.conns[probeID]
@@ -86,6 +103,30 @@ func (h *Hub) SendJSON(probeID int64, payload any) error {
 	return conn.send(b)
 }
 
+// BroadcastJSON sends payload to every connected probe and returns how many accepted it.
+func (h *Hub) BroadcastJSON(payload any) int {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return 0
+	}
+	h.mu.RLock()
+	conns := make([]*probeConnState, 0, len(h.conns))
+	for _, conn := range h.conns {
+		conns = append(conns, conn)
+	}
+	h.mu.RUnlock()
+	sent := 0
+	for _, conn := range conns {
+		if conn == nil || conn.send == nil {
+			continue
+		}
+		if conn.send(b) == nil {
+			sent++
+		}
+	}
+	return sent
+}
+
 func (h *Hub) OpenLogSession(sessionID string, probeID int64, ttl time.Duration) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -31,6 +31,7 @@ type Service struct {
 	events   appports.ProbeStatusEventRepository
 	sessions appports.ProbeLogSessionRepository
 	settings appports.SettingsRepository
+	releases appports.ProbeReleaseStore
 }
 
 func NewService(nodes appports.ProbeNodeRepository, tokens appports.ProbeEnrollTokenRepository, events appports.ProbeStatusEventRepository, sessions appports.ProbeLogSessionRepository, settings appports.SettingsRepository) *Service {
@@ -2,6 +2,8 @@ package push
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -22,8 +24,13 @@ type Service struct {
 	users    appports.UserRepository
 	settings appports.SettingsRepository
 	sender   appports.PushSender
+	prefs    appports.NotificationPreferenceGate
+	deferred appports.DeferredNotificationRepository
 }
 
+// deferredPerRun caps how many held pushes SendDeferred sends per call.
+const deferredPerRun = 200
+
 func NewService(tokens appports.PushTokenRepository, users appports.UserRepository, settings appports.SettingsRepository, sender appports.PushSender) *Service {
 	return &Service{
 		tokens:   tokens,
@@ -33,6 +40,16 @@ func NewService(tokens appports.PushTokenRepository, users appports.UserReposito
 	}
 }
 
+func (s *Service) SetNotificationPreferences(prefs appports.NotificationPreferenceGate) {
+	s.prefs = prefs
+}
+
+// SetDeferredQueue stores pushes that arrive during the user's quiet hours so
+// SendDeferred can send them when the window ends.
+func (s *Service) SetDeferredQueue(deferred appports.DeferredNotificationRepository) {
+	s.deferred = deferred
+}
+
 func (s *Service) RegisterToken(ctx context.Context, userID int64, platform, token, deviceID string) error {
 	if s.tokens == nil {
 		return appshared.ErrInvalidInput
@@ -73,13 +90,11 @@ func (s *Service) RemoveToken(ctx context.Context, userID int64, token string) e
 	return s.tokens.DeletePushToken(ctx, userID, token)
 }
 
-func (s *Service) NotifyAdminsNewOrder(ctx context.Context, order domain.Order) error {
-	if s.sender == nil || s.tokens == nil || s.users == nil || s.settings == nil {
-		return nil
-	}
+// config returns the FCM settings, or false when push is off or not set up.
+func (s *Service) config(ctx context.Context) (PushConfig, bool) {
 	enabled, _ := s.settings.GetSetting(ctx, "fcm_enabled")
 	if enabled.ValueJSON != "" && strings.ToLower(enabled.ValueJSON) != "true" {
-		return nil
+		return PushConfig{}, false
 	}
 	cfg := PushConfig{}
 	if projectSetting, err := s.settings.GetSetting(ctx, "fcm_project_id"); err == nil {
@@ -92,8 +107,136 @@ func (s *Service) NotifyAdminsNewOrder(ctx context.Context, order domain.Order)
 		cfg.LegacyServerKey = strings.TrimSpace(keySetting.ValueJSON)
 	}
 	if (cfg.ProjectID == "" || cfg.ServiceAccountJSON == "") && cfg.LegacyServerKey == "" {
+		return PushConfig{}, false
+	}
+	return cfg, true
+}
+
+// NotifyUser pushes a notification of type typ to every device the user
+// registered. Unlike the admin order push it reports why nothing was sent,
+// including domain.ErrNotificationMuted, so callers can record it. During
+// the user's quiet hours the push is queued and nil is returned; without a
+// queue it is reported as muted.
+func (s *Service) NotifyUser(ctx context.Context, userID int64, typ string, payload PushPayload) error {
+	if s.sender == nil || s.tokens == nil || s.settings == nil {
+		return domain.ErrPushNotConfigured
+	}
+	if !s.allow(ctx, userID, typ) {
+		return domain.ErrNotificationMuted
+	}
+	if until, quiet := s.quietUntil(ctx, userID, typ); quiet {
+		return s.deferPush(ctx, userID, typ, payload, until)
+	}
+	cfg, ok := s.config(ctx)
+	if !ok {
+		return domain.ErrPushNotConfigured
+	}
+	tokens, err := s.tokens.ListPushTokensByUserIDs(ctx, []int64{userID})
+	if err != nil {
+		return err
+	}
+	list := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		if t.Token != "" {
+			list = append(list, t.Token)
+		}
+	}
+	if len(list) == 0 {
+		return domain.ErrPushTokenNotBound
+	}
+	return s.sender.Send(ctx, cfg, list, payload)
+}
+
+func (s *Service) allow(ctx context.Context, userID int64, typ string) bool {
+	return s.prefs == nil || s.prefs.Allow(ctx, userID, typ, domain.NotificationChannelPush)
+}
+
+func (s *Service) quietUntil(ctx context.Context, userID int64, typ string) (time.Time, bool) {
+	if s.prefs == nil {
+		return time.Time{}, false
+	}
+	return s.prefs.QuietUntil(ctx, userID, typ, domain.NotificationChannelPush)
+}
+
+// deferPush queues a push held by quiet hours until the window ends.
+func (s *Service) deferPush(ctx context.Context, userID int64, typ string, payload PushPayload, until time.Time) error {
+	if s.deferred == nil {
+		return domain.ErrNotificationMuted
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return s.deferred.CreateDeferredNotification(ctx, &domain.DeferredNotification{
+		UserID:      userID,
+		Channel:     domain.NotificationChannelPush,
+		Type:        typ,
+		PayloadJSON: string(raw),
+		SendAfter:   until,
+	})
+}
+
+// SendDeferred sends the pushes held by quiet hours whose window has ended
+// and returns how many were sent. Each push goes through NotifyUser again, so
+// a user who has since muted the type does not get it. Delivered or not, the
+// queued row is removed.
+func (s *Service) SendDeferred(ctx context.Context) (int, error) {
+	if s.deferred == nil {
+		return 0, nil
+	}
+	items, err := s.deferred.ListDueDeferredNotifications(ctx, domain.NotificationChannelPush, time.Now(), deferredPerRun)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	var errs []error
+	for _, item := range items {
+		var payload PushPayload
+		if err := json.Unmarshal([]byte(item.PayloadJSON), &payload); err == nil {
+			switch err := s.NotifyUser(ctx, item.UserID, item.Type, payload); {
+			case err == nil:
+				sent++
+			case !errors.Is(err, domain.ErrNotificationMuted):
+				errs = append(errs, fmt.Errorf("deferred push %d: %w", item.ID, err))
+			}
+		}
+		if err := s.deferred.DeleteDeferredNotification(ctx, item.ID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (s *Service) NotifyAdminsNewOrder(ctx context.Context, order domain.Order) error {
+	if s.sender == nil || s.tokens == nil || s.users == nil || s.settings == nil {
+		return nil
+	}
+	cfg, ok := s.config(ctx)
+	if !ok {
 		return nil
 	}
+	orderNo := strings.TrimSpace(order.OrderNo)
+	if orderNo == "" {
+		orderNo = fmt.Sprintf("#%d", order.ID)
+	}
+	currency := strings.TrimSpace(order.Currency)
+	if currency == "" {
+		currency = "CNY"
+	}
+	amount := money.FormatCents(order.TotalAmount)
+	title := "新订单待审核"
+	body := fmt.Sprintf("订单 %s 金额 %s %s", orderNo, amount, currency)
+	payload := PushPayload{
+		Title: title,
+		Body:  body,
+		Data: map[string]string{
+			"order_id": fmt.Sprintf("%d", order.ID),
+			"order_no": order.OrderNo,
+			"status":   string(order.Status),
+			"amount":   amount,
+			"currency": currency,
+		},
+	}
 	adminIDs := make([]int64, 0, 8)
 	offset := 0
 	for {
@@ -105,6 +248,13 @@ func (s *Service) NotifyAdminsNewOrder(ctx context.Context, order domain.Order)
 			break
 		}
 		for _, admin := range admins {
+			if !s.allow(ctx, admin.ID, "order_new") {
+				continue
+			}
+			if until, quiet := s.quietUntil(ctx, admin.ID, "order_new"); quiet {
+				_ = s.deferPush(ctx, admin.ID, "order_new", payload, until)
+				continue
+			}
 			adminIDs = append(adminIDs, admin.ID)
 		}
 		offset += len(admins)
@@ -137,27 +287,5 @@ func (s *Service) NotifyAdminsNewOrder(ctx context.Context, order domain.Order)
 	if len(uniqueTokens) == 0 {
 		return nil
 	}
-	orderNo := strings.TrimSpace(order.OrderNo)
-	if orderNo == "" {
-		orderNo = fmt.Sprintf("#%d", order.ID)
-	}
-	currency := strings.TrimSpace(order.Currency)
-	if currency == "" {
-		currency = "CNY"
-	}
-	amount := money.FormatCents(order.TotalAmount)
-	title := "新订单待审核"
-	body := fmt.Sprintf("订单 %s 金额 %s %s", orderNo, amount, currency)
-	payload := PushPayload{
-		Title: title,
-		Body:  body,
-		Data: map[string]string{
-			"order_id": fmt.Sprintf("%d", order.ID),
-			"order_no": order.OrderNo,
-			"status":   string(order.Status),
-			"amount":   amount,
-			"currency": currency,
-		},
-	}
 	return s.sender.Send(ctx, cfg, uniqueTokens, payload)
 }
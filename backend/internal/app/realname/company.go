@@ -0,0 +1,313 @@
+package realname
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+const maxCompanyRequirements = 20
+
+// creditCodeChars are the characters allowed in a unified social credit code.
+const creditCodeChars = "0123456789ABCDEFGHJKLMNPQRTUWXY"
+
+// CompanyConfig returns whether company verification is open and the
+// requirements that demand it.
+func (s *Service) CompanyConfig(ctx context.Context) appshared.CompanyConfig {
+	cfg := appshared.CompanyConfig{}
+	if s.settings == nil {
+		return cfg
+	}
+	if setting, err := s.settings.GetSetting(ctx, "realname_company_enabled"); err == nil {
+		cfg.Enabled = strings.ToLower(strings.TrimSpace(setting.ValueJSON)) == "true"
+	}
+	if setting, err := s.settings.GetSetting(ctx, "realname_company_requirements"); err == nil && strings.TrimSpace(setting.ValueJSON) != "" {
+		var list []appshared.CompanyRequirement
+		if err := json.Unmarshal([]byte(setting.ValueJSON), &list); err == nil {
+			cfg.Requirements = list
+		}
+	}
+	return cfg
+}
+
+func (s *Service) UpdateCompanyConfig(ctx context.Context, cfg appshared.CompanyConfig) error {
+	if s.settings == nil {
+		return appshared.ErrInvalidInput
+	}
+	if len(cfg.Requirements) > maxCompanyRequirements {
+		return domain.ErrInvalidCompanyRequirement
+	}
+	requirements := make([]appshared.CompanyRequirement, 0, len(cfg.Requirements))
+	for _, item := range cfg.Requirements {
+		item.Action = strings.TrimSpace(item.Action)
+		if item.Action == "" || item.MinAmount < 0 {
+			return domain.ErrInvalidCompanyRequirement
+		}
+		for _, id := range item.GoodsTypeIDs {
+			if id <= 0 {
+				return domain.ErrInvalidCompanyRequirement
+			}
+		}
+		if item.GoodsTypeIDs == nil {
+			item.GoodsTypeIDs = []int64{}
+		}
+		requirements = append(requirements, item)
+	}
+	raw, _ := json.Marshal(requirements)
+	enabled := "false"
+	if cfg.Enabled {
+		enabled = "true"
+	}
+	if err := s.settings.UpsertSetting(ctx, domain.Setting{Key: "realname_company_enabled", ValueJSON: enabled}); err != nil {
+		return err
+	}
+	return s.settings.UpsertSetting(ctx, domain.Setting{Key: "realname_company_requirements", ValueJSON: string(raw)})
+}
+
+// SubmitCompany records a company verification. Providers that can verify
+// companies are tried in chain order; when none gives a result the record
+// waits for manual review of the uploaded business license.
+func (s *Service) SubmitCompany(ctx context.Context, userID int64, in appshared.CompanyVerificationInput) (domain.CompanyVerification, error) {
+	if s.companies == nil || s.uploads == nil {
+		return domain.CompanyVerification{}, appshared.ErrInvalidInput
+	}
+	if !s.CompanyConfig(ctx).Enabled {
+		return domain.CompanyVerification{}, appshared.ErrForbidden
+	}
+	in, err := normalizeCompanyInput(in)
+	if err != nil {
+		return domain.CompanyVerification{}, err
+	}
+	if err := s.checkReviewImages(ctx, userID, in.UploadIDs); err != nil {
+		return domain.CompanyVerification{}, err
+	}
+	if latest, err := s.companies.GetLatestCompanyVerification(ctx, userID); err == nil {
+		switch latest.Status {
+		case domain.RealNameStatusPendingReview:
+			return domain.CompanyVerification{}, domain.ErrRealNameReviewInProgress
+		case "verified":
+			return domain.CompanyVerification{}, appshared.ErrConflict
+		}
+	}
+	record := domain.CompanyVerification{
+		UserID:              userID,
+		CompanyName:         in.CompanyName,
+		CreditCode:          in.CreditCode,
+		LegalPersonName:     in.LegalPersonName,
+		LegalPersonIDNumber: in.LegalPersonIDNumber,
+		InvoiceTitle:        in.InvoiceTitle,
+		TaxNumber:           in.TaxNumber,
+		InvoiceAddress:      in.InvoiceAddress,
+		InvoicePhone:        in.InvoicePhone,
+		InvoiceBank:         in.InvoiceBank,
+		InvoiceBankAccount:  in.InvoiceBankAccount,
+		UploadIDs:           in.UploadIDs,
+		Status:              domain.RealNameStatusPendingReview,
+		Provider:            domain.RealNameProviderManual,
+		CreatedAt:           time.Now(),
+	}
+	if key, ok, reason, found := s.verifyCompanyWithProviders(ctx, in); found {
+		record.Provider = key
+		record.Reason = reason
+		record.Status = "failed"
+		if ok {
+			now := time.Now()
+			record.Status = "verified"
+			record.VerifiedAt = &now
+		}
+	}
+	if err := s.companies.CreateCompanyVerification(ctx, &record); err != nil {
+		return domain.CompanyVerification{}, err
+	}
+	return record, nil
+}
+
+// verifyCompanyWithProviders returns the first definite answer from a
+// provider that supports company verification.
+func (s *Service) verifyCompanyWithProviders(ctx context.Context, in appshared.CompanyVerificationInput) (string, bool, string, bool) {
+	if s.registry == nil {
+		return "", false, "", false
+	}
+	_, primary, _ := s.GetConfig(ctx)
+	chain := s.ChainConfig(ctx)
+	for _, key := range chainKeys(primary, chain) {
+		provider, err := s.registry.GetProvider(key)
+		if err != nil {
+			continue
+		}
+		verifier, ok := provider.(appshared.CompanyVerificationProvider)
+		if !ok {
+			continue
+		}
+		callCtx, cancel := context.WithTimeout(ctx, time.Duration(chain.TimeoutSec)*time.Second)
+		passed, reason, err := verifier.VerifyCompany(callCtx, in)
+		cancel()
+		if err != nil {
+			continue
+		}
+		return provider.Key(), passed, strings.TrimSpace(reason), true
+	}
+	return "", false, "", false
+}
+
+func normalizeCompanyInput(in appshared.CompanyVerificationInput) (appshared.CompanyVerificationInput, error) {
+	in.CompanyName = strings.TrimSpace(in.CompanyName)
+	in.CreditCode = strings.ToUpper(strings.TrimSpace(in.CreditCode))
+	in.LegalPersonName = strings.TrimSpace(in.LegalPersonName)
+	in.LegalPersonIDNumber = strings.TrimSpace(in.LegalPersonIDNumber)
+	in.InvoiceTitle = strings.TrimSpace(in.InvoiceTitle)
+	in.TaxNumber = strings.ToUpper(strings.TrimSpace(in.TaxNumber))
+	in.InvoiceAddress = strings.TrimSpace(in.InvoiceAddress)
+	in.InvoicePhone = strings.TrimSpace(in.InvoicePhone)
+	in.InvoiceBank = strings.TrimSpace(in.InvoiceBank)
+	in.InvoiceBankAccount = strings.TrimSpace(in.InvoiceBankAccount)
+	if in.CompanyName == "" || in.LegalPersonName == "" || in.LegalPersonIDNumber == "" || !validCreditCode(in.CreditCode) {
+		return in, domain.ErrInvalidCompanyVerification
+	}
+	if in.InvoiceTitle == "" {
+		in.InvoiceTitle = in.CompanyName
+	}
+	if in.TaxNumber == "" {
+		in.TaxNumber = in.CreditCode
+	}
+	return in, nil
+}
+
+func validCreditCode(code string) bool {
+	if len(code) != 18 {
+		return false
+	}
+	for _, ch := range code {
+		if !strings.ContainsRune(creditCodeChars, ch) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Service) LatestCompany(ctx context.Context, userID int64) (domain.CompanyVerification, error) {
+	if s.companies == nil {
+		return domain.CompanyVerification{}, appshared.ErrInvalidInput
+	}
+	return s.companies.GetLatestCompanyVerification(ctx, userID)
+}
+
+// ListCompanies lists company verifications, all of them when status is empty.
+func (s *Service) ListCompanies(ctx context.Context, userID *int64, status string, limit, offset int) ([]domain.CompanyVerification, int, error) {
+	if s.companies == nil {
+		return nil, 0, appshared.ErrInvalidInput
+	}
+	return s.companies.ListCompanyVerifications(ctx, userID, strings.TrimSpace(status), limit, offset)
+}
+
+// ReviewCompany approves or rejects a company verification waiting for
+// manual review. Rejections need a reason, which is shown to the user.
+func (s *Service) ReviewCompany(ctx context.Context, adminID, recordID int64, approve bool, reason string) (domain.CompanyVerification, error) {
+	if s.companies == nil {
+		return domain.CompanyVerification{}, appshared.ErrInvalidInput
+	}
+	reason = strings.TrimSpace(reason)
+	status := "verified"
+	if !approve {
+		status = "failed"
+		if reason == "" {
+			return domain.CompanyVerification{}, appshared.ErrInvalidInput
+		}
+	}
+	record, err := s.companies.GetCompanyVerification(ctx, recordID)
+	if err != nil {
+		return domain.CompanyVerification{}, err
+	}
+	updated, err := s.companies.ReviewCompanyVerification(ctx, recordID, status, reason, adminID, time.Now())
+	if err != nil {
+		return domain.CompanyVerification{}, err
+	}
+	if !updated {
+		return domain.CompanyVerification{}, domain.ErrRealNameNotPendingReview
+	}
+	if s.audit != nil {
+		detail, _ := json.Marshal(map[string]any{"user_id": record.UserID, "company_name": record.CompanyName, "status": status, "reason": reason})
+		_ = s.audit.AddAuditLog(ctx, domain.AdminAuditLog{AdminID: adminID, Action: "realname.company_review", TargetType: "company_verification", TargetID: strconv.FormatInt(recordID, 10), DetailJSON: string(detail)})
+	}
+	if s.messages != nil {
+		title := "企业认证已通过"
+		content := fmt.Sprintf("您提交的企业认证（%s）已审核通过。", record.CompanyName)
+		if !approve {
+			title = "企业认证未通过"
+			content = fmt.Sprintf("您提交的企业认证（%s）未通过审核，原因：%s", record.CompanyName, reason)
+		}
+		_ = s.messages.NotifyUser(ctx, record.UserID, "realname", title, content)
+	}
+	return s.companies.GetCompanyVerification(ctx, recordID)
+}
+
+// CompanyImage returns a document attached to a company verification.
+func (s *Service) CompanyImage(ctx context.Context, recordID, uploadID int64) (domain.Upload, error) {
+	if s.companies == nil || s.uploads == nil {
+		return domain.Upload{}, appshared.ErrInvalidInput
+	}
+	record, err := s.companies.GetCompanyVerification(ctx, recordID)
+	if err != nil {
+		return domain.Upload{}, err
+	}
+	for _, id := range record.UploadIDs {
+		if id == uploadID {
+			return s.uploads.GetUpload(ctx, uploadID)
+		}
+	}
+	return domain.Upload{}, appshared.ErrNotFound
+}
+
+// RequireCompanyAction returns ErrCompanyVerificationRequired when a company
+// requirement matches the action and order but the user has no verified
+// company.
+func (s *Service) RequireCompanyAction(ctx context.Context, userID int64, action string, scope appshared.RealNameActionScope) error {
+	cfg := s.CompanyConfig(ctx)
+	if !cfg.Enabled || action == "" {
+		return nil
+	}
+	required := false
+	for _, item := range cfg.Requirements {
+		if companyRequirementMatches(item, action, scope) {
+			required = true
+			break
+		}
+	}
+	if !required {
+		return nil
+	}
+	if s.companies == nil {
+		return domain.ErrCompanyVerificationRequired
+	}
+	latest, err := s.companies.GetLatestCompanyVerification(ctx, userID)
+	if err != nil || latest.Status != "verified" {
+		return domain.ErrCompanyVerificationRequired
+	}
+	return nil
+}
+
+func companyRequirementMatches(item appshared.CompanyRequirement, action string, scope appshared.RealNameActionScope) bool {
+	if !strings.EqualFold(strings.TrimSpace(item.Action), action) {
+		return false
+	}
+	if item.MinAmount > 0 && scope.Amount < item.MinAmount {
+		return false
+	}
+	if len(item.GoodsTypeIDs) == 0 {
+		return true
+	}
+	for _, want := range item.GoodsTypeIDs {
+		for _, got := range scope.GoodsTypeIDs {
+			if want == got {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package realname_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apprealname "xiaoheiplay/internal/app/realname"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+type fakeCompanyProvider struct {
+	testutil.FakeRealNameProvider
+	calls int
+}
+
+func (f *fakeCompanyProvider) VerifyCompany(ctx context.Context, in appshared.CompanyVerificationInput) (bool, string, error) {
+	f.calls++
+	return in.CompanyName == "Acme Ltd", "", nil
+}
+
+func TestRealName_CompanyVerificationAndRequirements(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "acme", "acme@example.com", "pass")
+	reg := testutil.NewFakeRealNameRegistry()
+	svc := apprealname.NewService(repo, reg, repo)
+	svc.SetManualReview(repo, repo, repo)
+	svc.SetCompanyVerification(repo)
+	if err := svc.UpdateConfig(ctx, true, "fake", nil); err != nil {
+		t.Fatalf("update config: %v", err)
+	}
+	if err := svc.UpdateCompanyConfig(ctx, appshared.CompanyConfig{Enabled: true, Requirements: []appshared.CompanyRequirement{
+		{Action: "purchase_vps", GoodsTypeIDs: []int64{7}},
+		{Action: "renew_vps", MinAmount: 100000},
+	}}); err != nil {
+		t.Fatalf("update company config: %v", err)
+	}
+	if err := svc.UpdateCompanyConfig(ctx, appshared.CompanyConfig{Requirements: []appshared.CompanyRequirement{{Action: ""}}}); !errors.Is(err, domain.ErrInvalidCompanyRequirement) {
+		t.Fatalf("expected invalid requirement, got %v", err)
+	}
+
+	purchase := appshared.RealNameActionScope{GoodsTypeIDs: []int64{3, 7}, Amount: 100}
+	if err := svc.RequireCompanyAction(ctx, user.ID, "purchase_vps", purchase); !errors.Is(err, domain.ErrCompanyVerificationRequired) {
+		t.Fatalf("expected company required for goods type 7, got %v", err)
+	}
+	if err := svc.RequireCompanyAction(ctx, user.ID, "purchase_vps", appshared.RealNameActionScope{GoodsTypeIDs: []int64{3}, Amount: 999999}); err != nil {
+		t.Fatalf("other goods types should pass: %v", err)
+	}
+	if err := svc.RequireCompanyAction(ctx, user.ID, "renew_vps", appshared.RealNameActionScope{Amount: 99999}); err != nil {
+		t.Fatalf("renewals below the amount should pass: %v", err)
+	}
+	if err := svc.RequireCompanyAction(ctx, user.ID, "renew_vps", appshared.RealNameActionScope{Amount: 100000}); !errors.Is(err, domain.ErrCompanyVerificationRequired) {
+		t.Fatalf("expected company required for large renewal, got %v", err)
+	}
+
+	license := domain.Upload{Name: "license.png", Path: "data/realname/license.png", Mime: "image/png", Size: 10, UploaderID: user.ID}
+	if err := repo.CreateUpload(ctx, &license); err != nil {
+		t.Fatalf("create upload: %v", err)
+	}
+	input := appshared.CompanyVerificationInput{
+		CompanyName:         "Acme Ltd",
+		CreditCode:          "91110000ma01abcd3x",
+		LegalPersonName:     "Alice",
+		LegalPersonIDNumber: "110101199001011234",
+		UploadIDs:           []int64{license.ID},
+	}
+	bad := input
+	bad.CreditCode = "123"
+	if _, err := svc.SubmitCompany(ctx, user.ID, bad); !errors.Is(err, domain.ErrInvalidCompanyVerification) {
+		t.Fatalf("expected invalid credit code, got %v", err)
+	}
+
+	// Without a provider that verifies companies the record waits for review.
+	record, err := svc.SubmitCompany(ctx, user.ID, input)
+	if err != nil || record.Status != domain.RealNameStatusPendingReview || record.CreditCode != "91110000MA01ABCD3X" || record.TaxNumber != record.CreditCode || record.InvoiceTitle != "Acme Ltd" {
+		t.Fatalf("submit company: %+v %v", record, err)
+	}
+	if _, err := svc.SubmitCompany(ctx, user.ID, input); !errors.Is(err, domain.ErrRealNameReviewInProgress) {
+		t.Fatalf("expected review in progress, got %v", err)
+	}
+	rejected, err := svc.ReviewCompany(ctx, 1, record.ID, false, "营业执照不清晰")
+	if err != nil || rejected.Status != "failed" {
+		t.Fatalf("reject company: %+v %v", rejected, err)
+	}
+
+	provider := &fakeCompanyProvider{FakeRealNameProvider: testutil.FakeRealNameProvider{KeyVal: "fake", NameVal: "Fake", OK: true}}
+	reg.Register(provider)
+	record, err = svc.SubmitCompany(ctx, user.ID, input)
+	if err != nil || record.Status != "verified" || record.Provider != "fake" || provider.calls != 1 {
+		t.Fatalf("provider verification: %+v %v", record, err)
+	}
+	if err := svc.RequireCompanyAction(ctx, user.ID, "purchase_vps", purchase); err != nil {
+		t.Fatalf("verified company should pass: %v", err)
+	}
+	latest, err := svc.LatestCompany(ctx, user.ID)
+	if err != nil || latest.LegalPersonIDNumber != input.LegalPersonIDNumber {
+		t.Fatalf("latest company: %+v %v", latest, err)
+	}
+}
@@ -0,0 +1,121 @@
+package realname_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apprealname "xiaoheiplay/internal/app/realname"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+type fakeMessages struct{ titles []string }
+
+func (f *fakeMessages) NotifyUser(ctx context.Context, userID int64, typ, title, content string) error {
+	f.titles = append(f.titles, title)
+	return nil
+}
+
+func TestRealName_FallsBackOnProviderError(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "rnchain", "rnchain@example.com", "pass")
+	reg := testutil.NewFakeRealNameRegistry()
+	reg.Register(&testutil.FakeRealNameProvider{KeyVal: "down", NameVal: "Down", Err: errors.New("timeout")})
+	svc := apprealname.NewService(repo, reg, repo)
+	if err := svc.UpdateConfig(ctx, true, "down", []string{"purchase_vps"}); err != nil {
+		t.Fatalf("update config: %v", err)
+	}
+
+	if _, err := svc.Verify(ctx, user.ID, "Alice", "110101199001011234"); err == nil || errors.Is(err, domain.ErrRealNameProvidersUnavailable) {
+		t.Fatalf("single provider should return its own error, got %v", err)
+	}
+	if err := svc.UpdateChainConfig(ctx, appshared.RealNameChainConfig{FallbackProviders: []string{"fake"}, TimeoutSec: 5}); err != nil {
+		t.Fatalf("update chain: %v", err)
+	}
+	record, err := svc.Verify(ctx, user.ID, "Alice", "110101199001011234")
+	if err != nil || record.Status != "verified" || record.Provider != "fake" {
+		t.Fatalf("expected fallback verification: %+v %v", record, err)
+	}
+
+	reg.Register(&testutil.FakeRealNameProvider{KeyVal: "fake", NameVal: "Fake", Err: errors.New("down too")})
+	if _, err := svc.Verify(ctx, user.ID, "Alice", "110101199001011234"); !errors.Is(err, domain.ErrRealNameProvidersUnavailable) {
+		t.Fatalf("expected providers unavailable, got %v", err)
+	}
+}
+
+func TestRealName_ManualReviewFlow(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "rnmanual", "rnmanual@example.com", "pass")
+	other := testutil.CreateUser(t, repo, "rnother", "rnother@example.com", "pass")
+	messages := &fakeMessages{}
+	svc := apprealname.NewService(repo, testutil.NewFakeRealNameRegistry(), repo)
+	svc.SetManualReview(repo, repo, repo)
+	svc.SetMessageService(messages)
+	if err := svc.UpdateConfig(ctx, true, "fake", nil); err != nil {
+		t.Fatalf("update config: %v", err)
+	}
+
+	image := domain.Upload{Name: "front.png", Path: "data/realname/front.png", Mime: "image/png", Size: 10, UploaderID: user.ID}
+	public := domain.Upload{Name: "banner.png", Path: "uploads/banner.png", URL: "/uploads/banner.png", Mime: "image/png", Size: 10, UploaderID: user.ID}
+	foreign := domain.Upload{Name: "other.png", Path: "data/realname/other.png", Mime: "image/png", Size: 10, UploaderID: other.ID}
+	for _, item := range []*domain.Upload{&image, &public, &foreign} {
+		if err := repo.CreateUpload(ctx, item); err != nil {
+			t.Fatalf("create upload: %v", err)
+		}
+	}
+	input := appshared.RealNameManualInput{RealName: "Alice", IDNumber: "110101199001011234", UploadIDs: []int64{image.ID}}
+	if _, err := svc.SubmitManual(ctx, user.ID, input); !errors.Is(err, domain.ErrRealNameManualReviewDisabled) {
+		t.Fatalf("expected manual review disabled, got %v", err)
+	}
+	if err := svc.UpdateChainConfig(ctx, appshared.RealNameChainConfig{ManualReview: true}); err != nil {
+		t.Fatalf("update chain: %v", err)
+	}
+	for _, ids := range [][]int64{{public.ID}, {foreign.ID}, {image.ID, image.ID}} {
+		bad := input
+		bad.UploadIDs = ids
+		if _, err := svc.SubmitManual(ctx, user.ID, bad); !errors.Is(err, domain.ErrInvalidRealNameImages) {
+			t.Fatalf("expected invalid images for %v, got %v", ids, err)
+		}
+	}
+	record, err := svc.SubmitManual(ctx, user.ID, input)
+	if err != nil || record.Status != domain.RealNameStatusPendingReview {
+		t.Fatalf("submit manual: %+v %v", record, err)
+	}
+	if _, err := svc.SubmitManual(ctx, user.ID, input); !errors.Is(err, domain.ErrRealNameReviewInProgress) {
+		t.Fatalf("expected review in progress, got %v", err)
+	}
+
+	items, total, err := svc.ListReviews(ctx, "", 10, 0)
+	if err != nil || total != 1 || items[0].ID != record.ID {
+		t.Fatalf("list reviews: %+v total=%d err=%v", items, total, err)
+	}
+	if _, err := svc.ReviewImage(ctx, record.ID, public.ID); !errors.Is(err, appshared.ErrNotFound) {
+		t.Fatalf("unattached upload must not be served, got %v", err)
+	}
+	if _, err := svc.Review(ctx, 1, record.ID, false, ""); !errors.Is(err, appshared.ErrInvalidInput) {
+		t.Fatalf("reject without reason should fail, got %v", err)
+	}
+	rejected, err := svc.Review(ctx, 1, record.ID, false, "照片模糊")
+	if err != nil || rejected.Status != "failed" || rejected.Reason != "照片模糊" {
+		t.Fatalf("reject: %+v %v", rejected, err)
+	}
+	if _, err := svc.Review(ctx, 1, record.ID, true, ""); !errors.Is(err, domain.ErrRealNameNotPendingReview) {
+		t.Fatalf("expected not pending review, got %v", err)
+	}
+
+	record, err = svc.SubmitManual(ctx, user.ID, input)
+	if err != nil {
+		t.Fatalf("resubmit: %v", err)
+	}
+	approved, err := svc.Review(ctx, 1, record.ID, true, "")
+	if err != nil || approved.Status != "verified" || approved.VerifiedAt == nil {
+		t.Fatalf("approve: %+v %v", approved, err)
+	}
+	if len(messages.titles) != 2 {
+		t.Fatalf("unexpected messages: %v", messages.titles)
+	}
+}
@@ -3,6 +3,9 @@ package realname
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,16 +22,48 @@ type (
 	RealNameVerifyInput           = appshared.RealNameVerifyInput
 )
 
+const (
+	defaultProviderTimeout = 10
+	maxProviderTimeout     = 120
+	maxFallbackProviders   = 5
+	maxReviewImages        = 3
+)
+
+type messageNotifier interface {
+	NotifyUser(ctx context.Context, userID int64, typ, title, content string) error
+}
+
 type Service struct {
-	repo     appports.RealNameRepository
-	registry RealNameProviderRegistry
-	settings appports.SettingsRepository
+	repo      appports.RealNameRepository
+	registry  RealNameProviderRegistry
+	settings  appports.SettingsRepository
+	reviews   appports.RealNameReviewRepository
+	uploads   appports.UploadRepository
+	audit     appports.AuditRepository
+	messages  messageNotifier
+	companies appports.CompanyVerificationRepository
 }
 
 func NewService(repo appports.RealNameRepository, registry appshared.RealNameProviderRegistry, settings appports.SettingsRepository) *Service {
 	return &Service{repo: repo, registry: registry, settings: settings}
 }
 
+// SetManualReview enables the manual review queue backed by the given
+// repositories.
+func (s *Service) SetManualReview(reviews appports.RealNameReviewRepository, uploads appports.UploadRepository, audit appports.AuditRepository) {
+	s.reviews = reviews
+	s.uploads = uploads
+	s.audit = audit
+}
+
+func (s *Service) SetMessageService(messages messageNotifier) {
+	s.messages = messages
+}
+
+func (s *Service) SetCompanyVerification(companies appports.CompanyVerificationRepository) {
+	s.companies = companies
+}
+
 func (s *Service) GetConfig(ctx context.Context) (bool, string, []string) {
 	enabled := false
 	provider := "idcard_cn"
@@ -78,6 +113,72 @@ func (s *Service) UpdateConfig(ctx context.Context, enabled bool, provider strin
 	return s.settings.UpsertSetting(ctx, domain.Setting{Key: "realname_block_actions", ValueJSON: string(raw)})
 }
 
+// ChainConfig returns the fallback providers, the per-provider timeout and
+// whether manual review is open to users.
+func (s *Service) ChainConfig(ctx context.Context) appshared.RealNameChainConfig {
+	cfg := appshared.RealNameChainConfig{TimeoutSec: defaultProviderTimeout}
+	if s.settings == nil {
+		return cfg
+	}
+	if setting, err := s.settings.GetSetting(ctx, "realname_fallback_providers"); err == nil && strings.TrimSpace(setting.ValueJSON) != "" {
+		var list []string
+		if err := json.Unmarshal([]byte(setting.ValueJSON), &list); err == nil {
+			cfg.FallbackProviders = list
+		}
+	}
+	if setting, err := s.settings.GetSetting(ctx, "realname_provider_timeout_sec"); err == nil {
+		if v, err := strconv.Atoi(strings.TrimSpace(setting.ValueJSON)); err == nil && v > 0 && v <= maxProviderTimeout {
+			cfg.TimeoutSec = v
+		}
+	}
+	if setting, err := s.settings.GetSetting(ctx, "realname_manual_review"); err == nil {
+		cfg.ManualReview = strings.ToLower(strings.TrimSpace(setting.ValueJSON)) == "true"
+	}
+	return cfg
+}
+
+func (s *Service) UpdateChainConfig(ctx context.Context, cfg appshared.RealNameChainConfig) error {
+	if s.settings == nil {
+		return appshared.ErrInvalidInput
+	}
+	if cfg.TimeoutSec <= 0 {
+		cfg.TimeoutSec = defaultProviderTimeout
+	}
+	if cfg.TimeoutSec > maxProviderTimeout {
+		return appshared.ErrInvalidInput
+	}
+	fallbacks := make([]string, 0, len(cfg.FallbackProviders))
+	seen := map[string]bool{}
+	for _, key := range cfg.FallbackProviders {
+		key = strings.TrimSpace(key)
+		if key == "" || seen[key] {
+			continue
+		}
+		if s.registry != nil {
+			if _, err := s.registry.GetProvider(key); err != nil {
+				return err
+			}
+		}
+		seen[key] = true
+		fallbacks = append(fallbacks, key)
+	}
+	if len(fallbacks) > maxFallbackProviders {
+		return appshared.ErrInvalidInput
+	}
+	raw, _ := json.Marshal(fallbacks)
+	manual := "false"
+	if cfg.ManualReview {
+		manual = "true"
+	}
+	if err := s.settings.UpsertSetting(ctx, domain.Setting{Key: "realname_fallback_providers", ValueJSON: string(raw)}); err != nil {
+		return err
+	}
+	if err := s.settings.UpsertSetting(ctx, domain.Setting{Key: "realname_provider_timeout_sec", ValueJSON: strconv.Itoa(cfg.TimeoutSec)}); err != nil {
+		return err
+	}
+	return s.settings.UpsertSetting(ctx, domain.Setting{Key: "realname_manual_review", ValueJSON: manual})
+}
+
 func (s *Service) Verify(ctx context.Context, userID int64, realName, idNumber string) (domain.RealNameVerification, error) {
 	return s.VerifyWithInput(ctx, userID, RealNameVerifyInput{
 		RealName: realName,
@@ -85,6 +186,9 @@ func (s *Service) Verify(ctx context.Context, userID int64, realName, idNumber s
 	})
 }
 
+// VerifyWithInput asks the configured provider and, when it errors or times
+// out, each fallback provider in turn. A definite pass or fail from any
+// provider ends the chain.
 func (s *Service) VerifyWithInput(ctx context.Context, userID int64, in RealNameVerifyInput) (domain.RealNameVerification, error) {
 	if s.repo == nil || s.registry == nil {
 		return domain.RealNameVerification{}, appshared.ErrInvalidInput
@@ -93,20 +197,49 @@ func (s *Service) VerifyWithInput(ctx context.Context, userID int64, in RealName
 	if !enabled {
 		return domain.RealNameVerification{}, appshared.ErrForbidden
 	}
-	provider, err := s.registry.GetProvider(providerKey)
-	if err != nil {
-		return domain.RealNameVerification{}, err
+	chain := s.ChainConfig(ctx)
+	keys := chainKeys(providerKey, chain)
+	var errs []error
+	for _, key := range keys {
+		provider, err := s.registry.GetProvider(key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		ok, reason, err := callProvider(ctx, provider, in, time.Duration(chain.TimeoutSec)*time.Second)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		return s.saveResult(ctx, userID, in, provider.Key(), ok, reason)
 	}
-	ok := false
-	reason := ""
-	if ext, yes := provider.(RealNameProviderWithInput); yes {
-		ok, reason, err = ext.VerifyWithInput(ctx, in)
-	} else {
-		ok, reason, err = provider.Verify(ctx, in.RealName, in.IDNumber)
+	if len(keys) == 1 && len(errs) == 1 {
+		return domain.RealNameVerification{}, errors.Unwrap(errs[0])
 	}
-	if err != nil {
-		return domain.RealNameVerification{}, err
+	return domain.RealNameVerification{}, errors.Join(append([]error{domain.ErrRealNameProvidersUnavailable}, errs...)...)
+}
+
+// chainKeys lists the primary provider followed by the distinct fallbacks.
+func chainKeys(primary string, chain appshared.RealNameChainConfig) []string {
+	keys := []string{primary}
+	for _, key := range chain.FallbackProviders {
+		if key != primary {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func callProvider(ctx context.Context, provider RealNameProvider, in RealNameVerifyInput, timeout time.Duration) (bool, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if ext, yes := provider.(RealNameProviderWithInput); yes {
+		return ext.VerifyWithInput(ctx, in)
 	}
+	return provider.Verify(ctx, in.RealName, in.IDNumber)
+}
+
+func (s *Service) saveResult(ctx context.Context, userID int64, in RealNameVerifyInput, providerKey string, ok bool, reason string) (domain.RealNameVerification, error) {
 	status := "failed"
 	var verifiedAt *time.Time
 	if ok {
@@ -122,7 +255,7 @@ func (s *Service) VerifyWithInput(ctx context.Context, userID int64, in RealName
 		RealName:   strings.TrimSpace(in.RealName),
 		IDNumber:   strings.TrimSpace(in.IDNumber),
 		Status:     status,
-		Provider:   provider.Key(),
+		Provider:   providerKey,
 		Reason:     reason,
 		VerifiedAt: verifiedAt,
 		CreatedAt:  time.Now(),
@@ -133,6 +266,136 @@ func (s *Service) VerifyWithInput(ctx context.Context, userID int64, in RealName
 	return record, nil
 }
 
+// SubmitManual queues a verification with uploaded ID images for admin
+// review. The images must have been uploaded by the same user.
+func (s *Service) SubmitManual(ctx context.Context, userID int64, in appshared.RealNameManualInput) (domain.RealNameVerification, error) {
+	if s.repo == nil || s.reviews == nil || s.uploads == nil {
+		return domain.RealNameVerification{}, appshared.ErrInvalidInput
+	}
+	enabled, _, _ := s.GetConfig(ctx)
+	if !enabled {
+		return domain.RealNameVerification{}, appshared.ErrForbidden
+	}
+	if !s.ChainConfig(ctx).ManualReview {
+		return domain.RealNameVerification{}, domain.ErrRealNameManualReviewDisabled
+	}
+	realName := strings.TrimSpace(in.RealName)
+	idNumber := strings.TrimSpace(in.IDNumber)
+	if realName == "" || idNumber == "" {
+		return domain.RealNameVerification{}, appshared.ErrInvalidInput
+	}
+	if err := s.checkReviewImages(ctx, userID, in.UploadIDs); err != nil {
+		return domain.RealNameVerification{}, err
+	}
+	if latest, err := s.repo.GetLatestRealNameVerification(ctx, userID); err == nil {
+		switch latest.Status {
+		case domain.RealNameStatusPendingReview, "pending":
+			return domain.RealNameVerification{}, domain.ErrRealNameReviewInProgress
+		case "verified":
+			return domain.RealNameVerification{}, appshared.ErrConflict
+		}
+	}
+	record := domain.RealNameVerification{
+		UserID:    userID,
+		RealName:  realName,
+		IDNumber:  idNumber,
+		Status:    domain.RealNameStatusPendingReview,
+		Provider:  domain.RealNameProviderManual,
+		UploadIDs: in.UploadIDs,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.CreateRealNameVerification(ctx, &record); err != nil {
+		return domain.RealNameVerification{}, err
+	}
+	return record, nil
+}
+
+// checkReviewImages accepts 1 to maxReviewImages distinct private uploads
+// owned by the user.
+func (s *Service) checkReviewImages(ctx context.Context, userID int64, uploadIDs []int64) error {
+	if len(uploadIDs) == 0 || len(uploadIDs) > maxReviewImages {
+		return domain.ErrInvalidRealNameImages
+	}
+	seen := map[int64]bool{}
+	for _, id := range uploadIDs {
+		upload, err := s.uploads.GetUpload(ctx, id)
+		if err != nil || seen[id] || upload.UploaderID != userID || upload.URL != "" {
+			return domain.ErrInvalidRealNameImages
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// ListReviews lists manual verifications, pending ones by default.
+func (s *Service) ListReviews(ctx context.Context, status string, limit, offset int) ([]domain.RealNameVerification, int, error) {
+	if s.reviews == nil {
+		return nil, 0, appshared.ErrInvalidInput
+	}
+	status = strings.TrimSpace(status)
+	if status == "" {
+		status = domain.RealNameStatusPendingReview
+	}
+	return s.reviews.ListRealNameVerificationsByStatus(ctx, status, limit, offset)
+}
+
+// Review approves or rejects a verification waiting for manual review.
+// Rejections need a reason, which is shown to the user.
+func (s *Service) Review(ctx context.Context, adminID, recordID int64, approve bool, reason string) (domain.RealNameVerification, error) {
+	if s.reviews == nil {
+		return domain.RealNameVerification{}, appshared.ErrInvalidInput
+	}
+	reason = strings.TrimSpace(reason)
+	status := "verified"
+	if !approve {
+		status = "failed"
+		if reason == "" {
+			return domain.RealNameVerification{}, appshared.ErrInvalidInput
+		}
+	}
+	record, err := s.reviews.GetRealNameVerification(ctx, recordID)
+	if err != nil {
+		return domain.RealNameVerification{}, err
+	}
+	now := time.Now()
+	updated, err := s.reviews.ReviewRealNameVerification(ctx, recordID, status, reason, adminID, now)
+	if err != nil {
+		return domain.RealNameVerification{}, err
+	}
+	if !updated {
+		return domain.RealNameVerification{}, domain.ErrRealNameNotPendingReview
+	}
+	if s.audit != nil {
+		detail, _ := json.Marshal(map[string]any{"user_id": record.UserID, "status": status, "reason": reason})
+		_ = s.audit.AddAuditLog(ctx, domain.AdminAuditLog{AdminID: adminID, Action: "realname.review", TargetType: "realname_verification", TargetID: strconv.FormatInt(recordID, 10), DetailJSON: string(detail)})
+	}
+	if s.messages != nil {
+		title, content := "实名认证已通过", "您提交的实名认证资料已审核通过。"
+		if !approve {
+			title, content = "实名认证未通过", "您提交的实名认证资料未通过审核，原因："+reason
+		}
+		_ = s.messages.NotifyUser(ctx, record.UserID, "realname", title, content)
+	}
+	return s.reviews.GetRealNameVerification(ctx, recordID)
+}
+
+// ReviewImage returns an ID image attached to a verification.
+func (s *Service) ReviewImage(ctx context.Context, recordID, uploadID int64) (domain.Upload, error) {
+	if s.reviews == nil || s.uploads == nil {
+		return domain.Upload{}, appshared.ErrInvalidInput
+	}
+	record, err := s.reviews.GetRealNameVerification(ctx, recordID)
+	if err != nil {
+		return domain.Upload{}, err
+	}
+	for _, id := range record.UploadIDs {
+		if id == uploadID {
+			return s.uploads.GetUpload(ctx, uploadID)
+		}
+	}
+	return domain.Upload{}, appshared.ErrNotFound
+}
+
 func (s *Service) PollPending(ctx context.Context, limit int) (int, error) {
 	if s.repo == nil || s.registry == nil {
 		return 0, appshared.ErrInvalidInput
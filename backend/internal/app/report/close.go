@@ -0,0 +1,244 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+type walletBalanceLister interface {
+	ListWalletBalances(ctx context.Context) (map[int64]int64, error)
+}
+
+// ClosePackage is the finance close for [From, To). Revenue, discounts and
+// refunds cover the period; wallet liabilities are a snapshot taken when the
+// package is built.
+type ClosePackage struct {
+	From        time.Time
+	To          time.Time
+	GeneratedAt time.Time
+
+	RevenueTotal int64
+	OrderCount   int
+	Revenue      []CloseRevenueRow
+
+	RefundTotal int64
+	Refunds     []CloseRefundRow
+
+	CouponDiscountTotal int64
+	CouponDiscounts     []CloseDiscountRow
+
+	TierDiscountTotal int64
+	TierDiscounts     []CloseDiscountRow
+
+	Liability   domain.WalletTotals
+	Liabilities []CloseLiabilityRow
+}
+
+type CloseRevenueRow struct {
+	GoodsTypeID int64
+	GoodsType   string
+	RegionID    int64
+	Region      string
+	LineID      int64
+	Line        string
+	Revenue     int64
+	OrderCount  int
+}
+
+type CloseRefundRow struct {
+	WalletOrderID int64
+	UserID        int64
+	Amount        int64
+	Note          string
+	ApprovedAt    time.Time
+}
+
+// CloseDiscountRow is one discounted order (coupon) or order item (tier).
+type CloseDiscountRow struct {
+	OrderID     int64
+	OrderNo     string
+	OrderItemID int64
+	UserID      int64
+	CouponCode  string
+	Qty         int
+	Amount      int64
+}
+
+type CloseLiabilityRow struct {
+	UserID  int64
+	Balance int64
+}
+
+// SetWalletSources enables the refund and wallet liability sections of the
+// close package.
+func (s *Service) SetWalletSources(walletOrders appports.WalletOrderRepository, stats appports.WalletStatsRepository, balances walletBalanceLister) {
+	s.walletOrders = walletOrders
+	s.walletStats = stats
+	s.walletBalances = balances
+}
+
+// BuildClosePackage collects the close package for [from, to). Revenue uses
+// the same recognition rules as revenue analytics.
+func (s *Service) BuildClosePackage(ctx context.Context, from, to time.Time) (ClosePackage, error) {
+	rows, total, err := s.collectRevenueDataByDimension(ctx, RevenueAnalyticsQuery{FromAt: from, ToAt: to, Level: RevenueLevelOverall}, RevenueLevelGoodsType)
+	if err != nil {
+		return ClosePackage{}, err
+	}
+	out := ClosePackage{From: from, To: to, GeneratedAt: time.Now(), RevenueTotal: total, OrderCount: uniqueOrderCount(rows)}
+	out.Revenue = s.closeRevenueRows(ctx, rows)
+
+	couponSeen := map[int64]bool{}
+	for _, row := range rows {
+		if row.order.CouponDiscount > 0 && !couponSeen[row.order.ID] {
+			couponSeen[row.order.ID] = true
+			out.CouponDiscounts = append(out.CouponDiscounts, CloseDiscountRow{
+				OrderID:    row.order.ID,
+				OrderNo:    row.order.OrderNo,
+				UserID:     row.order.UserID,
+				CouponCode: row.order.CouponCode,
+				Amount:     row.order.CouponDiscount,
+			})
+			out.CouponDiscountTotal += row.order.CouponDiscount
+		}
+		if discount := specTierDiscount(row.item.SpecJSON); discount > 0 {
+			qty := row.item.Qty
+			if qty <= 0 {
+				qty = 1
+			}
+			out.TierDiscounts = append(out.TierDiscounts, CloseDiscountRow{
+				OrderID:     row.order.ID,
+				OrderNo:     row.order.OrderNo,
+				OrderItemID: row.item.ID,
+				UserID:      row.order.UserID,
+				Qty:         qty,
+				Amount:      discount * int64(qty),
+			})
+			out.TierDiscountTotal += discount * int64(qty)
+		}
+	}
+
+	if s.walletOrders != nil {
+		refunds, err := s.listApprovedRefunds(ctx, from, to)
+		if err != nil {
+			return ClosePackage{}, err
+		}
+		for _, refund := range refunds {
+			out.Refunds = append(out.Refunds, CloseRefundRow{
+				WalletOrderID: refund.ID,
+				UserID:        refund.UserID,
+				Amount:        refund.Amount,
+				Note:          refund.Note,
+				ApprovedAt:    refund.UpdatedAt,
+			})
+			out.RefundTotal += refund.Amount
+		}
+	}
+	if s.walletStats != nil {
+		if out.Liability, err = s.walletStats.WalletTotals(ctx); err != nil {
+			return ClosePackage{}, err
+		}
+	}
+	if s.walletBalances != nil {
+		balances, err := s.walletBalances.ListWalletBalances(ctx)
+		if err != nil {
+			return ClosePackage{}, err
+		}
+		for userID, balance := range balances {
+			if balance != 0 {
+				out.Liabilities = append(out.Liabilities, CloseLiabilityRow{UserID: userID, Balance: balance})
+			}
+		}
+		sort.Slice(out.Liabilities, func(i, j int) bool {
+			if out.Liabilities[i].Balance == out.Liabilities[j].Balance {
+				return out.Liabilities[i].UserID < out.Liabilities[j].UserID
+			}
+			return out.Liabilities[i].Balance > out.Liabilities[j].Balance
+		})
+	}
+	return out, nil
+}
+
+func (s *Service) closeRevenueRows(ctx context.Context, rows []paymentSlice) []CloseRevenueRow {
+	type key struct{ goods, region, line int64 }
+	groups := map[key]*CloseRevenueRow{}
+	orders := map[key]map[int64]bool{}
+	for _, row := range rows {
+		k := key{row.goods, row.region, row.line}
+		group, ok := groups[k]
+		if !ok {
+			scope := revenueScope{goodsTypeID: row.goods, regionID: row.region, lineID: row.line, lineName: row.lineName}
+			group = &CloseRevenueRow{GoodsTypeID: row.goods, RegionID: row.region, LineID: row.line}
+			_, group.GoodsType, _ = s.resolveDimension(ctx, RevenueLevelGoodsType, scope)
+			_, group.Region, _ = s.resolveDimension(ctx, RevenueLevelRegion, scope)
+			_, group.Line, _ = s.resolveDimension(ctx, RevenueLevelLine, scope)
+			groups[k] = group
+			orders[k] = map[int64]bool{}
+		}
+		group.Revenue += row.amount
+		orders[k][row.order.ID] = true
+	}
+	out := make([]CloseRevenueRow, 0, len(groups))
+	for k, group := range groups {
+		group.OrderCount = len(orders[k])
+		out = append(out, *group)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Revenue == out[j].Revenue {
+			a, b := out[i], out[j]
+			if a.GoodsTypeID != b.GoodsTypeID {
+				return a.GoodsTypeID < b.GoodsTypeID
+			}
+			if a.RegionID != b.RegionID {
+				return a.RegionID < b.RegionID
+			}
+			return a.LineID < b.LineID
+		}
+		return out[i].Revenue > out[j].Revenue
+	})
+	return out
+}
+
+// listApprovedRefunds returns refund wallet orders approved in [from, to).
+// Approval is the last status change, so UpdatedAt is the approval time.
+func (s *Service) listApprovedRefunds(ctx context.Context, from, to time.Time) ([]domain.WalletOrder, error) {
+	limit := 200
+	offset := 0
+	var out []domain.WalletOrder
+	for {
+		items, total, err := s.walletOrders.ListAllWalletOrders(ctx, string(domain.WalletOrderApproved), limit, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if item.Type != domain.WalletOrderRefund || item.UpdatedAt.Before(from) || !item.UpdatedAt.Before(to) {
+				continue
+			}
+			out = append(out, item)
+		}
+		offset += len(items)
+		if offset >= total || len(items) == 0 {
+			break
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// specTierDiscount reads the per-unit tier discount recorded on the order
+// item spec at pricing time. Orders priced before it was recorded have none.
+func specTierDiscount(specJSON string) int64 {
+	if specJSON == "" {
+		return 0
+	}
+	var spec appshared.CartSpec
+	if err := json.Unmarshal([]byte(specJSON), &spec); err != nil {
+		return 0
+	}
+	return spec.TierDiscount
+}
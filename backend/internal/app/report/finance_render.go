@@ -0,0 +1,109 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/pkg/money"
+	"xiaoheiplay/internal/pkg/xlsx"
+)
+
+const (
+	csvContentType  = "text/csv; charset=utf-8"
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+type closeSection struct {
+	file  string
+	sheet xlsx.Sheet
+}
+
+// renderClosePackage renders pkg in each requested format. XLSX is a single
+// workbook with one sheet per section; CSV is one file per section.
+func renderClosePackage(pkg ClosePackage, formats []string, baseName string) ([]domain.FinanceReportFile, error) {
+	sections := closeSections(pkg)
+	var files []domain.FinanceReportFile
+	for _, format := range formats {
+		switch format {
+		case domain.FinanceReportFormatXLSX:
+			sheets := make([]xlsx.Sheet, 0, len(sections))
+			for _, section := range sections {
+				sheets = append(sheets, section.sheet)
+			}
+			var buf bytes.Buffer
+			if err := xlsx.Write(&buf, sheets); err != nil {
+				return nil, err
+			}
+			files = append(files, reportFile(baseName+".xlsx", xlsxContentType, buf.Bytes()))
+		case domain.FinanceReportFormatCSV:
+			for _, section := range sections {
+				buf := bytes.NewBuffer([]byte{0xEF, 0xBB, 0xBF})
+				w := csv.NewWriter(buf)
+				if err := w.WriteAll(section.sheet.Rows); err != nil {
+					return nil, err
+				}
+				files = append(files, reportFile(fmt.Sprintf("%s_%s.csv", baseName, section.file), csvContentType, buf.Bytes()))
+			}
+		default:
+			return nil, domain.ErrInvalidFinanceReport
+		}
+	}
+	return files, nil
+}
+
+func reportFile(name, contentType string, content []byte) domain.FinanceReportFile {
+	return domain.FinanceReportFile{Name: name, ContentType: contentType, Size: int64(len(content)), Content: content}
+}
+
+func closeSections(pkg ClosePackage) []closeSection {
+	id := func(v int64) string { return strconv.FormatInt(v, 10) }
+	ts := func(t time.Time) string { return t.Format("2006-01-02 15:04:05") }
+
+	summary := [][]string{
+		{"项目", "数值"},
+		{"期间开始", ts(pkg.From)},
+		{"期间结束（不含）", ts(pkg.To)},
+		{"生成时间", ts(pkg.GeneratedAt)},
+		{"收入合计（元）", money.FormatCents(pkg.RevenueTotal)},
+		{"订单数", strconv.Itoa(pkg.OrderCount)},
+		{"退款合计（元）", money.FormatCents(pkg.RefundTotal)},
+		{"优惠券折扣（元）", money.FormatCents(pkg.CouponDiscountTotal)},
+		{"等级折扣（元）", money.FormatCents(pkg.TierDiscountTotal)},
+		{"钱包余额合计（元）", money.FormatCents(pkg.Liability.Balance)},
+		{"钱包数", id(pkg.Liability.Wallets)},
+		{"负余额钱包数", id(pkg.Liability.NegativeCount)},
+	}
+	revenue := [][]string{{"商品类型ID", "商品类型", "地区ID", "地区", "线路ID", "线路", "收入（元）", "订单数"}}
+	for _, row := range pkg.Revenue {
+		revenue = append(revenue, []string{id(row.GoodsTypeID), row.GoodsType, id(row.RegionID), row.Region, id(row.LineID), row.Line, money.FormatCents(row.Revenue), strconv.Itoa(row.OrderCount)})
+	}
+	refunds := [][]string{{"退款单ID", "用户ID", "金额（元）", "备注", "通过时间"}}
+	for _, row := range pkg.Refunds {
+		refunds = append(refunds, []string{id(row.WalletOrderID), id(row.UserID), money.FormatCents(row.Amount), row.Note, ts(row.ApprovedAt)})
+	}
+	coupons := [][]string{{"订单ID", "订单号", "用户ID", "优惠码", "折扣（元）"}}
+	for _, row := range pkg.CouponDiscounts {
+		coupons = append(coupons, []string{id(row.OrderID), row.OrderNo, id(row.UserID), row.CouponCode, money.FormatCents(row.Amount)})
+	}
+	tiers := [][]string{{"订单ID", "订单号", "明细ID", "用户ID", "数量", "折扣（元）"}}
+	for _, row := range pkg.TierDiscounts {
+		tiers = append(tiers, []string{id(row.OrderID), row.OrderNo, id(row.OrderItemID), id(row.UserID), strconv.Itoa(row.Qty), money.FormatCents(row.Amount)})
+	}
+	liabilities := [][]string{{"用户ID", "余额（元）"}}
+	for _, row := range pkg.Liabilities {
+		liabilities = append(liabilities, []string{id(row.UserID), money.FormatCents(row.Balance)})
+	}
+
+	return []closeSection{
+		{file: "summary", sheet: xlsx.Sheet{Name: "汇总", Rows: summary, NumberColumns: []int{1}}},
+		{file: "revenue", sheet: xlsx.Sheet{Name: "收入", Rows: revenue, NumberColumns: []int{0, 2, 4, 6, 7}}},
+		{file: "refunds", sheet: xlsx.Sheet{Name: "退款", Rows: refunds, NumberColumns: []int{0, 1, 2}}},
+		{file: "coupon_discounts", sheet: xlsx.Sheet{Name: "优惠券折扣", Rows: coupons, NumberColumns: []int{0, 2, 4}}},
+		{file: "tier_discounts", sheet: xlsx.Sheet{Name: "等级折扣", Rows: tiers, NumberColumns: []int{0, 2, 3, 4, 5}}},
+		{file: "wallet_liabilities", sheet: xlsx.Sheet{Name: "钱包负债", Rows: liabilities, NumberColumns: []int{0, 1}}},
+	}
+}
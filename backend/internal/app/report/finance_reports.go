@@ -0,0 +1,360 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/pkg/money"
+)
+
+const (
+	maxFinanceReportRecipients = 20
+	maxFinanceReportRange      = 366 * 24 * time.Hour
+)
+
+// FinanceService keeps finance report definitions, generates close packages
+// for them on schedule or on demand, stores every run and emails the files.
+type FinanceService struct {
+	repo    appports.FinanceReportRepository
+	reports *Service
+	audit   appports.AuditRepository
+	mailer  appports.EmailAttachmentSender
+	now     func() time.Time
+}
+
+func NewFinanceService(repo appports.FinanceReportRepository, reports *Service, audit appports.AuditRepository) *FinanceService {
+	return &FinanceService{repo: repo, reports: reports, audit: audit, now: time.Now}
+}
+
+func (s *FinanceService) SetMailer(mailer appports.EmailAttachmentSender) {
+	s.mailer = mailer
+}
+
+func (s *FinanceService) ListDefinitions(ctx context.Context) ([]domain.FinanceReportDefinition, error) {
+	return s.repo.ListFinanceReportDefinitions(ctx)
+}
+
+func (s *FinanceService) CreateDefinition(ctx context.Context, adminID int64, input appshared.FinanceReportDefinitionInput) (domain.FinanceReportDefinition, error) {
+	def, err := normalizeFinanceDefinition(input)
+	if err != nil {
+		return domain.FinanceReportDefinition{}, err
+	}
+	def.CreatedBy = adminID
+	if err := s.repo.CreateFinanceReportDefinition(ctx, &def); err != nil {
+		return domain.FinanceReportDefinition{}, err
+	}
+	s.addAudit(ctx, adminID, "finance_report.create", def.ID, map[string]any{"name": def.Name, "period": def.Period, "recipients": def.Recipients})
+	return def, nil
+}
+
+func (s *FinanceService) UpdateDefinition(ctx context.Context, adminID, id int64, input appshared.FinanceReportDefinitionInput) (domain.FinanceReportDefinition, error) {
+	current, err := s.repo.GetFinanceReportDefinition(ctx, id)
+	if err != nil {
+		return domain.FinanceReportDefinition{}, err
+	}
+	def, err := normalizeFinanceDefinition(input)
+	if err != nil {
+		return domain.FinanceReportDefinition{}, err
+	}
+	def.ID = current.ID
+	def.CreatedBy = current.CreatedBy
+	def.LastRunAt = current.LastRunAt
+	def.CreatedAt = current.CreatedAt
+	if err := s.repo.UpdateFinanceReportDefinition(ctx, def); err != nil {
+		return domain.FinanceReportDefinition{}, err
+	}
+	s.addAudit(ctx, adminID, "finance_report.update", def.ID, map[string]any{"name": def.Name, "period": def.Period, "recipients": def.Recipients, "enabled": def.Enabled})
+	return s.repo.GetFinanceReportDefinition(ctx, id)
+}
+
+// DeleteDefinition removes the definition; runs already generated are kept
+// and stay downloadable.
+func (s *FinanceService) DeleteDefinition(ctx context.Context, adminID, id int64) error {
+	if err := s.repo.DeleteFinanceReportDefinition(ctx, id); err != nil {
+		return err
+	}
+	s.addAudit(ctx, adminID, "finance_report.delete", id, nil)
+	return nil
+}
+
+// RunNow generates and sends the package for def immediately. With a zero
+// range it covers the last completed period of the definition.
+func (s *FinanceService) RunNow(ctx context.Context, adminID, id int64, from, to time.Time) (domain.FinanceReportRun, error) {
+	def, err := s.repo.GetFinanceReportDefinition(ctx, id)
+	if err != nil {
+		return domain.FinanceReportRun{}, err
+	}
+	if from.IsZero() && to.IsZero() {
+		from, to = lastCompletedPeriod(def.Period, s.now())
+	}
+	if from.IsZero() || to.IsZero() || !from.Before(to) || to.Sub(from) > maxFinanceReportRange {
+		return domain.FinanceReportRun{}, appshared.ErrInvalidInput
+	}
+	run, err := s.generate(ctx, def, from, to, domain.FinanceReportTriggerManual, adminID)
+	if err != nil {
+		return domain.FinanceReportRun{}, err
+	}
+	s.addAudit(ctx, adminID, "finance_report.run", run.ID, map[string]any{"definition_id": def.ID, "from": from, "to": to, "status": run.Status})
+	return run, nil
+}
+
+// RunDue generates the last completed period of every enabled definition
+// that has no successful scheduled run for it yet. It returns how many runs
+// were generated; failures are joined into the error so the task shows them.
+func (s *FinanceService) RunDue(ctx context.Context) (int, error) {
+	defs, err := s.repo.ListFinanceReportDefinitions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	now := s.now()
+	count := 0
+	var errs []error
+	for _, def := range defs {
+		if !def.Enabled {
+			continue
+		}
+		from, to := lastCompletedPeriod(def.Period, now)
+		done, err := s.repo.HasScheduledFinanceReportRun(ctx, def.ID, from)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if done {
+			continue
+		}
+		run, err := s.generate(ctx, def, from, to, domain.FinanceReportTriggerSchedule, 0)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		count++
+		if run.Status != domain.FinanceReportRunSuccess {
+			errs = append(errs, fmt.Errorf("finance report %d: %s: %w", def.ID, run.Error, domain.ErrFinanceReportNotDelivered))
+		}
+	}
+	return count, errors.Join(errs...)
+}
+
+func (s *FinanceService) ListRuns(ctx context.Context, definitionID int64, limit, offset int) ([]domain.FinanceReportRun, int, error) {
+	return s.repo.ListFinanceReportRuns(ctx, definitionID, limit, offset)
+}
+
+func (s *FinanceService) GetRun(ctx context.Context, id int64) (domain.FinanceReportRun, error) {
+	return s.repo.GetFinanceReportRun(ctx, id)
+}
+
+func (s *FinanceService) GetFile(ctx context.Context, runID, fileID int64) (domain.FinanceReportFile, error) {
+	return s.repo.GetFinanceReportFile(ctx, runID, fileID)
+}
+
+// Resend emails the stored files of a run again, to its original recipients
+// or to recipients when given.
+func (s *FinanceService) Resend(ctx context.Context, adminID, runID int64, recipients []string) (domain.FinanceReportRun, error) {
+	run, err := s.repo.GetFinanceReportRun(ctx, runID)
+	if err != nil {
+		return domain.FinanceReportRun{}, err
+	}
+	if run.Status == domain.FinanceReportRunFailed || len(run.Files) == 0 {
+		return domain.FinanceReportRun{}, appshared.ErrConflict
+	}
+	if len(recipients) > 0 {
+		if run.Recipients, err = normalizeRecipients(recipients); err != nil {
+			return domain.FinanceReportRun{}, err
+		}
+	}
+	files := make([]domain.FinanceReportFile, 0, len(run.Files))
+	for _, file := range run.Files {
+		full, err := s.repo.GetFinanceReportFile(ctx, run.ID, file.ID)
+		if err != nil {
+			return domain.FinanceReportRun{}, err
+		}
+		files = append(files, full)
+	}
+	run.Files = files
+	s.deliver(ctx, &run, financeMailBody(run, nil))
+	s.addAudit(ctx, adminID, "finance_report.resend", run.ID, map[string]any{"recipients": run.Recipients, "status": run.Status})
+	return s.repo.GetFinanceReportRun(ctx, run.ID)
+}
+
+// generate builds, stores and sends one run. A package that cannot be built
+// is still stored as a failed run so the failure is visible in the history.
+func (s *FinanceService) generate(ctx context.Context, def domain.FinanceReportDefinition, from, to time.Time, trigger string, adminID int64) (domain.FinanceReportRun, error) {
+	run := domain.FinanceReportRun{
+		DefinitionID: def.ID,
+		Name:         def.Name,
+		Period:       def.Period,
+		PeriodStart:  from,
+		PeriodEnd:    to,
+		Trigger:      trigger,
+		Status:       domain.FinanceReportRunSuccess,
+		Recipients:   def.Recipients,
+		CreatedBy:    adminID,
+	}
+	pkg, err := s.reports.BuildClosePackage(ctx, from, to)
+	if err == nil {
+		run.Files, err = renderClosePackage(pkg, def.Formats, closeFileBase(from, to))
+	}
+	if err != nil {
+		run.Status = domain.FinanceReportRunFailed
+		run.Error = err.Error()
+		run.Files = nil
+	}
+	if err := s.repo.CreateFinanceReportRun(ctx, &run); err != nil {
+		return domain.FinanceReportRun{}, err
+	}
+	_ = s.repo.TouchFinanceReportDefinition(ctx, def.ID, run.CreatedAt)
+	if run.Status == domain.FinanceReportRunFailed {
+		return run, nil
+	}
+	s.deliver(ctx, &run, financeMailBody(run, &pkg))
+	return run, nil
+}
+
+// deliver sends the run's files to every recipient and records the
+// outcome. One failing recipient does not stop the others.
+func (s *FinanceService) deliver(ctx context.Context, run *domain.FinanceReportRun, body string) {
+	var failures []string
+	if s.mailer == nil {
+		failures = append(failures, "email sender not configured")
+	} else if len(run.Recipients) == 0 {
+		failures = append(failures, "no recipients")
+	} else {
+		attachments := make([]appports.EmailAttachment, 0, len(run.Files))
+		for _, file := range run.Files {
+			attachments = append(attachments, appports.EmailAttachment{Name: file.Name, ContentType: file.ContentType, Content: file.Content})
+		}
+		subject := fmt.Sprintf("[财务报表] %s %s", run.Name, periodLabel(run.PeriodStart, run.PeriodEnd))
+		for _, to := range run.Recipients {
+			if err := s.mailer.SendWithAttachments(ctx, to, subject, body, attachments); err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", to, err))
+			}
+		}
+	}
+	run.Status = domain.FinanceReportRunSuccess
+	run.Error = ""
+	var deliveredAt *time.Time
+	if len(failures) > 0 {
+		run.Status = domain.FinanceReportRunDeliveryFailed
+		run.Error = strings.Join(failures, "; ")
+	} else {
+		now := s.now()
+		deliveredAt = &now
+	}
+	_ = s.repo.UpdateFinanceReportRunDelivery(ctx, run.ID, run.Status, run.Error, deliveredAt)
+	run.DeliveredAt = deliveredAt
+}
+
+func financeMailBody(run domain.FinanceReportRun, pkg *ClosePackage) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n期间：%s\n", run.Name, periodLabel(run.PeriodStart, run.PeriodEnd))
+	if pkg != nil {
+		fmt.Fprintf(&b, "\n收入合计：%s 元（%d 单）\n", money.FormatCents(pkg.RevenueTotal), pkg.OrderCount)
+		fmt.Fprintf(&b, "退款合计：%s 元\n", money.FormatCents(pkg.RefundTotal))
+		fmt.Fprintf(&b, "优惠券折扣：%s 元\n", money.FormatCents(pkg.CouponDiscountTotal))
+		fmt.Fprintf(&b, "等级折扣：%s 元\n", money.FormatCents(pkg.TierDiscountTotal))
+		fmt.Fprintf(&b, "钱包余额合计：%s 元（截至 %s）\n", money.FormatCents(pkg.Liability.Balance), pkg.GeneratedAt.Format("2006-01-02 15:04"))
+	}
+	fmt.Fprintf(&b, "\n明细见附件，也可在后台财务报表中按运行记录 #%d 重新下载。\n", run.ID)
+	return b.String()
+}
+
+// lastCompletedPeriod returns the most recent full period before now, in
+// now's location. Weeks start on Monday.
+func lastCompletedPeriod(period domain.FinanceReportPeriod, now time.Time) (time.Time, time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch period {
+	case domain.FinanceReportWeekly:
+		monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		return monday.AddDate(0, 0, -7), monday
+	case domain.FinanceReportMonthly:
+		first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		return first.AddDate(0, -1, 0), first
+	default:
+		return today.AddDate(0, 0, -1), today
+	}
+}
+
+func periodLabel(from, to time.Time) string {
+	last := to.Add(-time.Second)
+	if last.Format("2006-01-02") == from.Format("2006-01-02") {
+		return from.Format("2006-01-02")
+	}
+	return from.Format("2006-01-02") + " ~ " + last.Format("2006-01-02")
+}
+
+func closeFileBase(from, to time.Time) string {
+	return "finance_close_" + from.Format("20060102") + "_" + to.Add(-time.Second).Format("20060102")
+}
+
+func normalizeFinanceDefinition(input appshared.FinanceReportDefinitionInput) (domain.FinanceReportDefinition, error) {
+	def := domain.FinanceReportDefinition{
+		Name:    strings.TrimSpace(input.Name),
+		Period:  domain.FinanceReportPeriod(strings.TrimSpace(input.Period)),
+		Enabled: input.Enabled,
+	}
+	if def.Name == "" || len([]rune(def.Name)) > 128 {
+		return def, domain.ErrInvalidFinanceReport
+	}
+	switch def.Period {
+	case domain.FinanceReportDaily, domain.FinanceReportWeekly, domain.FinanceReportMonthly:
+	default:
+		return def, domain.ErrInvalidFinanceReport
+	}
+	seen := map[string]bool{}
+	for _, format := range input.Formats {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format != domain.FinanceReportFormatCSV && format != domain.FinanceReportFormatXLSX {
+			return def, domain.ErrInvalidFinanceReport
+		}
+		if !seen[format] {
+			seen[format] = true
+			def.Formats = append(def.Formats, format)
+		}
+	}
+	if len(def.Formats) == 0 {
+		def.Formats = []string{domain.FinanceReportFormatXLSX}
+	}
+	recipients, err := normalizeRecipients(input.Recipients)
+	if err != nil {
+		return def, err
+	}
+	def.Recipients = recipients
+	return def, nil
+}
+
+func normalizeRecipients(input []string) ([]string, error) {
+	seen := map[string]bool{}
+	var out []string
+	for _, raw := range input {
+		addr, err := mail.ParseAddress(strings.TrimSpace(raw))
+		if err != nil || addr.Name != "" {
+			return nil, domain.ErrInvalidFinanceReport
+		}
+		email := strings.ToLower(addr.Address)
+		if !seen[email] {
+			seen[email] = true
+			out = append(out, email)
+		}
+	}
+	if len(out) == 0 || len(out) > maxFinanceReportRecipients {
+		return nil, domain.ErrInvalidFinanceReport
+	}
+	return out, nil
+}
+
+func (s *FinanceService) addAudit(ctx context.Context, adminID int64, action string, targetID int64, detail map[string]any) {
+	if s.audit == nil || adminID <= 0 {
+		return
+	}
+	b, _ := json.Marshal(detail)
+	_ = s.audit.AddAuditLog(ctx, domain.AdminAuditLog{AdminID: adminID, Action: action, TargetType: "finance_report", TargetID: strconv.FormatInt(targetID, 10), DetailJSON: string(b)})
+}
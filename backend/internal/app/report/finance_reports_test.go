@@ -0,0 +1,105 @@
+package report_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	appreport "xiaoheiplay/internal/app/report"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+func TestFinanceReports_RunStoreAndResend(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "fin_user", "fin_user@example.com", "pass")
+
+	gt := domain.GoodsType{Code: "vps", Name: "云主机", Active: true}
+	if err := repo.CreateGoodsType(ctx, &gt); err != nil {
+		t.Fatalf("create goods type: %v", err)
+	}
+	order := domain.Order{UserID: user.ID, OrderNo: "ORD-FIN-1", Status: domain.OrderStatusApproved, TotalAmount: 5000, Currency: "CNY", CouponCode: "SAVE5", CouponDiscount: 500}
+	if err := repo.CreateOrder(ctx, &order); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	if err := repo.CreateOrderItems(ctx, []domain.OrderItem{{OrderID: order.ID, GoodsTypeID: gt.ID, Amount: 5000, Qty: 2, Status: domain.OrderItemStatusApproved, Action: "create", SpecJSON: `{"tier_discount":200}`}}); err != nil {
+		t.Fatalf("create order items: %v", err)
+	}
+	refund := domain.WalletOrder{UserID: user.ID, Type: domain.WalletOrderRefund, Amount: 300, Currency: "CNY", Status: domain.WalletOrderApproved}
+	if err := repo.CreateWalletOrder(ctx, &refund); err != nil {
+		t.Fatalf("create refund: %v", err)
+	}
+	if _, err := repo.AdjustWalletBalance(ctx, user.ID, 1000, "credit", "test", 0, "seed"); err != nil {
+		t.Fatalf("seed wallet: %v", err)
+	}
+
+	reports := appreport.NewService(repo, repo, repo, repo, repo, repo)
+	reports.SetWalletSources(repo, repo, repo)
+	mailer := &testutil.FakeEmailSender{}
+	svc := appreport.NewFinanceService(repo, reports, repo)
+	svc.SetMailer(mailer)
+
+	if _, err := svc.CreateDefinition(ctx, 1, appshared.FinanceReportDefinitionInput{Name: "月结", Period: "monthly", Recipients: []string{"not-an-email"}}); !errors.Is(err, domain.ErrInvalidFinanceReport) {
+		t.Fatalf("expected invalid definition, got %v", err)
+	}
+	def, err := svc.CreateDefinition(ctx, 1, appshared.FinanceReportDefinitionInput{Name: "月结", Period: "monthly", Formats: []string{"xlsx", "csv"}, Recipients: []string{"Finance@Example.com"}, Enabled: true})
+	if err != nil {
+		t.Fatalf("create definition: %v", err)
+	}
+	if len(def.Recipients) != 1 || def.Recipients[0] != "finance@example.com" {
+		t.Fatalf("unexpected recipients: %v", def.Recipients)
+	}
+
+	now := time.Now()
+	run, err := svc.RunNow(ctx, 1, def.ID, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("run now: %v", err)
+	}
+	if run.Status != domain.FinanceReportRunSuccess || len(run.Files) != 7 {
+		t.Fatalf("unexpected run: status=%s err=%s files=%d", run.Status, run.Error, len(run.Files))
+	}
+	if len(mailer.Sends) != 1 || len(mailer.Sends[0].Attachments) != 7 || !strings.Contains(mailer.Sends[0].Body, "50.00") {
+		t.Fatalf("unexpected email: %+v", mailer.Sends)
+	}
+
+	stored, err := svc.GetRun(ctx, run.ID)
+	if err != nil || stored.DeliveredAt == nil {
+		t.Fatalf("expected delivered run, got %+v err=%v", stored, err)
+	}
+	var summaryID int64
+	for _, file := range stored.Files {
+		if strings.HasSuffix(file.Name, "_summary.csv") {
+			summaryID = file.ID
+		}
+	}
+	summary, err := svc.GetFile(ctx, run.ID, summaryID)
+	if err != nil {
+		t.Fatalf("get summary file: %v", err)
+	}
+	for _, want := range []string{"收入合计（元）,50.00", "退款合计（元）,3.00", "优惠券折扣（元）,5.00", "等级折扣（元）,4.00", "钱包余额合计（元）,10.00"} {
+		if !strings.Contains(string(summary.Content), want) {
+			t.Fatalf("summary missing %q:\n%s", want, summary.Content)
+		}
+	}
+
+	mailer.Err = errors.New("smtp down")
+	resent, err := svc.Resend(ctx, 1, run.ID, nil)
+	if err != nil {
+		t.Fatalf("resend: %v", err)
+	}
+	if resent.Status != domain.FinanceReportRunDeliveryFailed || !strings.Contains(resent.Error, "smtp down") {
+		t.Fatalf("unexpected resent run: %+v", resent)
+	}
+	mailer.Err = nil
+
+	if n, err := svc.RunDue(ctx); err != nil || n != 1 {
+		t.Fatalf("expected one scheduled run, got %d err=%v", n, err)
+	}
+	if n, err := svc.RunDue(ctx); err != nil || n != 0 {
+		t.Fatalf("expected scheduled run to happen once per period, got %d err=%v", n, err)
+	}
+}
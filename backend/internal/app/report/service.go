@@ -19,6 +19,10 @@ type Service struct {
 	vps        appports.VPSRepository
 	catalog    appports.CatalogRepository
 	goodsTypes appports.GoodsTypeRepository
+
+	walletOrders   appports.WalletOrderRepository
+	walletStats    appports.WalletStatsRepository
+	walletBalances walletBalanceLister
 }
 
 type OverviewReport struct {
@@ -298,6 +302,8 @@ type paymentSlice struct {
 	pkg     int64
 	item    domain.OrderItem
 	order   domain.Order
+	// lineName is the plan group name of the line, when resolved.
+	lineName string
 }
 
 type revenueScope struct {
@@ -562,15 +568,16 @@ func (s *Service) collectRevenueDataByDimension(ctx context.Context, q RevenueAn
 					Status:    domain.PaymentStatusApproved,
 					CreatedAt: effectiveAt,
 				},
-				amount:  amount,
-				dimID:   dimID,
-				dimName: dimName,
-				goods:   scope.goodsTypeID,
-				region:  scope.regionID,
-				line:    scope.lineID,
-				pkg:     scope.packageID,
-				item:    it,
-				order:   order,
+				amount:   amount,
+				dimID:    dimID,
+				dimName:  dimName,
+				goods:    scope.goodsTypeID,
+				region:   scope.regionID,
+				line:     scope.lineID,
+				pkg:      scope.packageID,
+				item:     it,
+				order:    order,
+				lineName: scope.lineName,
 			})
 		}
 	}
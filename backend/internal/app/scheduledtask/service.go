@@ -3,6 +3,7 @@ package scheduledtask
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"sync"
 	"time"
 
@@ -19,19 +20,20 @@ const (
 )
 
 type ScheduledTaskConfig struct {
-	Key         string       `json:"key"`
-	Name        string       `json:"name"`
-	Description string       `json:"description"`
-	Enabled     bool         `json:"enabled"`
-	Strategy    TaskStrategy `json:"strategy"`
-	IntervalSec int          `json:"interval_sec"`
-	DailyAt     string       `json:"daily_at"`
-	LastRunAt   *time.Time   `json:"last_run_at,omitempty"`
-	NextRunAt   *time.Time   `json:"next_run_at,omitempty"`
-	Running     bool         `json:"running"`
-	LastStatus  string       `json:"last_status,omitempty"`
-	LastError   string       `json:"last_error,omitempty"`
-	LastElapsed int          `json:"last_elapsed_sec,omitempty"`
+	Key          string        `json:"key"`
+	Name         string        `json:"name"`
+	Description  string        `json:"description"`
+	Enabled      bool          `json:"enabled"`
+	Strategy     TaskStrategy  `json:"strategy"`
+	IntervalSec  int           `json:"interval_sec"`
+	DailyAt      string        `json:"daily_at"`
+	LastRunAt    *time.Time    `json:"last_run_at,omitempty"`
+	NextRunAt    *time.Time    `json:"next_run_at,omitempty"`
+	Running      bool          `json:"running"`
+	LastStatus   string        `json:"last_status,omitempty"`
+	LastError    string        `json:"last_error,omitempty"`
+	LastElapsed  int           `json:"last_elapsed_sec,omitempty"`
+	LastDuration time.Duration `json:"-"`
 }
 
 type ScheduledTaskUpdate = appshared.ScheduledTaskUpdate
@@ -64,16 +66,49 @@ type integrationInventorySyncService interface {
 	SyncAutomationInventoryForGoodsType(ctx context.Context, goodsTypeID int64) (int, error)
 }
 
+type inventoryTaskService interface {
+	ExpireInventoryReservations(ctx context.Context, limit int) (int, error)
+}
+
+type backupPolicyTaskService interface {
+	RunDue(ctx context.Context, limit int) (int, error)
+}
+
+type vpsMigrationTaskService interface {
+	RunDue(ctx context.Context, limit int) (int, error)
+}
+
+type ledgerTaskService interface {
+	VerifyAndAlert(ctx context.Context) (domain.LedgerVerification, error)
+}
+
+type financeReportTaskService interface {
+	RunDue(ctx context.Context) (int, error)
+}
+
+type dunningTaskService interface {
+	Run(ctx context.Context) (int, error)
+}
+
+type broadcastTaskService interface {
+	RunDue(ctx context.Context) (int, error)
+}
+
+type deferredNotificationTaskService interface {
+	SendDeferred(ctx context.Context) (int, error)
+}
+
 type logRetentionCleaner interface {
 	Cleanup(ctx context.Context) (string, error)
 }
 
 type taskRuntime struct {
-	lastRun     time.Time
-	running     bool
-	lastStatus  string
-	lastError   string
-	lastElapsed int
+	lastRun      time.Time
+	running      bool
+	lastStatus   string
+	lastError    string
+	lastElapsed  int
+	lastDuration time.Duration
 }
 
 type Service struct {
@@ -84,7 +119,15 @@ type Service struct {
 	realname    realnameTaskService
 	userTier    userTierTaskService
 	integration integrationInventorySyncService
+	inventory   inventoryTaskService
 	logCleaner  logRetentionCleaner
+	backups     backupPolicyTaskService
+	migrations  vpsMigrationTaskService
+	ledger      ledgerTaskService
+	finance     financeReportTaskService
+	dunning     dunningTaskService
+	broadcasts  broadcastTaskService
+	deferred    []deferredNotificationTaskService
 	runs        appports.ScheduledTaskRunRepository
 	mu          sync.Mutex
 	runtime     map[string]*taskRuntime
@@ -114,10 +157,44 @@ func (s *Service) SetIntegrationService(svc integrationInventorySyncService) {
 	s.integration = svc
 }
 
+func (s *Service) SetInventoryService(svc inventoryTaskService) {
+	s.inventory = svc
+}
+
 func (s *Service) SetLogRetentionCleaner(svc logRetentionCleaner) {
 	s.logCleaner = svc
 }
 
+func (s *Service) SetBackupPolicyService(svc backupPolicyTaskService) {
+	s.backups = svc
+}
+
+func (s *Service) SetVPSMigrationService(svc vpsMigrationTaskService) {
+	s.migrations = svc
+}
+
+func (s *Service) SetLedgerService(svc ledgerTaskService) {
+	s.ledger = svc
+}
+
+func (s *Service) SetFinanceReportService(svc financeReportTaskService) {
+	s.finance = svc
+}
+
+func (s *Service) SetDunningService(svc dunningTaskService) {
+	s.dunning = svc
+}
+
+func (s *Service) SetBroadcastService(svc broadcastTaskService) {
+	s.broadcasts = svc
+}
+
+// SetDeferredNotificationServices registers the channels whose quiet-hours
+// queue the deferred_notifications task flushes.
+func (s *Service) SetDeferredNotificationServices(svcs ...deferredNotificationTaskService) {
+	s.deferred = svcs
+}
+
 func (s *Service) Start(ctx context.Context) {
 	ticker := time.NewTicker(10 * time.Second)
 	defer ticker.Stop()
@@ -143,6 +220,7 @@ func (s *Service) ListTasks(ctx context.Context) ([]ScheduledTaskConfig, error)
 		cfg.LastStatus = rt.lastStatus
 		cfg.LastError = rt.lastError
 		cfg.LastElapsed = rt.lastElapsed
+		cfg.LastDuration = rt.lastDuration
 		s.mu.Unlock()
 		cfg.LastRunAt = last
 		cfg.NextRunAt = next
@@ -251,6 +329,7 @@ func (s *Service) executeTask(ctx context.Context, cfg ScheduledTaskConfig) {
 			rt.running = false
 			rt.lastRun = time.Now()
 			rt.lastElapsed = elapsed
+			rt.lastDuration = time.Since(start)
 			s.mu.Unlock()
 			status := "success"
 			msg := ""
@@ -311,6 +390,42 @@ func (s *Service) executeTask(ctx context.Context, cfg ScheduledTaskConfig) {
 			if s.integration != nil {
 				_, runErr = s.integration.SyncAutomationInventoryForGoodsType(ctx, 0)
 			}
+		case "inventory_reservation_expire":
+			if s.inventory != nil {
+				_, runErr = s.inventory.ExpireInventoryReservations(ctx, 100)
+			}
+		case "vps_backup_policy":
+			if s.backups != nil {
+				_, runErr = s.backups.RunDue(ctx, 100)
+			}
+		case "vps_migration":
+			if s.migrations != nil {
+				_, runErr = s.migrations.RunDue(ctx, 20)
+			}
+		case "ledger_verify":
+			if s.ledger != nil {
+				_, runErr = s.ledger.VerifyAndAlert(ctx)
+			}
+		case "finance_reports":
+			if s.finance != nil {
+				_, runErr = s.finance.RunDue(ctx)
+			}
+		case "dunning":
+			if s.dunning != nil {
+				_, runErr = s.dunning.Run(ctx)
+			}
+		case "broadcasts":
+			if s.broadcasts != nil {
+				_, runErr = s.broadcasts.RunDue(ctx)
+			}
+		case "deferred_notifications":
+			var errs []error
+			for _, svc := range s.deferred {
+				if _, err := svc.SendDeferred(ctx); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			runErr = errors.Join(errs...)
 		case "log_retention_cleanup":
 			if s.logCleaner != nil {
 				_, runErr = s.logCleaner.Cleanup(ctx)
@@ -496,7 +611,7 @@ func defaultTaskDefinitions() map[string]ScheduledTaskConfig {
 		"vps_expire_cleanup": {
 			Key:         "vps_expire_cleanup",
 			Name:        "VPS Expire Cleanup",
-			Description: "Auto delete expired VPS instances based on lifecycle settings.",
+			Description: "Auto delete expired VPS instances based on lifecycle settings and tear down expired trials.",
 			Enabled:     true,
 			Strategy:    TaskStrategyDaily,
 			DailyAt:     "03:00",
@@ -533,6 +648,70 @@ func defaultTaskDefinitions() map[string]ScheduledTaskConfig {
 			Strategy:    TaskStrategyInterval,
 			IntervalSec: 300,
 		},
+		"inventory_reservation_expire": {
+			Key:         "inventory_reservation_expire",
+			Name:        "Inventory Reservation Expire",
+			Description: "Cancel unpaid orders whose stock reservation expired and reconcile reserved stock.",
+			Enabled:     true,
+			Strategy:    TaskStrategyInterval,
+			IntervalSec: 60,
+		},
+		"vps_backup_policy": {
+			Key:         "vps_backup_policy",
+			Name:        "VPS Backup Policy",
+			Description: "Run due scheduled snapshot and backup policies and prune beyond retention.",
+			Enabled:     true,
+			Strategy:    TaskStrategyInterval,
+			IntervalSec: 60,
+		},
+		"vps_migration": {
+			Key:         "vps_migration",
+			Name:        "VPS Migration",
+			Description: "Advance running VPS migrations between automation lines and retry failed steps.",
+			Enabled:     true,
+			Strategy:    TaskStrategyInterval,
+			IntervalSec: 30,
+		},
+		"ledger_verify": {
+			Key:         "ledger_verify",
+			Name:        "Ledger Verify",
+			Description: "Check wallet balances against the ledger and alert on drift or unbalanced entries.",
+			Enabled:     true,
+			Strategy:    TaskStrategyDaily,
+			DailyAt:     "04:00",
+		},
+		"dunning": {
+			Key:         "dunning",
+			Name:        "Dunning",
+			Description: "Run due dunning steps (reminders, suspension, final warning, termination) for expiring instances.",
+			Enabled:     true,
+			Strategy:    TaskStrategyInterval,
+			IntervalSec: 3600,
+		},
+		"broadcasts": {
+			Key:         "broadcasts",
+			Name:        "Broadcasts",
+			Description: "Start scheduled announcement broadcasts and send pending deliveries within each channel's rate limit.",
+			Enabled:     true,
+			Strategy:    TaskStrategyInterval,
+			IntervalSec: 60,
+		},
+		"deferred_notifications": {
+			Key:         "deferred_notifications",
+			Name:        "Deferred Notifications",
+			Description: "Send SMS and push held back by users' quiet hours once the window ends.",
+			Enabled:     true,
+			Strategy:    TaskStrategyInterval,
+			IntervalSec: 60,
+		},
+		"finance_reports": {
+			Key:         "finance_reports",
+			Name:        "Finance Reports",
+			Description: "Generate and email finance close packages for report definitions whose period has ended.",
+			Enabled:     true,
+			Strategy:    TaskStrategyDaily,
+			DailyAt:     "06:00",
+		},
 		"log_retention_cleanup": {
 			Key:         "log_retention_cleanup",
 			Name:        "Log Retention Cleanup",
@@ -0,0 +1,65 @@
+package shared
+
+import "xiaoheiplay/internal/domain"
+
+// CartAddon is one addon SKU picked in a cart spec. Kind, Name, Units and
+// Monthly (per quantity, after user tier pricing) are filled in when the
+// spec is priced; values sent by clients are overwritten.
+type CartAddon struct {
+	AddonID int64            `json:"addon_id"`
+	Qty     int              `json:"qty"`
+	Kind    domain.AddonKind `json:"kind,omitempty"`
+	Name    string           `json:"name,omitempty"`
+	Units   int              `json:"units,omitempty"`
+	Monthly int64            `json:"monthly_cents,omitempty"`
+}
+
+// NormalizeAddons merges repeated picks of the same addon and drops picks
+// with zero quantity.
+func (s *CartSpec) NormalizeAddons() error {
+	if len(s.Addons) == 0 {
+		s.Addons = nil
+		return nil
+	}
+	index := make(map[int64]int, len(s.Addons))
+	out := make([]CartAddon, 0, len(s.Addons))
+	for _, item := range s.Addons {
+		if item.AddonID <= 0 || item.Qty < 0 {
+			return ErrInvalidInput
+		}
+		if item.Qty == 0 {
+			continue
+		}
+		if i, ok := index[item.AddonID]; ok {
+			out[i].Qty += item.Qty
+			continue
+		}
+		index[item.AddonID] = len(out)
+		out = append(out, item)
+	}
+	if len(out) == 0 {
+		out = nil
+	}
+	s.Addons = out
+	return nil
+}
+
+// AddonsMonthly is the monthly price of all priced addons in the spec.
+func (s CartSpec) AddonsMonthly() int64 {
+	var total int64
+	for _, item := range s.Addons {
+		total += item.Monthly * int64(item.Qty)
+	}
+	return total
+}
+
+// AddonUnits is the number of units of kind granted by the spec's addons.
+func (s CartSpec) AddonUnits(kind domain.AddonKind) int {
+	units := 0
+	for _, item := range s.Addons {
+		if item.Kind == kind {
+			units += item.Units * item.Qty
+		}
+	}
+	return units
+}
@@ -0,0 +1,12 @@
+package shared
+
+import "strings"
+
+// EscapeCSVCell prefixes a quote to text that a spreadsheet would otherwise
+// evaluate as a formula when the exported CSV is opened.
+func EscapeCSVCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
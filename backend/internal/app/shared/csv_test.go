@@ -0,0 +1,27 @@
+package shared_test
+
+import (
+	"testing"
+
+	appshared "xiaoheiplay/internal/app/shared"
+)
+
+func TestEscapeCSVCell(t *testing.T) {
+	cases := map[string]string{
+		"=1+1":          "'=1+1",
+		"+86 138":       "'+86 138",
+		"-2":            "'-2",
+		"@SUM(A1)":      "'@SUM(A1)",
+		"\t=1+1":        "'\t=1+1",
+		"\r=1+1":        "'\r=1+1",
+		"ICBC":          "ICBC",
+		"a=b":           "a=b",
+		"":              "",
+		"6222000011112": "6222000011112",
+	}
+	for in, want := range cases {
+		if got := appshared.EscapeCSVCell(in); got != want {
+			t.Fatalf("EscapeCSVCell(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package shared
+
+import "xiaoheiplay/internal/domain"
+
+// StockShortage describes one package or plan group that cannot cover the
+// requested quantity. Available is what is left after local reservations.
+type StockShortage struct {
+	PackageID   int64  `json:"package_id"`
+	PlanGroupID int64  `json:"plan_group_id,omitempty"`
+	Scope       string `json:"scope"`
+	Requested   int    `json:"requested"`
+	Available   int    `json:"available"`
+}
+
+// StockShortageError is returned when an order cannot reserve its stock; it
+// matches domain.ErrInsufficientStock with errors.Is.
+type StockShortageError struct {
+	Shortages []StockShortage
+}
+
+func (e *StockShortageError) Error() string {
+	return domain.ErrInsufficientStock.Error()
+}
+
+func (e *StockShortageError) Unwrap() error {
+	return domain.ErrInsufficientStock
+}
@@ -0,0 +1,33 @@
+package shared
+
+type NotifyMessage struct {
+	Type           string
+	Title          string
+	Content        string
+	Recipients     []string
+	Vars           map[string]string
+	IdempotencyKey string
+}
+
+type NotifyDelivery struct {
+	MessageID string
+}
+
+// NotifyChannel is one enabled notify plugin instance that messages can be
+// routed to.
+type NotifyChannel struct {
+	PluginID   string `json:"plugin_id"`
+	InstanceID string `json:"instance_id"`
+	Name       string `json:"name"`
+	Channel    string `json:"channel"`
+	Recipients bool   `json:"recipients"`
+	HTML       bool   `json:"html"`
+	Loaded     bool   `json:"loaded"`
+}
+
+// NotifyRoute sends one notification type ("*" for all, "order.*" for a
+// prefix) to channels referenced as "plugin_id/instance_id".
+type NotifyRoute struct {
+	Type     string   `json:"type"`
+	Channels []string `json:"channels"`
+}
@@ -27,11 +27,18 @@ type PluginAutomationCapability struct {
 	CatalogReadonly     bool              `json:"catalog_readonly,omitempty"`
 }
 
+type PluginNotifyCapability struct {
+	Channel    string `json:"channel"`
+	Recipients bool   `json:"recipients,omitempty"`
+	HTML       bool   `json:"html,omitempty"`
+}
+
 type PluginCapabilities struct {
 	SMS        *PluginSMSCapability        `json:"sms,omitempty"`
 	Payment    *PluginPaymentCapability    `json:"payment,omitempty"`
 	KYC        *PluginKYCCapability        `json:"kyc,omitempty"`
 	Automation *PluginAutomationCapability `json:"automation,omitempty"`
+	Notify     *PluginNotifyCapability     `json:"notify,omitempty"`
 }
 
 type PluginManifest struct {
@@ -64,6 +71,9 @@ type PluginListItem struct {
 	LastHealthAt    *time.Time                   `json:"last_health_at"`
 	HealthStatus    string                       `json:"health_status"`
 	HealthMessage   string                       `json:"health_message"`
+	Restarts        int                          `json:"restarts"`
+	CircuitState    string                       `json:"circuit_state"`
+	LastCrashAt     *time.Time                   `json:"last_crash_at"`
 	Capabilities    PluginManifest               `json:"manifest"`
 	Entry           PluginEntryInfo              `json:"entry"`
 }
@@ -77,6 +87,38 @@ type PluginDiscoverItem struct {
 	Entry           PluginEntryInfo              `json:"entry"`
 }
 
+// PluginRepository is a configured plugin source; URL is http(s):// or a local directory.
+type PluginRepository struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type PluginRepositoryVersion struct {
+	Version     string   `json:"version"`
+	Platforms   []string `json:"platforms,omitempty"`
+	Changelog   string   `json:"changelog,omitempty"`
+	PublishedAt string   `json:"published_at,omitempty"`
+}
+
+type PluginRepositoryItem struct {
+	Repository       string                    `json:"repository"`
+	Category         string                    `json:"category"`
+	PluginID         string                    `json:"plugin_id"`
+	Name             string                    `json:"name"`
+	Description      string                    `json:"description,omitempty"`
+	Versions         []PluginRepositoryVersion `json:"versions"`
+	LatestVersion    string                    `json:"latest_version"`
+	InstalledVersion string                    `json:"installed_version,omitempty"`
+	UpgradeAvailable bool                      `json:"upgrade_available"`
+}
+
+// PluginRepositoryError reports a repository whose index could not be loaded,
+// so one broken source does not hide the others.
+type PluginRepositoryError struct {
+	Repository string `json:"repository"`
+	Error      string `json:"error"`
+}
+
 type PluginPaymentMethodState struct {
 	Method  string `json:"method"`
 	Enabled bool   `json:"enabled"`
@@ -0,0 +1,44 @@
+package shared
+
+import "xiaoheiplay/internal/domain"
+
+const (
+	MaxProvisionSSHKeys  = 10
+	MaxProvisionUserData = 16 * 1024
+)
+
+// NormalizeProvisionOptions dedupes the picked SSH keys and bounds the
+// cloud-init user-data carried by a cart spec.
+func (s *CartSpec) NormalizeProvisionOptions() error {
+	ids, err := NormalizeSSHKeyIDs(s.SSHKeyIDs)
+	if err != nil {
+		return err
+	}
+	s.SSHKeyIDs = ids
+	if len(s.UserData) > MaxProvisionUserData {
+		return domain.ErrUserDataTooLarge
+	}
+	return nil
+}
+
+func NormalizeSSHKeyIDs(ids []int64) ([]int64, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	seen := make(map[int64]struct{}, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, ErrInvalidInput
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	if len(out) > MaxProvisionSSHKeys {
+		return nil, ErrInvalidInput
+	}
+	return out, nil
+}
@@ -8,13 +8,19 @@ import (
 )
 
 type CartSpec struct {
-	AddCores       int   `json:"add_cores"`
-	AddMemGB       int   `json:"add_mem_gb"`
-	AddDiskGB      int   `json:"add_disk_gb"`
-	AddBWMbps      int   `json:"add_bw_mbps"`
-	BillingCycleID int64 `json:"billing_cycle_id"`
-	CycleQty       int   `json:"cycle_qty"`
-	DurationMonths int   `json:"duration_months"`
+	AddCores       int         `json:"add_cores"`
+	AddMemGB       int         `json:"add_mem_gb"`
+	AddDiskGB      int         `json:"add_disk_gb"`
+	AddBWMbps      int         `json:"add_bw_mbps"`
+	BillingCycleID int64       `json:"billing_cycle_id"`
+	CycleQty       int         `json:"cycle_qty"`
+	DurationMonths int         `json:"duration_months"`
+	SSHKeyIDs      []int64     `json:"ssh_key_ids,omitempty"`
+	UserData       string      `json:"user_data,omitempty"`
+	Addons         []CartAddon `json:"addons,omitempty"`
+	// TierDiscount is set by the server at pricing time: the per-unit list
+	// price minus the tier price for the whole billing cycle, in cents.
+	TierDiscount int64 `json:"tier_discount,omitempty"`
 }
 
 type RegisterInput struct {
@@ -57,8 +63,15 @@ type OrderFilter struct {
 	To     *time.Time
 }
 
+type DunningRecordFilter struct {
+	InstanceID int64
+	Action     string
+	Status     string
+}
+
 type PaymentFilter struct {
 	Status string
+	UserID int64
 	From   *time.Time
 	To     *time.Time
 }
@@ -99,6 +112,36 @@ type CouponFilter struct {
 	Active         *bool
 }
 
+// LedgerEntryFilter selects entries; Account matches entries with a line on
+// that account.
+type LedgerEntryFilter struct {
+	Account string
+	Kind    string
+	RefType string
+	RefID   int64
+	UserID  int64
+}
+
+type IPAddressFilter struct {
+	Keyword string
+	LineID  int64
+	VPSID   int64
+	Status  string
+}
+
+// VPSTrialFilter selects trials; non-empty fields are ANDed. Since limits
+// the match to trials created at or after it.
+type VPSTrialFilter struct {
+	UserID       int64
+	PackageID    int64
+	Status       string
+	IdentityHash string
+	Phone        string
+	ClientIP     string
+	DeviceID     string
+	Since        *time.Time
+}
+
 type OrderItemInput struct {
 	PackageID int64    `json:"package_id"`
 	SystemID  int64    `json:"system_id"`
@@ -220,6 +263,16 @@ type AutomationCreateHostRequest struct {
 	PortNum    int
 	Snapshot   int
 	Backups    int
+	SSHKeys    []string
+	UserData   string
+}
+
+type AutomationResetOSRequest struct {
+	HostID     int64
+	TemplateID int64
+	Password   string
+	SSHKeys    []string
+	UserData   string
 }
 
 type AutomationCreateHostResult struct {
@@ -239,9 +292,17 @@ type AutomationHostInfo struct {
 	VNCPassword   string
 	OSPassword    string
 	RemoteIP      string
+	IPs           []AutomationIP
 	ExpireAt      *time.Time
 }
 
+type AutomationIP struct {
+	Address string
+	Version int
+	Primary bool
+	PTR     string
+}
+
 type AutomationHostSimple struct {
 	ID       int64
 	HostName string
@@ -335,7 +396,7 @@ type AutomationClient interface {
 	StartHost(ctx context.Context, hostID int64) error
 	ShutdownHost(ctx context.Context, hostID int64) error
 	RebootHost(ctx context.Context, hostID int64) error
-	ResetOS(ctx context.Context, hostID int64, templateID int64, password string) error
+	ResetOS(ctx context.Context, req AutomationResetOSRequest) error
 	ResetOSPassword(ctx context.Context, hostID int64, password string) error
 	ListSnapshots(ctx context.Context, hostID int64) ([]AutomationSnapshot, error)
 	CreateSnapshot(ctx context.Context, hostID int64) error
@@ -348,6 +409,7 @@ type AutomationClient interface {
 	ListFirewallRules(ctx context.Context, hostID int64) ([]AutomationFirewallRule, error)
 	AddFirewallRule(ctx context.Context, req AutomationFirewallRuleCreate) error
 	DeleteFirewallRule(ctx context.Context, hostID int64, ruleID int64) error
+	SetReverseDNS(ctx context.Context, hostID int64, ip string, ptr string) error
 	ListPortMappings(ctx context.Context, hostID int64) ([]AutomationPortMapping, error)
 	AddPortMapping(ctx context.Context, req AutomationPortMappingCreate) error
 	DeletePortMapping(ctx context.Context, hostID int64, mappingID int64) error
@@ -369,6 +431,55 @@ type RealNameVerifyInput struct {
 	CallbackURL string
 }
 
+// RealNameChainConfig configures the providers tried after the primary one
+// when it errors or times out, and whether users may fall back to manual
+// review with ID images.
+type RealNameChainConfig struct {
+	FallbackProviders []string
+	TimeoutSec        int
+	ManualReview      bool
+}
+
+type RealNameManualInput struct {
+	RealName  string
+	IDNumber  string
+	UploadIDs []int64
+}
+
+type CompanyVerificationInput struct {
+	CompanyName         string
+	CreditCode          string
+	LegalPersonName     string
+	LegalPersonIDNumber string
+	InvoiceTitle        string
+	TaxNumber           string
+	InvoiceAddress      string
+	InvoicePhone        string
+	InvoiceBank         string
+	InvoiceBankAccount  string
+	UploadIDs           []int64
+}
+
+// CompanyRequirement demands a verified company for an action. An empty
+// GoodsTypeIDs matches every goods type and a zero MinAmount every amount;
+// when both are set the order must match both.
+type CompanyRequirement struct {
+	Action       string  `json:"action"`
+	GoodsTypeIDs []int64 `json:"goods_type_ids"`
+	MinAmount    int64   `json:"min_amount"`
+}
+
+type CompanyConfig struct {
+	Enabled      bool
+	Requirements []CompanyRequirement
+}
+
+// RealNameActionScope describes the order an action is performed for.
+type RealNameActionScope struct {
+	GoodsTypeIDs []int64
+	Amount       int64
+}
+
 type RealNameProvider interface {
 	Key() string
 	Name() string
@@ -379,6 +490,12 @@ type RealNameProviderWithInput interface {
 	VerifyWithInput(ctx context.Context, in RealNameVerifyInput) (bool, string, error)
 }
 
+// CompanyVerificationProvider is implemented by providers that can check a
+// company's registration and legal person.
+type CompanyVerificationProvider interface {
+	VerifyCompany(ctx context.Context, in CompanyVerificationInput) (bool, string, error)
+}
+
 type RealNameProviderPendingPoller interface {
 	QueryPending(ctx context.Context, token string, provider string) (status string, reason string, err error)
 }
@@ -434,6 +551,35 @@ type WalletOrderCreateInput struct {
 	Meta     map[string]any
 }
 
+type PayoutAccountInput struct {
+	Channel     string
+	AccountName string
+	AccountNo   string
+	BankName    string
+	BankBranch  string
+}
+
+type PayoutBatchCreateInput struct {
+	Channel  string
+	OrderIDs []int64
+	Note     string
+}
+
+type FinanceReportDefinitionInput struct {
+	Name       string
+	Period     string
+	Formats    []string
+	Recipients []string
+	Enabled    bool
+}
+
+// DunningPolicyInput replaces a goods type's dunning schedule; empty Steps
+// use the default schedule.
+type DunningPolicyInput struct {
+	Enabled bool
+	Steps   []domain.DunningStep
+}
+
 type TaskStrategy string
 
 const (
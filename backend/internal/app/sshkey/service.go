@@ -0,0 +1,121 @@
+package sshkey
+
+import (
+	"context"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+const (
+	MaxKeysPerUser = 20
+	maxNameLen     = 64
+)
+
+// Service stores users' SSH public keys and resolves the ones picked for a
+// new server or a reinstall.
+type Service struct {
+	repo appports.SSHKeyRepository
+}
+
+func NewService(repo appports.SSHKeyRepository) *Service {
+	return &Service{repo: repo}
+}
+
+func (s *Service) List(ctx context.Context, userID int64) ([]domain.SSHKey, error) {
+	if userID <= 0 {
+		return nil, appshared.ErrInvalidInput
+	}
+	return s.repo.ListSSHKeys(ctx, userID)
+}
+
+// Create validates an authorized_keys line and stores it in canonical form.
+// The key comment is used as the name when none is given.
+func (s *Service) Create(ctx context.Context, userID int64, name, publicKey string) (domain.SSHKey, error) {
+	if userID <= 0 {
+		return domain.SSHKey{}, appshared.ErrInvalidInput
+	}
+	pub, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(publicKey)))
+	if err != nil {
+		return domain.SSHKey{}, domain.ErrInvalidSSHKey
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = strings.TrimSpace(comment)
+	}
+	if name == "" {
+		name = pub.Type()
+	}
+	if len([]rune(name)) > maxNameLen {
+		return domain.SSHKey{}, appshared.ErrInvalidInput
+	}
+	existing, err := s.repo.ListSSHKeys(ctx, userID)
+	if err != nil {
+		return domain.SSHKey{}, err
+	}
+	if len(existing) >= MaxKeysPerUser {
+		return domain.SSHKey{}, domain.ErrSSHKeyLimit
+	}
+	fingerprint := ssh.FingerprintSHA256(pub)
+	for _, key := range existing {
+		if key.Fingerprint == fingerprint {
+			return domain.SSHKey{}, domain.ErrSSHKeyExists
+		}
+	}
+	line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub)))
+	if comment = strings.TrimSpace(comment); comment != "" {
+		line += " " + comment
+	}
+	key := domain.SSHKey{
+		UserID:      userID,
+		Name:        name,
+		PublicKey:   line,
+		Fingerprint: fingerprint,
+	}
+	if err := s.repo.CreateSSHKey(ctx, &key); err != nil {
+		return domain.SSHKey{}, err
+	}
+	return key, nil
+}
+
+func (s *Service) Delete(ctx context.Context, userID, id int64) error {
+	if userID <= 0 || id <= 0 {
+		return appshared.ErrInvalidInput
+	}
+	return s.repo.DeleteSSHKey(ctx, userID, id)
+}
+
+// Validate checks that every id belongs to the user.
+func (s *Service) Validate(ctx context.Context, userID int64, ids []int64) error {
+	_, err := s.PublicKeys(ctx, userID, ids)
+	return err
+}
+
+// PublicKeys returns the authorized_keys lines for ids in the given order.
+// An id the user does not own fails with ErrInvalidSSHKey.
+func (s *Service) PublicKeys(ctx context.Context, userID int64, ids []int64) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	keys, err := s.repo.ListSSHKeys(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]string, len(keys))
+	for _, key := range keys {
+		byID[key.ID] = key.PublicKey
+	}
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		line, ok := byID[id]
+		if !ok {
+			return nil, domain.ErrInvalidSSHKey
+		}
+		out = append(out, line)
+	}
+	return out, nil
+}
@@ -0,0 +1,84 @@
+package sshkey_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appsshkey "xiaoheiplay/internal/app/sshkey"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+const (
+	testKeyA = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAICVHTKFL2RRQKAuwEpgBGQ8fsiXh7gx89NuAheTIwUEs alice@laptop"
+	testKeyB = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAICrYN1/rf6f4FZQp2z3oQnEae1GAl83IIdoLiX90GZsW"
+)
+
+func TestSSHKeyService_CreateAndDedupe(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "sshkey", "sshkey@example.com", "pass")
+	svc := appsshkey.NewService(repo)
+
+	key, err := svc.Create(ctx, user.ID, "", "  "+testKeyA+"\n")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if key.Name != "alice@laptop" || key.PublicKey != testKeyA || key.Fingerprint == "" {
+		t.Fatalf("unexpected key: %+v", key)
+	}
+	if _, err := svc.Create(ctx, user.ID, "again", testKeyA); !errors.Is(err, domain.ErrSSHKeyExists) {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+	if _, err := svc.Create(ctx, user.ID, "bad", "ssh-rsa not-a-key"); !errors.Is(err, domain.ErrInvalidSSHKey) {
+		t.Fatalf("expected invalid key error, got %v", err)
+	}
+	second, err := svc.Create(ctx, user.ID, "", testKeyB)
+	if err != nil {
+		t.Fatalf("create second: %v", err)
+	}
+	if second.Name != "ssh-ed25519" {
+		t.Fatalf("expected key type as name, got %q", second.Name)
+	}
+	keys, err := svc.List(ctx, user.ID)
+	if err != nil || len(keys) != 2 {
+		t.Fatalf("list: %v %d", err, len(keys))
+	}
+}
+
+func TestSSHKeyService_PublicKeysOwnership(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	owner := testutil.CreateUser(t, repo, "owner", "owner@example.com", "pass")
+	other := testutil.CreateUser(t, repo, "other", "other@example.com", "pass")
+	svc := appsshkey.NewService(repo)
+
+	a, err := svc.Create(ctx, owner.ID, "a", testKeyA)
+	if err != nil {
+		t.Fatalf("create a: %v", err)
+	}
+	b, err := svc.Create(ctx, owner.ID, "b", testKeyB)
+	if err != nil {
+		t.Fatalf("create b: %v", err)
+	}
+	lines, err := svc.PublicKeys(ctx, owner.ID, []int64{b.ID, a.ID})
+	if err != nil {
+		t.Fatalf("public keys: %v", err)
+	}
+	if len(lines) != 2 || lines[0] != testKeyB || lines[1] != testKeyA {
+		t.Fatalf("unexpected lines: %v", lines)
+	}
+	if err := svc.Validate(ctx, other.ID, []int64{a.ID}); !errors.Is(err, domain.ErrInvalidSSHKey) {
+		t.Fatalf("expected foreign key to be rejected, got %v", err)
+	}
+	if err := svc.Delete(ctx, other.ID, a.ID); err == nil {
+		t.Fatalf("expected delete of foreign key to fail")
+	}
+	if err := svc.Delete(ctx, owner.ID, a.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := svc.PublicKeys(ctx, owner.ID, []int64{a.ID}); !errors.Is(err, domain.ErrInvalidSSHKey) {
+		t.Fatalf("expected deleted key to be rejected, got %v", err)
+	}
+}
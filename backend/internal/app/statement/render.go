@@ -0,0 +1,247 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"time"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/pkg/money"
+	"xiaoheiplay/internal/pkg/pdf"
+)
+
+const (
+	csvContentType = "text/csv; charset=utf-8"
+	pdfContentType = "application/pdf"
+)
+
+// File is a rendered statement ready for download.
+type File struct {
+	Name        string
+	ContentType string
+	Content     []byte
+}
+
+var kindLabels = map[domain.StatementEntryKind]string{
+	domain.StatementEntryOrder:   "订单",
+	domain.StatementEntryPayment: "支付",
+	domain.StatementEntryWallet:  "钱包",
+	domain.StatementEntryRefund:  "退款",
+	domain.StatementEntryCoupon:  "优惠券",
+}
+
+var statusLabels = map[string]string{
+	"pending_payment": "待支付",
+	"pending_review":  "待审核",
+	"approved":        "已通过",
+	"rejected":        "已拒绝",
+	"provisioning":    "开通中",
+	"active":          "已完成",
+	"failed":          "失败",
+	"canceled":        "已取消",
+}
+
+func render(st domain.Statement, format string) (File, error) {
+	base := fmt.Sprintf("statement_%d_%s_%s", st.UserID, st.From.Format("20060102"), st.To.Add(-time.Second).Format("20060102"))
+	if format == domain.StatementFormatPDF {
+		content, err := renderPDF(st)
+		if err != nil {
+			return File{}, err
+		}
+		return File{Name: base + ".pdf", ContentType: pdfContentType, Content: content}, nil
+	}
+	content, err := renderCSV(st)
+	if err != nil {
+		return File{}, err
+	}
+	return File{Name: base + ".csv", ContentType: csvContentType, Content: content}, nil
+}
+
+func summaryRows(st domain.Statement) [][]string {
+	return [][]string{
+		{"用户", fmt.Sprintf("%s (ID %d)", st.Username, st.UserID)},
+		{"邮箱", st.Email},
+		{"期间", periodText(st.From, st.To)},
+		{"生成时间", st.GeneratedAt.In(st.From.Location()).Format("2006-01-02 15:04:05")},
+		{"期初钱包余额（元）", money.FormatCents(st.OpeningBalance)},
+		{"订单金额（元）", money.FormatCents(st.Totals.Orders)},
+		{"已支付（元）", money.FormatCents(st.Totals.Paid)},
+		{"退款（元）", money.FormatCents(st.Totals.Refunds)},
+		{"优惠券抵扣（元）", money.FormatCents(st.Totals.CouponDiscount)},
+		{"钱包入账（元）", money.FormatCents(st.Totals.WalletIn)},
+		{"钱包支出（元）", money.FormatCents(st.Totals.WalletOut)},
+		{"期末钱包余额（元）", money.FormatCents(st.ClosingBalance)},
+	}
+}
+
+func renderCSV(st domain.Statement) ([]byte, error) {
+	// Names, notes and codes come from customers; keep spreadsheets from
+	// running them as formulas.
+	st.Username = appshared.EscapeCSVCell(st.Username)
+	st.Email = appshared.EscapeCSVCell(st.Email)
+	buf := bytes.NewBuffer([]byte{0xEF, 0xBB, 0xBF})
+	w := csv.NewWriter(buf)
+	w.Write([]string{"账户对账单"})
+	for _, row := range summaryRows(st) {
+		w.Write(row)
+	}
+	w.Write(nil)
+	w.Write([]string{"时间", "类型", "单号", "说明", "状态", "金额（元）", "钱包变动（元）", "钱包余额（元）"})
+	for _, e := range st.Entries {
+		change, balance := "", ""
+		if e.Kind == domain.StatementEntryWallet {
+			change = signedCents(e.WalletChange)
+		}
+		if e.Balance != nil {
+			balance = money.FormatCents(*e.Balance)
+		}
+		w.Write([]string{entryTime(st, e), kindLabels[e.Kind], appshared.EscapeCSVCell(e.Ref), appshared.EscapeCSVCell(e.Description), statusText(e.Status), money.FormatCents(e.Amount), change, balance})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// PDF layout in points; columns are left edges except the two amount
+// columns, which are right edges.
+const (
+	pdfMargin     = 36.0
+	pdfFontSize   = 8.0
+	pdfRowHeight  = 13.0
+	pdfColTime    = pdfMargin
+	pdfColKind    = 112.0
+	pdfColRef     = 146.0
+	pdfColDesc    = 250.0
+	pdfColStatus  = 410.0
+	pdfColAmount  = 500.0
+	pdfColBalance = pdf.PageWidth - pdfMargin
+)
+
+type pdfWriter struct {
+	doc  *pdf.Document
+	y    float64
+	page int
+}
+
+func renderPDF(st domain.Statement) ([]byte, error) {
+	pw := &pdfWriter{doc: pdf.New()}
+	pw.newPage()
+	pw.doc.Text(pdfMargin, pw.y, 16, "账户对账单")
+	pw.y -= 26
+	summary := summaryRows(st)
+	for _, row := range summary[:4] {
+		pw.doc.Text(pdfMargin, pw.y, 9, row[0]+"："+row[1])
+		pw.y -= 14
+	}
+	pw.y -= 6
+	pw.doc.Text(pdfMargin, pw.y, 10, summary[4][0]+"："+summary[4][1])
+	pw.y -= 18
+
+	pw.tableHeader()
+	for _, e := range st.Entries {
+		if pw.y < pdfMargin+pdfRowHeight {
+			pw.newPage()
+			pw.tableHeader()
+		}
+		amount := money.FormatCents(e.Amount)
+		if e.Kind == domain.StatementEntryWallet {
+			amount = signedCents(e.WalletChange)
+		}
+		balance := ""
+		if e.Balance != nil {
+			balance = money.FormatCents(*e.Balance)
+		}
+		pw.doc.Text(pdfColTime, pw.y, pdfFontSize, entryTime(st, e)[:16])
+		pw.doc.Text(pdfColKind, pw.y, pdfFontSize, kindLabels[e.Kind])
+		pw.doc.Text(pdfColRef, pw.y, pdfFontSize, pdf.Truncate(e.Ref, pdfFontSize, pdfColDesc-pdfColRef-4))
+		pw.doc.Text(pdfColDesc, pw.y, pdfFontSize, pdf.Truncate(e.Description, pdfFontSize, pdfColStatus-pdfColDesc-4))
+		pw.doc.Text(pdfColStatus, pw.y, pdfFontSize, statusText(e.Status))
+		pw.doc.TextRight(pdfColAmount, pw.y, pdfFontSize, amount)
+		pw.doc.TextRight(pdfColBalance, pw.y, pdfFontSize, balance)
+		pw.y -= pdfRowHeight
+	}
+	if len(st.Entries) == 0 {
+		pw.doc.Text(pdfMargin, pw.y, pdfFontSize, "本期间没有记录")
+		pw.y -= pdfRowHeight
+	}
+
+	totals := summary[5:]
+	if pw.y < pdfMargin+float64(len(totals)+1)*14 {
+		pw.newPage()
+	}
+	pw.doc.Line(pdfMargin, pw.y+pdfRowHeight-4, pdfColBalance, pw.y+pdfRowHeight-4)
+	pw.y -= 8
+	for _, row := range totals {
+		pw.doc.Text(pdfColStatus-80, pw.y, 9, row[0])
+		pw.doc.TextRight(pdfColBalance, pw.y, 9, row[1])
+		pw.y -= 14
+	}
+
+	var buf bytes.Buffer
+	if err := pw.doc.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (pw *pdfWriter) newPage() {
+	pw.doc.AddPage()
+	pw.page++
+	pw.doc.TextRight(pdfColBalance, pdfMargin/2, 7, fmt.Sprintf("第 %d 页", pw.page))
+	pw.y = pdf.PageHeight - pdfMargin - 10
+}
+
+func (pw *pdfWriter) tableHeader() {
+	headers := []struct {
+		x     float64
+		right bool
+		text  string
+	}{
+		{pdfColTime, false, "时间"},
+		{pdfColKind, false, "类型"},
+		{pdfColRef, false, "单号"},
+		{pdfColDesc, false, "说明"},
+		{pdfColStatus, false, "状态"},
+		{pdfColAmount, true, "金额（元）"},
+		{pdfColBalance, true, "钱包余额（元）"},
+	}
+	for _, h := range headers {
+		if h.right {
+			pw.doc.TextRight(h.x, pw.y, pdfFontSize, h.text)
+		} else {
+			pw.doc.Text(h.x, pw.y, pdfFontSize, h.text)
+		}
+	}
+	pw.doc.Line(pdfMargin, pw.y-4, pdfColBalance, pw.y-4)
+	pw.y -= pdfRowHeight + 2
+}
+
+func entryTime(st domain.Statement, e domain.StatementEntry) string {
+	return e.Time.In(st.From.Location()).Format("2006-01-02 15:04:05")
+}
+
+func periodText(from, to time.Time) string {
+	midnight := func(t time.Time) bool { return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 }
+	if midnight(from) && midnight(to) {
+		return from.Format("2006-01-02") + " ~ " + to.Add(-time.Second).Format("2006-01-02")
+	}
+	return from.Format("2006-01-02 15:04:05") + " ~ " + to.Format("2006-01-02 15:04:05")
+}
+
+func statusText(status string) string {
+	if label, ok := statusLabels[status]; ok {
+		return label
+	}
+	return status
+}
+
+func signedCents(v int64) string {
+	if v > 0 {
+		return "+" + money.FormatCents(v)
+	}
+	return money.FormatCents(v)
+}
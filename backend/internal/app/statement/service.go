@@ -0,0 +1,291 @@
+package statement
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+const (
+	maxRange = 366 * 24 * time.Hour
+	pageSize = 200
+)
+
+type Service struct {
+	users        appports.UserRepository
+	orders       appports.OrderRepository
+	payments     appports.PaymentRepository
+	wallets      appports.WalletRepository
+	walletOrders appports.WalletOrderRepository
+	repo         appports.StatementRepository
+	audit        appports.AuditRepository
+}
+
+func NewService(users appports.UserRepository, orders appports.OrderRepository, payments appports.PaymentRepository, wallets appports.WalletRepository, walletOrders appports.WalletOrderRepository, repo appports.StatementRepository, audit appports.AuditRepository) *Service {
+	return &Service{users: users, orders: orders, payments: payments, wallets: wallets, walletOrders: walletOrders, repo: repo, audit: audit}
+}
+
+// Build collects everything that happened to the user's account in
+// [from, to) in chronological order. Balances are derived backwards from the
+// current wallet balance, so they stay correct even for wallets that were
+// funded before transactions were recorded.
+func (s *Service) Build(ctx context.Context, userID int64, from, to time.Time) (domain.Statement, error) {
+	if s.users == nil || s.orders == nil || s.payments == nil || s.wallets == nil || s.walletOrders == nil || s.repo == nil {
+		return domain.Statement{}, appshared.ErrInvalidInput
+	}
+	if userID <= 0 {
+		return domain.Statement{}, appshared.ErrInvalidInput
+	}
+	if from.IsZero() || to.IsZero() || !from.Before(to) || to.Sub(from) > maxRange {
+		return domain.Statement{}, domain.ErrInvalidStatementRange
+	}
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return domain.Statement{}, err
+	}
+	st := domain.Statement{
+		UserID:      user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		From:        from,
+		To:          to,
+		GeneratedAt: time.Now(),
+	}
+
+	if err := s.addWallet(ctx, &st); err != nil {
+		return domain.Statement{}, err
+	}
+	if err := s.addOrders(ctx, &st); err != nil {
+		return domain.Statement{}, err
+	}
+	if err := s.addPayments(ctx, &st); err != nil {
+		return domain.Statement{}, err
+	}
+	if err := s.addRefunds(ctx, &st); err != nil {
+		return domain.Statement{}, err
+	}
+	sort.SliceStable(st.Entries, func(i, j int) bool {
+		return st.Entries[i].Time.Before(st.Entries[j].Time)
+	})
+	return st, nil
+}
+
+// Export builds the statement and renders it as CSV or PDF.
+func (s *Service) Export(ctx context.Context, userID int64, from, to time.Time, format string) (File, error) {
+	if format != domain.StatementFormatCSV && format != domain.StatementFormatPDF {
+		return File{}, appshared.ErrInvalidInput
+	}
+	st, err := s.Build(ctx, userID, from, to)
+	if err != nil {
+		return File{}, err
+	}
+	return render(st, format)
+}
+
+// AdminExport is Export on behalf of an admin; the download is audited.
+func (s *Service) AdminExport(ctx context.Context, adminID, userID int64, from, to time.Time, format string) (File, error) {
+	file, err := s.Export(ctx, userID, from, to, format)
+	if err != nil {
+		return File{}, err
+	}
+	if s.audit != nil && adminID > 0 {
+		detail, _ := json.Marshal(map[string]any{"from": from, "to": to, "format": format})
+		_ = s.audit.AddAuditLog(ctx, domain.AdminAuditLog{AdminID: adminID, Action: "user.statement", TargetType: "user", TargetID: strconv.FormatInt(userID, 10), DetailJSON: string(detail)})
+	}
+	return file, nil
+}
+
+func (s *Service) addWallet(ctx context.Context, st *domain.Statement) error {
+	wallet, err := s.wallets.GetWallet(ctx, st.UserID)
+	if err != nil {
+		return err
+	}
+	sinceFrom, err := s.repo.SumWalletTransactionsSince(ctx, st.UserID, st.From)
+	if err != nil {
+		return err
+	}
+	txs, err := s.repo.ListWalletTransactionsBetween(ctx, st.UserID, st.From, st.To)
+	if err != nil {
+		return err
+	}
+	st.OpeningBalance = wallet.Balance - sinceFrom
+	balance := st.OpeningBalance
+	for _, tx := range txs {
+		balance += tx.Amount
+		after := balance
+		if tx.Amount >= 0 {
+			st.Totals.WalletIn += tx.Amount
+		} else {
+			st.Totals.WalletOut -= tx.Amount
+		}
+		st.Entries = append(st.Entries, domain.StatementEntry{
+			Time:         tx.CreatedAt,
+			Kind:         domain.StatementEntryWallet,
+			Ref:          walletRef(tx),
+			Description:  walletDescription(tx),
+			Amount:       abs(tx.Amount),
+			WalletChange: tx.Amount,
+			Balance:      &after,
+		})
+	}
+	st.ClosingBalance = balance
+	return nil
+}
+
+func (s *Service) addOrders(ctx context.Context, st *domain.Statement) error {
+	from, to := st.From, st.To
+	for offset := 0; ; offset += pageSize {
+		orders, total, err := s.orders.ListOrders(ctx, appshared.OrderFilter{UserID: st.UserID, From: &from, To: &to}, pageSize, offset)
+		if err != nil {
+			return err
+		}
+		for _, order := range orders {
+			if !order.CreatedAt.Before(to) || order.Status == domain.OrderStatusDraft {
+				continue
+			}
+			st.Entries = append(st.Entries, domain.StatementEntry{
+				Time:        order.CreatedAt,
+				Kind:        domain.StatementEntryOrder,
+				Ref:         order.OrderNo,
+				Description: "下单",
+				Status:      string(order.Status),
+				Amount:      order.TotalAmount,
+			})
+			if countsAsCharge(order.Status) {
+				st.Totals.Orders += order.TotalAmount
+			}
+			if order.CouponDiscount > 0 {
+				st.Entries = append(st.Entries, domain.StatementEntry{
+					Time:        order.CreatedAt,
+					Kind:        domain.StatementEntryCoupon,
+					Ref:         order.OrderNo,
+					Description: "优惠券 " + order.CouponCode,
+					Status:      string(order.Status),
+					Amount:      order.CouponDiscount,
+				})
+				if countsAsCharge(order.Status) {
+					st.Totals.CouponDiscount += order.CouponDiscount
+				}
+			}
+		}
+		if len(orders) == 0 || offset+len(orders) >= total {
+			return nil
+		}
+	}
+}
+
+func (s *Service) addPayments(ctx context.Context, st *domain.Statement) error {
+	from, to := st.From, st.To
+	orderNos := map[int64]string{}
+	for offset := 0; ; offset += pageSize {
+		payments, total, err := s.payments.ListPayments(ctx, appshared.PaymentFilter{UserID: st.UserID, From: &from, To: &to}, pageSize, offset)
+		if err != nil {
+			return err
+		}
+		for _, payment := range payments {
+			if !payment.CreatedAt.Before(to) {
+				continue
+			}
+			ref, ok := orderNos[payment.OrderID]
+			if !ok {
+				ref = "order#" + strconv.FormatInt(payment.OrderID, 10)
+				if order, err := s.orders.GetOrder(ctx, payment.OrderID); err == nil && order.OrderNo != "" {
+					ref = order.OrderNo
+				}
+				orderNos[payment.OrderID] = ref
+			}
+			st.Entries = append(st.Entries, domain.StatementEntry{
+				Time:        payment.CreatedAt,
+				Kind:        domain.StatementEntryPayment,
+				Ref:         ref,
+				Description: "支付 " + payment.Method,
+				Status:      string(payment.Status),
+				Amount:      payment.Amount,
+			})
+			if payment.Status == domain.PaymentStatusApproved {
+				st.Totals.Paid += payment.Amount
+			}
+		}
+		if len(payments) == 0 || offset+len(payments) >= total {
+			return nil
+		}
+	}
+}
+
+// addRefunds lists approved refunds by approval time. The refunded money
+// shows up separately as a wallet credit.
+func (s *Service) addRefunds(ctx context.Context, st *domain.Statement) error {
+	for offset := 0; ; offset += pageSize {
+		orders, total, err := s.walletOrders.ListWalletOrders(ctx, st.UserID, pageSize, offset)
+		if err != nil {
+			return err
+		}
+		for _, order := range orders {
+			if order.Type != domain.WalletOrderRefund || order.Status != domain.WalletOrderApproved {
+				continue
+			}
+			if order.UpdatedAt.Before(st.From) || !order.UpdatedAt.Before(st.To) {
+				continue
+			}
+			description := "退款"
+			if order.Note != "" {
+				description += " " + order.Note
+			}
+			st.Entries = append(st.Entries, domain.StatementEntry{
+				Time:        order.UpdatedAt,
+				Kind:        domain.StatementEntryRefund,
+				Ref:         "wallet_order#" + strconv.FormatInt(order.ID, 10),
+				Description: description,
+				Status:      string(order.Status),
+				Amount:      order.Amount,
+			})
+			st.Totals.Refunds += order.Amount
+		}
+		if len(orders) == 0 || offset+len(orders) >= total {
+			return nil
+		}
+	}
+}
+
+// countsAsCharge reports whether an order's amount was actually owed.
+func countsAsCharge(status domain.OrderStatus) bool {
+	switch status {
+	case domain.OrderStatusDraft, domain.OrderStatusPendingPayment, domain.OrderStatusCanceled, domain.OrderStatusRejected, domain.OrderStatusFailed:
+		return false
+	}
+	return true
+}
+
+func walletRef(tx domain.WalletTransaction) string {
+	if tx.RefType == "" {
+		return ""
+	}
+	return tx.RefType + "#" + strconv.FormatInt(tx.RefID, 10)
+}
+
+func walletDescription(tx domain.WalletTransaction) string {
+	label := tx.Type
+	switch tx.Type {
+	case "credit":
+		label = "钱包入账"
+	case "debit":
+		label = "钱包扣款"
+	}
+	if tx.Note != "" {
+		label += " " + tx.Note
+	}
+	return label
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
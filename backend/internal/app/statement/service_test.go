@@ -0,0 +1,115 @@
+package statement_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	appstatement "xiaoheiplay/internal/app/statement"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+func TestStatement_BuildAndExport(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "stmt_user", "stmt_user@example.com", "pass")
+
+	if err := repo.UpsertWallet(ctx, &domain.Wallet{UserID: user.ID, Balance: 500}); err != nil {
+		t.Fatalf("seed wallet: %v", err)
+	}
+	order := domain.Order{UserID: user.ID, OrderNo: "ORD-STMT-1", Status: domain.OrderStatusApproved, TotalAmount: 3000, Currency: "CNY", CouponCode: "SAVE5", CouponDiscount: 500}
+	if err := repo.CreateOrder(ctx, &order); err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	if err := repo.CreatePayment(ctx, &domain.OrderPayment{OrderID: order.ID, UserID: user.ID, Method: "balance", Amount: 3000, Currency: "CNY", Status: domain.PaymentStatusApproved}); err != nil {
+		t.Fatalf("create payment: %v", err)
+	}
+	if _, err := repo.AdjustWalletBalance(ctx, user.ID, 5000, "credit", "wallet_order", 1, "recharge"); err != nil {
+		t.Fatalf("credit wallet: %v", err)
+	}
+	if _, err := repo.AdjustWalletBalance(ctx, user.ID, -3000, "debit", "order", order.ID, "balance payment"); err != nil {
+		t.Fatalf("debit wallet: %v", err)
+	}
+	refund := domain.WalletOrder{UserID: user.ID, Type: domain.WalletOrderRefund, Amount: 800, Currency: "CNY", Status: domain.WalletOrderApproved}
+	if err := repo.CreateWalletOrder(ctx, &refund); err != nil {
+		t.Fatalf("create refund: %v", err)
+	}
+
+	svc := appstatement.NewService(repo, repo, repo, repo, repo, repo, repo)
+	now := time.Now()
+	if _, err := svc.Build(ctx, user.ID, now, now.Add(-time.Hour)); !errors.Is(err, domain.ErrInvalidStatementRange) {
+		t.Fatalf("expected invalid range, got %v", err)
+	}
+	st, err := svc.Build(ctx, user.ID, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if st.OpeningBalance != 500 || st.ClosingBalance != 2500 {
+		t.Fatalf("unexpected balances: opening=%d closing=%d", st.OpeningBalance, st.ClosingBalance)
+	}
+	want := domain.StatementTotals{Orders: 3000, Paid: 3000, Refunds: 800, CouponDiscount: 500, WalletIn: 5000, WalletOut: 3000}
+	if st.Totals != want {
+		t.Fatalf("unexpected totals: %+v", st.Totals)
+	}
+	kinds := map[domain.StatementEntryKind]int{}
+	for i, e := range st.Entries {
+		kinds[e.Kind]++
+		if i > 0 && e.Time.Before(st.Entries[i-1].Time) {
+			t.Fatalf("entries not chronological at %d", i)
+		}
+	}
+	if kinds[domain.StatementEntryOrder] != 1 || kinds[domain.StatementEntryCoupon] != 1 || kinds[domain.StatementEntryPayment] != 1 || kinds[domain.StatementEntryWallet] != 2 || kinds[domain.StatementEntryRefund] != 1 {
+		t.Fatalf("unexpected entries: %+v", st.Entries)
+	}
+
+	before, err := svc.Build(ctx, user.ID, now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("build earlier: %v", err)
+	}
+	if before.OpeningBalance != 500 || before.ClosingBalance != 500 || len(before.Entries) != 0 {
+		t.Fatalf("unexpected earlier statement: %+v", before)
+	}
+
+	file, err := svc.Export(ctx, user.ID, now.Add(-time.Hour), now.Add(time.Hour), domain.StatementFormatCSV)
+	if err != nil {
+		t.Fatalf("export csv: %v", err)
+	}
+	body := string(file.Content)
+	for _, want := range []string{"期初钱包余额（元）,5.00", "期末钱包余额（元）,25.00", "ORD-STMT-1", "+50.00"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("csv missing %q:\n%s", want, body)
+		}
+	}
+	file, err = svc.AdminExport(ctx, 1, user.ID, now.Add(-time.Hour), now.Add(time.Hour), domain.StatementFormatPDF)
+	if err != nil {
+		t.Fatalf("export pdf: %v", err)
+	}
+	if file.ContentType != "application/pdf" || !strings.HasPrefix(string(file.Content), "%PDF-") || !strings.HasSuffix(file.Name, ".pdf") {
+		t.Fatalf("unexpected pdf file: %s %s", file.Name, file.ContentType)
+	}
+}
+
+func TestStatement_CSVEscapesFormulas(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "=HYPERLINK(1)", "@evil@example.com", "pass")
+	if _, err := repo.AdjustWalletBalance(ctx, user.ID, 1000, "-2+3", "", 0, "bonus"); err != nil {
+		t.Fatalf("credit wallet: %v", err)
+	}
+
+	svc := appstatement.NewService(repo, repo, repo, repo, repo, repo, repo)
+	now := time.Now()
+	file, err := svc.Export(ctx, user.ID, now.Add(-time.Hour), now.Add(time.Hour), domain.StatementFormatCSV)
+	if err != nil {
+		t.Fatalf("export csv: %v", err)
+	}
+	body := string(file.Content)
+	for _, want := range []string{"用户,'=HYPERLINK(1) (ID ", "邮箱,'@evil@example.com", ",'-2+3 bonus,", "+10.00"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("csv missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package trial
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	"xiaoheiplay/internal/domain"
+)
+
+const (
+	defaultIPLimit      = 1
+	defaultIPWindowDays = 30
+	defaultDeviceLimit  = 1
+	maxDeviceIDLen      = 128
+)
+
+type orderCreator interface {
+	CreateTrialOrder(ctx context.Context, userID int64, pkg domain.Package, systemID int64) (domain.Order, domain.OrderItem, error)
+	ApproveOrder(ctx context.Context, adminID int64, orderID int64) error
+}
+
+type StartInput struct {
+	PackageID int64
+	SystemID  int64
+	ClientIP  string
+	DeviceID  string
+}
+
+type PackageStat struct {
+	PackageID      int64
+	PackageName    string
+	Total          int
+	Active         int
+	Converted      int
+	Expired        int
+	ConversionRate float64
+}
+
+type Stats struct {
+	Total          int
+	Active         int
+	Converted      int
+	Expired        int
+	ConversionRate float64
+	Packages       []PackageStat
+}
+
+// Service hands out free trials of packages with TrialDays > 0. A trial is
+// a zero-priced order whose instance expires after the trial days; paying
+// for a renewal converts it, otherwise the lifecycle cleanup destroys it.
+type Service struct {
+	repo     appports.VPSTrialRepository
+	catalog  appports.CatalogRepository
+	users    appports.UserRepository
+	realname appports.RealNameRepository
+	settings appports.SettingsRepository
+	orders   orderCreator
+	mu       sync.Mutex
+}
+
+func NewService(repo appports.VPSTrialRepository, catalog appports.CatalogRepository, users appports.UserRepository, realname appports.RealNameRepository, settings appports.SettingsRepository, orders orderCreator) *Service {
+	return &Service{repo: repo, catalog: catalog, users: users, realname: realname, settings: settings, orders: orders}
+}
+
+// Start checks eligibility and abuse limits, then creates and approves the
+// trial order. Each verified identity, phone number and account gets one
+// trial; devices and client IPs are limited by settings.
+func (s *Service) Start(ctx context.Context, userID int64, in StartInput) (domain.VPSTrial, error) {
+	if s.repo == nil || s.orders == nil {
+		return domain.VPSTrial{}, appshared.ErrNotSupported
+	}
+	if enabled, ok := getSettingBool(ctx, s.settings, "trial_enabled"); ok && !enabled {
+		return domain.VPSTrial{}, domain.ErrTrialNotAvailable
+	}
+	if in.PackageID <= 0 || in.SystemID <= 0 || len(in.DeviceID) > maxDeviceIDLen {
+		return domain.VPSTrial{}, appshared.ErrInvalidInput
+	}
+	pkg, err := s.catalog.GetPackage(ctx, in.PackageID)
+	if err != nil {
+		return domain.VPSTrial{}, err
+	}
+	if pkg.TrialDays <= 0 || !pkg.Active {
+		return domain.VPSTrial{}, domain.ErrTrialNotAvailable
+	}
+	identity, phone, err := s.identity(ctx, userID)
+	if err != nil {
+		return domain.VPSTrial{}, err
+	}
+	trial := domain.VPSTrial{
+		UserID:       userID,
+		PackageID:    pkg.ID,
+		Days:         pkg.TrialDays,
+		IdentityHash: identity,
+		Phone:        phone,
+		ClientIP:     strings.TrimSpace(in.ClientIP),
+		DeviceID:     strings.TrimSpace(in.DeviceID),
+		Status:       domain.VPSTrialStatusActive,
+	}
+
+	// Serialize the limit check and the insert so parallel requests from one
+	// identity cannot both pass.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkLimits(ctx, trial); err != nil {
+		return domain.VPSTrial{}, err
+	}
+	order, item, err := s.orders.CreateTrialOrder(ctx, userID, pkg, in.SystemID)
+	if err != nil {
+		return domain.VPSTrial{}, err
+	}
+	trial.OrderID = order.ID
+	trial.OrderItemID = item.ID
+	trial.ExpireAt = time.Now().Add(time.Duration(pkg.TrialDays) * 24 * time.Hour)
+	if err := s.repo.CreateVPSTrial(ctx, &trial); err != nil {
+		return domain.VPSTrial{}, err
+	}
+	if err := s.orders.ApproveOrder(ctx, 0, order.ID); err != nil {
+		return domain.VPSTrial{}, err
+	}
+	return trial, nil
+}
+
+// identity returns the hashed ID number of the user's verified real-name
+// record and the bound phone number; both are required for a trial.
+func (s *Service) identity(ctx context.Context, userID int64) (string, string, error) {
+	if s.realname == nil || s.users == nil {
+		return "", "", domain.ErrTrialVerificationRequired
+	}
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+	phone := strings.TrimSpace(user.Phone)
+	if phone == "" {
+		return "", "", domain.ErrTrialVerificationRequired
+	}
+	latest, err := s.realname.GetLatestRealNameVerification(ctx, userID)
+	if err != nil {
+		if errors.Is(err, appshared.ErrNotFound) {
+			return "", "", domain.ErrTrialVerificationRequired
+		}
+		return "", "", err
+	}
+	idNumber := strings.ToUpper(strings.TrimSpace(latest.IDNumber))
+	if latest.Status != "verified" || idNumber == "" {
+		return "", "", domain.ErrTrialVerificationRequired
+	}
+	sum := sha256.Sum256([]byte(idNumber))
+	return hex.EncodeToString(sum[:]), phone, nil
+}
+
+func (s *Service) checkLimits(ctx context.Context, trial domain.VPSTrial) error {
+	checks := []appshared.VPSTrialFilter{
+		{UserID: trial.UserID},
+		{IdentityHash: trial.IdentityHash},
+		{Phone: trial.Phone},
+	}
+	for _, filter := range checks {
+		if err := s.checkLimit(ctx, filter, 1); err != nil {
+			return err
+		}
+	}
+	if trial.DeviceID != "" {
+		limit := defaultDeviceLimit
+		if v, ok := getSettingInt(ctx, s.settings, "trial_device_limit"); ok {
+			limit = v
+		}
+		if err := s.checkLimit(ctx, appshared.VPSTrialFilter{DeviceID: trial.DeviceID}, limit); err != nil {
+			return err
+		}
+	}
+	if trial.ClientIP != "" {
+		limit := defaultIPLimit
+		if v, ok := getSettingInt(ctx, s.settings, "trial_ip_limit"); ok {
+			limit = v
+		}
+		days := defaultIPWindowDays
+		if v, ok := getSettingInt(ctx, s.settings, "trial_ip_window_days"); ok && v > 0 {
+			days = v
+		}
+		since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+		if err := s.checkLimit(ctx, appshared.VPSTrialFilter{ClientIP: trial.ClientIP, Since: &since}, limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkLimit fails when filter already matches limit trials; a limit of 0 or
+// less disables the check.
+func (s *Service) checkLimit(ctx context.Context, filter appshared.VPSTrialFilter, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+	count, err := s.repo.CountVPSTrials(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if count >= limit {
+		return domain.ErrTrialLimitReached
+	}
+	return nil
+}
+
+// TrialForOrderItem returns the trial provisioned by orderItemID.
+func (s *Service) TrialForOrderItem(ctx context.Context, orderItemID int64) (domain.VPSTrial, bool) {
+	if s.repo == nil || orderItemID <= 0 {
+		return domain.VPSTrial{}, false
+	}
+	trial, err := s.repo.GetVPSTrialByOrderItem(ctx, orderItemID)
+	if err != nil {
+		return domain.VPSTrial{}, false
+	}
+	return trial, true
+}
+
+// MarkConverted records that the trial instance of orderItemID was renewed.
+func (s *Service) MarkConverted(ctx context.Context, orderItemID int64) error {
+	trial, ok := s.TrialForOrderItem(ctx, orderItemID)
+	if !ok || trial.Status != domain.VPSTrialStatusActive {
+		return nil
+	}
+	now := time.Now()
+	trial.Status = domain.VPSTrialStatusConverted
+	trial.ConvertedAt = &now
+	return s.repo.UpdateVPSTrial(ctx, trial)
+}
+
+// DueTrials lists active trials past their expiry.
+func (s *Service) DueTrials(ctx context.Context, limit int) ([]domain.VPSTrial, error) {
+	if s.repo == nil {
+		return nil, nil
+	}
+	return s.repo.ListDueVPSTrials(ctx, time.Now(), limit)
+}
+
+// MarkExpired closes a trial whose instance was destroyed.
+func (s *Service) MarkExpired(ctx context.Context, trial domain.VPSTrial) error {
+	if trial.Status != domain.VPSTrialStatusActive {
+		return nil
+	}
+	trial.Status = domain.VPSTrialStatusExpired
+	return s.repo.UpdateVPSTrial(ctx, trial)
+}
+
+func (s *Service) List(ctx context.Context, filter appshared.VPSTrialFilter, limit, offset int) ([]domain.VPSTrial, int, error) {
+	return s.repo.ListVPSTrials(ctx, filter, limit, offset)
+}
+
+// Stats reports trials started in [from, to). The conversion rate is the
+// share of converted trials among those that have ended, so trials still
+// running do not drag it down.
+func (s *Service) Stats(ctx context.Context, from, to time.Time) (Stats, error) {
+	if !to.After(from) {
+		return Stats{}, appshared.ErrInvalidInput
+	}
+	rows, err := s.repo.VPSTrialStats(ctx, from, to)
+	if err != nil {
+		return Stats{}, err
+	}
+	out := Stats{Packages: make([]PackageStat, 0, len(rows))}
+	for _, row := range rows {
+		item := PackageStat{
+			PackageID:      row.PackageID,
+			Total:          row.Total,
+			Active:         row.Active,
+			Converted:      row.Converted,
+			Expired:        row.Expired,
+			ConversionRate: conversionRate(row.Converted, row.Expired),
+		}
+		if s.catalog != nil {
+			if pkg, err := s.catalog.GetPackage(ctx, row.PackageID); err == nil {
+				item.PackageName = pkg.Name
+			}
+		}
+		out.Packages = append(out.Packages, item)
+		out.Total += row.Total
+		out.Active += row.Active
+		out.Converted += row.Converted
+		out.Expired += row.Expired
+	}
+	out.ConversionRate = conversionRate(out.Converted, out.Expired)
+	return out, nil
+}
+
+func conversionRate(converted, expired int) float64 {
+	if converted+expired == 0 {
+		return 0
+	}
+	return float64(converted) / float64(converted+expired)
+}
+
+func getSettingInt(ctx context.Context, repo appports.SettingsRepository, key string) (int, bool) {
+	if repo == nil {
+		return 0, false
+	}
+	setting, err := repo.GetSetting(ctx, key)
+	if err != nil {
+		return 0, false
+	}
+	val, err := strconv.Atoi(strings.TrimSpace(setting.ValueJSON))
+	if err != nil {
+		return 0, false
+	}
+	return val, true
+}
+
+func getSettingBool(ctx context.Context, repo appports.SettingsRepository, key string) (bool, bool) {
+	if repo == nil {
+		return false, false
+	}
+	setting, err := repo.GetSetting(ctx, key)
+	if err != nil {
+		return false, false
+	}
+	switch strings.ToLower(strings.TrimSpace(setting.ValueJSON)) {
+	case "true", "1", "yes":
+		return true, true
+	case "false", "0", "no":
+		return false, true
+	}
+	return false, false
+}
@@ -0,0 +1,187 @@
+package trial_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"xiaoheiplay/internal/adapter/repo/core"
+	appshared "xiaoheiplay/internal/app/shared"
+	apptrial "xiaoheiplay/internal/app/trial"
+	appvps "xiaoheiplay/internal/app/vps"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+type fakeTrialOrders struct {
+	nextID   int64
+	approved []int64
+}
+
+func (f *fakeTrialOrders) CreateTrialOrder(ctx context.Context, userID int64, pkg domain.Package, systemID int64) (domain.Order, domain.OrderItem, error) {
+	f.nextID++
+	return domain.Order{ID: f.nextID, UserID: userID}, domain.OrderItem{ID: f.nextID * 10, OrderID: f.nextID, PackageID: pkg.ID}, nil
+}
+
+func (f *fakeTrialOrders) ApproveOrder(ctx context.Context, adminID int64, orderID int64) error {
+	f.approved = append(f.approved, orderID)
+	return nil
+}
+
+func createVerifiedUser(t *testing.T, gormRepo *repo.GormRepo, name, phone, idNumber string) domain.User {
+	t.Helper()
+	ctx := context.Background()
+	user := testutil.CreateUser(t, gormRepo, name, name+"@example.com", "pass")
+	user.Phone = phone
+	if err := gormRepo.UpdateUser(ctx, user); err != nil {
+		t.Fatalf("update user: %v", err)
+	}
+	now := time.Now()
+	if err := gormRepo.CreateRealNameVerification(ctx, &domain.RealNameVerification{
+		UserID:     user.ID,
+		RealName:   "张三",
+		IDNumber:   idNumber,
+		Status:     "verified",
+		Provider:   "fake",
+		VerifiedAt: &now,
+	}); err != nil {
+		t.Fatalf("create realname: %v", err)
+	}
+	return user
+}
+
+func TestTrial_StartEligibilityAndLimits(t *testing.T) {
+	_, gormRepo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	seed := testutil.SeedCatalog(t, gormRepo)
+	orders := &fakeTrialOrders{}
+	svc := apptrial.NewService(gormRepo, gormRepo, gormRepo, gormRepo, gormRepo, orders)
+	in := apptrial.StartInput{PackageID: seed.Package.ID, SystemID: seed.SystemImage.ID, ClientIP: "198.51.100.1", DeviceID: "dev-1"}
+
+	alice := createVerifiedUser(t, gormRepo, "alice", "13800000001", "11010119900101001x")
+	if _, err := svc.Start(ctx, alice.ID, in); !errors.Is(err, domain.ErrTrialNotAvailable) {
+		t.Fatalf("expected trial not available, got %v", err)
+	}
+	pkg := seed.Package
+	pkg.TrialDays = 3
+	if err := gormRepo.UpdatePackage(ctx, pkg); err != nil {
+		t.Fatalf("update package: %v", err)
+	}
+
+	unverified := testutil.CreateUser(t, gormRepo, "bob", "bob@example.com", "pass")
+	if _, err := svc.Start(ctx, unverified.ID, in); !errors.Is(err, domain.ErrTrialVerificationRequired) {
+		t.Fatalf("expected verification required, got %v", err)
+	}
+
+	trial, err := svc.Start(ctx, alice.ID, in)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if trial.Days != 3 || trial.Status != domain.VPSTrialStatusActive || trial.OrderItemID != 10 || len(orders.approved) != 1 {
+		t.Fatalf("unexpected trial: %+v approved=%v", trial, orders.approved)
+	}
+	if d := time.Until(trial.ExpireAt); d < 71*time.Hour || d > 72*time.Hour {
+		t.Fatalf("unexpected expiry: %v", trial.ExpireAt)
+	}
+	if _, err := svc.Start(ctx, alice.ID, apptrial.StartInput{PackageID: pkg.ID, SystemID: seed.SystemImage.ID}); !errors.Is(err, domain.ErrTrialLimitReached) {
+		t.Fatalf("expected user limit, got %v", err)
+	}
+
+	// Same ID number on another account, differing only in letter case.
+	carol := createVerifiedUser(t, gormRepo, "carol", "13800000002", "11010119900101001X")
+	if _, err := svc.Start(ctx, carol.ID, apptrial.StartInput{PackageID: pkg.ID, SystemID: seed.SystemImage.ID}); !errors.Is(err, domain.ErrTrialLimitReached) {
+		t.Fatalf("expected identity limit, got %v", err)
+	}
+	dave := createVerifiedUser(t, gormRepo, "dave", "13800000003", "110101199001010020")
+	if _, err := svc.Start(ctx, dave.ID, apptrial.StartInput{PackageID: pkg.ID, SystemID: seed.SystemImage.ID, DeviceID: "dev-1"}); !errors.Is(err, domain.ErrTrialLimitReached) {
+		t.Fatalf("expected device limit, got %v", err)
+	}
+	if _, err := svc.Start(ctx, dave.ID, apptrial.StartInput{PackageID: pkg.ID, SystemID: seed.SystemImage.ID, ClientIP: "198.51.100.1"}); !errors.Is(err, domain.ErrTrialLimitReached) {
+		t.Fatalf("expected ip limit, got %v", err)
+	}
+	if err := gormRepo.UpsertSetting(ctx, domain.Setting{Key: "trial_ip_limit", ValueJSON: "2"}); err != nil {
+		t.Fatalf("set ip limit: %v", err)
+	}
+	if _, err := svc.Start(ctx, dave.ID, apptrial.StartInput{PackageID: pkg.ID, SystemID: seed.SystemImage.ID, ClientIP: "198.51.100.1"}); err != nil {
+		t.Fatalf("expected raised ip limit to pass, got %v", err)
+	}
+}
+
+func TestTrial_ConversionTeardownAndStats(t *testing.T) {
+	_, gormRepo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	seed := testutil.SeedCatalog(t, gormRepo)
+	pkg := seed.Package
+	pkg.TrialDays = 3
+	if err := gormRepo.UpdatePackage(ctx, pkg); err != nil {
+		t.Fatalf("update package: %v", err)
+	}
+	svc := apptrial.NewService(gormRepo, gormRepo, gormRepo, gormRepo, gormRepo, &fakeTrialOrders{})
+	client := &testutil.FakeAutomationClient{}
+	vpsSvc := appvps.NewService(gormRepo, &testutil.FakeAutomationResolver{Client: client}, gormRepo)
+	vpsSvc.SetTrialService(svc)
+
+	start := func(name, phone, id string) domain.VPSTrial {
+		user := createVerifiedUser(t, gormRepo, name, phone, id)
+		trial, err := svc.Start(ctx, user.ID, apptrial.StartInput{PackageID: pkg.ID, SystemID: seed.SystemImage.ID})
+		if err != nil {
+			t.Fatalf("start %s: %v", name, err)
+		}
+		past := time.Now().Add(-time.Hour)
+		inst := domain.VPSInstance{
+			UserID:               user.ID,
+			OrderItemID:          trial.OrderItemID,
+			AutomationInstanceID: "42",
+			GoodsTypeID:          pkg.GoodsTypeID,
+			Name:                 name,
+			PackageID:            pkg.ID,
+			Status:               domain.VPSStatusRunning,
+			SpecJSON:             "{}",
+			ExpireAt:             &past,
+		}
+		if err := gormRepo.CreateInstance(ctx, &inst); err != nil {
+			t.Fatalf("create vps: %v", err)
+		}
+		trial.ExpireAt = past
+		if err := gormRepo.UpdateVPSTrial(ctx, trial); err != nil {
+			t.Fatalf("expire trial: %v", err)
+		}
+		return trial
+	}
+	converted := start("erin", "13800000011", "110101199001010031")
+	expired := start("frank", "13800000012", "110101199001010047")
+
+	if err := svc.MarkConverted(ctx, converted.OrderItemID); err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	// Auto delete is off, trials are torn down anyway.
+	if err := vpsSvc.AutoDeleteExpired(ctx); err != nil {
+		t.Fatalf("auto delete: %v", err)
+	}
+	if len(client.DeleteCalls) != 1 {
+		t.Fatalf("expected only the unconverted trial deleted, got %v", client.DeleteCalls)
+	}
+	if _, err := gormRepo.GetInstanceByOrderItem(ctx, expired.OrderItemID); !errors.Is(err, appshared.ErrNotFound) {
+		t.Fatalf("expected trial instance removed, got %v", err)
+	}
+	if _, err := gormRepo.GetInstanceByOrderItem(ctx, converted.OrderItemID); err != nil {
+		t.Fatalf("expected converted instance kept: %v", err)
+	}
+	trial, ok := svc.TrialForOrderItem(ctx, expired.OrderItemID)
+	if !ok || trial.Status != domain.VPSTrialStatusExpired {
+		t.Fatalf("expected expired trial, got %+v", trial)
+	}
+
+	start("grace", "13800000013", "110101199001010055")
+	stats, err := svc.Stats(ctx, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if stats.Total != 3 || stats.Converted != 1 || stats.Expired != 1 || stats.Active != 1 || stats.ConversionRate != 0.5 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if len(stats.Packages) != 1 || stats.Packages[0].PackageName != pkg.Name {
+		t.Fatalf("unexpected package stats: %+v", stats.Packages)
+	}
+}
@@ -492,22 +492,10 @@ func (s *Service) ResolvePackagePricing(ctx context.Context, userID, packageID i
 	if userID <= 0 || packageID <= 0 {
 		return domain.UserTierPriceCache{}, 0, appshared.ErrInvalidInput
 	}
-	_ = s.TryAutoApproveForUser(ctx, userID, "pricing")
-	user, err := s.users.GetUserByID(ctx, userID)
+	groupID, err := s.pricingGroupID(ctx, userID)
 	if err != nil {
 		return domain.UserTierPriceCache{}, 0, err
 	}
-	groupID := int64(0)
-	if user.UserTierGroupID != nil {
-		groupID = *user.UserTierGroupID
-	}
-	if groupID <= 0 {
-		def, derr := s.EnsureDefaultGroup(ctx)
-		if derr != nil {
-			return domain.UserTierPriceCache{}, 0, derr
-		}
-		groupID = def.ID
-	}
 	cache, err := s.repo.GetUserTierPriceCache(ctx, groupID, packageID)
 	if err == nil {
 		return cache, groupID, nil
@@ -533,6 +521,56 @@ func (s *Service) ResolvePackagePricing(ctx context.Context, userID, packageID i
 	}, groupID, nil
 }
 
+// ResolveAddonPrice returns the monthly price of one unit of an addon SKU for
+// the user's tier group. An addon-scoped rule wins over the group's
+// all_addons discount.
+func (s *Service) ResolveAddonPrice(ctx context.Context, userID int64, addon domain.Addon) (int64, error) {
+	if userID <= 0 || addon.ID <= 0 {
+		return 0, appshared.ErrInvalidInput
+	}
+	groupID, err := s.pricingGroupID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	rules, err := s.repo.ListUserTierDiscountRules(ctx, groupID)
+	if err != nil {
+		return 0, err
+	}
+	var fallback *domain.UserTierDiscountRule
+	for i := range rules {
+		r := &rules[i]
+		switch {
+		case r.Scope == domain.UserTierScopeAddon && r.AddonID == addon.ID:
+			if r.FixedPrice != nil {
+				return *r.FixedPrice, nil
+			}
+			return applyDiscount(addon.Monthly, r.DiscountPermille), nil
+		case r.Scope == domain.UserTierScopeAllAddons && fallback == nil:
+			fallback = r
+		}
+	}
+	if fallback != nil {
+		return applyDiscount(addon.Monthly, fallback.DiscountPermille), nil
+	}
+	return addon.Monthly, nil
+}
+
+func (s *Service) pricingGroupID(ctx context.Context, userID int64) (int64, error) {
+	_ = s.TryAutoApproveForUser(ctx, userID, "pricing")
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if user.UserTierGroupID != nil && *user.UserTierGroupID > 0 {
+		return *user.UserTierGroupID, nil
+	}
+	def, err := s.EnsureDefaultGroup(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return def.ID, nil
+}
+
 func (s *Service) rebuildGroupPriceCache(groupID int64) {
 	lock := s.getRebuildLock(groupID)
 	lock.Lock()
@@ -623,6 +661,9 @@ func (s *Service) validateDiscountRule(ctx context.Context, rule domain.UserTier
 	if rule.FixedPrice != nil && *rule.FixedPrice < 0 {
 		return appshared.ErrInvalidInput
 	}
+	if rule.Scope == domain.UserTierScopeAddon && rule.AddonID <= 0 {
+		return appshared.ErrInvalidInput
+	}
 	rules, err := s.repo.ListUserTierDiscountRules(ctx, rule.GroupID)
 	if err != nil {
 		return err
@@ -724,7 +765,8 @@ func sameRuleScope(a, b domain.UserTierDiscountRule) bool {
 		a.GoodsTypeID == b.GoodsTypeID &&
 		a.RegionID == b.RegionID &&
 		a.PlanGroupID == b.PlanGroupID &&
-		a.PackageID == b.PackageID
+		a.PackageID == b.PackageID &&
+		a.AddonID == b.AddonID
 }
 
 func applyDiscount(v int64, permille int) int64 {
@@ -739,6 +781,8 @@ func applyDiscount(v int64, permille int) int64 {
 
 func ruleSpecificity(scope domain.UserTierScope) int {
 	switch scope {
+	case domain.UserTierScopeAddon:
+		return 70
 	case domain.UserTierScopePackage:
 		return 60
 	case domain.UserTierScopePlanGroup, domain.UserTierScopeAddonConfig:
@@ -3,6 +3,7 @@ package vps
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -28,12 +29,73 @@ type Service struct {
 	vps        appports.VPSRepository
 	automation appports.AutomationClientResolver
 	settings   appports.SettingsRepository
+	sshKeys    sshKeyResolver
+	quotas     addonQuotaResolver
+	ips        ipAddressSyncer
+	trials     trialExpirer
+	dunning    dunningScope
+}
+
+type sshKeyResolver interface {
+	PublicKeys(ctx context.Context, userID int64, ids []int64) ([]string, error)
 }
 
 func NewService(vps appports.VPSRepository, automation appports.AutomationClientResolver, settings appports.SettingsRepository) *Service {
 	return &Service{vps: vps, automation: automation, settings: settings}
 }
 
+func (s *Service) SetSSHKeyService(keys sshKeyResolver) {
+	s.sshKeys = keys
+}
+
+type addonQuotaResolver interface {
+	Quota(ctx context.Context, inst domain.VPSInstance, kind domain.AddonKind) (int, bool, error)
+}
+
+func (s *Service) SetAddonService(quotas addonQuotaResolver) {
+	s.quotas = quotas
+}
+
+type ipAddressSyncer interface {
+	SyncInstance(ctx context.Context, inst domain.VPSInstance, info AutomationHostInfo) error
+}
+
+func (s *Service) SetIPAddressService(ips ipAddressSyncer) {
+	s.ips = ips
+}
+
+type trialExpirer interface {
+	DueTrials(ctx context.Context, limit int) ([]domain.VPSTrial, error)
+	MarkExpired(ctx context.Context, trial domain.VPSTrial) error
+}
+
+func (s *Service) SetTrialService(trials trialExpirer) {
+	s.trials = trials
+}
+
+// dunningScope reports goods types whose expiry handling is driven by a
+// dunning policy instead of the auto lock and delete settings.
+type dunningScope interface {
+	Manages(ctx context.Context, goodsTypeID int64) bool
+}
+
+func (s *Service) SetDunningService(dunning dunningScope) {
+	s.dunning = dunning
+}
+
+func (s *Service) managedByDunning(ctx context.Context, goodsTypeID int64) bool {
+	return s.dunning != nil && s.dunning.Manages(ctx, goodsTypeID)
+}
+
+// quotaLimit returns the snapshot or backup quota of inst; the bool is false
+// when the instance is unlimited.
+func (s *Service) quotaLimit(ctx context.Context, inst domain.VPSInstance, kind domain.AddonKind) (int, bool, error) {
+	if s.quotas == nil {
+		return 0, false, nil
+	}
+	return s.quotas.Quota(ctx, inst, kind)
+}
+
 func (s *Service) client(ctx context.Context, goodsTypeID int64) (AutomationClient, error) {
 	if s.automation == nil {
 		return nil, appshared.ErrInvalidInput
@@ -118,6 +180,9 @@ func (s *Service) RefreshStatus(ctx context.Context, inst domain.VPSInstance) (d
 			_ = s.vps.UpdateInstanceSpec(ctx, inst.ID, merged)
 		}
 	}
+	if s.ips != nil {
+		_ = s.ips.SyncInstance(ctx, inst, info)
+	}
 	return s.vps.GetInstance(ctx, inst.ID)
 }
 
@@ -212,7 +277,9 @@ func (s *Service) Reboot(ctx context.Context, inst domain.VPSInstance) error {
 	return cli.RebootHost(ctx, hostID)
 }
 
-func (s *Service) ResetOS(ctx context.Context, inst domain.VPSInstance, templateID int64, password string) error {
+// ResetOS reinstalls the instance. sshKeyIDs and userData are optional and
+// are passed to plugins that declare the ssh_keys / cloud_init features.
+func (s *Service) ResetOS(ctx context.Context, inst domain.VPSInstance, templateID int64, password string, sshKeyIDs []int64, userData string) error {
 	if s.automation == nil {
 		return appshared.ErrInvalidInput
 	}
@@ -239,11 +306,29 @@ func (s *Service) ResetOS(ctx context.Context, inst domain.VPSInstance, template
 		return appshared.ErrInvalidInput
 	}
 	password = validatedPassword
+	sshKeyIDs, err := appshared.NormalizeSSHKeyIDs(sshKeyIDs)
+	if err != nil {
+		return err
+	}
+	if len(userData) > appshared.MaxProvisionUserData {
+		return domain.ErrUserDataTooLarge
+	}
+	req := appshared.AutomationResetOSRequest{HostID: hostID, TemplateID: templateID, Password: password, UserData: userData}
+	if len(sshKeyIDs) > 0 {
+		if s.sshKeys == nil {
+			return domain.ErrSSHKeysNotSupported
+		}
+		keys, err := s.sshKeys.PublicKeys(ctx, inst.UserID, sshKeyIDs)
+		if err != nil {
+			return err
+		}
+		req.SSHKeys = keys
+	}
 	cli, err := s.client(ctx, inst.GoodsTypeID)
 	if err != nil {
 		return err
 	}
-	if err := cli.ResetOS(ctx, hostID, templateID, password); err != nil {
+	if err := cli.ResetOS(ctx, req); err != nil {
 		return err
 	}
 	_ = s.vps.UpdateInstanceStatus(ctx, inst.ID, domain.VPSStatusReinstalling, 4)
@@ -310,6 +395,19 @@ func (s *Service) CreateSnapshot(ctx context.Context, inst domain.VPSInstance) e
 	if err != nil {
 		return err
 	}
+	limit, limited, err := s.quotaLimit(ctx, inst, domain.AddonKindSnapshotQuota)
+	if err != nil {
+		return err
+	}
+	if limited {
+		existing, err := cli.ListSnapshots(ctx, hostID)
+		if err != nil {
+			return err
+		}
+		if len(existing) >= limit {
+			return domain.ErrSnapshotQuotaExceeded
+		}
+	}
 	return cli.CreateSnapshot(ctx, hostID)
 }
 
@@ -358,6 +456,19 @@ func (s *Service) CreateBackup(ctx context.Context, inst domain.VPSInstance) err
 	if err != nil {
 		return err
 	}
+	limit, limited, err := s.quotaLimit(ctx, inst, domain.AddonKindBackupPlan)
+	if err != nil {
+		return err
+	}
+	if limited {
+		existing, err := cli.ListBackups(ctx, hostID)
+		if err != nil {
+			return err
+		}
+		if len(existing) >= limit {
+			return domain.ErrBackupQuotaExceeded
+		}
+	}
 	return cli.CreateBackup(ctx, hostID)
 }
 
@@ -568,7 +679,13 @@ func (s *Service) EmergencyRenew(ctx context.Context, inst domain.VPSInstance) (
 }
 
 func (s *Service) AutoDeleteExpired(ctx context.Context) error {
-	if s.settings == nil || s.vps == nil || s.automation == nil {
+	if s.vps == nil || s.automation == nil {
+		return nil
+	}
+	if err := s.deleteExpiredTrials(ctx); err != nil {
+		return err
+	}
+	if s.settings == nil {
 		return nil
 	}
 	enabled, ok := getSettingBool(ctx, s.settings, "auto_delete_enabled")
@@ -591,18 +708,64 @@ func (s *Service) AutoDeleteExpired(ctx context.Context) error {
 		if inst.ExpireAt == nil || inst.ExpireAt.After(cutoff) {
 			continue
 		}
-		hostID := parseHostID(inst.AutomationInstanceID)
-		if hostID == 0 {
+		if s.managedByDunning(ctx, inst.GoodsTypeID) {
 			continue
 		}
-		cli, err := s.client(ctx, inst.GoodsTypeID)
-		if err != nil {
+		_ = s.TerminateExpired(ctx, inst)
+	}
+	return nil
+}
+
+// TerminateExpired deletes the host of an expired instance and the instance
+// record.
+func (s *Service) TerminateExpired(ctx context.Context, inst domain.VPSInstance) error {
+	hostID := parseHostID(inst.AutomationInstanceID)
+	if hostID == 0 {
+		return appshared.ErrInvalidInput
+	}
+	cli, err := s.client(ctx, inst.GoodsTypeID)
+	if err != nil {
+		return err
+	}
+	if err := cli.DeleteHost(ctx, hostID); err != nil {
+		return err
+	}
+	return s.vps.DeleteInstance(ctx, inst.ID)
+}
+
+// deleteExpiredTrials destroys unconverted trials past their hard expiry,
+// regardless of the auto delete settings.
+func (s *Service) deleteExpiredTrials(ctx context.Context) error {
+	if s.trials == nil {
+		return nil
+	}
+	due, err := s.trials.DueTrials(ctx, 200)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, trial := range due {
+		inst, err := s.vps.GetInstanceByOrderItem(ctx, trial.OrderItemID)
+		if err != nil && !errors.Is(err, appshared.ErrNotFound) {
 			continue
 		}
-		if err := cli.DeleteHost(ctx, hostID); err != nil {
-			continue
+		if err == nil {
+			// Extended by hand; wait for the new expiry.
+			if inst.ExpireAt != nil && inst.ExpireAt.After(now) {
+				continue
+			}
+			if hostID := parseHostID(inst.AutomationInstanceID); hostID > 0 {
+				cli, err := s.client(ctx, inst.GoodsTypeID)
+				if err != nil {
+					continue
+				}
+				if err := cli.DeleteHost(ctx, hostID); err != nil {
+					continue
+				}
+			}
+			_ = s.vps.DeleteInstance(ctx, inst.ID)
 		}
-		_ = s.vps.DeleteInstance(ctx, inst.ID)
+		_ = s.trials.MarkExpired(ctx, trial)
 	}
 	return nil
 }
@@ -626,19 +789,29 @@ func (s *Service) AutoLockExpired(ctx context.Context) error {
 		if inst.AdminStatus != "" && inst.AdminStatus != domain.VPSAdminStatusNormal {
 			continue
 		}
-		hostID := parseHostID(inst.AutomationInstanceID)
-		if hostID == 0 {
+		if s.managedByDunning(ctx, inst.GoodsTypeID) {
 			continue
 		}
-		cli, err := s.client(ctx, inst.GoodsTypeID)
-		if err != nil {
-			continue
-		}
-		if err := cli.LockHost(ctx, hostID); err != nil {
-			continue
-		}
-		_ = s.vps.UpdateInstanceStatus(ctx, inst.ID, domain.VPSStatusExpiredLocked, 10)
-		_ = s.vps.UpdateInstanceAdminStatus(ctx, inst.ID, domain.VPSAdminStatusLocked)
+		_ = s.SuspendExpired(ctx, inst)
+	}
+	return nil
+}
+
+// SuspendExpired locks the host of an expired instance; renewing it unlocks
+// the host again.
+func (s *Service) SuspendExpired(ctx context.Context, inst domain.VPSInstance) error {
+	hostID := parseHostID(inst.AutomationInstanceID)
+	if hostID == 0 {
+		return appshared.ErrInvalidInput
+	}
+	cli, err := s.client(ctx, inst.GoodsTypeID)
+	if err != nil {
+		return err
+	}
+	if err := cli.LockHost(ctx, hostID); err != nil {
+		return err
 	}
+	_ = s.vps.UpdateInstanceStatus(ctx, inst.ID, domain.VPSStatusExpiredLocked, 10)
+	_ = s.vps.UpdateInstanceAdminStatus(ctx, inst.ID, domain.VPSAdminStatusLocked)
 	return nil
 }
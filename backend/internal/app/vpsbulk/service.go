@@ -0,0 +1,324 @@
+package vpsbulk
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	appports "xiaoheiplay/internal/app/ports"
+	"xiaoheiplay/internal/domain"
+)
+
+const (
+	defaultConcurrency = 5
+	maxConcurrency     = 20
+	maxTargets         = 2000
+	maxExtendDays      = 3650
+	itemTimeout        = 2 * time.Minute
+)
+
+// operator is the single-instance admin API the bulk jobs fan out to, so each
+// item goes through the same checks and audit log as a manual action.
+type operator interface {
+	Get(ctx context.Context, vpsID int64) (domain.VPSInstance, error)
+	Reboot(ctx context.Context, adminID int64, vpsID int64) error
+	SetAdminStatus(ctx context.Context, adminID int64, vpsID int64, status domain.VPSAdminStatus, reason string) error
+	UpdateExpireAt(ctx context.Context, adminID int64, vpsID int64, expireAt time.Time) (domain.VPSInstance, error)
+}
+
+// Params carries the action specific arguments: Status for set_admin_status,
+// Days for extend_expire and an optional Reason for the admin status actions.
+type Params struct {
+	Status string `json:"status,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	Days   int    `json:"days,omitempty"`
+}
+
+type StartInput struct {
+	Action      domain.VPSBulkAction
+	Params      Params
+	Filter      domain.VPSBulkFilter
+	Concurrency int
+}
+
+// Service runs admin operations over many instances in the background with
+// bounded concurrency and keeps a per-instance result for every job.
+type Service struct {
+	repo    appports.VPSBulkJobRepository
+	ops     operator
+	mu      sync.Mutex
+	cancels map[int64]context.CancelFunc
+}
+
+func NewService(repo appports.VPSBulkJobRepository, ops operator) *Service {
+	return &Service{repo: repo, ops: ops, cancels: make(map[int64]context.CancelFunc)}
+}
+
+// Start validates the input, snapshots the matching instances into a new job
+// and starts running it. The returned job is already in the running state.
+func (s *Service) Start(ctx context.Context, adminID int64, in StartInput) (domain.VPSBulkJob, error) {
+	if err := validateParams(in.Action, in.Params); err != nil {
+		return domain.VPSBulkJob{}, err
+	}
+	filter := normalizeFilter(in.Filter)
+	if len(filter.IDs) == 0 && filter.LineID == 0 && filter.RegionID == 0 && filter.PackageID == 0 && filter.UserID == 0 {
+		return domain.VPSBulkJob{}, domain.ErrBulkFilterRequired
+	}
+	if len(filter.IDs) > maxTargets {
+		return domain.VPSBulkJob{}, domain.ErrTooManyBulkTargets
+	}
+	ids, err := s.repo.MatchVPSBulkTargets(ctx, filter, maxTargets+1)
+	if err != nil {
+		return domain.VPSBulkJob{}, err
+	}
+	if len(ids) == 0 {
+		return domain.VPSBulkJob{}, domain.ErrNoBulkTargets
+	}
+	if len(ids) > maxTargets {
+		return domain.VPSBulkJob{}, domain.ErrTooManyBulkTargets
+	}
+	concurrency := in.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	if concurrency > maxConcurrency {
+		concurrency = maxConcurrency
+	}
+	job := domain.VPSBulkJob{
+		AdminID:     adminID,
+		Action:      in.Action,
+		ParamsJSON:  mustJSON(in.Params),
+		FilterJSON:  mustJSON(filter),
+		Concurrency: concurrency,
+		Status:      domain.VPSBulkJobStatusRunning,
+	}
+	if err := s.repo.CreateVPSBulkJob(ctx, &job, ids); err != nil {
+		return domain.VPSBulkJob{}, err
+	}
+	items, err := s.repo.ListVPSBulkJobItems(ctx, job.ID, domain.VPSBulkItemStatusPending)
+	if err != nil {
+		return domain.VPSBulkJob{}, err
+	}
+	runCtx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.cancels[job.ID] = cancel
+	s.mu.Unlock()
+	go s.run(runCtx, job, in.Params, items)
+	return job, nil
+}
+
+func (s *Service) Get(ctx context.Context, id int64) (domain.VPSBulkJob, error) {
+	return s.repo.GetVPSBulkJob(ctx, id)
+}
+
+func (s *Service) List(ctx context.Context, limit, offset int) ([]domain.VPSBulkJob, int, error) {
+	return s.repo.ListVPSBulkJobs(ctx, limit, offset)
+}
+
+func (s *Service) Items(ctx context.Context, jobID int64, status domain.VPSBulkItemStatus) ([]domain.VPSBulkJobItem, error) {
+	return s.repo.ListVPSBulkJobItems(ctx, jobID, status)
+}
+
+// ItemsAfter returns the items finished after seq, in finishing order; it
+// backs the progress stream.
+func (s *Service) ItemsAfter(ctx context.Context, jobID, seq int64, limit int) ([]domain.VPSBulkJobItem, error) {
+	return s.repo.ListVPSBulkJobItemsAfter(ctx, jobID, seq, limit)
+}
+
+// Cancel stops a running job. Items already in flight finish; the pending
+// ones are marked canceled.
+func (s *Service) Cancel(ctx context.Context, id int64) (domain.VPSBulkJob, error) {
+	job, err := s.repo.GetVPSBulkJob(ctx, id)
+	if err != nil {
+		return domain.VPSBulkJob{}, err
+	}
+	if job.Status != domain.VPSBulkJobStatusRunning {
+		return job, domain.ErrBulkJobFinished
+	}
+	s.mu.Lock()
+	cancel, ok := s.cancels[id]
+	s.mu.Unlock()
+	if ok {
+		cancel()
+		return job, nil
+	}
+	if err := s.abandon(ctx, job); err != nil {
+		return job, err
+	}
+	return s.repo.GetVPSBulkJob(ctx, id)
+}
+
+// RecoverInterrupted cancels the jobs a previous process left running. It is
+// meant to be called once at startup.
+func (s *Service) RecoverInterrupted(ctx context.Context) error {
+	jobs, err := s.repo.ListRunningVPSBulkJobs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		s.mu.Lock()
+		_, running := s.cancels[job.ID]
+		s.mu.Unlock()
+		if running {
+			continue
+		}
+		if err := s.abandon(ctx, job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) abandon(ctx context.Context, job domain.VPSBulkJob) error {
+	now := time.Now()
+	n, err := s.repo.CancelPendingVPSBulkJobItems(ctx, job.ID, now)
+	if err != nil {
+		return err
+	}
+	job.Canceled += n
+	job.Status = domain.VPSBulkJobStatusCanceled
+	job.FinishedAt = &now
+	return s.repo.UpdateVPSBulkJob(ctx, job)
+}
+
+func (s *Service) run(runCtx context.Context, job domain.VPSBulkJob, params Params, items []domain.VPSBulkJobItem) {
+	defer func() {
+		s.mu.Lock()
+		if cancel, ok := s.cancels[job.ID]; ok {
+			cancel()
+			delete(s.cancels, job.ID)
+		}
+		s.mu.Unlock()
+	}()
+	// Results are persisted with a fresh context: a canceled job still
+	// records the items that were in flight.
+	store := context.Background()
+	sem := make(chan struct{}, job.Concurrency)
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		seq int64
+	)
+	for _, item := range items {
+		if runCtx.Err() != nil {
+			break
+		}
+		select {
+		case <-runCtx.Done():
+		case sem <- struct{}{}:
+		}
+		if runCtx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(item domain.VPSBulkJobItem) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			opCtx, cancel := context.WithTimeout(store, itemTimeout)
+			err := s.apply(opCtx, job, params, item.VPSID)
+			cancel()
+			now := time.Now()
+			mu.Lock()
+			defer mu.Unlock()
+			seq++
+			item.Seq = seq
+			item.FinishedAt = &now
+			if err != nil {
+				item.Status = domain.VPSBulkItemStatusFailed
+				item.Error = err.Error()
+				job.Failed++
+			} else {
+				item.Status = domain.VPSBulkItemStatusSuccess
+				job.Succeeded++
+			}
+			_ = s.repo.UpdateVPSBulkJobItem(store, item)
+			_ = s.repo.UpdateVPSBulkJob(store, job)
+		}(item)
+	}
+	wg.Wait()
+	now := time.Now()
+	job.Status = domain.VPSBulkJobStatusCompleted
+	if runCtx.Err() != nil {
+		n, _ := s.repo.CancelPendingVPSBulkJobItems(store, job.ID, now)
+		job.Canceled += n
+		job.Status = domain.VPSBulkJobStatusCanceled
+	}
+	job.FinishedAt = &now
+	_ = s.repo.UpdateVPSBulkJob(store, job)
+}
+
+func (s *Service) apply(ctx context.Context, job domain.VPSBulkJob, params Params, vpsID int64) error {
+	switch job.Action {
+	case domain.VPSBulkActionReboot:
+		return s.ops.Reboot(ctx, job.AdminID, vpsID)
+	case domain.VPSBulkActionLock:
+		return s.ops.SetAdminStatus(ctx, job.AdminID, vpsID, domain.VPSAdminStatusLocked, reasonOr(params.Reason, "bulk lock"))
+	case domain.VPSBulkActionUnlock:
+		return s.ops.SetAdminStatus(ctx, job.AdminID, vpsID, domain.VPSAdminStatusNormal, reasonOr(params.Reason, "bulk unlock"))
+	case domain.VPSBulkActionSetAdminStatus:
+		return s.ops.SetAdminStatus(ctx, job.AdminID, vpsID, domain.VPSAdminStatus(params.Status), params.Reason)
+	case domain.VPSBulkActionExtendExpire:
+		inst, err := s.ops.Get(ctx, vpsID)
+		if err != nil {
+			return err
+		}
+		base := time.Now()
+		if inst.ExpireAt != nil {
+			base = *inst.ExpireAt
+		}
+		_, err = s.ops.UpdateExpireAt(ctx, job.AdminID, vpsID, base.AddDate(0, 0, params.Days))
+		return err
+	}
+	return domain.ErrInvalidBulkAction
+}
+
+func validateParams(action domain.VPSBulkAction, params Params) error {
+	switch action {
+	case domain.VPSBulkActionReboot, domain.VPSBulkActionLock, domain.VPSBulkActionUnlock:
+		return nil
+	case domain.VPSBulkActionSetAdminStatus:
+		switch domain.VPSAdminStatus(params.Status) {
+		case domain.VPSAdminStatusNormal, domain.VPSAdminStatusAbuse, domain.VPSAdminStatusFraud, domain.VPSAdminStatusLocked:
+			return nil
+		}
+	case domain.VPSBulkActionExtendExpire:
+		if params.Days > 0 && params.Days <= maxExtendDays {
+			return nil
+		}
+	}
+	return domain.ErrInvalidBulkAction
+}
+
+func normalizeFilter(filter domain.VPSBulkFilter) domain.VPSBulkFilter {
+	if len(filter.IDs) == 0 {
+		return filter
+	}
+	seen := make(map[int64]struct{}, len(filter.IDs))
+	ids := make([]int64, 0, len(filter.IDs))
+	for _, id := range filter.IDs {
+		if id <= 0 {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	return domain.VPSBulkFilter{IDs: ids}
+}
+
+func reasonOr(reason, fallback string) string {
+	if reason == "" {
+		return fallback
+	}
+	return reason
+}
+
+func mustJSON(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,78 @@
+package vpsbulk_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appadminvps "xiaoheiplay/internal/app/adminvps"
+	appvpsbulk "xiaoheiplay/internal/app/vpsbulk"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+func TestVPSBulk_RebootJobRecordsPerInstanceResults(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "bulk", "bulk@example.com", "pass")
+	other := testutil.CreateUser(t, repo, "other", "other@example.com", "pass")
+	for i, hostID := range []string{"11", "12", "bad"} {
+		inst := domain.VPSInstance{UserID: user.ID, AutomationInstanceID: hostID, Name: "vm" + hostID, Status: domain.VPSStatusRunning, SpecJSON: "{}"}
+		if err := repo.CreateInstance(ctx, &inst); err != nil {
+			t.Fatalf("create vps %d: %v", i, err)
+		}
+	}
+	untouched := domain.VPSInstance{UserID: other.ID, AutomationInstanceID: "99", Name: "other", Status: domain.VPSStatusRunning, SpecJSON: "{}"}
+	if err := repo.CreateInstance(ctx, &untouched); err != nil {
+		t.Fatalf("create other vps: %v", err)
+	}
+	client := &testutil.FakeAutomationClient{}
+	adminVPS := appadminvps.NewService(repo, &testutil.FakeAutomationResolver{Client: client}, repo, repo, repo, nil)
+	svc := appvpsbulk.NewService(repo, adminVPS)
+
+	if _, err := svc.Start(ctx, 1, appvpsbulk.StartInput{Action: domain.VPSBulkActionReboot}); !errors.Is(err, domain.ErrBulkFilterRequired) {
+		t.Fatalf("expected filter required, got %v", err)
+	}
+	if _, err := svc.Start(ctx, 1, appvpsbulk.StartInput{Action: "format", Filter: domain.VPSBulkFilter{UserID: user.ID}}); !errors.Is(err, domain.ErrInvalidBulkAction) {
+		t.Fatalf("expected invalid action, got %v", err)
+	}
+	if _, err := svc.Start(ctx, 1, appvpsbulk.StartInput{Action: domain.VPSBulkActionExtendExpire, Filter: domain.VPSBulkFilter{UserID: user.ID}}); !errors.Is(err, domain.ErrInvalidBulkAction) {
+		t.Fatalf("expected missing days to be rejected, got %v", err)
+	}
+	if _, err := svc.Start(ctx, 1, appvpsbulk.StartInput{Action: domain.VPSBulkActionReboot, Filter: domain.VPSBulkFilter{UserID: 9999}}); !errors.Is(err, domain.ErrNoBulkTargets) {
+		t.Fatalf("expected no targets, got %v", err)
+	}
+
+	job, err := svc.Start(ctx, 1, appvpsbulk.StartInput{Action: domain.VPSBulkActionReboot, Filter: domain.VPSBulkFilter{UserID: user.ID}, Concurrency: 2})
+	if err != nil {
+		t.Fatalf("start job: %v", err)
+	}
+	if job.Total != 3 || job.Status != domain.VPSBulkJobStatusRunning {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Status == domain.VPSBulkJobStatusRunning && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+		if job, err = svc.Get(ctx, job.ID); err != nil {
+			t.Fatalf("get job: %v", err)
+		}
+	}
+	if job.Status != domain.VPSBulkJobStatusCompleted || job.Succeeded != 2 || job.Failed != 1 || job.FinishedAt == nil {
+		t.Fatalf("unexpected finished job: %+v", job)
+	}
+	if len(client.RebootCalls) != 2 {
+		t.Fatalf("expected 2 reboots, got %v", client.RebootCalls)
+	}
+	failed, err := svc.Items(ctx, job.ID, domain.VPSBulkItemStatusFailed)
+	if err != nil || len(failed) != 1 || failed[0].Error == "" {
+		t.Fatalf("unexpected failed items: %+v err=%v", failed, err)
+	}
+	after, err := svc.ItemsAfter(ctx, job.ID, 1, 10)
+	if err != nil || len(after) != 2 || after[0].Seq != 2 {
+		t.Fatalf("unexpected items after seq 1: %+v err=%v", after, err)
+	}
+	if _, err := svc.Cancel(ctx, job.ID); !errors.Is(err, domain.ErrBulkJobFinished) {
+		t.Fatalf("expected finished job cancel to fail, got %v", err)
+	}
+}
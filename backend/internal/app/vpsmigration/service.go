@@ -0,0 +1,622 @@
+package vpsmigration
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appports "xiaoheiplay/internal/app/ports"
+	appshared "xiaoheiplay/internal/app/shared"
+	appvps "xiaoheiplay/internal/app/vps"
+	"xiaoheiplay/internal/domain"
+)
+
+const (
+	defaultMaxAttempts = 5
+	retryBackoff       = 2 * time.Minute
+	waitInterval       = time.Minute
+	// readyTimeout bounds how long after creation a migration may wait on the
+	// target host or a backup before waiting counts as a failed attempt.
+	readyTimeout = 6 * time.Hour
+)
+
+// errNotReady marks a step that is waiting on the upstream (target host still
+// provisioning, backup not listed yet) rather than failing.
+var errNotReady = domain.ErrProvisioning
+
+type catalogReader interface {
+	GetPackage(ctx context.Context, id int64) (domain.Package, error)
+	GetPlanGroup(ctx context.Context, id int64) (domain.PlanGroup, error)
+	GetRegion(ctx context.Context, id int64) (domain.Region, error)
+}
+
+type imageReader interface {
+	GetSystemImage(ctx context.Context, id int64) (domain.SystemImage, error)
+}
+
+type orderItemAutomation interface {
+	UpdateOrderItemAutomation(ctx context.Context, id int64, automationID string) error
+}
+
+type messageNotifier interface {
+	NotifyUser(ctx context.Context, userID int64, typ, title, content string) error
+}
+
+type StartInput struct {
+	VPSID           int64
+	TargetPackageID int64
+	TargetSystemID  int64
+	TransferData    bool
+}
+
+// Service moves instances between automation lines and plugins. Work happens
+// in steps driven by the vps_migration scheduled task; a failing step is
+// retried with backoff and the migration fails after MaxAttempts.
+type Service struct {
+	repo       appports.VPSMigrationRepository
+	vps        appports.VPSRepository
+	catalog    catalogReader
+	images     imageReader
+	automation appports.AutomationClientResolver
+	items      orderItemAutomation
+	autoLogs   appports.AutomationLogRepository
+	messages   messageNotifier
+	now        func() time.Time
+}
+
+func NewService(repo appports.VPSMigrationRepository, vps appports.VPSRepository, catalog catalogReader, images imageReader, automation appports.AutomationClientResolver, autoLogs appports.AutomationLogRepository) *Service {
+	return &Service{repo: repo, vps: vps, catalog: catalog, images: images, automation: automation, autoLogs: autoLogs, now: time.Now}
+}
+
+func (s *Service) SetOrderItemRepository(items orderItemAutomation) {
+	s.items = items
+}
+
+func (s *Service) SetMessageService(messages messageNotifier) {
+	s.messages = messages
+}
+
+// Start validates the target and queues a migration for the instance. The
+// first step runs on the next scheduled task tick.
+func (s *Service) Start(ctx context.Context, adminID int64, in StartInput) (domain.VPSMigration, error) {
+	inst, err := s.vps.GetInstance(ctx, in.VPSID)
+	if err != nil {
+		return domain.VPSMigration{}, err
+	}
+	if parseHostID(inst.AutomationInstanceID) == 0 {
+		return domain.VPSMigration{}, domain.ErrInvalidMigration
+	}
+	if _, err := s.repo.GetRunningVPSMigration(ctx, inst.ID); err == nil {
+		return domain.VPSMigration{}, domain.ErrMigrationInProgress
+	} else if !errors.Is(err, appshared.ErrNotFound) {
+		return domain.VPSMigration{}, err
+	}
+	pkg, err := s.catalog.GetPackage(ctx, in.TargetPackageID)
+	if err != nil {
+		return domain.VPSMigration{}, domain.ErrInvalidMigration
+	}
+	plan, err := s.catalog.GetPlanGroup(ctx, pkg.PlanGroupID)
+	if err != nil || pkg.GoodsTypeID <= 0 || plan.LineID <= 0 {
+		return domain.VPSMigration{}, domain.ErrInvalidMigration
+	}
+	if pkg.GoodsTypeID == inst.GoodsTypeID && plan.LineID == inst.LineID {
+		return domain.VPSMigration{}, domain.ErrInvalidMigration
+	}
+	if in.TransferData && pkg.GoodsTypeID != inst.GoodsTypeID {
+		return domain.VPSMigration{}, domain.ErrMigrationTransferUnsupported
+	}
+	systemID := in.TargetSystemID
+	if systemID <= 0 {
+		systemID = inst.SystemID
+	}
+	if _, err := s.images.GetSystemImage(ctx, systemID); err != nil {
+		return domain.VPSMigration{}, domain.ErrInvalidMigration
+	}
+	m := domain.VPSMigration{
+		VPSID:             inst.ID,
+		UserID:            inst.UserID,
+		AdminID:           adminID,
+		SourceGoodsTypeID: inst.GoodsTypeID,
+		SourceInstanceID:  inst.AutomationInstanceID,
+		SourceLineID:      inst.LineID,
+		SourcePackageID:   inst.PackageID,
+		TargetGoodsTypeID: pkg.GoodsTypeID,
+		TargetLineID:      plan.LineID,
+		TargetRegionID:    plan.RegionID,
+		TargetPackageID:   pkg.ID,
+		TargetSystemID:    systemID,
+		TransferData:      in.TransferData,
+		Status:            domain.VPSMigrationStatusRunning,
+		Step:              domain.VPSMigrationStepProvision,
+		MaxAttempts:       defaultMaxAttempts,
+		NextRunAt:         s.now(),
+	}
+	if err := s.repo.CreateVPSMigration(ctx, &m); err != nil {
+		return domain.VPSMigration{}, err
+	}
+	s.notify(ctx, m.UserID, "vps_migration_started", "VPS Migration Scheduled",
+		fmt.Sprintf("VPS %s is being migrated to a new host. It keeps running on the current host until the switch.", inst.Name))
+	return m, nil
+}
+
+func (s *Service) Get(ctx context.Context, id int64) (domain.VPSMigration, error) {
+	return s.repo.GetVPSMigration(ctx, id)
+}
+
+func (s *Service) List(ctx context.Context, vpsID int64, limit, offset int) ([]domain.VPSMigration, int, error) {
+	return s.repo.ListVPSMigrations(ctx, vpsID, limit, offset)
+}
+
+// Retry puts a failed migration back into the queue at the step it failed.
+func (s *Service) Retry(ctx context.Context, id int64) (domain.VPSMigration, error) {
+	m, err := s.repo.GetVPSMigration(ctx, id)
+	if err != nil {
+		return domain.VPSMigration{}, err
+	}
+	if m.Status != domain.VPSMigrationStatusFailed {
+		return domain.VPSMigration{}, domain.ErrMigrationStateConflict
+	}
+	if _, err := s.repo.GetRunningVPSMigration(ctx, m.VPSID); err == nil {
+		return domain.VPSMigration{}, domain.ErrMigrationInProgress
+	}
+	m.Status = domain.VPSMigrationStatusRunning
+	m.Attempts = 0
+	m.NextRunAt = s.now()
+	m.FinishedAt = nil
+	if err := s.repo.UpdateVPSMigration(ctx, m); err != nil {
+		return domain.VPSMigration{}, err
+	}
+	return m, nil
+}
+
+// Cancel stops a migration that has not switched yet and deletes the target
+// host if one was created. After the switch the customer already runs on the
+// target, so canceling is refused.
+func (s *Service) Cancel(ctx context.Context, id int64) (domain.VPSMigration, error) {
+	m, err := s.repo.GetVPSMigration(ctx, id)
+	if err != nil {
+		return domain.VPSMigration{}, err
+	}
+	if m.Status != domain.VPSMigrationStatusRunning && m.Status != domain.VPSMigrationStatusFailed {
+		return domain.VPSMigration{}, domain.ErrMigrationStateConflict
+	}
+	if m.Step != domain.VPSMigrationStepProvision && m.Step != domain.VPSMigrationStepTransfer {
+		return domain.VPSMigration{}, domain.ErrMigrationStateConflict
+	}
+	if m.TargetInstanceID != "" || m.CreateRequestedAt != nil {
+		cli, err := s.automation.ClientForGoodsType(ctx, m.TargetGoodsTypeID)
+		if err != nil {
+			return domain.VPSMigration{}, err
+		}
+		hostID := parseHostID(m.TargetInstanceID)
+		if hostID == 0 {
+			// CreateHost may have succeeded without us seeing the reply.
+			if hostID, err = findHost(ctx, cli, m.TargetHostName); err != nil {
+				return domain.VPSMigration{}, err
+			}
+		}
+		if hostID > 0 {
+			if err := cli.DeleteHost(ctx, hostID); err != nil {
+				return domain.VPSMigration{}, err
+			}
+		}
+	}
+	now := s.now()
+	m.Status = domain.VPSMigrationStatusCanceled
+	m.FinishedAt = &now
+	if err := s.repo.UpdateVPSMigration(ctx, m); err != nil {
+		return domain.VPSMigration{}, err
+	}
+	s.notify(ctx, m.UserID, "vps_migration_canceled", "VPS Migration Canceled",
+		fmt.Sprintf("The migration of VPS #%d was canceled. The instance stays on its current host.", m.VPSID))
+	return m, nil
+}
+
+// RunDue advances every running migration whose next run time has passed.
+// A migration that cannot be saved is logged and left for the next run so
+// it does not hold up the others.
+func (s *Service) RunDue(ctx context.Context, limit int) (int, error) {
+	due, err := s.repo.ListDueVPSMigrations(ctx, s.now(), limit)
+	if err != nil {
+		return 0, err
+	}
+	for _, m := range due {
+		if err := s.advance(ctx, m); err != nil {
+			log.Printf("vps migration %d: %v", m.ID, err)
+		}
+	}
+	return len(due), nil
+}
+
+// advance runs steps until the migration finishes, has to wait or fails.
+// Progress is saved after every step so a crash never repeats CreateHost.
+func (s *Service) advance(ctx context.Context, m domain.VPSMigration) error {
+	for m.Status == domain.VPSMigrationStatusRunning {
+		if m.Step == domain.VPSMigrationStepDone {
+			return s.complete(ctx, m)
+		}
+		step := m.Step
+		stepErr := s.runStep(ctx, &m)
+		s.log(ctx, m, step, stepErr)
+		if stepErr == nil {
+			m.Attempts = 0
+			m.LastError = ""
+			if err := s.repo.UpdateVPSMigration(ctx, m); err != nil {
+				return err
+			}
+			continue
+		}
+		m.LastError = stepErr.Error()
+		if errors.Is(stepErr, errNotReady) && s.now().Sub(m.CreatedAt) < readyTimeout {
+			m.NextRunAt = s.now().Add(waitInterval)
+			return s.repo.UpdateVPSMigration(ctx, m)
+		}
+		m.Attempts++
+		if m.Attempts >= m.MaxAttempts {
+			return s.fail(ctx, m)
+		}
+		m.NextRunAt = s.now().Add(time.Duration(m.Attempts) * retryBackoff)
+		return s.repo.UpdateVPSMigration(ctx, m)
+	}
+	return nil
+}
+
+func (s *Service) runStep(ctx context.Context, m *domain.VPSMigration) error {
+	switch m.Step {
+	case domain.VPSMigrationStepProvision:
+		return s.provision(ctx, m)
+	case domain.VPSMigrationStepTransfer:
+		return s.transfer(ctx, m)
+	case domain.VPSMigrationStepSwitch:
+		return s.switchOver(ctx, m)
+	case domain.VPSMigrationStepCleanup:
+		return s.cleanup(ctx, m)
+	}
+	return domain.ErrMigrationStateConflict
+}
+
+func (s *Service) provision(ctx context.Context, m *domain.VPSMigration) error {
+	inst, err := s.vps.GetInstance(ctx, m.VPSID)
+	if err != nil {
+		return err
+	}
+	img, err := s.images.GetSystemImage(ctx, m.TargetSystemID)
+	if err != nil {
+		return err
+	}
+	cli, err := s.automation.ClientForGoodsType(ctx, m.TargetGoodsTypeID)
+	if err != nil {
+		return err
+	}
+	expireAt := s.now().AddDate(0, 1, 0)
+	if inst.ExpireAt != nil {
+		expireAt = *inst.ExpireAt
+	}
+	portNum := inst.PortNum
+	if portNum <= 0 {
+		portNum = 30
+	}
+	// The host name and passwords are saved before CreateHost so a retry
+	// after a lost response finds the host it asked for instead of creating
+	// a second one.
+	if m.CreateRequestedAt != nil {
+		hostID, err := findHost(ctx, cli, m.TargetHostName)
+		if err != nil {
+			return err
+		}
+		if hostID != 0 {
+			return s.provisioned(m, hostID)
+		}
+	} else {
+		now := s.now()
+		m.TargetHostName = fmt.Sprintf("mig-%d-%d", inst.ID, m.ID)
+		m.TargetAccessJSON = mustJSON(map[string]any{"os_password": randomToken(12), "vnc_password": randomToken(8)})
+		m.CreateRequestedAt = &now
+		if err := s.repo.UpdateVPSMigration(ctx, *m); err != nil {
+			return err
+		}
+	}
+	var access struct {
+		OSPassword  string `json:"os_password"`
+		VNCPassword string `json:"vnc_password"`
+	}
+	_ = json.Unmarshal([]byte(m.TargetAccessJSON), &access)
+	res, err := cli.CreateHost(ctx, appshared.AutomationCreateHostRequest{
+		LineID:     m.TargetLineID,
+		OS:         img.Name,
+		CPU:        inst.CPU,
+		MemoryGB:   inst.MemoryGB,
+		DiskGB:     inst.DiskGB,
+		Bandwidth:  inst.BandwidthMB,
+		PortNum:    portNum,
+		ExpireTime: expireAt,
+		HostName:   m.TargetHostName,
+		SysPwd:     access.OSPassword,
+		VNCPwd:     access.VNCPassword,
+	})
+	if err != nil {
+		return err
+	}
+	hostID := res.HostID
+	if hostID == 0 {
+		hostID, _ = findHost(ctx, cli, m.TargetHostName)
+	}
+	if hostID == 0 {
+		return domain.ErrHostIDNotFound
+	}
+	return s.provisioned(m, hostID)
+}
+
+func (s *Service) provisioned(m *domain.VPSMigration, hostID int64) error {
+	m.TargetInstanceID = strconv.FormatInt(hostID, 10)
+	if m.TransferData {
+		m.Step = domain.VPSMigrationStepTransfer
+	} else {
+		m.Step = domain.VPSMigrationStepSwitch
+	}
+	return nil
+}
+
+// findHost looks the target host up by the name provision gave it; 0 means
+// the automation has no such host.
+func findHost(ctx context.Context, cli appshared.AutomationClient, hostName string) (int64, error) {
+	hosts, err := cli.ListHostSimple(ctx, hostName)
+	if err != nil {
+		return 0, err
+	}
+	for _, host := range hosts {
+		if host.HostName == hostName {
+			return host.ID, nil
+		}
+	}
+	return 0, nil
+}
+
+// transfer takes a fresh backup of the source and restores it onto the
+// target. Both hosts live behind the same plugin, which Start enforces.
+func (s *Service) transfer(ctx context.Context, m *domain.VPSMigration) error {
+	cli, err := s.automation.ClientForGoodsType(ctx, m.SourceGoodsTypeID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.targetInfo(ctx, cli, m); err != nil {
+		return err
+	}
+	sourceID := parseHostID(m.SourceInstanceID)
+	if m.BackupID == 0 {
+		if m.BackupRequestedAt == nil {
+			if err := cli.CreateBackup(ctx, sourceID); err != nil {
+				return err
+			}
+			now := s.now()
+			m.BackupRequestedAt = &now
+			if err := s.repo.UpdateVPSMigration(ctx, *m); err != nil {
+				return err
+			}
+		}
+		backups, err := cli.ListBackups(ctx, sourceID)
+		if err != nil {
+			return err
+		}
+		m.BackupID = newestSince(backups, m.BackupRequestedAt.Add(-time.Minute))
+		if m.BackupID == 0 {
+			return errNotReady
+		}
+	}
+	if err := cli.RestoreBackup(ctx, parseHostID(m.TargetInstanceID), m.BackupID); err != nil {
+		return err
+	}
+	m.Step = domain.VPSMigrationStepSwitch
+	return nil
+}
+
+// switchOver rewrites the local instance to point at the target host. The
+// customer keeps their spec, price and expiry; only placement changes.
+func (s *Service) switchOver(ctx context.Context, m *domain.VPSMigration) error {
+	cli, err := s.automation.ClientForGoodsType(ctx, m.TargetGoodsTypeID)
+	if err != nil {
+		return err
+	}
+	info, err := s.targetInfo(ctx, cli, m)
+	if err != nil {
+		return err
+	}
+	inst, err := s.vps.GetInstance(ctx, m.VPSID)
+	if err != nil {
+		return err
+	}
+	access := map[string]any{}
+	_ = json.Unmarshal([]byte(inst.AccessInfoJSON), &access)
+	var target map[string]any
+	_ = json.Unmarshal([]byte(m.TargetAccessJSON), &target)
+	for key, value := range target {
+		// A restored backup brings the source's OS password along.
+		if key == "os_password" && m.TransferData {
+			continue
+		}
+		access[key] = value
+	}
+	access["remote_ip"] = info.RemoteIP
+	if info.PanelPassword != "" {
+		access["panel_password"] = info.PanelPassword
+	}
+	inst.AutomationInstanceID = m.TargetInstanceID
+	inst.GoodsTypeID = m.TargetGoodsTypeID
+	inst.LineID = m.TargetLineID
+	inst.RegionID = m.TargetRegionID
+	inst.PackageID = m.TargetPackageID
+	inst.SystemID = m.TargetSystemID
+	if pkg, err := s.catalog.GetPackage(ctx, m.TargetPackageID); err == nil {
+		inst.PackageName = pkg.Name
+	}
+	if region, err := s.catalog.GetRegion(ctx, m.TargetRegionID); err == nil {
+		inst.Region = region.Name
+	}
+	inst.Status = appvps.MapAutomationState(info.State)
+	inst.PanelURLCache = ""
+	inst.AccessInfoJSON = mustJSON(access)
+	if err := s.vps.UpdateInstanceLocal(ctx, inst); err != nil {
+		return err
+	}
+	_ = s.vps.UpdateInstanceStatus(ctx, inst.ID, inst.Status, info.State)
+	if s.items != nil && inst.OrderItemID > 0 {
+		_ = s.items.UpdateOrderItemAutomation(ctx, inst.OrderItemID, m.TargetInstanceID)
+	}
+	m.Step = domain.VPSMigrationStepCleanup
+	return nil
+}
+
+func (s *Service) cleanup(ctx context.Context, m *domain.VPSMigration) error {
+	cli, err := s.automation.ClientForGoodsType(ctx, m.SourceGoodsTypeID)
+	if err != nil {
+		return err
+	}
+	if err := cli.DeleteHost(ctx, parseHostID(m.SourceInstanceID)); err != nil {
+		return err
+	}
+	m.Step = domain.VPSMigrationStepDone
+	return nil
+}
+
+func (s *Service) targetInfo(ctx context.Context, cli appshared.AutomationClient, m *domain.VPSMigration) (appshared.AutomationHostInfo, error) {
+	info, err := cli.GetHostInfo(ctx, parseHostID(m.TargetInstanceID))
+	if err != nil {
+		return appshared.AutomationHostInfo{}, err
+	}
+	if appvps.MapAutomationState(info.State) == domain.VPSStatusProvisioning {
+		return appshared.AutomationHostInfo{}, errNotReady
+	}
+	return info, nil
+}
+
+func (s *Service) complete(ctx context.Context, m domain.VPSMigration) error {
+	now := s.now()
+	m.Status = domain.VPSMigrationStatusCompleted
+	m.FinishedAt = &now
+	if err := s.repo.UpdateVPSMigration(ctx, m); err != nil {
+		return err
+	}
+	content := fmt.Sprintf("VPS #%d has been migrated to its new host.", m.VPSID)
+	if inst, err := s.vps.GetInstance(ctx, m.VPSID); err == nil {
+		var access map[string]any
+		_ = json.Unmarshal([]byte(inst.AccessInfoJSON), &access)
+		if ip, _ := access["remote_ip"].(string); ip != "" {
+			content = fmt.Sprintf("VPS %s has been migrated to its new host. The new IP address is %s.", inst.Name, ip)
+		}
+	}
+	s.notify(ctx, m.UserID, "vps_migration_completed", "VPS Migration Completed", content)
+	return nil
+}
+
+func (s *Service) fail(ctx context.Context, m domain.VPSMigration) error {
+	now := s.now()
+	m.Status = domain.VPSMigrationStatusFailed
+	m.FinishedAt = &now
+	if err := s.repo.UpdateVPSMigration(ctx, m); err != nil {
+		return err
+	}
+	content := fmt.Sprintf("The migration of VPS #%d could not be completed. The instance keeps running on its current host and our staff will follow up.", m.VPSID)
+	if m.Step == domain.VPSMigrationStepCleanup {
+		content = fmt.Sprintf("VPS #%d now runs on its new host. Removing the old host needs manual follow-up by our staff.", m.VPSID)
+	}
+	s.notify(ctx, m.UserID, "vps_migration_failed", "VPS Migration Delayed", content)
+	return nil
+}
+
+func (s *Service) notify(ctx context.Context, userID int64, typ, title, content string) {
+	if s.messages == nil || userID <= 0 {
+		return
+	}
+	_ = s.messages.NotifyUser(ctx, userID, typ, title, content)
+}
+
+func (s *Service) log(ctx context.Context, m domain.VPSMigration, step domain.VPSMigrationStep, stepErr error) {
+	if s.autoLogs == nil || errors.Is(stepErr, errNotReady) {
+		return
+	}
+	inst, _ := s.vps.GetInstance(ctx, m.VPSID)
+	req := map[string]any{
+		"migration_id":       m.ID,
+		"vps_id":             m.VPSID,
+		"source_instance_id": m.SourceInstanceID,
+		"target_goods_type":  m.TargetGoodsTypeID,
+		"target_line_id":     m.TargetLineID,
+		"attempt":            m.Attempts + 1,
+	}
+	resp := map[string]any{"target_instance_id": m.TargetInstanceID, "backup_id": m.BackupID}
+	message := "ok"
+	if stepErr != nil {
+		resp = map[string]any{"error": stepErr.Error()}
+		message = stepErr.Error()
+	}
+	_ = s.autoLogs.CreateAutomationLog(ctx, &domain.AutomationLog{
+		OrderItemID:  inst.OrderItemID,
+		Action:       "migration." + string(step),
+		RequestJSON:  mustJSON(req),
+		ResponseJSON: mustJSON(resp),
+		Success:      stepErr == nil,
+		Message:      message,
+	})
+}
+
+// newestSince picks the most recent backup created at or after since.
+func newestSince(backups []appshared.AutomationBackup, since time.Time) int64 {
+	items := make([]appshared.AutomationBackup, 0, len(backups))
+	for _, item := range backups {
+		if itemInt(item, "id") > 0 && itemInt(item, "created_at_unix") >= since.Unix() {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		return 0
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return itemInt(items[i], "created_at_unix") > itemInt(items[j], "created_at_unix")
+	})
+	return itemInt(items[0], "id")
+}
+
+func parseHostID(v string) int64 {
+	id, _ := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	return id
+}
+
+func itemInt(item map[string]any, key string) int64 {
+	switch v := item[key].(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	case json.Number:
+		n, _ := v.Int64()
+		return n
+	case string:
+		n, _ := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return n
+	}
+	return 0
+}
+
+func randomToken(n int) string {
+	letters := []byte("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
+	buf := make([]byte, n)
+	_, _ = rand.Read(buf)
+	for i := range buf {
+		buf[i] = letters[int(buf[i])%len(letters)]
+	}
+	return string(buf)
+}
+
+func mustJSON(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
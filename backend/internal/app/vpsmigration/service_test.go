@@ -0,0 +1,205 @@
+package vpsmigration_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	appshared "xiaoheiplay/internal/app/shared"
+	appvpsmigration "xiaoheiplay/internal/app/vpsmigration"
+	"xiaoheiplay/internal/domain"
+	"xiaoheiplay/internal/testutil"
+)
+
+func TestVPSMigration_MovesInstanceToTargetLine(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	seed := testutil.SeedCatalog(t, repo)
+	user := testutil.CreateUser(t, repo, "mig", "mig@example.com", "pass")
+
+	target := domain.PlanGroup{RegionID: seed.Region.ID, Name: "New line", LineID: 2, Active: true, Visible: true, CapacityRemaining: -1}
+	if err := repo.CreatePlanGroup(ctx, &target); err != nil {
+		t.Fatalf("create plan group: %v", err)
+	}
+	pkg := domain.Package{PlanGroupID: target.ID, GoodsTypeID: 1, Name: "New basic", Cores: 2, MemoryGB: 4, DiskGB: 40, BandwidthMB: 10, Active: true, Visible: true, CapacityRemaining: -1}
+	if err := repo.CreatePackage(ctx, &pkg); err != nil {
+		t.Fatalf("create package: %v", err)
+	}
+	inst := domain.VPSInstance{
+		UserID:               user.ID,
+		AutomationInstanceID: "7",
+		GoodsTypeID:          1,
+		LineID:               seed.PlanGroup.LineID,
+		PackageID:            seed.Package.ID,
+		SystemID:             seed.SystemImage.ID,
+		Name:                 "vm",
+		CPU:                  2,
+		MemoryGB:             4,
+		DiskGB:               40,
+		BandwidthMB:          10,
+		MonthlyPrice:         1000,
+		Status:               domain.VPSStatusRunning,
+		SpecJSON:             "{}",
+		AccessInfoJSON:       `{"os_password":"old"}`,
+	}
+	if err := repo.CreateInstance(ctx, &inst); err != nil {
+		t.Fatalf("create vps: %v", err)
+	}
+	client := &testutil.FakeAutomationClient{
+		CreateHostResult: appshared.AutomationCreateHostResult{HostID: 1001},
+		BackupList:       []appshared.AutomationBackup{{"id": int64(55), "created_at_unix": time.Now().Unix() + 5}},
+	}
+	svc := appvpsmigration.NewService(repo, repo, repo, repo, &testutil.FakeAutomationResolver{Client: client}, repo)
+
+	if _, err := svc.Start(ctx, 1, appvpsmigration.StartInput{VPSID: inst.ID, TargetPackageID: seed.Package.ID}); !errors.Is(err, domain.ErrInvalidMigration) {
+		t.Fatalf("expected package without goods type to be rejected, got %v", err)
+	}
+	m, err := svc.Start(ctx, 1, appvpsmigration.StartInput{VPSID: inst.ID, TargetPackageID: pkg.ID, TransferData: true})
+	if err != nil {
+		t.Fatalf("start migration: %v", err)
+	}
+	if _, err := svc.Start(ctx, 1, appvpsmigration.StartInput{VPSID: inst.ID, TargetPackageID: pkg.ID}); !errors.Is(err, domain.ErrMigrationInProgress) {
+		t.Fatalf("expected in progress, got %v", err)
+	}
+	if n, err := svc.RunDue(ctx, 10); err != nil || n != 1 {
+		t.Fatalf("run due: n=%d err=%v", n, err)
+	}
+	m, err = svc.Get(ctx, m.ID)
+	if err != nil {
+		t.Fatalf("get migration: %v", err)
+	}
+	if m.Status != domain.VPSMigrationStatusCompleted || m.Step != domain.VPSMigrationStepDone || m.BackupID != 55 {
+		t.Fatalf("unexpected migration: %+v", m)
+	}
+	if len(client.CreateHostRequests) != 1 || client.CreateHostRequests[0].LineID != 2 || client.CreateHostRequests[0].CPU != 2 {
+		t.Fatalf("unexpected create host requests: %+v", client.CreateHostRequests)
+	}
+	if len(client.BackupCreateCalls) != 1 || client.BackupCreateCalls[0] != 7 {
+		t.Fatalf("expected source backup, got %v", client.BackupCreateCalls)
+	}
+	if len(client.RestoreBackupCalls) != 1 || client.RestoreBackupCalls[0].HostID != 1001 || client.RestoreBackupCalls[0].BackupID != 55 {
+		t.Fatalf("unexpected restores: %+v", client.RestoreBackupCalls)
+	}
+	if len(client.DeleteCalls) != 1 || client.DeleteCalls[0] != 7 {
+		t.Fatalf("expected source host deleted, got %v", client.DeleteCalls)
+	}
+	got, err := repo.GetInstance(ctx, inst.ID)
+	if err != nil {
+		t.Fatalf("get vps: %v", err)
+	}
+	if got.AutomationInstanceID != "1001" || got.LineID != 2 || got.PackageID != pkg.ID || got.MonthlyPrice != 1000 {
+		t.Fatalf("unexpected switched instance: %+v", got)
+	}
+	if !strings.Contains(got.AccessInfoJSON, `"os_password":"old"`) {
+		t.Fatalf("restored instance must keep the source os password: %s", got.AccessInfoJSON)
+	}
+}
+
+func TestVPSMigration_RetriesAndCancels(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	seed := testutil.SeedCatalog(t, repo)
+	user := testutil.CreateUser(t, repo, "mig2", "mig2@example.com", "pass")
+	target := domain.PlanGroup{RegionID: seed.Region.ID, Name: "Other plugin", LineID: 9, Active: true, Visible: true, CapacityRemaining: -1}
+	if err := repo.CreatePlanGroup(ctx, &target); err != nil {
+		t.Fatalf("create plan group: %v", err)
+	}
+	pkg := domain.Package{PlanGroupID: target.ID, GoodsTypeID: 2, Name: "Other", Cores: 1, MemoryGB: 1, DiskGB: 10, BandwidthMB: 5, Active: true, Visible: true, CapacityRemaining: -1}
+	if err := repo.CreatePackage(ctx, &pkg); err != nil {
+		t.Fatalf("create package: %v", err)
+	}
+	inst := domain.VPSInstance{UserID: user.ID, AutomationInstanceID: "8", GoodsTypeID: 1, LineID: 1, SystemID: seed.SystemImage.ID, Name: "vm2", Status: domain.VPSStatusRunning, SpecJSON: "{}"}
+	if err := repo.CreateInstance(ctx, &inst); err != nil {
+		t.Fatalf("create vps: %v", err)
+	}
+	client := &testutil.FakeAutomationClient{CreateHostErr: errors.New("line is full")}
+	svc := appvpsmigration.NewService(repo, repo, repo, repo, &testutil.FakeAutomationResolver{Client: client}, repo)
+
+	if _, err := svc.Start(ctx, 1, appvpsmigration.StartInput{VPSID: inst.ID, TargetPackageID: pkg.ID, TransferData: true}); !errors.Is(err, domain.ErrMigrationTransferUnsupported) {
+		t.Fatalf("expected cross-plugin transfer to be rejected, got %v", err)
+	}
+	m, err := svc.Start(ctx, 1, appvpsmigration.StartInput{VPSID: inst.ID, TargetPackageID: pkg.ID})
+	if err != nil {
+		t.Fatalf("start migration: %v", err)
+	}
+	if _, err := svc.RunDue(ctx, 10); err != nil {
+		t.Fatalf("run due: %v", err)
+	}
+	m, _ = svc.Get(ctx, m.ID)
+	if m.Status != domain.VPSMigrationStatusRunning || m.Attempts != 1 || m.LastError != "line is full" || !m.NextRunAt.After(time.Now()) {
+		t.Fatalf("expected a scheduled retry, got %+v", m)
+	}
+	if n, _ := svc.RunDue(ctx, 10); n != 0 {
+		t.Fatalf("retry must wait for backoff, ran %d", n)
+	}
+	if _, err := svc.Retry(ctx, m.ID); !errors.Is(err, domain.ErrMigrationStateConflict) {
+		t.Fatalf("running migration cannot be retried, got %v", err)
+	}
+	m, err = svc.Cancel(ctx, m.ID)
+	if err != nil || m.Status != domain.VPSMigrationStatusCanceled {
+		t.Fatalf("cancel: %+v err=%v", m, err)
+	}
+	if len(client.DeleteCalls) != 0 {
+		t.Fatalf("no host was created, nothing to delete: %v", client.DeleteCalls)
+	}
+	got, _ := repo.GetInstance(ctx, inst.ID)
+	if got.AutomationInstanceID != "8" || got.GoodsTypeID != 1 {
+		t.Fatalf("canceled migration must leave the instance alone: %+v", got)
+	}
+}
+
+func TestVPSMigration_RetryFindsHostFromLostCreate(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	seed := testutil.SeedCatalog(t, repo)
+	user := testutil.CreateUser(t, repo, "mig3", "mig3@example.com", "pass")
+	target := domain.PlanGroup{RegionID: seed.Region.ID, Name: "Retry line", LineID: 3, Active: true, Visible: true, CapacityRemaining: -1}
+	if err := repo.CreatePlanGroup(ctx, &target); err != nil {
+		t.Fatalf("create plan group: %v", err)
+	}
+	pkg := domain.Package{PlanGroupID: target.ID, GoodsTypeID: 1, Name: "Retry", Cores: 1, MemoryGB: 1, DiskGB: 10, BandwidthMB: 5, Active: true, Visible: true, CapacityRemaining: -1}
+	if err := repo.CreatePackage(ctx, &pkg); err != nil {
+		t.Fatalf("create package: %v", err)
+	}
+	inst := domain.VPSInstance{UserID: user.ID, AutomationInstanceID: "9", GoodsTypeID: 1, LineID: 1, SystemID: seed.SystemImage.ID, Name: "vm3", Status: domain.VPSStatusRunning, SpecJSON: "{}"}
+	if err := repo.CreateInstance(ctx, &inst); err != nil {
+		t.Fatalf("create vps: %v", err)
+	}
+	// The host gets created but the response is lost.
+	client := &testutil.FakeAutomationClient{CreateHostErr: errors.New("timeout")}
+	svc := appvpsmigration.NewService(repo, repo, repo, repo, &testutil.FakeAutomationResolver{Client: client}, repo)
+
+	m, err := svc.Start(ctx, 1, appvpsmigration.StartInput{VPSID: inst.ID, TargetPackageID: pkg.ID})
+	if err != nil {
+		t.Fatalf("start migration: %v", err)
+	}
+	if _, err := svc.RunDue(ctx, 10); err != nil {
+		t.Fatalf("run due: %v", err)
+	}
+	m, _ = svc.Get(ctx, m.ID)
+	if m.TargetHostName == "" || m.CreateRequestedAt == nil || len(client.CreateHostRequests) != 1 || client.CreateHostRequests[0].HostName != m.TargetHostName {
+		t.Fatalf("expected the requested host to be saved: %+v %+v", m, client.CreateHostRequests)
+	}
+	if !strings.Contains(m.TargetAccessJSON, client.CreateHostRequests[0].SysPwd) {
+		t.Fatalf("expected the requested password to be saved: %s", m.TargetAccessJSON)
+	}
+
+	client.CreateHostErr = nil
+	client.ListHostSimpleItems = []appshared.AutomationHostSimple{{ID: 2002, HostName: m.TargetHostName}}
+	m.NextRunAt = time.Now().Add(-time.Minute)
+	if err := repo.UpdateVPSMigration(ctx, m); err != nil {
+		t.Fatalf("make due: %v", err)
+	}
+	if _, err := svc.RunDue(ctx, 10); err != nil {
+		t.Fatalf("run due again: %v", err)
+	}
+	m, _ = svc.Get(ctx, m.ID)
+	if len(client.CreateHostRequests) != 1 {
+		t.Fatalf("retry must not create a second host, got %d requests", len(client.CreateHostRequests))
+	}
+	if m.Status != domain.VPSMigrationStatusCompleted || m.TargetInstanceID != "2002" {
+		t.Fatalf("expected migration onto the found host, got %+v", m)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	appports "xiaoheiplay/internal/app/ports"
 	appshared "xiaoheiplay/internal/app/shared"
@@ -13,12 +14,36 @@ import (
 type Service struct {
 	wallets appports.WalletRepository
 	audit   appports.AuditRepository
+	holds   appports.WalletHoldRepository
+	ledger  ledgerPoster
+}
+
+type ledgerPoster interface {
+	Post(ctx context.Context, entry domain.LedgerEntry) error
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 func NewService(wallets appports.WalletRepository, audit appports.AuditRepository) *Service {
 	return &Service{wallets: wallets, audit: audit}
 }
 
+func (s *Service) SetWalletHolds(holds appports.WalletHoldRepository) {
+	s.holds = holds
+}
+
+func (s *Service) SetLedger(ledger ledgerPoster) {
+	s.ledger = ledger
+}
+
+// inTx commits the balance change together with its ledger entry. Without a
+// ledger there is nothing to post and fn runs on its own.
+func (s *Service) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.ledger == nil {
+		return fn(ctx)
+	}
+	return s.ledger.InTx(ctx, fn)
+}
+
 func (s *Service) GetWallet(ctx context.Context, userID int64) (domain.Wallet, error) {
 	if s.wallets == nil {
 		return domain.Wallet{}, appshared.ErrInvalidInput
@@ -33,6 +58,18 @@ func (s *Service) ListTransactions(ctx context.Context, userID int64, limit, off
 	return s.wallets.ListWalletTransactions(ctx, userID, limit, offset)
 }
 
+func (s *Service) ListHolds(ctx context.Context, userID int64, status string, limit, offset int) ([]domain.WalletHold, int, error) {
+	if s.holds == nil {
+		return nil, 0, appshared.ErrInvalidInput
+	}
+	switch domain.WalletHoldStatus(status) {
+	case "", domain.WalletHoldActive, domain.WalletHoldCaptured, domain.WalletHoldReleased:
+	default:
+		return nil, 0, appshared.ErrInvalidInput
+	}
+	return s.holds.ListWalletHolds(ctx, userID, status, limit, offset)
+}
+
 func (s *Service) AdjustBalance(ctx context.Context, adminID int64, userID int64, amount int64, note string) (domain.Wallet, error) {
 	if s.wallets == nil {
 		return domain.Wallet{}, appshared.ErrInvalidInput
@@ -41,7 +78,22 @@ func (s *Service) AdjustBalance(ctx context.Context, adminID int64, userID int64
 	if amount < 0 {
 		txType = "debit"
 	}
-	wallet, err := s.wallets.AdjustWalletBalance(ctx, userID, amount, txType, "admin_adjust", adminID, note)
+	var wallet domain.Wallet
+	err := s.inTx(ctx, func(ctx context.Context) error {
+		var err error
+		wallet, err = s.wallets.AdjustWalletBalance(ctx, userID, amount, txType, "admin_adjust", adminID, note)
+		if err != nil || s.ledger == nil {
+			return err
+		}
+		account := domain.LedgerWalletAccount(userID)
+		entry := domain.NewLedgerTransfer(domain.LedgerKindAdjustment, "admin_adjust", adminID, userID, domain.LedgerAccountAdjustments, account, amount, note)
+		if amount < 0 {
+			entry = domain.NewLedgerTransfer(domain.LedgerKindAdjustment, "admin_adjust", adminID, userID, account, domain.LedgerAccountAdjustments, -amount, note)
+		}
+		// The reference is the admin, so the key needs its own discriminator.
+		entry.Key = fmt.Sprintf("%s:%d:%d", entry.Key, userID, time.Now().UnixNano())
+		return s.ledger.Post(ctx, entry)
+	})
 	if err != nil {
 		return domain.Wallet{}, err
 	}
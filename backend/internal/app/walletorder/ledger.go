@@ -0,0 +1,80 @@
+package walletorder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"xiaoheiplay/internal/domain"
+)
+
+type ledgerPoster interface {
+	Post(ctx context.Context, entry domain.LedgerEntry) error
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+func (s *Service) SetLedger(ledger ledgerPoster) {
+	s.ledger = ledger
+}
+
+// inTx runs a wallet order change and the entries it posts in one
+// transaction, so an entry that cannot be posted rolls the change back.
+func (s *Service) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.ledger == nil {
+		return fn(ctx)
+	}
+	return s.ledger.InTx(ctx, fn)
+}
+
+// postApproval books an approved wallet order: recharges and withdrawals
+// clear through the payment provider's account, refunds settle the amount
+// accrued in refunds payable.
+func (s *Service) postApproval(ctx context.Context, order domain.WalletOrder) error {
+	if s.ledger == nil {
+		return nil
+	}
+	wallet := domain.LedgerWalletAccount(order.UserID)
+	var entry domain.LedgerEntry
+	switch order.Type {
+	case domain.WalletOrderRecharge:
+		entry = domain.NewLedgerTransfer(domain.LedgerKindRecharge, walletOrderRefType, order.ID, order.UserID, domain.LedgerGatewayAccount(metaProvider(order.MetaJSON, "payment_method")), wallet, order.Amount, "recharge")
+	case domain.WalletOrderWithdraw:
+		entry = domain.NewLedgerTransfer(domain.LedgerKindWithdraw, walletOrderRefType, order.ID, order.UserID, wallet, domain.LedgerGatewayAccount(metaProvider(order.MetaJSON, "payout_method", "payment_method")), order.Amount, "withdraw")
+	case domain.WalletOrderRefund:
+		if err := s.postRefundAccrual(ctx, order); err != nil {
+			return err
+		}
+		entry = domain.NewLedgerTransfer(domain.LedgerKindRefundSettlement, walletOrderRefType, order.ID, order.UserID, domain.LedgerAccountRefundsPayable, wallet, order.Amount, "refund to wallet")
+	default:
+		return nil
+	}
+	return s.ledger.Post(ctx, entry)
+}
+
+// postRefundAccrual moves a refund from revenue to refunds payable when the
+// refund order is created.
+func (s *Service) postRefundAccrual(ctx context.Context, order domain.WalletOrder) error {
+	if s.ledger == nil || order.Type != domain.WalletOrderRefund {
+		return nil
+	}
+	return s.ledger.Post(ctx, domain.NewLedgerTransfer(domain.LedgerKindRefundAccrual, walletOrderRefType, order.ID, order.UserID, domain.LedgerAccountRevenue, domain.LedgerAccountRefundsPayable, order.Amount, fmt.Sprintf("refund order %d", order.ID)))
+}
+
+// postRefundReversal returns an accrued refund to revenue when the refund
+// order is rejected or canceled.
+func (s *Service) postRefundReversal(ctx context.Context, order domain.WalletOrder) error {
+	if s.ledger == nil || order.Type != domain.WalletOrderRefund {
+		return nil
+	}
+	return s.ledger.Post(ctx, domain.NewLedgerTransfer(domain.LedgerKindRefundReversal, walletOrderRefType, order.ID, order.UserID, domain.LedgerAccountRefundsPayable, domain.LedgerAccountRevenue, order.Amount, "refund rejected"))
+}
+
+func metaProvider(metaJSON string, keys ...string) string {
+	meta := parseJSON(metaJSON)
+	for _, key := range keys {
+		if v, ok := meta[key].(string); ok && strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
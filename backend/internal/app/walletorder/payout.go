@@ -0,0 +1,51 @@
+package walletorder
+
+import (
+	"context"
+	"strings"
+
+	"xiaoheiplay/internal/domain"
+)
+
+type payoutAccountResolver interface {
+	WithdrawAccount(ctx context.Context, userID, id int64) (domain.PayoutAccount, error)
+}
+
+// SetPayoutAccounts lets withdrawals name a verified payout account via
+// meta.payout_account_id.
+func (s *Service) SetPayoutAccounts(accounts payoutAccountResolver) {
+	s.payoutAccounts = accounts
+}
+
+// withdrawPayee copies the chosen payout account into the withdrawal meta so
+// later batches pay to the account as it was when the user asked. Payout
+// fields supplied by the client are discarded.
+func (s *Service) withdrawPayee(ctx context.Context, userID int64, meta map[string]any) (map[string]any, error) {
+	if s.payoutAccounts == nil {
+		return meta, nil
+	}
+	out := make(map[string]any, len(meta)+6)
+	for k, v := range meta {
+		if !strings.HasPrefix(k, "payout_") {
+			out[k] = v
+		}
+	}
+	accountID := getInt64(meta["payout_account_id"])
+	if accountID <= 0 {
+		if required, _ := getSettingBool(ctx, s.settings, "withdraw_requires_payout_account"); required {
+			return nil, domain.ErrPayoutAccountRequired
+		}
+		return out, nil
+	}
+	account, err := s.payoutAccounts.WithdrawAccount(ctx, userID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	out["payout_account_id"] = account.ID
+	out["payout_method"] = string(account.Channel)
+	out["payout_account_name"] = account.AccountName
+	out["payout_account_no"] = account.AccountNo
+	out["payout_bank_name"] = account.BankName
+	out["payout_bank_branch"] = account.BankBranch
+	return out, nil
+}
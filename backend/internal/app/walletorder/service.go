@@ -44,8 +44,16 @@ type Service struct {
 	automation appports.AutomationClientResolver
 	audit      appports.AuditRepository
 	userTiers  userTierAutoApprover
+	holds      appports.WalletHoldRepository
+	ledger     ledgerPoster
+
+	payoutAccounts payoutAccountResolver
 }
 
+// walletOrderRefType is the reference used for wallet transactions and holds
+// created by wallet orders.
+const walletOrderRefType = "wallet_order"
+
 func NewService(orders appports.WalletOrderRepository, wallets appports.WalletRepository, settings appports.SettingsRepository, vps appports.VPSRepository, orderItems appports.OrderItemRepository, automation appports.AutomationClientResolver, audit appports.AuditRepository) *Service {
 	return &Service{orders: orders, wallets: wallets, settings: settings, vps: vps, orderItems: orderItems, automation: automation, audit: audit}
 }
@@ -58,6 +66,12 @@ func (s *Service) SetUserTierAutoApprover(approver userTierAutoApprover) {
 	s.userTiers = approver
 }
 
+// SetWalletHolds makes withdrawals freeze their amount until the order is
+// approved (captured) or rejected/canceled (released).
+func (s *Service) SetWalletHolds(holds appports.WalletHoldRepository) {
+	s.holds = holds
+}
+
 func (s *Service) CreateRefundOrder(ctx context.Context, userID int64, amount int64, note string, meta map[string]any) (domain.WalletOrder, error) {
 	if userID == 0 || amount <= 0 {
 		return domain.WalletOrder{}, appshared.ErrInvalidInput
@@ -67,7 +81,7 @@ func (s *Service) CreateRefundOrder(ctx context.Context, userID int64, amount in
 		return domain.WalletOrder{}, appshared.ErrInvalidInput
 	}
 	order := domain.WalletOrder{UserID: userID, Type: domain.WalletOrderRefund, Amount: amount, Currency: "CNY", Status: domain.WalletOrderPendingReview, Note: trimmedNote, MetaJSON: toJSON(meta)}
-	if err := s.orders.CreateWalletOrder(ctx, &order); err != nil {
+	if err := s.createRefundOrder(ctx, &order); err != nil {
 		return domain.WalletOrder{}, err
 	}
 	return order, nil
@@ -107,17 +121,29 @@ func (s *Service) CreateWithdraw(ctx context.Context, userID int64, input Wallet
 	if err != nil {
 		return domain.WalletOrder{}, err
 	}
-	if wallet.Balance < input.Amount {
+	if wallet.Available() < input.Amount {
 		return domain.WalletOrder{}, appshared.ErrInsufficientBalance
 	}
+	meta, err := s.withdrawPayee(ctx, userID, input.Meta)
+	if err != nil {
+		return domain.WalletOrder{}, err
+	}
 	currency := strings.TrimSpace(input.Currency)
 	if currency == "" {
 		currency = "CNY"
 	}
-	order := domain.WalletOrder{UserID: userID, Type: domain.WalletOrderWithdraw, Amount: input.Amount, Currency: currency, Status: domain.WalletOrderPendingReview, Note: trimmedNote, MetaJSON: toJSON(input.Meta)}
+	order := domain.WalletOrder{UserID: userID, Type: domain.WalletOrderWithdraw, Amount: input.Amount, Currency: currency, Status: domain.WalletOrderPendingReview, Note: trimmedNote, MetaJSON: toJSON(meta)}
 	if err := s.orders.CreateWalletOrder(ctx, &order); err != nil {
 		return domain.WalletOrder{}, err
 	}
+	if s.holds != nil {
+		hold := domain.WalletHold{UserID: userID, Amount: order.Amount, RefType: walletOrderRefType, RefID: order.ID, Note: "withdraw"}
+		if err := s.holds.CreateWalletHold(ctx, &hold); err != nil {
+			// Another debit won the race for the balance; close the order.
+			_ = s.orders.UpdateWalletOrderStatus(ctx, order.ID, domain.WalletOrderRejected, nil, "insufficient_balance")
+			return domain.WalletOrder{}, err
+		}
+	}
 	return order, nil
 }
 
@@ -156,7 +182,7 @@ func (s *Service) CancelByUser(ctx context.Context, userID, orderID int64, reaso
 	if err != nil {
 		return domain.WalletOrder{}, err
 	}
-	if order.Type != domain.WalletOrderRecharge && order.Type != domain.WalletOrderRefund {
+	if order.Type != domain.WalletOrderRecharge && order.Type != domain.WalletOrderRefund && order.Type != domain.WalletOrderWithdraw {
 		return domain.WalletOrder{}, appshared.ErrInvalidInput
 	}
 	if order.Status != domain.WalletOrderPendingReview {
@@ -165,13 +191,20 @@ func (s *Service) CancelByUser(ctx context.Context, userID, orderID int64, reaso
 	if reason == "" {
 		reason = "user_cancel"
 	}
-	updated, err := s.orders.UpdateWalletOrderStatusIfCurrent(ctx, order.ID, domain.WalletOrderPendingReview, domain.WalletOrderRejected, nil, reason)
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		updated, err := s.orders.UpdateWalletOrderStatusIfCurrent(ctx, order.ID, domain.WalletOrderPendingReview, domain.WalletOrderRejected, nil, reason)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return appshared.ErrConflict
+		}
+		return s.postRefundReversal(ctx, order)
+	})
 	if err != nil {
 		return domain.WalletOrder{}, err
 	}
-	if !updated {
-		return domain.WalletOrder{}, appshared.ErrConflict
-	}
+	s.releaseHold(ctx, order, reason)
 	order.Status = domain.WalletOrderRejected
 	order.ReviewReason = reason
 	order.ReviewedBy = nil
@@ -211,7 +244,7 @@ func (s *Service) RequestRefund(ctx context.Context, userID int64, vpsID int64,
 		status = domain.WalletOrderApproved
 	}
 	order := domain.WalletOrder{UserID: userID, Type: domain.WalletOrderRefund, Amount: amount, Currency: "CNY", Status: status, Note: reason, MetaJSON: toJSON(meta)}
-	if err := s.orders.CreateWalletOrder(ctx, &order); err != nil {
+	if err := s.createRefundOrder(ctx, &order); err != nil {
 		return domain.WalletOrder{}, nil, err
 	}
 	if status == domain.WalletOrderApproved {
@@ -257,7 +290,7 @@ func (s *Service) AutoRefundOnAdminDelete(ctx context.Context, adminID int64, vp
 		status = domain.WalletOrderApproved
 	}
 	order := domain.WalletOrder{UserID: inst.UserID, Type: domain.WalletOrderRefund, Amount: amount, Currency: "CNY", Status: status, Note: reason, MetaJSON: toJSON(meta)}
-	if err := s.orders.CreateWalletOrder(ctx, &order); err != nil {
+	if err := s.createRefundOrder(ctx, &order); err != nil {
 		return domain.WalletOrder{}, nil, err
 	}
 	if status == domain.WalletOrderApproved {
@@ -299,9 +332,16 @@ func (s *Service) Reject(ctx context.Context, adminID int64, orderID int64, reas
 	if order.Status != domain.WalletOrderPendingReview {
 		return appshared.ErrConflict
 	}
-	if err := s.orders.UpdateWalletOrderStatus(ctx, order.ID, domain.WalletOrderRejected, &adminID, reason); err != nil {
+	err = s.inTx(ctx, func(ctx context.Context) error {
+		if err := s.orders.UpdateWalletOrderStatus(ctx, order.ID, domain.WalletOrderRejected, &adminID, reason); err != nil {
+			return err
+		}
+		return s.postRefundReversal(ctx, order)
+	})
+	if err != nil {
 		return err
 	}
+	s.releaseHold(ctx, order, reason)
 	if s.audit != nil {
 		_ = s.audit.AddAuditLog(ctx, domain.AdminAuditLog{AdminID: adminID, Action: "wallet_order.reject", TargetType: "wallet_order", TargetID: strconv.FormatInt(order.ID, 10), DetailJSON: mustJSON(map[string]any{"type": order.Type, "amount": order.Amount, "reason": reason})})
 	}
@@ -334,32 +374,35 @@ func (s *Service) approveOrder(ctx context.Context, adminID int64, order domain.
 	default:
 		return domain.Wallet{}, appshared.ErrInvalidInput
 	}
-	refType := "wallet_order"
-	exists, err := s.wallets.HasWalletTransaction(ctx, order.UserID, refType, order.ID)
-	if err != nil {
-		return domain.Wallet{}, err
-	}
 	var wallet domain.Wallet
-	if exists {
-		wallet, err = s.wallets.GetWallet(ctx, order.UserID)
+	err := s.inTx(ctx, func(ctx context.Context) error {
+		exists, err := s.wallets.HasWalletTransaction(ctx, order.UserID, walletOrderRefType, order.ID)
 		if err != nil {
-			return domain.Wallet{}, err
+			return err
 		}
-	} else {
-		wallet, err = s.wallets.AdjustWalletBalance(ctx, order.UserID, amount, txType, refType, order.ID, string(order.Type))
-		if err != nil {
-			ok, checkErr := s.wallets.HasWalletTransaction(ctx, order.UserID, refType, order.ID)
-			if checkErr == nil && ok {
-				wallet, checkErr = s.wallets.GetWallet(ctx, order.UserID)
-				if checkErr != nil {
-					return domain.Wallet{}, checkErr
+		if exists {
+			wallet, err = s.wallets.GetWallet(ctx, order.UserID)
+			if err != nil {
+				return err
+			}
+		} else {
+			wallet, err = s.applyBalanceChange(ctx, order, amount, txType)
+			if err != nil {
+				ok, checkErr := s.wallets.HasWalletTransaction(ctx, order.UserID, walletOrderRefType, order.ID)
+				if checkErr != nil || !ok {
+					return err
+				}
+				if wallet, err = s.wallets.GetWallet(ctx, order.UserID); err != nil {
+					return err
 				}
-			} else {
-				return domain.Wallet{}, err
 			}
 		}
-	}
-	if err := s.orders.UpdateWalletOrderStatus(ctx, order.ID, domain.WalletOrderApproved, &adminID, ""); err != nil {
+		if err := s.orders.UpdateWalletOrderStatus(ctx, order.ID, domain.WalletOrderApproved, &adminID, ""); err != nil {
+			return err
+		}
+		return s.postApproval(ctx, order)
+	})
+	if err != nil {
 		return domain.Wallet{}, err
 	}
 	if s.audit != nil {
@@ -371,6 +414,35 @@ func (s *Service) approveOrder(ctx context.Context, adminID int64, order domain.
 	return wallet, nil
 }
 
+// createRefundOrder stores a refund order together with its accrual entry.
+func (s *Service) createRefundOrder(ctx context.Context, order *domain.WalletOrder) error {
+	return s.inTx(ctx, func(ctx context.Context) error {
+		if err := s.orders.CreateWalletOrder(ctx, order); err != nil {
+			return err
+		}
+		return s.postRefundAccrual(ctx, *order)
+	})
+}
+
+// applyBalanceChange books an approved wallet order. Withdrawals capture
+// their hold; orders created before holds existed debit the balance directly.
+func (s *Service) applyBalanceChange(ctx context.Context, order domain.WalletOrder, amount int64, txType string) (domain.Wallet, error) {
+	if order.Type == domain.WalletOrderWithdraw && s.holds != nil {
+		wallet, err := s.holds.CaptureWalletHold(ctx, walletOrderRefType, order.ID, txType, string(order.Type))
+		if err != appshared.ErrNotFound {
+			return wallet, err
+		}
+	}
+	return s.wallets.AdjustWalletBalance(ctx, order.UserID, amount, txType, walletOrderRefType, order.ID, string(order.Type))
+}
+
+func (s *Service) releaseHold(ctx context.Context, order domain.WalletOrder, reason string) {
+	if order.Type != domain.WalletOrderWithdraw || s.holds == nil {
+		return
+	}
+	_, _ = s.holds.ReleaseWalletHold(ctx, walletOrderRefType, order.ID, reason)
+}
+
 func (s *Service) deleteVPS(ctx context.Context, metaJSON string) error {
 	if s.vps == nil || s.automation == nil {
 		return appshared.ErrInvalidInput
@@ -87,3 +87,73 @@ func TestWalletOrderService_CancelByUserConflictOnNonPending(t *testing.T) {
 		t.Fatalf("expected conflict on approved order, got %v", err)
 	}
 }
+
+func TestWalletOrderService_WithdrawHoldLifecycle(t *testing.T) {
+	_, repo := testutil.NewTestDB(t, false)
+	ctx := context.Background()
+	user := testutil.CreateUser(t, repo, "w5", "w5@example.com", "pass")
+	svc := appwalletorder.NewService(repo, repo, repo, repo, repo, nil, repo)
+	svc.SetWalletHolds(repo)
+	if _, err := repo.AdjustWalletBalance(ctx, user.ID, 10000, "credit", "admin_adjust", 1, "seed"); err != nil {
+		t.Fatalf("seed wallet: %v", err)
+	}
+
+	rejected, err := svc.CreateWithdraw(ctx, user.ID, appshared.WalletOrderCreateInput{Amount: 6000})
+	if err != nil {
+		t.Fatalf("create withdraw: %v", err)
+	}
+	wallet, _ := repo.GetWallet(ctx, user.ID)
+	if wallet.Balance != 10000 || wallet.Frozen != 6000 || wallet.Available() != 4000 {
+		t.Fatalf("expected frozen withdraw amount, got %+v", wallet)
+	}
+	// Frozen funds can be neither withdrawn again nor spent.
+	if _, err := svc.CreateWithdraw(ctx, user.ID, appshared.WalletOrderCreateInput{Amount: 5000}); err != appshared.ErrInsufficientBalance {
+		t.Fatalf("expected insufficient available balance, got %v", err)
+	}
+	if _, err := repo.AdjustWalletBalance(ctx, user.ID, -5000, "debit", "order", 99, "balance payment"); err != appshared.ErrInsufficientBalance {
+		t.Fatalf("expected debit to respect holds, got %v", err)
+	}
+
+	if err := svc.Reject(ctx, 1, rejected.ID, "bank info mismatch"); err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+	wallet, _ = repo.GetWallet(ctx, user.ID)
+	if wallet.Frozen != 0 || wallet.Balance != 10000 {
+		t.Fatalf("expected hold released on reject, got %+v", wallet)
+	}
+
+	approved, err := svc.CreateWithdraw(ctx, user.ID, appshared.WalletOrderCreateInput{Amount: 7000})
+	if err != nil {
+		t.Fatalf("create withdraw: %v", err)
+	}
+	_, after, err := svc.Approve(ctx, 1, approved.ID)
+	if err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if after == nil || after.Balance != 3000 || after.Frozen != 0 {
+		t.Fatalf("expected captured withdraw, got %+v", after)
+	}
+
+	canceled, err := svc.CreateWithdraw(ctx, user.ID, appshared.WalletOrderCreateInput{Amount: 2000})
+	if err != nil {
+		t.Fatalf("create withdraw: %v", err)
+	}
+	if _, err := svc.CancelByUser(ctx, user.ID, canceled.ID, ""); err != nil {
+		t.Fatalf("cancel withdraw: %v", err)
+	}
+	holds, total, err := repo.ListWalletHolds(ctx, user.ID, "", 10, 0)
+	if err != nil || total != 3 {
+		t.Fatalf("expected three holds, got %d err=%v", total, err)
+	}
+	statuses := map[int64]domain.WalletHoldStatus{}
+	for _, hold := range holds {
+		statuses[hold.RefID] = hold.Status
+	}
+	if statuses[rejected.ID] != domain.WalletHoldReleased || statuses[approved.ID] != domain.WalletHoldCaptured || statuses[canceled.ID] != domain.WalletHoldReleased {
+		t.Fatalf("unexpected hold statuses: %+v", statuses)
+	}
+	txs, _, _ := repo.ListWalletTransactions(ctx, user.ID, 10, 0)
+	if len(txs) != 2 || txs[0].Amount != -7000 || txs[0].RefType != "wallet_order" || txs[0].RefID != approved.ID {
+		t.Fatalf("expected one capture transaction, got %+v", txs)
+	}
+}
@@ -249,4 +249,73 @@ var (
 	ErrTooMany2FAAttempts                                 = errors.New("too many 2fa attempts")
 	ErrServiceUnavailable                                 = errors.New("service unavailable")
 	ErrAccountDisabled                                    = errors.New("account disabled")
+	ErrProbeReleaseNotFound                               = errors.New("probe release not found")
+	ErrProbeReleaseInvalid                                = errors.New("probe release invalid")
+	ErrProbeReleaseUnsigned                               = errors.New("probe release unsigned")
+	ErrProbeReleasesDisabled                              = errors.New("probe releases disabled")
+	ErrMetricsDisabled                                    = errors.New("metrics disabled")
+	ErrInvalidPluginRepositories                          = errors.New("invalid plugin_repositories setting")
+	ErrPluginRepositoryNotFound                           = errors.New("plugin repository not found")
+	ErrPluginVersionNotFound                              = errors.New("plugin version not found")
+	ErrPluginCircuitOpen                                  = errors.New("plugin circuit open")
+	ErrPluginRestarting                                   = errors.New("plugin restarting")
+	ErrInvalidNotifyRoutes                                = errors.New("invalid notify channel routes")
+	ErrNotifyChannelNotFound                              = errors.New("notify channel not found")
+	ErrInsufficientStock                                  = errors.New("insufficient stock")
+	ErrInvalidSSHKey                                      = errors.New("invalid ssh public key")
+	ErrSSHKeyExists                                       = errors.New("ssh key already exists")
+	ErrSSHKeyLimit                                        = errors.New("ssh key limit reached")
+	ErrSSHKeysNotSupported                                = errors.New("ssh keys not supported")
+	ErrCloudInitNotSupported                              = errors.New("cloud-init not supported")
+	ErrUserDataTooLarge                                   = errors.New("user data too large")
+	ErrInvalidAddon                                       = errors.New("invalid addon")
+	ErrSnapshotQuotaExceeded                              = errors.New("snapshot quota exceeded")
+	ErrBackupQuotaExceeded                                = errors.New("backup quota exceeded")
+	ErrInvalidBackupPolicy                                = errors.New("invalid backup policy")
+	ErrInvalidBulkAction                                  = errors.New("invalid bulk action")
+	ErrBulkFilterRequired                                 = errors.New("bulk filter required")
+	ErrNoBulkTargets                                      = errors.New("no instances match the bulk filter")
+	ErrTooManyBulkTargets                                 = errors.New("too many instances for one bulk job")
+	ErrBulkJobFinished                                    = errors.New("bulk job already finished")
+	ErrInvalidMigration                                   = errors.New("invalid migration target")
+	ErrMigrationInProgress                                = errors.New("migration already in progress")
+	ErrMigrationTransferUnsupported                       = errors.New("data transfer requires the same automation plugin")
+	ErrMigrationStateConflict                             = errors.New("migration cannot be changed in its current state")
+	ErrInvalidIPAddress                                   = errors.New("invalid ip address")
+	ErrIPAddressExists                                    = errors.New("ip address already exists")
+	ErrIPAddressInUse                                     = errors.New("ip address is assigned to an instance")
+	ErrIPAddressBlocked                                   = errors.New("ip address is blocked")
+	ErrInvalidPTR                                         = errors.New("invalid ptr record")
+	ErrReverseDNSNotSupported                             = errors.New("reverse dns not supported")
+	ErrTrialNotAvailable                                  = errors.New("trial not available for this package")
+	ErrTrialLimitReached                                  = errors.New("trial limit reached")
+	ErrTrialVerificationRequired                          = errors.New("real-name verification and phone required for trial")
+	ErrInvalidApprovalRule                                = errors.New("invalid order approval rule")
+	ErrOrderRejectedByPolicy                              = errors.New("order rejected by risk policy")
+	ErrOrderHeldForReview                                 = errors.New("order held for manual review")
+	ErrUnbalancedLedgerEntry                              = errors.New("unbalanced ledger entry")
+	ErrLedgerDrift                                        = errors.New("wallet balances drift from ledger")
+	ErrInvalidPayoutAccount                               = errors.New("invalid payout account")
+	ErrPayoutAccountNotVerified                           = errors.New("payout account not verified")
+	ErrPayoutAccountRequired                              = errors.New("a verified payout account is required for withdrawals")
+	ErrPayoutBatchSettled                                 = errors.New("payout batch already settled")
+	ErrNoPayableWithdrawals                               = errors.New("no approved withdrawals to pay out")
+	ErrInvalidFinanceReport                               = errors.New("invalid finance report definition")
+	ErrFinanceReportNotDelivered                          = errors.New("finance report not delivered")
+	ErrInvalidStatementRange                              = errors.New("invalid statement range")
+	ErrInvalidDunningPolicy                               = errors.New("invalid dunning policy")
+	ErrRealNameProvidersUnavailable                       = errors.New("real name providers unavailable")
+	ErrRealNameManualReviewDisabled                       = errors.New("manual real name review disabled")
+	ErrRealNameReviewInProgress                           = errors.New("real name review already in progress")
+	ErrRealNameNotPendingReview                           = errors.New("real name verification is not pending review")
+	ErrInvalidRealNameImages                              = errors.New("invalid real name id images")
+	ErrInvalidCompanyVerification                         = errors.New("invalid company verification")
+	ErrCompanyVerificationRequired                        = errors.New("company verification required")
+	ErrInvalidCompanyRequirement                          = errors.New("invalid company verification requirement")
+	ErrInvalidNotificationPreferences                     = errors.New("invalid notification preferences")
+	ErrInvalidBroadcast                                   = errors.New("invalid broadcast")
+	ErrBroadcastNotCancelable                             = errors.New("broadcast can no longer be canceled")
+	ErrPushNotConfigured                                  = errors.New("push not configured")
+	ErrPushTokenNotBound                                  = errors.New("push token not bound")
+	ErrNotificationMuted                                  = errors.New("muted by notification preferences")
 )
@@ -0,0 +1,96 @@
+package domain
+
+import "time"
+
+type BroadcastStatus string
+
+const (
+	BroadcastStatusScheduled BroadcastStatus = "scheduled"
+	BroadcastStatusSending   BroadcastStatus = "sending"
+	BroadcastStatusSent      BroadcastStatus = "sent"
+	BroadcastStatusCanceled  BroadcastStatus = "canceled"
+)
+
+type BroadcastDeliveryStatus string
+
+const (
+	BroadcastDeliveryPending BroadcastDeliveryStatus = "pending"
+	BroadcastDeliverySent    BroadcastDeliveryStatus = "sent"
+	BroadcastDeliveryFailed  BroadcastDeliveryStatus = "failed"
+	BroadcastDeliverySkipped BroadcastDeliveryStatus = "skipped"
+)
+
+// Realname filters of a broadcast segment.
+const (
+	BroadcastRealNameVerified   = "verified"
+	BroadcastRealNameUnverified = "unverified"
+)
+
+// BroadcastSegment selects the recipients of a broadcast. Empty fields do
+// not filter; the filters that are set must all match. Goods types and
+// regions match users who have an unexpired instance in them.
+type BroadcastSegment struct {
+	UserTierGroupIDs []int64
+	GoodsTypeIDs     []int64
+	RegionIDs        []int64
+	RealNameStatus   string
+	RegisteredFrom   *time.Time
+	RegisteredTo     *time.Time
+}
+
+// Broadcast is an announcement sent to a segment of users over the chosen
+// channels. The counters are refreshed as deliveries are processed.
+type Broadcast struct {
+	ID          int64
+	Title       string
+	Content     string
+	LinkURL     string
+	Channels    []string
+	Segment     BroadcastSegment
+	Status      BroadcastStatus
+	ScheduledAt time.Time
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+	Total       int
+	SentCount   int
+	FailedCount int
+	CreatedBy   int64
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// BroadcastDelivery is one recipient on one channel. Token identifies the
+// tracked link sent with it; NotificationID links in-app deliveries to the
+// message so reads can be counted.
+type BroadcastDelivery struct {
+	ID             int64
+	BroadcastID    int64
+	UserID         int64
+	Channel        string
+	Status         BroadcastDeliveryStatus
+	Error          string
+	Token          string
+	NotificationID int64
+	SentAt         *time.Time
+	ClickedAt      *time.Time
+	CreatedAt      time.Time
+}
+
+type BroadcastChannelStats struct {
+	Channel string
+	Total   int
+	Pending int
+	Sent    int
+	Failed  int
+	Skipped int
+	Clicked int
+}
+
+// BroadcastStats summarizes deliveries of a broadcast. Read counts in-app
+// messages the recipient opened; Clicked counts recipients who followed the
+// link on any channel.
+type BroadcastStats struct {
+	Channels []BroadcastChannelStats
+	Read     int
+	Clicked  int
+}
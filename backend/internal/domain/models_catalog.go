@@ -48,7 +48,13 @@ type PlanGroup struct {
 	Active            bool
 	Visible           bool
 	CapacityRemaining int
-	SortOrder         int
+	CapacityReserved  int
+	// SnapshotQuota/BackupQuota are the counts included with every instance
+	// of the group: 0 means unlimited, -1 means none unless bought as an
+	// addon.
+	SnapshotQuota int
+	BackupQuota   int
+	SortOrder     int
 }
 
 type Package struct {
@@ -69,6 +75,79 @@ type Package struct {
 	Active               bool
 	Visible              bool
 	CapacityRemaining    int
+	CapacityReserved     int
+	// TrialDays > 0 enables free trials of the package for that many days.
+	TrialDays int
+}
+
+// CapacityAvailable returns the stock left after local reservations, or -1
+// when the package is unlimited.
+func (p Package) CapacityAvailable() int {
+	return capacityAvailable(p.CapacityRemaining, p.CapacityReserved)
+}
+
+// CapacityAvailable returns the stock left after local reservations, or -1
+// when the plan group is unlimited.
+func (p PlanGroup) CapacityAvailable() int {
+	return capacityAvailable(p.CapacityRemaining, p.CapacityReserved)
+}
+
+func capacityAvailable(remaining, reserved int) int {
+	if remaining < 0 {
+		return -1
+	}
+	if remaining <= reserved {
+		return 0
+	}
+	return remaining - reserved
+}
+
+type AddonKind string
+
+const (
+	AddonKindIPv4          AddonKind = "ipv4"
+	AddonKindSnapshotQuota AddonKind = "snapshot_quota"
+	AddonKindBackupPlan    AddonKind = "backup_plan"
+)
+
+// Addon is a recurring SKU sold on top of a plan group's packages. Units is
+// what one quantity grants (addresses, snapshot slots or backup slots).
+type Addon struct {
+	ID          int64
+	PlanGroupID int64
+	Kind        AddonKind
+	Name        string
+	Description string
+	Monthly     int64
+	Units       int
+	MaxQty      int
+	Active      bool
+	SortOrder   int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type InventoryReservationStatus string
+
+const (
+	InventoryReservationReserved  InventoryReservationStatus = "reserved"
+	InventoryReservationCommitted InventoryReservationStatus = "committed"
+	InventoryReservationReleased  InventoryReservationStatus = "released"
+)
+
+// InventoryReservation holds stock of one package for one order item until the
+// host is created upstream (committed) or the order is abandoned (released).
+type InventoryReservation struct {
+	ID          int64
+	OrderID     int64
+	OrderItemID int64
+	PackageID   int64
+	PlanGroupID int64
+	Qty         int
+	Status      InventoryReservationStatus
+	ExpiresAt   *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
 type SystemImage struct {
@@ -110,6 +110,59 @@ type PushToken struct {
 	UpdatedAt time.Time
 }
 
+// Notification categories group notification types for user preferences.
+// Security and billing notices are mandatory and always delivered.
+const (
+	NotificationCategoryOrder     = "order"
+	NotificationCategoryExpiry    = "expiry"
+	NotificationCategoryTicket    = "ticket"
+	NotificationCategoryService   = "service"
+	NotificationCategoryMarketing = "marketing"
+	NotificationCategorySecurity  = "security"
+	NotificationCategoryBilling   = "billing"
+	NotificationCategorySystem    = "system"
+)
+
+const (
+	NotificationChannelInApp = "inapp"
+	NotificationChannelEmail = "email"
+	NotificationChannelSMS   = "sms"
+	NotificationChannelPush  = "push"
+)
+
+// NotificationPreferences holds what a user opted out of. Everything not
+// listed in Disabled is delivered. Quiet hours delay SMS and push until the
+// window ends in the user's time zone; QuietStart and QuietEnd are "HH:MM".
+type NotificationPreferences struct {
+	UserID            int64
+	Disabled          map[string][]string
+	QuietHoursEnabled bool
+	QuietStart        string
+	QuietEnd          string
+	Timezone          string
+	UpdatedAt         time.Time
+}
+
+// DeferredNotification is an SMS or push held back by quiet hours. It is sent
+// once SendAfter has passed; PayloadJSON holds the channel's message.
+type DeferredNotification struct {
+	ID          int64
+	UserID      int64
+	Channel     string
+	Type        string
+	PayloadJSON string
+	SendAfter   time.Time
+	CreatedAt   time.Time
+}
+
+// RealNameProviderManual is the provider of verifications submitted for
+// manual review with ID images.
+const RealNameProviderManual = "manual"
+
+// RealNameStatusPendingReview marks a manual verification waiting in the
+// admin review queue.
+const RealNameStatusPendingReview = "pending_review"
+
 type RealNameVerification struct {
 	ID         int64
 	UserID     int64
@@ -118,6 +171,34 @@ type RealNameVerification struct {
 	Status     string
 	Provider   string
 	Reason     string
+	UploadIDs  []int64
+	ReviewedBy *int64
+	ReviewedAt *time.Time
 	CreatedAt  time.Time
 	VerifiedAt *time.Time
 }
+
+// CompanyVerification records the business license, legal person and invoice
+// details of a company behind a user account.
+type CompanyVerification struct {
+	ID                  int64
+	UserID              int64
+	CompanyName         string
+	CreditCode          string
+	LegalPersonName     string
+	LegalPersonIDNumber string
+	InvoiceTitle        string
+	TaxNumber           string
+	InvoiceAddress      string
+	InvoicePhone        string
+	InvoiceBank         string
+	InvoiceBankAccount  string
+	UploadIDs           []int64
+	Status              string
+	Provider            string
+	Reason              string
+	ReviewedBy          *int64
+	ReviewedAt          *time.Time
+	CreatedAt           time.Time
+	VerifiedAt          *time.Time
+}
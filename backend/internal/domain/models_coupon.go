@@ -12,6 +12,7 @@ const (
 	CouponGroupScopePlanGroup       CouponGroupScope = "plan_group"
 	CouponGroupScopePackage         CouponGroupScope = "package"
 	CouponGroupScopeAddonConfig     CouponGroupScope = "addon_config"
+	CouponGroupScopeAddon           CouponGroupScope = "addon"
 
 	CouponRedemptionStatusApplied   string = "applied"
 	CouponRedemptionStatusConfirmed string = "confirmed"
@@ -41,6 +42,7 @@ type CouponProductRule struct {
 	RegionID         int64            `json:"region_id,omitempty"`
 	PlanGroupID      int64            `json:"plan_group_id,omitempty"`
 	PackageID        int64            `json:"package_id,omitempty"`
+	AddonID          int64            `json:"addon_id,omitempty"`
 	AddonCoreEnabled bool             `json:"addon_core_enabled,omitempty"`
 	AddonMemEnabled  bool             `json:"addon_mem_enabled,omitempty"`
 	AddonDiskEnabled bool             `json:"addon_disk_enabled,omitempty"`
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"strconv"
+	"time"
+)
+
+type DunningAction string
+
+const (
+	DunningActionRemind       DunningAction = "remind"
+	DunningActionSuspend      DunningAction = "suspend"
+	DunningActionFinalWarning DunningAction = "final_warning"
+	DunningActionTerminate    DunningAction = "terminate"
+)
+
+const (
+	DunningChannelEmail = "email"
+	DunningChannelSMS   = "sms"
+	DunningChannelInApp = "inapp"
+)
+
+type DunningStepStatus string
+
+const (
+	DunningStepSuccess DunningStepStatus = "success"
+	DunningStepFailed  DunningStepStatus = "failed"
+	DunningStepSkipped DunningStepStatus = "skipped"
+)
+
+// DunningStep runs OffsetDays after the instance expires; negative offsets
+// run before expiry. Channels are the notifications sent with the step.
+type DunningStep struct {
+	OffsetDays int
+	Action     DunningAction
+	Channels   []string
+}
+
+// Key identifies the step within a policy across edits.
+func (s DunningStep) Key() string {
+	return string(s.Action) + "@" + strconv.Itoa(s.OffsetDays)
+}
+
+// DunningPolicy is the schedule for one goods type; GoodsTypeID 0 applies to
+// goods types without their own policy.
+type DunningPolicy struct {
+	ID          int64
+	GoodsTypeID int64
+	Enabled     bool
+	Steps       []DunningStep
+	UpdatedBy   int64
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// DunningRecord is the outcome of one step for one instance. CycleExpireAt is
+// the expiry the step was scheduled against, so a renewal starts a new cycle.
+type DunningRecord struct {
+	ID            int64
+	InstanceID    int64
+	UserID        int64
+	GoodsTypeID   int64
+	CycleExpireAt time.Time
+	StepKey       string
+	Action        DunningAction
+	OffsetDays    int
+	Status        DunningStepStatus
+	Channels      []string
+	Error         string
+	Attempts      int
+	ExecutedAt    time.Time
+}
@@ -0,0 +1,73 @@
+package domain
+
+import "time"
+
+type FinanceReportPeriod string
+
+const (
+	FinanceReportDaily   FinanceReportPeriod = "daily"
+	FinanceReportWeekly  FinanceReportPeriod = "weekly"
+	FinanceReportMonthly FinanceReportPeriod = "monthly"
+)
+
+const (
+	FinanceReportFormatCSV  = "csv"
+	FinanceReportFormatXLSX = "xlsx"
+)
+
+type FinanceReportRunStatus string
+
+const (
+	FinanceReportRunSuccess        FinanceReportRunStatus = "success"
+	FinanceReportRunDeliveryFailed FinanceReportRunStatus = "delivery_failed"
+	FinanceReportRunFailed         FinanceReportRunStatus = "failed"
+)
+
+const (
+	FinanceReportTriggerSchedule = "schedule"
+	FinanceReportTriggerManual   = "manual"
+)
+
+// FinanceReportDefinition describes a recurring close package: which period
+// it covers, which file formats are attached and who receives it.
+type FinanceReportDefinition struct {
+	ID         int64
+	Name       string
+	Period     FinanceReportPeriod
+	Formats    []string
+	Recipients []string
+	Enabled    bool
+	CreatedBy  int64
+	LastRunAt  *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// FinanceReportRun is one generated close package. Files are kept so the
+// package can be downloaded or resent later.
+type FinanceReportRun struct {
+	ID           int64
+	DefinitionID int64
+	Name         string
+	Period       FinanceReportPeriod
+	PeriodStart  time.Time
+	PeriodEnd    time.Time
+	Trigger      string
+	Status       FinanceReportRunStatus
+	Error        string
+	Recipients   []string
+	CreatedBy    int64
+	DeliveredAt  *time.Time
+	CreatedAt    time.Time
+	Files        []FinanceReportFile
+}
+
+type FinanceReportFile struct {
+	ID          int64
+	RunID       int64
+	Name        string
+	ContentType string
+	Size        int64
+	Content     []byte
+	CreatedAt   time.Time
+}
@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+type LedgerAccountType string
+
+const (
+	LedgerAsset     LedgerAccountType = "asset"
+	LedgerLiability LedgerAccountType = "liability"
+	LedgerRevenue   LedgerAccountType = "revenue"
+	LedgerEquity    LedgerAccountType = "equity"
+)
+
+// Fixed ledger accounts. User wallets and payment gateways get one account
+// each, see LedgerWalletAccount and LedgerGatewayAccount.
+const (
+	LedgerAccountRevenue        = "revenue"
+	LedgerAccountRefundsPayable = "refunds_payable"
+	LedgerAccountAdjustments    = "adjustments"
+	LedgerAccountOpening        = "opening_balance"
+
+	ledgerWalletPrefix  = "wallet:"
+	ledgerGatewayPrefix = "gateway:"
+)
+
+const (
+	LedgerKindRecharge         = "recharge"
+	LedgerKindWithdraw         = "withdraw"
+	LedgerKindOrderPayment     = "order_payment"
+	LedgerKindRefundAccrual    = "refund_accrual"
+	LedgerKindRefundSettlement = "refund_settlement"
+	LedgerKindRefundReversal   = "refund_reversal"
+	LedgerKindAdjustment       = "adjustment"
+	LedgerKindOpeningBalance   = "opening_balance"
+	LedgerKindPayoutReversal   = "payout_reversal"
+)
+
+// LedgerWalletAccount is the liability account holding a user's wallet.
+func LedgerWalletAccount(userID int64) string {
+	return ledgerWalletPrefix + strconv.FormatInt(userID, 10)
+}
+
+// LedgerGatewayAccount is the clearing account of a payment provider;
+// payments without a provider clear through "manual".
+func LedgerGatewayAccount(provider string) string {
+	provider = strings.TrimSpace(provider)
+	if provider == "" {
+		provider = "manual"
+	}
+	return ledgerGatewayPrefix + provider
+}
+
+// LedgerWalletUserID returns the user of a wallet account code.
+func LedgerWalletUserID(account string) (int64, bool) {
+	if !strings.HasPrefix(account, ledgerWalletPrefix) {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(strings.TrimPrefix(account, ledgerWalletPrefix), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+func LedgerAccountTypeOf(account string) LedgerAccountType {
+	switch {
+	case strings.HasPrefix(account, ledgerWalletPrefix), account == LedgerAccountRefundsPayable:
+		return LedgerLiability
+	case strings.HasPrefix(account, ledgerGatewayPrefix):
+		return LedgerAsset
+	case account == LedgerAccountRevenue:
+		return LedgerRevenue
+	default:
+		return LedgerEquity
+	}
+}
+
+// LedgerEntry is one balanced journal entry. Key makes posting idempotent:
+// an entry whose key already exists is not posted again.
+type LedgerEntry struct {
+	ID        int64
+	Key       string
+	Kind      string
+	RefType   string
+	RefID     int64
+	UserID    int64
+	Memo      string
+	CreatedAt time.Time
+	Lines     []LedgerLine
+}
+
+type LedgerLine struct {
+	ID      int64
+	EntryID int64
+	Account string
+	Debit   int64
+	Credit  int64
+}
+
+// NewLedgerTransfer builds a two-line entry moving amount from credit to
+// debit, keyed by kind and reference.
+func NewLedgerTransfer(kind, refType string, refID, userID int64, debit, credit string, amount int64, memo string) LedgerEntry {
+	return LedgerEntry{
+		Key:     kind + ":" + refType + ":" + strconv.FormatInt(refID, 10),
+		Kind:    kind,
+		RefType: refType,
+		RefID:   refID,
+		UserID:  userID,
+		Memo:    memo,
+		Lines: []LedgerLine{
+			{Account: debit, Debit: amount},
+			{Account: credit, Credit: amount},
+		},
+	}
+}
+
+// Balanced reports whether the entry has lines, no negative amounts and
+// equal debit and credit totals.
+func (e LedgerEntry) Balanced() bool {
+	if len(e.Lines) < 2 {
+		return false
+	}
+	var debit, credit int64
+	for _, line := range e.Lines {
+		if line.Debit < 0 || line.Credit < 0 || strings.TrimSpace(line.Account) == "" {
+			return false
+		}
+		debit += line.Debit
+		credit += line.Credit
+	}
+	return debit > 0 && debit == credit
+}
+
+type LedgerAccountBalance struct {
+	Account string
+	Type    LedgerAccountType
+	Debit   int64
+	Credit  int64
+}
+
+// Balance is the account balance on its normal side: debit minus credit for
+// assets, credit minus debit otherwise.
+func (b LedgerAccountBalance) Balance() int64 {
+	if b.Type == LedgerAsset {
+		return b.Debit - b.Credit
+	}
+	return b.Credit - b.Debit
+}
+
+type LedgerDrift struct {
+	UserID        int64
+	WalletBalance int64
+	LedgerBalance int64
+}
+
+type LedgerVerification struct {
+	CheckedAt         time.Time
+	Wallets           int
+	TotalDebit        int64
+	TotalCredit       int64
+	Drifts            []LedgerDrift
+	UnbalancedEntries []int64
+}
+
+func (v LedgerVerification) OK() bool {
+	return len(v.Drifts) == 0 && len(v.UnbalancedEntries) == 0 && v.TotalDebit == v.TotalCredit
+}
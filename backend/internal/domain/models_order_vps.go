@@ -123,6 +123,14 @@ type ProvisionJob struct {
 	UpdatedAt   time.Time
 }
 
+// ProvisionJobStat aggregates provision jobs sharing one status.
+type ProvisionJobStat struct {
+	Status      string
+	Count       int64
+	Attempts    int64
+	MaxAttempts int
+}
+
 type ResizeTask struct {
 	ID          int64
 	VPSID       int64
@@ -135,3 +143,298 @@ type ResizeTask struct {
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
+
+type VPSBackupPolicyKind string
+
+const (
+	VPSBackupPolicyKindSnapshot VPSBackupPolicyKind = "snapshot"
+	VPSBackupPolicyKindBackup   VPSBackupPolicyKind = "backup"
+)
+
+// VPSBackupPolicy schedules snapshots or backups for one instance. Weekday is
+// -1 for a daily run, otherwise 0 (Sunday) to 6; RunAt is "HH:MM" in server
+// local time. Retention is how many snapshots or backups are kept.
+type VPSBackupPolicy struct {
+	ID         int64
+	VPSID      int64
+	UserID     int64
+	Kind       VPSBackupPolicyKind
+	Enabled    bool
+	Weekday    int
+	RunAt      string
+	Retention  int
+	NextRunAt  time.Time
+	LastRunAt  *time.Time
+	LastStatus string
+	LastError  string
+	// ManagedIDs are the snapshots or backups this policy created; retention
+	// only ever prunes these.
+	ManagedIDs []int64
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type VPSBulkAction string
+
+const (
+	VPSBulkActionReboot         VPSBulkAction = "reboot"
+	VPSBulkActionLock           VPSBulkAction = "lock"
+	VPSBulkActionUnlock         VPSBulkAction = "unlock"
+	VPSBulkActionExtendExpire   VPSBulkAction = "extend_expire"
+	VPSBulkActionSetAdminStatus VPSBulkAction = "set_admin_status"
+)
+
+type VPSBulkJobStatus string
+
+const (
+	VPSBulkJobStatusRunning   VPSBulkJobStatus = "running"
+	VPSBulkJobStatusCompleted VPSBulkJobStatus = "completed"
+	VPSBulkJobStatusCanceled  VPSBulkJobStatus = "canceled"
+)
+
+type VPSBulkItemStatus string
+
+const (
+	VPSBulkItemStatusPending  VPSBulkItemStatus = "pending"
+	VPSBulkItemStatusSuccess  VPSBulkItemStatus = "success"
+	VPSBulkItemStatusFailed   VPSBulkItemStatus = "failed"
+	VPSBulkItemStatusCanceled VPSBulkItemStatus = "canceled"
+)
+
+// VPSBulkFilter selects the instances of a bulk job. IDs, when set, wins over
+// the other fields, which are combined with AND.
+type VPSBulkFilter struct {
+	IDs       []int64 `json:"ids,omitempty"`
+	LineID    int64   `json:"line_id,omitempty"`
+	RegionID  int64   `json:"region_id,omitempty"`
+	PackageID int64   `json:"package_id,omitempty"`
+	UserID    int64   `json:"user_id,omitempty"`
+}
+
+// VPSBulkJob is one admin operation applied to many instances.
+type VPSBulkJob struct {
+	ID          int64
+	AdminID     int64
+	Action      VPSBulkAction
+	ParamsJSON  string
+	FilterJSON  string
+	Concurrency int
+	Status      VPSBulkJobStatus
+	Total       int
+	Succeeded   int
+	Failed      int
+	Canceled    int
+	CreatedAt   time.Time
+	FinishedAt  *time.Time
+	UpdatedAt   time.Time
+}
+
+// VPSBulkJobItem is the result for one instance. Seq orders finished items
+// within the job and is 0 while the item is pending.
+type VPSBulkJobItem struct {
+	ID         int64
+	JobID      int64
+	VPSID      int64
+	Status     VPSBulkItemStatus
+	Error      string
+	Seq        int64
+	FinishedAt *time.Time
+}
+
+type VPSMigrationStatus string
+
+const (
+	VPSMigrationStatusRunning   VPSMigrationStatus = "running"
+	VPSMigrationStatusCompleted VPSMigrationStatus = "completed"
+	VPSMigrationStatusFailed    VPSMigrationStatus = "failed"
+	VPSMigrationStatusCanceled  VPSMigrationStatus = "canceled"
+)
+
+// VPSMigrationStep is the next piece of work a running migration has to do.
+// Steps always run in this order; transfer is skipped unless TransferData.
+type VPSMigrationStep string
+
+const (
+	VPSMigrationStepProvision VPSMigrationStep = "provision"
+	VPSMigrationStepTransfer  VPSMigrationStep = "transfer"
+	VPSMigrationStepSwitch    VPSMigrationStep = "switch"
+	VPSMigrationStepCleanup   VPSMigrationStep = "cleanup"
+	VPSMigrationStepDone      VPSMigrationStep = "done"
+)
+
+// VPSMigration moves one instance to another automation line or plugin. The
+// source host keeps serving until the switch step rewrites the local record.
+type VPSMigration struct {
+	ID                int64
+	VPSID             int64
+	UserID            int64
+	AdminID           int64
+	SourceGoodsTypeID int64
+	SourceInstanceID  string
+	SourceLineID      int64
+	SourcePackageID   int64
+	TargetGoodsTypeID int64
+	TargetLineID      int64
+	TargetRegionID    int64
+	TargetPackageID   int64
+	TargetSystemID    int64
+	TargetInstanceID  string
+	TargetAccessJSON  string
+	TargetHostName    string
+	CreateRequestedAt *time.Time
+	TransferData      bool
+	BackupID          int64
+	BackupRequestedAt *time.Time
+	Status            VPSMigrationStatus
+	Step              VPSMigrationStep
+	Attempts          int
+	MaxAttempts       int
+	LastError         string
+	NextRunAt         time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	FinishedAt        *time.Time
+}
+
+type IPAddressStatus string
+
+const (
+	IPAddressStatusAvailable IPAddressStatus = "available"
+	IPAddressStatusAssigned  IPAddressStatus = "assigned"
+	IPAddressStatusReserved  IPAddressStatus = "reserved"
+	IPAddressStatusBlocked   IPAddressStatus = "blocked"
+)
+
+// IPAddress is one address of a line's pool. Addresses are usually created by
+// syncing automation instance info; VPSID is 0 while the address is free.
+type IPAddress struct {
+	ID        int64
+	Address   string
+	Version   int
+	LineID    int64
+	VPSID     int64
+	UserID    int64
+	Primary   bool
+	PTR       string
+	Status    IPAddressStatus
+	Note      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type IPAddressEventType string
+
+const (
+	IPAddressEventAssigned IPAddressEventType = "assigned"
+	IPAddressEventReleased IPAddressEventType = "released"
+	IPAddressEventPTR      IPAddressEventType = "ptr"
+	IPAddressEventStatus   IPAddressEventType = "status"
+	IPAddressEventAbuse    IPAddressEventType = "abuse"
+)
+
+// IPAddressEvent records allocation and abuse history. VPSID and UserID are
+// the holders at the time of the event, so history survives reassignment.
+type IPAddressEvent struct {
+	ID          int64
+	IPAddressID int64
+	Address     string
+	Type        IPAddressEventType
+	VPSID       int64
+	UserID      int64
+	AdminID     int64
+	Detail      string
+	CreatedAt   time.Time
+}
+
+type VPSTrialStatus string
+
+const (
+	VPSTrialStatusActive    VPSTrialStatus = "active"
+	VPSTrialStatusConverted VPSTrialStatus = "converted"
+	VPSTrialStatusExpired   VPSTrialStatus = "expired"
+)
+
+// VPSTrial is a free trial of a package. IdentityHash is the SHA-256 of the
+// verified ID number so the raw number never leaves the realname records; it
+// and Phone, DeviceID and ClientIP are the keys the abuse limits count on.
+type VPSTrial struct {
+	ID           int64
+	UserID       int64
+	PackageID    int64
+	OrderID      int64
+	OrderItemID  int64
+	Days         int
+	IdentityHash string
+	Phone        string
+	ClientIP     string
+	DeviceID     string
+	Status       VPSTrialStatus
+	ExpireAt     time.Time
+	ConvertedAt  *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// VPSTrialStat aggregates the trials of one package started in a period.
+type VPSTrialStat struct {
+	PackageID int64
+	Total     int
+	Active    int
+	Converted int
+	Expired   int
+}
+
+type OrderApprovalDecision string
+
+const (
+	OrderApprovalApprove OrderApprovalDecision = "approve"
+	OrderApprovalReview  OrderApprovalDecision = "review"
+	OrderApprovalReject  OrderApprovalDecision = "reject"
+)
+
+// OrderApprovalRule is a risk rule evaluated when a purchase order is
+// created. Zero-valued conditions are ignored and the rest must all match;
+// a rule without conditions matches every order. Countries holds ISO 3166
+// codes resolved from the client IP, "ZZ" standing for an unknown country.
+type OrderApprovalRule struct {
+	ID                 int64
+	Name               string
+	Priority           int
+	Enabled            bool
+	Decision           OrderApprovalDecision
+	MinAmount          int64
+	MaxAccountAgeDays  int
+	RealNameUnverified bool
+	Countries          []string
+	MinInstances       int
+	MinRecentRefunds   int
+	Reason             string
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// OrderApprovalFacts are the order and account attributes the rules match
+// against, captured at order time.
+type OrderApprovalFacts struct {
+	Amount           int64
+	AccountAgeDays   int
+	RealNameVerified bool
+	ClientIP         string
+	Country          string
+	Instances        int
+	RecentRefunds    int
+}
+
+// OrderApprovalEvaluation records the decision taken for an order. RuleID is
+// 0 when no rule matched and the order was approved by default.
+type OrderApprovalEvaluation struct {
+	ID        int64
+	OrderID   int64
+	UserID    int64
+	Decision  OrderApprovalDecision
+	RuleID    int64
+	RuleName  string
+	Reason    string
+	Facts     OrderApprovalFacts
+	CreatedAt time.Time
+}
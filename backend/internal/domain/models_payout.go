@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+type PayoutChannel string
+
+const (
+	PayoutChannelBank   PayoutChannel = "bank"
+	PayoutChannelAlipay PayoutChannel = "alipay"
+)
+
+type PayoutAccountStatus string
+
+const (
+	PayoutAccountPending  PayoutAccountStatus = "pending"
+	PayoutAccountVerified PayoutAccountStatus = "verified"
+	PayoutAccountRejected PayoutAccountStatus = "rejected"
+)
+
+// PayoutAccount is where a user's withdrawals are paid to. Only verified
+// accounts can receive withdrawals.
+type PayoutAccount struct {
+	ID           int64
+	UserID       int64
+	Channel      PayoutChannel
+	AccountName  string
+	AccountNo    string
+	BankName     string
+	BankBranch   string
+	Status       PayoutAccountStatus
+	ReviewReason string
+	ReviewedBy   *int64
+	ReviewedAt   *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// MaskedAccountNo keeps the last four characters of the account number, or
+// for Alipay email accounts the first character and the domain.
+func (a PayoutAccount) MaskedAccountNo() string {
+	no := a.AccountNo
+	if at := strings.Index(no, "@"); at > 0 {
+		return no[:1] + "***" + no[at:]
+	}
+	if len(no) <= 4 {
+		return no
+	}
+	return strings.Repeat("*", len(no)-4) + no[len(no)-4:]
+}
+
+type PayoutBatchStatus string
+
+const (
+	PayoutBatchPending PayoutBatchStatus = "pending"
+	PayoutBatchPaid    PayoutBatchStatus = "paid"
+	PayoutBatchPartial PayoutBatchStatus = "partial"
+	PayoutBatchFailed  PayoutBatchStatus = "failed"
+)
+
+type PayoutItemStatus string
+
+const (
+	PayoutItemPending PayoutItemStatus = "pending"
+	PayoutItemPaid    PayoutItemStatus = "paid"
+	PayoutItemFailed  PayoutItemStatus = "failed"
+)
+
+// PayoutBatch groups approved withdrawals of one channel that are paid out
+// together, typically through one bank bulk transfer file.
+type PayoutBatch struct {
+	ID          int64
+	Channel     PayoutChannel
+	Status      PayoutBatchStatus
+	ItemCount   int
+	TotalAmount int64
+	Reference   string
+	Note        string
+	CreatedBy   int64
+	ExportedAt  *time.Time
+	SettledAt   *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Items       []PayoutBatchItem
+}
+
+// PayoutBatchItem is one withdrawal in a batch. The payee fields are a
+// snapshot of the payout account when the batch was created.
+type PayoutBatchItem struct {
+	ID              int64
+	BatchID         int64
+	WalletOrderID   int64
+	UserID          int64
+	Amount          int64
+	PayoutAccountID int64
+	AccountName     string
+	AccountNo       string
+	BankName        string
+	BankBranch      string
+	Status          PayoutItemStatus
+	Reference       string
+	FailReason      string
+	SettledAt       *time.Time
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+type StatementEntryKind string
+
+const (
+	StatementEntryOrder   StatementEntryKind = "order"
+	StatementEntryPayment StatementEntryKind = "payment"
+	StatementEntryWallet  StatementEntryKind = "wallet"
+	StatementEntryRefund  StatementEntryKind = "refund"
+	StatementEntryCoupon  StatementEntryKind = "coupon"
+)
+
+const (
+	StatementFormatCSV = "csv"
+	StatementFormatPDF = "pdf"
+)
+
+// StatementEntry is one line of a customer statement. Only wallet entries
+// move the wallet balance; Balance is set on those and is the balance after
+// the entry. Orders, payments, refunds and coupons are listed for reference.
+type StatementEntry struct {
+	Time         time.Time
+	Kind         StatementEntryKind
+	Ref          string
+	Description  string
+	Status       string
+	Amount       int64
+	WalletChange int64
+	Balance      *int64
+}
+
+type StatementTotals struct {
+	Orders         int64
+	Paid           int64
+	Refunds        int64
+	CouponDiscount int64
+	WalletIn       int64
+	WalletOut      int64
+}
+
+// Statement covers [From, To) for one user.
+type Statement struct {
+	UserID         int64
+	Username       string
+	Email          string
+	From           time.Time
+	To             time.Time
+	GeneratedAt    time.Time
+	OpeningBalance int64
+	ClosingBalance int64
+	Totals         StatementTotals
+	Entries        []StatementEntry
+}
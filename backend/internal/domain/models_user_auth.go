@@ -188,6 +188,16 @@ type UserAPIKey struct {
 	LastUsedAt *time.Time
 }
 
+// SSHKey is an OpenSSH public key a user keeps for provisioning and reinstall.
+type SSHKey struct {
+	ID          int64
+	UserID      int64
+	Name        string
+	PublicKey   string
+	Fingerprint string
+	CreatedAt   time.Time
+}
+
 type RequestActor struct {
 	Mode          RequestActorMode
 	UserID        int64
@@ -12,6 +12,7 @@ const (
 	UserTierScopePlanGroup         UserTierScope = "plan_group"
 	UserTierScopePackage           UserTierScope = "package"
 	UserTierScopeAddonConfig       UserTierScope = "addon_config"
+	UserTierScopeAddon             UserTierScope = "addon"
 	UserTierMembershipSourceAuto   string        = "auto"
 	UserTierMembershipSourceManual string        = "manual"
 )
@@ -36,6 +37,7 @@ type UserTierDiscountRule struct {
 	RegionID         int64
 	PlanGroupID      int64
 	PackageID        int64
+	AddonID          int64
 	DiscountPermille int
 	FixedPrice       *int64
 	AddCorePermille  int
@@ -3,10 +3,47 @@ package domain
 import "time"
 
 type Wallet struct {
+	ID      int64
+	UserID  int64
+	Balance int64
+	// Frozen is the sum of active holds; it stays part of Balance until the
+	// hold is captured or released.
+	Frozen    int64
+	UpdatedAt time.Time
+}
+
+// Available is the part of the balance that can still be spent or held.
+func (w Wallet) Available() int64 {
+	return w.Balance - w.Frozen
+}
+
+type WalletHoldStatus string
+
+const (
+	WalletHoldActive   WalletHoldStatus = "active"
+	WalletHoldCaptured WalletHoldStatus = "captured"
+	WalletHoldReleased WalletHoldStatus = "released"
+)
+
+// WalletHold reserves part of a wallet balance for a pending withdrawal or
+// an in-flight balance payment, identified by RefType and RefID.
+type WalletHold struct {
 	ID        int64
 	UserID    int64
-	Balance   int64
-	UpdatedAt time.Time
+	Amount    int64
+	RefType   string
+	RefID     int64
+	Status    WalletHoldStatus
+	Note      string
+	CreatedAt time.Time
+	SettledAt *time.Time
+}
+
+// WalletTotals sums balances across all wallets, in cents.
+type WalletTotals struct {
+	Wallets       int64
+	Balance       int64
+	NegativeCount int64
 }
 
 type WalletTransaction struct {
@@ -42,6 +79,16 @@ const (
 	ProbeStatusOnline  ProbeStatus = "online"
 )
 
+type ProbeAgentUpdateStatus string
+
+const (
+	ProbeAgentUpdateDownloading ProbeAgentUpdateStatus = "downloading"
+	ProbeAgentUpdateRestarting  ProbeAgentUpdateStatus = "restarting"
+	ProbeAgentUpdateApplied     ProbeAgentUpdateStatus = "applied"
+	ProbeAgentUpdateFailed      ProbeAgentUpdateStatus = "failed"
+	ProbeAgentUpdateRolledBack  ProbeAgentUpdateStatus = "rolled_back"
+)
+
 type ProbeNode struct {
 	ID               int64
 	Name             string
@@ -53,8 +100,25 @@ type ProbeNode struct {
 	LastHeartbeatAt  *time.Time
 	LastSnapshotAt   *time.Time
 	LastSnapshotJSON string
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
+	// AgentVersion is reported by pingbot in its hello message.
+	AgentVersion       string
+	AgentUpdateStatus  ProbeAgentUpdateStatus
+	AgentUpdateMessage string
+	AgentUpdatedAt     *time.Time
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+// ProbeAgentRelease is a signed pingbot release hosted by the backend.
+// Releases use the plugin package layout: manifest.json, bin/<os>_<arch>/pingbot,
+// checksums.json and signature.sig produced by cmd/tools/pluginsign.
+type ProbeAgentRelease struct {
+	Version         string
+	Platforms       []string
+	SignatureStatus PluginSignatureStatus
+	Valid           bool
+	Error           string
+	CreatedAt       time.Time
 }
 
 type ProbeEnrollToken struct {
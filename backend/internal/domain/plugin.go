@@ -32,3 +32,25 @@ type PluginPaymentMethod struct {
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
 }
+
+const (
+	PluginHealthCrashed       = "crashed"
+	PluginHealthRestarted     = "restarted"
+	PluginHealthRestartFailed = "restart_failed"
+	PluginHealthCircuitOpen   = "circuit_open"
+	PluginHealthCircuitClosed = "circuit_closed"
+	PluginHealthUnhealthy     = "unhealthy"
+	PluginHealthRecovered     = "recovered"
+)
+
+// PluginHealthEvent is emitted by the plugin supervisor whenever a running
+// instance changes state.
+type PluginHealthEvent struct {
+	Category   string
+	PluginID   string
+	InstanceID string
+	Kind       string
+	Message    string
+	Restarts   int
+	At         time.Time
+}
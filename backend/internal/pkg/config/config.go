@@ -26,6 +26,7 @@ type Config struct {
 	PluginMasterKey    string
 	PluginOfficialKeys []string
 	PluginsDir         string
+	ProbeReleasesDir   string
 	APIBase            string
 	SiteName           string
 	SiteURL            string
@@ -44,6 +45,7 @@ type fileConfig struct {
 	PluginMasterKey    string   `json:"plugin_master_key" yaml:"plugin_master_key"`
 	PluginOfficialKeys []string `json:"plugin_official_ed25519_pubkeys" yaml:"plugin_official_ed25519_pubkeys"`
 	PluginsDir         string   `json:"plugins_dir" yaml:"plugins_dir"`
+	ProbeReleasesDir   string   `json:"probe_releases_dir" yaml:"probe_releases_dir"`
 
 	DB struct {
 		Type string `json:"type" yaml:"type"`
@@ -62,6 +64,7 @@ func Load() Config {
 		PluginMasterKey:    "",
 		PluginOfficialKeys: nil,
 		PluginsDir:         "plugins",
+		ProbeReleasesDir:   "probe-releases",
 		APIBase:            "http://localhost:8080",
 		SiteName:           "",
 		SiteURL:            "",
@@ -91,6 +94,9 @@ func Load() Config {
 		if strings.TrimSpace(cfg.PluginsDir) != "" && !filepath.IsAbs(cfg.PluginsDir) {
 			cfg.PluginsDir = filepath.Join(cfg.ConfigDir, cfg.PluginsDir)
 		}
+		if strings.TrimSpace(cfg.ProbeReleasesDir) != "" && !filepath.IsAbs(cfg.ProbeReleasesDir) {
+			cfg.ProbeReleasesDir = filepath.Join(cfg.ConfigDir, cfg.ProbeReleasesDir)
+		}
 	}
 
 	if strings.TrimSpace(cfg.JWTSecret) == "" {
@@ -304,6 +310,9 @@ func applyFileConfig(cfg *Config, fc *fileConfig) {
 	if strings.TrimSpace(fc.PluginsDir) != "" {
 		cfg.PluginsDir = strings.TrimSpace(fc.PluginsDir)
 	}
+	if strings.TrimSpace(fc.ProbeReleasesDir) != "" {
+		cfg.ProbeReleasesDir = strings.TrimSpace(fc.ProbeReleasesDir)
+	}
 	if strings.TrimSpace(fc.DB.Type) != "" {
 		cfg.DBType = strings.TrimSpace(fc.DB.Type)
 	}
@@ -343,6 +352,9 @@ func applyEnvConfig(cfg *Config) {
 	if v, ok := getEnvTrimmed("APP_PLUGINS_DIR"); ok {
 		cfg.PluginsDir = v
 	}
+	if v, ok := getEnvTrimmed("APP_PROBE_RELEASES_DIR"); ok {
+		cfg.ProbeReleasesDir = v
+	}
 }
 
 func getEnvTrimmed(key string) (string, bool) {
@@ -0,0 +1,233 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContentType is the OpenMetrics 1.0 text exposition format.
+const ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// Namespace prefixes every metric family exposed by the backend.
+const Namespace = "xiaoheiplay_"
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+func L(name, value string) Label {
+	return Label{Name: name, Value: value}
+}
+
+// Writer emits OpenMetrics text. Families must be declared before their samples
+// and EOF must be called exactly once at the end.
+type Writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Family declares a metric family; typ is "counter", "gauge" or "histogram".
+func (w *Writer) Family(name, typ, help string) {
+	w.write("# TYPE ", Namespace+name, " ", typ, "\n")
+	w.write("# HELP ", Namespace+name, " ", escapeHelp(help), "\n")
+}
+
+// Sample writes one sample. name must include any suffix (_total, _bucket, ...).
+func (w *Writer) Sample(name string, value float64, labels ...Label) {
+	var b strings.Builder
+	b.WriteString(Namespace)
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(l.Name)
+			b.WriteString(`="`)
+			b.WriteString(escapeLabelValue(l.Value))
+			b.WriteByte('"')
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatFloat(value))
+	b.WriteByte('\n')
+	w.write(b.String())
+}
+
+func (w *Writer) EOF() error {
+	w.write("# EOF\n")
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+func (w *Writer) write(parts ...string) {
+	if w.err != nil {
+		return
+	}
+	for _, p := range parts {
+		if _, err := w.w.WriteString(p); err != nil {
+			w.err = err
+			return
+		}
+	}
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(s)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func Bool(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+// DefaultLatencyBuckets are upper bounds in seconds for HTTP latency histograms.
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type httpCountKey struct {
+	method string
+	route  string
+	code   string
+}
+
+type httpLatencyKey struct {
+	method string
+	route  string
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// HTTPRecorder keeps in-process request counters and latency histograms keyed by
+// route template, so cardinality is bounded by the number of registered routes.
+type HTTPRecorder struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  map[httpCountKey]uint64
+	latency map[httpLatencyKey]*histogram
+}
+
+func NewHTTPRecorder() *HTTPRecorder {
+	return &HTTPRecorder{
+		buckets: DefaultLatencyBuckets,
+		counts:  make(map[httpCountKey]uint64),
+		latency: make(map[httpLatencyKey]*histogram),
+	}
+}
+
+func (r *HTTPRecorder) Observe(method, route string, status int, d time.Duration) {
+	if r == nil {
+		return
+	}
+	if route == "" {
+		route = "unmatched"
+	}
+	seconds := d.Seconds()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counts[httpCountKey{method: method, route: route, code: strconv.Itoa(status)}]++
+	lk := httpLatencyKey{method: method, route: route}
+	h := r.latency[lk]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(r.buckets))}
+		r.latency[lk] = h
+	}
+	for i, le := range r.buckets {
+		if seconds <= le {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+func (r *HTTPRecorder) Write(w *Writer) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	countKeys := make([]httpCountKey, 0, len(r.counts))
+	for k := range r.counts {
+		countKeys = append(countKeys, k)
+	}
+	counts := make(map[httpCountKey]uint64, len(r.counts))
+	for k, v := range r.counts {
+		counts[k] = v
+	}
+	latencyKeys := make([]httpLatencyKey, 0, len(r.latency))
+	latency := make(map[httpLatencyKey]histogram, len(r.latency))
+	for k, h := range r.latency {
+		latencyKeys = append(latencyKeys, k)
+		latency[k] = histogram{counts: append([]uint64(nil), h.counts...), count: h.count, sum: h.sum}
+	}
+	r.mu.Unlock()
+
+	sort.Slice(countKeys, func(i, j int) bool {
+		a, b := countKeys[i], countKeys[j]
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.code < b.code
+	})
+	sort.Slice(latencyKeys, func(i, j int) bool {
+		a, b := latencyKeys[i], latencyKeys[j]
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		return a.method < b.method
+	})
+
+	w.Family("http_requests", "counter", "HTTP requests by route template, method and status code.")
+	for _, k := range countKeys {
+		w.Sample("http_requests_total", float64(counts[k]), L("method", k.method), L("route", k.route), L("code", k.code))
+	}
+	w.Family("http_request_duration_seconds", "histogram", "HTTP request latency by route template and method.")
+	for _, k := range latencyKeys {
+		h := latency[k]
+		for i, le := range r.buckets {
+			w.Sample("http_request_duration_seconds_bucket", float64(h.counts[i]), L("method", k.method), L("route", k.route), L("le", formatFloat(le)))
+		}
+		w.Sample("http_request_duration_seconds_bucket", float64(h.count), L("method", k.method), L("route", k.route), L("le", "+Inf"))
+		w.Sample("http_request_duration_seconds_count", float64(h.count), L("method", k.method), L("route", k.route))
+		w.Sample("http_request_duration_seconds_sum", h.sum, L("method", k.method), L("route", k.route))
+	}
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriterEscapesAndTerminates(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Family("demo", "gauge", "line one\nline two")
+	w.Sample("demo", 1.5, L("name", `a"b\c`))
+	if err := w.EOF(); err != nil {
+		t.Fatalf("eof: %v", err)
+	}
+	want := "# TYPE xiaoheiplay_demo gauge\n" +
+		"# HELP xiaoheiplay_demo line one\\nline two\n" +
+		"xiaoheiplay_demo{name=\"a\\\"b\\\\c\"} 1.5\n" +
+		"# EOF\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestHTTPRecorderHistogram(t *testing.T) {
+	r := NewHTTPRecorder()
+	r.Observe("GET", "/api/v1/orders/:id", 200, 20*time.Millisecond)
+	r.Observe("GET", "/api/v1/orders/:id", 404, 2*time.Second)
+	r.Observe("GET", "", 404, time.Millisecond)
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	r.Write(w)
+	if err := w.EOF(); err != nil {
+		t.Fatalf("eof: %v", err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		`xiaoheiplay_http_requests_total{method="GET",route="/api/v1/orders/:id",code="200"} 1`,
+		`xiaoheiplay_http_requests_total{method="GET",route="/api/v1/orders/:id",code="404"} 1`,
+		`xiaoheiplay_http_requests_total{method="GET",route="unmatched",code="404"} 1`,
+		`xiaoheiplay_http_request_duration_seconds_bucket{method="GET",route="/api/v1/orders/:id",le="0.025"} 1`,
+		`xiaoheiplay_http_request_duration_seconds_bucket{method="GET",route="/api/v1/orders/:id",le="2.5"} 2`,
+		`xiaoheiplay_http_request_duration_seconds_bucket{method="GET",route="/api/v1/orders/:id",le="+Inf"} 2`,
+		`xiaoheiplay_http_request_duration_seconds_count{method="GET",route="/api/v1/orders/:id"} 2`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Fatalf("missing %q in:\n%s", line, out)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Package pdf writes minimal text documents: pages of positioned text and
+// lines, no images or embedded fonts. Text uses the Adobe STSong-Light CJK
+// font, which PDF viewers supply themselves, so Chinese renders without
+// shipping a font file.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 page size in points.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type Document struct {
+	pages []*bytes.Buffer
+}
+
+func New() *Document {
+	return &Document{}
+}
+
+// AddPage starts a new page; later drawing goes to it.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+func (d *Document) current() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// Text draws s with its baseline starting at (x, y); y grows upwards from
+// the bottom of the page.
+func (d *Document) Text(x, y, size float64, s string) {
+	if s == "" {
+		return
+	}
+	fmt.Fprintf(d.current(), "BT /F1 %s Tf %s %s Td <%s> Tj ET\n", num(size), num(x), num(y), encodeUCS2(s))
+}
+
+// TextRight draws s so that it ends at x.
+func (d *Document) TextRight(x, y, size float64, s string) {
+	d.Text(x-TextWidth(s, size), y, size, s)
+}
+
+func (d *Document) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.current(), "0.5 w %s %s m %s %s l S\n", num(x1), num(y1), num(x2), num(y2))
+}
+
+// TextWidth estimates the drawn width of s: printable ASCII is half width,
+// everything else full width.
+func TextWidth(s string, size float64) float64 {
+	var w float64
+	for _, r := range s {
+		w += runeWidth(r) * size
+	}
+	return w
+}
+
+// Truncate shortens s to fit max points, marking the cut with "..".
+func Truncate(s string, size, max float64) string {
+	if TextWidth(s, size) <= max {
+		return s
+	}
+	limit := max - TextWidth("..", size)
+	var b strings.Builder
+	var w float64
+	for _, r := range s {
+		w += runeWidth(r) * size
+		if w > limit {
+			break
+		}
+		b.WriteRune(r)
+	}
+	return b.String() + ".."
+}
+
+func runeWidth(r rune) float64 {
+	if r >= 0x20 && r <= 0x7e {
+		return 0.5
+	}
+	return 1
+}
+
+// encodeUCS2 hex-encodes s as UTF-16BE code units for the UniGB-UCS2-H
+// CMap. Characters outside the BMP have no mapping and become "?".
+func encodeUCS2(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r > 0xffff || (r >= 0xd800 && r <= 0xdfff) {
+			r = '?'
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	return b.String()
+}
+
+func num(v float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", v), "0"), ".")
+}
+
+// Write encodes the document to w. An empty document gets one blank page.
+func (d *Document) Write(w io.Writer) error {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	const (
+		catalogID = 1
+		pagesID   = 2
+		fontID    = 3
+		cidFontID = 4
+		descID    = 5
+		firstPage = 6
+	)
+	var out bytes.Buffer
+	offsets := []int{0}
+	obj := func(id int, body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", id, body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	kids := make([]string, 0, len(d.pages))
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", firstPage+i*2))
+	}
+	obj(catalogID, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesID))
+	obj(pagesID, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj(fontID, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [%d 0 R] >>", cidFontID))
+	obj(cidFontID, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> /FontDescriptor %d 0 R /DW 1000 /W [1 95 500] >>", descID))
+	obj(descID, "<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] "+
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+	for i, page := range d.pages {
+		pageID := firstPage + i*2
+		obj(pageID, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pagesID, num(PageWidth), num(PageHeight), fontID, pageID+1))
+		obj(pageID+1, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", page.Len(), page.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets))
+	for _, off := range offsets[1:] {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets), catalogID, xref)
+	_, err := w.Write(out.Bytes())
+	return err
+}
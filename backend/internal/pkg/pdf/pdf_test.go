@@ -0,0 +1,54 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	doc := New()
+	doc.Text(40, 800, 10, "账单 A1")
+	doc.Line(40, 790, 555, 790)
+	doc.AddPage()
+	doc.TextRight(555, 800, 10, "12.50")
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"%PDF-1.4", "/Count 2", "/Encoding /UniGB-UCS2-H", "<8D265355002000410031>", "%%EOF"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(out)
+	if m == nil {
+		t.Fatalf("missing startxref")
+	}
+	xref, _ := strconv.Atoi(m[1])
+	if !strings.HasPrefix(out[xref:], "xref\n") {
+		t.Fatalf("startxref points at %q", out[xref:xref+10])
+	}
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(out, -1)
+	for i, off := range offsets {
+		pos, _ := strconv.Atoi(off[1])
+		if !strings.HasPrefix(out[pos:], strconv.Itoa(i+1)+" 0 obj") {
+			t.Fatalf("xref entry %d points at %q", i+1, out[pos:pos+10])
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := Truncate("abc", 10, 100); got != "abc" {
+		t.Fatalf("unexpected %q", got)
+	}
+	got := Truncate("中文很长的说明文字", 10, 50)
+	if TextWidth(got, 10) > 50 || !strings.HasSuffix(got, "..") {
+		t.Fatalf("unexpected %q", got)
+	}
+}
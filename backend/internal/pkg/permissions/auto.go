@@ -15,38 +15,51 @@ type moduleMeta struct {
 }
 
 var moduleMapping = map[string]moduleMeta{
-	"user":             {Display: "用户管理", SortOrder: 1},
-	"order":            {Display: "订单管理", SortOrder: 2},
-	"vps":              {Display: "VPS管理", SortOrder: 3},
-	"region":           {Display: "地区管理", SortOrder: 4},
-	"plan_group":       {Display: "线路管理", SortOrder: 5},
-	"line":             {Display: "线路管理", SortOrder: 5},
-	"package":          {Display: "套餐管理", SortOrder: 6},
-	"system_image":     {Display: "系统镜像", SortOrder: 7},
-	"billing_cycle":    {Display: "计费周期", SortOrder: 8},
-	"settings":         {Display: "系统设置", SortOrder: 9},
-	"debug":            {Display: "Debug", SortOrder: 9},
-	"automation":       {Display: "自动化平台", SortOrder: 10},
-	"robot":            {Display: "机器人配置", SortOrder: 11},
-	"smtp":             {Display: "SMTP配置", SortOrder: 12},
-	"sms":              {Display: "短信配置", SortOrder: 12},
-	"api_key":          {Display: "API密钥", SortOrder: 13},
-	"email_template":   {Display: "邮件模板", SortOrder: 14},
-	"sms_template":     {Display: "短信模板", SortOrder: 14},
-	"admin":            {Display: "管理员管理", SortOrder: 15},
-	"permission_group": {Display: "权限组", SortOrder: 16},
-	"permission":       {Display: "权限配置", SortOrder: 17},
-	"audit_log":        {Display: "审计日志", SortOrder: 18},
-	"dashboard":        {Display: "数据面板", SortOrder: 19},
-	"profile":          {Display: "个人中心", SortOrder: 20},
-	"cms_category":     {Display: "内容分类", SortOrder: 21},
-	"cms_post":         {Display: "内容管理", SortOrder: 22},
-	"cms_block":        {Display: "页面模块", SortOrder: 23},
-	"upload":           {Display: "资源上传", SortOrder: 24},
-	"tickets":          {Display: "工单管理", SortOrder: 25},
-	"goods_type":       {Display: "Goods Type", SortOrder: 6},
-	"plugin":           {Display: "Plugin", SortOrder: 26},
-	"probe":            {Display: "探针监控", SortOrder: 27},
+	"user":               {Display: "用户管理", SortOrder: 1},
+	"order":              {Display: "订单管理", SortOrder: 2},
+	"order_approval":     {Display: "订单审核策略", SortOrder: 2},
+	"vps":                {Display: "VPS管理", SortOrder: 3},
+	"region":             {Display: "地区管理", SortOrder: 4},
+	"plan_group":         {Display: "线路管理", SortOrder: 5},
+	"line":               {Display: "线路管理", SortOrder: 5},
+	"package":            {Display: "套餐管理", SortOrder: 6},
+	"system_image":       {Display: "系统镜像", SortOrder: 7},
+	"billing_cycle":      {Display: "计费周期", SortOrder: 8},
+	"addon":              {Display: "附加商品", SortOrder: 8},
+	"ip_address":         {Display: "IP地址池", SortOrder: 8},
+	"trial":              {Display: "试用管理", SortOrder: 8},
+	"ledger":             {Display: "财务账本", SortOrder: 19},
+	"payout_account":     {Display: "提现收款账户", SortOrder: 19},
+	"payout_batch":       {Display: "提现打款批次", SortOrder: 19},
+	"finance_report":     {Display: "财务报表", SortOrder: 19},
+	"finance_report_run": {Display: "财务报表", SortOrder: 19},
+	"dunning_policy":     {Display: "续费催缴", SortOrder: 19},
+	"dunning_record":     {Display: "续费催缴", SortOrder: 19},
+	"broadcast":          {Display: "公告群发", SortOrder: 19},
+	"settings":           {Display: "系统设置", SortOrder: 9},
+	"debug":              {Display: "Debug", SortOrder: 9},
+	"automation":         {Display: "自动化平台", SortOrder: 10},
+	"robot":              {Display: "机器人配置", SortOrder: 11},
+	"smtp":               {Display: "SMTP配置", SortOrder: 12},
+	"sms":                {Display: "短信配置", SortOrder: 12},
+	"notify_channels":    {Display: "通知渠道", SortOrder: 12},
+	"api_key":            {Display: "API密钥", SortOrder: 13},
+	"email_template":     {Display: "邮件模板", SortOrder: 14},
+	"sms_template":       {Display: "短信模板", SortOrder: 14},
+	"admin":              {Display: "管理员管理", SortOrder: 15},
+	"permission_group":   {Display: "权限组", SortOrder: 16},
+	"permission":         {Display: "权限配置", SortOrder: 17},
+	"audit_log":          {Display: "审计日志", SortOrder: 18},
+	"dashboard":          {Display: "数据面板", SortOrder: 19},
+	"profile":            {Display: "个人中心", SortOrder: 20},
+	"cms_category":       {Display: "内容分类", SortOrder: 21},
+	"cms_post":           {Display: "内容管理", SortOrder: 22},
+	"cms_block":          {Display: "页面模块", SortOrder: 23},
+	"upload":             {Display: "资源上传", SortOrder: 24},
+	"tickets":            {Display: "工单管理", SortOrder: 25},
+	"goods_type":         {Display: "Goods Type", SortOrder: 6},
+	"plugin":             {Display: "Plugin", SortOrder: 26},
+	"probe":              {Display: "探针监控", SortOrder: 27},
 }
 
 var actionFriendlyName = map[string]string{
@@ -80,6 +93,7 @@ var actionFriendlyName = map[string]string{
 	"revenue_analytics_top":      "收入Top分析",
 	"revenue_analytics_details":  "收入明细分析",
 	"vps_status":                 "VPS状态分布",
+	"trials":                     "试用转化",
 	"tree":                       "权限树",
 }
 
@@ -114,7 +128,8 @@ var actionSortOrder = map[string]int{
 	"revenue_analytics_top":      28,
 	"revenue_analytics_details":  29,
 	"vps_status":                 30,
-	"tree":                       31,
+	"trials":                     31,
+	"tree":                       32,
 }
 
 func BuildFromRoutes(routes []gin.RouteInfo) []domain.PermissionDefinition {
@@ -224,6 +239,8 @@ func moduleFromSegments(segments []string) string {
 		return "admin"
 	case "orders":
 		return "order"
+	case "order-approval-rules":
+		return "order_approval"
 	case "cms":
 		if len(segments) > 1 {
 			switch segments[1] {
@@ -246,6 +263,26 @@ func moduleFromSegments(segments []string) string {
 		return "system_image"
 	case "billing-cycles":
 		return "billing_cycle"
+	case "addons":
+		return "addon"
+	case "ip-addresses":
+		return "ip_address"
+	case "trials":
+		return "trial"
+	case "payout-accounts":
+		return "payout_account"
+	case "payout-batches":
+		return "payout_batch"
+	case "finance-reports":
+		return "finance_report"
+	case "finance-report-runs":
+		return "finance_report_run"
+	case "dunning-policies":
+		return "dunning_policy"
+	case "dunning-records":
+		return "dunning_record"
+	case "broadcasts":
+		return "broadcast"
 	case "api-keys":
 		return "api_key"
 	case "email-templates":
@@ -366,6 +403,12 @@ func actionFromSegments(method string, segments []string) (string, bool) {
 		if len(segments) == 2 && segments[1] == "install" && method == "POST" {
 			return "create", true
 		}
+		if len(segments) == 2 && segments[1] == "repository" && method == "GET" {
+			return "list", true
+		}
+		if len(segments) == 3 && segments[1] == "repository" && segments[2] == "install" && method == "POST" {
+			return "create", true
+		}
 		if len(segments) >= 3 && strings.HasPrefix(segments[1], ":") && strings.HasPrefix(segments[2], ":") {
 			// /plugins/:category/:plugin_id/import
 			if len(segments) == 4 && segments[3] == "import" && method == "POST" {
@@ -414,9 +457,22 @@ func actionFromSegments(method string, segments []string) (string, bool) {
 			}
 		}
 	}
+	if segments[0] == "probes" && len(segments) == 2 && !strings.HasPrefix(segments[1], ":") {
+		// /probes/agent-releases, /probes/agent-version
+		switch method {
+		case "GET":
+			return "list", true
+		case "PUT", "PATCH":
+			return "update", true
+		}
+		return "", false
+	}
 	if segments[0] == "server" && len(segments) > 1 && segments[1] == "status" && method == "GET" {
 		return "status", true
 	}
+	if segments[0] == "broadcasts" && len(segments) == 2 && segments[1] == "preview" && method == "POST" {
+		return "preview", true
+	}
 	if segments[0] == "integrations" && len(segments) > 2 {
 		action := strings.ReplaceAll(segments[2], "-", "_")
 		return action, true
@@ -438,6 +494,26 @@ func actionFromSegments(method string, segments []string) (string, bool) {
 			if method == "GET" {
 				return "list", true
 			}
+		case "reviews":
+			switch method {
+			case "GET":
+				if len(segments) > 3 && segments[3] == "files" {
+					return "review_files", true
+				}
+				return "review_list", true
+			case "POST":
+				return "review", true
+			}
+		case "companies":
+			switch method {
+			case "GET":
+				if len(segments) > 3 && segments[3] == "files" {
+					return "company_files", true
+				}
+				return "company_list", true
+			case "POST":
+				return "company_review", true
+			}
 		}
 	}
 	if segments[0] == "wallet" && len(segments) > 1 && segments[1] == "orders" {
@@ -502,6 +578,35 @@ func actionFromSegments(method string, segments []string) (string, bool) {
 			return "update", true
 		}
 	}
+	if segments[0] == "vps" && len(segments) > 1 && segments[1] == "migrations" {
+		if method == "GET" {
+			return "migrate_view", true
+		}
+		return "migrate", true
+	}
+	if segments[0] == "vps" && len(segments) > 1 && segments[1] == "bulk-jobs" {
+		if method == "GET" {
+			return "bulk_view", true
+		}
+		return "bulk", true
+	}
+	if segments[0] == "ledger" && len(segments) == 2 {
+		switch {
+		case segments[1] == "accounts" && method == "GET":
+			return "view", true
+		case segments[1] == "entries" && method == "GET":
+			return "list", true
+		case method == "POST":
+			return strings.ReplaceAll(segments[1], "-", "_"), true
+		}
+		return "", false
+	}
+	if segments[0] == "order-approval-rules" && len(segments) == 2 && segments[1] == "dry-run" && method == "POST" {
+		return "dry_run", true
+	}
+	if segments[0] == "ip-addresses" && len(segments) == 3 && segments[2] == "events" && method == "GET" {
+		return "view", true
+	}
 	if segments[0] == "vps" && len(segments) > 2 && segments[2] == "status" && method == "POST" {
 		return "admin_status", true
 	}
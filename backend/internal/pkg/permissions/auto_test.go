@@ -15,6 +15,134 @@ func TestInferPermissionCode(t *testing.T) {
 	if !ok || code != "user.update" {
 		t.Fatalf("unexpected status code: %v %s", ok, code)
 	}
+	code, ok = InferPermissionCode("GET", "/admin/api/v1/users/:id/statement")
+	if !ok || code != "user.statement" {
+		t.Fatalf("unexpected statement code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("PUT", "/admin/api/v1/probes/agent-version")
+	if !ok || code != "probe.update" {
+		t.Fatalf("unexpected probe agent version code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("POST", "/admin/api/v1/plugins/repository/install")
+	if !ok || code != "plugin.create" {
+		t.Fatalf("unexpected plugin repository install code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("POST", "/admin/api/v1/integrations/notify-channels/test")
+	if !ok || code != "notify_channels.test" {
+		t.Fatalf("unexpected notify channel test code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("PATCH", "/admin/api/v1/addons/:id")
+	if !ok || code != "addon.update" {
+		t.Fatalf("unexpected addon update code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("GET", "/admin/api/v1/vps/bulk-jobs/:id/stream")
+	if !ok || code != "vps.bulk_view" {
+		t.Fatalf("unexpected vps bulk stream code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("POST", "/admin/api/v1/vps/bulk-jobs/:id/cancel")
+	if !ok || code != "vps.bulk" {
+		t.Fatalf("unexpected vps bulk cancel code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("POST", "/admin/api/v1/vps/migrations/:id/retry")
+	if !ok || code != "vps.migrate" {
+		t.Fatalf("unexpected vps migration retry code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("GET", "/admin/api/v1/vps/:id/dunning")
+	if !ok || code != "vps.dunning" {
+		t.Fatalf("unexpected vps dunning code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("PATCH", "/admin/api/v1/dunning-policies/:goods_type_id")
+	if !ok || code != "dunning_policy.update" {
+		t.Fatalf("unexpected dunning policy update code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("GET", "/admin/api/v1/dunning-records")
+	if !ok || code != "dunning_record.list" {
+		t.Fatalf("unexpected dunning records code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("POST", "/admin/api/v1/broadcasts/preview")
+	if !ok || code != "broadcast.preview" {
+		t.Fatalf("unexpected broadcast preview code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("GET", "/admin/api/v1/broadcasts/:id/deliveries")
+	if !ok || code != "broadcast.deliveries" {
+		t.Fatalf("unexpected broadcast deliveries code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("POST", "/admin/api/v1/broadcasts/:id/cancel")
+	if !ok || code != "broadcast.cancel" {
+		t.Fatalf("unexpected broadcast cancel code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("POST", "/admin/api/v1/realname/reviews/:id/reject")
+	if !ok || code != "realname.review" {
+		t.Fatalf("unexpected realname review code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("GET", "/admin/api/v1/realname/reviews/:id/files/:upload_id")
+	if !ok || code != "realname.review_files" {
+		t.Fatalf("unexpected realname review files code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("POST", "/admin/api/v1/realname/companies/:id/approve")
+	if !ok || code != "realname.company_review" {
+		t.Fatalf("unexpected realname company review code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("GET", "/admin/api/v1/realname/companies")
+	if !ok || code != "realname.company_list" {
+		t.Fatalf("unexpected realname company list code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("GET", "/admin/api/v1/ip-addresses/:id/events")
+	if !ok || code != "ip_address.view" {
+		t.Fatalf("unexpected ip address events code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("POST", "/admin/api/v1/ip-addresses/:id/abuse")
+	if !ok || code != "ip_address.abuse" {
+		t.Fatalf("unexpected ip address abuse code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("POST", "/admin/api/v1/order-approval-rules/dry-run")
+	if !ok || code != "order_approval.dry_run" {
+		t.Fatalf("unexpected order approval dry-run code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("PATCH", "/admin/api/v1/order-approval-rules/:id")
+	if !ok || code != "order_approval.update" {
+		t.Fatalf("unexpected order approval update code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("GET", "/admin/api/v1/orders/:id/approval")
+	if !ok || code != "order.approval" {
+		t.Fatalf("unexpected order approval view code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("GET", "/admin/api/v1/ledger/entries")
+	if !ok || code != "ledger.list" {
+		t.Fatalf("unexpected ledger entries code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("POST", "/admin/api/v1/ledger/opening-balances")
+	if !ok || code != "ledger.opening_balances" {
+		t.Fatalf("unexpected ledger opening balances code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("POST", "/admin/api/v1/payout-accounts/:id/verify")
+	if !ok || code != "payout_account.verify" {
+		t.Fatalf("unexpected payout account verify code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("GET", "/admin/api/v1/payout-batches/:id/export")
+	if !ok || code != "payout_batch.export" {
+		t.Fatalf("unexpected payout batch export code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("POST", "/admin/api/v1/payout-batches")
+	if !ok || code != "payout_batch.create" {
+		t.Fatalf("unexpected payout batch create code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("POST", "/admin/api/v1/finance-reports/:id/run")
+	if !ok || code != "finance_report.run" {
+		t.Fatalf("unexpected finance report run code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("GET", "/admin/api/v1/finance-report-runs/:id/files/:file_id")
+	if !ok || code != "finance_report_run.files" {
+		t.Fatalf("unexpected finance report file code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("GET", "/admin/api/v1/trials")
+	if !ok || code != "trial.list" {
+		t.Fatalf("unexpected trial list code: %v %s", ok, code)
+	}
+	code, ok = InferPermissionCode("GET", "/admin/api/v1/dashboard/trials")
+	if !ok || code != "dashboard.trials" {
+		t.Fatalf("unexpected dashboard trials code: %v %s", ok, code)
+	}
 	if _, ok := InferPermissionCode("GET", "/api/v1/users"); ok {
 		t.Fatalf("expected non-admin route to be ignored")
 	}
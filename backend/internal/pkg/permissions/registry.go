@@ -51,12 +51,65 @@ func InitRegistry() {
 	Register("user.update", "更新用户", "用户管理", 4)
 	Register("user.delete", "删除用户", "用户管理", 5)
 	Register("user.reset_password", "重置用户密码", "用户管理", 6)
+	Register("user.statement", "导出用户对账单", "用户管理", 7)
 
 	Register("order.view", "查看订单详情", "订单管理", 1)
 	Register("order.list", "查看订单列表", "订单管理", 2)
 	Register("order.approve", "批准订单", "订单管理", 3)
 	Register("order.reject", "驳回订单", "订单管理", 4)
 	Register("order.delete", "删除订单", "订单管理", 5)
+	Register("order.approval", "查看订单风控评估", "订单管理", 6)
+
+	Register("order_approval.list", "查看审核规则", "订单审核策略", 1)
+	Register("order_approval.create", "创建审核规则", "订单审核策略", 2)
+	Register("order_approval.update", "更新审核规则", "订单审核策略", 3)
+	Register("order_approval.delete", "删除审核规则", "订单审核策略", 4)
+	Register("order_approval.dry_run", "试运行审核规则", "订单审核策略", 5)
+
+	Register("ledger.view", "查看账本科目", "财务账本", 1)
+	Register("ledger.list", "查看账本分录", "财务账本", 2)
+	Register("ledger.verify", "校验账本", "财务账本", 3)
+	Register("ledger.opening_balances", "补记期初余额", "财务账本", 4)
+
+	Register("payout_account.list", "查看收款账户", "提现收款账户", 1)
+	Register("payout_account.verify", "通过收款账户", "提现收款账户", 2)
+	Register("payout_account.reject", "驳回收款账户", "提现收款账户", 3)
+
+	Register("payout_batch.list", "查看打款批次列表", "提现打款批次", 1)
+	Register("payout_batch.view", "查看打款批次详情", "提现打款批次", 2)
+	Register("payout_batch.create", "创建打款批次", "提现打款批次", 3)
+	Register("payout_batch.export", "导出打款文件", "提现打款批次", 4)
+	Register("payout_batch.paid", "标记批次已打款", "提现打款批次", 5)
+	Register("payout_batch.failed", "标记批次打款失败", "提现打款批次", 6)
+
+	Register("finance_report.list", "查看财务报表定义", "财务报表", 1)
+	Register("finance_report.create", "创建财务报表定义", "财务报表", 2)
+	Register("finance_report.update", "更新财务报表定义", "财务报表", 3)
+	Register("finance_report.delete", "删除财务报表定义", "财务报表", 4)
+	Register("finance_report.run", "立即生成财务报表", "财务报表", 5)
+	Register("finance_report_run.list", "查看报表运行记录", "财务报表", 6)
+	Register("finance_report_run.view", "查看报表运行详情", "财务报表", 7)
+	Register("finance_report_run.files", "下载报表文件", "财务报表", 8)
+	Register("finance_report_run.resend", "重新发送报表", "财务报表", 9)
+
+	Register("dunning_policy.list", "查看催缴策略", "续费催缴", 1)
+	Register("dunning_policy.update", "保存催缴策略", "续费催缴", 2)
+	Register("dunning_policy.delete", "删除催缴策略", "续费催缴", 3)
+	Register("dunning_record.list", "查看催缴记录", "续费催缴", 4)
+
+	Register("broadcast.list", "查看公告群发", "公告群发", 1)
+	Register("broadcast.view", "查看群发详情与统计", "公告群发", 2)
+	Register("broadcast.deliveries", "查看群发投递记录", "公告群发", 3)
+	Register("broadcast.preview", "预估群发人数", "公告群发", 4)
+	Register("broadcast.create", "创建公告群发", "公告群发", 5)
+	Register("broadcast.cancel", "取消公告群发", "公告群发", 6)
+
+	Register("realname.review_list", "查看实名审核队列", "实名认证", 1)
+	Register("realname.review", "审核实名认证", "实名认证", 2)
+	Register("realname.review_files", "查看实名证件照片", "实名认证", 3)
+	Register("realname.company_list", "查看企业认证", "实名认证", 4)
+	Register("realname.company_review", "审核企业认证", "实名认证", 5)
+	Register("realname.company_files", "查看企业认证材料", "实名认证", 6)
 
 	Register("vps.view", "查看VPS详情", "VPS管理", 1)
 	Register("vps.list", "查看VPS列表", "VPS管理", 2)
@@ -66,6 +119,11 @@ func InitRegistry() {
 	Register("vps.resize", "调整VPS配置", "VPS管理", 6)
 	Register("vps.renew", "续费VPS", "VPS管理", 7)
 	Register("vps.admin_status", "设置VPS管理员状态", "VPS管理", 8)
+	Register("vps.bulk_view", "查看VPS批量任务", "VPS管理", 9)
+	Register("vps.bulk", "执行VPS批量操作", "VPS管理", 10)
+	Register("vps.migrate_view", "查看VPS迁移", "VPS管理", 11)
+	Register("vps.migrate", "执行VPS迁移", "VPS管理", 12)
+	Register("vps.dunning", "查看VPS催缴进度", "VPS管理", 13)
 
 	Register("settings.view", "查看系统设置", "系统设置", 1)
 	Register("settings.update", "更新系统设置", "系统设置", 2)
@@ -116,6 +174,20 @@ func InitRegistry() {
 	Register("billing_cycle.update", "更新计费周期", "计费周期", 4)
 	Register("billing_cycle.delete", "删除计费周期", "计费周期", 5)
 
+	Register("addon.list", "查看附加商品列表", "附加商品", 1)
+	Register("addon.create", "创建附加商品", "附加商品", 2)
+	Register("addon.update", "更新附加商品", "附加商品", 3)
+	Register("addon.delete", "删除附加商品", "附加商品", 4)
+
+	Register("ip_address.list", "查看IP地址列表", "IP地址池", 1)
+	Register("ip_address.view", "查看IP地址详情与记录", "IP地址池", 2)
+	Register("ip_address.create", "添加IP地址", "IP地址池", 3)
+	Register("ip_address.update", "更新IP地址", "IP地址池", 4)
+	Register("ip_address.delete", "删除IP地址", "IP地址池", 5)
+	Register("ip_address.abuse", "登记IP滥用", "IP地址池", 6)
+
+	Register("trial.list", "查看试用记录", "试用管理", 1)
+
 	Register("system_image.view", "查看系统镜像详情", "系统镜像", 1)
 	Register("system_image.list", "查看系统镜像列表", "系统镜像", 2)
 	Register("system_image.create", "创建系统镜像", "系统镜像", 3)
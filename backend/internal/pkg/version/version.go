@@ -0,0 +1,43 @@
+package version
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Compare orders dotted versions numerically where possible ("1.10.0" > "1.9.2").
+// A leading "v" is ignored; non-numeric parts compare lexically.
+func Compare(a, b string) int {
+	as := strings.FieldsFunc(strings.TrimPrefix(a, "v"), isSeparator)
+	bs := strings.FieldsFunc(strings.TrimPrefix(b, "v"), isSeparator)
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y string
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xi, xerr := strconv.Atoi(x)
+		yi, yerr := strconv.Atoi(y)
+		switch {
+		case xerr == nil && yerr == nil:
+			if xi != yi {
+				if xi > yi {
+					return 1
+				}
+				return -1
+			}
+		case x != y:
+			if x > y {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
+
+func isSeparator(r rune) bool {
+	return r == '.' || r == '-' || r == '_'
+}
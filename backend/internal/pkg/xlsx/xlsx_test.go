@@ -0,0 +1,54 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, []Sheet{
+		{Name: "收入/Revenue", Rows: [][]string{{"名称", "金额"}, {"A&B", "12.50"}, {"C", "n/a"}}, NumberColumns: []int{1}},
+		{Name: "收入/Revenue", Rows: [][]string{{"x"}}},
+	})
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		body, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(body)
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `name="收入_Revenue"`) || !strings.Contains(files["xl/workbook.xml"], `name="收入_Revenue (2)"`) {
+		t.Fatalf("unexpected workbook: %s", files["xl/workbook.xml"])
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{`<c r="B2"><v>12.50</v></c>`, `A&amp;B`, `<c r="B3" t="inlineStr">`} {
+		if !strings.Contains(sheet, want) {
+			t.Fatalf("sheet missing %q: %s", want, sheet)
+		}
+	}
+	if _, ok := files["xl/worksheets/sheet2.xml"]; !ok {
+		t.Fatalf("missing second sheet")
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for idx, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(idx); got != want {
+			t.Fatalf("column %d: got %s want %s", idx, got, want)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"fmt"
+	appports "xiaoheiplay/internal/app/ports"
 	appshared "xiaoheiplay/internal/app/shared"
 )
 
@@ -31,25 +32,36 @@ type FakeAutomationClient struct {
 	}
 	RenewErr error
 
-	LockCalls    []int64
-	UnlockCalls  []int64
-	DeleteCalls  []int64
-	StartCalls   []int64
-	StopCalls    []int64
-	RebootCalls  []int64
-	ResetOSCalls []struct {
-		HostID     int64
-		TemplateID int64
-		Password   string
-	}
+	LockCalls            []int64
+	UnlockCalls          []int64
+	DeleteCalls          []int64
+	StartCalls           []int64
+	StopCalls            []int64
+	RebootCalls          []int64
+	ResetOSCalls         []appshared.AutomationResetOSRequest
 	ResetOSPasswordCalls []struct {
 		HostID   int64
 		Password string
 	}
-	SnapshotList []appshared.AutomationSnapshot
-	BackupList   []appshared.AutomationBackup
-	FirewallList []appshared.AutomationFirewallRule
-	PortList     []appshared.AutomationPortMapping
+	SnapshotList        []appshared.AutomationSnapshot
+	SnapshotCreateErr   error
+	SnapshotCreateCalls []int64
+	SnapshotDeleteCalls []int64
+	BackupList          []appshared.AutomationBackup
+	BackupCreateCalls   []int64
+	RestoreBackupCalls  []struct {
+		HostID   int64
+		BackupID int64
+	}
+	BackupDeleteCalls []int64
+	FirewallList      []appshared.AutomationFirewallRule
+	PortList          []appshared.AutomationPortMapping
+	ReverseDNSCalls   []struct {
+		HostID int64
+		IP     string
+		PTR    string
+	}
+	ReverseDNSErr error
 }
 
 type FakeAutomationResolver struct {
@@ -160,14 +172,10 @@ func (f *FakeAutomationClient) RebootHost(ctx context.Context, hostID int64) err
 	return nil
 }
 
-func (f *FakeAutomationClient) ResetOS(ctx context.Context, hostID int64, templateID int64, password string) error {
+func (f *FakeAutomationClient) ResetOS(ctx context.Context, req appshared.AutomationResetOSRequest) error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
-	f.ResetOSCalls = append(f.ResetOSCalls, struct {
-		HostID     int64
-		TemplateID int64
-		Password   string
-	}{HostID: hostID, TemplateID: templateID, Password: password})
+	f.ResetOSCalls = append(f.ResetOSCalls, req)
 	return nil
 }
 
@@ -186,10 +194,16 @@ func (f *FakeAutomationClient) ListSnapshots(ctx context.Context, hostID int64)
 }
 
 func (f *FakeAutomationClient) CreateSnapshot(ctx context.Context, hostID int64) error {
-	return nil
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.SnapshotCreateCalls = append(f.SnapshotCreateCalls, hostID)
+	return f.SnapshotCreateErr
 }
 
 func (f *FakeAutomationClient) DeleteSnapshot(ctx context.Context, hostID int64, snapshotID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.SnapshotDeleteCalls = append(f.SnapshotDeleteCalls, snapshotID)
 	return nil
 }
 
@@ -202,14 +216,26 @@ func (f *FakeAutomationClient) ListBackups(ctx context.Context, hostID int64) ([
 }
 
 func (f *FakeAutomationClient) CreateBackup(ctx context.Context, hostID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.BackupCreateCalls = append(f.BackupCreateCalls, hostID)
 	return nil
 }
 
 func (f *FakeAutomationClient) DeleteBackup(ctx context.Context, hostID int64, backupID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.BackupDeleteCalls = append(f.BackupDeleteCalls, backupID)
 	return nil
 }
 
 func (f *FakeAutomationClient) RestoreBackup(ctx context.Context, hostID int64, backupID int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.RestoreBackupCalls = append(f.RestoreBackupCalls, struct {
+		HostID   int64
+		BackupID int64
+	}{HostID: hostID, BackupID: backupID})
 	return nil
 }
 
@@ -225,6 +251,20 @@ func (f *FakeAutomationClient) DeleteFirewallRule(ctx context.Context, hostID in
 	return nil
 }
 
+func (f *FakeAutomationClient) SetReverseDNS(ctx context.Context, hostID int64, ip string, ptr string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.ReverseDNSErr != nil {
+		return f.ReverseDNSErr
+	}
+	f.ReverseDNSCalls = append(f.ReverseDNSCalls, struct {
+		HostID int64
+		IP     string
+		PTR    string
+	}{HostID: hostID, IP: ip, PTR: ptr})
+	return nil
+}
+
 func (f *FakeAutomationClient) ListPortMappings(ctx context.Context, hostID int64) ([]appshared.AutomationPortMapping, error) {
 	return f.PortList, nil
 }
@@ -377,9 +417,10 @@ type FakeEmailSender struct {
 }
 
 type EmailSend struct {
-	To      string
-	Subject string
-	Body    string
+	To          string
+	Subject     string
+	Body        string
+	Attachments []appports.EmailAttachment
 }
 
 func (f *FakeEmailSender) Send(ctx context.Context, to string, subject string, body string) error {
@@ -389,6 +430,13 @@ func (f *FakeEmailSender) Send(ctx context.Context, to string, subject string, b
 	return f.Err
 }
 
+func (f *FakeEmailSender) SendWithAttachments(ctx context.Context, to string, subject string, body string, attachments []appports.EmailAttachment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Sends = append(f.Sends, EmailSend{To: to, Subject: subject, Body: body, Attachments: attachments})
+	return f.Err
+}
+
 type FakeRobotNotifier struct {
 	mu      sync.Mutex
 	Payload []appshared.RobotOrderPayload
@@ -455,6 +503,7 @@ func (r *FakeRealNameRegistry) Register(provider appshared.RealNameProvider) {
 var _ appshared.AutomationClient = (*FakeAutomationClient)(nil)
 var _ appshared.PaymentProviderRegistry = (*FakePaymentRegistry)(nil)
 var _ appshared.EmailSender = (*FakeEmailSender)(nil)
+var _ appports.EmailAttachmentSender = (*FakeEmailSender)(nil)
 var _ appshared.RobotNotifier = (*FakeRobotNotifier)(nil)
 var _ appshared.RealNameProviderRegistry = (*FakeRealNameRegistry)(nil)
 var _ appshared.RealNameProvider = (*FakeRealNameProvider)(nil)
@@ -14,21 +14,27 @@ import (
 	appadminvps "xiaoheiplay/internal/app/adminvps"
 	appauth "xiaoheiplay/internal/app/auth"
 	appautomationlog "xiaoheiplay/internal/app/automationlog"
+	appbroadcast "xiaoheiplay/internal/app/broadcast"
 	appcart "xiaoheiplay/internal/app/cart"
 	appcatalog "xiaoheiplay/internal/app/catalog"
 	appcms "xiaoheiplay/internal/app/cms"
+	appdunning "xiaoheiplay/internal/app/dunning"
 	appgoodstype "xiaoheiplay/internal/app/goodstype"
 	appintegration "xiaoheiplay/internal/app/integration"
+	appledger "xiaoheiplay/internal/app/ledger"
 	appmessage "xiaoheiplay/internal/app/message"
 	appnotification "xiaoheiplay/internal/app/notification"
+	appnotifypref "xiaoheiplay/internal/app/notifypref"
 	apporder "xiaoheiplay/internal/app/order"
 	apporderevent "xiaoheiplay/internal/app/orderevent"
 	apppayment "xiaoheiplay/internal/app/payment"
+	apppayout "xiaoheiplay/internal/app/payout"
 	apppermission "xiaoheiplay/internal/app/permission"
 	apprealname "xiaoheiplay/internal/app/realname"
 	appreport "xiaoheiplay/internal/app/report"
 	appsecurityticket "xiaoheiplay/internal/app/securityticket"
 	appsettings "xiaoheiplay/internal/app/settings"
+	appstatement "xiaoheiplay/internal/app/statement"
 	appticket "xiaoheiplay/internal/app/ticket"
 	appupload "xiaoheiplay/internal/app/upload"
 	appvps "xiaoheiplay/internal/app/vps"
@@ -60,6 +66,7 @@ type Env struct {
 	WalletSvc     *appwallet.Service
 	WalletOrder   *appwalletorder.Service
 	NotifySvc     *appnotification.Service
+	BroadcastSvc  *appbroadcast.Service
 	Handler       *http.Handler
 	Router        *gin.Engine
 }
@@ -84,8 +91,15 @@ func NewTestEnv(t *testing.T, withCMS bool) *Env {
 	goodsTypeSvc := appgoodstype.NewService(repoSQLite, repoSQLite)
 	cartSvc := appcart.NewService(repoSQLite, repoSQLite, repoSQLite)
 	messageSvc := appmessage.NewService(repoSQLite, repoSQLite)
+	notifyPrefSvc := appnotifypref.NewService(repoSQLite)
+	messageSvc.SetNotificationPreferences(notifyPrefSvc)
+	messageSvc.SetDeferredQueue(repoSQLite)
+	messageSvc.SetEmailSender(email)
 	realnameSvc := apprealname.NewService(repoSQLite, realnameReg, repoSQLite)
-	orderSvc := apporder.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, broker, automationResolver, robot, repoSQLite, repoSQLite, email, repoSQLite, repoSQLite, repoSQLite, repoSQLite, messageSvc, realnameSvc)
+	realnameSvc.SetManualReview(repoSQLite, repoSQLite, repoSQLite)
+	realnameSvc.SetMessageService(messageSvc)
+	realnameSvc.SetCompanyVerification(repoSQLite)
+	orderSvc := apporder.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, broker, automationResolver, robot, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, messageSvc, realnameSvc)
 	vpsSvc := appvps.NewService(repoSQLite, automationResolver, repoSQLite)
 	adminSvc := appadmin.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite)
 	adminVPSSvc := appadminvps.NewService(repoSQLite, automationResolver, repoSQLite, repoSQLite, repoSQLite, messageSvc)
@@ -94,14 +108,35 @@ func NewTestEnv(t *testing.T, withCMS bool) *Env {
 	paymentSvc := apppayment.NewService(repoSQLite, repoSQLite, repoSQLite, paymentReg, repoSQLite, orderSvc, broker)
 	walletSvc := appwallet.NewService(repoSQLite, repoSQLite)
 	walletOrderSvc := appwalletorder.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, automationResolver, repoSQLite)
+	paymentSvc.SetWalletHolds(repoSQLite)
+	walletSvc.SetWalletHolds(repoSQLite)
+	walletOrderSvc.SetWalletHolds(repoSQLite)
+	ledgerSvc := appledger.NewService(repoSQLite)
+	walletSvc.SetLedger(ledgerSvc)
+	walletOrderSvc.SetLedger(ledgerSvc)
+	paymentSvc.SetLedger(ledgerSvc)
+	orderSvc.SetLedger(ledgerSvc)
+	payoutSvc := apppayout.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite)
+	payoutSvc.SetLedger(ledgerSvc)
+	walletOrderSvc.SetPayoutAccounts(payoutSvc)
 	uploadSvc := appupload.NewService(repoSQLite)
 	autoLogSvc := appautomationlog.NewService(repoSQLite)
 	orderEventSvc := apporderevent.NewService(repoSQLite)
 	securityTicketSvc := appsecurityticket.NewService(repoSQLite)
 	settingsSvc := appsettings.NewService(repoSQLite)
-	notifySvc := appnotification.NewService(repoSQLite, repoSQLite, repoSQLite, email, messageSvc)
+	notifySvc := appnotification.NewService(repoSQLite, repoSQLite, repoSQLite, messageSvc)
 	integrationSvc := appintegration.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, automationResolver, repoSQLite)
 	reportSvc := appreport.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite)
+	reportSvc.SetWalletSources(repoSQLite, repoSQLite, repoSQLite)
+	financeReportSvc := appreport.NewFinanceService(repoSQLite, reportSvc, repoSQLite)
+	statementSvc := appstatement.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite)
+	dunningSvc := appdunning.NewService(repoSQLite, repoSQLite, repoSQLite, repoSQLite, repoSQLite)
+	dunningSvc.SetMessageService(messageSvc)
+	dunningSvc.SetInstanceActions(vpsSvc)
+	vpsSvc.SetDunningService(dunningSvc)
+	notifySvc.SetDunningService(dunningSvc)
+	broadcastSvc := appbroadcast.NewService(repoSQLite, messageSvc, repoSQLite, repoSQLite)
+	financeReportSvc.SetMailer(email)
 	cmsSvc := appcms.NewService(repoSQLite, repoSQLite, repoSQLite, messageSvc)
 	ticketSvc := appticket.NewService(repoSQLite, repoSQLite, repoSQLite, messageSvc)
 	seedDefaultGoodsType(t, repoSQLite)
@@ -121,6 +156,13 @@ func NewTestEnv(t *testing.T, withCMS bool) *Env {
 		CMSSvc:            cmsSvc,
 		TicketSvc:         ticketSvc,
 		WalletSvc:         walletSvc,
+		LedgerSvc:         ledgerSvc,
+		PayoutSvc:         payoutSvc,
+		FinanceReportSvc:  financeReportSvc,
+		StatementSvc:      statementSvc,
+		DunningSvc:        dunningSvc,
+		NotifyPrefSvc:     notifyPrefSvc,
+		BroadcastSvc:      broadcastSvc,
 		WalletOrder:       walletOrderSvc,
 		PaymentSvc:        paymentSvc,
 		MessageSvc:        messageSvc,
@@ -160,6 +202,7 @@ func NewTestEnv(t *testing.T, withCMS bool) *Env {
 		WalletSvc:     walletSvc,
 		WalletOrder:   walletOrderSvc,
 		NotifySvc:     notifySvc,
+		BroadcastSvc:  broadcastSvc,
 		Handler:       handler,
 		Router:        server.Engine,
 	}
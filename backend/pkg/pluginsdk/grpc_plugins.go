@@ -78,3 +78,17 @@ func (p *AutomationGRPCPlugin) GRPCServer(_ *plugin.GRPCBroker, s *grpc.Server)
 func (p *AutomationGRPCPlugin) GRPCClient(_ context.Context, _ *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
 	return pluginv1.NewAutomationServiceClient(c), nil
 }
+
+type NotifyGRPCPlugin struct {
+	plugin.NetRPCUnsupportedPlugin
+	Impl pluginv1.NotifyServiceServer
+}
+
+func (p *NotifyGRPCPlugin) GRPCServer(_ *plugin.GRPCBroker, s *grpc.Server) error {
+	pluginv1.RegisterNotifyServiceServer(s, p.Impl)
+	return nil
+}
+
+func (p *NotifyGRPCPlugin) GRPCClient(_ context.Context, _ *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	return pluginv1.NewNotifyServiceClient(c), nil
+}
@@ -14,6 +14,7 @@ const (
 	PluginKeyPayment    = "payment"
 	PluginKeyKYC        = "kyc"
 	PluginKeyAutomation = "automation"
+	PluginKeyNotify     = "notify"
 )
 
 var Handshake = plugin.HandshakeConfig{
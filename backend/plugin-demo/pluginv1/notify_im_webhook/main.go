@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"xiaoheiplay/pkg/pluginsdk"
+	pluginv1 "xiaoheiplay/plugin/v1"
+)
+
+type config struct {
+	Format     string `json:"format"`
+	WebhookURL string `json:"webhook_url"`
+	Secret     string `json:"secret"`
+	BotToken   string `json:"bot_token"`
+	ChatID     string `json:"chat_id"`
+	TimeoutSec int    `json:"timeout_sec"`
+}
+
+func (c config) validate() error {
+	switch c.Format {
+	case "slack", "dingtalk", "wecom":
+		if strings.TrimSpace(c.WebhookURL) == "" {
+			return fmt.Errorf("webhook_url required")
+		}
+	case "telegram":
+		if strings.TrimSpace(c.BotToken) == "" || strings.TrimSpace(c.ChatID) == "" {
+			return fmt.Errorf("bot_token/chat_id required")
+		}
+	default:
+		return fmt.Errorf("unsupported format: %s", c.Format)
+	}
+	return nil
+}
+
+func parseConfig(raw string) (config, error) {
+	var cfg config
+	if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+		return config{}, fmt.Errorf("invalid json")
+	}
+	cfg.Format = strings.ToLower(strings.TrimSpace(cfg.Format))
+	if cfg.Format == "" {
+		cfg.Format = "slack"
+	}
+	if cfg.TimeoutSec <= 0 {
+		cfg.TimeoutSec = 8
+	}
+	return cfg, cfg.validate()
+}
+
+type coreServer struct {
+	pluginv1.UnimplementedCoreServiceServer
+	cfg      config
+	instance string
+	http     *http.Client
+}
+
+func (s *coreServer) GetManifest(ctx context.Context, _ *pluginv1.Empty) (*pluginv1.Manifest, error) {
+	_ = ctx
+	return &pluginv1.Manifest{
+		PluginId:    "im_webhook",
+		Name:        "IM Webhook",
+		Version:     "1.0.0",
+		Description: "Slack-compatible, DingTalk, WeCom and Telegram bot notifications.",
+		Notify:      &pluginv1.NotifyCapability{Channel: "webhook"},
+	}, nil
+}
+
+func (s *coreServer) GetConfigSchema(ctx context.Context, _ *pluginv1.Empty) (*pluginv1.ConfigSchema, error) {
+	_ = ctx
+	return &pluginv1.ConfigSchema{
+		JsonSchema: `{
+  "title": "IM Webhook",
+  "type": "object",
+  "properties": {
+    "format": { "type": "string", "title": "Format", "enum": ["slack", "dingtalk", "wecom", "telegram"], "default": "slack" },
+    "webhook_url": { "type": "string", "title": "Webhook URL" },
+    "secret": { "type": "string", "title": "DingTalk Sign Secret", "format": "password" },
+    "bot_token": { "type": "string", "title": "Telegram Bot Token", "format": "password" },
+    "chat_id": { "type": "string", "title": "Telegram Chat ID" },
+    "timeout_sec": { "type": "integer", "title": "Request Timeout (sec)", "default": 8, "minimum": 1, "maximum": 60 }
+  }
+}`,
+		UiSchema: `{
+  "secret": { "ui:widget": "password", "ui:help": "留空表示不修改（由宿主处理）" },
+  "bot_token": { "ui:widget": "password", "ui:help": "留空表示不修改（由宿主处理）" }
+}`,
+	}, nil
+}
+
+func (s *coreServer) ValidateConfig(ctx context.Context, req *pluginv1.ValidateConfigRequest) (*pluginv1.ValidateConfigResponse, error) {
+	_ = ctx
+	if _, err := parseConfig(req.GetConfigJson()); err != nil {
+		return &pluginv1.ValidateConfigResponse{Ok: false, Error: err.Error()}, nil
+	}
+	return &pluginv1.ValidateConfigResponse{Ok: true}, nil
+}
+
+func (s *coreServer) Init(ctx context.Context, req *pluginv1.InitRequest) (*pluginv1.InitResponse, error) {
+	_ = ctx
+	cfg, err := parseConfig(req.GetConfigJson())
+	if err != nil {
+		return &pluginv1.InitResponse{Ok: false, Error: err.Error()}, nil
+	}
+	s.cfg = cfg
+	s.instance = req.GetInstanceId()
+	s.http = &http.Client{Timeout: time.Duration(cfg.TimeoutSec) * time.Second}
+	return &pluginv1.InitResponse{Ok: true}, nil
+}
+
+func (s *coreServer) ReloadConfig(ctx context.Context, req *pluginv1.ReloadConfigRequest) (*pluginv1.ReloadConfigResponse, error) {
+	resp, _ := s.Init(ctx, &pluginv1.InitRequest{InstanceId: s.instance, ConfigJson: req.GetConfigJson()})
+	if !resp.GetOk() {
+		return &pluginv1.ReloadConfigResponse{Ok: false, Error: resp.GetError()}, nil
+	}
+	return &pluginv1.ReloadConfigResponse{Ok: true}, nil
+}
+
+func (s *coreServer) Health(ctx context.Context, _ *pluginv1.HealthCheckRequest) (*pluginv1.HealthCheckResponse, error) {
+	_ = ctx
+	return &pluginv1.HealthCheckResponse{
+		Status:     pluginv1.HealthStatus_HEALTH_STATUS_OK,
+		Message:    "ok",
+		UnixMillis: time.Now().UnixMilli(),
+	}, nil
+}
+
+type notifyServer struct {
+	pluginv1.UnimplementedNotifyServiceServer
+	core *coreServer
+}
+
+func (s *notifyServer) Send(ctx context.Context, req *pluginv1.SendNotifyRequest) (*pluginv1.SendNotifyResponse, error) {
+	if s.core == nil || s.core.http == nil {
+		return nil, status.Error(codes.FailedPrecondition, "plugin not initialized")
+	}
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing request")
+	}
+	text := strings.TrimSpace(req.GetContent())
+	if title := strings.TrimSpace(req.GetTitle()); title != "" {
+		text = title + "\n" + text
+	}
+	if text == "" {
+		return nil, status.Error(codes.InvalidArgument, "title or content required")
+	}
+	cfg := s.core.cfg
+	target := strings.TrimSpace(cfg.WebhookURL)
+	var payload any
+	switch cfg.Format {
+	case "dingtalk":
+		payload = map[string]any{"msgtype": "text", "text": map[string]string{"content": text}}
+		if secret := strings.TrimSpace(cfg.Secret); secret != "" {
+			target = signDingTalk(target, secret, time.Now())
+		}
+	case "wecom":
+		payload = map[string]any{"msgtype": "text", "text": map[string]string{"content": text}}
+	case "telegram":
+		target = "https://api.telegram.org/bot" + strings.TrimSpace(cfg.BotToken) + "/sendMessage"
+		payload = map[string]string{"chat_id": strings.TrimSpace(cfg.ChatID), "text": text}
+	default:
+		payload = map[string]string{"text": text}
+	}
+	body, _ := json.Marshal(payload)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return &pluginv1.SendNotifyResponse{Ok: false, Error: sanitizeErr(err)}, nil
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := s.core.http.Do(httpReq)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, sanitizeErr(err))
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode/100 != 2 {
+		return &pluginv1.SendNotifyResponse{Ok: false, Error: "webhook returned " + resp.Status, ErrorCode: strconv.Itoa(resp.StatusCode)}, nil
+	}
+	// DingTalk and WeCom report failures in the body with HTTP 200.
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if json.Unmarshal(respBody, &result) == nil && result.ErrCode != 0 {
+		return &pluginv1.SendNotifyResponse{Ok: false, Error: result.ErrMsg, ErrorCode: strconv.Itoa(result.ErrCode)}, nil
+	}
+	return &pluginv1.SendNotifyResponse{Ok: true}, nil
+}
+
+func signDingTalk(rawURL, secret string, now time.Time) string {
+	ts := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(ts + "\n" + secret))
+	sign := url.QueryEscape(base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	sep := "?"
+	if strings.Contains(rawURL, "?") {
+		sep = "&"
+	}
+	return rawURL + sep + "timestamp=" + ts + "&sign=" + sign
+}
+
+func main() {
+	core := &coreServer{}
+	notify := &notifyServer{core: core}
+	pluginsdk.Serve(map[string]pluginsdk.Plugin{
+		pluginsdk.PluginKeyCore:   &pluginsdk.CoreGRPCPlugin{Impl: core},
+		pluginsdk.PluginKeyNotify: &pluginsdk.NotifyGRPCPlugin{Impl: notify},
+	})
+}
+
+func sanitizeErr(err error) string {
+	if err == nil {
+		return ""
+	}
+	s := err.Error()
+	s = strings.ReplaceAll(s, "\n", " ")
+	if len(s) > 400 {
+		s = s[:400]
+	}
+	return s
+}
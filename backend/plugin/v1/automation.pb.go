@@ -407,6 +407,8 @@ type AutomationInstance struct {
 	VncPassword   string                 `protobuf:"bytes,10,opt,name=vnc_password,json=vncPassword,proto3" json:"vnc_password,omitempty"`
 	OsPassword    string                 `protobuf:"bytes,11,opt,name=os_password,json=osPassword,proto3" json:"os_password,omitempty"`
 	ExpireAtUnix  int64                  `protobuf:"varint,12,opt,name=expire_at_unix,json=expireAtUnix,proto3" json:"expire_at_unix,omitempty"`
+	// All addresses bound to the instance including remote_ip (optional).
+	Ips           []*AutomationInstanceIP `protobuf:"bytes,13,rep,name=ips,proto3" json:"ips,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -525,6 +527,13 @@ func (x *AutomationInstance) GetExpireAtUnix() int64 {
 	return 0
 }
 
+func (x *AutomationInstance) GetIps() []*AutomationInstanceIP {
+	if x != nil {
+		return x.Ips
+	}
+	return nil
+}
+
 type ListAreasResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Items         []*AutomationArea      `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
@@ -808,6 +817,12 @@ type CreateInstanceRequest struct {
 	MemoryGb      int32 `protobuf:"varint,11,opt,name=memory_gb,json=memoryGb,proto3" json:"memory_gb,omitempty"`
 	DiskGb        int32 `protobuf:"varint,12,opt,name=disk_gb,json=diskGb,proto3" json:"disk_gb,omitempty"`
 	BandwidthMbps int32 `protobuf:"varint,13,opt,name=bandwidth_mbps,json=bandwidthMbps,proto3" json:"bandwidth_mbps,omitempty"`
+	// OpenSSH authorized_keys lines for the default login user (optional).
+	// Only honored by plugins declaring AUTOMATION_FEATURE_SSH_KEYS.
+	SshPublicKeys []string `protobuf:"bytes,14,rep,name=ssh_public_keys,json=sshPublicKeys,proto3" json:"ssh_public_keys,omitempty"`
+	// cloud-init user-data, e.g. "#cloud-config" or a shell script (optional).
+	// Only honored by plugins declaring AUTOMATION_FEATURE_CLOUD_INIT.
+	UserData      string `protobuf:"bytes,15,opt,name=user_data,json=userData,proto3" json:"user_data,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -933,6 +948,20 @@ func (x *CreateInstanceRequest) GetBandwidthMbps() int32 {
 	return 0
 }
 
+func (x *CreateInstanceRequest) GetSshPublicKeys() []string {
+	if x != nil {
+		return x.SshPublicKeys
+	}
+	return nil
+}
+
+func (x *CreateInstanceRequest) GetUserData() string {
+	if x != nil {
+		return x.UserData
+	}
+	return ""
+}
+
 type CreateInstanceResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	InstanceId    int64                  `protobuf:"varint,1,opt,name=instance_id,json=instanceId,proto3" json:"instance_id,omitempty"`
@@ -1346,10 +1375,14 @@ func (x *RebootRequest) GetInstanceId() int64 {
 }
 
 type RebuildRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	InstanceId    int64                  `protobuf:"varint,1,opt,name=instance_id,json=instanceId,proto3" json:"instance_id,omitempty"`
-	ImageId       int64                  `protobuf:"varint,2,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
-	Password      string                 `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	InstanceId int64                  `protobuf:"varint,1,opt,name=instance_id,json=instanceId,proto3" json:"instance_id,omitempty"`
+	ImageId    int64                  `protobuf:"varint,2,opt,name=image_id,json=imageId,proto3" json:"image_id,omitempty"`
+	Password   string                 `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
+	// Same semantics as CreateInstanceRequest.ssh_public_keys.
+	SshPublicKeys []string `protobuf:"bytes,4,rep,name=ssh_public_keys,json=sshPublicKeys,proto3" json:"ssh_public_keys,omitempty"`
+	// Same semantics as CreateInstanceRequest.user_data.
+	UserData      string `protobuf:"bytes,5,opt,name=user_data,json=userData,proto3" json:"user_data,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -1405,6 +1438,20 @@ func (x *RebuildRequest) GetPassword() string {
 	return ""
 }
 
+func (x *RebuildRequest) GetSshPublicKeys() []string {
+	if x != nil {
+		return x.SshPublicKeys
+	}
+	return nil
+}
+
+func (x *RebuildRequest) GetUserData() string {
+	if x != nil {
+		return x.UserData
+	}
+	return ""
+}
+
 type ResetPasswordRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	InstanceId    int64                  `protobuf:"varint,1,opt,name=instance_id,json=instanceId,proto3" json:"instance_id,omitempty"`
@@ -3302,6 +3349,137 @@ func (x *DeleteFirewallRuleRequest) GetRuleId() int64 {
 	return 0
 }
 
+type AutomationInstanceIP struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Address string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// 4 or 6.
+	Version int32 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	Primary bool  `protobuf:"varint,3,opt,name=primary,proto3" json:"primary,omitempty"`
+	// Current PTR record, empty when unknown or unset.
+	Ptr           string `protobuf:"bytes,4,opt,name=ptr,proto3" json:"ptr,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AutomationInstanceIP) Reset() {
+	*x = AutomationInstanceIP{}
+	mi := &file_plugin_v1_automation_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AutomationInstanceIP) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AutomationInstanceIP) ProtoMessage() {}
+
+func (x *AutomationInstanceIP) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_v1_automation_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AutomationInstanceIP.ProtoReflect.Descriptor instead.
+func (*AutomationInstanceIP) Descriptor() ([]byte, []int) {
+	return file_plugin_v1_automation_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *AutomationInstanceIP) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *AutomationInstanceIP) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *AutomationInstanceIP) GetPrimary() bool {
+	if x != nil {
+		return x.Primary
+	}
+	return false
+}
+
+func (x *AutomationInstanceIP) GetPtr() string {
+	if x != nil {
+		return x.Ptr
+	}
+	return ""
+}
+
+type SetReverseDNSRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	InstanceId int64                  `protobuf:"varint,1,opt,name=instance_id,json=instanceId,proto3" json:"instance_id,omitempty"`
+	Ip         string                 `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
+	// Empty ptr resets the record to the provider default.
+	Ptr           string `protobuf:"bytes,3,opt,name=ptr,proto3" json:"ptr,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetReverseDNSRequest) Reset() {
+	*x = SetReverseDNSRequest{}
+	mi := &file_plugin_v1_automation_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetReverseDNSRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetReverseDNSRequest) ProtoMessage() {}
+
+func (x *SetReverseDNSRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_v1_automation_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetReverseDNSRequest.ProtoReflect.Descriptor instead.
+func (*SetReverseDNSRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_v1_automation_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *SetReverseDNSRequest) GetInstanceId() int64 {
+	if x != nil {
+		return x.InstanceId
+	}
+	return 0
+}
+
+func (x *SetReverseDNSRequest) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *SetReverseDNSRequest) GetPtr() string {
+	if x != nil {
+		return x.Ptr
+	}
+	return ""
+}
+
 var File_plugin_v1_automation_proto protoreflect.FileDescriptor
 
 const file_plugin_v1_automation_proto_rawDesc = "" +
@@ -3311,11 +3489,12 @@ const file_plugin_v1_automation_proto_rawDesc = "" +
 	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x1d\n" +
 	"\n" +
 	"error_code\x18\x02 \x01(\tR\terrorCode\x12#\n" +
-	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage\"J\n" +
+	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage\"^\n" +
 	"\x0eAutomationArea\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
-	"\x05state\x18\x03 \x01(\x05R\x05state\"c\n" +
+	"\x05state\x18\x03 \x01(\x05R\x05state\x12\x12\n" +
+	"\x04code\x18\x04 \x01(\tR\x04code\"c\n" +
 	"\x0eAutomationLine\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x17\n" +
@@ -3331,11 +3510,12 @@ const file_plugin_v1_automation_proto_rawDesc = "" +
 	"\bport_num\x18\a \x01(\x05R\aportNum\x12#\n" +
 	"\rmonthly_price\x18\b \x01(\x03R\fmonthlyPrice\x122\n" +
 	"\x12capacity_remaining\x18\t \x01(\x05H\x00R\x11capacityRemaining\x88\x01\x01B\x15\n" +
-	"\x13_capacity_remaining\"I\n" +
+	"\x13_capacity_remaining\"c\n" +
 	"\x0fAutomationImage\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
-	"\x04type\x18\x03 \x01(\tR\x04type\"\xeb\x02\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x18\n" +
+	"\aenabled\x18\x04 \x01(\bR\aenabled\"\x9e\x03\n" +
 	"\x12AutomationInstance\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
@@ -3350,7 +3530,8 @@ const file_plugin_v1_automation_proto_rawDesc = "" +
 	" \x01(\tR\vvncPassword\x12\x1f\n" +
 	"\vos_password\x18\v \x01(\tR\n" +
 	"osPassword\x12$\n" +
-	"\x0eexpire_at_unix\x18\f \x01(\x03R\fexpireAtUnix\"D\n" +
+	"\x0eexpire_at_unix\x18\f \x01(\x03R\fexpireAtUnix\x121\n" +
+	"\x03ips\x18\r \x03(\v2\x1f.plugin.v1.AutomationInstanceIPR\x03ips\"D\n" +
 	"\x11ListAreasResponse\x12/\n" +
 	"\x05items\x18\x01 \x03(\v2\x19.plugin.v1.AutomationAreaR\x05items\"D\n" +
 	"\x11ListLinesResponse\x12/\n" +
@@ -3362,7 +3543,7 @@ const file_plugin_v1_automation_proto_rawDesc = "" +
 	"\x11ListImagesRequest\x12\x17\n" +
 	"\aline_id\x18\x01 \x01(\x03R\x06lineId\"F\n" +
 	"\x12ListImagesResponse\x120\n" +
-	"\x05items\x18\x01 \x03(\v2\x1a.plugin.v1.AutomationImageR\x05items\"\xfd\x02\n" +
+	"\x05items\x18\x01 \x03(\v2\x1a.plugin.v1.AutomationImageR\x05items\"\xc2\x03\n" +
 	"\x15CreateInstanceRequest\x12\x17\n" +
 	"\aline_id\x18\x01 \x01(\x03R\x06lineId\x12\x1d\n" +
 	"\n" +
@@ -3378,7 +3559,9 @@ const file_plugin_v1_automation_proto_rawDesc = "" +
 	" \x01(\x05R\x03cpu\x12\x1b\n" +
 	"\tmemory_gb\x18\v \x01(\x05R\bmemoryGb\x12\x17\n" +
 	"\adisk_gb\x18\f \x01(\x05R\x06diskGb\x12%\n" +
-	"\x0ebandwidth_mbps\x18\r \x01(\x05R\rbandwidthMbps\"9\n" +
+	"\x0ebandwidth_mbps\x18\r \x01(\x05R\rbandwidthMbps\x12&\n" +
+	"\x0fssh_public_keys\x18\x0e \x03(\tR\rsshPublicKeys\x12\x1b\n" +
+	"\tuser_data\x18\x0f \x01(\tR\buserData\"9\n" +
 	"\x16CreateInstanceResponse\x12\x1f\n" +
 	"\vinstance_id\x18\x01 \x01(\x03R\n" +
 	"instanceId\"5\n" +
@@ -3404,12 +3587,14 @@ const file_plugin_v1_automation_proto_rawDesc = "" +
 	"instanceId\"0\n" +
 	"\rRebootRequest\x12\x1f\n" +
 	"\vinstance_id\x18\x01 \x01(\x03R\n" +
-	"instanceId\"h\n" +
+	"instanceId\"\xad\x01\n" +
 	"\x0eRebuildRequest\x12\x1f\n" +
 	"\vinstance_id\x18\x01 \x01(\x03R\n" +
 	"instanceId\x12\x19\n" +
 	"\bimage_id\x18\x02 \x01(\x03R\aimageId\x12\x1a\n" +
-	"\bpassword\x18\x03 \x01(\tR\bpassword\"S\n" +
+	"\bpassword\x18\x03 \x01(\tR\bpassword\x12&\n" +
+	"\x0fssh_public_keys\x18\x04 \x03(\tR\rsshPublicKeys\x12\x1b\n" +
+	"\tuser_data\x18\x05 \x01(\tR\buserData\"S\n" +
 	"\x14ResetPasswordRequest\x12\x1f\n" +
 	"\vinstance_id\x18\x01 \x01(\x03R\n" +
 	"instanceId\x12\x1a\n" +
@@ -3553,7 +3738,17 @@ const file_plugin_v1_automation_proto_rawDesc = "" +
 	"\x19DeleteFirewallRuleRequest\x12\x1f\n" +
 	"\vinstance_id\x18\x01 \x01(\x03R\n" +
 	"instanceId\x12\x17\n" +
-	"\arule_id\x18\x02 \x01(\x03R\x06ruleId2\x9b\x15\n" +
+	"\arule_id\x18\x02 \x01(\x03R\x06ruleId\"v\n" +
+	"\x14AutomationInstanceIP\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\x12\x18\n" +
+	"\aprimary\x18\x03 \x01(\bR\aprimary\x12\x10\n" +
+	"\x03ptr\x18\x04 \x01(\tR\x03ptr\"Y\n" +
+	"\x14SetReverseDNSRequest\x12\x1f\n" +
+	"\vinstance_id\x18\x01 \x01(\x03R\n" +
+	"instanceId\x12\x0e\n" +
+	"\x02ip\x18\x02 \x01(\tR\x02ip\x12\x10\n" +
+	"\x03ptr\x18\x03 \x01(\tR\x03ptr2\xe9\x15\n" +
 	"\x11AutomationService\x12;\n" +
 	"\tListAreas\x12\x10.plugin.v1.Empty\x1a\x1c.plugin.v1.ListAreasResponse\x12;\n" +
 	"\tListLines\x12\x10.plugin.v1.Empty\x1a\x1c.plugin.v1.ListLinesResponse\x12O\n" +
@@ -3591,7 +3786,8 @@ const file_plugin_v1_automation_proto_rawDesc = "" +
 	"\x0fRestoreSnapshot\x12!.plugin.v1.RestoreSnapshotRequest\x1a\x1a.plugin.v1.OperationResult\x12^\n" +
 	"\x11ListFirewallRules\x12#.plugin.v1.ListFirewallRulesRequest\x1a$.plugin.v1.ListFirewallRulesResponse\x12P\n" +
 	"\x0fAddFirewallRule\x12!.plugin.v1.AddFirewallRuleRequest\x1a\x1a.plugin.v1.OperationResult\x12V\n" +
-	"\x12DeleteFirewallRule\x12$.plugin.v1.DeleteFirewallRuleRequest\x1a\x1a.plugin.v1.OperationResultB Z\x1exiaoheiplay/plugin/v1;pluginv1b\x06proto3"
+	"\x12DeleteFirewallRule\x12$.plugin.v1.DeleteFirewallRuleRequest\x1a\x1a.plugin.v1.OperationResult\x12L\n" +
+	"\rSetReverseDNS\x12\x1f.plugin.v1.SetReverseDNSRequest\x1a\x1a.plugin.v1.OperationResultB Z\x1exiaoheiplay/plugin/v1;pluginv1b\x06proto3"
 
 var (
 	file_plugin_v1_automation_proto_rawDescOnce sync.Once
@@ -3605,7 +3801,7 @@ func file_plugin_v1_automation_proto_rawDescGZIP() []byte {
 	return file_plugin_v1_automation_proto_rawDescData
 }
 
-var file_plugin_v1_automation_proto_msgTypes = make([]protoimpl.MessageInfo, 59)
+var file_plugin_v1_automation_proto_msgTypes = make([]protoimpl.MessageInfo, 61)
 var file_plugin_v1_automation_proto_goTypes = []any{
 	(*OperationResult)(nil),             // 0: plugin.v1.OperationResult
 	(*AutomationArea)(nil),              // 1: plugin.v1.AutomationArea
@@ -3666,94 +3862,99 @@ var file_plugin_v1_automation_proto_goTypes = []any{
 	(*ListFirewallRulesResponse)(nil),   // 56: plugin.v1.ListFirewallRulesResponse
 	(*AddFirewallRuleRequest)(nil),      // 57: plugin.v1.AddFirewallRuleRequest
 	(*DeleteFirewallRuleRequest)(nil),   // 58: plugin.v1.DeleteFirewallRuleRequest
-	(*Empty)(nil),                       // 59: plugin.v1.Empty
+	(*AutomationInstanceIP)(nil),        // 59: plugin.v1.AutomationInstanceIP
+	(*SetReverseDNSRequest)(nil),        // 60: plugin.v1.SetReverseDNSRequest
+	(*Empty)(nil),                       // 61: plugin.v1.Empty
 }
 var file_plugin_v1_automation_proto_depIdxs = []int32{
-	1,  // 0: plugin.v1.ListAreasResponse.items:type_name -> plugin.v1.AutomationArea
-	2,  // 1: plugin.v1.ListLinesResponse.items:type_name -> plugin.v1.AutomationLine
-	3,  // 2: plugin.v1.ListPackagesResponse.items:type_name -> plugin.v1.AutomationPackage
-	4,  // 3: plugin.v1.ListImagesResponse.items:type_name -> plugin.v1.AutomationImage
-	5,  // 4: plugin.v1.GetInstanceResponse.instance:type_name -> plugin.v1.AutomationInstance
-	16, // 5: plugin.v1.ListInstancesSimpleResponse.items:type_name -> plugin.v1.AutomationInstanceSimple
-	35, // 6: plugin.v1.ListPortMappingsResponse.items:type_name -> plugin.v1.AutomationPortMapping
-	42, // 7: plugin.v1.ListBackupsResponse.items:type_name -> plugin.v1.AutomationBackup
-	48, // 8: plugin.v1.ListSnapshotsResponse.items:type_name -> plugin.v1.AutomationSnapshot
-	54, // 9: plugin.v1.ListFirewallRulesResponse.items:type_name -> plugin.v1.AutomationFirewallRule
-	59, // 10: plugin.v1.AutomationService.ListAreas:input_type -> plugin.v1.Empty
-	59, // 11: plugin.v1.AutomationService.ListLines:input_type -> plugin.v1.Empty
-	8,  // 12: plugin.v1.AutomationService.ListPackages:input_type -> plugin.v1.ListPackagesRequest
-	10, // 13: plugin.v1.AutomationService.ListImages:input_type -> plugin.v1.ListImagesRequest
-	12, // 14: plugin.v1.AutomationService.CreateInstance:input_type -> plugin.v1.CreateInstanceRequest
-	14, // 15: plugin.v1.AutomationService.GetInstance:input_type -> plugin.v1.GetInstanceRequest
-	17, // 16: plugin.v1.AutomationService.ListInstancesSimple:input_type -> plugin.v1.ListInstancesSimpleRequest
-	19, // 17: plugin.v1.AutomationService.Start:input_type -> plugin.v1.StartRequest
-	20, // 18: plugin.v1.AutomationService.Shutdown:input_type -> plugin.v1.ShutdownRequest
-	21, // 19: plugin.v1.AutomationService.Reboot:input_type -> plugin.v1.RebootRequest
-	22, // 20: plugin.v1.AutomationService.Rebuild:input_type -> plugin.v1.RebuildRequest
-	23, // 21: plugin.v1.AutomationService.ResetPassword:input_type -> plugin.v1.ResetPasswordRequest
-	24, // 22: plugin.v1.AutomationService.ElasticUpdate:input_type -> plugin.v1.ElasticUpdateRequest
-	25, // 23: plugin.v1.AutomationService.Lock:input_type -> plugin.v1.LockRequest
-	26, // 24: plugin.v1.AutomationService.Unlock:input_type -> plugin.v1.UnlockRequest
-	27, // 25: plugin.v1.AutomationService.Renew:input_type -> plugin.v1.RenewRequest
-	28, // 26: plugin.v1.AutomationService.Destroy:input_type -> plugin.v1.DestroyRequest
-	29, // 27: plugin.v1.AutomationService.GetPanelURL:input_type -> plugin.v1.GetPanelURLRequest
-	31, // 28: plugin.v1.AutomationService.GetVNCURL:input_type -> plugin.v1.GetVNCURLRequest
-	33, // 29: plugin.v1.AutomationService.GetMonitor:input_type -> plugin.v1.GetMonitorRequest
-	36, // 30: plugin.v1.AutomationService.ListPortMappings:input_type -> plugin.v1.ListPortMappingsRequest
-	38, // 31: plugin.v1.AutomationService.AddPortMapping:input_type -> plugin.v1.AddPortMappingRequest
-	39, // 32: plugin.v1.AutomationService.DeletePortMapping:input_type -> plugin.v1.DeletePortMappingRequest
-	40, // 33: plugin.v1.AutomationService.FindPortCandidates:input_type -> plugin.v1.FindPortCandidatesRequest
-	43, // 34: plugin.v1.AutomationService.ListBackups:input_type -> plugin.v1.ListBackupsRequest
-	45, // 35: plugin.v1.AutomationService.CreateBackup:input_type -> plugin.v1.CreateBackupRequest
-	46, // 36: plugin.v1.AutomationService.DeleteBackup:input_type -> plugin.v1.DeleteBackupRequest
-	47, // 37: plugin.v1.AutomationService.RestoreBackup:input_type -> plugin.v1.RestoreBackupRequest
-	49, // 38: plugin.v1.AutomationService.ListSnapshots:input_type -> plugin.v1.ListSnapshotsRequest
-	51, // 39: plugin.v1.AutomationService.CreateSnapshot:input_type -> plugin.v1.CreateSnapshotRequest
-	52, // 40: plugin.v1.AutomationService.DeleteSnapshot:input_type -> plugin.v1.DeleteSnapshotRequest
-	53, // 41: plugin.v1.AutomationService.RestoreSnapshot:input_type -> plugin.v1.RestoreSnapshotRequest
-	55, // 42: plugin.v1.AutomationService.ListFirewallRules:input_type -> plugin.v1.ListFirewallRulesRequest
-	57, // 43: plugin.v1.AutomationService.AddFirewallRule:input_type -> plugin.v1.AddFirewallRuleRequest
-	58, // 44: plugin.v1.AutomationService.DeleteFirewallRule:input_type -> plugin.v1.DeleteFirewallRuleRequest
-	6,  // 45: plugin.v1.AutomationService.ListAreas:output_type -> plugin.v1.ListAreasResponse
-	7,  // 46: plugin.v1.AutomationService.ListLines:output_type -> plugin.v1.ListLinesResponse
-	9,  // 47: plugin.v1.AutomationService.ListPackages:output_type -> plugin.v1.ListPackagesResponse
-	11, // 48: plugin.v1.AutomationService.ListImages:output_type -> plugin.v1.ListImagesResponse
-	13, // 49: plugin.v1.AutomationService.CreateInstance:output_type -> plugin.v1.CreateInstanceResponse
-	15, // 50: plugin.v1.AutomationService.GetInstance:output_type -> plugin.v1.GetInstanceResponse
-	18, // 51: plugin.v1.AutomationService.ListInstancesSimple:output_type -> plugin.v1.ListInstancesSimpleResponse
-	0,  // 52: plugin.v1.AutomationService.Start:output_type -> plugin.v1.OperationResult
-	0,  // 53: plugin.v1.AutomationService.Shutdown:output_type -> plugin.v1.OperationResult
-	0,  // 54: plugin.v1.AutomationService.Reboot:output_type -> plugin.v1.OperationResult
-	0,  // 55: plugin.v1.AutomationService.Rebuild:output_type -> plugin.v1.OperationResult
-	0,  // 56: plugin.v1.AutomationService.ResetPassword:output_type -> plugin.v1.OperationResult
-	0,  // 57: plugin.v1.AutomationService.ElasticUpdate:output_type -> plugin.v1.OperationResult
-	0,  // 58: plugin.v1.AutomationService.Lock:output_type -> plugin.v1.OperationResult
-	0,  // 59: plugin.v1.AutomationService.Unlock:output_type -> plugin.v1.OperationResult
-	0,  // 60: plugin.v1.AutomationService.Renew:output_type -> plugin.v1.OperationResult
-	0,  // 61: plugin.v1.AutomationService.Destroy:output_type -> plugin.v1.OperationResult
-	30, // 62: plugin.v1.AutomationService.GetPanelURL:output_type -> plugin.v1.GetPanelURLResponse
-	32, // 63: plugin.v1.AutomationService.GetVNCURL:output_type -> plugin.v1.GetVNCURLResponse
-	34, // 64: plugin.v1.AutomationService.GetMonitor:output_type -> plugin.v1.GetMonitorResponse
-	37, // 65: plugin.v1.AutomationService.ListPortMappings:output_type -> plugin.v1.ListPortMappingsResponse
-	0,  // 66: plugin.v1.AutomationService.AddPortMapping:output_type -> plugin.v1.OperationResult
-	0,  // 67: plugin.v1.AutomationService.DeletePortMapping:output_type -> plugin.v1.OperationResult
-	41, // 68: plugin.v1.AutomationService.FindPortCandidates:output_type -> plugin.v1.FindPortCandidatesResponse
-	44, // 69: plugin.v1.AutomationService.ListBackups:output_type -> plugin.v1.ListBackupsResponse
-	0,  // 70: plugin.v1.AutomationService.CreateBackup:output_type -> plugin.v1.OperationResult
-	0,  // 71: plugin.v1.AutomationService.DeleteBackup:output_type -> plugin.v1.OperationResult
-	0,  // 72: plugin.v1.AutomationService.RestoreBackup:output_type -> plugin.v1.OperationResult
-	50, // 73: plugin.v1.AutomationService.ListSnapshots:output_type -> plugin.v1.ListSnapshotsResponse
-	0,  // 74: plugin.v1.AutomationService.CreateSnapshot:output_type -> plugin.v1.OperationResult
-	0,  // 75: plugin.v1.AutomationService.DeleteSnapshot:output_type -> plugin.v1.OperationResult
-	0,  // 76: plugin.v1.AutomationService.RestoreSnapshot:output_type -> plugin.v1.OperationResult
-	56, // 77: plugin.v1.AutomationService.ListFirewallRules:output_type -> plugin.v1.ListFirewallRulesResponse
-	0,  // 78: plugin.v1.AutomationService.AddFirewallRule:output_type -> plugin.v1.OperationResult
-	0,  // 79: plugin.v1.AutomationService.DeleteFirewallRule:output_type -> plugin.v1.OperationResult
-	45, // [45:80] is the sub-list for method output_type
-	10, // [10:45] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	59, // 0: plugin.v1.AutomationInstance.ips:type_name -> plugin.v1.AutomationInstanceIP
+	1,  // 1: plugin.v1.ListAreasResponse.items:type_name -> plugin.v1.AutomationArea
+	2,  // 2: plugin.v1.ListLinesResponse.items:type_name -> plugin.v1.AutomationLine
+	3,  // 3: plugin.v1.ListPackagesResponse.items:type_name -> plugin.v1.AutomationPackage
+	4,  // 4: plugin.v1.ListImagesResponse.items:type_name -> plugin.v1.AutomationImage
+	5,  // 5: plugin.v1.GetInstanceResponse.instance:type_name -> plugin.v1.AutomationInstance
+	16, // 6: plugin.v1.ListInstancesSimpleResponse.items:type_name -> plugin.v1.AutomationInstanceSimple
+	35, // 7: plugin.v1.ListPortMappingsResponse.items:type_name -> plugin.v1.AutomationPortMapping
+	42, // 8: plugin.v1.ListBackupsResponse.items:type_name -> plugin.v1.AutomationBackup
+	48, // 9: plugin.v1.ListSnapshotsResponse.items:type_name -> plugin.v1.AutomationSnapshot
+	54, // 10: plugin.v1.ListFirewallRulesResponse.items:type_name -> plugin.v1.AutomationFirewallRule
+	61, // 11: plugin.v1.AutomationService.ListAreas:input_type -> plugin.v1.Empty
+	61, // 12: plugin.v1.AutomationService.ListLines:input_type -> plugin.v1.Empty
+	8,  // 13: plugin.v1.AutomationService.ListPackages:input_type -> plugin.v1.ListPackagesRequest
+	10, // 14: plugin.v1.AutomationService.ListImages:input_type -> plugin.v1.ListImagesRequest
+	12, // 15: plugin.v1.AutomationService.CreateInstance:input_type -> plugin.v1.CreateInstanceRequest
+	14, // 16: plugin.v1.AutomationService.GetInstance:input_type -> plugin.v1.GetInstanceRequest
+	17, // 17: plugin.v1.AutomationService.ListInstancesSimple:input_type -> plugin.v1.ListInstancesSimpleRequest
+	19, // 18: plugin.v1.AutomationService.Start:input_type -> plugin.v1.StartRequest
+	20, // 19: plugin.v1.AutomationService.Shutdown:input_type -> plugin.v1.ShutdownRequest
+	21, // 20: plugin.v1.AutomationService.Reboot:input_type -> plugin.v1.RebootRequest
+	22, // 21: plugin.v1.AutomationService.Rebuild:input_type -> plugin.v1.RebuildRequest
+	23, // 22: plugin.v1.AutomationService.ResetPassword:input_type -> plugin.v1.ResetPasswordRequest
+	24, // 23: plugin.v1.AutomationService.ElasticUpdate:input_type -> plugin.v1.ElasticUpdateRequest
+	25, // 24: plugin.v1.AutomationService.Lock:input_type -> plugin.v1.LockRequest
+	26, // 25: plugin.v1.AutomationService.Unlock:input_type -> plugin.v1.UnlockRequest
+	27, // 26: plugin.v1.AutomationService.Renew:input_type -> plugin.v1.RenewRequest
+	28, // 27: plugin.v1.AutomationService.Destroy:input_type -> plugin.v1.DestroyRequest
+	29, // 28: plugin.v1.AutomationService.GetPanelURL:input_type -> plugin.v1.GetPanelURLRequest
+	31, // 29: plugin.v1.AutomationService.GetVNCURL:input_type -> plugin.v1.GetVNCURLRequest
+	33, // 30: plugin.v1.AutomationService.GetMonitor:input_type -> plugin.v1.GetMonitorRequest
+	36, // 31: plugin.v1.AutomationService.ListPortMappings:input_type -> plugin.v1.ListPortMappingsRequest
+	38, // 32: plugin.v1.AutomationService.AddPortMapping:input_type -> plugin.v1.AddPortMappingRequest
+	39, // 33: plugin.v1.AutomationService.DeletePortMapping:input_type -> plugin.v1.DeletePortMappingRequest
+	40, // 34: plugin.v1.AutomationService.FindPortCandidates:input_type -> plugin.v1.FindPortCandidatesRequest
+	43, // 35: plugin.v1.AutomationService.ListBackups:input_type -> plugin.v1.ListBackupsRequest
+	45, // 36: plugin.v1.AutomationService.CreateBackup:input_type -> plugin.v1.CreateBackupRequest
+	46, // 37: plugin.v1.AutomationService.DeleteBackup:input_type -> plugin.v1.DeleteBackupRequest
+	47, // 38: plugin.v1.AutomationService.RestoreBackup:input_type -> plugin.v1.RestoreBackupRequest
+	49, // 39: plugin.v1.AutomationService.ListSnapshots:input_type -> plugin.v1.ListSnapshotsRequest
+	51, // 40: plugin.v1.AutomationService.CreateSnapshot:input_type -> plugin.v1.CreateSnapshotRequest
+	52, // 41: plugin.v1.AutomationService.DeleteSnapshot:input_type -> plugin.v1.DeleteSnapshotRequest
+	53, // 42: plugin.v1.AutomationService.RestoreSnapshot:input_type -> plugin.v1.RestoreSnapshotRequest
+	55, // 43: plugin.v1.AutomationService.ListFirewallRules:input_type -> plugin.v1.ListFirewallRulesRequest
+	57, // 44: plugin.v1.AutomationService.AddFirewallRule:input_type -> plugin.v1.AddFirewallRuleRequest
+	58, // 45: plugin.v1.AutomationService.DeleteFirewallRule:input_type -> plugin.v1.DeleteFirewallRuleRequest
+	60, // 46: plugin.v1.AutomationService.SetReverseDNS:input_type -> plugin.v1.SetReverseDNSRequest
+	6,  // 47: plugin.v1.AutomationService.ListAreas:output_type -> plugin.v1.ListAreasResponse
+	7,  // 48: plugin.v1.AutomationService.ListLines:output_type -> plugin.v1.ListLinesResponse
+	9,  // 49: plugin.v1.AutomationService.ListPackages:output_type -> plugin.v1.ListPackagesResponse
+	11, // 50: plugin.v1.AutomationService.ListImages:output_type -> plugin.v1.ListImagesResponse
+	13, // 51: plugin.v1.AutomationService.CreateInstance:output_type -> plugin.v1.CreateInstanceResponse
+	15, // 52: plugin.v1.AutomationService.GetInstance:output_type -> plugin.v1.GetInstanceResponse
+	18, // 53: plugin.v1.AutomationService.ListInstancesSimple:output_type -> plugin.v1.ListInstancesSimpleResponse
+	0,  // 54: plugin.v1.AutomationService.Start:output_type -> plugin.v1.OperationResult
+	0,  // 55: plugin.v1.AutomationService.Shutdown:output_type -> plugin.v1.OperationResult
+	0,  // 56: plugin.v1.AutomationService.Reboot:output_type -> plugin.v1.OperationResult
+	0,  // 57: plugin.v1.AutomationService.Rebuild:output_type -> plugin.v1.OperationResult
+	0,  // 58: plugin.v1.AutomationService.ResetPassword:output_type -> plugin.v1.OperationResult
+	0,  // 59: plugin.v1.AutomationService.ElasticUpdate:output_type -> plugin.v1.OperationResult
+	0,  // 60: plugin.v1.AutomationService.Lock:output_type -> plugin.v1.OperationResult
+	0,  // 61: plugin.v1.AutomationService.Unlock:output_type -> plugin.v1.OperationResult
+	0,  // 62: plugin.v1.AutomationService.Renew:output_type -> plugin.v1.OperationResult
+	0,  // 63: plugin.v1.AutomationService.Destroy:output_type -> plugin.v1.OperationResult
+	30, // 64: plugin.v1.AutomationService.GetPanelURL:output_type -> plugin.v1.GetPanelURLResponse
+	32, // 65: plugin.v1.AutomationService.GetVNCURL:output_type -> plugin.v1.GetVNCURLResponse
+	34, // 66: plugin.v1.AutomationService.GetMonitor:output_type -> plugin.v1.GetMonitorResponse
+	37, // 67: plugin.v1.AutomationService.ListPortMappings:output_type -> plugin.v1.ListPortMappingsResponse
+	0,  // 68: plugin.v1.AutomationService.AddPortMapping:output_type -> plugin.v1.OperationResult
+	0,  // 69: plugin.v1.AutomationService.DeletePortMapping:output_type -> plugin.v1.OperationResult
+	41, // 70: plugin.v1.AutomationService.FindPortCandidates:output_type -> plugin.v1.FindPortCandidatesResponse
+	44, // 71: plugin.v1.AutomationService.ListBackups:output_type -> plugin.v1.ListBackupsResponse
+	0,  // 72: plugin.v1.AutomationService.CreateBackup:output_type -> plugin.v1.OperationResult
+	0,  // 73: plugin.v1.AutomationService.DeleteBackup:output_type -> plugin.v1.OperationResult
+	0,  // 74: plugin.v1.AutomationService.RestoreBackup:output_type -> plugin.v1.OperationResult
+	50, // 75: plugin.v1.AutomationService.ListSnapshots:output_type -> plugin.v1.ListSnapshotsResponse
+	0,  // 76: plugin.v1.AutomationService.CreateSnapshot:output_type -> plugin.v1.OperationResult
+	0,  // 77: plugin.v1.AutomationService.DeleteSnapshot:output_type -> plugin.v1.OperationResult
+	0,  // 78: plugin.v1.AutomationService.RestoreSnapshot:output_type -> plugin.v1.OperationResult
+	56, // 79: plugin.v1.AutomationService.ListFirewallRules:output_type -> plugin.v1.ListFirewallRulesResponse
+	0,  // 80: plugin.v1.AutomationService.AddFirewallRule:output_type -> plugin.v1.OperationResult
+	0,  // 81: plugin.v1.AutomationService.DeleteFirewallRule:output_type -> plugin.v1.OperationResult
+	0,  // 82: plugin.v1.AutomationService.SetReverseDNS:output_type -> plugin.v1.OperationResult
+	47, // [47:83] is the sub-list for method output_type
+	11, // [11:47] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_plugin_v1_automation_proto_init() }
@@ -3770,7 +3971,7 @@ func file_plugin_v1_automation_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_plugin_v1_automation_proto_rawDesc), len(file_plugin_v1_automation_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   59,
+			NumMessages:   61,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -54,6 +54,7 @@ const (
 	AutomationService_ListFirewallRules_FullMethodName   = "/plugin.v1.AutomationService/ListFirewallRules"
 	AutomationService_AddFirewallRule_FullMethodName     = "/plugin.v1.AutomationService/AddFirewallRule"
 	AutomationService_DeleteFirewallRule_FullMethodName  = "/plugin.v1.AutomationService/DeleteFirewallRule"
+	AutomationService_SetReverseDNS_FullMethodName       = "/plugin.v1.AutomationService/SetReverseDNS"
 )
 
 // AutomationServiceClient is the client API for AutomationService service.
@@ -101,6 +102,8 @@ type AutomationServiceClient interface {
 	ListFirewallRules(ctx context.Context, in *ListFirewallRulesRequest, opts ...grpc.CallOption) (*ListFirewallRulesResponse, error)
 	AddFirewallRule(ctx context.Context, in *AddFirewallRuleRequest, opts ...grpc.CallOption) (*OperationResult, error)
 	DeleteFirewallRule(ctx context.Context, in *DeleteFirewallRuleRequest, opts ...grpc.CallOption) (*OperationResult, error)
+	// Reverse DNS (optional; gated by AUTOMATION_FEATURE_RDNS)
+	SetReverseDNS(ctx context.Context, in *SetReverseDNSRequest, opts ...grpc.CallOption) (*OperationResult, error)
 }
 
 type automationServiceClient struct {
@@ -461,6 +464,16 @@ func (c *automationServiceClient) DeleteFirewallRule(ctx context.Context, in *De
 	return out, nil
 }
 
+func (c *automationServiceClient) SetReverseDNS(ctx context.Context, in *SetReverseDNSRequest, opts ...grpc.CallOption) (*OperationResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OperationResult)
+	err := c.cc.Invoke(ctx, AutomationService_SetReverseDNS_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AutomationServiceServer is the server API for AutomationService service.
 // All implementations must embed UnimplementedAutomationServiceServer
 // for forward compatibility.
@@ -506,6 +519,8 @@ type AutomationServiceServer interface {
 	ListFirewallRules(context.Context, *ListFirewallRulesRequest) (*ListFirewallRulesResponse, error)
 	AddFirewallRule(context.Context, *AddFirewallRuleRequest) (*OperationResult, error)
 	DeleteFirewallRule(context.Context, *DeleteFirewallRuleRequest) (*OperationResult, error)
+	// Reverse DNS (optional; gated by AUTOMATION_FEATURE_RDNS)
+	SetReverseDNS(context.Context, *SetReverseDNSRequest) (*OperationResult, error)
 	mustEmbedUnimplementedAutomationServiceServer()
 }
 
@@ -621,6 +636,9 @@ func (UnimplementedAutomationServiceServer) AddFirewallRule(context.Context, *Ad
 func (UnimplementedAutomationServiceServer) DeleteFirewallRule(context.Context, *DeleteFirewallRuleRequest) (*OperationResult, error) {
 	return nil, status.Error(codes.Unimplemented, "method DeleteFirewallRule not implemented")
 }
+func (UnimplementedAutomationServiceServer) SetReverseDNS(context.Context, *SetReverseDNSRequest) (*OperationResult, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetReverseDNS not implemented")
+}
 func (UnimplementedAutomationServiceServer) mustEmbedUnimplementedAutomationServiceServer() {}
 func (UnimplementedAutomationServiceServer) testEmbeddedByValue()                           {}
 
@@ -1272,6 +1290,24 @@ func _AutomationService_DeleteFirewallRule_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AutomationService_SetReverseDNS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetReverseDNSRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AutomationServiceServer).SetReverseDNS(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AutomationService_SetReverseDNS_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AutomationServiceServer).SetReverseDNS(ctx, req.(*SetReverseDNSRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AutomationService_ServiceDesc is the grpc.ServiceDesc for AutomationService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -1419,6 +1455,10 @@ var AutomationService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteFirewallRule",
 			Handler:    _AutomationService_DeleteFirewallRule_Handler,
 		},
+		{
+			MethodName: "SetReverseDNS",
+			Handler:    _AutomationService_SetReverseDNS_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "plugin/v1/automation.proto",
@@ -31,6 +31,12 @@ const (
 	AutomationFeature_AUTOMATION_FEATURE_BACKUP       AutomationFeature = 4
 	AutomationFeature_AUTOMATION_FEATURE_SNAPSHOT     AutomationFeature = 5
 	AutomationFeature_AUTOMATION_FEATURE_FIREWALL     AutomationFeature = 6
+	// Installs CreateInstanceRequest/RebuildRequest.ssh_public_keys.
+	AutomationFeature_AUTOMATION_FEATURE_SSH_KEYS AutomationFeature = 7
+	// Applies CreateInstanceRequest/RebuildRequest.user_data via cloud-init.
+	AutomationFeature_AUTOMATION_FEATURE_CLOUD_INIT AutomationFeature = 8
+	// Implements AutomationService.SetReverseDNS.
+	AutomationFeature_AUTOMATION_FEATURE_RDNS AutomationFeature = 9
 )
 
 // Enum value maps for AutomationFeature.
@@ -43,6 +49,9 @@ var (
 		4: "AUTOMATION_FEATURE_BACKUP",
 		5: "AUTOMATION_FEATURE_SNAPSHOT",
 		6: "AUTOMATION_FEATURE_FIREWALL",
+		7: "AUTOMATION_FEATURE_SSH_KEYS",
+		8: "AUTOMATION_FEATURE_CLOUD_INIT",
+		9: "AUTOMATION_FEATURE_RDNS",
 	}
 	AutomationFeature_value = map[string]int32{
 		"AUTOMATION_FEATURE_UNSPECIFIED":  0,
@@ -52,6 +61,9 @@ var (
 		"AUTOMATION_FEATURE_BACKUP":       4,
 		"AUTOMATION_FEATURE_SNAPSHOT":     5,
 		"AUTOMATION_FEATURE_FIREWALL":     6,
+		"AUTOMATION_FEATURE_SSH_KEYS":     7,
+		"AUTOMATION_FEATURE_CLOUD_INIT":   8,
+		"AUTOMATION_FEATURE_RDNS":         9,
 	}
 )
 
@@ -286,6 +298,71 @@ func (x *AutomationCapability) GetCatalogReadonly() bool {
 	return false
 }
 
+type NotifyCapability struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Channel kind shown in the admin UI, e.g. "telegram", "dingtalk", "wecom",
+	// "slack", "email".
+	Channel string `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	// The channel delivers to explicit recipients (email addresses, chat ids)
+	// instead of a fixed destination configured on the instance.
+	Recipients bool `protobuf:"varint,2,opt,name=recipients,proto3" json:"recipients,omitempty"`
+	// Content may contain HTML; otherwise plain text / markdown is sent.
+	Html          bool `protobuf:"varint,3,opt,name=html,proto3" json:"html,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotifyCapability) Reset() {
+	*x = NotifyCapability{}
+	mi := &file_plugin_v1_manifest_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotifyCapability) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotifyCapability) ProtoMessage() {}
+
+func (x *NotifyCapability) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_v1_manifest_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotifyCapability.ProtoReflect.Descriptor instead.
+func (*NotifyCapability) Descriptor() ([]byte, []int) {
+	return file_plugin_v1_manifest_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *NotifyCapability) GetChannel() string {
+	if x != nil {
+		return x.Channel
+	}
+	return ""
+}
+
+func (x *NotifyCapability) GetRecipients() bool {
+	if x != nil {
+		return x.Recipients
+	}
+	return false
+}
+
+func (x *NotifyCapability) GetHtml() bool {
+	if x != nil {
+		return x.Html
+	}
+	return false
+}
+
 type Manifest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	PluginId      string                 `protobuf:"bytes,1,opt,name=plugin_id,json=pluginId,proto3" json:"plugin_id,omitempty"`
@@ -296,13 +373,14 @@ type Manifest struct {
 	Payment       *PaymentCapability     `protobuf:"bytes,11,opt,name=payment,proto3,oneof" json:"payment,omitempty"`
 	Kyc           *KycCapability         `protobuf:"bytes,12,opt,name=kyc,proto3,oneof" json:"kyc,omitempty"`
 	Automation    *AutomationCapability  `protobuf:"bytes,13,opt,name=automation,proto3,oneof" json:"automation,omitempty"`
+	Notify        *NotifyCapability      `protobuf:"bytes,14,opt,name=notify,proto3,oneof" json:"notify,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Manifest) Reset() {
 	*x = Manifest{}
-	mi := &file_plugin_v1_manifest_proto_msgTypes[4]
+	mi := &file_plugin_v1_manifest_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -314,7 +392,7 @@ func (x *Manifest) String() string {
 func (*Manifest) ProtoMessage() {}
 
 func (x *Manifest) ProtoReflect() protoreflect.Message {
-	mi := &file_plugin_v1_manifest_proto_msgTypes[4]
+	mi := &file_plugin_v1_manifest_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -327,7 +405,7 @@ func (x *Manifest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Manifest.ProtoReflect.Descriptor instead.
 func (*Manifest) Descriptor() ([]byte, []int) {
-	return file_plugin_v1_manifest_proto_rawDescGZIP(), []int{4}
+	return file_plugin_v1_manifest_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Manifest) GetPluginId() string {
@@ -386,6 +464,13 @@ func (x *Manifest) GetAutomation() *AutomationCapability {
 	return nil
 }
 
+func (x *Manifest) GetNotify() *NotifyCapability {
+	if x != nil {
+		return x.Notify
+	}
+	return nil
+}
+
 var File_plugin_v1_manifest_proto protoreflect.FileDescriptor
 
 const file_plugin_v1_manifest_proto_rawDesc = "" +
@@ -404,7 +489,13 @@ const file_plugin_v1_manifest_proto_rawDesc = "" +
 	"\x10catalog_readonly\x18\x03 \x01(\bR\x0fcatalogReadonly\x1aF\n" +
 	"\x18NotSupportedReasonsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\x05R\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x87\x03\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"`\n" +
+	"\x10NotifyCapability\x12\x18\n" +
+	"\achannel\x18\x01 \x01(\tR\achannel\x12\x1e\n" +
+	"\n" +
+	"recipients\x18\x02 \x01(\bR\n" +
+	"recipients\x12\x12\n" +
+	"\x04html\x18\x03 \x01(\bR\x04html\"\xcc\x03\n" +
 	"\bManifest\x12\x1b\n" +
 	"\tplugin_id\x18\x01 \x01(\tR\bpluginId\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
@@ -416,12 +507,14 @@ const file_plugin_v1_manifest_proto_rawDesc = "" +
 	"\x03kyc\x18\f \x01(\v2\x18.plugin.v1.KycCapabilityH\x02R\x03kyc\x88\x01\x01\x12D\n" +
 	"\n" +
 	"automation\x18\r \x01(\v2\x1f.plugin.v1.AutomationCapabilityH\x03R\n" +
-	"automation\x88\x01\x01B\x06\n" +
+	"automation\x88\x01\x01\x128\n" +
+	"\x06notify\x18\x0e \x01(\v2\x1b.plugin.v1.NotifyCapabilityH\x04R\x06notify\x88\x01\x01B\x06\n" +
 	"\x04_smsB\n" +
 	"\n" +
 	"\b_paymentB\x06\n" +
 	"\x04_kycB\r\n" +
-	"\v_automation*\x84\x02\n" +
+	"\v_automationB\t\n" +
+	"\a_notify*\xe5\x02\n" +
 	"\x11AutomationFeature\x12\"\n" +
 	"\x1eAUTOMATION_FEATURE_UNSPECIFIED\x10\x00\x12#\n" +
 	"\x1fAUTOMATION_FEATURE_CATALOG_SYNC\x10\x01\x12 \n" +
@@ -429,7 +522,10 @@ const file_plugin_v1_manifest_proto_rawDesc = "" +
 	"\x1fAUTOMATION_FEATURE_PORT_MAPPING\x10\x03\x12\x1d\n" +
 	"\x19AUTOMATION_FEATURE_BACKUP\x10\x04\x12\x1f\n" +
 	"\x1bAUTOMATION_FEATURE_SNAPSHOT\x10\x05\x12\x1f\n" +
-	"\x1bAUTOMATION_FEATURE_FIREWALL\x10\x06B Z\x1exiaoheiplay/plugin/v1;pluginv1b\x06proto3"
+	"\x1bAUTOMATION_FEATURE_FIREWALL\x10\x06\x12\x1f\n" +
+	"\x1bAUTOMATION_FEATURE_SSH_KEYS\x10\a\x12!\n" +
+	"\x1dAUTOMATION_FEATURE_CLOUD_INIT\x10\b\x12\x1b\n" +
+	"\x17AUTOMATION_FEATURE_RDNS\x10\tB Z\x1exiaoheiplay/plugin/v1;pluginv1b\x06proto3"
 
 var (
 	file_plugin_v1_manifest_proto_rawDescOnce sync.Once
@@ -444,28 +540,30 @@ func file_plugin_v1_manifest_proto_rawDescGZIP() []byte {
 }
 
 var file_plugin_v1_manifest_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_plugin_v1_manifest_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_plugin_v1_manifest_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_plugin_v1_manifest_proto_goTypes = []any{
 	(AutomationFeature)(0),       // 0: plugin.v1.AutomationFeature
 	(*SmsCapability)(nil),        // 1: plugin.v1.SmsCapability
 	(*PaymentCapability)(nil),    // 2: plugin.v1.PaymentCapability
 	(*KycCapability)(nil),        // 3: plugin.v1.KycCapability
 	(*AutomationCapability)(nil), // 4: plugin.v1.AutomationCapability
-	(*Manifest)(nil),             // 5: plugin.v1.Manifest
-	nil,                          // 6: plugin.v1.AutomationCapability.NotSupportedReasonsEntry
+	(*NotifyCapability)(nil),     // 5: plugin.v1.NotifyCapability
+	(*Manifest)(nil),             // 6: plugin.v1.Manifest
+	nil,                          // 7: plugin.v1.AutomationCapability.NotSupportedReasonsEntry
 }
 var file_plugin_v1_manifest_proto_depIdxs = []int32{
 	0, // 0: plugin.v1.AutomationCapability.features:type_name -> plugin.v1.AutomationFeature
-	6, // 1: plugin.v1.AutomationCapability.not_supported_reasons:type_name -> plugin.v1.AutomationCapability.NotSupportedReasonsEntry
+	7, // 1: plugin.v1.AutomationCapability.not_supported_reasons:type_name -> plugin.v1.AutomationCapability.NotSupportedReasonsEntry
 	1, // 2: plugin.v1.Manifest.sms:type_name -> plugin.v1.SmsCapability
 	2, // 3: plugin.v1.Manifest.payment:type_name -> plugin.v1.PaymentCapability
 	3, // 4: plugin.v1.Manifest.kyc:type_name -> plugin.v1.KycCapability
 	4, // 5: plugin.v1.Manifest.automation:type_name -> plugin.v1.AutomationCapability
-	6, // [6:6] is the sub-list for method output_type
-	6, // [6:6] is the sub-list for method input_type
-	6, // [6:6] is the sub-list for extension type_name
-	6, // [6:6] is the sub-list for extension extendee
-	0, // [0:6] is the sub-list for field type_name
+	5, // 6: plugin.v1.Manifest.notify:type_name -> plugin.v1.NotifyCapability
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_plugin_v1_manifest_proto_init() }
@@ -473,14 +571,14 @@ func file_plugin_v1_manifest_proto_init() {
 	if File_plugin_v1_manifest_proto != nil {
 		return
 	}
-	file_plugin_v1_manifest_proto_msgTypes[4].OneofWrappers = []any{}
+	file_plugin_v1_manifest_proto_msgTypes[5].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_plugin_v1_manifest_proto_rawDesc), len(file_plugin_v1_manifest_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   6,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -0,0 +1,257 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.0
+// source: plugin/v1/notify.proto
+
+package pluginv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SendNotifyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Notification type being delivered, e.g. "order.pending_review".
+	Type    string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Title   string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Content string `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	// Optional recipients for channels that declare notify.recipients.
+	Recipients []string          `protobuf:"bytes,4,rep,name=recipients,proto3" json:"recipients,omitempty"`
+	Vars       map[string]string `protobuf:"bytes,5,rep,name=vars,proto3" json:"vars,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Stable per logical notification; plugins may use it to drop duplicates.
+	IdempotencyKey string `protobuf:"bytes,6,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SendNotifyRequest) Reset() {
+	*x = SendNotifyRequest{}
+	mi := &file_plugin_v1_notify_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendNotifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendNotifyRequest) ProtoMessage() {}
+
+func (x *SendNotifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_v1_notify_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendNotifyRequest.ProtoReflect.Descriptor instead.
+func (*SendNotifyRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_v1_notify_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SendNotifyRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SendNotifyRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *SendNotifyRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *SendNotifyRequest) GetRecipients() []string {
+	if x != nil {
+		return x.Recipients
+	}
+	return nil
+}
+
+func (x *SendNotifyRequest) GetVars() map[string]string {
+	if x != nil {
+		return x.Vars
+	}
+	return nil
+}
+
+func (x *SendNotifyRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type SendNotifyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ok            bool                   `protobuf:"varint,1,opt,name=ok,proto3" json:"ok,omitempty"`
+	MessageId     string                 `protobuf:"bytes,2,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	ErrorCode     string                 `protobuf:"bytes,4,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendNotifyResponse) Reset() {
+	*x = SendNotifyResponse{}
+	mi := &file_plugin_v1_notify_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendNotifyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendNotifyResponse) ProtoMessage() {}
+
+func (x *SendNotifyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_v1_notify_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendNotifyResponse.ProtoReflect.Descriptor instead.
+func (*SendNotifyResponse) Descriptor() ([]byte, []int) {
+	return file_plugin_v1_notify_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SendNotifyResponse) GetOk() bool {
+	if x != nil {
+		return x.Ok
+	}
+	return false
+}
+
+func (x *SendNotifyResponse) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+func (x *SendNotifyResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *SendNotifyResponse) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+var File_plugin_v1_notify_proto protoreflect.FileDescriptor
+
+const file_plugin_v1_notify_proto_rawDesc = "" +
+	"\n" +
+	"\x16plugin/v1/notify.proto\x12\tplugin.v1\"\x95\x02\n" +
+	"\x11SendNotifyRequest\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\x12\x1e\n" +
+	"\n" +
+	"recipients\x18\x04 \x03(\tR\n" +
+	"recipients\x12:\n" +
+	"\x04vars\x18\x05 \x03(\v2&.plugin.v1.SendNotifyRequest.VarsEntryR\x04vars\x12'\n" +
+	"\x0fidempotency_key\x18\x06 \x01(\tR\x0eidempotencyKey\x1a7\n" +
+	"\tVarsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"x\n" +
+	"\x12SendNotifyResponse\x12\x0e\n" +
+	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x02 \x01(\tR\tmessageId\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x04 \x01(\tR\terrorCode2T\n" +
+	"\rNotifyService\x12C\n" +
+	"\x04Send\x12\x1c.plugin.v1.SendNotifyRequest\x1a\x1d.plugin.v1.SendNotifyResponseB Z\x1exiaoheiplay/plugin/v1;pluginv1b\x06proto3"
+
+var (
+	file_plugin_v1_notify_proto_rawDescOnce sync.Once
+	file_plugin_v1_notify_proto_rawDescData []byte
+)
+
+func file_plugin_v1_notify_proto_rawDescGZIP() []byte {
+	file_plugin_v1_notify_proto_rawDescOnce.Do(func() {
+		file_plugin_v1_notify_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_plugin_v1_notify_proto_rawDesc), len(file_plugin_v1_notify_proto_rawDesc)))
+	})
+	return file_plugin_v1_notify_proto_rawDescData
+}
+
+var file_plugin_v1_notify_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_plugin_v1_notify_proto_goTypes = []any{
+	(*SendNotifyRequest)(nil),  // 0: plugin.v1.SendNotifyRequest
+	(*SendNotifyResponse)(nil), // 1: plugin.v1.SendNotifyResponse
+	nil,                        // 2: plugin.v1.SendNotifyRequest.VarsEntry
+}
+var file_plugin_v1_notify_proto_depIdxs = []int32{
+	2, // 0: plugin.v1.SendNotifyRequest.vars:type_name -> plugin.v1.SendNotifyRequest.VarsEntry
+	0, // 1: plugin.v1.NotifyService.Send:input_type -> plugin.v1.SendNotifyRequest
+	1, // 2: plugin.v1.NotifyService.Send:output_type -> plugin.v1.SendNotifyResponse
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_plugin_v1_notify_proto_init() }
+func file_plugin_v1_notify_proto_init() {
+	if File_plugin_v1_notify_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_plugin_v1_notify_proto_rawDesc), len(file_plugin_v1_notify_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_plugin_v1_notify_proto_goTypes,
+		DependencyIndexes: file_plugin_v1_notify_proto_depIdxs,
+		MessageInfos:      file_plugin_v1_notify_proto_msgTypes,
+	}.Build()
+	File_plugin_v1_notify_proto = out.File
+	file_plugin_v1_notify_proto_goTypes = nil
+	file_plugin_v1_notify_proto_depIdxs = nil
+}
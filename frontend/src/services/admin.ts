@@ -2,10 +2,23 @@ import { http } from "./http";
 import type {
   AdminProfile,
   AdminUser,
+  NotificationPreferences,
+  NotificationPreferencesPayload,
   ApiList,
   AutomationConfig,
   AutomationSyncLog,
   BillingCycle,
+  Addon,
+  VPSBulkJob,
+  VPSBulkJobItem,
+  VPSMigration,
+  IPAddress,
+  IPAddressEvent,
+  AdminVPSTrial,
+  OrderApprovalRule,
+  OrderApprovalEvaluation,
+  OrderApprovalDryRunResult,
+  DashboardTrials,
   DashboardOverview,
   DashboardRevenue,
   DashboardStatus,
@@ -30,10 +43,14 @@ import type {
   RealNameConfig,
   RealNameProvider,
   RealNameRecordListResponse,
+  RealNameVerification,
+  CompanyVerification,
+  CompanyVerificationListResponse,
   Region,
   RobotConfig,
   ServerStatus,
   ProbeNode,
+  ProbeAgentRelease,
   ProbeSLA,
   ProbeLogSession,
   SMTPConfig,
@@ -52,11 +69,32 @@ import type {
   VPSInstance,
   WalletOrderListResponse,
   WalletOrder,
+  WalletHold,
+  LedgerAccount,
+  LedgerEntry,
+  LedgerVerification,
+  PayoutAccount,
+  PayoutBatch,
+  FinanceReportDefinition,
+  FinanceReportRun,
+  DunningPolicy,
+  DunningRecord,
+  DunningStep,
+  DunningView,
+  Broadcast,
+  BroadcastDelivery,
+  BroadcastPayload,
+  BroadcastSegment,
+  BroadcastStats,
   DebugStatusResponse,
   DebugLogsResponse,
   PluginListItem,
   PluginDiscoverItem,
+  PluginRepository,
+  PluginRepositoryItem,
   PluginPaymentMethodItem,
+  NotifyChannel,
+  NotifyRoute,
   GoodsType,
   Coupon,
   CouponProductGroup
@@ -80,6 +118,10 @@ export const updateAdminUserRealNameStatus = (id: number | string, payload: Reco
   http.patch(`/admin/api/v1/users/${id}/realname-status`, payload);
 export const adminImpersonateUser = (id: number | string) =>
   http.post(`/admin/api/v1/users/${id}/impersonate`);
+export const downloadAdminUserStatement = (
+  id: number | string,
+  params: { from_at: string; to_at: string; format?: "csv" | "pdf" }
+) => http.get(`/admin/api/v1/users/${id}/statement`, { params, responseType: "blob" });
 export const resetUserPassword = (id: number | string, payload: Record<string, unknown>) =>
   http.post(`/admin/api/v1/users/${id}/reset-password`, payload);
 export const setAdminUserTier = (id: number | string, payload: { group_id: number; expire_at?: string }) =>
@@ -138,6 +180,21 @@ export const rejectAdminOrder = (id: number | string, payload: Record<string, un
 export const deleteAdminOrder = (id: number | string) => http.delete(`/admin/api/v1/orders/${id}`);
 export const markPaidAdminOrder = (id: number | string) => http.post(`/admin/api/v1/orders/${id}/mark-paid`);
 export const retryAdminOrder = (id: number | string) => http.post(`/admin/api/v1/orders/${id}/retry`);
+export const getAdminOrderApproval = (id: number | string) =>
+  http.get<OrderApprovalEvaluation>(`/admin/api/v1/orders/${id}/approval`);
+export const listOrderApprovalRules = () => http.get<ApiList<OrderApprovalRule>>("/admin/api/v1/order-approval-rules");
+export const createOrderApprovalRule = (payload: OrderApprovalRule) =>
+  http.post<OrderApprovalRule>("/admin/api/v1/order-approval-rules", payload);
+export const updateOrderApprovalRule = (id: number | string, payload: OrderApprovalRule) =>
+  http.patch<OrderApprovalRule>(`/admin/api/v1/order-approval-rules/${id}`, payload);
+export const deleteOrderApprovalRule = (id: number | string) => http.delete(`/admin/api/v1/order-approval-rules/${id}`);
+export const dryRunOrderApprovalRules = (payload: {
+  order_ids?: number[];
+  from?: string;
+  to?: string;
+  limit?: number;
+  rules?: OrderApprovalRule[];
+}) => http.post<OrderApprovalDryRunResult>("/admin/api/v1/order-approval-rules/dry-run", payload);
 export const listAdminScheduledTasks = () => http.get<ApiList<Record<string, unknown>>>("/admin/api/v1/scheduled-tasks");
 export const updateAdminScheduledTask = (key: string, payload: Record<string, unknown>) =>
   http.patch(`/admin/api/v1/scheduled-tasks/${key}`, payload);
@@ -160,6 +217,52 @@ export const emergencyRenewAdminVps = (id: number | string, payload: Record<stri
   http.post(`/admin/api/v1/vps/${id}/emergency-renew`, payload);
 export const updateAdminVpsExpire = (id: number | string, payload: Record<string, unknown>) =>
   http.patch(`/admin/api/v1/vps/${id}/expire-at`, payload);
+export const getAdminVpsDunning = (id: number | string) => http.get<DunningView>(`/admin/api/v1/vps/${id}/dunning`);
+export const listAdminVpsBulkJobs = (params?: Record<string, unknown>) =>
+  http.get<ApiList<VPSBulkJob>>("/admin/api/v1/vps/bulk-jobs", { params });
+export const createAdminVpsBulkJob = (payload: Record<string, unknown>) =>
+  http.post<VPSBulkJob>("/admin/api/v1/vps/bulk-jobs", payload);
+export const getAdminVpsBulkJob = (id: number | string, params?: { status?: string }) =>
+  http.get<{ job: VPSBulkJob; items: VPSBulkJobItem[] }>(`/admin/api/v1/vps/bulk-jobs/${id}`, { params });
+export const cancelAdminVpsBulkJob = (id: number | string) =>
+  http.post<VPSBulkJob>(`/admin/api/v1/vps/bulk-jobs/${id}/cancel`);
+export const adminVpsBulkJobStreamPath = (id: number | string) => `/admin/api/v1/vps/bulk-jobs/${id}/stream`;
+export const listAdminVpsMigrations = (params?: { vps_id?: number | string; limit?: number; offset?: number }) =>
+  http.get<ApiList<VPSMigration>>("/admin/api/v1/vps/migrations", { params });
+export const createAdminVpsMigration = (payload: Record<string, unknown>) =>
+  http.post<VPSMigration>("/admin/api/v1/vps/migrations", payload);
+export const getAdminVpsMigration = (id: number | string) => http.get<VPSMigration>(`/admin/api/v1/vps/migrations/${id}`);
+export const retryAdminVpsMigration = (id: number | string) =>
+  http.post<VPSMigration>(`/admin/api/v1/vps/migrations/${id}/retry`);
+export const cancelAdminVpsMigration = (id: number | string) =>
+  http.post<VPSMigration>(`/admin/api/v1/vps/migrations/${id}/cancel`);
+export const listAdminIPAddresses = (params?: {
+  keyword?: string;
+  line_id?: number | string;
+  vps_id?: number | string;
+  status?: string;
+  limit?: number;
+  offset?: number;
+}) => http.get<ApiList<IPAddress>>("/admin/api/v1/ip-addresses", { params });
+export const createAdminIPAddress = (payload: { address: string; line_id?: number; status?: string; note?: string }) =>
+  http.post<IPAddress>("/admin/api/v1/ip-addresses", payload);
+export const getAdminIPAddress = (id: number | string) => http.get<IPAddress>(`/admin/api/v1/ip-addresses/${id}`);
+export const updateAdminIPAddress = (id: number | string, payload: { line_id?: number; status?: string; note?: string }) =>
+  http.patch<IPAddress>(`/admin/api/v1/ip-addresses/${id}`, payload);
+export const deleteAdminIPAddress = (id: number | string) => http.delete(`/admin/api/v1/ip-addresses/${id}`);
+export const listAdminIPAddressEvents = (id: number | string, params?: { limit?: number; offset?: number }) =>
+  http.get<ApiList<IPAddressEvent>>(`/admin/api/v1/ip-addresses/${id}/events`, { params });
+export const reportAdminIPAddressAbuse = (id: number | string, payload: { detail: string; block?: boolean }) =>
+  http.post<IPAddress>(`/admin/api/v1/ip-addresses/${id}/abuse`, payload);
+export const listAdminTrials = (params?: {
+  user_id?: number | string;
+  package_id?: number | string;
+  status?: string;
+  client_ip?: string;
+  device_id?: string;
+  limit?: number;
+  offset?: number;
+}) => http.get<ApiList<AdminVPSTrial>>("/admin/api/v1/trials", { params });
 
 export const listRegions = (params?: Record<string, unknown>) => http.get<ApiList<Region>>("/admin/api/v1/regions", { params });
 export const createRegion = (payload: Record<string, unknown>) => http.post("/admin/api/v1/regions", payload);
@@ -204,6 +307,13 @@ export const deleteBillingCycle = (id: number | string) => http.delete(`/admin/a
 export const bulkDeleteBillingCycles = (ids: Array<number | string>) =>
   http.post("/admin/api/v1/billing-cycles/bulk-delete", { ids });
 
+export const listAddons = (params?: { plan_group_id?: number | string }) =>
+  http.get<ApiList<Addon>>("/admin/api/v1/addons", { params });
+export const createAddon = (payload: Record<string, unknown>) => http.post("/admin/api/v1/addons", payload);
+export const updateAddon = (id: number | string, payload: Record<string, unknown>) =>
+  http.patch(`/admin/api/v1/addons/${id}`, payload);
+export const deleteAddon = (id: number | string) => http.delete(`/admin/api/v1/addons/${id}`);
+
 export const listSystemImages = (params?: Record<string, unknown>) =>
   http.get<ApiList<SystemImage>>("/admin/api/v1/system-images", { params });
 export const createSystemImage = (payload: Record<string, unknown>) => http.post("/admin/api/v1/system-images", payload);
@@ -255,7 +365,92 @@ export const getAdminWalletInfo = (userId: number | string) =>
   http.get(`/admin/api/v1/wallets/${userId}`);
 export const listAdminWalletTransactions = (userId: number | string, params?: Record<string, unknown>) =>
   http.get(`/admin/api/v1/wallets/${userId}/transactions`, { params });
-
+export const listAdminWalletHolds = (userId: number | string, params?: Record<string, unknown>) =>
+  http.get<ApiList<WalletHold>>(`/admin/api/v1/wallets/${userId}/holds`, { params });
+
+// 财务账本
+export const listLedgerAccounts = () => http.get<ApiList<LedgerAccount>>("/admin/api/v1/ledger/accounts");
+export const listLedgerEntries = (params?: {
+  account?: string;
+  kind?: string;
+  ref_type?: string;
+  ref_id?: number;
+  user_id?: number;
+  limit?: number;
+  offset?: number;
+}) => http.get<ApiList<LedgerEntry>>("/admin/api/v1/ledger/entries", { params });
+export const verifyLedger = () => http.post<LedgerVerification>("/admin/api/v1/ledger/verify");
+export const postLedgerOpeningBalances = () => http.post<{ posted: number }>("/admin/api/v1/ledger/opening-balances");
+
+// 提现打款
+export const listAdminPayoutAccounts = (params?: { status?: "pending" | "verified" | "rejected"; limit?: number; offset?: number }) =>
+  http.get<ApiList<PayoutAccount>>("/admin/api/v1/payout-accounts", { params });
+export const verifyPayoutAccount = (id: number | string) => http.post<PayoutAccount>(`/admin/api/v1/payout-accounts/${id}/verify`, {});
+export const rejectPayoutAccount = (id: number | string, payload: { reason?: string }) =>
+  http.post<PayoutAccount>(`/admin/api/v1/payout-accounts/${id}/reject`, payload);
+export const listPayoutBatches = (params?: { status?: string; limit?: number; offset?: number }) =>
+  http.get<ApiList<PayoutBatch>>("/admin/api/v1/payout-batches", { params });
+export const createPayoutBatch = (payload: { channel: "bank" | "alipay"; order_ids?: number[]; note?: string }) =>
+  http.post<PayoutBatch>("/admin/api/v1/payout-batches", payload);
+export const getPayoutBatch = (id: number | string) => http.get<PayoutBatch>(`/admin/api/v1/payout-batches/${id}`);
+export const exportPayoutBatch = (id: number | string) =>
+  http.get(`/admin/api/v1/payout-batches/${id}/export`, { responseType: "blob" });
+export const markPayoutBatchPaid = (
+  id: number | string,
+  payload: { reference: string; failed_items?: { item_id: number; reason?: string }[] }
+) => http.post<PayoutBatch>(`/admin/api/v1/payout-batches/${id}/paid`, payload);
+export const markPayoutBatchFailed = (id: number | string, payload: { reason: string }) =>
+  http.post<PayoutBatch>(`/admin/api/v1/payout-batches/${id}/failed`, payload);
+
+export const listFinanceReports = () => http.get<ApiList<FinanceReportDefinition>>("/admin/api/v1/finance-reports");
+export const createFinanceReport = (payload: {
+  name: string;
+  period: "daily" | "weekly" | "monthly";
+  formats?: ("csv" | "xlsx")[];
+  recipients: string[];
+  enabled?: boolean;
+}) => http.post<FinanceReportDefinition>("/admin/api/v1/finance-reports", payload);
+export const updateFinanceReport = (
+  id: number | string,
+  payload: { name: string; period: "daily" | "weekly" | "monthly"; formats?: ("csv" | "xlsx")[]; recipients: string[]; enabled?: boolean }
+) => http.patch<FinanceReportDefinition>(`/admin/api/v1/finance-reports/${id}`, payload);
+export const deleteFinanceReport = (id: number | string) => http.delete(`/admin/api/v1/finance-reports/${id}`);
+export const runFinanceReport = (id: number | string, payload?: { from_at?: string; to_at?: string }) =>
+  http.post<FinanceReportRun>(`/admin/api/v1/finance-reports/${id}/run`, payload ?? {});
+export const listFinanceReportRuns = (params?: { definition_id?: number; limit?: number; offset?: number }) =>
+  http.get<ApiList<FinanceReportRun>>("/admin/api/v1/finance-report-runs", { params });
+export const getFinanceReportRun = (id: number | string) => http.get<FinanceReportRun>(`/admin/api/v1/finance-report-runs/${id}`);
+export const downloadFinanceReportFile = (id: number | string, fileId: number | string) =>
+  http.get(`/admin/api/v1/finance-report-runs/${id}/files/${fileId}`, { responseType: "blob" });
+export const resendFinanceReportRun = (id: number | string, payload?: { recipients?: string[] }) =>
+  http.post<FinanceReportRun>(`/admin/api/v1/finance-report-runs/${id}/resend`, payload ?? {});
+
+export const listDunningPolicies = () =>
+  http.get<{ items: DunningPolicy[]; default_steps: DunningStep[] }>("/admin/api/v1/dunning-policies");
+export const saveDunningPolicy = (goodsTypeId: number | string, payload: { enabled?: boolean; steps?: DunningStep[] }) =>
+  http.patch<DunningPolicy>(`/admin/api/v1/dunning-policies/${goodsTypeId}`, payload);
+export const deleteDunningPolicy = (goodsTypeId: number | string) => http.delete(`/admin/api/v1/dunning-policies/${goodsTypeId}`);
+export const listDunningRecords = (params?: {
+  instance_id?: number;
+  action?: string;
+  status?: string;
+  limit?: number;
+  offset?: number;
+}) => http.get<ApiList<DunningRecord>>("/admin/api/v1/dunning-records", { params });
+
+// 公告群发
+export const listBroadcasts = (params?: { status?: string; limit?: number; offset?: number }) =>
+  http.get<ApiList<Broadcast>>("/admin/api/v1/broadcasts", { params });
+export const createBroadcast = (payload: BroadcastPayload) => http.post<Broadcast>("/admin/api/v1/broadcasts", payload);
+export const previewBroadcast = (segment: BroadcastSegment) =>
+  http.post<{ recipients: number }>("/admin/api/v1/broadcasts/preview", { segment });
+export const getBroadcast = (id: number | string) =>
+  http.get<{ broadcast: Broadcast; stats: BroadcastStats }>(`/admin/api/v1/broadcasts/${id}`);
+export const listBroadcastDeliveries = (
+  id: number | string,
+  params?: { channel?: string; status?: string; limit?: number; offset?: number }
+) => http.get<ApiList<BroadcastDelivery>>(`/admin/api/v1/broadcasts/${id}/deliveries`, { params });
+export const cancelBroadcast = (id: number | string) => http.post<Broadcast>(`/admin/api/v1/broadcasts/${id}/cancel`);
 // 工单
 export const listAdminTickets = (params?: Record<string, unknown>) =>
   http.get<ApiList<Ticket>>("/admin/api/v1/tickets", { params });
@@ -283,6 +478,22 @@ export const updateRealNameConfig = (payload: RealNameConfig) => http.patch("/ad
 export const listRealNameProviders = () => http.get<{ items: RealNameProvider[] }>("/admin/api/v1/realname/providers");
 export const listRealNameRecords = (params?: Record<string, unknown>) =>
   http.get<RealNameRecordListResponse>("/admin/api/v1/realname/records", { params });
+export const listRealNameReviews = (params?: Record<string, unknown>) =>
+  http.get<RealNameRecordListResponse>("/admin/api/v1/realname/reviews", { params });
+export const approveRealNameReview = (id: number | string, payload?: { reason?: string }) =>
+  http.post<RealNameVerification>(`/admin/api/v1/realname/reviews/${id}/approve`, payload ?? {});
+export const rejectRealNameReview = (id: number | string, payload: { reason: string }) =>
+  http.post<RealNameVerification>(`/admin/api/v1/realname/reviews/${id}/reject`, payload);
+export const getRealNameReviewFile = (id: number | string, uploadId: number | string) =>
+  http.get(`/admin/api/v1/realname/reviews/${id}/files/${uploadId}`, { responseType: "blob" });
+export const listCompanyVerifications = (params?: Record<string, unknown>) =>
+  http.get<CompanyVerificationListResponse>("/admin/api/v1/realname/companies", { params });
+export const approveCompanyVerification = (id: number | string, payload?: { reason?: string }) =>
+  http.post<CompanyVerification>(`/admin/api/v1/realname/companies/${id}/approve`, payload ?? {});
+export const rejectCompanyVerification = (id: number | string, payload: { reason: string }) =>
+  http.post<CompanyVerification>(`/admin/api/v1/realname/companies/${id}/reject`, payload);
+export const getCompanyVerificationFile = (id: number | string, uploadId: number | string) =>
+  http.get(`/admin/api/v1/realname/companies/${id}/files/${uploadId}`, { responseType: "blob" });
 export const getSmtpConfig = () => http.get<SMTPConfig>("/admin/api/v1/integrations/smtp");
 export const updateSmtpConfig = (payload: Record<string, unknown>) => http.patch("/admin/api/v1/integrations/smtp", payload);
 export const testSmtpConfig = (payload: Record<string, unknown>) => http.post("/admin/api/v1/integrations/smtp/test", payload);
@@ -290,6 +501,14 @@ export const getSmsConfig = () => http.get<SMSConfig>("/admin/api/v1/integration
 export const updateSmsConfig = (payload: Record<string, unknown>) => http.patch("/admin/api/v1/integrations/sms", payload);
 export const previewSmsConfig = (payload: Record<string, unknown>) => http.post<{ content?: string }>("/admin/api/v1/integrations/sms/preview", payload);
 export const testSmsConfig = (payload: Record<string, unknown>) => http.post("/admin/api/v1/integrations/sms/test", payload);
+export const getNotifyChannels = () =>
+  http.get<{ channels?: NotifyChannel[]; routes?: NotifyRoute[]; types?: string[]; email_channel?: string }>(
+    "/admin/api/v1/integrations/notify-channels"
+  );
+export const updateNotifyChannels = (payload: { routes: NotifyRoute[]; email_channel?: string }) =>
+  http.patch<{ routes?: NotifyRoute[] }>("/admin/api/v1/integrations/notify-channels", payload);
+export const testNotifyChannel = (payload: { channel: string; recipients?: string[]; title?: string; content?: string }) =>
+  http.post<{ ok?: boolean; message_id?: string }>("/admin/api/v1/integrations/notify-channels/test", payload);
 export const listSmsTemplates = () => http.get<ApiList<SMSTemplate>>("/admin/api/v1/sms-templates");
 export const upsertSmsTemplate = (payload: Record<string, unknown>) => http.post<SMSTemplate>("/admin/api/v1/sms-templates", payload);
 export const updateSmsTemplate = (id: number | string, payload: Record<string, unknown>) =>
@@ -300,6 +519,8 @@ export const getAdminDashboardOverview = () => http.post<DashboardOverview>("/ad
 export const getAdminDashboardRevenue = (params?: Record<string, unknown>) =>
   http.post<DashboardRevenue>("/admin/api/v1/dashboard/revenue", null, { params });
 export const getAdminDashboardVpsStatus = () => http.get<DashboardStatus>("/admin/api/v1/dashboard/vps-status");
+export const getAdminDashboardTrials = (params?: { from?: string; to?: string }) =>
+  http.get<DashboardTrials>("/admin/api/v1/dashboard/trials", { params });
 export const getServerStatus = () => http.get<ServerStatus>("/admin/api/v1/server/status");
 export const getRevenueAnalyticsOverview = (payload: RevenueAnalyticsQuery) =>
   http.post<RevenueAnalyticsOverviewResponse>("/admin/api/v1/dashboard/revenue-analytics/overview", payload);
@@ -317,6 +538,12 @@ export const listAdminProbes = (params?: Record<string, unknown>) =>
   http.get<ApiList<ProbeNode>>("/admin/api/v1/probes", { params });
 export const createAdminProbe = (payload: Record<string, unknown>) =>
   http.post<{ probe?: ProbeNode; enroll_token?: string }>("/admin/api/v1/probes", payload);
+export const listAdminProbeAgentReleases = () =>
+  http.get<{ items?: ProbeAgentRelease[]; desired_version?: string; rollback_timeout_sec?: number }>(
+    "/admin/api/v1/probes/agent-releases"
+  );
+export const updateAdminProbeAgentVersion = (payload: { version: string }) =>
+  http.put<{ ok?: boolean; notified?: number }>("/admin/api/v1/probes/agent-version", payload);
 export const getAdminProbeDetail = (id: number | string, params?: Record<string, unknown>) =>
   http.get<{ probe?: ProbeNode; online?: boolean }>(`/admin/api/v1/probes/${id}`, { params });
 export const updateAdminProbe = (id: number | string, payload: Record<string, unknown>) =>
@@ -354,6 +581,10 @@ export const getAdminProfile = () => http.get<AdminProfile>("/admin/api/v1/profi
 export const updateAdminProfile = (payload: Record<string, unknown>) => http.patch("/admin/api/v1/profile", payload);
 export const changeAdminPassword = (payload: { old_password: string; new_password: string }) =>
   http.post("/admin/api/v1/profile/change-password", payload);
+export const getAdminNotificationPreferences = () =>
+  http.get<NotificationPreferences>("/admin/api/v1/profile/notification-preferences");
+export const updateAdminNotificationPreferences = (payload: NotificationPreferencesPayload) =>
+  http.patch<NotificationPreferences>("/admin/api/v1/profile/notification-preferences", payload);
 
 // CMS
 export const listCmsCategories = (params?: Record<string, unknown>) =>
@@ -401,6 +632,19 @@ export const uploadPaymentPlugin = (file: File, password: string) => {
 export const listAdminPlugins = () => http.get<{ items?: PluginListItem[] }>("/admin/api/v1/plugins");
 export const discoverAdminPlugins = () => http.get<{ items?: PluginDiscoverItem[] }>("/admin/api/v1/plugins/discover");
 
+export const listAdminPluginRepository = () =>
+  http.get<{ repositories?: PluginRepository[]; items?: PluginRepositoryItem[]; errors?: { repository: string; error: string }[] }>(
+    "/admin/api/v1/plugins/repository"
+  );
+
+export const installAdminPluginFromRepository = (payload: {
+  repository: string;
+  category: string;
+  plugin_id: string;
+  version?: string;
+  admin_password?: string;
+}) => http.post<{ ok?: boolean; plugin?: Record<string, unknown> }>("/admin/api/v1/plugins/repository/install", payload);
+
 export const installAdminPlugin = (file: File, adminPassword?: string) => {
   const formData = new FormData();
   formData.append("file", file);
@@ -17,6 +17,7 @@ export interface PluginManifest {
   description?: string;
   capabilities?: {
     sms?: { send?: boolean } | null;
+    notify?: { channel?: string; recipients?: boolean; html?: boolean } | null;
     payment?: { methods?: string[] } | null;
     kyc?: { start?: boolean; query_result?: boolean } | null;
     automation?: {
@@ -41,6 +42,9 @@ export interface PluginListItem {
   last_health_at?: string | null;
   health_status?: string;
   health_message?: string;
+  restarts?: number;
+  circuit_state?: "closed" | "open" | "half_open" | string;
+  last_crash_at?: string | null;
   manifest?: PluginManifest;
   entry?: {
     platform?: string;
@@ -50,6 +54,30 @@ export interface PluginListItem {
   };
 }
 
+export interface PluginRepository {
+  name: string;
+  url: string;
+}
+
+export interface PluginRepositoryVersion {
+  version: string;
+  platforms?: string[];
+  changelog?: string;
+  published_at?: string;
+}
+
+export interface PluginRepositoryItem {
+  repository: string;
+  category: string;
+  plugin_id: string;
+  name?: string;
+  description?: string;
+  versions: PluginRepositoryVersion[];
+  latest_version?: string;
+  installed_version?: string;
+  upgrade_available?: boolean;
+}
+
 export interface PluginDiscoverItem {
   category?: string;
   plugin_id?: string;
@@ -170,6 +198,27 @@ export interface User {
   user_tier_expire_at?: string;
 }
 
+export interface SSHKey {
+  id?: number;
+  name?: string;
+  public_key?: string;
+  fingerprint?: string;
+  created_at?: string;
+}
+
+export interface VPSBackupPolicy {
+  kind?: "snapshot" | "backup";
+  enabled?: boolean;
+  weekday?: number;
+  run_at?: string;
+  retention?: number;
+  next_run_at?: string;
+  last_run_at?: string;
+  last_status?: "success" | "failed" | "skipped" | string;
+  last_error?: string;
+  updated_at?: string;
+}
+
 export interface UserAPIKey {
   id?: number;
   name?: string;
@@ -214,6 +263,10 @@ export interface Line {
   active?: boolean;
   visible?: boolean;
   capacity_remaining?: number;
+  capacity_reserved?: number;
+  capacity_available?: number;
+  snapshot_quota?: number;
+  backup_quota?: number;
   sort_order?: number;
 }
 
@@ -234,6 +287,9 @@ export interface Product {
   active?: boolean;
   visible?: boolean;
   capacity_remaining?: number;
+  capacity_reserved?: number;
+  capacity_available?: number;
+  trial_days?: number;
 }
 
 export interface Package extends Product {}
@@ -279,6 +335,217 @@ export interface BillingCycle {
   sort_order?: number;
 }
 
+export interface Addon {
+  id?: number;
+  plan_group_id?: number;
+  kind?: "ipv4" | "snapshot_quota" | "backup_plan" | string;
+  name?: string;
+  description?: string;
+  monthly_price?: number;
+  units?: number;
+  max_qty?: number;
+  active?: boolean;
+  sort_order?: number;
+  created_at?: string;
+  updated_at?: string;
+}
+
+export interface VPSBulkJob {
+  id: number;
+  admin_id?: number;
+  action: "reboot" | "lock" | "unlock" | "extend_expire" | "set_admin_status" | string;
+  params?: { status?: string; reason?: string; days?: number };
+  filter?: { ids?: number[]; line_id?: number; region_id?: number; package_id?: number; user_id?: number };
+  concurrency?: number;
+  status: "running" | "completed" | "canceled" | string;
+  total: number;
+  succeeded: number;
+  failed: number;
+  canceled: number;
+  pending: number;
+  created_at?: string;
+  finished_at?: string;
+  updated_at?: string;
+}
+
+export interface VPSBulkJobItem {
+  vps_id: number;
+  status: "pending" | "success" | "failed" | "canceled" | string;
+  error?: string;
+  seq?: number;
+  finished_at?: string;
+}
+
+export interface VPSMigration {
+  id: number;
+  vps_id: number;
+  user_id?: number;
+  admin_id?: number;
+  source_goods_type_id?: number;
+  source_instance_id?: string;
+  source_line_id?: number;
+  source_package_id?: number;
+  target_goods_type_id?: number;
+  target_line_id?: number;
+  target_region_id?: number;
+  target_package_id?: number;
+  target_system_id?: number;
+  target_instance_id?: string;
+  transfer_data?: boolean;
+  backup_id?: number;
+  status: "running" | "completed" | "failed" | "canceled" | string;
+  step: "provision" | "transfer" | "switch" | "cleanup" | "done" | string;
+  attempts?: number;
+  max_attempts?: number;
+  last_error?: string;
+  next_run_at?: string;
+  created_at?: string;
+  updated_at?: string;
+  finished_at?: string;
+}
+
+export interface VPSIPAddress {
+  address?: string;
+  version?: 4 | 6;
+  primary?: boolean;
+  ptr?: string;
+  blocked?: boolean;
+  updated_at?: string;
+}
+
+export type IPAddressStatus = "available" | "assigned" | "reserved" | "blocked";
+
+export interface IPAddress {
+  id?: number;
+  address?: string;
+  version?: 4 | 6;
+  line_id?: number;
+  vps_id?: number;
+  user_id?: number;
+  primary?: boolean;
+  ptr?: string;
+  status?: IPAddressStatus;
+  note?: string;
+  created_at?: string;
+  updated_at?: string;
+}
+
+export type VPSTrialStatus = "active" | "converted" | "expired";
+
+export interface VPSTrial {
+  id?: number;
+  user_id?: number;
+  package_id?: number;
+  order_id?: number;
+  order_item_id?: number;
+  days?: number;
+  status?: VPSTrialStatus;
+  expire_at?: string;
+  converted_at?: string;
+  created_at?: string;
+}
+
+export interface AdminVPSTrial extends VPSTrial {
+  phone?: string;
+  client_ip?: string;
+  device_id?: string;
+}
+
+export interface TrialPackageStat {
+  package_id?: number;
+  package_name?: string;
+  total?: number;
+  active?: number;
+  converted?: number;
+  expired?: number;
+  conversion_rate?: number;
+}
+
+export interface DashboardTrials {
+  from?: string;
+  to?: string;
+  total?: number;
+  active?: number;
+  converted?: number;
+  expired?: number;
+  conversion_rate?: number;
+  packages?: TrialPackageStat[];
+}
+
+export type OrderApprovalDecision = "approve" | "review" | "reject";
+
+export interface OrderApprovalRule {
+  id?: number;
+  name?: string;
+  priority?: number;
+  enabled?: boolean;
+  decision?: OrderApprovalDecision;
+  min_amount?: number;
+  max_account_age_days?: number;
+  realname_unverified?: boolean;
+  countries?: string[];
+  min_instances?: number;
+  min_recent_refunds?: number;
+  reason?: string;
+  created_at?: string;
+  updated_at?: string;
+}
+
+export interface OrderApprovalEvaluation {
+  order_id?: number;
+  decision?: OrderApprovalDecision;
+  rule_id?: number;
+  rule_name?: string;
+  reason?: string;
+  amount?: number;
+  account_age_days?: number;
+  realname_verified?: boolean;
+  client_ip?: string;
+  country?: string;
+  instances?: number;
+  recent_refunds?: number;
+  created_at?: string;
+}
+
+export interface OrderApprovalDryRunItem {
+  order_id?: number;
+  order_no?: string;
+  user_id?: number;
+  status?: string;
+  result?: OrderApprovalEvaluation;
+  recorded?: OrderApprovalEvaluation | null;
+  different?: boolean;
+}
+
+export interface OrderApprovalDryRunResult {
+  total?: number;
+  approve?: number;
+  review?: number;
+  reject?: number;
+  changed?: number;
+  items?: OrderApprovalDryRunItem[];
+}
+
+export interface IPAddressEvent {
+  id?: number;
+  address?: string;
+  type?: "assigned" | "released" | "ptr" | "status" | "abuse";
+  vps_id?: number;
+  user_id?: number;
+  admin_id?: number;
+  detail?: string;
+  created_at?: string;
+}
+
+export interface CartAddon {
+  addon_id: number;
+  qty: number;
+  kind?: string;
+  name?: string;
+  units?: number;
+  monthly_cents?: number;
+}
+
 export interface CartSpec {
   add_cores?: number;
   add_mem_gb?: number;
@@ -287,6 +554,21 @@ export interface CartSpec {
   billing_cycle_id?: number;
   cycle_qty?: number;
   duration_months?: number;
+  ssh_key_ids?: number[];
+  user_data?: string;
+  addons?: CartAddon[];
+}
+
+export interface StockShortage {
+  package_id?: number;
+  plan_group_id?: number;
+  scope: "package" | "plan_group";
+  requested: number;
+  available: number;
+}
+
+export interface CartList extends ApiList<CartItem> {
+  shortages?: StockShortage[];
 }
 
 export interface CartItem {
@@ -412,9 +694,23 @@ export interface PaymentCreateResult {
 
 export interface WalletInfo {
   balance?: number;
+  frozen?: number;
+  available?: number;
   currency?: string;
 }
 
+export interface WalletHold {
+  id?: number;
+  user_id?: number;
+  amount?: number;
+  ref_type?: string;
+  ref_id?: number;
+  status?: "active" | "captured" | "released";
+  note?: string;
+  created_at?: string;
+  settled_at?: string | null;
+}
+
 export interface WalletOrder {
   id?: number;
   user_id?: number;
@@ -438,6 +734,7 @@ export interface WalletOrderCreateRequest {
   return_url?: string;
   notify_url?: string;
   extra?: Record<string, string>;
+  payout_account_id?: number;
   meta?: Record<string, unknown>;
 }
 
@@ -454,6 +751,169 @@ export interface WalletTransaction {
   created_at?: string;
 }
 
+export interface LedgerAccount {
+  account: string;
+  type: "asset" | "liability" | "revenue" | "equity";
+  debit: number;
+  credit: number;
+  balance: number;
+}
+
+export interface LedgerEntry {
+  id: number;
+  key: string;
+  kind: string;
+  ref_type: string;
+  ref_id: number;
+  user_id: number;
+  memo?: string;
+  created_at: string;
+  lines: { account: string; debit: number; credit: number }[];
+}
+
+export interface PayoutAccount {
+  id: number;
+  user_id: number;
+  channel: "bank" | "alipay";
+  account_name: string;
+  account_no: string;
+  bank_name: string;
+  bank_branch: string;
+  status: "pending" | "verified" | "rejected";
+  review_reason: string;
+  reviewed_at?: string;
+  created_at: string;
+}
+
+export interface PayoutBatchItem {
+  id: number;
+  wallet_order_id: number;
+  user_id: number;
+  amount: number;
+  payout_account_id: number;
+  account_name: string;
+  account_no: string;
+  bank_name: string;
+  bank_branch: string;
+  status: "pending" | "paid" | "failed";
+  reference: string;
+  fail_reason: string;
+  settled_at?: string;
+}
+
+export interface PayoutBatch {
+  id: number;
+  channel: "bank" | "alipay";
+  status: "pending" | "paid" | "partial" | "failed";
+  item_count: number;
+  total_amount: number;
+  reference: string;
+  note: string;
+  created_by: number;
+  exported_at?: string;
+  settled_at?: string;
+  created_at: string;
+  items?: PayoutBatchItem[];
+}
+
+export interface FinanceReportDefinition {
+  id: number;
+  name: string;
+  period: "daily" | "weekly" | "monthly";
+  formats: ("csv" | "xlsx")[];
+  recipients: string[];
+  enabled: boolean;
+  created_by: number;
+  last_run_at?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface FinanceReportFile {
+  id: number;
+  name: string;
+  content_type: string;
+  size: number;
+}
+
+export interface FinanceReportRun {
+  id: number;
+  definition_id: number;
+  name: string;
+  period: "daily" | "weekly" | "monthly";
+  period_start: string;
+  period_end: string;
+  trigger: "schedule" | "manual";
+  status: "success" | "delivery_failed" | "failed";
+  error: string;
+  recipients: string[];
+  created_by: number;
+  delivered_at?: string;
+  created_at: string;
+  files?: FinanceReportFile[];
+}
+
+export type DunningAction = "remind" | "suspend" | "final_warning" | "terminate";
+export type DunningChannel = "email" | "sms" | "inapp";
+
+export interface DunningStep {
+  offset_days: number;
+  action: DunningAction;
+  channels: DunningChannel[];
+}
+
+export interface DunningPolicy {
+  id: number;
+  goods_type_id: number;
+  enabled: boolean;
+  steps: DunningStep[];
+  updated_by: number;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface DunningRecord {
+  id: number;
+  instance_id: number;
+  user_id: number;
+  goods_type_id: number;
+  cycle_expire_at: string;
+  step_key: string;
+  action: DunningAction;
+  offset_days: number;
+  status: "success" | "failed" | "skipped";
+  channels: DunningChannel[];
+  error: string;
+  attempts: number;
+  executed_at: string;
+}
+
+export interface DunningStepView extends DunningStep {
+  due_at: string;
+  state: "pending" | "due" | "success" | "failed" | "skipped";
+  record?: DunningRecord;
+}
+
+export interface DunningView {
+  instance_id: number;
+  goods_type_id: number;
+  expire_at?: string;
+  stage: "none" | "renewal_paid" | "scheduled" | "reminded" | "suspended" | "final_warning" | "terminated";
+  policy?: DunningPolicy;
+  steps: DunningStepView[];
+  history: DunningRecord[];
+}
+
+export interface LedgerVerification {
+  ok: boolean;
+  checked_at: string;
+  wallets: number;
+  total_debit: number;
+  total_credit: number;
+  drifts: { user_id: number; wallet_balance: number; ledger_balance: number; difference: number }[];
+  unbalanced_entries: number[];
+}
+
 export interface Notification {
   id?: number;
   user_id?: number;
@@ -469,6 +929,101 @@ export interface UnreadCountResponse {
   unread?: number;
 }
 
+export type NotificationChannel = "inapp" | "email" | "sms" | "push";
+
+export interface NotificationCategory {
+  key: string;
+  name: string;
+  mandatory: boolean;
+  channels: NotificationChannel[];
+  disabled_channels: NotificationChannel[];
+}
+
+export interface NotificationPreferences {
+  categories: NotificationCategory[];
+  disabled: Record<string, NotificationChannel[]>;
+  quiet_hours_enabled: boolean;
+  quiet_start: string;
+  quiet_end: string;
+  timezone: string;
+  updated_at?: string | null;
+}
+
+export interface NotificationPreferencesPayload {
+  disabled: Record<string, NotificationChannel[]>;
+  quiet_hours_enabled: boolean;
+  quiet_start?: string;
+  quiet_end?: string;
+  timezone?: string;
+}
+
+export type BroadcastStatus = "scheduled" | "sending" | "sent" | "canceled";
+export type BroadcastDeliveryStatus = "pending" | "sent" | "failed" | "skipped";
+
+export interface BroadcastSegment {
+  user_tier_group_ids?: number[];
+  goods_type_ids?: number[];
+  region_ids?: number[];
+  realname_status?: "" | "verified" | "unverified";
+  registered_from?: string | null;
+  registered_to?: string | null;
+}
+
+export interface Broadcast {
+  id: number;
+  title: string;
+  content: string;
+  link_url: string;
+  channels: NotificationChannel[];
+  segment: BroadcastSegment;
+  status: BroadcastStatus;
+  scheduled_at: string;
+  started_at?: string;
+  finished_at?: string;
+  total: number;
+  sent_count: number;
+  failed_count: number;
+  created_by: number;
+  created_at: string;
+}
+
+export interface BroadcastPayload {
+  title: string;
+  content: string;
+  link_url?: string;
+  channels: NotificationChannel[];
+  segment?: BroadcastSegment;
+  scheduled_at?: string | null;
+}
+
+export interface BroadcastChannelStats {
+  channel: NotificationChannel;
+  total: number;
+  pending: number;
+  sent: number;
+  failed: number;
+  skipped: number;
+  clicked: number;
+}
+
+export interface BroadcastStats {
+  channels: BroadcastChannelStats[];
+  read: number;
+  clicked: number;
+}
+
+export interface BroadcastDelivery {
+  id: number;
+  user_id: number;
+  channel: NotificationChannel;
+  status: BroadcastDeliveryStatus;
+  error: string;
+  notification_id?: number;
+  sent_at?: string;
+  clicked_at?: string;
+  created_at: string;
+}
+
 export interface ServerStatus {
   hostname?: string;
   os?: string;
@@ -502,6 +1057,8 @@ export interface ProbeNode {
   agent_id?: string;
   status?: string;
   os_type?: string;
+  agent_version?: string;
+  agent_update?: ProbeAgentUpdate;
   tags?: string[];
   last_heartbeat_at?: string;
   last_snapshot_at?: string;
@@ -510,6 +1067,22 @@ export interface ProbeNode {
   updated_at?: string;
 }
 
+export interface ProbeAgentUpdate {
+  status?: string;
+  message?: string;
+  updated_at?: string;
+}
+
+export interface ProbeAgentRelease {
+  version?: string;
+  platforms?: string[];
+  signature_status?: string;
+  valid?: boolean;
+  error?: string;
+  desired?: boolean;
+  created_at?: string;
+}
+
 export interface ProbeStatusEvent {
   id?: number;
   probe_id?: number;
@@ -653,6 +1226,7 @@ export interface CouponProductRule {
   region_id?: number;
   plan_group_id?: number;
   package_id?: number;
+  addon_id?: number;
   addon_core_enabled?: boolean;
   addon_mem_enabled?: boolean;
   addon_disk_enabled?: boolean;
@@ -870,6 +1444,7 @@ export interface UserTierDiscountRule {
   region_id?: number;
   plan_group_id?: number;
   package_id?: number;
+  addon_id?: number;
   discount_permille?: number;
   fixed_price?: number | null;
   add_core_permille?: number;
@@ -1190,6 +1765,9 @@ export interface RealNameVerification {
   provider?: string;
   reason?: string;
   redirect_url?: string;
+  upload_ids?: number[];
+  reviewed_by?: number;
+  reviewed_at?: string;
   created_at?: string;
   verified_at?: string;
 }
@@ -1199,6 +1777,7 @@ export interface RealNameStatusResponse {
   provider?: string;
   block_actions?: string[];
   verified?: boolean;
+  manual_review?: boolean;
   verification?: RealNameVerification;
 }
 
@@ -1206,6 +1785,72 @@ export interface RealNameConfig {
   enabled?: boolean;
   provider?: string;
   block_actions?: string[];
+  fallback_providers?: string[];
+  provider_timeout_sec?: number;
+  manual_review?: boolean;
+  company_enabled?: boolean;
+  company_requirements?: CompanyRequirement[];
+}
+
+export interface CompanyRequirement {
+  action: string;
+  goods_type_ids?: number[];
+  min_amount?: number;
+}
+
+export interface CompanyVerification {
+  id?: number;
+  user_id?: number;
+  company_name?: string;
+  credit_code?: string;
+  legal_person_name?: string;
+  legal_person_id_number?: string;
+  invoice_title?: string;
+  tax_number?: string;
+  invoice_address?: string;
+  invoice_phone?: string;
+  invoice_bank?: string;
+  invoice_bank_account?: string;
+  status?: string;
+  provider?: string;
+  reason?: string;
+  upload_ids?: number[];
+  reviewed_by?: number;
+  reviewed_at?: string;
+  created_at?: string;
+  verified_at?: string;
+}
+
+export interface CompanyStatusResponse {
+  enabled?: boolean;
+  verified?: boolean;
+  verification?: CompanyVerification | null;
+}
+
+export interface CompanyVerificationListResponse {
+  items?: CompanyVerification[];
+  total?: number;
+}
+
+export interface CompanyVerificationPayload {
+  company_name: string;
+  credit_code: string;
+  legal_person_name: string;
+  legal_person_id_number: string;
+  invoice_title?: string;
+  tax_number?: string;
+  invoice_address?: string;
+  invoice_phone?: string;
+  invoice_bank?: string;
+  invoice_bank_account?: string;
+  upload_ids: number[];
+}
+
+export interface RealNameUpload {
+  id?: number;
+  name?: string;
+  mime?: string;
+  size?: number;
 }
 
 export interface RealNameProvider {
@@ -1279,3 +1924,18 @@ export interface OrderDetailWithEventsResponse {
   payments?: OrderPayment[];
   events?: OrderEvent[];
 }
+
+export interface NotifyChannel {
+  plugin_id: string;
+  instance_id: string;
+  name?: string;
+  channel?: string;
+  recipients?: boolean;
+  html?: boolean;
+  loaded?: boolean;
+}
+
+export interface NotifyRoute {
+  type: string;
+  channels: string[];
+}
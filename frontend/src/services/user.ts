@@ -5,6 +5,7 @@ import type {
   AuthSettings,
   CaptchaResponse,
   CartItem,
+  CartList,
   CartItemRequest,
   Order,
   OrderCreateResponse,
@@ -12,6 +13,7 @@ import type {
   OrderDetailResponse,
   PaymentRequest,
   BillingCycle,
+  Addon,
   Package,
   Line,
   SystemImage,
@@ -28,15 +30,28 @@ import type {
   WalletOrderCreateRequest,
   WalletOrderListResponse,
   WalletTransaction,
+  WalletHold,
+  PayoutAccount,
+  DunningView,
   Notification,
+  NotificationPreferences,
+  NotificationPreferencesPayload,
   RealNameStatusResponse,
   UnreadCountResponse,
+  RealNameUpload,
+  CompanyStatusResponse,
+  CompanyVerification,
+  CompanyVerificationPayload,
   RealNameVerification,
   CMSBlock,
   CMSPost,
   GoodsType,
   CouponPreviewResponse,
-  UserAPIKey
+  UserAPIKey,
+  SSHKey,
+  VPSBackupPolicy,
+  VPSIPAddress,
+  VPSTrial
 } from "./types";
 
 export const getCaptcha = () => http.get<CaptchaResponse>("/api/v1/captcha");
@@ -71,10 +86,12 @@ export const listPlanGroups = (params?: { region_id?: number; goods_type_id?: nu
 export const listPackages = (params?: { plan_group_id?: number; goods_type_id?: number }) =>
   http.get<ApiList<Package>>("/api/v1/packages", { params });
 export const listBillingCycles = () => http.get<ApiList<BillingCycle>>("/api/v1/billing-cycles");
+export const listAddons = (planGroupId: number | string) =>
+  http.get<ApiList<Addon>>("/api/v1/addons", { params: { plan_group_id: planGroupId } });
 export const listSystemImages = (params?: { line_id?: number; plan_group_id?: number }) =>
   http.get<ApiList<SystemImage>>("/api/v1/system-images", { params });
 
-export const listCart = () => http.get<ApiList<CartItem>>("/api/v1/cart");
+export const listCart = () => http.get<CartList>("/api/v1/cart");
 export const addCartItem = (payload: CartItemRequest) => http.post("/api/v1/cart", payload);
 export const updateCartItem = (id: number | string, payload: CartItemRequest) => http.patch(`/api/v1/cart/${id}`, payload);
 export const deleteCartItem = (id: number | string) => http.delete(`/api/v1/cart/${id}`);
@@ -120,6 +137,19 @@ export const listWalletOrders = (params?: Record<string, unknown>) =>
   http.get<WalletOrderListResponse>("/api/v1/wallet/orders", { params });
 export const listWalletTransactions = (params?: Record<string, unknown>) =>
   http.get<ApiList<WalletTransaction>>("/api/v1/wallet/transactions", { params });
+export const listWalletHolds = (params?: { status?: "active" | "captured" | "released"; limit?: number; offset?: number }) =>
+  http.get<ApiList<WalletHold>>("/api/v1/wallet/holds", { params });
+export const downloadStatement = (params: { from_at: string; to_at: string; format?: "csv" | "pdf" }) =>
+  http.get("/api/v1/statement", { params, responseType: "blob" });
+export const listPayoutAccounts = () => http.get<ApiList<PayoutAccount>>("/api/v1/wallet/payout-accounts");
+export const createPayoutAccount = (payload: {
+  channel: "bank" | "alipay";
+  account_name: string;
+  account_no: string;
+  bank_name?: string;
+  bank_branch?: string;
+}) => http.post<PayoutAccount>("/api/v1/wallet/payout-accounts", payload);
+export const deletePayoutAccount = (id: number | string) => http.delete<{ ok?: boolean }>(`/api/v1/wallet/payout-accounts/${id}`);
 
 // 消息中心
 export const listNotifications = (params?: Record<string, unknown>) =>
@@ -127,6 +157,10 @@ export const listNotifications = (params?: Record<string, unknown>) =>
 export const getUnreadCount = () => http.get<UnreadCountResponse>("/api/v1/notifications/unread-count");
 export const markNotificationRead = (id: number | string) => http.post(`/api/v1/notifications/${id}/read`);
 export const markAllNotificationsRead = () => http.post("/api/v1/notifications/read-all");
+export const getNotificationPreferences = () =>
+  http.get<NotificationPreferences>("/api/v1/me/notification-preferences");
+export const updateNotificationPreferences = (payload: NotificationPreferencesPayload) =>
+  http.patch<NotificationPreferences>("/api/v1/me/notification-preferences", payload);
 
 export const listVps = () => http.get<ApiList<VPSInstance>>("/api/v1/vps");
 export const getVpsDetail = (id: number | string) => http.get<VPSInstance>(`/api/v1/vps/${id}`);
@@ -137,7 +171,10 @@ export const getVpsVnc = (id: number | string) => http.get(`/api/v1/vps/${id}/vn
 export const startVps = (id: number | string) => http.post(`/api/v1/vps/${id}/start`);
 export const shutdownVps = (id: number | string) => http.post(`/api/v1/vps/${id}/shutdown`);
 export const rebootVps = (id: number | string) => http.post(`/api/v1/vps/${id}/reboot`);
-export const resetVpsOS = (id: number | string, payload: { template_id: number | string; password: string }) =>
+export const resetVpsOS = (
+  id: number | string,
+  payload: { template_id: number | string; password: string; ssh_key_ids?: number[]; user_data?: string }
+) =>
   http.post(`/api/v1/vps/${id}/reset-os`, { host_id: id, ...payload });
 export const resetVpsOsPassword = (id: number | string, payload: { password: string }) =>
   http.post(`/api/v1/vps/${id}/reset-os-password`, payload);
@@ -153,6 +190,22 @@ export const deleteVpsBackup = (id: number | string, backupId: number | string)
   http.delete(`/api/v1/vps/${id}/backups/${backupId}`);
 export const restoreVpsBackup = (id: number | string, backupId: number | string) =>
   http.post(`/api/v1/vps/${id}/backups/${backupId}/restore`, {}, { timeout: 1800000 });
+export const listVpsBackupPolicies = (id: number | string) =>
+  http.get<ApiList<VPSBackupPolicy>>(`/api/v1/vps/${id}/backup-policies`);
+export const saveVpsBackupPolicy = (
+  id: number | string,
+  kind: "snapshot" | "backup",
+  payload: { enabled: boolean; weekday?: number; run_at: string; retention: number }
+) => http.put<VPSBackupPolicy>(`/api/v1/vps/${id}/backup-policies/${kind}`, payload);
+export const deleteVpsBackupPolicy = (id: number | string, kind: "snapshot" | "backup") =>
+  http.delete(`/api/v1/vps/${id}/backup-policies/${kind}`);
+export const listTrials = (params?: { limit?: number; offset?: number }) =>
+  http.get<ApiList<VPSTrial>>("/api/v1/trials", { params });
+export const startTrial = (payload: { package_id: number; system_id: number; device_id?: string }) =>
+  http.post<VPSTrial>("/api/v1/trials", payload);
+export const listVpsIPAddresses = (id: number | string) => http.get<ApiList<VPSIPAddress>>(`/api/v1/vps/${id}/ips`);
+export const setVpsReverseDNS = (id: number | string, payload: { ip: string; ptr: string }) =>
+  http.put<VPSIPAddress>(`/api/v1/vps/${id}/ips/rdns`, payload);
 export const getVpsFirewallRules = (id: number | string) => http.get(`/api/v1/vps/${id}/firewall`);
 export const addVpsFirewallRule = (id: number | string, payload: Record<string, unknown>) =>
   http.post(`/api/v1/vps/${id}/firewall`, payload);
@@ -167,6 +220,7 @@ export const deleteVpsPortMapping = (id: number | string, mappingId: number | st
   http.delete(`/api/v1/vps/${id}/ports/${mappingId}`);
 export const createVpsRenewOrder = (id: number | string, payload: Record<string, unknown>) =>
   http.post(`/api/v1/vps/${id}/renew`, payload);
+export const getVpsDunning = (id: number | string) => http.get<DunningView>(`/api/v1/vps/${id}/dunning`);
 export const emergencyRenewVps = (id: number | string) => http.post(`/api/v1/vps/${id}/emergency-renew`);
 export const createVpsResizeOrder = (id: number | string, payload: Record<string, unknown>) =>
   http.post(`/api/v1/vps/${id}/resize`, payload);
@@ -187,10 +241,27 @@ export const updateUserApiKeyStatus = (id: number | string, payload: { status: "
   http.patch(`/api/v1/open/me/api-keys/${id}`, payload);
 export const deleteUserApiKey = (id: number | string) => http.delete(`/api/v1/open/me/api-keys/${id}`);
 
+// SSH 公钥
+export const listSSHKeys = () => http.get<ApiList<SSHKey>>("/api/v1/me/ssh-keys");
+export const createSSHKey = (payload: { name?: string; public_key: string }) => http.post<SSHKey>("/api/v1/me/ssh-keys", payload);
+export const deleteSSHKey = (id: number | string) => http.delete(`/api/v1/me/ssh-keys/${id}`);
+
 // 实名认证
 export const getRealNameStatus = () => http.get<RealNameStatusResponse>("/api/v1/realname/status");
 export const submitRealNameVerification = (payload: { real_name: string; id_number: string; phone?: string }) =>
   http.post<RealNameVerification>("/api/v1/realname/verify", payload);
+export const uploadRealNameImage = (file: File) => {
+  const formData = new FormData();
+  formData.append("file", file);
+  return http.post<RealNameUpload>("/api/v1/realname/uploads", formData, {
+    headers: { "Content-Type": "multipart/form-data" }
+  });
+};
+export const submitRealNameManual = (payload: { real_name: string; id_number: string; upload_ids: number[] }) =>
+  http.post<RealNameVerification>("/api/v1/realname/manual", payload);
+export const getCompanyVerification = () => http.get<CompanyStatusResponse>("/api/v1/realname/company");
+export const submitCompanyVerification = (payload: CompanyVerificationPayload) =>
+  http.post<CompanyVerification>("/api/v1/realname/company", payload);
 
 // 密码找回
 export const forgotPassword = (email: string) => http.post("/api/v1/auth/forgot-password", { email });
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"log"
 	"os"
@@ -11,6 +12,8 @@ import (
 	"pingbot/internal/collector"
 	"pingbot/internal/config"
 	"pingbot/internal/service"
+	"pingbot/internal/updater"
+	"pingbot/internal/version"
 )
 
 func main() {
@@ -25,13 +28,17 @@ func main() {
 	if cfg.ServerURL == "" {
 		log.Fatalf("server_url is empty")
 	}
-	log.Printf("pingbot starting config=%s server=%s probe_id=%d", *cfgPath, cfg.ServerURL, cfg.ProbeID)
+	log.Printf("pingbot starting version=%s config=%s server=%s probe_id=%d", version.Version, *cfgPath, cfg.ServerURL, cfg.ProbeID)
 
 	svc := service.New(*cfgPath, cfg)
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
 	if err := svc.Run(ctx); err != nil {
+		if errors.Is(err, updater.ErrRestartRequired) {
+			log.Printf("pingbot exiting for restart after update")
+			os.Exit(updater.ExitCodeRestart)
+		}
 		log.Fatalf("service exit: %v", err)
 	}
 }
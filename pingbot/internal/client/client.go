@@ -2,11 +2,13 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -17,9 +19,11 @@ type APIClient struct {
 }
 
 type RuntimeConfig struct {
-	HeartbeatIntervalSec int `json:"heartbeat_interval_sec"`
-	SnapshotIntervalSec  int `json:"snapshot_interval_sec"`
-	LogChunkMaxBytes     int `json:"log_chunk_max_bytes"`
+	HeartbeatIntervalSec   int    `json:"heartbeat_interval_sec"`
+	SnapshotIntervalSec    int    `json:"snapshot_interval_sec"`
+	LogChunkMaxBytes       int    `json:"log_chunk_max_bytes"`
+	AgentDesiredVersion    string `json:"agent_desired_version"`
+	AgentUpdateRollbackSec int    `json:"agent_update_rollback_sec"`
 }
 
 func New(baseURL string, insecure bool) *APIClient {
@@ -65,6 +69,36 @@ func (c *APIClient) AuthToken(probeID int64, probeSecret string) (accessToken st
 	return resp.AccessToken, resp.Config, err
 }
 
+// DownloadReleaseFile streams one file of a signed pingbot release into w.
+func (c *APIClient) DownloadReleaseFile(ctx context.Context, accessToken, version, relPath string, w io.Writer, maxBytes int64) error {
+	u := c.baseURL + "/api/v1/probe/releases/" + url.PathEscape(version) + "/files/" + strings.TrimPrefix(relPath, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	// Binaries can take longer than the API timeout; the caller's context bounds the download.
+	hc := *c.client
+	hc.Timeout = 0
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("http %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	n, err := io.Copy(w, io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return err
+	}
+	if n > maxBytes {
+		return fmt.Errorf("%s exceeds %d bytes", relPath, maxBytes)
+	}
+	return nil
+}
+
 func (c *APIClient) postJSON(path string, payload any, out any) error {
 	b, _ := json.Marshal(payload)
 	req, err := http.NewRequest(http.MethodPost, c.baseURL+path, bytes.NewReader(b))
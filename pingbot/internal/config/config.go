@@ -16,6 +16,9 @@ type Config struct {
 	HostnameAlias         string `yaml:"hostname_alias"`
 	LogFileSource         string `yaml:"log_file_source"`
 	TLSInsecureSkipVerify bool   `yaml:"tls_insecure_skip_verify"`
+	// UpdatePublicKeys are base64 ed25519 keys trusted for self-update.
+	// Self-update stays disabled while the list is empty.
+	UpdatePublicKeys []string `yaml:"update_public_keys,omitempty"`
 }
 
 func Load(path string) (Config, error) {
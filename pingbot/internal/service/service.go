@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -20,6 +22,8 @@ import (
 	"pingbot/internal/collector"
 	"pingbot/internal/config"
 	"pingbot/internal/logreader"
+	"pingbot/internal/updater"
+	"pingbot/internal/version"
 )
 
 type Envelope struct {
@@ -28,38 +32,74 @@ type Envelope struct {
 	Payload   any    `json:"payload,omitempty"`
 }
 
+// selfUpdater is the part of updater.Updater the service drives.
+type selfUpdater interface {
+	Recover() error
+	Watchdog(ctx context.Context)
+	ShouldUpdate(desired string) bool
+	Apply(ctx context.Context, accessToken, version string, rollbackSec int) error
+	Confirm()
+	TakeReport() (status, message string, ok bool)
+}
+
 type Service struct {
-	cfgPath string
-	cfg     config.Config
-	api     *client.APIClient
+	cfgPath  string
+	cfg      config.Config
+	api      *client.APIClient
+	updater  selfUpdater
+	updating atomic.Bool
+	// restartCh outlives each connection so an update installed while
+	// wsLoop is exiting or reconnecting still stops Run.
+	restartCh chan struct{}
 }
 
 func New(cfgPath string, cfg config.Config) *Service {
+	api := client.New(cfg.ServerURL, cfg.TLSInsecureSkipVerify)
+	upd, err := updater.New(api, cfg.UpdatePublicKeys, version.Version)
+	if err != nil {
+		log.Printf("self-update disabled: %v", err)
+		upd = nil
+	}
 	return &Service{
-		cfgPath: cfgPath,
-		cfg:     cfg,
-		api:     client.New(cfg.ServerURL, cfg.TLSInsecureSkipVerify),
+		cfgPath:   cfgPath,
+		cfg:       cfg,
+		api:       api,
+		updater:   upd,
+		restartCh: make(chan struct{}, 1),
 	}
 }
 
 func (s *Service) Run(ctx context.Context) error {
+	if err := s.updater.Recover(); err != nil {
+		if errors.Is(err, updater.ErrRestartRequired) {
+			return err
+		}
+		log.Printf("update recover failed: %v", err)
+	}
+	go s.updater.Watchdog(ctx)
 	backoff := time.Second
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
+		case <-s.restartCh:
+			return updater.ErrRestartRequired
 		default:
 		}
 		if err := s.ensureEnrollment(); err != nil {
 			log.Printf("enroll failed: %v", err)
-			time.Sleep(backoff)
+			if err := s.wait(ctx, backoff); err != nil {
+				return err
+			}
 			backoff = minDuration(backoff*2, 30*time.Second)
 			continue
 		}
 		accessToken, runtimeCfg, err := s.api.AuthToken(s.cfg.ProbeID, s.cfg.ProbeSecret)
 		if err != nil {
 			log.Printf("auth token failed: %v", err)
-			time.Sleep(backoff)
+			if err := s.wait(ctx, backoff); err != nil {
+				return err
+			}
 			backoff = minDuration(backoff*2, 30*time.Second)
 			continue
 		}
@@ -73,13 +113,32 @@ func (s *Service) Run(ctx context.Context) error {
 			runtimeCfg.LogChunkMaxBytes = 16384
 		}
 		if err := s.wsLoop(ctx, accessToken, runtimeCfg); err != nil {
+			if errors.Is(err, updater.ErrRestartRequired) {
+				return err
+			}
 			log.Printf("ws disconnected: %v", err)
 		}
-		time.Sleep(backoff)
+		if err := s.wait(ctx, backoff); err != nil {
+			return err
+		}
 		backoff = minDuration(backoff*2, 30*time.Second)
 	}
 }
 
+// wait sleeps for d between reconnects. It returns ErrRestartRequired as soon
+// as an installed update asks for a restart, and nil when ctx ends.
+func (s *Service) wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-s.restartCh:
+		return updater.ErrRestartRequired
+	case <-timer.C:
+	}
+	return nil
+}
+
 func (s *Service) ensureEnrollment() error {
 	if s.cfg.ProbeID > 0 && strings.TrimSpace(s.cfg.ProbeSecret) != "" {
 		log.Printf("credential loaded probe_id=%d", s.cfg.ProbeID)
@@ -138,10 +197,11 @@ func (s *Service) wsLoop(ctx context.Context, accessToken string, runtimeCfg cli
 	_ = send(Envelope{
 		Type: "hello",
 		Payload: map[string]any{
-			"os_type": runtime.GOOS,
+			"os_type":       runtime.GOOS,
+			"agent_version": version.Version,
 		},
 	})
-	log.Printf("hello sent os=%s", runtime.GOOS)
+	log.Printf("hello sent os=%s version=%s", runtime.GOOS, version.Version)
 
 	hbTicker := time.NewTicker(time.Duration(runtimeCfg.HeartbeatIntervalSec) * time.Second)
 	defer hbTicker.Stop()
@@ -205,6 +265,11 @@ func (s *Service) wsLoop(ctx context.Context, accessToken string, runtimeCfg cli
 				case cfgCh <- p.Config:
 				default:
 				}
+			case "hello_ack":
+				s.updater.Confirm()
+				if status, message, ok := s.updater.TakeReport(); ok {
+					sendUpdateStatus(send, status, message)
+				}
 			case "ping":
 				_ = send(Envelope{Type: "pong", RequestID: msg.RequestID})
 			case "request_log":
@@ -232,6 +297,7 @@ func (s *Service) wsLoop(ctx context.Context, accessToken string, runtimeCfg cli
 	}
 	sendHeartbeat()
 	sendSnapshot("startup")
+	s.maybeUpdate(ctx, accessToken, runtimeCfg, send)
 
 	for {
 		select {
@@ -239,7 +305,10 @@ func (s *Service) wsLoop(ctx context.Context, accessToken string, runtimeCfg cli
 			return nil
 		case err := <-errCh:
 			return err
+		case <-s.restartCh:
+			return updater.ErrRestartRequired
 		case incoming := <-cfgCh:
+			s.maybeUpdate(ctx, accessToken, incoming, send)
 			changedHB := false
 			changedSS := false
 			if incoming.HeartbeatIntervalSec > 0 && incoming.HeartbeatIntervalSec != runtimeCfg.HeartbeatIntervalSec {
@@ -276,6 +345,45 @@ func (s *Service) wsLoop(ctx context.Context, accessToken string, runtimeCfg cli
 	}
 }
 
+// maybeUpdate installs the server's desired pingbot version in the background.
+// A successful install signals s.restartCh so Run exits and the service manager restarts pingbot.
+func (s *Service) maybeUpdate(ctx context.Context, accessToken string, cfg client.RuntimeConfig, send func(Envelope) error) {
+	target := strings.TrimSpace(cfg.AgentDesiredVersion)
+	if !s.updater.ShouldUpdate(target) || !s.updating.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer s.updating.Store(false)
+		log.Printf("update started current=%s target=%s", version.Version, target)
+		sendUpdateStatus(send, "downloading", target)
+		err := s.updater.Apply(ctx, accessToken, target, cfg.AgentUpdateRollbackSec)
+		if errors.Is(err, updater.ErrRestartRequired) {
+			sendUpdateStatus(send, "restarting", target)
+			select {
+			case s.restartCh <- struct{}{}:
+			default:
+			}
+			return
+		}
+		if err != nil {
+			log.Printf("update failed target=%s err=%v", target, err)
+			sendUpdateStatus(send, "failed", target+": "+err.Error())
+		}
+	}()
+}
+
+func sendUpdateStatus(send func(Envelope) error, status, message string) {
+	if err := send(Envelope{
+		Type: "agent_update",
+		Payload: map[string]any{
+			"status":  status,
+			"message": message,
+		},
+	}); err != nil {
+		log.Printf("update status send failed status=%s err=%v", status, err)
+	}
+}
+
 func (s *Service) handleLogRequest(send func(Envelope) error, msg Envelope, maxChunk int) {
 	raw, _ := json.Marshal(msg.Payload)
 	var payload struct {
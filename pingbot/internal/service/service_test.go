@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"pingbot/internal/client"
+	"pingbot/internal/config"
+	"pingbot/internal/updater"
+)
+
+// fakeUpdater blocks the first Apply until release is closed, then reports a
+// successful install. Later calls fail, as the version is already installed.
+type fakeUpdater struct {
+	started chan struct{}
+	release chan struct{}
+	applies atomic.Int32
+}
+
+func (f *fakeUpdater) Recover() error               { return nil }
+func (f *fakeUpdater) Watchdog(context.Context)     {}
+func (f *fakeUpdater) ShouldUpdate(ver string) bool { return ver != "" }
+func (f *fakeUpdater) Confirm()                     {}
+func (f *fakeUpdater) TakeReport() (string, string, bool) {
+	return "", "", false
+}
+
+func (f *fakeUpdater) Apply(ctx context.Context, _, _ string, _ int) error {
+	if f.applies.Add(1) > 1 {
+		return errors.New("already installed")
+	}
+	close(f.started)
+	<-f.release
+	return updater.ErrRestartRequired
+}
+
+func TestRunRestartsWhenUpdateInstallsWhileWSLoopExits(t *testing.T) {
+	upd := &fakeUpdater{started: make(chan struct{}), release: make(chan struct{})}
+	upgrader := websocket.Upgrader{}
+	var conns atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/probe/auth/token":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"access_token": "token",
+				"config":       client.RuntimeConfig{AgentDesiredVersion: "9.9.9"},
+			})
+		case "/api/v1/probe/ws":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			if conns.Add(1) > 1 {
+				// A reconnect means the restart was lost; hold it open.
+				for {
+					if _, _, err := conn.ReadMessage(); err != nil {
+						return
+					}
+				}
+			}
+			// Drop the connection while the update is still installing,
+			// and let the install finish once wsLoop has returned and Run
+			// is waiting to reconnect.
+			<-upd.started
+			_ = conn.Close()
+			time.Sleep(200 * time.Millisecond)
+			close(upd.release)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	svc := &Service{
+		cfg:       config.Config{ServerURL: srv.URL, ProbeID: 1, ProbeSecret: "secret"},
+		api:       client.New(srv.URL, false),
+		updater:   upd,
+		restartCh: make(chan struct{}, 1),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- svc.Run(ctx) }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, updater.ErrRestartRequired) {
+			t.Fatalf("expected restart, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("restart signal lost; Run still running after %d connections", conns.Load())
+	}
+}
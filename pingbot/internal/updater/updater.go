@@ -0,0 +1,400 @@
+package updater
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"pingbot/internal/client"
+)
+
+// ExitCodeRestart is used when pingbot exits so the service manager
+// (systemd Restart=always / NSSM) starts the freshly installed binary.
+const ExitCodeRestart = 3
+
+const (
+	defaultRollbackSec = 120
+	maxMetaBytes       = 1 << 20
+	maxBinaryBytes     = 256 << 20
+)
+
+var (
+	ErrRestartRequired = errors.New("restart required")
+	errVerify          = errors.New("release verification failed")
+)
+
+// State is persisted next to the executable so a new binary that never
+// reaches the server can be rolled back after a restart.
+type State struct {
+	Pending         bool      `json:"pending"`
+	TargetVersion   string    `json:"target_version,omitempty"`
+	PreviousVersion string    `json:"previous_version,omitempty"`
+	Deadline        time.Time `json:"deadline,omitempty"`
+	FailedVersion   string    `json:"failed_version,omitempty"`
+	ReportStatus    string    `json:"report_status,omitempty"`
+	ReportMessage   string    `json:"report_message,omitempty"`
+}
+
+type checksums struct {
+	Algo  string            `json:"algo"`
+	Files map[string]string `json:"files"`
+}
+
+type manifest struct {
+	Version string `json:"version"`
+}
+
+type Updater struct {
+	api     *client.APIClient
+	keys    []ed25519.PublicKey
+	exePath string
+	current string
+	mu      sync.Mutex
+}
+
+func New(api *client.APIClient, base64Keys []string, currentVersion string) (*Updater, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	keys := make([]ed25519.PublicKey, 0, len(base64Keys))
+	for _, raw := range base64Keys {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(raw)
+		if err != nil || len(b) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid update public key %q", raw)
+		}
+		keys = append(keys, ed25519.PublicKey(b))
+	}
+	return &Updater{api: api, keys: keys, exePath: exe, current: strings.TrimSpace(currentVersion)}, nil
+}
+
+func (u *Updater) Enabled() bool {
+	return u != nil && len(u.keys) > 0
+}
+
+// ShouldUpdate reports whether desired differs from the running version and
+// has not already been rolled back on this host.
+func (u *Updater) ShouldUpdate(desired string) bool {
+	desired = strings.TrimSpace(desired)
+	if !u.Enabled() || desired == "" || desired == u.current {
+		return false
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	st := u.loadState()
+	return !st.Pending && st.FailedVersion != desired
+}
+
+// Apply downloads and verifies the release, swaps the executable and returns
+// ErrRestartRequired on success. The previous binary is kept as <exe>.old.
+func (u *Updater) Apply(ctx context.Context, accessToken, version string, rollbackSec int) error {
+	version = strings.TrimSpace(version)
+	if rollbackSec <= 0 {
+		rollbackSec = defaultRollbackSec
+	}
+	dir, err := os.MkdirTemp(filepath.Dir(u.exePath), ".pingbot-update-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	newBin, err := u.download(ctx, accessToken, version, dir)
+	if err != nil {
+		if errors.Is(err, errVerify) {
+			u.mu.Lock()
+			st := u.loadState()
+			st.FailedVersion = version
+			_ = u.saveState(st)
+			u.mu.Unlock()
+		}
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	oldPath := u.exePath + ".old"
+	_ = os.Remove(oldPath)
+	if err := os.Rename(u.exePath, oldPath); err != nil {
+		return fmt.Errorf("backup current binary: %w", err)
+	}
+	if err := os.Rename(newBin, u.exePath); err != nil {
+		_ = os.Rename(oldPath, u.exePath)
+		return fmt.Errorf("install new binary: %w", err)
+	}
+	st := State{
+		Pending:         true,
+		TargetVersion:   version,
+		PreviousVersion: u.current,
+		Deadline:        time.Now().Add(time.Duration(rollbackSec) * time.Second),
+	}
+	if err := u.saveState(st); err != nil {
+		_ = os.Rename(oldPath, u.exePath)
+		return fmt.Errorf("save update state: %w", err)
+	}
+	log.Printf("update installed version=%s previous=%s rollback_deadline=%s", version, u.current, st.Deadline.Format(time.RFC3339))
+	return ErrRestartRequired
+}
+
+// Recover runs at startup. It rolls back a pending update whose deadline has
+// passed and returns ErrRestartRequired when the previous binary was restored.
+func (u *Updater) Recover() error {
+	if u == nil {
+		return nil
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	_ = os.Remove(u.exePath + ".failed")
+	st := u.loadState()
+	if !st.Pending {
+		return nil
+	}
+	if st.TargetVersion != u.current {
+		// The old binary is running again (manual restore or exec failure).
+		st.Pending = false
+		st.FailedVersion = st.TargetVersion
+		st.ReportStatus = "failed"
+		st.ReportMessage = fmt.Sprintf("running %s instead of %s after update", u.current, st.TargetVersion)
+		return u.saveState(st)
+	}
+	if time.Now().After(st.Deadline) {
+		return u.rollbackLocked(st, "not connected before rollback deadline")
+	}
+	return nil
+}
+
+// Watchdog rolls back and exits if the running update is not confirmed
+// before its deadline.
+func (u *Updater) Watchdog(ctx context.Context) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	st := u.loadState()
+	u.mu.Unlock()
+	if !st.Pending || st.TargetVersion != u.current {
+		return
+	}
+	timer := time.NewTimer(time.Until(st.Deadline))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return
+	case <-timer.C:
+	}
+	u.mu.Lock()
+	st = u.loadState()
+	var err error
+	if st.Pending && st.TargetVersion == u.current {
+		err = u.rollbackLocked(st, "not connected before rollback deadline")
+	}
+	u.mu.Unlock()
+	if errors.Is(err, ErrRestartRequired) {
+		log.Printf("update rolled back version=%s restarting", u.current)
+		os.Exit(ExitCodeRestart)
+	}
+	if err != nil {
+		log.Printf("update rollback failed: %v", err)
+	}
+}
+
+// Confirm marks a pending update as applied once the server accepted the connection.
+func (u *Updater) Confirm() {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	st := u.loadState()
+	if !st.Pending || st.TargetVersion != u.current {
+		return
+	}
+	_ = os.Remove(u.exePath + ".old")
+	st.Pending = false
+	st.FailedVersion = ""
+	st.ReportStatus = "applied"
+	st.ReportMessage = "updated from " + st.PreviousVersion
+	if err := u.saveState(st); err != nil {
+		log.Printf("save update state failed: %v", err)
+		return
+	}
+	log.Printf("update confirmed version=%s", u.current)
+}
+
+// TakeReport returns and clears the outcome recorded by the last update.
+func (u *Updater) TakeReport() (status, message string, ok bool) {
+	if u == nil {
+		return "", "", false
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	st := u.loadState()
+	if st.ReportStatus == "" {
+		return "", "", false
+	}
+	status, message = st.ReportStatus, st.ReportMessage
+	st.ReportStatus = ""
+	st.ReportMessage = ""
+	_ = u.saveState(st)
+	return status, message, true
+}
+
+func (u *Updater) rollbackLocked(st State, reason string) error {
+	oldPath := u.exePath + ".old"
+	if _, err := os.Stat(oldPath); err != nil {
+		return fmt.Errorf("previous binary missing: %w", err)
+	}
+	// Renaming works for a running executable on both unix and windows.
+	if err := os.Rename(u.exePath, u.exePath+".failed"); err != nil {
+		return err
+	}
+	if err := os.Rename(oldPath, u.exePath); err != nil {
+		_ = os.Rename(u.exePath+".failed", u.exePath)
+		return err
+	}
+	st.Pending = false
+	st.FailedVersion = st.TargetVersion
+	st.ReportStatus = "rolled_back"
+	st.ReportMessage = fmt.Sprintf("%s: %s", st.TargetVersion, reason)
+	if err := u.saveState(st); err != nil {
+		return err
+	}
+	log.Printf("update rolled back from=%s to=%s reason=%s", st.TargetVersion, st.PreviousVersion, reason)
+	return ErrRestartRequired
+}
+
+func (u *Updater) download(ctx context.Context, accessToken, version, dir string) (string, error) {
+	fetch := func(rel string, max int64) ([]byte, error) {
+		var buf bytes.Buffer
+		if err := u.api.DownloadReleaseFile(ctx, accessToken, version, rel, &buf, max); err != nil {
+			return nil, fmt.Errorf("download %s: %w", rel, err)
+		}
+		return buf.Bytes(), nil
+	}
+	csBytes, err := fetch("checksums.json", maxMetaBytes)
+	if err != nil {
+		return "", err
+	}
+	sig, err := fetch("signature.sig", maxMetaBytes)
+	if err != nil {
+		return "", err
+	}
+	if !u.verifySignature(csBytes, sig) {
+		return "", fmt.Errorf("%w: signature not trusted", errVerify)
+	}
+	var cs checksums
+	if err := json.Unmarshal(csBytes, &cs); err != nil || !strings.EqualFold(cs.Algo, "sha256") {
+		return "", fmt.Errorf("%w: invalid checksums.json", errVerify)
+	}
+
+	manifestBytes, err := fetch("manifest.json", maxMetaBytes)
+	if err != nil {
+		return "", err
+	}
+	if !checksumMatches(cs, "manifest.json", sha256.Sum256(manifestBytes)) {
+		return "", fmt.Errorf("%w: manifest.json checksum mismatch", errVerify)
+	}
+	var m manifest
+	if err := json.Unmarshal(manifestBytes, &m); err != nil || strings.TrimSpace(m.Version) != version {
+		return "", fmt.Errorf("%w: manifest version mismatch", errVerify)
+	}
+
+	binName := "pingbot"
+	if runtime.GOOS == "windows" {
+		binName = "pingbot.exe"
+	}
+	binRel := "bin/" + runtime.GOOS + "_" + runtime.GOARCH + "/" + binName
+	if _, ok := cs.Files[binRel]; !ok {
+		return "", fmt.Errorf("%w: no binary for %s_%s", errVerify, runtime.GOOS, runtime.GOARCH)
+	}
+	binPath := filepath.Join(dir, binName)
+	f, err := os.OpenFile(binPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	err = u.api.DownloadReleaseFile(ctx, accessToken, version, binRel, io.MultiWriter(f, h), maxBinaryBytes)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", fmt.Errorf("download %s: %w", binRel, err)
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	if !checksumMatches(cs, binRel, sum) {
+		return "", fmt.Errorf("%w: %s checksum mismatch", errVerify, binRel)
+	}
+	if err := os.Chmod(binPath, 0o755); err != nil {
+		return "", err
+	}
+	return binPath, nil
+}
+
+// verifySignature accepts raw or base64 signatures, like the backend plugin verifier.
+func (u *Updater) verifySignature(msg, sig []byte) bool {
+	if len(sig) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+		if err != nil || len(decoded) != ed25519.SignatureSize {
+			return false
+		}
+		sig = decoded
+	}
+	for _, key := range u.keys {
+		if ed25519.Verify(key, msg, sig) {
+			return true
+		}
+	}
+	return false
+}
+
+func checksumMatches(cs checksums, rel string, sum [sha256.Size]byte) bool {
+	want := strings.ToLower(strings.TrimSpace(cs.Files[rel]))
+	return want != "" && want == hex.EncodeToString(sum[:])
+}
+
+func (u *Updater) statePath() string {
+	return u.exePath + ".update.json"
+}
+
+func (u *Updater) loadState() State {
+	var st State
+	b, err := os.ReadFile(u.statePath())
+	if err != nil {
+		return st
+	}
+	_ = json.Unmarshal(b, &st)
+	return st
+}
+
+func (u *Updater) saveState(st State) error {
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := u.statePath() + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, u.statePath())
+}
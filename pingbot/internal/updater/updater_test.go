@@ -0,0 +1,206 @@
+package updater
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"pingbot/internal/client"
+)
+
+// release is what the fake server serves for one version. checksums.json is
+// built from files unless sums overrides an entry.
+type release struct {
+	version string
+	files   map[string][]byte
+	sums    map[string]string
+	signer  ed25519.PrivateKey
+}
+
+func binRel() string {
+	name := "pingbot"
+	if runtime.GOOS == "windows" {
+		name = "pingbot.exe"
+	}
+	return "bin/" + runtime.GOOS + "_" + runtime.GOARCH + "/" + name
+}
+
+func newRelease(version string, binary []byte, signer ed25519.PrivateKey) *release {
+	return &release{
+		version: version,
+		files: map[string][]byte{
+			"manifest.json": []byte(`{"version":"` + version + `"}`),
+			binRel():        binary,
+		},
+		sums:   map[string]string{},
+		signer: signer,
+	}
+}
+
+func (r *release) serve(t *testing.T) *httptest.Server {
+	t.Helper()
+	cs := checksums{Algo: "sha256", Files: map[string]string{}}
+	for name, b := range r.files {
+		sum := sha256.Sum256(b)
+		cs.Files[name] = hex.EncodeToString(sum[:])
+	}
+	for name, sum := range r.sums {
+		cs.Files[name] = sum
+	}
+	csBytes, _ := json.Marshal(cs)
+	sig := ed25519.Sign(r.signer, csBytes)
+	prefix := "/api/v1/probe/releases/" + r.version + "/files/"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rel := strings.TrimPrefix(req.URL.Path, prefix)
+		switch rel {
+		case "checksums.json":
+			_, _ = w.Write(csBytes)
+		case "signature.sig":
+			_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString(sig)))
+		default:
+			b, ok := r.files[rel]
+			if !ok {
+				http.NotFound(w, req)
+				return
+			}
+			_, _ = w.Write(b)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func generateKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return pub, priv
+}
+
+// newTestUpdater points an updater at a fake executable holding "old".
+func newTestUpdater(t *testing.T, exePath, baseURL, current string, key ed25519.PublicKey) *Updater {
+	t.Helper()
+	return &Updater{
+		api:     client.New(baseURL, false),
+		keys:    []ed25519.PublicKey{key},
+		exePath: exePath,
+		current: current,
+	}
+}
+
+func writeExe(t *testing.T) string {
+	t.Helper()
+	exe := filepath.Join(t.TempDir(), "pingbot")
+	if err := os.WriteFile(exe, []byte("old"), 0o755); err != nil {
+		t.Fatalf("write exe: %v", err)
+	}
+	return exe
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(b)
+}
+
+func TestApplyRejectsUntrustedSignature(t *testing.T) {
+	trusted, _ := generateKey(t)
+	_, other := generateKey(t)
+	srv := newRelease("1.1.0", []byte("new"), other).serve(t)
+	exe := writeExe(t)
+	u := newTestUpdater(t, exe, srv.URL, "1.0.0", trusted)
+
+	err := u.Apply(context.Background(), "token", "1.1.0", 60)
+	if !errors.Is(err, errVerify) || !strings.Contains(err.Error(), "signature") {
+		t.Fatalf("expected signature rejection, got %v", err)
+	}
+	if got := readFile(t, exe); got != "old" {
+		t.Fatalf("executable must be untouched, got %q", got)
+	}
+	if u.ShouldUpdate("1.1.0") {
+		t.Fatalf("a release that failed verification must not be retried")
+	}
+}
+
+func TestApplyRejectsChecksumMismatch(t *testing.T) {
+	pub, priv := generateKey(t)
+	rel := newRelease("1.1.0", []byte("new"), priv)
+	sum := sha256.Sum256([]byte("something else"))
+	rel.sums[binRel()] = hex.EncodeToString(sum[:])
+	srv := rel.serve(t)
+	exe := writeExe(t)
+	u := newTestUpdater(t, exe, srv.URL, "1.0.0", pub)
+
+	err := u.Apply(context.Background(), "token", "1.1.0", 60)
+	if !errors.Is(err, errVerify) || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if got := readFile(t, exe); got != "old" {
+		t.Fatalf("executable must be untouched, got %q", got)
+	}
+	if _, err := os.Stat(exe + ".old"); !os.IsNotExist(err) {
+		t.Fatalf("no backup should be made for a rejected release: %v", err)
+	}
+}
+
+func TestRecoverRollsBackWhenNewBinaryNeverConnects(t *testing.T) {
+	pub, priv := generateKey(t)
+	srv := newRelease("1.1.0", []byte("new"), priv).serve(t)
+	exe := writeExe(t)
+	old := newTestUpdater(t, exe, srv.URL, "1.0.0", pub)
+
+	if err := old.Apply(context.Background(), "token", "1.1.0", 60); !errors.Is(err, ErrRestartRequired) {
+		t.Fatalf("apply: %v", err)
+	}
+	if got := readFile(t, exe); got != "new" {
+		t.Fatalf("expected new binary installed, got %q", got)
+	}
+
+	// The new binary starts but cannot reach the server before the deadline.
+	next := newTestUpdater(t, exe, srv.URL, "1.1.0", pub)
+	if err := next.Recover(); err != nil {
+		t.Fatalf("recover before deadline: %v", err)
+	}
+	st := next.loadState()
+	st.Deadline = time.Now().Add(-time.Second)
+	if err := next.saveState(st); err != nil {
+		t.Fatalf("save state: %v", err)
+	}
+	if err := next.Recover(); !errors.Is(err, ErrRestartRequired) {
+		t.Fatalf("expected rollback, got %v", err)
+	}
+	if got := readFile(t, exe); got != "old" {
+		t.Fatalf("expected previous binary restored, got %q", got)
+	}
+	status, msg, ok := next.TakeReport()
+	if !ok || status != "rolled_back" || !strings.Contains(msg, "1.1.0") {
+		t.Fatalf("unexpected report: %q %q %v", status, msg, ok)
+	}
+
+	// Back on the old binary the failed version is not offered again.
+	restarted := newTestUpdater(t, exe, srv.URL, "1.0.0", pub)
+	if err := restarted.Recover(); err != nil {
+		t.Fatalf("recover after rollback: %v", err)
+	}
+	if restarted.ShouldUpdate("1.1.0") {
+		t.Fatalf("rolled back version must not be retried")
+	}
+}
@@ -0,0 +1,6 @@
+package version
+
+// Version is stamped at build time:
+//
+//	go build -ldflags "-X pingbot/internal/version.Version=1.2.0" ./cmd/pingbot
+var Version = "dev"